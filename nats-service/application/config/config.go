@@ -3,9 +3,16 @@ package config
 import (
 	"fmt"
 	"os"
+	"shared/grpc/clients/nats_service/messaging"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
+// DefaultCollectInterval is the metrics collection interval used when none or an invalid one is configured.
+const DefaultCollectInterval = time.Duration(5) * time.Second
+
 var (
 	once   sync.Once
 	config *Config
@@ -29,31 +36,67 @@ func GetConfig() *Config {
 //   - TLS:     TLS configuration settings.
 //   - RPC:     RPC configuration settings.
 //   - Metrics: Metrics configuration settings.
+//   - Log:     Logger configuration settings.
 //   - Env:     Environment type (e.g., dev, prod).
 type Config struct {
 	Nats    NatsConfig
 	TLS     TLSConfig
 	RPC     RPCConfig
 	Metrics MetricsConfig
+	Log     LogConfig
 	Env     string
 }
 
+// LogConfig holds configuration settings for the logger.
+//
+// Fields:
+//   - Format:    Log handler, json or console.
+//   - AddSource: Adds the source file:line of the logging call to every record.
+type LogConfig struct {
+	Format    string
+	AddSource bool
+}
+
 // MetricsConfig holds settings related to the application's metrics endpoint.
 //
 // Fields:
-//   - ServerPort: Port on which the metrics server listens.
+//   - ServerPort:      Port on which the metrics server listens.
+//   - Subjects:        Subjects labeled individually in the message metrics; others are bucketed as "other".
+//     Defaults to the registered subjects (see messaging.Subjects).
+//   - CollectInterval: Interval at which the runtime and heap collectors sample the Go runtime.
+//   - ShutdownTimeout: Maximum duration to stop the collectors and the metrics server together.
 type MetricsConfig struct {
-	ServerPort string
+	ServerPort      string
+	Subjects        []string
+	CollectInterval time.Duration
+	ShutdownTimeout time.Duration
 }
 
 // RPCConfig holds configuration settings for the RPC server.
 //
 // Fields:
-//   - Port: Port on which the Bus gRPC server listens.
+//   - Port:                Port on which the Bus gRPC server listens.
+//   - SubscribeBufferSize: Buffer size of the messages channel of every subscription, 0 for the default.
+//     Values above the handler maximum are capped.
+//   - Transport:           TransportTCP to listen on Port, or TransportInProcess to serve co-located
+//     clients in the same process without a network listener.
+//   - MaxSubscriptions:    Maximum number of concurrent Subscribe streams, 0 disables the limit.
+//   - AckTimeout:          Time SubscribeAck waits for the ack of a message before delivering it again,
+//     0 for the default.
 type RPCConfig struct {
-	Port string
+	Port                string
+	SubscribeBufferSize int
+	Transport           string
+	MaxSubscriptions    int
+	AckTimeout          time.Duration
 }
 
+// Supported values of RPCConfig.Transport.
+const (
+	TransportTCP       = "tcp"
+	TransportInProcess = "inprocess"
+)
+
 // TLSConfig holds configuration settings for TLS.
 //
 // Fields:
@@ -67,11 +110,15 @@ type TLSConfig struct {
 // NatsConfig holds configuration settings for the NATS server.
 //
 // Fields:
-//   - Host: Hostname of the NATS server.
-//   - Port: Port number of the NATS server.
+//   - Host:           Hostname of the NATS server.
+//   - Port:           Port number of the NATS server.
+//   - StreamMaxAge:   Maximum age of the messages of the JetStream streams created for durable subscriptions.
+//   - StreamReplicas: Number of replicas of the JetStream streams created for durable subscriptions.
 type NatsConfig struct {
-	Host string
-	Port string
+	Host           string
+	Port           string
+	StreamMaxAge   time.Duration
+	StreamReplicas int
 }
 
 // loadConfig loads the application configuration by reading the environment variables.
@@ -84,6 +131,7 @@ func loadConfig() *Config {
 		TLS:     loadTLSConfig(),
 		RPC:     loadRPCConfig(),
 		Metrics: loadMetricsConfig(),
+		Log:     loadLogConfig(),
 		Env:     getEnv("ENV", "dev"),
 	}
 }
@@ -91,33 +139,75 @@ func loadConfig() *Config {
 // loadMetricsConfig loads the metrics configuration by reading the appropriate environment variable.
 //
 // Returns:
-//   - MetricsConfig: An instance of MetricsConfig with the metrics server port setting.
+//   - MetricsConfig: An instance of MetricsConfig with the metrics server port and collection settings.
 func loadMetricsConfig() MetricsConfig {
 	metrics := MetricsConfig{
-		ServerPort: getEnv("METRICS_SERVER_PORT", ""),
+		ServerPort:      getEnv("METRICS_SERVER_PORT", ""),
+		Subjects:        parseList(getEnv("METRICS_SUBJECTS", strings.Join(messaging.Names(), ","))),
+		CollectInterval: ParseCollectInterval(getEnv("METRICS_COLLECT_INTERVAL", "")),
+		ShutdownTimeout: getDurationEnv("METRICS_SHUTDOWN_TIMEOUT", time.Duration(10)*time.Second),
 	}
 
 	checkRequiredVars("METRICS_SERVER_PORT", map[string]string{
 		"METRICS_SERVER_PORT": metrics.ServerPort,
 	})
+	if metrics.ShutdownTimeout <= 0 {
+		panic("METRICS configuration error: METRICS_SHUTDOWN_TIMEOUT must be positive")
+	}
 	return metrics
 }
 
 // loadRPCConfig loads RPC configuration settings from environment variables.
 //
 // Returns:
-//   - RPCConfig: An instance of RPCConfig with the appropriate port setting.
+//   - RPCConfig: An instance of RPCConfig with the appropriate port and transport settings.
 func loadRPCConfig() RPCConfig {
 	rpc := RPCConfig{
-		Port: getEnv("NATS_RPC_SERVER_PORT", ""),
+		Port:                getEnv("NATS_RPC_SERVER_PORT", ""),
+		SubscribeBufferSize: getIntEnv("NATS_RPC_SUBSCRIBE_BUFFER_SIZE", 0),
+		Transport:           getEnv("NATS_RPC_TRANSPORT", TransportTCP),
+		MaxSubscriptions:    getIntEnv("NATS_RPC_MAX_SUBSCRIPTIONS", 0),
+		AckTimeout:          getDurationEnv("NATS_RPC_ACK_TIMEOUT", 0),
 	}
 
-	checkRequiredVars("NATS_RPC", map[string]string{
-		"NATS_RPC_SERVER_PORT": rpc.Port,
-	})
+	switch rpc.Transport {
+	case TransportTCP:
+		checkRequiredVars("NATS_RPC", map[string]string{
+			"NATS_RPC_SERVER_PORT": rpc.Port,
+		})
+	case TransportInProcess:
+	default:
+		panic(fmt.Sprintf("NATS_RPC configuration error: unsupported NATS_RPC_TRANSPORT %q", rpc.Transport))
+	}
+	if rpc.SubscribeBufferSize < 0 {
+		panic("NATS_RPC configuration error: NATS_RPC_SUBSCRIBE_BUFFER_SIZE must not be negative")
+	}
+	if rpc.MaxSubscriptions < 0 {
+		panic("NATS_RPC configuration error: NATS_RPC_MAX_SUBSCRIPTIONS must not be negative")
+	}
+	if rpc.AckTimeout < 0 {
+		panic("NATS_RPC configuration error: NATS_RPC_ACK_TIMEOUT must not be negative")
+	}
 	return rpc
 }
 
+// loadLogConfig loads logger configuration settings from environment variables.
+// It panics if LOG_ADD_SOURCE is not a valid boolean.
+//
+// Returns:
+//   - LogConfig: An instance of LogConfig with the log handler settings.
+func loadLogConfig() LogConfig {
+	log := LogConfig{Format: getEnv("LOG_FORMAT", "json")}
+	if v := getEnv("LOG_ADD_SOURCE", ""); v != "" {
+		addSource, err := strconv.ParseBool(v)
+		if err != nil {
+			panic(fmt.Sprintf("LOG configuration error: LOG_ADD_SOURCE must be a boolean: %v", err))
+		}
+		log.AddSource = addSource
+	}
+	return log
+}
+
 // loadTLSConfig loads TLS configuration settings from environment variables.
 //
 // Returns:
@@ -138,11 +228,13 @@ func loadTLSConfig() TLSConfig {
 // loadNatsConfig loads NATS configuration settings from environment variables.
 //
 // Returns:
-//   - NatsConfig: An instance of NatsConfig with NATS server hostname and port.
+//   - NatsConfig: An instance of NatsConfig with NATS server hostname, port and JetStream stream settings.
 func loadNatsConfig() NatsConfig {
 	nats := NatsConfig{
-		Host: getEnv("NATS_HOST", "localhost"),
-		Port: getEnv("NATS_PORT", ""),
+		Host:           getEnv("NATS_HOST", "localhost"),
+		Port:           getEnv("NATS_PORT", ""),
+		StreamMaxAge:   getDurationEnv("NATS_STREAM_MAX_AGE", time.Duration(24)*time.Hour),
+		StreamReplicas: getIntEnv("NATS_STREAM_REPLICAS", 1),
 	}
 
 	// Ensure required values are present
@@ -150,6 +242,12 @@ func loadNatsConfig() NatsConfig {
 		"NATS_HOST": nats.Host,
 		"NATS_PORT": nats.Port,
 	})
+	if nats.StreamMaxAge < 0 {
+		panic("NATS configuration error: NATS_STREAM_MAX_AGE must not be negative")
+	}
+	if nats.StreamReplicas < 1 {
+		panic("NATS configuration error: NATS_STREAM_REPLICAS must be positive")
+	}
 	return nats
 }
 
@@ -168,6 +266,81 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getIntEnv fetches the value of an environment variable as an integer.
+// It panics if the value is not a valid integer.
+//
+// Parameters:
+//   - key:      The name of the environment variable.
+//   - fallback: The default value to return if the environment variable is not set or empty.
+//
+// Returns:
+//   - int: The value of the environment variable or the fallback.
+func getIntEnv(key string, fallback int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(v)
+	if err != nil {
+		panic(fmt.Sprintf("configuration error: %s must be an integer: %v", key, err))
+	}
+	return value
+}
+
+// getDurationEnv fetches the value of an environment variable as a duration (e.g., "10s").
+// It panics if the value is not a valid duration.
+//
+// Parameters:
+//   - key:      The name of the environment variable.
+//   - fallback: The default value to return if the environment variable is not set or empty.
+//
+// Returns:
+//   - time.Duration: The value of the environment variable or the fallback.
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return fallback
+	}
+	value, err := time.ParseDuration(v)
+	if err != nil {
+		panic(fmt.Sprintf("configuration error: %s must be a duration: %v", key, err))
+	}
+	return value
+}
+
+// ParseCollectInterval parses a metrics collection interval (e.g., "10s").
+// Empty, malformed and non-positive values fall back to DefaultCollectInterval.
+//
+// Parameters:
+//   - value: The interval as a Go duration string.
+//
+// Returns:
+//   - time.Duration: The parsed interval or DefaultCollectInterval.
+func ParseCollectInterval(value string) time.Duration {
+	interval, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || interval <= 0 {
+		return DefaultCollectInterval
+	}
+	return interval
+}
+
+// parseList splits a comma-separated list, dropping empty items.
+//
+// Parameters:
+//   - value: The comma-separated list.
+//
+// Returns:
+//   - []string: The trimmed, non-empty items of the list.
+func parseList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // checkRequiredVars ensures that all required environment variables are set.
 //
 // Parameters:
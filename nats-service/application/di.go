@@ -4,7 +4,6 @@ import (
 	"nats-service/application/services"
 	"nats-service/infrastructure"
 	"shared/dependency"
-	"time"
 
 	"github.com/nats-io/nats.go"
 )
@@ -34,14 +33,16 @@ func NewContainer() *Container {
 	c.Operations = dependency.LazyDependency[*services.Operations]{
 		InitFunc: func() *services.Operations {
 			var (
-				logger = c.Infrastructure.Get().Logger.Get()
-				conn   *nats.Conn
-				err    error
+				logger     = c.Infrastructure.Get().Logger.Get()
+				natsConfig = c.Infrastructure.Get().Config.Get().Nats
+				conn       *nats.Conn
+				err        error
 			)
 			if conn, err = c.Infrastructure.Get().NatsClient.Get().Connect(); err != nil {
 				panic(err)
 			}
-			return services.NewOperations(conn, logger)
+			streamConfig := services.WithStreamConfig(natsConfig.StreamMaxAge, natsConfig.StreamReplicas)
+			return services.NewOperations(conn, logger, streamConfig)
 		},
 	}
 	c.MetricsService = dependency.LazyDependency[*services.MetricsService]{
@@ -50,9 +51,10 @@ func NewContainer() *Container {
 				provider      = c.Infrastructure.Get().MetricsProvider.Get()
 				metricsServer = c.Infrastructure.Get().MetricsServer.Get()
 				logger        = c.Infrastructure.Get().Logger.Get()
-				interval      = time.Duration(5) * time.Second
+				interval      = c.Infrastructure.Get().Config.Get().Metrics.CollectInterval
+				timeout       = c.Infrastructure.Get().Config.Get().Metrics.ShutdownTimeout
 			)
-			return services.NewMetricsService(provider, metricsServer, logger, interval)
+			return services.NewMetricsService(provider, metricsServer, logger, interval, timeout)
 		},
 	}
 
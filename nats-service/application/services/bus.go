@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"shared/logging"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/nats-io/nats.go"
 )
@@ -11,11 +16,34 @@ import (
 // Operations provides methods for interacting with the NATS message broker.
 //
 // Fields:
-//   - conn:    The active NATS connection used to send/receive messages.
-//   - logger:  Logger used for logging operation statuses and errors.
+//   - conn:           The active NATS connection used to send/receive messages.
+//   - streamMaxAge:   Maximum age of the messages of the streams created for durable subscriptions, 0 is unlimited.
+//   - streamReplicas: Number of replicas of the streams created for durable subscriptions.
+//   - logger:         Logger used for logging operation statuses and errors.
 type Operations struct {
-	conn   *nats.Conn
-	logger *slog.Logger
+	conn           *nats.Conn
+	streamMaxAge   time.Duration
+	streamReplicas int
+	logger         *slog.Logger
+}
+
+// OperationsOption defines a functional option for configuring Operations.
+type OperationsOption func(*Operations)
+
+// WithStreamConfig sets the configuration of the JetStream streams created for durable subscriptions.
+// Streams that already exist are used as they are.
+//
+// Parameters:
+//   - maxAge:   Maximum age of the stored messages, 0 keeps them until the stream limits are reached.
+//   - replicas: Number of stream replicas, non-positive values keep a single replica.
+//
+// Returns:
+//   - OperationsOption: The functional option setting the stream configuration.
+func WithStreamConfig(maxAge time.Duration, replicas int) OperationsOption {
+	return func(o *Operations) {
+		o.streamMaxAge = max(maxAge, 0)
+		o.streamReplicas = max(replicas, 1)
+	}
 }
 
 // NewOperations creates a new instance of Operations.
@@ -23,11 +51,16 @@ type Operations struct {
 // Parameters:
 //   - conn:    A pointer to the active NATS connection.
 //   - logger:  A pointer to the logger to be used for logging.
+//   - opts:    Optional functional options.
 //
 // Returns:
 //   - *Operations: A pointer to the newly created Operations instance.
-func NewOperations(conn *nats.Conn, logger *slog.Logger) *Operations {
-	return &Operations{conn: conn, logger: logger}
+func NewOperations(conn *nats.Conn, logger *slog.Logger, opts ...OperationsOption) *Operations {
+	o := &Operations{conn: conn, streamReplicas: 1, logger: logger}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
 }
 
 // Publish sends a message to a specified NATS topic.
@@ -40,18 +73,19 @@ func NewOperations(conn *nats.Conn, logger *slog.Logger) *Operations {
 // Returns:
 //   - err: An error if the publish operation fails, or nil if successful.
 func (o *Operations) Publish(ctx context.Context, subject string, data []byte) (err error) {
+	logger := logging.FromContext(ctx, o.logger)
 	if o.conn == nil || o.conn.IsClosed() {
-		o.logger.Error("NATS connection is not established", slog.String("topic", subject))
+		logger.Error("NATS connection is not established", slog.String("topic", subject))
 		return fmt.Errorf("connection is not established")
 	}
 
 	select {
 	case <-ctx.Done():
-		o.logger.Info("Context canceled before publishing", slog.String("topic", subject))
+		logger.Info("Context canceled before publishing", slog.String("topic", subject))
 		return ctx.Err()
 	default:
 		if err = o.conn.Publish(subject, data); err != nil {
-			o.logger.Error("NATS connection publish failed",
+			logger.Error("NATS connection publish failed",
 				slog.String("topic", subject), slog.String("error", err.Error()))
 			return fmt.Errorf("could not send message to NATS: %w", err)
 		}
@@ -60,6 +94,52 @@ func (o *Operations) Publish(ctx context.Context, subject string, data []byte) (
 	return err
 }
 
+// PublishAcked sends a message to a specified NATS topic through JetStream and waits for its PubAck,
+// so the message is persisted once it returns nil.
+//
+// The stream capturing the subject is created with the configured max. age and replicas when no stream
+// acknowledges the first publish.
+//
+// Parameters:
+//   - ctx:     Context for managing timeouts and cancellation signals, bounding the wait for the PubAck.
+//   - subject: The subject/topic to which the message will be published.
+//   - data:    The byte slice representing the message payload.
+//
+// Returns:
+//   - err: An error if JetStream is unavailable or the message is not acked, or nil if successful.
+func (o *Operations) PublishAcked(ctx context.Context, subject string, data []byte) (err error) {
+	logger := logging.FromContext(ctx, o.logger)
+	if o.conn == nil || o.conn.IsClosed() {
+		logger.Error("NATS connection is not established", slog.String("topic", subject))
+		return fmt.Errorf("connection is not established")
+	}
+
+	var js nats.JetStreamContext
+	if js, err = o.conn.JetStream(nats.Context(ctx)); err != nil {
+		logger.Error("JetStream is not available", slog.String("topic", subject), slog.String("error", err.Error()))
+		return fmt.Errorf("could not create JetStream context: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Context canceled before publishing", slog.String("topic", subject))
+		return ctx.Err()
+	default:
+		_, err = js.Publish(subject, data, nats.Context(ctx))
+		if errors.Is(err, nats.ErrNoStreamResponse) {
+			if _, err = o.ensureStream(ctx, js, subject); err == nil {
+				_, err = js.Publish(subject, data, nats.Context(ctx))
+			}
+		}
+		if err != nil {
+			logger.Error("JetStream publish failed", slog.String("topic", subject), slog.String("error", err.Error()))
+			return fmt.Errorf("could not send message to JetStream: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Subscribe listens for messages on the specified NATS subject.
 //
 // Parameters:
@@ -76,14 +156,15 @@ func (o *Operations) Subscribe(
 	subject, queueGroup string,
 	handler func(message *nats.Msg),
 ) (sub *nats.Subscription, err error) {
+	logger := logging.FromContext(ctx, o.logger)
 	if o.conn == nil || o.conn.IsClosed() {
-		o.logger.Error("NATS connection is not established", slog.String("topic", subject))
+		logger.Error("NATS connection is not established", slog.String("topic", subject))
 		return nil, fmt.Errorf("connection is not established")
 	}
 
 	select {
 	case <-ctx.Done():
-		o.logger.Info("Context canceled before subscription", slog.String("topic", subject))
+		logger.Info("Context canceled before subscription", slog.String("topic", subject))
 		return nil, ctx.Err()
 	default:
 		switch queueGroup {
@@ -94,7 +175,7 @@ func (o *Operations) Subscribe(
 		}
 
 		if err != nil {
-			o.logger.Error("NATS connection subscribe failed",
+			logger.Error("NATS connection subscribe failed",
 				slog.String("topic", subject), slog.String("error", err.Error()))
 			return nil, fmt.Errorf("could not subscribe to NATS subject: %w", err)
 		}
@@ -102,3 +183,319 @@ func (o *Operations) Subscribe(
 		return sub, nil
 	}
 }
+
+// SubscribeAcked listens for messages on the specified subject through a JetStream push consumer with manual acks.
+//
+// Messages are acked by publishing to their reply subject, those that are not acked within ackWait are redelivered.
+// The subject must be captured by a JetStream stream. With a queue group, the durable consumer named after the
+// group is created up front when missing, so it outlives the subscription and messages left unacked by a crashed
+// subscriber are redelivered to the next one. An existing consumer whose ack wait differs is updated to ackWait.
+// Without a queue group the consumer is ephemeral and delivers only the messages published after the subscription,
+// so a resubscribe does not replay the stream.
+//
+// Parameters:
+//   - ctx:        Context for managing timeouts and cancellation signals.
+//   - subject:    The subject/topic to subscribe to.
+//   - queueGroup: (Optional) The queue group for load-balanced message processing, naming the durable consumer.
+//   - ackWait:    The time a delivered message may stay unacked before it is redelivered.
+//   - handler:    The message handler function that will process incoming messages.
+//
+// Returns:
+//   - sub: A pointer to the NATS subscription if the subscription is successful.
+//   - err: An error if JetStream is unavailable or the subscription operation fails; otherwise, nil.
+func (o *Operations) SubscribeAcked(
+	ctx context.Context,
+	subject, queueGroup string,
+	ackWait time.Duration,
+	handler func(message *nats.Msg),
+) (sub *nats.Subscription, err error) {
+	logger := logging.FromContext(ctx, o.logger)
+	if o.conn == nil || o.conn.IsClosed() {
+		logger.Error("NATS connection is not established", slog.String("topic", subject))
+		return nil, fmt.Errorf("connection is not established")
+	}
+
+	var js nats.JetStreamContext
+	if js, err = o.conn.JetStream(nats.Context(ctx)); err != nil {
+		logger.Error("JetStream is not available", slog.String("topic", subject), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("could not create JetStream context: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Context canceled before subscription", slog.String("topic", subject))
+		return nil, ctx.Err()
+	default:
+		opts := []nats.SubOpt{nats.ManualAck(), nats.AckExplicit(), nats.AckWait(ackWait)}
+		switch queueGroup {
+		case "":
+			sub, err = js.Subscribe(subject, handler, append(opts, nats.DeliverNew())...)
+		default:
+			if err = o.ensureDurable(ctx, logger, js, subject, queueGroup, ackWait); err == nil {
+				sub, err = js.QueueSubscribe(subject, queueGroup, handler, append(opts, nats.Durable(queueGroup))...)
+			}
+		}
+
+		if err != nil {
+			logger.Error("JetStream subscribe failed",
+				slog.String("topic", subject), slog.String("error", err.Error()))
+			return nil, fmt.Errorf("could not subscribe to JetStream subject: %w", err)
+		}
+
+		return sub, nil
+	}
+}
+
+// SubscribeDurable listens for messages on the specified subject through a durable JetStream consumer.
+//
+// The stream capturing the subject is created with the configured max. age and replicas when missing, and so is
+// the durable consumer, so both outlive the subscription: messages published while no subscriber is connected are
+// persisted and delivered once one subscribes again. A message is acked once the handler returns nil, and
+// negatively acked for an immediate redelivery when it returns an error.
+//
+// Parameters:
+//   - ctx:         Context for managing timeouts and cancellation signals.
+//   - subject:     The subject/topic to subscribe to.
+//   - durableName: The name of the durable consumer, subscriptions with the same name resume its deliveries.
+//   - handler:     The message handler function, returning an error to have the message redelivered.
+//
+// Returns:
+//   - sub: A pointer to the NATS subscription if the subscription is successful.
+//   - err: An error if JetStream is unavailable or the subscription operation fails; otherwise, nil.
+func (o *Operations) SubscribeDurable(
+	ctx context.Context,
+	subject, durableName string,
+	handler func(message *nats.Msg) error,
+) (sub *nats.Subscription, err error) {
+	logger := logging.FromContext(ctx, o.logger)
+	if o.conn == nil || o.conn.IsClosed() {
+		logger.Error("NATS connection is not established", slog.String("topic", subject))
+		return nil, fmt.Errorf("connection is not established")
+	}
+
+	var js nats.JetStreamContext
+	if js, err = o.conn.JetStream(nats.Context(ctx)); err != nil {
+		logger.Error("JetStream is not available", slog.String("topic", subject), slog.String("error", err.Error()))
+		return nil, fmt.Errorf("could not create JetStream context: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Context canceled before subscription", slog.String("topic", subject))
+		return nil, ctx.Err()
+	default:
+		var stream string
+		if stream, err = o.ensureStream(ctx, js, subject); err == nil {
+			err = ensureConsumer(ctx, js, stream, &nats.ConsumerConfig{
+				Durable:        durableName,
+				DeliverSubject: nats.NewInbox(),
+				FilterSubject:  subject,
+				AckPolicy:      nats.AckExplicitPolicy,
+			})
+		}
+		if err == nil {
+			sub, err = js.Subscribe(subject, func(message *nats.Msg) {
+				o.settle(logger, message, handler(message))
+			}, nats.Bind(stream, durableName), nats.ManualAck())
+		}
+
+		if err != nil {
+			logger.Error("JetStream durable subscribe failed", slog.String("topic", subject),
+				slog.String("durable", durableName), slog.String("error", err.Error()))
+			return nil, fmt.Errorf("could not subscribe to JetStream subject: %w", err)
+		}
+
+		return sub, nil
+	}
+}
+
+// settle acks a handled message, or negatively acks it for a redelivery when the handler failed.
+//
+// Parameters:
+//   - logger:     Logger used for logging the handler and ack failures.
+//   - message:    The handled message.
+//   - handlerErr: The error returned by the handler, nil if the message was handled.
+func (o *Operations) settle(logger *slog.Logger, message *nats.Msg, handlerErr error) {
+	if handlerErr == nil {
+		if err := message.Ack(); err != nil {
+			logger.Error("Failed to ack message",
+				slog.String("topic", message.Subject), slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	logger.Warn("Message handler failed, requesting redelivery",
+		slog.String("topic", message.Subject), slog.String("error", handlerErr.Error()))
+	if err := message.Nak(); err != nil {
+		logger.Error("Failed to nak message", slog.String("topic", message.Subject), slog.String("error", err.Error()))
+	}
+}
+
+// ensureStream returns the stream capturing the subject, creating it with the configured max. age and replicas
+// when no stream captures the subject yet.
+//
+// Parameters:
+//   - ctx:     Context for managing timeouts and cancellation signals.
+//   - js:      The JetStream context.
+//   - subject: The subject the stream captures.
+//
+// Returns:
+//   - stream: The name of the stream capturing the subject.
+//   - err:    An error if the stream could not be looked up or created; otherwise, nil.
+func (o *Operations) ensureStream(ctx context.Context, js nats.JetStreamContext, subject string) (
+	stream string,
+	err error,
+) {
+	if stream, err = js.StreamNameBySubject(subject, nats.Context(ctx)); err == nil {
+		return stream, nil
+	} else if !errors.Is(err, nats.ErrNoMatchingStream) {
+		return "", fmt.Errorf("find stream of subject %s: %w", subject, err)
+	}
+
+	// Stream names must not contain dots or wildcards
+	stream = strings.NewReplacer(".", "_", "*", "ANY", ">", "ALL").Replace(strings.ToUpper(subject))
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:     stream,
+		Subjects: []string{subject},
+		MaxAge:   o.streamMaxAge,
+		Replicas: o.streamReplicas,
+		Storage:  nats.FileStorage,
+	}, nats.Context(ctx))
+	if err != nil && !errors.Is(err, nats.ErrStreamNameAlreadyInUse) {
+		return "", fmt.Errorf("create stream %s: %w", stream, err)
+	}
+	return stream, nil
+}
+
+// ensureDurable creates the durable push consumer of a queue group when it does not exist yet, and updates the
+// ack wait of an existing one when it differs from ackWait.
+//
+// A consumer created by the subscription itself would be deleted on unsubscribe, together with the
+// pending acks, creating it beforehand keeps the unacked messages for the next subscriber.
+//
+// Parameters:
+//   - ctx:        Context for managing timeouts and cancellation signals.
+//   - logger:     Logger used for logging the ack wait update.
+//   - js:         The JetStream context.
+//   - subject:    The subject the consumer filters on.
+//   - queueGroup: The queue group, used as the durable consumer name and the deliver group.
+//   - ackWait:    The time a delivered message may stay unacked before it is redelivered.
+//
+// Returns:
+//   - err: An error if no stream captures the subject or the consumer could not be created or updated;
+//     otherwise, nil.
+func (o *Operations) ensureDurable(
+	ctx context.Context,
+	logger *slog.Logger,
+	js nats.JetStreamContext,
+	subject, queueGroup string,
+	ackWait time.Duration,
+) (err error) {
+	var stream string
+	if stream, err = js.StreamNameBySubject(subject, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("find stream of subject %s: %w", subject, err)
+	}
+
+	var info *nats.ConsumerInfo
+	if info, err = js.ConsumerInfo(stream, queueGroup, nats.Context(ctx)); err != nil {
+		if !errors.Is(err, nats.ErrConsumerNotFound) {
+			return fmt.Errorf("lookup consumer %s: %w", queueGroup, err)
+		}
+		return ensureConsumer(ctx, js, stream, &nats.ConsumerConfig{
+			Durable:        queueGroup,
+			DeliverSubject: nats.NewInbox(),
+			DeliverGroup:   queueGroup,
+			FilterSubject:  subject,
+			AckPolicy:      nats.AckExplicitPolicy,
+			AckWait:        ackWait,
+		})
+	}
+	if info.Config.AckWait == ackWait {
+		return nil
+	}
+
+	logger.Info("Updating the ack wait of the durable consumer", slog.String("consumer", queueGroup),
+		slog.Duration("from", info.Config.AckWait), slog.Duration("to", ackWait))
+	config := info.Config
+	config.AckWait = ackWait
+	if _, err = js.UpdateConsumer(stream, &config, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("update ack wait of consumer %s: %w", queueGroup, err)
+	}
+	return nil
+}
+
+// ensureConsumer creates the durable consumer of a stream when it does not exist yet.
+// An existing consumer is used as it is.
+//
+// Parameters:
+//   - ctx:    Context for managing timeouts and cancellation signals.
+//   - js:     The JetStream context.
+//   - stream: The name of the stream of the consumer.
+//   - config: The configuration of the consumer, its Durable field names it.
+//
+// Returns:
+//   - err: An error if the consumer could not be looked up or created; otherwise, nil.
+func ensureConsumer(ctx context.Context, js nats.JetStreamContext, stream string, config *nats.ConsumerConfig) (
+	err error,
+) {
+	if _, err = js.ConsumerInfo(stream, config.Durable, nats.Context(ctx)); err == nil {
+		return nil
+	} else if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return fmt.Errorf("lookup consumer %s: %w", config.Durable, err)
+	}
+
+	if _, err = js.AddConsumer(stream, config, nats.Context(ctx)); err != nil &&
+		!errors.Is(err, nats.ErrConsumerNameAlreadyInUse) {
+		return fmt.Errorf("create consumer %s: %w", config.Durable, err)
+	}
+	return nil
+}
+
+// SubscribeUntilDone listens for messages on the specified NATS subject and unsubscribes
+// automatically once the context is done.
+//
+// Parameters:
+//   - ctx:        Context bounding the lifetime of the subscription.
+//   - subject:    The subject/topic to subscribe to.
+//   - queueGroup: (Optional) The queue group for load-balanced message processing.
+//   - handler:    The message handler function that will process incoming messages.
+//
+// Returns:
+//   - sub:     A pointer to the NATS subscription if the subscription is successful.
+//   - cleanup: A function that unsubscribes immediately; it is safe to call multiple times.
+//   - err:     An error if the subscription operation fails; otherwise, nil.
+func (o *Operations) SubscribeUntilDone(
+	ctx context.Context,
+	subject, queueGroup string,
+	handler func(message *nats.Msg),
+) (sub *nats.Subscription, cleanup func(), err error) {
+	if sub, err = o.Subscribe(ctx, subject, queueGroup, handler); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		once    sync.Once
+		stopped = make(chan struct{})
+		logger  = logging.FromContext(ctx, o.logger)
+	)
+	cleanup = func() {
+		once.Do(func() {
+			close(stopped)
+			if unsubErr := sub.Unsubscribe(); unsubErr != nil && !errors.Is(unsubErr, nats.ErrConnectionClosed) {
+				logger.Error("Failed to unsubscribe",
+					slog.String("topic", subject), slog.String("error", unsubErr.Error()))
+			}
+		})
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context done, unsubscribing", slog.String("topic", subject))
+			cleanup()
+		case <-stopped:
+		}
+	}()
+
+	return sub, cleanup, nil
+}
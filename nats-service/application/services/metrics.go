@@ -12,28 +12,32 @@ import (
 )
 
 // MetricsService manages the lifecycle of application metrics collection and exposure.
+// The collectors and the HTTP server are started and stopped together.
 //
 // Fields:
-//   - provider: Metrics provider responsible for managing metrics collectors.
-//   - server:   HTTP metrics server instance for exposing collected metrics.
-//   - logger:   Structured logger instance for lifecycle logging.
-//   - interval: Duration interval at which metrics are collected.
-//   - wg:       WaitGroup ensuring graceful shutdown.
+//   - provider:        Metrics provider responsible for managing metrics collectors.
+//   - server:          HTTP metrics server instance for exposing collected metrics.
+//   - logger:          Structured logger instance for lifecycle logging.
+//   - interval:        Duration interval at which metrics are collected.
+//   - shutdownTimeout: Upper bound of Stop for both the collectors and the HTTP server.
+//   - wg:              WaitGroup ensuring graceful shutdown.
 type MetricsService struct {
-	provider *metrics.Provider
-	server   *metrics.Server
-	logger   *slog.Logger
-	interval time.Duration
-	wg       sync.WaitGroup
+	provider        *metrics.Provider
+	server          *metrics.Server
+	logger          *slog.Logger
+	interval        time.Duration
+	shutdownTimeout time.Duration
+	wg              sync.WaitGroup
 }
 
 // NewMetricsService creates and initializes a new MetricsService.
 //
 // Parameters:
-//   - provider: Initialized metrics.Provider for managing collectors.
-//   - server:   Initialized metrics.Server exposing metrics via HTTP.
-//   - logger:   Structured logger for logging metrics service lifecycle events.
-//   - interval: Duration between metrics collection cycles.
+//   - provider:        Initialized metrics.Provider for managing collectors.
+//   - server:          Initialized metrics.Server exposing metrics via HTTP.
+//   - logger:          Structured logger for logging metrics service lifecycle events.
+//   - interval:        Duration between metrics collection cycles.
+//   - shutdownTimeout: Maximum duration of Stop when the caller's context has no deadline.
 //
 // Returns:
 //   - *MetricsService: Fully initialized MetricsService instance.
@@ -42,12 +46,14 @@ func NewMetricsService(
 	server *metrics.Server,
 	logger *slog.Logger,
 	interval time.Duration,
+	shutdownTimeout time.Duration,
 ) *MetricsService {
 	return &MetricsService{
-		provider: provider,
-		server:   server,
-		logger:   logger,
-		interval: interval,
+		provider:        provider,
+		server:          server,
+		logger:          logger,
+		interval:        interval,
+		shutdownTimeout: shutdownTimeout,
 	}
 }
 
@@ -69,6 +75,8 @@ func (m *MetricsService) Start() {
 }
 
 // Stop gracefully stops all metrics collection and shuts down the metrics server.
+// The configured shutdown timeout applies when ctx has no deadline of its own; the collectors'
+// goroutines are joined before the HTTP server is shut down within the remaining time.
 //
 // Parameters:
 //   - ctx: Context to control graceful shutdown timeout.
@@ -76,8 +84,15 @@ func (m *MetricsService) Start() {
 // Returns:
 //   - error: An error if stopping the metrics server encounters issues; otherwise, nil.
 func (m *MetricsService) Stop(ctx context.Context) (err error) {
-	m.logger.Info("Stopping metrics service")
-	m.provider.Stop(time.Duration(10) * time.Second)
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.shutdownTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	m.logger.Info("Stopping metrics service", slog.Duration("timeout", time.Until(deadline)))
+	m.provider.Stop(time.Until(deadline))
 
 	if err = m.server.Stop(ctx); err != nil {
 		m.logger.Error("Error stopping metrics HTTP server", slog.String("error", err.Error()))
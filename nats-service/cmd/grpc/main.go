@@ -4,7 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"nats-service/application"
-	"time"
+	"shared/logging"
 )
 
 func main() {
@@ -17,12 +17,12 @@ func main() {
 		metricsService = appContainer.MetricsService.Get()
 	)
 
+	logger.Info("Effective configuration", logging.Config("config", infra.Config.Get()))
+
 	metricsService.Start()
 	defer func() {
-		stopCtx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
-		defer cancel()
-
-		if err := metricsService.Stop(stopCtx); err != nil {
+		// The configured shutdown timeout bounds the collectors and the HTTP server together.
+		if err := metricsService.Stop(context.Background()); err != nil {
 			logger.Debug("Error stopping metrics service", slog.String("error", err.Error()))
 		}
 	}()
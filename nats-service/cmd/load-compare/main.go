@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"nats-service/tests/load/infrastructure/comparator"
+	"os"
+
+	"github.com/mguley/go-loadtest/pkg/reporter"
+)
+
+func main() {
+	var (
+		baselinePath = flag.String("baseline", "", "Path to the baseline JSON result")
+		currentPath  = flag.String("current", "", "Path to the current JSON result")
+		tolerances   comparator.Tolerances
+		baseline     *reporter.ResultOutput
+		current      *reporter.ResultOutput
+		output       []byte
+		err          error
+	)
+	flag.Float64Var(&tolerances.MaxThroughputDropPercent, "max-throughput-drop", 5, "Max. throughput drop in percent")
+	flag.Float64Var(&tolerances.MaxLatencyP99RisePercent, "max-p99-rise", 10, "Max. p99 latency rise in percent")
+	flag.Float64Var(&tolerances.MaxErrorRateRisePercentage, "max-error-rate-rise", 1, "Max. error rate rise in points")
+	flag.Parse()
+
+	if *baselinePath == "" || *currentPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if baseline, err = comparator.LoadResult(*baselinePath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if current, err = comparator.LoadResult(*currentPath); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	diff := comparator.Compare(baseline, current, tolerances)
+	if output, err = json.Marshal(diff); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Println(string(output))
+
+	if !diff.Passed {
+		os.Exit(1)
+	}
+}
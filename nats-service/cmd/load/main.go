@@ -1,8 +1,10 @@
 package main
 
 import (
+	"errors"
 	"log/slog"
 	"nats-service/tests/load"
+	loadOrchestrator "nats-service/tests/load/infrastructure/orchestrator"
 	"os"
 
 	"github.com/mguley/go-loadtest/pkg/core"
@@ -20,31 +22,66 @@ func main() {
 		err           error
 	)
 
+	if err = load.CheckTarget(config, logger); err != nil {
+		logger.Error("Load test refused", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	if testRunner, err = runnerFactory.CreateRunner(testType); err != nil {
 		logger.Error("Failed to create test runner", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	orchestrator.AddRunner(testRunner)
+	if err = orchestrator.AddRunner(testRunner); err != nil {
+		logger.Error("Failed to add test runner", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	// Add metrics collectors.
-	orchestrator.AddCollector(app.CompositeCollector.Get())
+	if err = orchestrator.AddCollector(app.CompositeCollector.Get()); err != nil {
+		logger.Error("Failed to add metrics collector", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	// Add reporters.
-	orchestrator.AddReporter(app.ConsoleReporter.Get())
+	if err = orchestrator.AddReporter(app.ConsoleReporter.Get()); err != nil {
+		logger.Error("Failed to add reporter", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if config.NDJSONPath != "" {
+		if err = orchestrator.AddReporter(app.NDJSONReporter.Get()); err != nil {
+			logger.Error("Failed to add reporter", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+	if config.CSVPath != "" {
+		if err = orchestrator.AddReporter(app.CSVReporter.Get()); err != nil {
+			logger.Error("Failed to add reporter", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
 
 	// Log test start and parameters.
 	logger.Info("Starting NATS service load test",
 		slog.String("test_type", string(testType)),
-		slog.String("subject", config.Subject),
+		slog.String("subject", config.EffectiveSubject()),
 		slog.Int("concurrency", config.Concurrency),
 		slog.Any("duration", config.Duration.String()))
 
 	// Run the load test.
 	if err = orchestrator.Run(); err != nil {
+		if errors.Is(err, loadOrchestrator.ErrInterrupted) {
+			logger.Warn("Load test interrupted, partial results reported")
+			os.Exit(130)
+		}
 		logger.Error("Load test failed", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
+	if report := app.ConsoleReporter.Get().SLOReport(); report != nil && !report.Passed {
+		logger.Error("Load test breached its service level objectives", slog.Any("slo", report.Verdicts))
+		os.Exit(1)
+	}
+
 	logger.Info("Load test completed successfully")
 }
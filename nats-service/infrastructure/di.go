@@ -1,7 +1,6 @@
 package infrastructure
 
 import (
-	"log"
 	"log/slog"
 	"nats-service/application/config"
 	"nats-service/application/services"
@@ -11,34 +10,46 @@ import (
 	"nats-service/infrastructure/grpc/handler"
 	"nats-service/infrastructure/grpc/server"
 	"nats-service/infrastructure/grpc/validators"
+	"nats-service/infrastructure/logfile"
 	"nats-service/infrastructure/metrics"
 	"os"
 	"shared/dependency"
+	"shared/grpc/clients/nats_service"
+	"shared/logging"
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Container provides a lazily initialized set of infrastructure dependencies.
 //
 // Fields:
-//   - Logger:     Lazy dependency for the logger instance.
-//   - Config:     Lazy dependency for the application configuration.
-//   - NatsClient: Lazy dependency for the NATS client.
-//   - Operations: Lazy dependency for the NATS operations service.
-//   - Validator:  Lazy dependency for the request validator.
-//   - BusService: Lazy dependency for the gRPC bus service.
-//   - BusServer:  Lazy dependency for the gRPC bus server.
+//   - Logger:              Lazy dependency for the logger instance.
+//   - Config:              Lazy dependency for the application configuration.
+//   - NatsClient:          Lazy dependency for the NATS client.
+//   - Operations:          Lazy dependency for the NATS operations service.
+//   - Validator:           Lazy dependency for the request validator.
+//   - BusService:          Lazy dependency for the gRPC bus service.
+//   - BusServer:           Lazy dependency for the gRPC bus server.
+//   - MetricsServer:       Lazy dependency for the Prometheus metrics HTTP server.
+//   - MetricsRegistry:     Lazy dependency for the registry shared by all metric components.
+//   - MetricsProvider:     Lazy dependency for the runtime and heap metrics collectors.
+//   - MessageMetrics:      Lazy dependency for the per-subject message metrics.
+//   - SubscriptionMetrics: Lazy dependency for the Subscribe stream metrics.
 type Container struct {
-	Logger          dependency.LazyDependency[*slog.Logger]
-	Config          dependency.LazyDependency[*config.Config]
-	NatsClient      dependency.LazyDependency[*broker.Client]
-	Operations      dependency.LazyDependency[*services.Operations]
-	Validator       dependency.LazyDependency[validators.Validator]
-	BusService      dependency.LazyDependency[*handler.BusService]
-	BusServer       dependency.LazyDependency[*server.BusServer]
-	MetricsServer   dependency.LazyDependency[*metrics.Server]
-	MetricsProvider dependency.LazyDependency[*metrics.Provider]
+	Logger              dependency.LazyDependency[*slog.Logger]
+	Config              dependency.LazyDependency[*config.Config]
+	NatsClient          dependency.LazyDependency[*broker.Client]
+	Operations          dependency.LazyDependency[*services.Operations]
+	Validator           dependency.LazyDependency[validators.Validator]
+	BusService          dependency.LazyDependency[*handler.BusService]
+	BusServer           dependency.LazyDependency[*server.BusServer]
+	MetricsServer       dependency.LazyDependency[*metrics.Server]
+	MetricsRegistry     dependency.LazyDependency[*prometheus.Registry]
+	MetricsProvider     dependency.LazyDependency[*metrics.Provider]
+	MessageMetrics      dependency.LazyDependency[*metrics.MessageMetrics]
+	SubscriptionMetrics dependency.LazyDependency[*metrics.SubscriptionMetrics]
 }
 
 // NewContainer initializes and returns a new Container with all required dependencies.
@@ -50,19 +61,9 @@ func NewContainer() *Container {
 
 	c.Logger = dependency.LazyDependency[*slog.Logger]{
 		InitFunc: func() *slog.Logger {
-			var (
-				file *os.File
-				err  error
-			)
-			if err = os.MkdirAll(interfaces.LogDir, 0o755); err != nil {
-				log.Fatalf("Failed to create log directory: %v", err)
-			}
-
-			file, err = os.OpenFile(interfaces.LogFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-			if err != nil {
-				return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
-			}
-			return slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{}))
+			options := c.Config.Get().Log
+			return logfile.NewLogger(interfaces.LogFilePath, os.Stdout,
+				logging.HandlerOptions{Format: options.Format, AddSource: options.AddSource})
 		},
 	}
 	c.Config = dependency.LazyDependency[*config.Config]{
@@ -104,15 +105,17 @@ func NewContainer() *Container {
 	c.Operations = dependency.LazyDependency[*services.Operations]{
 		InitFunc: func() *services.Operations {
 			var (
-				logger = c.Logger.Get()
-				conn   *nats.Conn
-				err    error
+				logger     = c.Logger.Get()
+				natsConfig = c.Config.Get().Nats
+				conn       *nats.Conn
+				err        error
 			)
 			if conn, err = c.NatsClient.Get().Connect(); err != nil {
 				logger.Error("Failed to connect to NATS", slog.String("error", err.Error()))
 				panic(err)
 			}
-			return services.NewOperations(conn, logger)
+			streamConfig := services.WithStreamConfig(natsConfig.StreamMaxAge, natsConfig.StreamReplicas)
+			return services.NewOperations(conn, logger, streamConfig)
 		},
 	}
 	c.Validator = dependency.LazyDependency[validators.Validator]{
@@ -122,7 +125,22 @@ func NewContainer() *Container {
 	}
 	c.BusService = dependency.LazyDependency[*handler.BusService]{
 		InitFunc: func() *handler.BusService {
-			return handler.NewBusService(c.Operations.Get(), c.Validator.Get(), c.Logger.Get())
+			var (
+				operations     = c.Operations.Get()
+				validator      = c.Validator.Get()
+				logger         = c.Logger.Get()
+				messageMetrics = c.MessageMetrics.Get()
+				subMetrics     = c.SubscriptionMetrics.Get()
+				bufferSize     = c.Config.Get().RPC.SubscribeBufferSize
+				maxSubs        = c.Config.Get().RPC.MaxSubscriptions
+				ackTimeout     = c.Config.Get().RPC.AckTimeout
+			)
+			return handler.NewBusService(operations, validator, logger,
+				handler.WithMessageMetrics(messageMetrics),
+				handler.WithChannelBufferSize(bufferSize),
+				handler.WithMaxSubscriptions(maxSubs),
+				handler.WithSubscriptionMetrics(subMetrics),
+				handler.WithAckTimeout(ackTimeout))
 		},
 	}
 	c.BusServer = dependency.LazyDependency[*server.BusServer]{
@@ -131,12 +149,19 @@ func NewContainer() *Container {
 				logger    = c.Logger.Get()
 				env       = c.Config.Get().Env
 				port      = c.Config.Get().RPC.Port
+				transport = c.Config.Get().RPC.Transport
 				certFile  = c.Config.Get().TLS.Certificate
 				keyFile   = c.Config.Get().TLS.Key
+				health    = server.WithHealthCheck(c.NatsClient.Get().IsConnected)
 				err       error
 				busServer *server.BusServer
 			)
-			if busServer, err = server.NewBusServer(env, port, certFile, keyFile, logger); err != nil {
+			if transport == config.TransportInProcess {
+				busServer, err = server.NewInProcessBusServer(nats_service.InProcessName, logger, health)
+			} else {
+				busServer, err = server.NewBusServer(env, port, certFile, keyFile, logger, health)
+			}
+			if err != nil {
 				logger.Error("Failed to create BusServer", slog.String("error", err.Error()))
 				panic(err)
 			}
@@ -155,13 +180,46 @@ func NewContainer() *Container {
 			return metrics.NewServer(port, metricsProvider, logger)
 		},
 	}
+	c.MetricsRegistry = dependency.LazyDependency[*prometheus.Registry]{
+		InitFunc: prometheus.NewRegistry,
+	}
 	c.MetricsProvider = dependency.LazyDependency[*metrics.Provider]{
 		InitFunc: func() *metrics.Provider {
 			var (
 				namespace = "nats_service"
+				registry  = c.MetricsRegistry.Get()
 				logger    = c.Logger.Get()
 			)
-			return metrics.NewProvider(namespace, logger)
+			return metrics.NewProvider(namespace, logger, metrics.WithRegistry(registry))
+		},
+	}
+	c.MessageMetrics = dependency.LazyDependency[*metrics.MessageMetrics]{
+		InitFunc: func() *metrics.MessageMetrics {
+			var (
+				namespace      = "nats_service"
+				subjects       = c.Config.Get().Metrics.Subjects
+				registry       = c.MetricsRegistry.Get()
+				messageMetrics = metrics.NewMessageMetrics(namespace, subjects)
+			)
+			if err := messageMetrics.Register(registry); err != nil {
+				c.Logger.Get().Error("Failed to register message metrics", slog.String("error", err.Error()))
+				panic(err)
+			}
+			return messageMetrics
+		},
+	}
+	c.SubscriptionMetrics = dependency.LazyDependency[*metrics.SubscriptionMetrics]{
+		InitFunc: func() *metrics.SubscriptionMetrics {
+			var (
+				namespace           = "nats_service"
+				registry            = c.MetricsRegistry.Get()
+				subscriptionMetrics = metrics.NewSubscriptionMetrics(namespace)
+			)
+			if err := subscriptionMetrics.Register(registry); err != nil {
+				c.Logger.Get().Error("Failed to register subscription metrics", slog.String("error", err.Error()))
+				panic(err)
+			}
+			return subscriptionMetrics
 		},
 	}
 
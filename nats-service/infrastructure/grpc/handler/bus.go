@@ -1,36 +1,242 @@
 package handler
 
 import (
+	"context"
 	"log/slog"
-	"nats-service/application/services"
 	"nats-service/infrastructure/grpc/validators"
+	"nats-service/infrastructure/metrics"
 	natsservicev1 "shared/proto/nats-service/gen"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
 )
 
+// Operations is the set of NATS operations BusService relies on.
+//
+// It is implemented by services.Operations, and can be replaced by an in-memory implementation in tests.
+type Operations interface {
+	// Publish sends data to the subject.
+	Publish(ctx context.Context, subject string, data []byte) (err error)
+
+	// PublishAcked sends data to the subject through JetStream and returns once the message is persisted.
+	PublishAcked(ctx context.Context, subject string, data []byte) (err error)
+
+	// Subscribe delivers the messages of the subject to handler, queueGroup load-balances them when not empty.
+	Subscribe(
+		ctx context.Context,
+		subject, queueGroup string,
+		handler func(message *nats.Msg),
+	) (sub *nats.Subscription, err error)
+
+	// SubscribeAcked delivers the messages of the subject through JetStream, messages are acked by publishing
+	// to their reply subject and redelivered when not acked within ackWait.
+	SubscribeAcked(
+		ctx context.Context,
+		subject, queueGroup string,
+		ackWait time.Duration,
+		handler func(message *nats.Msg),
+	) (sub *nats.Subscription, err error)
+}
+
 // BusService is the gRPC service implementation for handling NATS operations.
 //
 // It provides methods for publishing and subscribing to NATS messages.
 //
 // Fields:
-//   - operations: Reference to service operations for interacting with NATS.
-//   - validator:  Validator for incoming gRPC requests.
-//   - logger:     Logger for structured logging of service events.
+//   - operations:        Reference to service operations for interacting with NATS.
+//   - validator:         Validator for incoming gRPC requests.
+//   - chunkSize:         Maximum payload size of a single SubscribeResponse; larger messages are chunked.
+//   - channelBufferSize: Buffer size of the messages channel of every subscription.
+//   - metrics:           Optional per-subject message metrics; nil disables them.
+//   - maxSubscriptions:  Maximum number of concurrent Subscribe streams; 0 disables the limit.
+//   - subscriptions:     Number of currently open Subscribe streams.
+//   - subMetrics:        Optional subscription metrics; nil disables them.
+//   - ackTimeout:        Time SubscribeAck waits for the ack of a message before delivering it again.
+//   - logger:            Logger for structured logging of service events.
 type BusService struct {
 	natsservicev1.UnimplementedBusServiceServer
-	operations *services.Operations
-	validator  validators.Validator
-	logger     *slog.Logger
+	operations        Operations
+	validator         validators.Validator
+	chunkSize         int
+	channelBufferSize int
+	metrics           *metrics.MessageMetrics
+	maxSubscriptions  int
+	subscriptions     atomic.Int64
+	subMetrics        *metrics.SubscriptionMetrics
+	ackTimeout        time.Duration
+	logger            *slog.Logger
+}
+
+// Option defines a functional option for configuring BusService.
+type Option func(*BusService)
+
+// WithChunkSize sets the maximum payload size of a single SubscribeResponse.
+//
+// Messages larger than the chunk size are split into several responses sharing a message id.
+// Non-positive values are ignored and the default chunk size is kept.
+//
+// Parameters:
+//   - size: Maximum number of payload bytes per streamed response.
+//
+// Returns:
+//   - Option: A functional option that sets the chunk size.
+func WithChunkSize(size int) Option {
+	return func(s *BusService) {
+		if size > 0 {
+			s.chunkSize = size
+		}
+	}
+}
+
+// WithChannelBufferSize sets the buffer size of the messages channel of every subscription.
+//
+// Larger buffers absorb bursts on high-throughput subjects, smaller ones reduce the memory footprint.
+// Non-positive values are ignored and the default buffer size is kept; values above MaxChannelBufferSize
+// are capped.
+//
+// Parameters:
+//   - size: Number of messages buffered per subscription.
+//
+// Returns:
+//   - Option: A functional option that sets the channel buffer size.
+func WithChannelBufferSize(size int) Option {
+	return func(s *BusService) {
+		if size > 0 {
+			s.channelBufferSize = min(size, MaxChannelBufferSize)
+		}
+	}
+}
+
+// WithMessageMetrics records the published and received messages in the per-subject message metrics.
+//
+// Parameters:
+//   - messageMetrics: The message metrics to record into; nil disables them.
+//
+// Returns:
+//   - Option: A functional option that sets the message metrics.
+func WithMessageMetrics(messageMetrics *metrics.MessageMetrics) Option {
+	return func(s *BusService) {
+		s.metrics = messageMetrics
+	}
+}
+
+// WithMaxSubscriptions limits the number of concurrent Subscribe streams.
+//
+// Streams opened beyond the limit are rejected with ResourceExhausted, which protects NATS and the server
+// memory from subscription floods. Non-positive values disable the limit.
+//
+// Parameters:
+//   - limit: Maximum number of concurrent Subscribe streams.
+//
+// Returns:
+//   - Option: A functional option that sets the concurrent subscriptions limit.
+func WithMaxSubscriptions(limit int) Option {
+	return func(s *BusService) {
+		s.maxSubscriptions = max(limit, 0)
+	}
+}
+
+// WithSubscriptionMetrics records the open and rejected Subscribe streams in the subscription metrics.
+//
+// Parameters:
+//   - subscriptionMetrics: The subscription metrics to record into; nil disables them.
+//
+// Returns:
+//   - Option: A functional option that sets the subscription metrics.
+func WithSubscriptionMetrics(subscriptionMetrics *metrics.SubscriptionMetrics) Option {
+	return func(s *BusService) {
+		s.subMetrics = subscriptionMetrics
+	}
+}
+
+// WithAckTimeout sets the time SubscribeAck waits for the ack of a delivered message before delivering it again.
+//
+// Short timeouts redeliver the messages of a stalled client sooner, at the cost of duplicates when processing
+// takes longer. Non-positive values are ignored and DefaultAckTimeout is kept.
+//
+// Parameters:
+//   - timeout: Time a delivered message may stay unacked.
+//
+// Returns:
+//   - Option: A functional option that sets the ack timeout.
+func WithAckTimeout(timeout time.Duration) Option {
+	return func(s *BusService) {
+		if timeout > 0 {
+			s.ackTimeout = timeout
+		}
+	}
 }
 
 // NewBusService creates a new instance of BusService.
 //
 // Parameters:
-//   - operations: Pointer to the Operations service for NATS interactions.
+//   - operations: The Operations for NATS interactions, e.g., services.Operations.
 //   - validator:  Validator for validating incoming requests.
 //   - logger:     Logger instance for logging.
+//   - opts:       Optional functional options for configuring the service.
 //
 // Returns:
 //   - *BusService: A pointer to the newly created BusService.
-func NewBusService(operations *services.Operations, validator validators.Validator, logger *slog.Logger) *BusService {
-	return &BusService{operations: operations, validator: validator, logger: logger}
+func NewBusService(
+	operations Operations,
+	validator validators.Validator,
+	logger *slog.Logger,
+	opts ...Option,
+) *BusService {
+	s := &BusService{
+		operations:        operations,
+		validator:         validator,
+		chunkSize:         defaultChunkSize,
+		channelBufferSize: DefaultChannelBufferSize,
+		ackTimeout:        DefaultAckTimeout,
+		logger:            logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ChannelBufferSize returns the buffer size of the messages channel created for every subscription.
+//
+// Returns:
+//   - int: The channel buffer size.
+func (s *BusService) ChannelBufferSize() int {
+	return s.channelBufferSize
+}
+
+// Subscriptions returns the number of currently open Subscribe streams.
+//
+// Returns:
+//   - int: The number of open streams.
+func (s *BusService) Subscriptions() int {
+	return int(s.subscriptions.Load())
+}
+
+// acquireSubscription reserves a slot for a new Subscribe stream.
+//
+// Returns:
+//   - bool: True if the stream may proceed, in which case releaseSubscription must be called once it closes.
+func (s *BusService) acquireSubscription() bool {
+	count := s.subscriptions.Add(1)
+	if s.maxSubscriptions > 0 && count > int64(s.maxSubscriptions) {
+		s.subscriptions.Add(-1)
+		if s.subMetrics != nil {
+			s.subMetrics.ObserveRejected()
+		}
+		return false
+	}
+	if s.subMetrics != nil {
+		s.subMetrics.SetActive(int(count))
+	}
+	return true
+}
+
+// releaseSubscription frees the slot of a closed Subscribe stream.
+func (s *BusService) releaseSubscription() {
+	count := s.subscriptions.Add(-1)
+	if s.subMetrics != nil {
+		s.subMetrics.SetActive(int(count))
+	}
 }
@@ -2,9 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"shared/logging"
 	natsservicev1 "shared/proto/nats-service/gen"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,6 +20,7 @@ var successResponse = &natsservicev1.PublishResponse{
 }
 
 // Publish is a unary RPC method that publishes a message to a specified NATS subject.
+// Requests with jetstream set are published through JetStream and succeed once the message is persisted.
 //
 // Parameters:
 //   - ctx:     The context for the RPC request.
@@ -29,18 +33,47 @@ func (s *BusService) Publish(
 	ctx context.Context,
 	request *natsservicev1.PublishRequest,
 ) (response *natsservicev1.PublishResponse, err error) {
+	logger := logging.FromContext(ctx, s.logger)
 	if result := s.validator.ValidatePublishRequest(request); result != nil {
-		s.logger.Error("Publish request failed due to validation",
+		logger.Error("Publish request failed due to validation",
 			slog.String("subject", request.Subject), slog.String("error", result.Error()))
 		return nil, result
 	}
 
-	if err = s.operations.Publish(ctx, request.GetSubject(), request.GetData()); err != nil {
-		s.logger.Error("Failed to publish",
+	start := time.Now()
+	publish := s.operations.Publish
+	if request.GetJetstream() {
+		publish = s.operations.PublishAcked
+	}
+	if err = publish(ctx, request.GetSubject(), request.GetData()); err != nil {
+		logger.Error("Failed to publish",
 			slog.String("subject", request.GetSubject()),
 			slog.String("error", err.Error()))
-		return nil, status.Error(codes.Internal, fmt.Sprintf("could not publish: %v", err))
+		return nil, status.Error(publishCode(err), fmt.Sprintf("could not publish: %v", err))
+	}
+	if s.metrics != nil {
+		s.metrics.ObservePublish(request.GetSubject(), time.Since(start))
 	}
 
 	return successResponse, nil
 }
+
+// publishCode maps a broker publish failure to a gRPC status code.
+// Failures of the broker are reported as unavailable so that clients retry them,
+// while an interrupted request keeps the code of its context error.
+//
+// Parameters:
+//   - err: The error returned by the broker.
+//
+// Returns:
+//   - code: The gRPC status code reported to the client.
+func publishCode(err error) (code codes.Code) {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	default:
+		return codes.Unavailable
+	}
+}
@@ -1,9 +1,17 @@
 package handler
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"shared/logging"
 	natsservicev1 "shared/proto/nats-service/gen"
 	"sync"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"google.golang.org/grpc"
@@ -12,10 +20,34 @@ import (
 )
 
 const (
-	// channelBufferSize defines the buffer size for the messages channel.
-	channelBufferSize = 64
+	// DefaultChannelBufferSize defines the default buffer size for the messages channel of a subscription.
+	DefaultChannelBufferSize = 64
+
+	// MaxChannelBufferSize defines the maximum buffer size for the messages channel of a subscription.
+	MaxChannelBufferSize = 65536
+
+	// defaultChunkSize defines the maximum payload size of a single SubscribeResponse.
+	defaultChunkSize = 512 * 1024
+
+	// DefaultAckTimeout defines the default time SubscribeAck waits for the ack of a message before delivering it again.
+	DefaultAckTimeout = 30 * time.Second
 )
 
+// jetStreamAck is the payload published to the reply subject of a JetStream message to ack it.
+var jetStreamAck = []byte("+ACK")
+
+// responseStream is the sending side of the Subscribe and SubscribeAck streams.
+type responseStream interface {
+	Send(response *natsservicev1.SubscribeResponse) error
+}
+
+// outstandingMessage is a message delivered by SubscribeAck and not acked yet.
+type outstandingMessage struct {
+	message    *nats.Msg // message is the NATS message, delivered again when not acked in time.
+	deadline   time.Time // deadline is the time after which the message is delivered again.
+	deliveries int       // deliveries is the number of times the message was delivered.
+}
+
 // responsePool is a sync.Pool used to reuse SubscribeResponse objects
 // to minimize allocations during high message throughput.
 var responsePool = sync.Pool{
@@ -28,12 +60,19 @@ var responsePool = sync.Pool{
 func reset(response *natsservicev1.SubscribeResponse) {
 	response.Data = nil
 	response.Subject = ""
+	response.MessageId = ""
+	response.Sequence = 0
+	response.Total = 0
+	response.Reply = ""
 }
 
 // Subscribe is a server-streaming RPC method that subscribes to a NATS subject
 // and streams incoming messages to the client.
 //
 // It listens for messages on the specified subject and streams them as SubscribeResponse messages.
+// A request with an ack wait subscribes through JetStream with manual acks instead: every response carries
+// the reply subject the client publishes to once the message is processed, unacked messages are redelivered.
+// SubscribeAck acks the messages on the stream itself.
 //
 // Parameters:
 //   - request: Pointer to the SubscribeRequest containing the subject and an optional queue group.
@@ -45,32 +84,35 @@ func (s *BusService) Subscribe(
 	request *natsservicev1.SubscribeRequest,
 	server grpc.ServerStreamingServer[natsservicev1.SubscribeResponse],
 ) (err error) {
+	logger := logging.FromContext(server.Context(), s.logger)
 	if result := s.validator.ValidateSubscribeRequest(request); result != nil {
-		s.logger.Error("Subscribe request failed due to validation",
+		logger.Error("Subscribe request failed due to validation",
 			slog.String("subject", request.Subject), slog.String("error", result.Error()))
 		return result
 	}
 
+	if !s.acquireSubscription() {
+		logger.Warn("Subscribe request rejected, too many concurrent subscriptions",
+			slog.String("subject", request.GetSubject()), slog.Int("limit", s.maxSubscriptions))
+		return status.Error(codes.ResourceExhausted, "too many concurrent subscriptions")
+	}
+	defer s.releaseSubscription()
+
 	var (
 		sub        *nats.Subscription
-		messagesCh = make(chan *nats.Msg, channelBufferSize)
+		messagesCh = make(chan *nats.Msg, s.channelBufferSize)
+		done       = make(chan struct{})
 		ctx        = server.Context()
 		subject    = request.GetSubject()
-		queueGroup = request.GetQueueGroup()
-		handler    = func(msg *nats.Msg) { messagesCh <- msg }
 	)
 
-	if sub, err = s.operations.Subscribe(ctx, subject, queueGroup, handler); err != nil {
-		s.logger.Error("Failed to subscribe",
+	if sub, err = s.subscribe(ctx, request, messagesCh, done); err != nil {
+		logger.Error("Failed to subscribe",
 			slog.String("topic", subject), slog.String("error", err.Error()))
 		return status.Error(codes.Internal, err.Error())
 	}
 
-	defer func() {
-		if unsubErr := sub.Unsubscribe(); unsubErr != nil {
-			s.logger.Error("Failed to unsubscribe", slog.String("error", unsubErr.Error()))
-		}
-	}()
+	defer s.unsubscribe(logger, sub, done)
 
 	for {
 		select {
@@ -78,26 +120,379 @@ func (s *BusService) Subscribe(
 			return status.Error(codes.Canceled, ctx.Err().Error())
 		case message, ok := <-messagesCh:
 			if !ok {
-				s.logger.Info("Message channel closed, shutting down subscription")
+				logger.Info("Message channel closed, shutting down subscription")
 				return nil
 			}
 
-			// Retrieve a response object from the pool and populate it.
-			response := responsePool.Get().(*natsservicev1.SubscribeResponse)
-			response.Data = message.Data
-			response.Subject = message.Subject
+			start := time.Now()
+			if err = s.send(server, message, ""); err != nil {
+				logger.Error("Failed to send response",
+					slog.String("topic", subject), slog.String("error", err.Error()))
+				return status.Error(codes.Internal, err.Error())
+			}
+			if s.metrics != nil {
+				s.metrics.ObserveDelivery(message.Subject, time.Since(start))
+			}
+		}
+	}
+}
+
+// SubscribeAck is a bidirectional streaming RPC method that subscribes to a NATS subject and streams incoming
+// messages to the client until it acknowledges them.
+//
+// The first request of the stream carries the SubscribeRequest, the following ones ack the delivered messages
+// by the message id of their responses. A delivered message stays outstanding until its ack arrives, and is
+// delivered again with the same id once the ack timeout elapsed. At most the channel buffer size of messages
+// are outstanding, no new message is delivered until an ack makes room.
+//
+// The outstanding messages live in the stream only. For a plain, non-JetStream subscription, the unacked and
+// the not yet delivered messages are lost when the stream ends, e.g. when the client crashes or disconnects.
+// A SubscribeRequest with an ack wait subscribes through JetStream instead: the JetStream message is acked once
+// the client acked it, and JetStream redelivers the unacked ones after the stream ended or the ack wait elapsed.
+//
+// Parameters:
+//   - server: The gRPC bidirectional streaming interface receiving acks and sending SubscribeResponse messages.
+//
+// Returns:
+//   - err: An error if the subscription or streaming fails, or nil once the client closed its side of the stream.
+func (s *BusService) SubscribeAck(
+	server grpc.BidiStreamingServer[natsservicev1.SubscribeAckRequest, natsservicev1.SubscribeResponse],
+) (err error) {
+	var (
+		ctx    = server.Context()
+		logger = logging.FromContext(ctx, s.logger)
+		first  *natsservicev1.SubscribeAckRequest
+	)
+
+	if first, err = server.Recv(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return status.Error(codes.InvalidArgument, "subscribe request is required")
+		}
+		return err
+	}
+	request := first.GetSubscribe()
+	if request == nil {
+		logger.Error("SubscribeAck request failed, the first request does not subscribe")
+		return status.Error(codes.InvalidArgument, "the first request must subscribe")
+	}
+	if result := s.validator.ValidateSubscribeRequest(request); result != nil {
+		logger.Error("SubscribeAck request failed due to validation",
+			slog.String("subject", request.Subject), slog.String("error", result.Error()))
+		return result
+	}
 
-			if err = server.Send(response); err != nil {
-				reset(response)
-				responsePool.Put(response)
-				s.logger.Error("Failed to send response",
+	if !s.acquireSubscription() {
+		logger.Warn("SubscribeAck request rejected, too many concurrent subscriptions",
+			slog.String("subject", request.GetSubject()), slog.Int("limit", s.maxSubscriptions))
+		return status.Error(codes.ResourceExhausted, "too many concurrent subscriptions")
+	}
+	defer s.releaseSubscription()
+
+	var (
+		sub         *nats.Subscription
+		messagesCh  = make(chan *nats.Msg, s.channelBufferSize)
+		done        = make(chan struct{})
+		subject     = request.GetSubject()
+		acks        = make(chan string, s.channelBufferSize)
+		recvErr     = make(chan error, 1)
+		outstanding = make(map[string]*outstandingMessage)
+		ticker      = time.NewTicker(max(s.ackTimeout/4, time.Millisecond))
+	)
+	defer ticker.Stop()
+
+	if sub, err = s.subscribe(ctx, request, messagesCh, done); err != nil {
+		logger.Error("Failed to subscribe",
+			slog.String("topic", subject), slog.String("error", err.Error()))
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	defer s.unsubscribe(logger, sub, done)
+
+	go receiveAcks(ctx, server, acks, recvErr)
+
+	for {
+		// No new message is taken while the outstanding messages are at their limit, acks make room again.
+		incoming := messagesCh
+		if len(outstanding) >= s.channelBufferSize {
+			incoming = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.Error(codes.Canceled, ctx.Err().Error())
+		case err = <-recvErr:
+			if errors.Is(err, io.EOF) {
+				logger.Info("Client closed the ack stream",
+					slog.String("topic", subject), slog.Int("unacked", len(outstanding)))
+				return nil
+			}
+			return err
+		case id := <-acks:
+			s.ack(ctx, logger, outstanding, id)
+		case now := <-ticker.C:
+			if err = s.redeliver(logger, server, outstanding, now); err != nil {
+				logger.Error("Failed to redeliver unacked messages",
 					slog.String("topic", subject), slog.String("error", err.Error()))
 				return status.Error(codes.Internal, err.Error())
 			}
+		case message := <-incoming:
+			var id string
+			if id, err = newMessageId(); err != nil {
+				return status.Error(codes.Internal, fmt.Sprintf("generate message id: %v", err))
+			}
 
-			// Reset and return the response object to the pool.
-			reset(response)
-			responsePool.Put(response)
+			start := time.Now()
+			outstanding[id] = &outstandingMessage{message: message, deadline: start.Add(s.ackTimeout), deliveries: 1}
+			if err = s.send(server, message, id); err != nil {
+				logger.Error("Failed to send response",
+					slog.String("topic", subject), slog.String("error", err.Error()))
+				return status.Error(codes.Internal, err.Error())
+			}
+			if s.metrics != nil {
+				s.metrics.ObserveDelivery(message.Subject, time.Since(start))
+			}
 		}
 	}
 }
+
+// subscribe subscribes to the subject of the request, through JetStream with manual acks when it has an ack
+// wait, and forwards the received messages to messagesCh.
+//
+// A message the stream no longer takes, e.g. while SubscribeAck waits for acks, blocks the NATS callback until
+// done is closed, the message is then dropped so the callback returns once the stream ended.
+//
+// Parameters:
+//   - ctx:        The context of the stream.
+//   - request:    The validated SubscribeRequest.
+//   - messagesCh: The channel receiving the messages of the subscription.
+//   - done:       The channel closed once the stream ended, see unsubscribe.
+//
+// Returns:
+//   - sub: The subscription, unsubscribed by the caller once the stream ends.
+//   - err: An error if the subscription failed.
+func (s *BusService) subscribe(
+	ctx context.Context,
+	request *natsservicev1.SubscribeRequest,
+	messagesCh chan<- *nats.Msg,
+	done <-chan struct{},
+) (sub *nats.Subscription, err error) {
+	var (
+		subject    = request.GetSubject()
+		queueGroup = request.GetQueueGroup()
+		ackWait    = time.Duration(request.GetAckWaitSeconds()) * time.Second
+		handler    = func(msg *nats.Msg) {
+			if s.metrics != nil {
+				s.metrics.ObserveReceive(msg.Subject)
+			}
+			select {
+			case messagesCh <- msg:
+			case <-done:
+			}
+		}
+	)
+
+	if ackWait > 0 {
+		return s.operations.SubscribeAcked(ctx, subject, queueGroup, ackWait, handler)
+	}
+	return s.operations.Subscribe(ctx, subject, queueGroup, handler)
+}
+
+// unsubscribe releases the NATS callbacks blocked on the messages channel by closing done, and removes
+// the subscription.
+//
+// Parameters:
+//   - logger: The logger of the stream.
+//   - sub:    The subscription of the stream.
+//   - done:   The channel passed to subscribe.
+func (s *BusService) unsubscribe(logger *slog.Logger, sub *nats.Subscription, done chan struct{}) {
+	close(done)
+	if err := sub.Unsubscribe(); err != nil {
+		logger.Error("Failed to unsubscribe", slog.String("error", err.Error()))
+	}
+}
+
+// receiveAcks forwards the message ids acked by the client to acks until the stream fails or ends.
+//
+// Parameters:
+//   - ctx:     The context of the stream.
+//   - server:  The gRPC bidirectional streaming interface receiving the acks.
+//   - acks:    The channel receiving the acked message ids.
+//   - recvErr: The channel receiving the error ending the stream, io.EOF once the client closed its side.
+func receiveAcks(
+	ctx context.Context,
+	server grpc.BidiStreamingServer[natsservicev1.SubscribeAckRequest, natsservicev1.SubscribeResponse],
+	acks chan<- string,
+	recvErr chan<- error,
+) {
+	for {
+		request, err := server.Recv()
+		if err != nil {
+			recvErr <- err
+			return
+		}
+		ack := request.GetAck()
+		if ack == nil {
+			recvErr <- status.Error(codes.InvalidArgument, "only acks may follow the subscribe request")
+			return
+		}
+
+		select {
+		case acks <- ack.GetMessageId():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ack removes an acked message from the outstanding messages, and acks it to JetStream when it has a reply
+// subject. Acks of unknown messages, e.g. acked twice, are ignored.
+//
+// Parameters:
+//   - ctx:         The context of the stream.
+//   - logger:      The logger of the stream.
+//   - outstanding: The messages delivered and not acked yet, keyed by message id.
+//   - id:          The message id acked by the client.
+func (s *BusService) ack(
+	ctx context.Context,
+	logger *slog.Logger,
+	outstanding map[string]*outstandingMessage,
+	id string,
+) {
+	entry, exists := outstanding[id]
+	if !exists {
+		logger.Debug("Ignoring the ack of an unknown message", slog.String("message_id", id))
+		return
+	}
+	delete(outstanding, id)
+
+	if reply := entry.message.Reply; reply != "" {
+		if err := s.operations.Publish(ctx, reply, jetStreamAck); err != nil {
+			logger.Warn("Failed to ack the JetStream message, it will be redelivered",
+				slog.String("message_id", id), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// redeliver sends again the outstanding messages whose ack timeout elapsed, with their original message id.
+//
+// Parameters:
+//   - logger:      The logger of the stream.
+//   - server:      The gRPC streaming interface used to send SubscribeResponse messages.
+//   - outstanding: The messages delivered and not acked yet, keyed by message id.
+//   - now:         The current time.
+//
+// Returns:
+//   - err: An error if a message could not be sent, or nil on success.
+func (s *BusService) redeliver(
+	logger *slog.Logger,
+	server responseStream,
+	outstanding map[string]*outstandingMessage,
+	now time.Time,
+) (err error) {
+	for id, entry := range outstanding {
+		if now.Before(entry.deadline) {
+			continue
+		}
+		if err = s.send(server, entry.message, id); err != nil {
+			return fmt.Errorf("redeliver message %s: %w", id, err)
+		}
+		entry.deadline = now.Add(s.ackTimeout)
+		entry.deliveries++
+		logger.Debug("Redelivered an unacked message",
+			slog.String("message_id", id), slog.Int("deliveries", entry.deliveries))
+	}
+	return nil
+}
+
+// send streams a single NATS message to the client.
+//
+// Messages that fit into the configured chunk size are sent as a single response. Larger
+// messages are split into sequential chunks that share a message id, so the client can
+// reassemble the original payload. Every chunk carries the reply subject of the message.
+//
+// Parameters:
+//   - server:    The gRPC streaming interface used to send SubscribeResponse messages.
+//   - message:   The NATS message to deliver.
+//   - messageId: The id of the message, empty to identify only chunked messages by a generated id.
+//
+// Returns:
+//   - err: An error if any of the responses could not be sent, or nil on success.
+func (s *BusService) send(server responseStream, message *nats.Msg, messageId string) (err error) {
+	var (
+		size  = len(message.Data)
+		total = (size + s.chunkSize - 1) / s.chunkSize
+	)
+
+	if total <= 1 {
+		return s.sendChunk(server, message.Subject, message.Reply, message.Data, messageId, 0, 0)
+	}
+
+	if messageId == "" {
+		if messageId, err = newMessageId(); err != nil {
+			return fmt.Errorf("generate message id: %w", err)
+		}
+	}
+
+	for sequence := 0; sequence < total; sequence++ {
+		var (
+			start = sequence * s.chunkSize
+			end   = min(start+s.chunkSize, size)
+		)
+		if err = s.sendChunk(server, message.Subject, message.Reply, message.Data[start:end],
+			messageId, uint32(sequence), uint32(total)); err != nil {
+			return fmt.Errorf("send chunk %d/%d: %w", sequence+1, total, err)
+		}
+	}
+
+	return nil
+}
+
+// sendChunk populates a pooled SubscribeResponse and sends it to the client.
+//
+// Parameters:
+//   - server:    The gRPC streaming interface used to send SubscribeResponse messages.
+//   - subject:   The subject on which the message was received.
+//   - reply:     The subject acking the message (empty for messages without acks).
+//   - data:      The payload (or payload chunk) to send.
+//   - messageId: The id shared by all chunks of a message (empty for unchunked messages of Subscribe).
+//   - sequence:  The zero-based index of the chunk.
+//   - total:     The number of chunks (0 for unchunked messages).
+//
+// Returns:
+//   - err: An error if the response could not be sent, or nil on success.
+func (s *BusService) sendChunk(
+	server responseStream,
+	subject, reply string,
+	data []byte,
+	messageId string,
+	sequence, total uint32,
+) (err error) {
+	// Retrieve a response object from the pool and populate it.
+	response := responsePool.Get().(*natsservicev1.SubscribeResponse)
+	response.Data = data
+	response.Subject = subject
+	response.MessageId = messageId
+	response.Sequence = sequence
+	response.Total = total
+	response.Reply = reply
+
+	err = server.Send(response)
+
+	// Reset and return the response object to the pool.
+	reset(response)
+	responsePool.Put(response)
+	return err
+}
+
+// newMessageId generates a random identifier used to correlate the chunks of a message and to ack it.
+//
+// Returns:
+//   - id:  A hex-encoded random identifier.
+//   - err: An error if the random source could not be read.
+func newMessageId() (id string, err error) {
+	buf := make([]byte, 12)
+	if _, err = rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"log/slog"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -8,15 +10,19 @@ import (
 // Config holds the gRPC server configuration.
 //
 // Fields:
-//   - TLSEnabled: Indicates whether TLS is enabled.
-//   - CertFile:   Path to the TLS certificate file.
-//   - KeyFile:    Path to the TLS key file.
-//   - Port:       Port on which the server listens.
+//   - TLSEnabled:  Indicates whether TLS is enabled.
+//   - CertFile:    Path to the TLS certificate file.
+//   - KeyFile:     Path to the TLS key file.
+//   - Port:        Port on which the server listens.
+//   - Logger:      Base logger of the request-scoped loggers; nil disables the logging interceptors.
+//   - HealthCheck: Reports whether the dependencies of the server are healthy; nil always passes.
 type Config struct {
-	TLSEnabled bool
-	CertFile   string
-	KeyFile    string
-	Port       string
+	TLSEnabled  bool
+	CertFile    string
+	KeyFile     string
+	Port        string
+	Logger      *slog.Logger
+	HealthCheck func() bool
 }
 
 // Option defines a functional option for configuring the server.
@@ -51,6 +57,33 @@ func WithPort(port string) Option {
 	}
 }
 
+// WithRequestLogging installs the logging interceptors, binding the request fields to a logger
+// stored in the request context.
+//
+// Parameters:
+//   - logger: The base logger the request fields are bound to.
+//
+// Returns:
+//   - Option: A functional option that modifies the server configuration.
+func WithRequestLogging(logger *slog.Logger) Option {
+	return func(config *Config) {
+		config.Logger = logger
+	}
+}
+
+// WithHealthCheck makes the health service report NOT_SERVING while check fails, e.g. while NATS is disconnected.
+//
+// Parameters:
+//   - check: Reports whether the dependencies of the server are healthy.
+//
+// Returns:
+//   - Option: A functional option that modifies the server configuration.
+func WithHealthCheck(check func() bool) Option {
+	return func(config *Config) {
+		config.HealthCheck = check
+	}
+}
+
 // NewGRPCServer initializes a new gRPC server with the provided options.
 //
 // Parameters:
@@ -70,15 +103,22 @@ func NewGRPCServer(opts ...Option) (grpcServer *grpc.Server, config *Config, err
 		opt(config)
 	}
 
-	var transportCredentials credentials.TransportCredentials
+	var (
+		transportCredentials credentials.TransportCredentials
+		serverOpts           []grpc.ServerOption
+	)
+	if config.Logger != nil {
+		serverOpts = append(serverOpts,
+			grpc.ChainUnaryInterceptor(UnaryLoggingInterceptor(config.Logger)),
+			grpc.ChainStreamInterceptor(StreamLoggingInterceptor(config.Logger)))
+	}
 	if config.TLSEnabled {
 		if transportCredentials, err = credentials.NewServerTLSFromFile(config.CertFile, config.KeyFile); err != nil {
 			return nil, nil, err
 		}
-		grpcServer = grpc.NewServer(grpc.Creds(transportCredentials))
-	} else {
-		grpcServer = grpc.NewServer()
+		serverOpts = append(serverOpts, grpc.Creds(transportCredentials))
 	}
+	grpcServer = grpc.NewServer(serverOpts...)
 
 	return grpcServer, config, nil
 }
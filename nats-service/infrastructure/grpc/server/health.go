@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	healthv1 "shared/proto/health/gen"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultHealthWatchInterval is how often Watch re-evaluates the serving status of a service.
+const DefaultHealthWatchInterval = time.Second
+
+// HealthServer implements the standard gRPC health checking protocol (grpc.health.v1.Health).
+//
+// The server as a whole ("") and the BusService are reported. A service is SERVING only while the serving
+// status set on the server is SERVING and the health check, e.g. the NATS connection state, passes.
+//
+// Fields:
+//   - mu:       Guards status.
+//   - status:   The serving status set on the server, e.g. NOT_SERVING during shutdown.
+//   - check:    Reports whether the dependencies of the server are healthy; nil always passes.
+//   - interval: How often Watch re-evaluates the serving status.
+type HealthServer struct {
+	healthv1.UnimplementedHealthServer
+	mu       sync.RWMutex
+	status   healthv1.HealthCheckResponse_ServingStatus
+	check    func() bool
+	interval time.Duration
+}
+
+// NewHealthServer creates a new instance of HealthServer, NOT_SERVING until SetServingStatus is called.
+//
+// Parameters:
+//   - check:    Reports whether the dependencies of the server are healthy; nil always passes.
+//   - interval: How often Watch re-evaluates the serving status; non-positive uses DefaultHealthWatchInterval.
+//
+// Returns:
+//   - *HealthServer: A pointer to the newly created HealthServer.
+func NewHealthServer(check func() bool, interval time.Duration) *HealthServer {
+	if interval <= 0 {
+		interval = DefaultHealthWatchInterval
+	}
+	return &HealthServer{
+		status:   healthv1.HealthCheckResponse_NOT_SERVING,
+		check:    check,
+		interval: interval,
+	}
+}
+
+// SetServingStatus sets the serving status of the server, it applies to all reported services.
+//
+// Parameters:
+//   - servingStatus: The new serving status, e.g. NOT_SERVING once a graceful shutdown starts.
+func (h *HealthServer) SetServingStatus(servingStatus healthv1.HealthCheckResponse_ServingStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.status = servingStatus
+}
+
+// Check returns the serving status of the requested service.
+//
+// Parameters:
+//   - ctx:     Context of the request.
+//   - request: The request naming the service, empty for the server as a whole.
+//
+// Returns:
+//   - response: The serving status of the service.
+//   - err:      A NotFound status error if the service is unknown, or nil if successful.
+func (h *HealthServer) Check(
+	ctx context.Context,
+	request *healthv1.HealthCheckRequest,
+) (response *healthv1.HealthCheckResponse, err error) {
+	servingStatus := h.servingStatus(request.GetService())
+	if servingStatus == healthv1.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", request.GetService())
+	}
+	return &healthv1.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch streams the serving status of the requested service, a new status is sent whenever it changes.
+//
+// An unknown service is reported as SERVICE_UNKNOWN instead of failing the stream.
+//
+// Parameters:
+//   - request: The request naming the service, empty for the server as a whole.
+//   - stream:  The stream the statuses are sent to.
+//
+// Returns:
+//   - err: An error if sending fails, or the context error once the client goes away.
+func (h *HealthServer) Watch(
+	request *healthv1.HealthCheckRequest,
+	stream healthv1.Health_WatchServer,
+) (err error) {
+	var (
+		ticker = time.NewTicker(h.interval)
+		last   = healthv1.HealthCheckResponse_ServingStatus(-1)
+	)
+	defer ticker.Stop()
+
+	for {
+		if current := h.servingStatus(request.GetService()); current != last {
+			if err = stream.Send(&healthv1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// servingStatus evaluates the serving status of a service.
+//
+// Parameters:
+//   - service: The name of the service, empty for the server as a whole.
+//
+// Returns:
+//   - healthv1.HealthCheckResponse_ServingStatus: SERVICE_UNKNOWN for an unknown service,
+//     NOT_SERVING if the server is not serving or the health check fails, SERVING otherwise.
+func (h *HealthServer) servingStatus(service string) healthv1.HealthCheckResponse_ServingStatus {
+	if service != "" && service != natsservicev1.BusService_ServiceDesc.ServiceName {
+		return healthv1.HealthCheckResponse_SERVICE_UNKNOWN
+	}
+
+	h.mu.RLock()
+	servingStatus := h.status
+	h.mu.RUnlock()
+
+	if servingStatus == healthv1.HealthCheckResponse_SERVING && h.check != nil && !h.check() {
+		return healthv1.HealthCheckResponse_NOT_SERVING
+	}
+	return servingStatus
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"shared/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// CorrelationIDHeader is the metadata key carrying the caller's correlation id.
+const CorrelationIDHeader = "x-correlation-id"
+
+// UnaryLoggingInterceptor binds the request fields to a logger stored in the request context.
+//
+// Handlers retrieve it with logging.FromContext, so all their log lines include the method, the peer,
+// and the correlation id (when sent by the caller).
+//
+// Parameters:
+//   - logger: The base logger the request fields are bound to.
+//
+// Returns:
+//   - grpc.UnaryServerInterceptor: The interceptor binding the request fields.
+func UnaryLoggingInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		request any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (response any, err error) {
+		return handler(logging.With(ctx, logger, requestFields(ctx, info.FullMethod)...), request)
+	}
+}
+
+// StreamLoggingInterceptor binds the request fields to a logger stored in the stream context.
+//
+// Parameters:
+//   - logger: The base logger the request fields are bound to.
+//
+// Returns:
+//   - grpc.StreamServerInterceptor: The interceptor binding the request fields.
+func StreamLoggingInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx := logging.With(stream.Context(), logger, requestFields(stream.Context(), info.FullMethod)...)
+		return handler(srv, &loggingStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// loggingStream overrides the context of a grpc.ServerStream with the one carrying the scoped logger.
+type loggingStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream context carrying the scoped logger.
+func (s *loggingStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestFields returns the method, peer and correlation id fields of a request.
+//
+// Parameters:
+//   - ctx:    The request context.
+//   - method: The full RPC method name.
+//
+// Returns:
+//   - []any: The fields as slog attributes.
+func requestFields(ctx context.Context, method string) []any {
+	fields := []any{slog.String("method", method)}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields = append(fields, slog.String("peer", p.Addr.String()))
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(CorrelationIDHeader); len(ids) > 0 {
+			fields = append(fields, slog.String("correlation_id", ids[0]))
+		}
+	}
+	return fields
+}
@@ -7,6 +7,8 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"shared/grpc/inprocess"
+	healthv1 "shared/proto/health/gen"
 	natsservicev1 "shared/proto/nats-service/gen"
 	"syscall"
 
@@ -22,12 +24,14 @@ import (
 //   - listener:   The network listener for incoming connections.
 //   - port:       Port on which the server listens.
 //   - env:        The environment in which the server is running (e.g., "prod" or "dev").
+//   - health:     The gRPC health service, SERVING while the server is started and its health check passes.
 //   - logger:     Logger for structured logging of server events.
 type BusServer struct {
 	grpcServer *grpc.Server
 	listener   net.Listener
 	port       string
 	env        string
+	health     *HealthServer
 	logger     *slog.Logger
 }
 
@@ -39,11 +43,16 @@ type BusServer struct {
 //   - certFile: Path to the TLS certificate file (used in "prod").
 //   - keyFile:  Path to the TLS key file (used in "prod").
 //   - logger:   Logger instance for logging.
+//   - opts:     Additional server options, e.g. WithHealthCheck.
 //
 // Returns:
 //   - busServer: A pointer to the newly created BusServer.
 //   - err:       An error if server creation fails, or nil if successful.
-func NewBusServer(env, port, certFile, keyFile string, logger *slog.Logger) (busServer *BusServer, err error) {
+func NewBusServer(
+	env, port, certFile, keyFile string,
+	logger *slog.Logger,
+	opts ...Option,
+) (busServer *BusServer, err error) {
 	var (
 		grpcServer   *grpc.Server
 		serverConfig *Config
@@ -53,14 +62,14 @@ func NewBusServer(env, port, certFile, keyFile string, logger *slog.Logger) (bus
 
 	switch env {
 	case "dev":
-		grpcServer, serverConfig, err = NewGRPCServer(WithPort(port))
+		opts = append([]Option{WithPort(port), WithRequestLogging(logger)}, opts...)
 	case "prod":
-		grpcServer, serverConfig, err = NewGRPCServer(WithTLS(certFile, keyFile), WithPort(port))
+		opts = append([]Option{WithTLS(certFile, keyFile), WithPort(port), WithRequestLogging(logger)}, opts...)
 	default:
 		return nil, errors.New("unsupported environment; must be \"prod\" or \"dev\"")
 	}
 
-	if err != nil {
+	if grpcServer, serverConfig, err = NewGRPCServer(opts...); err != nil {
 		return nil, fmt.Errorf("create gRPC server: %w", err)
 	}
 
@@ -68,13 +77,71 @@ func NewBusServer(env, port, certFile, keyFile string, logger *slog.Logger) (bus
 		return nil, fmt.Errorf("create gRPC listener: %w", err)
 	}
 
+	return newBusServer(grpcServer, serverConfig, listener, port, env, logger), nil
+}
+
+// NewInProcessBusServer creates a new instance of BusServer serving co-located clients in the same process.
+//
+// The server listens on an in-memory listener registered under name instead of a network socket, so clients
+// dial it with an in-process address (see inprocess.Address) and no TLS is involved.
+//
+// Parameters:
+//   - name:   The name the in-process listener is registered under.
+//   - logger: Logger instance for logging.
+//   - opts:   Additional server options, e.g. WithHealthCheck.
+//
+// Returns:
+//   - busServer: A pointer to the newly created BusServer.
+//   - err:       An error if the name is already registered, or nil if successful.
+func NewInProcessBusServer(name string, logger *slog.Logger, opts ...Option) (busServer *BusServer, err error) {
+	var (
+		grpcServer   *grpc.Server
+		serverConfig *Config
+		listener     net.Listener
+	)
+
+	opts = append([]Option{WithRequestLogging(logger)}, opts...)
+	if grpcServer, serverConfig, err = NewGRPCServer(opts...); err != nil {
+		return nil, fmt.Errorf("create gRPC server: %w", err)
+	}
+
+	if listener, err = inprocess.Listen(name); err != nil {
+		return nil, fmt.Errorf("create in-process gRPC listener: %w", err)
+	}
+
+	return newBusServer(grpcServer, serverConfig, listener, "", "inprocess", logger), nil
+}
+
+// newBusServer creates a BusServer and registers the gRPC health service with it.
+//
+// Parameters:
+//   - grpcServer:   The gRPC server instance.
+//   - serverConfig: The configuration the gRPC server was created with.
+//   - listener:     The listener for incoming connections.
+//   - port:         Port on which the server listens, empty in-process.
+//   - env:          The environment in which the server is running.
+//   - logger:       Logger instance for logging.
+//
+// Returns:
+//   - *BusServer: A pointer to the newly created BusServer.
+func newBusServer(
+	grpcServer *grpc.Server,
+	serverConfig *Config,
+	listener net.Listener,
+	port, env string,
+	logger *slog.Logger,
+) *BusServer {
+	health := NewHealthServer(serverConfig.HealthCheck, DefaultHealthWatchInterval)
+	healthv1.RegisterHealthServer(grpcServer, health)
+
 	return &BusServer{
 		grpcServer: grpcServer,
 		listener:   listener,
 		port:       port,
 		env:        env,
+		health:     health,
 		logger:     logger,
-	}, nil
+	}
 }
 
 // RegisterService registers the BusService with the gRPC server.
@@ -85,11 +152,21 @@ func (s *BusServer) RegisterService(service natsservicev1.BusServiceServer) {
 	natsservicev1.RegisterBusServiceServer(s.grpcServer, service)
 }
 
+// SetServingStatus sets the status reported by the gRPC health service, e.g. NOT_SERVING once a graceful
+// shutdown starts, so that probes stop routing traffic before the server stops.
+//
+// Parameters:
+//   - servingStatus: The new serving status.
+func (s *BusServer) SetServingStatus(servingStatus healthv1.HealthCheckResponse_ServingStatus) {
+	s.health.SetServingStatus(servingStatus)
+}
+
 // Start begins serving incoming gRPC requests.
 //
-// It starts the server in a separate goroutine.
+// It starts the server in a separate goroutine and reports it as SERVING.
 func (s *BusServer) Start() {
 	s.logger.Info("Starting the Bus gRPC server...", "address", s.listener.Addr(), "env", s.env)
+	s.SetServingStatus(healthv1.HealthCheckResponse_SERVING)
 	go func() {
 		if err := s.grpcServer.Serve(s.listener); err != nil {
 			s.logger.Error("Bus gRPC server failed to serve", "error", err)
@@ -100,7 +177,7 @@ func (s *BusServer) Start() {
 
 // WaitForShutdown gracefully shuts down the gRPC server upon receiving termination signals.
 //
-// It waits for a shutdown signal and then gracefully stops the server.
+// It waits for a shutdown signal, reports the server as NOT_SERVING and then gracefully stops it.
 func (s *BusServer) WaitForShutdown() {
 	var (
 		signalChan = make(chan os.Signal, 1)
@@ -115,6 +192,7 @@ func (s *BusServer) WaitForShutdown() {
 		defer close(signalChan)
 
 		s.logger.Info("Received shutdown signal. Initiating shutdown...", "signal", sig)
+		s.SetServingStatus(healthv1.HealthCheckResponse_NOT_SERVING)
 		s.grpcServer.GracefulStop()
 		s.logger.Info("Bus gRPC server stopped gracefully")
 
@@ -124,7 +202,8 @@ func (s *BusServer) WaitForShutdown() {
 	<-done
 }
 
-// GracefulStop stops the gRPC server gracefully.
+// GracefulStop reports the server as NOT_SERVING and stops the gRPC server gracefully.
 func (s *BusServer) GracefulStop() {
+	s.SetServingStatus(healthv1.HealthCheckResponse_NOT_SERVING)
 	s.grpcServer.GracefulStop()
 }
@@ -0,0 +1,55 @@
+package logfile
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"shared/logging"
+)
+
+// NewLogger creates a logger appending to the log file at path, its directory is created when missing.
+//
+// When the directory or the file cannot be used, e.g. in a read-only or restricted environment, the logger
+// writes to fallback instead and its first record is a warning naming the failure, so the service still starts
+// and the reason the log file is missing is visible.
+//
+// Parameters:
+//   - path:     The path of the log file.
+//   - fallback: The writer used when the log file is unusable, typically os.Stdout.
+//   - options:  The handler options, e.g., the format and whether records carry their source.
+//
+// Returns:
+//   - *slog.Logger: The logger writing to the log file, or to fallback.
+func NewLogger(path string, fallback io.Writer, options logging.HandlerOptions) *slog.Logger {
+	var (
+		file *os.File
+		err  error
+	)
+	if file, err = open(path); err != nil {
+		logger := slog.New(logging.NewHandler(fallback, options))
+		logger.Warn("Log file is unusable, logging to the fallback output instead",
+			slog.String("path", path), slog.String("error", err.Error()))
+		return logger
+	}
+	return slog.New(logging.NewHandler(file, options))
+}
+
+// open creates the directory of the log file when missing and opens the file for appending.
+//
+// Parameters:
+//   - path: The path of the log file.
+//
+// Returns:
+//   - file: The opened log file.
+//   - err:  An error if the directory could not be created or the file could not be opened.
+func open(path string) (file *os.File, err error) {
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	if file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err != nil {
+		return nil, fmt.Errorf("open log file: %w", err)
+	}
+	return file, nil
+}
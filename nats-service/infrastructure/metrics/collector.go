@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"errors"
 	"log/slog"
 	metricsCollectors "nats-service/infrastructure/metrics/collectors"
 	"reflect"
@@ -22,21 +23,61 @@ type Provider struct {
 	logger     *slog.Logger
 }
 
+// ProviderOption configures optional Provider behavior.
+type ProviderOption func(*providerOptions)
+
+// providerOptions holds the settings applied by ProviderOption values.
+//
+// Fields:
+//   - registry: Registry to register collectors on, or nil to create a private one.
+type providerOptions struct {
+	registry *prometheus.Registry
+}
+
+// WithRegistry makes the Provider register its collectors on an externally owned registry,
+// so metrics from other components registered there are exposed alongside them.
+//
+// Parameters:
+//   - registry: The shared Prometheus registry.
+//
+// Returns:
+//   - ProviderOption: Option applying the registry.
+func WithRegistry(registry *prometheus.Registry) ProviderOption {
+	return func(o *providerOptions) {
+		o.registry = registry
+	}
+}
+
 // NewProvider creates and initializes a new Provider instance.
 //
 // Parameters:
 //   - namespace: A string namespace for metrics to avoid naming collisions.
 //   - logger:    Logger instance for structured logging.
+//   - opts:      Optional settings such as WithRegistry.
 //
 // Returns:
 //   - *Provider: Initialized Provider instance with registered collectors.
-func NewProvider(namespace string, logger *slog.Logger) *Provider {
-	registry := prometheus.NewRegistry()
+func NewProvider(namespace string, logger *slog.Logger, opts ...ProviderOption) *Provider {
+	options := providerOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	registry := options.registry
+	if registry == nil {
+		registry = prometheus.NewRegistry()
+	}
 
-	// Add default prometheus collectors
-	registry.MustRegister(
+	// Add default prometheus collectors; a shared registry may already carry them
+	for _, collector := range []prometheus.Collector{
 		collectors.NewGoCollector(),
-		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if err := registry.Register(collector); err != nil && !errors.As(err, &alreadyRegistered) {
+			logger.Error("Default collector registration failed", slog.String("error", err.Error()))
+		}
+	}
 
 	allCollectors := []metricsCollectors.Collector{
 		metricsCollectors.NewRuntimeMetrics(namespace, logger),
@@ -68,10 +109,14 @@ func (p *Provider) StartCollectors(interval time.Duration) {
 	p.logger.Info("Metrics collectors started")
 }
 
-// Stop gracefully terminates all metric collectors.
+// Stop gracefully terminates all metric collectors and joins their goroutines.
+//
+// Parameters:
+//   - timeout: Duration bounding the shutdown of all collectors together.
 func (p *Provider) Stop(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
 	for _, collector := range p.Collectors {
-		collector.StopWithTimeout(timeout)
+		collector.StopWithTimeout(time.Until(deadline))
 	}
 	p.logger.Info("Metrics collectors stopped")
 }
@@ -2,7 +2,9 @@ package collectors
 
 import (
 	"log/slog"
+	"math"
 	"runtime"
+	"slices"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -10,9 +12,12 @@ import (
 
 // HeapMetrics collects detailed Go heap and stack memory usage metrics.
 //
-// Purpose: Periodically captures heap, stack, and garbage collection statistics
+// Purpose: Periodically captures heap, stack, goroutine and garbage collection statistics
 // from the Go runtime and exposes them as Prometheus metrics.
 //
+// GC pause quantiles are computed over the rolling window of the most recent pauses kept
+// by the runtime (up to 256), so they follow the current behavior rather than the process lifetime.
+//
 // Fields:
 //   - BaseCollector: Embeds lifecycle management functionalities.
 //   - metrics:       Map holding Prometheus Gauges for each metric.
@@ -62,6 +67,10 @@ func (h *HeapMetrics) InitMetrics(registry *prometheus.Registry) (err error) {
 
 		"num_gc":        "Number of completed GC cycles",
 		"num_forced_gc": "Number of forced GC cycles",
+
+		"goroutines":           "Number of goroutines that currently exist",
+		"gc_pause_p50_seconds": "Median GC pause over the recent GC cycles",
+		"gc_pause_p99_seconds": "99th percentile GC pause over the recent GC cycles",
 	}
 
 	for name, help := range metricDefs {
@@ -81,7 +90,7 @@ func (h *HeapMetrics) InitMetrics(registry *prometheus.Registry) (err error) {
 	return nil
 }
 
-// Start initiates periodic collection and update of heap metrics.
+// Start collects the heap metrics right away, then periodically until the collector is stopped.
 //
 // Parameters:
 //   - interval: Interval at which metrics are collected and updated.
@@ -89,6 +98,8 @@ func (h *HeapMetrics) Start(interval time.Duration) {
 	h.wg.Add(1)
 	go func() {
 		defer h.wg.Done()
+		// Collect at once, so the exposed metrics are not left unset until the first tick
+		h.collectMetrics()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
@@ -121,4 +132,43 @@ func (h *HeapMetrics) collectMetrics() {
 
 	h.metrics["num_gc"].Set(float64(mem.NumGC))
 	h.metrics["num_forced_gc"].Set(float64(mem.NumForcedGC))
+
+	h.metrics["goroutines"].Set(float64(runtime.NumGoroutine()))
+
+	pauses := recentPauses(&mem)
+	h.metrics["gc_pause_p50_seconds"].Set(pauseQuantile(pauses, 0.50).Seconds())
+	h.metrics["gc_pause_p99_seconds"].Set(pauseQuantile(pauses, 0.99).Seconds())
+}
+
+// recentPauses returns the sorted GC pauses kept in the runtime circular buffer.
+//
+// Parameters:
+//   - mem: Memory statistics read from the Go runtime.
+//
+// Returns:
+//   - []time.Duration: The recent GC pauses in ascending order, empty if no GC happened yet.
+func recentPauses(mem *runtime.MemStats) []time.Duration {
+	count := min(int(mem.NumGC), len(mem.PauseNs))
+	pauses := make([]time.Duration, 0, count)
+	for i := 0; i < count; i++ {
+		pauses = append(pauses, time.Duration(mem.PauseNs[i]))
+	}
+	slices.Sort(pauses)
+	return pauses
+}
+
+// pauseQuantile returns the nearest-rank quantile of sorted GC pauses.
+//
+// Parameters:
+//   - pauses:   GC pauses in ascending order.
+//   - quantile: Quantile in the (0, 1] range.
+//
+// Returns:
+//   - time.Duration: The pause at the quantile, zero if there are no pauses.
+func pauseQuantile(pauses []time.Duration, quantile float64) time.Duration {
+	if len(pauses) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(quantile*float64(len(pauses)))) - 1
+	return pauses[max(rank, 0)]
 }
@@ -69,7 +69,7 @@ func (r *RuntimeMetrics) InitMetrics(registry *prometheus.Registry) (err error)
 	return nil
 }
 
-// Start initiates periodic collection of runtime metrics.
+// Start collects the runtime metrics right away, then periodically until the collector is stopped.
 //
 // Parameters:
 //   - interval: Interval for updating runtime metrics.
@@ -77,6 +77,8 @@ func (r *RuntimeMetrics) Start(interval time.Duration) {
 	r.wg.Add(1)
 	go func() {
 		defer r.wg.Done()
+		// Collect at once, so the exposed metrics are not left unset until the first tick
+		r.collectMetrics()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
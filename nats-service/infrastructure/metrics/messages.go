@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OtherSubject is the subject label of the messages whose subject is not in the allowlist.
+const OtherSubject = "other"
+
+// MessageMetrics exposes Prometheus metrics describing the messages passing through the bus, labeled by subject.
+//
+// Purpose: Gives per-subject visibility of the message traffic. Only allowlisted subjects get their own label,
+// every other subject falls into the OtherSubject bucket, which keeps the label cardinality bounded.
+//
+// Fields:
+//   - published:        Counter of published messages by subject.
+//   - received:         Counter of messages received from NATS subscriptions by subject.
+//   - publishDuration:  Histogram of the publish durations by subject.
+//   - deliveryDuration: Histogram of the durations of streaming a received message to the client by subject.
+//   - subjects:         Set of the allowlisted subjects.
+type MessageMetrics struct {
+	published        *prometheus.CounterVec
+	received         *prometheus.CounterVec
+	publishDuration  *prometheus.HistogramVec
+	deliveryDuration *prometheus.HistogramVec
+	subjects         map[string]struct{}
+}
+
+// NewMessageMetrics creates a new instance of MessageMetrics.
+//
+// Parameters:
+//   - namespace: Prefix namespace applied to all message metric names.
+//   - subjects:  Allowlisted subjects labeled individually; others are labeled as OtherSubject.
+//
+// Returns:
+//   - *MessageMetrics: Pointer to the initialized MessageMetrics instance.
+func NewMessageMetrics(namespace string, subjects []string) *MessageMetrics {
+	m := &MessageMetrics{subjects: make(map[string]struct{}, len(subjects))}
+	for _, subject := range subjects {
+		m.subjects[subject] = struct{}{}
+	}
+
+	m.published = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "published_total",
+		Help:      "Total number of published messages by subject.",
+	}, []string{"subject"})
+	m.received = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "received_total",
+		Help:      "Total number of messages received from subscriptions by subject.",
+	}, []string{"subject"})
+	m.publishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "publish_duration_seconds",
+		Help:      "Duration of publishing a message by subject.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject"})
+	m.deliveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "messages",
+		Name:      "delivery_duration_seconds",
+		Help:      "Duration of streaming a received message to the subscriber by subject.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"subject"})
+
+	return m
+}
+
+// Register registers the message metrics with the given registerer.
+//
+// Parameters:
+//   - registerer: Prometheus registerer to register the message metrics.
+//
+// Returns:
+//   - err: Error encountered during registration; nil on success.
+func (m *MessageMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range []prometheus.Collector{m.published, m.received, m.publishDuration, m.deliveryDuration} {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register message metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// SubjectLabel returns the label value of the subject.
+//
+// Parameters:
+//   - subject: The NATS subject of the message.
+//
+// Returns:
+//   - string: The subject itself if allowlisted, otherwise OtherSubject.
+func (m *MessageMetrics) SubjectLabel(subject string) string {
+	if _, ok := m.subjects[subject]; ok {
+		return subject
+	}
+	return OtherSubject
+}
+
+// ObservePublish records a published message and the duration of its publishing.
+//
+// Parameters:
+//   - subject:  The NATS subject of the message.
+//   - duration: The duration of the publish operation.
+func (m *MessageMetrics) ObservePublish(subject string, duration time.Duration) {
+	label := m.SubjectLabel(subject)
+	m.published.WithLabelValues(label).Inc()
+	m.publishDuration.WithLabelValues(label).Observe(duration.Seconds())
+}
+
+// ObserveReceive records a message received from a subscription.
+//
+// Parameters:
+//   - subject: The NATS subject of the message.
+func (m *MessageMetrics) ObserveReceive(subject string) {
+	m.received.WithLabelValues(m.SubjectLabel(subject)).Inc()
+}
+
+// ObserveDelivery records the duration of streaming a received message to the subscriber.
+//
+// Parameters:
+//   - subject:  The NATS subject of the message.
+//   - duration: The duration of the delivery.
+func (m *MessageMetrics) ObserveDelivery(subject string, duration time.Duration) {
+	m.deliveryDuration.WithLabelValues(m.SubjectLabel(subject)).Observe(duration.Seconds())
+}
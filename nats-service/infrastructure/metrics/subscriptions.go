@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SubscriptionMetrics exposes Prometheus metrics describing the Subscribe streams of the bus.
+//
+// Purpose: Shows how close the server is to its concurrent subscriptions limit, and how many
+// streams were turned away once it was reached.
+//
+// Fields:
+//   - active:   Gauge of the currently open Subscribe streams.
+//   - rejected: Counter of the Subscribe streams rejected by the concurrent subscriptions limit.
+type SubscriptionMetrics struct {
+	active   prometheus.Gauge
+	rejected prometheus.Counter
+}
+
+// NewSubscriptionMetrics creates a new instance of SubscriptionMetrics.
+//
+// Parameters:
+//   - namespace: Prefix namespace applied to all subscription metric names.
+//
+// Returns:
+//   - *SubscriptionMetrics: Pointer to the initialized SubscriptionMetrics instance.
+func NewSubscriptionMetrics(namespace string) *SubscriptionMetrics {
+	return &SubscriptionMetrics{
+		active: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "subscriptions",
+			Name:      "active",
+			Help:      "Number of currently open Subscribe streams.",
+		}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "subscriptions",
+			Name:      "rejected_total",
+			Help:      "Total number of Subscribe streams rejected by the concurrent subscriptions limit.",
+		}),
+	}
+}
+
+// Register registers the subscription metrics with the given registerer.
+//
+// Parameters:
+//   - registerer: Prometheus registerer to register the subscription metrics.
+//
+// Returns:
+//   - err: Error encountered during registration; nil on success.
+func (m *SubscriptionMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range []prometheus.Collector{m.active, m.rejected} {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register subscription metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetActive records the number of currently open Subscribe streams.
+//
+// Parameters:
+//   - count: The number of open streams.
+func (m *SubscriptionMetrics) SetActive(count int) {
+	m.active.Set(float64(count))
+}
+
+// ObserveRejected records a Subscribe stream rejected by the concurrent subscriptions limit.
+func (m *SubscriptionMetrics) ObserveRejected() {
+	m.rejected.Inc()
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"nats-service/application/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseCollectInterval verifies that invalid collection intervals fall back to the default
+// and valid ones are used as configured.
+func TestParseCollectInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "valid seconds", value: "10s", expected: time.Duration(10) * time.Second},
+		{name: "valid milliseconds", value: "250ms", expected: time.Duration(250) * time.Millisecond},
+		{name: "surrounding spaces", value: " 1m ", expected: time.Minute},
+		{name: "empty", value: "", expected: config.DefaultCollectInterval},
+		{name: "malformed", value: "often", expected: config.DefaultCollectInterval},
+		{name: "missing unit", value: "10", expected: config.DefaultCollectInterval},
+		{name: "zero", value: "0s", expected: config.DefaultCollectInterval},
+		{name: "negative", value: "-5s", expected: config.DefaultCollectInterval},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, config.ParseCollectInterval(test.value))
+		})
+	}
+}
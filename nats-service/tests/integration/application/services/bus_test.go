@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
+	"nats-service/tests/integration/corefake"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -39,3 +42,254 @@ func TestOperations_Subscribe_Publish(t *testing.T) {
 		t.Fatal("Did not receive message in time")
 	}
 }
+
+// TestOperations_SubscribeUntilDone verifies that canceling the context unsubscribes the subscription.
+func TestOperations_SubscribeUntilDone(t *testing.T) {
+	container := SetupTestContainer()
+	ops := container.Operations.Get()
+
+	subject := "test.subscribe.until.done"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, cleanup, err := ops.SubscribeUntilDone(ctx, subject, "", func(msg *nats.Msg) {})
+	require.NoError(t, err, "Failed to subscribe to subject")
+	require.NotNil(t, cleanup, "Expected a cleanup function")
+	defer cleanup()
+	require.True(t, sub.IsValid(), "Expected the subscription to be active")
+
+	cancel()
+	require.Eventually(t, func() bool { return !sub.IsValid() },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Expected the subscription to be removed")
+}
+
+// TestOperations_SubscribeAcked verifies under JetStream ack semantics that a message left unacked by a
+// subscriber crashing before its save is redelivered to the next subscriber of the queue group, and that an
+// acked message is not redelivered. It is skipped when the broker has no JetStream.
+func TestOperations_SubscribeAcked(t *testing.T) {
+	if corefake.Enabled() {
+		t.Skip("The core NATS fake has no JetStream")
+	}
+	container := SetupTestContainer()
+	ops := container.Operations.Get()
+	conn, err := container.NatsClient.Get().Connect()
+	require.NoError(t, err, "Failed to connect to NATS")
+	js, err := conn.JetStream()
+	require.NoError(t, err, "Failed to create JetStream context")
+	if _, err = js.AccountInfo(); err != nil {
+		t.Skipf("JetStream is not available: %v", err)
+	}
+
+	var (
+		stream     = "TEST_ACKED"
+		subject    = "test.acked.subject"
+		queueGroup = "test-acked"
+		ackWait    = time.Second
+		data       = []byte("acked message")
+	)
+	_, err = js.AddStream(&nats.StreamConfig{Name: stream, Subjects: []string{subject}})
+	require.NoError(t, err, "Failed to create stream")
+	t.Cleanup(func() { _ = js.DeleteStream(stream) })
+
+	// The first subscriber crashes before its save: it receives the message but never acks it
+	crashed := make(chan *nats.Msg, 1)
+	sub, err := ops.SubscribeAcked(context.Background(), subject, queueGroup, ackWait, func(msg *nats.Msg) {
+		crashed <- msg
+	})
+	require.NoError(t, err, "Failed to subscribe to subject")
+	_, err = js.Publish(subject, data)
+	require.NoError(t, err, "Failed to publish message")
+	select {
+	case msg := <-crashed:
+		assert.Equal(t, data, msg.Data, "Received message does not match published data")
+	case <-time.After(time.Duration(2) * time.Second):
+		t.Fatal("Did not receive message in time")
+	}
+	require.NoError(t, sub.Unsubscribe(), "Failed to unsubscribe the crashed subscriber")
+
+	// The next subscriber receives the message again once the ack wait elapsed, and acks it
+	received := make(chan *nats.Msg, 2)
+	sub, err = ops.SubscribeAcked(context.Background(), subject, queueGroup, ackWait, func(msg *nats.Msg) {
+		received <- msg
+		_ = msg.Ack()
+	})
+	require.NoError(t, err, "Failed to resubscribe to subject")
+	defer func() { _ = sub.Unsubscribe() }()
+	select {
+	case msg := <-received:
+		assert.Equal(t, data, msg.Data, "Redelivered message does not match published data")
+		metadata, metadataErr := msg.Metadata()
+		require.NoError(t, metadataErr, "Failed to read message metadata")
+		assert.Equal(t, uint64(2), metadata.NumDelivered, "Expected the message to be redelivered")
+	case <-time.After(time.Duration(5) * time.Second):
+		t.Fatal("Message was not redelivered")
+	}
+	select {
+	case <-received:
+		t.Fatal("Acked message was redelivered")
+	case <-time.After(2 * ackWait):
+	}
+}
+
+// TestOperations_SubscribeAcked_Consumers verifies that an ephemeral acked subscription does not replay the
+// messages stored before it, and that resubscribing a queue group with another ack wait updates its durable
+// consumer. It is skipped when the broker has no JetStream.
+func TestOperations_SubscribeAcked_Consumers(t *testing.T) {
+	if corefake.Enabled() {
+		t.Skip("The core NATS fake has no JetStream")
+	}
+	container := SetupTestContainer()
+	ops := container.Operations.Get()
+	conn, err := container.NatsClient.Get().Connect()
+	require.NoError(t, err, "Failed to connect to NATS")
+	js, err := conn.JetStream()
+	require.NoError(t, err, "Failed to create JetStream context")
+	if _, err = js.AccountInfo(); err != nil {
+		t.Skipf("JetStream is not available: %v", err)
+	}
+
+	var (
+		stream     = "TEST_ACKED_CONSUMERS"
+		subject    = "test.acked.consumers"
+		queueGroup = "test-acked-consumers"
+	)
+	_, err = js.AddStream(&nats.StreamConfig{Name: stream, Subjects: []string{subject}})
+	require.NoError(t, err, "Failed to create stream")
+	t.Cleanup(func() { _ = js.DeleteStream(stream) })
+
+	_, err = js.Publish(subject, []byte("stored before the subscription"))
+	require.NoError(t, err, "Failed to publish the stored message")
+
+	received := make(chan *nats.Msg, 2)
+	sub, err := ops.SubscribeAcked(context.Background(), subject, "", time.Second, func(msg *nats.Msg) {
+		received <- msg
+		_ = msg.Ack()
+	})
+	require.NoError(t, err, "Failed to subscribe to subject")
+	defer func() { _ = sub.Unsubscribe() }()
+
+	data := []byte("published after the subscription")
+	_, err = js.Publish(subject, data)
+	require.NoError(t, err, "Failed to publish message")
+	select {
+	case msg := <-received:
+		assert.Equal(t, data, msg.Data, "Expected only the message published after the subscription")
+	case <-time.After(time.Duration(2) * time.Second):
+		t.Fatal("Did not receive message in time")
+	}
+
+	for _, ackWait := range []time.Duration{time.Second, time.Duration(3) * time.Second} {
+		durable, subErr := ops.SubscribeAcked(context.Background(), subject, queueGroup, ackWait,
+			func(msg *nats.Msg) { _ = msg.Ack() })
+		require.NoError(t, subErr, "Failed to subscribe the queue group")
+		require.NoError(t, durable.Unsubscribe(), "Failed to unsubscribe the queue group")
+
+		info, infoErr := js.ConsumerInfo(stream, queueGroup)
+		require.NoError(t, infoErr, "Failed to look up the durable consumer")
+		assert.Equal(t, ackWait, info.Config.AckWait, "Expected the consumer ack wait to follow the subscription")
+	}
+}
+
+// TestOperations_SubscribeDurable verifies that a durable subscription creates its stream, redelivers a message
+// whose handler failed, and receives the messages published while no subscriber was connected.
+func TestOperations_SubscribeDurable(t *testing.T) {
+	if corefake.Enabled() {
+		t.Skip("The core NATS fake has no JetStream")
+	}
+	container := SetupTestContainer()
+	ops := container.Operations.Get()
+	conn, err := container.NatsClient.Get().Connect()
+	require.NoError(t, err, "Failed to connect to NATS")
+	js, err := conn.JetStream()
+	require.NoError(t, err, "Failed to create JetStream context")
+	if _, err = js.AccountInfo(); err != nil {
+		t.Skipf("JetStream is not available: %v", err)
+	}
+
+	var (
+		stream      = "TEST_DURABLE_SUBJECT"
+		subject     = "test.durable.subject"
+		durableName = "test-durable"
+		first       = []byte("first message")
+		second      = []byte("second message")
+		received    = make(chan *nats.Msg, 4)
+		failures    atomic.Int32
+	)
+	t.Cleanup(func() { _ = js.DeleteStream(stream) })
+	failures.Store(1)
+	handler := func(msg *nats.Msg) error {
+		received <- msg
+		if failures.Add(-1) >= 0 {
+			return errors.New("handler failed")
+		}
+		return nil
+	}
+	receive := func(data []byte, delivered uint64) {
+		select {
+		case msg := <-received:
+			assert.Equal(t, data, msg.Data, "Received message does not match published data")
+			metadata, metadataErr := msg.Metadata()
+			require.NoError(t, metadataErr, "Failed to read message metadata")
+			assert.Equal(t, delivered, metadata.NumDelivered, "Unexpected number of deliveries")
+		case <-time.After(time.Duration(5) * time.Second):
+			t.Fatal("Did not receive message in time")
+		}
+	}
+
+	// The failed first delivery is redelivered right away
+	sub, err := ops.SubscribeDurable(context.Background(), subject, durableName, handler)
+	require.NoError(t, err, "Failed to subscribe to subject")
+	info, err := js.StreamInfo(stream)
+	require.NoError(t, err, "Expected the stream to be created")
+	assert.Equal(t, []string{subject}, info.Config.Subjects)
+	_, err = js.Publish(subject, first)
+	require.NoError(t, err, "Failed to publish message")
+	receive(first, 1)
+	receive(first, 2)
+	require.NoError(t, sub.Unsubscribe(), "Failed to unsubscribe")
+
+	// A message published without a subscriber is persisted and delivered to the next one
+	_, err = js.Publish(subject, second)
+	require.NoError(t, err, "Failed to publish message")
+	sub, err = ops.SubscribeDurable(context.Background(), subject, durableName, handler)
+	require.NoError(t, err, "Failed to resubscribe to subject")
+	defer func() { _ = sub.Unsubscribe() }()
+	receive(second, 1)
+	select {
+	case msg := <-received:
+		t.Fatalf("Unexpected delivery of an acked message: %s", msg.Data)
+	case <-time.After(time.Duration(500) * time.Millisecond):
+	}
+}
+
+// TestOperations_PublishAcked verifies that a JetStream publish creates the stream capturing the subject and
+// returns once the message is persisted. It is skipped when the broker has no JetStream.
+func TestOperations_PublishAcked(t *testing.T) {
+	if corefake.Enabled() {
+		t.Skip("The core NATS fake has no JetStream")
+	}
+	container := SetupTestContainer()
+	ops := container.Operations.Get()
+	conn, err := container.NatsClient.Get().Connect()
+	require.NoError(t, err, "Failed to connect to NATS")
+	js, err := conn.JetStream()
+	require.NoError(t, err, "Failed to create JetStream context")
+	if _, err = js.AccountInfo(); err != nil {
+		t.Skipf("JetStream is not available: %v", err)
+	}
+
+	var (
+		stream  = "TEST_ACKED_SUBJECT"
+		subject = "test.acked.subject"
+		data    = []byte("persisted message")
+	)
+	t.Cleanup(func() { _ = js.DeleteStream(stream) })
+
+	require.NoError(t, ops.PublishAcked(context.Background(), subject, data), "Failed to publish message")
+	require.NoError(t, ops.PublishAcked(context.Background(), subject, data), "Failed to publish message")
+
+	info, err := js.StreamInfo(stream)
+	require.NoError(t, err, "Expected the stream to be created")
+	assert.Equal(t, []string{subject}, info.Config.Subjects)
+	assert.Equal(t, uint64(2), info.State.Msgs, "Expected both messages to be persisted")
+}
@@ -4,8 +4,10 @@ import (
 	"log"
 	"log/slog"
 	"nats-service/application/services"
-	"nats-service/domain/entities"
 	"nats-service/infrastructure/broker"
+	"nats-service/infrastructure/metrics"
+	"nats-service/tests/integration/corefake"
+	"net"
 	"os"
 	"shared/dependency"
 	"time"
@@ -18,6 +20,11 @@ type TestContainer struct {
 	Logger     dependency.LazyDependency[*slog.Logger]
 	NatsClient dependency.LazyDependency[*broker.Client]
 	Operations dependency.LazyDependency[*services.Operations]
+
+	MetricsAddress  dependency.LazyDependency[string]
+	MetricsProvider dependency.LazyDependency[*metrics.Provider]
+	MetricsServer   dependency.LazyDependency[*metrics.Server]
+	MetricsService  dependency.LazyDependency[*services.MetricsService]
 }
 
 // NewTestContainer initializes a new test container.
@@ -45,7 +52,7 @@ func NewTestContainer() *TestContainer {
 					log.Println("Disconnected from NATS due to", err)
 				}
 			)
-			if address, err = entities.GetBroker().Address(); err != nil {
+			if address, err = corefake.Address(); err != nil {
 				panic(err)
 			}
 
@@ -74,6 +81,39 @@ func NewTestContainer() *TestContainer {
 			return services.NewOperations(conn, logger)
 		},
 	}
+	c.MetricsAddress = dependency.LazyDependency[string]{
+		InitFunc: func() string {
+			// Reserve a free local port for the metrics server.
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				panic(err)
+			}
+			defer func() { _ = listener.Close() }()
+			return listener.Addr().String()
+		},
+	}
+	c.MetricsProvider = dependency.LazyDependency[*metrics.Provider]{
+		InitFunc: func() *metrics.Provider {
+			return metrics.NewProvider("test", c.Logger.Get())
+		},
+	}
+	c.MetricsServer = dependency.LazyDependency[*metrics.Server]{
+		InitFunc: func() *metrics.Server {
+			return metrics.NewServer(c.MetricsAddress.Get(), c.MetricsProvider.Get(), c.Logger.Get())
+		},
+	}
+	c.MetricsService = dependency.LazyDependency[*services.MetricsService]{
+		InitFunc: func() *services.MetricsService {
+			var (
+				logger   = c.Logger.Get()
+				provider = c.MetricsProvider.Get()
+				server   = c.MetricsServer.Get()
+				interval = time.Duration(10) * time.Millisecond
+				timeout  = time.Duration(2) * time.Second
+			)
+			return services.NewMetricsService(provider, server, logger, interval, timeout)
+		},
+	}
 
 	return c
 }
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"nats-service/application/services"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetricsService_Stop verifies that Stop halts the collectors and the HTTP server within the shutdown timeout.
+func TestMetricsService_Stop(t *testing.T) {
+	var (
+		container = SetupTestContainer()
+		service   = container.MetricsService.Get()
+		healthURL = "http://" + container.MetricsAddress.Get() + "/health"
+		client    = &http.Client{Timeout: time.Second}
+	)
+
+	service.Start()
+	require.Eventually(t, func() bool {
+		response, err := client.Get(healthURL)
+		if err != nil {
+			return false
+		}
+		_ = response.Body.Close()
+		return response.StatusCode == http.StatusOK
+	}, time.Duration(2)*time.Second, time.Duration(20)*time.Millisecond, "Metrics server did not start")
+
+	start := time.Now()
+	require.NoError(t, service.Stop(context.Background()), "Expected the metrics service to stop cleanly")
+	assert.Less(t, time.Since(start), time.Duration(2)*time.Second, "Expected Stop to finish within the timeout")
+
+	for _, collector := range service.GetCollectors() {
+		done, ok := collector.(interface{ Done() <-chan struct{} })
+		require.True(t, ok, "Expected the collector to expose its lifecycle")
+		select {
+		case <-done.Done():
+		default:
+			t.Fatalf("Collector %T still running after Stop", collector)
+		}
+	}
+
+	_, err := client.Get(healthURL)
+	assert.Error(t, err, "Expected the metrics server to be closed after Stop")
+}
+
+// TestMetricsService_CollectorsStarted verifies that starting the service starts the collectors, which expose
+// collected values right away instead of after the first interval, and that Stop stops them.
+func TestMetricsService_CollectorsStarted(t *testing.T) {
+	var (
+		container = SetupTestContainer()
+		provider  = container.MetricsProvider.Get()
+		service   = services.NewMetricsService(provider, container.MetricsServer.Get(), container.Logger.Get(),
+			time.Hour, time.Duration(2)*time.Second)
+	)
+	require.Zero(t, gaugeValue(t, provider.Registry, "test_heap_alloc_bytes"), "Expected no collection before Start")
+
+	service.Start()
+	require.Eventually(t, func() bool {
+		return gaugeValue(t, provider.Registry, "test_heap_alloc_bytes") > 0 &&
+			gaugeValue(t, provider.Registry, "test_heap_objects") > 0
+	}, time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Collectors did not collect on start")
+
+	require.NoError(t, service.Stop(context.Background()), "Expected the metrics service to stop cleanly")
+	for _, collector := range service.GetCollectors() {
+		done, ok := collector.(interface{ Done() <-chan struct{} })
+		require.True(t, ok, "Expected the collector to expose its lifecycle")
+		select {
+		case <-done.Done():
+		default:
+			t.Fatalf("Collector %T still running after Stop", collector)
+		}
+	}
+}
+
+// gaugeValue returns the value of the gauge with the given name in the registry, or 0 if it is not found.
+func gaugeValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() == name && len(family.GetMetric()) > 0 {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,39 @@
+package corefake
+
+import (
+	"nats-service/domain/entities"
+	"os"
+	"strconv"
+	"sync"
+)
+
+var (
+	shared     *Server
+	sharedOnce sync.Once
+	sharedErr  error
+)
+
+// Enabled reports whether NATS_CORE_FAKE is set to true, so the tests use the core NATS fake
+// instead of the externally provisioned one.
+func Enabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("NATS_CORE_FAKE"))
+	return err == nil && enabled
+}
+
+// Address returns the NATS address the integration tests connect to.
+//
+// With NATS_CORE_FAKE=true the fake is started once per test binary and its URL is returned,
+// otherwise the configured broker address is used.
+func Address() (address string, err error) {
+	if !Enabled() {
+		return entities.GetBroker().Address()
+	}
+
+	sharedOnce.Do(func() {
+		shared, sharedErr = Start()
+	})
+	if sharedErr != nil {
+		return "", sharedErr
+	}
+	return shared.URL(), nil
+}
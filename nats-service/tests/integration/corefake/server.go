@@ -0,0 +1,300 @@
+package corefake
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// info is the INFO line sent to every client, it advertises neither TLS, auth, nor headers.
+const info = `INFO {"server_id":"embedded","server_name":"embedded","version":"2.10.0","proto":1,` +
+	`"max_payload":1048576,"headers":false}` + "\r\n"
+
+// Server is a core-only NATS fake for integration tests, it is not a real nats-server.
+//
+// The nats-server module is not a dependency of this repository, so Server implements the subset of the
+// client protocol used by nats.go for core NATS: CONNECT, PING/PONG, PUB, SUB (with queue groups and
+// the * and > wildcards) and UNSUB (with a max message count). There is no JetStream, auth, TLS,
+// headers, or clustering, so tests of those paths must skip when the fake is enabled.
+type Server struct {
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[*client]struct{} // conns holds the connected clients.
+	next     map[string]int       // next holds the next member index by subject and queue group.
+	wg       sync.WaitGroup
+}
+
+// Start starts a server on a free local port.
+func Start() (server *Server, err error) {
+	var listener net.Listener
+	if listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		return nil, fmt.Errorf("embedded NATS listen: %w", err)
+	}
+
+	server = &Server{
+		listener: listener,
+		conns:    make(map[*client]struct{}),
+		next:     make(map[string]int),
+	}
+	server.wg.Add(1)
+	go server.accept()
+	return server, nil
+}
+
+// URL returns the nats:// URL clients connect to.
+func (s *Server) URL() string {
+	return "nats://" + s.listener.Addr().String()
+}
+
+// Shutdown closes the listener and every client connection.
+func (s *Server) Shutdown() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		_ = c.conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// accept serves incoming connections until the listener is closed.
+func (s *Server) accept() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &client{conn: conn, subs: make(map[string]*subscription)}
+		s.mu.Lock()
+		s.conns[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(c)
+		}()
+	}
+}
+
+// serve reads the protocol lines of a client until it disconnects.
+func (s *Server) serve(c *client) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, c)
+		s.mu.Unlock()
+		_ = c.conn.Close()
+	}()
+
+	if err := c.write([]byte(info)); err != nil {
+		return
+	}
+
+	reader := bufio.NewReader(c.conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "CONNECT", "PONG":
+		case "PING":
+			err = c.write([]byte("PONG\r\n"))
+		case "SUB":
+			err = s.subscribe(c, fields[1:])
+		case "UNSUB":
+			err = s.unsubscribe(c, fields[1:])
+		case "PUB":
+			err = s.publish(reader, fields[1:])
+		default:
+			err = fmt.Errorf("unknown protocol operation %q", fields[0])
+		}
+		if err != nil {
+			_ = c.write([]byte(fmt.Sprintf("-ERR '%s'\r\n", err.Error())))
+			return
+		}
+	}
+}
+
+// subscribe handles SUB <subject> [queue group] <sid>.
+func (s *Server) subscribe(c *client, args []string) error {
+	sub := &subscription{client: c}
+	switch len(args) {
+	case 2:
+		sub.subject, sub.sid = args[0], args[1]
+	case 3:
+		sub.subject, sub.queue, sub.sid = args[0], args[1], args[2]
+	default:
+		return errors.New("invalid SUB arguments")
+	}
+
+	s.mu.Lock()
+	c.subs[sub.sid] = sub
+	s.mu.Unlock()
+	return nil
+}
+
+// unsubscribe handles UNSUB <sid> [max msgs].
+//
+// Without a max the subscription is removed immediately, with one it is removed once it has received max
+// messages in total, which nats.go relies on for AutoUnsubscribe and old-style requests.
+func (s *Server) unsubscribe(c *client, args []string) (err error) {
+	var limit int
+	switch len(args) {
+	case 1:
+	case 2:
+		if limit, err = strconv.Atoi(args[1]); err != nil || limit < 0 {
+			return errors.New("invalid UNSUB max")
+		}
+	default:
+		return errors.New("invalid UNSUB arguments")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := c.subs[args[0]]
+	if !ok {
+		return nil
+	}
+	if limit == 0 || sub.delivered >= limit {
+		delete(c.subs, sub.sid)
+		return nil
+	}
+	sub.max = limit
+	return nil
+}
+
+// publish handles PUB <subject> [reply-to] <size> followed by the payload.
+func (s *Server) publish(reader *bufio.Reader, args []string) (err error) {
+	var (
+		subject, reply string
+		size           int
+	)
+	switch len(args) {
+	case 2:
+		subject = args[0]
+		size, err = strconv.Atoi(args[1])
+	case 3:
+		subject, reply = args[0], args[1]
+		size, err = strconv.Atoi(args[2])
+	default:
+		return errors.New("invalid PUB arguments")
+	}
+	if err != nil || size < 0 {
+		return errors.New("invalid PUB size")
+	}
+
+	payload := make([]byte, size+2)
+	if _, err = io.ReadFull(reader, payload); err != nil {
+		return err
+	}
+	payload = payload[:size]
+
+	for _, sub := range s.receivers(subject) {
+		_ = sub.client.write(message(sub, subject, reply, payload))
+	}
+	return nil
+}
+
+// receivers returns every plain subscription matching the subject and one member of each queue group.
+//
+// Each returned subscription is counted as delivered, and removed once it reaches its UNSUB max.
+func (s *Server) receivers(subject string) (receivers []*subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make(map[string][]*subscription)
+	for c := range s.conns {
+		for _, sub := range c.subs {
+			if !matches(sub.subject, subject) {
+				continue
+			}
+			if sub.queue == "" {
+				receivers = append(receivers, sub)
+				continue
+			}
+			groups[sub.queue] = append(groups[sub.queue], sub)
+		}
+	}
+	for queue, members := range groups {
+		key := subject + " " + queue
+		receivers = append(receivers, members[s.next[key]%len(members)])
+		s.next[key]++
+	}
+	for _, sub := range receivers {
+		sub.delivered++
+		if sub.max > 0 && sub.delivered >= sub.max {
+			delete(sub.client.subs, sub.sid)
+		}
+	}
+	return receivers
+}
+
+// message encodes a MSG delivery for the subscription.
+func message(sub *subscription, subject, reply string, payload []byte) []byte {
+	var header string
+	if reply == "" {
+		header = fmt.Sprintf("MSG %s %s %d\r\n", subject, sub.sid, len(payload))
+	} else {
+		header = fmt.Sprintf("MSG %s %s %s %d\r\n", subject, sub.sid, reply, len(payload))
+	}
+
+	data := make([]byte, 0, len(header)+len(payload)+2)
+	data = append(data, header...)
+	data = append(data, payload...)
+	return append(data, "\r\n"...)
+}
+
+// matches reports whether the subject matches the subscription pattern, with the * and > wildcards.
+func matches(pattern, subject string) bool {
+	var (
+		patternTokens = strings.Split(pattern, ".")
+		subjectTokens = strings.Split(subject, ".")
+	)
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
+
+// client is a connected client.
+type client struct {
+	conn    net.Conn
+	writeMu sync.Mutex               // writeMu serializes the writes to conn.
+	subs    map[string]*subscription // subs holds the subscriptions by sid, guarded by Server.mu.
+}
+
+// write sends data to the client.
+func (c *client) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(data)
+	return err
+}
+
+// subscription is a single SUB of a client.
+type subscription struct {
+	client    *client
+	subject   string
+	queue     string
+	sid       string
+	max       int // max is the UNSUB message limit, zero when there is none.
+	delivered int // delivered counts the messages sent to the subscription.
+}
@@ -0,0 +1,105 @@
+package corefake
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServer_PublishSubscribe verifies that nats.go connects to the fake and that a published
+// message is delivered to plain, wildcard, and queue group subscribers.
+func TestServer_PublishSubscribe(t *testing.T) {
+	server, err := Start()
+	require.NoError(t, err, "Failed to start the fake")
+	t.Cleanup(server.Shutdown)
+
+	conn, err := nats.Connect(server.URL(), nats.Timeout(time.Duration(2)*time.Second))
+	require.NoError(t, err, "Failed to connect to the fake")
+	t.Cleanup(conn.Close)
+
+	var (
+		plain    = make(chan *nats.Msg, 1)
+		wildcard = make(chan *nats.Msg, 1)
+		queue    = make(chan *nats.Msg, 2)
+		subject  = "test.corefake.roundtrip"
+		data     = []byte("Hello fake Nats")
+	)
+	_, err = conn.ChanSubscribe(subject, plain)
+	require.NoError(t, err, "Failed to subscribe")
+	_, err = conn.ChanSubscribe("test.corefake.>", wildcard)
+	require.NoError(t, err, "Failed to subscribe with a wildcard")
+	for i := 0; i < 2; i++ {
+		_, err = conn.ChanQueueSubscribe(subject, "workers", queue)
+		require.NoError(t, err, "Failed to subscribe to the queue group")
+	}
+	require.NoError(t, conn.Flush(), "Failed to flush the subscriptions")
+
+	require.NoError(t, conn.Publish(subject, data), "Failed to publish")
+	require.NoError(t, conn.Flush(), "Failed to flush the publish")
+
+	for name, ch := range map[string]chan *nats.Msg{"plain": plain, "wildcard": wildcard, "queue": queue} {
+		select {
+		case msg := <-ch:
+			require.Equal(t, subject, msg.Subject, "Subject mismatch for the %s subscriber", name)
+			require.Equal(t, data, msg.Data, "Data mismatch for the %s subscriber", name)
+		case <-time.After(time.Duration(2) * time.Second):
+			t.Fatalf("The %s subscriber did not receive the message", name)
+		}
+	}
+	select {
+	case <-queue:
+		t.Fatal("The queue group received the message more than once")
+	case <-time.After(time.Duration(100) * time.Millisecond):
+	}
+}
+
+// TestServer_AutoUnsubscribe verifies that UNSUB with a max keeps the subscription until it has received max
+// messages, which AutoUnsubscribe and request/reply depend on.
+func TestServer_AutoUnsubscribe(t *testing.T) {
+	server, err := Start()
+	require.NoError(t, err, "Failed to start the fake")
+	t.Cleanup(server.Shutdown)
+
+	conn, err := nats.Connect(server.URL(), nats.Timeout(time.Duration(2)*time.Second))
+	require.NoError(t, err, "Failed to connect to the fake")
+	t.Cleanup(conn.Close)
+
+	var (
+		ch      = make(chan *nats.Msg, 5)
+		subject = "test.corefake.auto"
+	)
+	sub, err := conn.ChanSubscribe(subject, ch)
+	require.NoError(t, err, "Failed to subscribe")
+	require.NoError(t, sub.AutoUnsubscribe(2), "Failed to auto unsubscribe")
+	require.NoError(t, conn.Flush(), "Failed to flush the subscription")
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, conn.Publish(subject, []byte("message")), "Failed to publish")
+	}
+	require.NoError(t, conn.Flush(), "Failed to flush the publishes")
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-ch:
+		case <-time.After(time.Duration(2) * time.Second):
+			t.Fatalf("Message %d was not delivered before the max", i+1)
+		}
+	}
+	select {
+	case <-ch:
+		t.Fatal("A message was delivered after the max was reached")
+	case <-time.After(time.Duration(100) * time.Millisecond):
+	}
+
+	responder, err := conn.Subscribe("test.corefake.echo", func(msg *nats.Msg) {
+		_ = msg.Respond(msg.Data)
+	})
+	require.NoError(t, err, "Failed to subscribe the responder")
+	t.Cleanup(func() { _ = responder.Unsubscribe() })
+
+	reply, err := conn.Request("test.corefake.echo", []byte("ping"), time.Duration(2)*time.Second)
+	require.NoError(t, err, "Failed to receive the reply")
+	require.Equal(t, []byte("ping"), reply.Data, "Reply data mismatch")
+}
@@ -0,0 +1,270 @@
+package harness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ErrPublishFailed is returned by MockOperations.Publish while failures are injected.
+var ErrPublishFailed = errors.New("mock operations: publish failed")
+
+// MockOperations is an in-memory replacement of services.Operations, no NATS server is involved.
+//
+// Published messages are delivered synchronously to every plain subscriber of the exact subject,
+// and to a single member of each queue group, so a slow subscriber applies backpressure to Publish.
+// Messages delivered to acked subscriptions mimic JetStream: they carry a reply subject, publishing to it acks
+// the message, and unacked messages are redelivered once the ack wait elapsed.
+type MockOperations struct {
+	mu        sync.Mutex
+	subs      map[string][]*mockSubscription // subs holds the active subscriptions by subject.
+	next      map[string]int                 // next holds the next member index by subject and queue group.
+	failures  int                            // failures is the number of upcoming Publish calls that fail.
+	published int                            // published is the number of successful Publish calls.
+	pending   map[string]*pendingAck         // pending holds the unacked messages by reply subject.
+	acks      int                            // acks is the number of acked messages.
+	sequence  int                            // sequence numbers the reply subjects.
+}
+
+// mockSubscription is a single subscription of MockOperations.
+type mockSubscription struct {
+	queueGroup string
+	ackWait    time.Duration // ackWait is the redelivery delay of unacked messages, 0 for plain subscriptions.
+	handler    func(message *nats.Msg)
+}
+
+// pendingAck is a message delivered to an acked subscription and not acked yet.
+type pendingAck struct {
+	subject    string
+	queueGroup string
+	data       []byte
+	timer      *time.Timer
+}
+
+// ackPrefix is the prefix of the reply subjects acking a message.
+const ackPrefix = "$JS.ACK.mock."
+
+// NewMockOperations creates a new instance of MockOperations.
+func NewMockOperations() *MockOperations {
+	return &MockOperations{
+		subs:    make(map[string][]*mockSubscription),
+		next:    make(map[string]int),
+		pending: make(map[string]*pendingAck),
+	}
+}
+
+// FailPublishes makes the next n Publish calls fail with ErrPublishFailed.
+func (o *MockOperations) FailPublishes(n int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failures = n
+}
+
+// Published returns the number of successful Publish calls.
+func (o *MockOperations) Published() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.published
+}
+
+// Acks returns the number of acked messages.
+func (o *MockOperations) Acks() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.acks
+}
+
+// Unacked returns the number of delivered messages waiting for their ack.
+func (o *MockOperations) Unacked() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Subscribers returns the number of active subscriptions of the subject.
+func (o *MockOperations) Subscribers(subject string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.subs[subject])
+}
+
+// Publish delivers data to the subscribers of the subject, or acks the message whose reply subject it is.
+func (o *MockOperations) Publish(ctx context.Context, subject string, data []byte) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+
+	o.mu.Lock()
+	if o.failures > 0 {
+		o.failures--
+		o.mu.Unlock()
+		return ErrPublishFailed
+	}
+	o.published++
+	if strings.HasPrefix(subject, ackPrefix) {
+		if pending, ok := o.pending[subject]; ok {
+			pending.timer.Stop()
+			delete(o.pending, subject)
+			o.acks++
+		}
+		o.mu.Unlock()
+		return nil
+	}
+	messages := o.deliveries(o.receivers(subject, nil), subject, data)
+	o.mu.Unlock()
+
+	for _, message := range messages {
+		message.handler(message.msg)
+	}
+	return nil
+}
+
+// PublishAcked behaves like Publish, the in-memory delivery stands in for the JetStream persistence.
+func (o *MockOperations) PublishAcked(ctx context.Context, subject string, data []byte) (err error) {
+	return o.Publish(ctx, subject, data)
+}
+
+// delivery is a message to pass to the handler of a subscription.
+type delivery struct {
+	handler func(message *nats.Msg)
+	msg     *nats.Msg
+}
+
+// deliveries prepares the messages of the receivers, registering the acks of acked subscriptions.
+// The caller holds mu.
+func (o *MockOperations) deliveries(receivers []*mockSubscription, subject string, data []byte) (list []delivery) {
+	for _, sub := range receivers {
+		msg := &nats.Msg{Subject: subject, Data: data}
+		if sub.ackWait > 0 {
+			o.sequence++
+			msg.Reply = fmt.Sprintf("%s%d", ackPrefix, o.sequence)
+			o.track(msg.Reply, &pendingAck{subject: subject, queueGroup: sub.queueGroup, data: data},
+				sub.ackWait)
+		}
+		list = append(list, delivery{handler: sub.handler, msg: msg})
+	}
+	return list
+}
+
+// track registers the pending ack of reply and arms its redelivery, the caller holds mu.
+func (o *MockOperations) track(reply string, pending *pendingAck, ackWait time.Duration) {
+	o.pending[reply] = pending
+	pending.timer = time.AfterFunc(ackWait, func() { o.redeliver(reply, ackWait) })
+}
+
+// redeliver delivers an unacked message again to a member of its queue group, possibly another one.
+// Without a subscriber the message stays pending until the next ack wait elapsed.
+func (o *MockOperations) redeliver(reply string, ackWait time.Duration) {
+	o.mu.Lock()
+	pending, ok := o.pending[reply]
+	if !ok {
+		o.mu.Unlock()
+		return
+	}
+	receivers := o.receivers(pending.subject, func(sub *mockSubscription) bool {
+		return sub.ackWait > 0 && sub.queueGroup == pending.queueGroup
+	})
+	if len(receivers) == 0 {
+		pending.timer.Reset(ackWait)
+		o.mu.Unlock()
+		return
+	}
+	sub := receivers[0]
+	o.track(reply, pending, sub.ackWait)
+	o.mu.Unlock()
+
+	sub.handler(&nats.Msg{Subject: pending.subject, Data: pending.data, Reply: reply})
+}
+
+// receivers picks the subscriptions receiving the next message of the subject among the ones accepted by filter,
+// a nil filter accepts every subscription. The caller holds mu.
+func (o *MockOperations) receivers(
+	subject string,
+	filter func(sub *mockSubscription) bool,
+) (receivers []*mockSubscription) {
+	groups := make(map[string][]*mockSubscription)
+	for _, sub := range o.subs[subject] {
+		if filter != nil && !filter(sub) {
+			continue
+		}
+		if sub.queueGroup == "" {
+			receivers = append(receivers, sub)
+			continue
+		}
+		groups[sub.queueGroup] = append(groups[sub.queueGroup], sub)
+	}
+	for group, members := range groups {
+		key := subject + "\x00" + group
+		receivers = append(receivers, members[o.next[key]%len(members)])
+		o.next[key]++
+	}
+	return receivers
+}
+
+// Subscribe registers handler for the subject until the subscription's context is done.
+//
+// The returned subscription is not bound to a connection, unsubscribing it reports an error that
+// BusService only logs; the handler is removed once ctx is done.
+func (o *MockOperations) Subscribe(
+	ctx context.Context,
+	subject, queueGroup string,
+	handler func(message *nats.Msg),
+) (sub *nats.Subscription, err error) {
+	return o.subscribe(ctx, subject, queueGroup, 0, handler)
+}
+
+// SubscribeAcked registers handler like Subscribe, its messages carry a reply subject and are redelivered
+// to the queue group when they are not acked within ackWait, also after the subscription is gone.
+func (o *MockOperations) SubscribeAcked(
+	ctx context.Context,
+	subject, queueGroup string,
+	ackWait time.Duration,
+	handler func(message *nats.Msg),
+) (sub *nats.Subscription, err error) {
+	return o.subscribe(ctx, subject, queueGroup, ackWait, handler)
+}
+
+// subscribe registers handler for the subject until ctx is done, a positive ackWait makes it acked.
+func (o *MockOperations) subscribe(
+	ctx context.Context,
+	subject, queueGroup string,
+	ackWait time.Duration,
+	handler func(message *nats.Msg),
+) (sub *nats.Subscription, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	subscription := &mockSubscription{queueGroup: queueGroup, ackWait: ackWait, handler: handler}
+	o.mu.Lock()
+	o.subs[subject] = append(o.subs[subject], subscription)
+	o.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		o.remove(subject, subscription)
+	}()
+	return &nats.Subscription{Subject: subject, Queue: queueGroup}, nil
+}
+
+// remove drops the subscription of the subject.
+func (o *MockOperations) remove(subject string, subscription *mockSubscription) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	subs := o.subs[subject]
+	for i, sub := range subs {
+		if sub == subscription {
+			o.subs[subject] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(o.subs[subject]) == 0 {
+		delete(o.subs, subject)
+	}
+}
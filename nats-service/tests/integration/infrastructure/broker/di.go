@@ -3,8 +3,8 @@ package broker
 import (
 	"log"
 	"log/slog"
-	"nats-service/domain/entities"
 	"nats-service/infrastructure/broker"
+	"nats-service/tests/integration/corefake"
 	"os"
 	"shared/dependency"
 	"time"
@@ -43,7 +43,7 @@ func NewTestContainer() *TestContainer {
 					log.Println("Disconnected from NATS due to: ", cErr)
 				}
 			)
-			if address, err = entities.GetBroker().Address(); err != nil {
+			if address, err = corefake.Address(); err != nil {
 				panic(err)
 			}
 
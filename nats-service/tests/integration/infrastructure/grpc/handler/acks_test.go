@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"nats-service/infrastructure/grpc/handler"
+	"nats-service/tests/integration/harness"
+	"runtime"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ackStream is the client side of a SubscribeAck stream.
+type ackStream = grpc.BidiStreamingClient[natsservicev1.SubscribeAckRequest, natsservicev1.SubscribeResponse]
+
+// openAckStream opens a SubscribeAck stream on request and waits until its subscription is registered.
+func openAckStream(
+	ctx context.Context,
+	t *testing.T,
+	bus *harness.Harness,
+	request *natsservicev1.SubscribeRequest,
+) ackStream {
+	t.Helper()
+
+	stream, err := bus.Client.SubscribeAck(ctx)
+	require.NoError(t, err, "Failed to open the ack stream")
+	require.NoError(t, stream.Send(&natsservicev1.SubscribeAckRequest{
+		Frame: &natsservicev1.SubscribeAckRequest_Subscribe{Subscribe: request},
+	}), "Failed to send the subscribe request")
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(request.GetSubject()) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscription not registered")
+	return stream
+}
+
+// sendAck acks the message with id on stream.
+func sendAck(t *testing.T, stream ackStream, id string) {
+	t.Helper()
+
+	require.NoError(t, stream.Send(&natsservicev1.SubscribeAckRequest{
+		Frame: &natsservicev1.SubscribeAckRequest_Ack{Ack: &natsservicev1.Ack{MessageId: id}},
+	}), "Failed to send the ack of %s", id)
+}
+
+// receiveAsync receives the next response of stream in the background.
+func receiveAsync(stream ackStream) <-chan *natsservicev1.SubscribeResponse {
+	received := make(chan *natsservicev1.SubscribeResponse, 1)
+	go func() {
+		if response, err := stream.Recv(); err == nil {
+			received <- response
+		}
+	}()
+	return received
+}
+
+// TestHarness_SubscribeAck verifies through the in-process harness that every message of a SubscribeAck stream
+// carries an id, that unacked messages are delivered again with the same id once the ack timeout elapsed while
+// acked ones are not, and that closing the client side ends the stream.
+func TestHarness_SubscribeAck(t *testing.T) {
+	var (
+		bus     = harness.New(t, handler.WithAckTimeout(time.Duration(200)*time.Millisecond))
+		subject = "test.ack"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+
+	stream := openAckStream(ctx, t, bus, &natsservicev1.SubscribeRequest{Subject: subject})
+
+	ids := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		request := &natsservicev1.PublishRequest{Subject: subject, Data: []byte(fmt.Sprintf("message-%d", i))}
+		_, err := bus.Client.Publish(ctx, request)
+		require.NoError(t, err, "Failed to publish message %d", i)
+
+		response, err := stream.Recv()
+		require.NoError(t, err, "Failed to receive message %d", i)
+		require.Equal(t, fmt.Sprintf("message-%d", i), string(response.GetData()), "Messages out of order")
+		require.NotEmpty(t, response.GetMessageId(), "Expected a message id")
+		ids = append(ids, response.GetMessageId())
+	}
+	require.NotEqual(t, ids[0], ids[1], "Expected distinct message ids")
+
+	// Only the unacked message is delivered again.
+	sendAck(t, stream, ids[0])
+	response, err := stream.Recv()
+	require.NoError(t, err, "Failed to receive the redelivered message")
+	require.Equal(t, ids[1], response.GetMessageId(), "Expected the unacked message to be redelivered")
+	require.Equal(t, "message-1", string(response.GetData()), "Expected the payload of the unacked message")
+
+	sendAck(t, stream, ids[1])
+	sendAck(t, stream, "unknown")
+	received := receiveAsync(stream)
+	select {
+	case response = <-received:
+		require.Failf(t, "Unexpected delivery", "Acked message %s delivered again", response.GetMessageId())
+	case <-time.After(time.Duration(600) * time.Millisecond):
+	}
+
+	_, err = bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: []byte("message-2")})
+	require.NoError(t, err, "Failed to publish message 2")
+	select {
+	case response = <-received:
+		require.Equal(t, "message-2", string(response.GetData()), "Expected the new message")
+		sendAck(t, stream, response.GetMessageId())
+	case <-ctx.Done():
+		require.Fail(t, "New message not delivered")
+	}
+
+	require.NoError(t, stream.CloseSend(), "Failed to close the client side")
+	_, err = stream.Recv()
+	require.ErrorIs(t, err, io.EOF, "Expected the stream to end once the client side is closed")
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(subject) == 0 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscription not removed")
+}
+
+// TestHarness_SubscribeAckLimit verifies that no message is delivered while the outstanding messages are at
+// the channel buffer size, and that an ack makes room for the next one.
+func TestHarness_SubscribeAckLimit(t *testing.T) {
+	var (
+		bus = harness.New(t, handler.WithChannelBufferSize(2),
+			handler.WithAckTimeout(time.Duration(1)*time.Minute))
+		subject = "test.ack.limit"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+
+	stream := openAckStream(ctx, t, bus, &natsservicev1.SubscribeRequest{Subject: subject})
+	for i := 0; i < 3; i++ {
+		request := &natsservicev1.PublishRequest{Subject: subject, Data: []byte(fmt.Sprintf("message-%d", i))}
+		_, err := bus.Client.Publish(ctx, request)
+		require.NoError(t, err, "Failed to publish message %d", i)
+	}
+
+	var first *natsservicev1.SubscribeResponse
+	for i := 0; i < 2; i++ {
+		response, err := stream.Recv()
+		require.NoError(t, err, "Failed to receive message %d", i)
+		if first == nil {
+			first = response
+		}
+	}
+
+	received := receiveAsync(stream)
+	select {
+	case response := <-received:
+		require.Failf(t, "Unexpected delivery", "Message %q delivered beyond the limit", response.GetData())
+	case <-time.After(time.Duration(300) * time.Millisecond):
+	}
+
+	sendAck(t, stream, first.GetMessageId())
+	select {
+	case response := <-received:
+		require.Equal(t, "message-2", string(response.GetData()), "Expected the next message once acked")
+	case <-ctx.Done():
+		require.Fail(t, "Message not delivered after the ack")
+	}
+}
+
+// TestHarness_SubscribeAckLimitRelease verifies that ending a stream whose outstanding messages are at their limit
+// releases the NATS callback blocked on the messages channel, so no goroutine is left behind.
+func TestHarness_SubscribeAckLimitRelease(t *testing.T) {
+	var (
+		bus = harness.New(t, handler.WithChannelBufferSize(1),
+			handler.WithAckTimeout(time.Duration(1)*time.Minute))
+		subject = "test.ack.release"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+
+	// The first RPC sets up the in-memory connection, its goroutines are part of the baseline.
+	_, err := bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: "test.warmup", Data: []byte("data")})
+	require.NoError(t, err, "Failed to publish the warmup message")
+	baseline := runtime.NumGoroutine()
+
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	stream := openAckStream(streamCtx, t, bus, &natsservicev1.SubscribeRequest{Subject: subject})
+
+	// One message is outstanding, one fills the messages channel, and the callback blocks on the third one.
+	_, err = bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: []byte("message-0")})
+	require.NoError(t, err, "Failed to publish message 0")
+	_, err = stream.Recv()
+	require.NoError(t, err, "Failed to receive message 0")
+	_, err = bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: []byte("message-1")})
+	require.NoError(t, err, "Failed to publish message 1")
+
+	// Published on the operations directly, the blocked callback does not hold an RPC open.
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- bus.Operations.Publish(ctx, subject, []byte("message-2"))
+	}()
+	select {
+	case <-blocked:
+		require.Fail(t, "Expected the callback to block while the outstanding messages are at their limit")
+	case <-time.After(time.Duration(200) * time.Millisecond):
+	}
+
+	streamCancel()
+	select {
+	case err = <-blocked:
+		require.NoError(t, err, "Failed to publish message 2")
+	case <-ctx.Done():
+		require.Fail(t, "Callback still blocked after the stream ended")
+	}
+	// Polled inline, require.Eventually runs its condition in goroutines of its own.
+	deadline := time.Now().Add(time.Duration(2) * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Duration(10) * time.Millisecond)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), baseline, "Goroutines leaked by the ended stream")
+}
+
+// TestHarness_SubscribeAckJetStream verifies that a SubscribeAck stream with an ack wait acks the JetStream
+// message once the client acked it.
+func TestHarness_SubscribeAckJetStream(t *testing.T) {
+	var (
+		bus     = harness.New(t)
+		subject = "test.ack.jetstream"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+
+	stream := openAckStream(ctx, t, bus, &natsservicev1.SubscribeRequest{Subject: subject, AckWaitSeconds: 10})
+	_, err := bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: []byte("data")})
+	require.NoError(t, err, "Failed to publish message")
+
+	response, err := stream.Recv()
+	require.NoError(t, err, "Failed to receive message")
+	require.NotEmpty(t, response.GetReply(), "Expected the reply subject of the JetStream message")
+	require.Zero(t, bus.Operations.Acks(), "Expected no ack before the client acked")
+
+	sendAck(t, stream, response.GetMessageId())
+	require.Eventually(t, func() bool { return bus.Operations.Acks() == 1 && bus.Operations.Unacked() == 0 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "JetStream message not acked")
+}
+
+// TestHarness_SubscribeAckValidation verifies that a SubscribeAck stream must start with a valid subscribe
+// request.
+func TestHarness_SubscribeAckValidation(t *testing.T) {
+	bus := harness.New(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		request *natsservicev1.SubscribeAckRequest
+	}{
+		{
+			name: "ack first",
+			request: &natsservicev1.SubscribeAckRequest{
+				Frame: &natsservicev1.SubscribeAckRequest_Ack{Ack: &natsservicev1.Ack{MessageId: "id"}},
+			},
+		},
+		{
+			name: "empty subject",
+			request: &natsservicev1.SubscribeAckRequest{
+				Frame: &natsservicev1.SubscribeAckRequest_Subscribe{Subscribe: &natsservicev1.SubscribeRequest{}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stream, err := bus.Client.SubscribeAck(ctx)
+			require.NoError(t, err, "Opening the stream should not fail")
+			require.NoError(t, stream.Send(test.request), "Failed to send the first request")
+			_, err = stream.Recv()
+			require.Equal(t, codes.InvalidArgument, status.Code(err), "Expected the stream to be rejected")
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"nats-service/infrastructure/grpc/handler"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBusService_ChannelBufferSize verifies that subscriptions use the configured channel buffer size
+// and that non-positive sizes fall back to the default.
+func TestBusService_ChannelBufferSize(t *testing.T) {
+	container := NewTestContainer()
+
+	tests := []struct {
+		name     string
+		size     int
+		expected int
+	}{
+		{name: "configured", size: 1024, expected: 1024},
+		{name: "zero falls back to default", size: 0, expected: handler.DefaultChannelBufferSize},
+		{name: "negative falls back to default", size: -1, expected: handler.DefaultChannelBufferSize},
+		{name: "capped at maximum", size: handler.MaxChannelBufferSize + 1, expected: handler.MaxChannelBufferSize},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			busService := handler.NewBusService(nil, container.Validator.Get(), container.Logger.Get(),
+				handler.WithChannelBufferSize(test.size))
+			assert.Equal(t, test.expected, busService.ChannelBufferSize())
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"nats-service/tests/integration/harness"
+	"os"
+	"shared/grpc/clients/nats_service"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestHarness_SubscribeChunking verifies through the in-process harness that a payload larger than the default
+// chunk size is streamed as ordered chunks of a single message, and that NatsClient reassembles them.
+func TestHarness_SubscribeChunking(t *testing.T) {
+	var (
+		bus       = harness.New(t)
+		subject   = "test.chunks"
+		chunkSize = 512 * 1024 // chunkSize mirrors the default chunk size of BusService.
+		payload   = bytes.Repeat([]byte("0123456789abcdef"), (3*chunkSize+chunkSize/2)/16)
+		numChunks = (len(payload) + chunkSize - 1) / chunkSize
+		logger    = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		received  = make(chan []byte, 1)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	stream, err := bus.Client.Subscribe(ctx, &natsservicev1.SubscribeRequest{Subject: subject})
+	require.NoError(t, err, "Failed to open the subscription stream")
+
+	client, err := nats_service.NewNatsClient("dev", bus.Address, nats_service.NewBusClientValidator(), logger)
+	require.NoError(t, err, "Failed to create NATS client")
+	t.Cleanup(func() { _ = client.Close() })
+	go func() {
+		_ = client.Subscribe(ctx, subject, "", func(data []byte, subject string) { received <- data })
+	}()
+
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(subject) == 2 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscriptions not registered")
+
+	_, err = bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: payload})
+	require.NoError(t, err, "Failed to publish the payload")
+
+	// The raw stream carries the chunks of a single message in sequence order.
+	var (
+		messageId string
+		data      []byte
+	)
+	for i := 0; i < numChunks; i++ {
+		response, recvErr := stream.Recv()
+		require.NoError(t, recvErr, "Failed to receive chunk %d", i)
+		require.Equal(t, subject, response.GetSubject())
+		require.Equal(t, uint32(i), response.GetSequence(), "Chunks out of order")
+		require.Equal(t, uint32(numChunks), response.GetTotal(), "Unexpected chunk count")
+		require.LessOrEqual(t, len(response.GetData()), chunkSize, "Chunk exceeds the chunk size")
+		if i == 0 {
+			messageId = response.GetMessageId()
+			require.NotEmpty(t, messageId, "Chunks should carry a message id")
+		}
+		require.Equal(t, messageId, response.GetMessageId(), "Chunks should share the message id")
+		data = append(data, response.GetData()...)
+	}
+	require.Equal(t, payload, data, "Chunks should concatenate to the payload")
+
+	select {
+	case data = <-received:
+		require.Equal(t, payload, data, "NatsClient should reassemble the payload")
+	case <-ctx.Done():
+		t.Fatal("NatsClient did not deliver the reassembled payload")
+	}
+}
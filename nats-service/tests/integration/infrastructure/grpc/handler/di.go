@@ -4,10 +4,10 @@ import (
 	"log"
 	"log/slog"
 	"nats-service/application/services"
-	"nats-service/domain/entities"
 	"nats-service/infrastructure/broker"
 	"nats-service/infrastructure/grpc/handler"
 	"nats-service/infrastructure/grpc/validators"
+	"nats-service/tests/integration/corefake"
 	"os"
 	"shared/dependency"
 	"time"
@@ -49,7 +49,7 @@ func NewTestContainer() *TestContainer {
 					log.Println("Disconnected from NATS due to", err)
 				}
 			)
-			if address, err = entities.GetBroker().Address(); err != nil {
+			if address, err = corefake.Address(); err != nil {
 				panic(err)
 			}
 
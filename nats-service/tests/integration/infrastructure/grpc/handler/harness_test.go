@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"nats-service/tests/integration/harness"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestHarness_PublishValidation verifies through the in-process harness that invalid publish requests are
+// rejected before reaching the broker, and that broker failures are reported as internal errors.
+func TestHarness_PublishValidation(t *testing.T) {
+	var (
+		bus = harness.New(t)
+		ctx = context.Background()
+	)
+
+	tests := []struct {
+		name    string
+		request *natsservicev1.PublishRequest
+		errCode codes.Code
+	}{
+		{
+			name:    "valid",
+			request: &natsservicev1.PublishRequest{Subject: "test.valid", Data: []byte("data")},
+			errCode: codes.OK,
+		},
+		{
+			name:    "empty subject",
+			request: &natsservicev1.PublishRequest{Subject: "", Data: []byte("data")},
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:    "blank subject",
+			request: &natsservicev1.PublishRequest{Subject: "   ", Data: []byte("data")},
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:    "empty data",
+			request: &natsservicev1.PublishRequest{Subject: "test.empty"},
+			errCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := bus.Client.Publish(ctx, test.request)
+			require.Equal(t, test.errCode, status.Code(err), "Unexpected status code: %v", err)
+		})
+	}
+	require.Equal(t, 1, bus.Operations.Published(), "Only the valid request should reach the broker")
+
+	bus.Operations.FailPublishes(1)
+	_, err := bus.Client.Publish(ctx, &natsservicev1.PublishRequest{Subject: "test.failure", Data: []byte("data")})
+	require.Equal(t, codes.Unavailable, status.Code(err), "Broker failure should be reported as retryable")
+}
+
+// TestHarness_SubscribeStreaming verifies through the in-process harness that messages published on a subject
+// are streamed in order to its subscriber, and that canceling the stream removes the subscription.
+func TestHarness_SubscribeStreaming(t *testing.T) {
+	var (
+		bus         = harness.New(t)
+		subject     = "test.stream"
+		numMessages = 5
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+
+	stream, err := bus.Client.Subscribe(ctx, &natsservicev1.SubscribeRequest{Subject: subject})
+	require.NoError(t, err, "Failed to open the subscription stream")
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(subject) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscription not registered")
+
+	for i := 0; i < numMessages; i++ {
+		request := &natsservicev1.PublishRequest{Subject: subject, Data: []byte(fmt.Sprintf("message-%d", i))}
+		_, err = bus.Client.Publish(ctx, request)
+		require.NoError(t, err, "Failed to publish message %d", i)
+	}
+
+	for i := 0; i < numMessages; i++ {
+		response, recvErr := stream.Recv()
+		require.NoError(t, recvErr, "Failed to receive message %d", i)
+		require.Equal(t, subject, response.GetSubject())
+		require.Equal(t, fmt.Sprintf("message-%d", i), string(response.GetData()), "Messages out of order")
+	}
+
+	// An invalid subscription is rejected on the first receive.
+	invalid, err := bus.Client.Subscribe(ctx, &natsservicev1.SubscribeRequest{Subject: ""})
+	require.NoError(t, err, "Opening the stream should not fail")
+	_, err = invalid.Recv()
+	require.Equal(t, codes.InvalidArgument, status.Code(err), "Empty subject should be rejected")
+
+	cancel()
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(subject) == 0 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscription not removed")
+}
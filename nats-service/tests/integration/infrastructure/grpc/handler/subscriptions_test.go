@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"nats-service/infrastructure/grpc/handler"
+	"nats-service/infrastructure/metrics"
+	"nats-service/tests/integration/harness"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestBusService_MaxSubscriptions verifies that streams beyond the concurrent subscriptions limit are
+// rejected with ResourceExhausted and counted, and that closing a stream frees its slot.
+func TestBusService_MaxSubscriptions(t *testing.T) {
+	var (
+		limit               = 2
+		registry            = prometheus.NewRegistry()
+		subscriptionMetrics = metrics.NewSubscriptionMetrics("test")
+	)
+	require.NoError(t, subscriptionMetrics.Register(registry), "Failed to register subscription metrics")
+
+	bus := harness.New(t, handler.WithMaxSubscriptions(limit), handler.WithSubscriptionMetrics(subscriptionMetrics))
+	request := &natsservicev1.SubscribeRequest{Subject: "test.limit"}
+	waitForSubscriptions := func(expected int) {
+		require.Eventually(t, func() bool { return bus.BusService.Subscriptions() == expected },
+			time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Expected %d open streams", expected)
+	}
+
+	cancels := make([]context.CancelFunc, 0, limit)
+	for i := 0; i < limit; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels = append(cancels, cancel)
+		_, err := bus.Client.Subscribe(ctx, request)
+		require.NoError(t, err, "Failed to open stream %d", i)
+	}
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+	waitForSubscriptions(limit)
+
+	rejected, err := bus.Client.Subscribe(context.Background(), request)
+	require.NoError(t, err, "Opening the stream should not fail")
+	_, err = rejected.Recv()
+	require.Equal(t, codes.ResourceExhausted, status.Code(err), "Stream beyond the limit should be rejected")
+	require.Equal(t, limit, bus.BusService.Subscriptions(), "Rejected stream must not hold a slot")
+	require.Equal(t, 1.0, gaugeOrCounter(t, registry, "test_subscriptions_rejected_total"))
+
+	// Closing a stream frees its slot for a new one.
+	cancels[0]()
+	waitForSubscriptions(limit - 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancels = append(cancels, cancel)
+	_, err = bus.Client.Subscribe(ctx, request)
+	require.NoError(t, err, "Failed to open a stream after one closed")
+	waitForSubscriptions(limit)
+	require.Equal(t, float64(limit), gaugeOrCounter(t, registry, "test_subscriptions_active"))
+}
+
+// gaugeOrCounter returns the value of an unlabeled gauge or counter in the registry.
+func gaugeOrCounter(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetCounter() != nil {
+				return metric.GetCounter().GetValue()
+			}
+			return metric.GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("Metric %s not found", name)
+	return 0
+}
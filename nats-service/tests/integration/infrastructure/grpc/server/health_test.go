@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"nats-service/infrastructure/grpc/server"
+	"os"
+	"shared/grpc/inprocess"
+	healthv1 "shared/proto/health/gen"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// TestBusServer_Health verifies that the health service reports SERVING only while the server is started
+// and its health check passes, and NOT_SERVING once the status is flipped for shutdown.
+func TestBusServer_Health(t *testing.T) {
+	var (
+		logger    = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		name      = "bus-health-test"
+		connected atomic.Bool
+		ctx       = context.Background()
+	)
+	connected.Store(true)
+
+	busServer, err := server.NewInProcessBusServer(name, logger, server.WithHealthCheck(connected.Load))
+	require.NoError(t, err, "Failed to create in-process BusServer")
+
+	conn, err := grpc.NewClient("passthrough:///"+name,
+		grpc.WithContextDialer(inprocess.Dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "Failed to connect to in-process BusServer")
+	t.Cleanup(func() {
+		_ = conn.Close()
+		busServer.GracefulStop()
+	})
+	client := healthv1.NewHealthClient(conn)
+
+	check := func(service string) healthv1.HealthCheckResponse_ServingStatus {
+		response, err := client.Check(ctx, &healthv1.HealthCheckRequest{Service: service})
+		require.NoError(t, err, "Health check failed")
+		return response.GetStatus()
+	}
+
+	busServer.Start()
+	require.Equal(t, healthv1.HealthCheckResponse_SERVING, check(""), "Started server should be serving")
+	require.Equal(t, healthv1.HealthCheckResponse_SERVING, check(natsservicev1.BusService_ServiceDesc.ServiceName),
+		"BusService should be serving")
+
+	_, err = client.Check(ctx, &healthv1.HealthCheckRequest{Service: "unknown.Service"})
+	require.Equal(t, codes.NotFound, status.Code(err), "Unknown service should not be found")
+
+	connected.Store(false)
+	require.Equal(t, healthv1.HealthCheckResponse_NOT_SERVING, check(""), "Disconnected server should not be serving")
+
+	connected.Store(true)
+	require.Equal(t, healthv1.HealthCheckResponse_SERVING, check(""), "Reconnected server should be serving")
+
+	busServer.SetServingStatus(healthv1.HealthCheckResponse_NOT_SERVING)
+	require.Equal(t, healthv1.HealthCheckResponse_NOT_SERVING, check(""), "Server should not be serving on shutdown")
+}
+
+// TestBusServer_HealthWatch verifies that Watch streams the current status first and then every status change.
+func TestBusServer_HealthWatch(t *testing.T) {
+	var (
+		logger      = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		name        = "bus-health-watch-test"
+		ctx, cancel = context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	)
+	defer cancel()
+
+	busServer, err := server.NewInProcessBusServer(name, logger)
+	require.NoError(t, err, "Failed to create in-process BusServer")
+
+	conn, err := grpc.NewClient("passthrough:///"+name,
+		grpc.WithContextDialer(inprocess.Dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "Failed to connect to in-process BusServer")
+	t.Cleanup(func() {
+		_ = conn.Close()
+		busServer.GracefulStop()
+	})
+
+	busServer.Start()
+	stream, err := healthv1.NewHealthClient(conn).Watch(ctx, &healthv1.HealthCheckRequest{})
+	require.NoError(t, err, "Failed to watch health")
+
+	response, err := stream.Recv()
+	require.NoError(t, err, "Failed to receive the initial status")
+	require.Equal(t, healthv1.HealthCheckResponse_SERVING, response.GetStatus(), "Initial status should be serving")
+
+	busServer.SetServingStatus(healthv1.HealthCheckResponse_NOT_SERVING)
+	response, err = stream.Recv()
+	require.NoError(t, err, "Failed to receive the status change")
+	require.Equal(t, healthv1.HealthCheckResponse_NOT_SERVING, response.GetStatus(), "Status change should be streamed")
+}
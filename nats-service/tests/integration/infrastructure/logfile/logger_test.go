@@ -0,0 +1,49 @@
+package logfile
+
+import (
+	"bytes"
+	"nats-service/infrastructure/logfile"
+	"os"
+	"path/filepath"
+	"shared/logging"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewLogger_File verifies that the logger creates the missing log directory and appends to the log file.
+func TestNewLogger_File(t *testing.T) {
+	var (
+		fallback bytes.Buffer
+		path     = filepath.Join(t.TempDir(), "logs", "nats-service.log")
+		logger   = logfile.NewLogger(path, &fallback, logging.HandlerOptions{})
+	)
+
+	logger.Info("Written to the log file")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "Expected the log file to be created")
+	require.Contains(t, string(content), "Written to the log file")
+	require.Empty(t, fallback.String(), "Expected nothing to be written to the fallback output")
+}
+
+// TestNewLogger_Fallback verifies that an unwritable log path falls back to the fallback output with a warning,
+// instead of exiting the process.
+func TestNewLogger_Fallback(t *testing.T) {
+	var (
+		fallback bytes.Buffer
+		blocker  = filepath.Join(t.TempDir(), "blocker")
+	)
+	// A regular file in place of the log directory makes the directory creation fail, even for root
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644), "Failed to create the blocking file")
+	path := filepath.Join(blocker, "logs", "nats-service.log")
+
+	logger := logfile.NewLogger(path, &fallback, logging.HandlerOptions{})
+	logger.Info("Written to the fallback output")
+
+	require.Contains(t, fallback.String(), "Log file is unusable", "Expected a warning about the log file")
+	require.Contains(t, fallback.String(), path, "Expected the warning to name the log file")
+	require.Contains(t, fallback.String(), "Written to the fallback output")
+	_, err := os.Stat(path)
+	require.Error(t, err, "Expected no log file to be created")
+}
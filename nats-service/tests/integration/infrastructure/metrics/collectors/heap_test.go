@@ -0,0 +1,85 @@
+package collectors
+
+import (
+	"log/slog"
+	"math"
+	"nats-service/infrastructure/metrics/collectors"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHeapMetrics_Goroutines verifies that the goroutine gauge reflects runtime.NumGoroutine.
+func TestHeapMetrics_Goroutines(t *testing.T) {
+	registry := startHeapMetrics(t)
+
+	var (
+		extra = 20
+		stop  = make(chan struct{})
+	)
+	for i := 0; i < extra; i++ {
+		go func() { <-stop }()
+	}
+	t.Cleanup(func() { close(stop) })
+
+	// The goroutine count moves between samples (test runner, race detector), so it is compared against
+	// runtime.NumGoroutine taken at the same moment as the gauge.
+	require.Eventually(t, func() bool {
+		var (
+			value   = gaugeValue(t, registry, "test_goroutines")
+			current = float64(runtime.NumGoroutine())
+		)
+		return value >= float64(extra) && math.Abs(current-value) <= 2
+	}, time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond,
+		"Expected the goroutine gauge to match runtime.NumGoroutine")
+}
+
+// TestHeapMetrics_GCPauseQuantiles verifies that the GC pause quantile gauges update after GC activity.
+func TestHeapMetrics_GCPauseQuantiles(t *testing.T) {
+	registry := startHeapMetrics(t)
+
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+	}
+
+	require.Eventually(t, func() bool {
+		return gaugeValue(t, registry, "test_gc_pause_p99_seconds") > 0
+	}, time.Second, time.Duration(10)*time.Millisecond, "GC pause quantiles not updated")
+
+	p50 := gaugeValue(t, registry, "test_gc_pause_p50_seconds")
+	p99 := gaugeValue(t, registry, "test_gc_pause_p99_seconds")
+	assert.Greater(t, p50, float64(0), "Expected a positive median GC pause")
+	assert.LessOrEqual(t, p50, p99, "Expected the median not to exceed the 99th percentile")
+}
+
+// startHeapMetrics registers and starts a HeapMetrics collector with a short interval.
+func startHeapMetrics(t *testing.T) *prometheus.Registry {
+	var (
+		registry    = prometheus.NewRegistry()
+		logger      = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		heapMetrics = collectors.NewHeapMetrics("test", logger)
+	)
+	require.NoError(t, heapMetrics.InitMetrics(registry), "Failed to register heap metrics")
+
+	heapMetrics.Start(time.Duration(10) * time.Millisecond)
+	t.Cleanup(func() { heapMetrics.StopWithTimeout(time.Second) })
+	return registry
+}
+
+// gaugeValue returns the value of the named gauge.
+func gaugeValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() == name && len(family.GetMetric()) > 0 {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	return 0
+}
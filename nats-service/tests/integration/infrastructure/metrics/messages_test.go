@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"nats-service/infrastructure/metrics"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMessageMetrics_SubjectLabel verifies that allowlisted subjects are labeled individually
+// and all other subjects are bucketed as "other".
+func TestMessageMetrics_SubjectLabel(t *testing.T) {
+	var (
+		registry       = prometheus.NewRegistry()
+		messageMetrics = metrics.NewMessageMetrics("test", []string{"url.incoming", "url.outgoing"})
+	)
+	require.NoError(t, messageMetrics.Register(registry), "Failed to register message metrics")
+
+	messageMetrics.ObservePublish("url.incoming", time.Millisecond)
+	messageMetrics.ObservePublish("url.incoming", time.Millisecond)
+	messageMetrics.ObservePublish("unknown.subject", time.Millisecond)
+	messageMetrics.ObservePublish("another.subject", time.Millisecond)
+	messageMetrics.ObserveReceive("url.outgoing")
+	messageMetrics.ObserveReceive("unknown.subject")
+	messageMetrics.ObserveDelivery("url.outgoing", time.Millisecond)
+
+	assert.Equal(t, float64(2), counterValue(t, registry, "test_messages_published_total", "url.incoming"))
+	assert.Equal(t, float64(2), counterValue(t, registry, "test_messages_published_total", metrics.OtherSubject))
+	assert.Equal(t, float64(1), counterValue(t, registry, "test_messages_received_total", "url.outgoing"))
+	assert.Equal(t, float64(1), counterValue(t, registry, "test_messages_received_total", metrics.OtherSubject))
+
+	assert.Equal(t, uint64(2), histogramCount(t, registry, "test_messages_publish_duration_seconds", "url.incoming"))
+	assert.Equal(t, uint64(1), histogramCount(t, registry, "test_messages_delivery_duration_seconds", "url.outgoing"))
+
+	assert.ElementsMatch(t, []string{"url.incoming", metrics.OtherSubject},
+		subjectLabels(t, registry, "test_messages_published_total"), "Expected no label for unknown subjects")
+}
+
+// subjectLabels returns the subject label values exported for the named metric family.
+func subjectLabels(t *testing.T, registry *prometheus.Registry, name string) (subjects []string) {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "subject" {
+					subjects = append(subjects, label.GetValue())
+				}
+			}
+		}
+	}
+	return subjects
+}
+
+// counterValue returns the value of the named counter with the given subject label.
+func counterValue(t *testing.T, registry *prometheus.Registry, name, subject string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "subject" && label.GetValue() == subject {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// histogramCount returns the sample count of the named histogram with the given subject label.
+func histogramCount(t *testing.T, registry *prometheus.Registry, name, subject string) uint64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "subject" && label.GetValue() == subject {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
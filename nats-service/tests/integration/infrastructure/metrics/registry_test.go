@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"io"
+	"log/slog"
+	"nats-service/infrastructure/metrics"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProvider_WithRegistry verifies that the provider collectors and other metric components
+// registered on the same injected registry are exposed together through a single endpoint.
+func TestProvider_WithRegistry(t *testing.T) {
+	var (
+		registry = prometheus.NewRegistry()
+		logger   = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	)
+	// A default collector already present on the shared registry must not break the provider.
+	require.NoError(t, registry.Register(collectors.NewGoCollector()), "Failed to register Go collector")
+
+	provider := metrics.NewProvider("test", logger, metrics.WithRegistry(registry))
+	assert.Same(t, registry, provider.Registry, "Expected the provider to use the injected registry")
+
+	provider.StartCollectors(time.Duration(10) * time.Millisecond)
+	t.Cleanup(func() { provider.Stop(time.Second) })
+
+	messageMetrics := metrics.NewMessageMetrics("test", []string{"url.incoming"})
+	require.NoError(t, messageMetrics.Register(registry), "Failed to register message metrics")
+	messageMetrics.ObservePublish("url.incoming", time.Millisecond)
+
+	server := httptest.NewServer(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	t.Cleanup(server.Close)
+
+	body := scrape(t, server.URL)
+	assert.Contains(t, body, "test_heap_alloc_bytes", "Expected heap metrics to be scrapeable")
+	assert.Contains(t, body, "test_messages_published_total", "Expected message metrics to be scrapeable")
+	assert.Contains(t, body, "go_goroutines", "Expected default Go metrics to be scrapeable")
+}
+
+// scrape fetches the metrics endpoint and returns the response body.
+func scrape(t *testing.T, url string) string {
+	response, err := http.Get(url)
+	require.NoError(t, err, "Failed to scrape metrics")
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err, "Failed to read metrics response")
+	return string(body)
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"nats-service/tests/load/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadTestConfig_EffectiveSubject verifies that the effective subject includes the configured namespace.
+func TestLoadTestConfig_EffectiveSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.LoadTestConfig
+		expected string
+	}{
+		{
+			name:     "without namespace",
+			cfg:      &config.LoadTestConfig{Subject: "load.test", RunId: "42"},
+			expected: "load.test",
+		},
+		{
+			name:     "with namespace",
+			cfg:      &config.LoadTestConfig{Subject: "load.test", Namespace: "loadtest", RunId: "42"},
+			expected: "loadtest.42.load.test",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.cfg.EffectiveSubject())
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package load
+
+import (
+	"io"
+	"log/slog"
+	"nats-service/tests/load"
+	"nats-service/tests/load/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckTarget_ProdHost verifies that a load test against a production host is blocked without the override.
+func TestCheckTarget_ProdHost(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name    string
+		cfg     *config.LoadTestConfig
+		blocked bool
+	}{
+		{
+			name:    "prod host without override",
+			cfg:     &config.LoadTestConfig{RpcHost: "1.2.3.4", ProdHostPattern: `^1\.2\.3\.4$`},
+			blocked: true,
+		},
+		{
+			name: "prod host with override",
+			cfg:  &config.LoadTestConfig{RpcHost: "1.2.3.4", ProdHostPattern: `^1\.2\.3\.4$`, AllowProd: true},
+		},
+		{
+			name: "non-prod host",
+			cfg:  &config.LoadTestConfig{RpcHost: "localhost", ProdHostPattern: `^1\.2\.3\.4$`},
+		},
+		{
+			name: "no pattern configured",
+			cfg:  &config.LoadTestConfig{RpcHost: "1.2.3.4"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := load.CheckTarget(test.cfg, logger)
+			if test.blocked {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, load.ErrProdTarget)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestCheckTarget_InvalidPattern verifies that an invalid production host pattern is reported as an error.
+func TestCheckTarget_InvalidPattern(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.LoadTestConfig{RpcHost: "localhost", ProdHostPattern: "("}
+
+	err := load.CheckTarget(cfg, logger)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, load.ErrProdTarget)
+}
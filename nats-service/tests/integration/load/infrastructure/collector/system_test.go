@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"io"
+	"log/slog"
+	"nats-service/tests/load/infrastructure/collector"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSystemCollector_StopLongInterval verifies that Stop returns promptly with a long collection interval.
+func TestSystemCollector_StopLongInterval(t *testing.T) {
+	systemCollector := collector.NewSystemCollector(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	systemCollector.SetInterval(time.Hour)
+
+	require.NoError(t, systemCollector.Start(), "Failed to start collector")
+
+	start := time.Now()
+	require.NoError(t, systemCollector.Stop(), "Failed to stop collector")
+	require.Less(t, time.Since(start), 100*time.Millisecond, "Expected Stop to return promptly")
+}
+
+// TestSystemCollector_StopWhileSampling verifies that Stop returns promptly while samples are taken and keeps them.
+func TestSystemCollector_StopWhileSampling(t *testing.T) {
+	systemCollector := collector.NewSystemCollector(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	systemCollector.SetInterval(time.Millisecond)
+
+	require.NoError(t, systemCollector.Start(), "Failed to start collector")
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, systemCollector.Stop(), "Failed to stop collector")
+	require.Less(t, time.Since(start), 100*time.Millisecond, "Expected Stop to return promptly")
+
+	resources := systemCollector.GetMetrics().ResourceMetrics
+	require.Positive(t, resources.MemoryUsageMB, "Expected memory samples to be collected")
+	require.Positive(t, resources.ActiveGoroutines, "Expected goroutine samples to be collected")
+}
@@ -0,0 +1,48 @@
+package comparator
+
+import (
+	"nats-service/tests/load/infrastructure/comparator"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompare_Regression verifies the deltas and verdict computed from two known result files.
+func TestCompare_Regression(t *testing.T) {
+	baseline, err := comparator.LoadResult("testdata/baseline.json")
+	require.NoError(t, err, "Failed to load baseline result")
+	current, err := comparator.LoadResult("testdata/current.json")
+	require.NoError(t, err, "Failed to load current result")
+
+	tolerances := comparator.Tolerances{
+		MaxThroughputDropPercent:   5,
+		MaxLatencyP99RisePercent:   15,
+		MaxErrorRateRisePercentage: 0.5,
+	}
+	diff := comparator.Compare(baseline, current, tolerances)
+
+	assert.InDelta(t, -10, diff.ThroughputDeltaPercent, 0.001, "Unexpected throughput delta")
+	assert.InDelta(t, 10, diff.LatencyP99DeltaPercent, 0.001, "Unexpected p99 latency delta")
+	assert.InDelta(t, 1, diff.ErrorRateDelta, 0.001, "Unexpected error rate delta")
+	assert.False(t, diff.Passed, "Expected the comparison to fail")
+	assert.Len(t, diff.Violations, 2, "Expected throughput and error rate violations")
+}
+
+// TestCompare_WithinTolerances verifies that a comparison within the tolerances passes.
+func TestCompare_WithinTolerances(t *testing.T) {
+	baseline, err := comparator.LoadResult("testdata/baseline.json")
+	require.NoError(t, err, "Failed to load baseline result")
+	current, err := comparator.LoadResult("testdata/current.json")
+	require.NoError(t, err, "Failed to load current result")
+
+	tolerances := comparator.Tolerances{
+		MaxThroughputDropPercent:   20,
+		MaxLatencyP99RisePercent:   15,
+		MaxErrorRateRisePercentage: 2,
+	}
+	diff := comparator.Compare(baseline, current, tolerances)
+
+	assert.True(t, diff.Passed, "Expected the comparison to pass")
+	assert.Empty(t, diff.Violations, "Expected no violations")
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"nats-service/tests/load/infrastructure/metrics"
+	"sync"
+	"testing"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCounters_ConcurrentIncrement verifies that concurrent increments of the same custom metric are not lost.
+// Run with -race to detect unsynchronized access.
+func TestCounters_ConcurrentIncrement(t *testing.T) {
+	const (
+		goroutines = 64
+		increments = 1_000
+	)
+	counters := metrics.NewCounters()
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				counters.IncrementCustomMetric("errors_503", 1)
+				counters.AddCustomMetric(map[string]float64{"errors_total": 0.5})
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, float64(goroutines*increments), counters.Get("errors_503"))
+	assert.Equal(t, float64(goroutines*increments)/2, counters.Get("errors_total"))
+}
+
+// TestCounters_Merge verifies that merged counters are summed rather than overwritten.
+func TestCounters_Merge(t *testing.T) {
+	first, second := metrics.NewCounters(), metrics.NewCounters()
+	first.IncrementCustomMetric("errors_503", 2)
+	second.IncrementCustomMetric("errors_503", 3)
+	second.IncrementCustomMetric("errors_429", 1)
+
+	first.Merge(second)
+	first.Merge(first)
+
+	assert.Equal(t, map[string]float64{"errors_503": 5, "errors_429": 1}, first.Snapshot())
+
+	result := core.NewMetrics()
+	first.ApplyTo(result)
+	require.Equal(t, float64(5), result.Custom["errors_503"])
+	require.Equal(t, float64(1), result.Custom["errors_429"])
+}
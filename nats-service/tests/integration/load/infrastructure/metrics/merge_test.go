@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"nats-service/tests/load/infrastructure/metrics"
+	"testing"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAggregator_ResourceMean verifies that merging three resource metric sets yields their true arithmetic mean.
+func TestAggregator_ResourceMean(t *testing.T) {
+	resources := []core.ResourceMetrics{
+		{CPUUsagePercent: 10, MemoryUsageMB: 100, GCPauseMs: 1, ActiveGoroutines: 5},
+		{CPUUsagePercent: 20, MemoryUsageMB: 200, GCPauseMs: 2, ActiveGoroutines: 15},
+		{CPUUsagePercent: 60, MemoryUsageMB: 600, GCPauseMs: 6, ActiveGoroutines: 10},
+	}
+
+	aggregator := metrics.NewAggregator(core.NewMetrics())
+	for _, item := range resources {
+		collected := core.NewMetrics()
+		collected.ResourceMetrics = item
+		aggregator.Add(collected)
+	}
+	result := aggregator.Finish()
+
+	assert.InDelta(t, 30, result.ResourceMetrics.CPUUsagePercent, 1e-9)
+	assert.InDelta(t, 300, result.ResourceMetrics.MemoryUsageMB, 1e-9)
+	assert.InDelta(t, 3, result.ResourceMetrics.GCPauseMs, 1e-9)
+	assert.Equal(t, 15, result.ResourceMetrics.ActiveGoroutines)
+}
+
+// TestAggregator_Throughput verifies that throughput is calculated once from the merged totals and time range.
+func TestAggregator_Throughput(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	base := core.NewMetrics()
+	base.StartTime, base.EndTime = start, start.Add(5*time.Second)
+	base.TotalOperations = 100
+	base.AddLatency(1)
+
+	other := core.NewMetrics()
+	other.StartTime, other.EndTime = start.Add(time.Second), start.Add(10*time.Second)
+	other.TotalOperations = 100
+	other.ErrorCount = 3
+	other.AddLatency(2)
+	other.SetCustomMetric("custom", 7)
+
+	aggregator := metrics.NewAggregator(base)
+	aggregator.Add(other)
+	aggregator.Add(nil)
+	result := aggregator.Finish()
+
+	require.Same(t, base, result)
+	assert.Equal(t, start, result.StartTime)
+	assert.Equal(t, start.Add(10*time.Second), result.EndTime)
+	assert.Equal(t, int64(200), result.TotalOperations)
+	assert.Equal(t, int64(3), result.ErrorCount)
+	assert.Equal(t, []float64{1, 2}, result.Latencies)
+	assert.Equal(t, float64(7), result.Custom["custom"])
+	assert.InDelta(t, 20, result.Throughput, 1e-9)
+}
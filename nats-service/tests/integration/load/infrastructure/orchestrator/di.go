@@ -0,0 +1,126 @@
+package orchestrator
+
+import (
+	"io"
+	"log/slog"
+	"nats-service/tests/load/infrastructure/orchestrator"
+	"shared/dependency"
+	"syscall"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// ConfiguredRunner is the name of the runner whose concurrency is set on the ConcurrencyOrchestrator.
+const ConfiguredRunner = "configured"
+
+// MaxOperations is the operation limit of the LimitedOrchestrator.
+const MaxOperations = 25
+
+// InterruptSignal is the signal interrupting the InterruptedOrchestrator, unlike SIGINT it cannot stop the test binary.
+const InterruptSignal = syscall.SIGUSR1
+
+// TestContainer holds dependencies for the orchestrator tests.
+type TestContainer struct {
+	Logger       dependency.LazyDependency[*slog.Logger]
+	Config       dependency.LazyDependency[*core.TestConfig]
+	Orchestrator dependency.LazyDependency[*orchestrator.Orchestrator]
+
+	WarmupConfig       dependency.LazyDependency[*core.TestConfig]
+	WarmupOrchestrator dependency.LazyDependency[*orchestrator.Orchestrator]
+
+	TimeoutOrchestrator dependency.LazyDependency[*orchestrator.Orchestrator]
+
+	ConcurrencyOrchestrator dependency.LazyDependency[*orchestrator.Orchestrator]
+
+	LimitedConfig       dependency.LazyDependency[*core.TestConfig]
+	LimitedOrchestrator dependency.LazyDependency[*orchestrator.Orchestrator]
+
+	InterruptedOrchestrator dependency.LazyDependency[*orchestrator.Orchestrator]
+}
+
+// NewTestContainer initializes a new test container.
+func NewTestContainer() *TestContainer {
+	c := &TestContainer{}
+
+	c.Logger = dependency.LazyDependency[*slog.Logger]{
+		InitFunc: func() *slog.Logger {
+			return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{}))
+		},
+	}
+	c.Config = dependency.LazyDependency[*core.TestConfig]{
+		InitFunc: func() *core.TestConfig {
+			return &core.TestConfig{
+				TestDuration:   time.Duration(200) * time.Millisecond,
+				Concurrency:    2,
+				ReportInterval: time.Duration(50) * time.Millisecond,
+				LogLevel:       "info",
+				Tags:           make(map[string]string),
+			}
+		},
+	}
+	c.Orchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
+			return orchestrator.NewOrchestrator(c.Config.Get(), c.Logger.Get())
+		},
+	}
+
+	c.WarmupConfig = dependency.LazyDependency[*core.TestConfig]{
+		InitFunc: func() *core.TestConfig {
+			cfg := *c.Config.Get()
+			cfg.WarmupDuration = time.Duration(100) * time.Millisecond
+			return &cfg
+		},
+	}
+	c.WarmupOrchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
+			return orchestrator.NewOrchestrator(c.WarmupConfig.Get(), c.Logger.Get(), orchestrator.WithWarmupMetrics())
+		},
+	}
+	c.TimeoutOrchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
+			var (
+				cfg     = c.Config.Get()
+				logger  = c.Logger.Get()
+				timeout = time.Duration(20) * time.Millisecond
+			)
+			return orchestrator.NewOrchestrator(cfg, logger, orchestrator.WithOperationTimeout(timeout))
+		},
+	}
+	c.ConcurrencyOrchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
+			var (
+				cfg    = c.Config.Get()
+				logger = c.Logger.Get()
+			)
+			return orchestrator.NewOrchestrator(cfg, logger, orchestrator.WithRunnerConcurrency(ConfiguredRunner, 5))
+		},
+	}
+	c.LimitedConfig = dependency.LazyDependency[*core.TestConfig]{
+		InitFunc: func() *core.TestConfig {
+			cfg := *c.Config.Get()
+			cfg.TestDuration = time.Duration(10) * time.Second
+			return &cfg
+		},
+	}
+	c.LimitedOrchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
+			var (
+				cfg    = c.LimitedConfig.Get()
+				logger = c.Logger.Get()
+			)
+			return orchestrator.NewOrchestrator(cfg, logger, orchestrator.WithMaxOperations(MaxOperations))
+		},
+	}
+	c.InterruptedOrchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
+			var (
+				cfg    = c.LimitedConfig.Get()
+				logger = c.Logger.Get()
+			)
+			return orchestrator.NewOrchestrator(cfg, logger, orchestrator.WithSignals(InterruptSignal))
+		},
+	}
+
+	return c
+}
@@ -0,0 +1,220 @@
+package orchestrator
+
+import (
+	"context"
+	"nats-service/tests/load/infrastructure/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// CountedMetric is the custom metric incremented by the CountingRunner.
+const CountedMetric = "counted"
+
+// MockRunner is a core.Runner implementation that counts its calls.
+type MockRunner struct {
+	name  string        // name is the runner name.
+	delay time.Duration // delay simulates the duration of a single operation.
+	calls atomic.Int64  // calls is the number of Run invocations.
+}
+
+// NewMockRunner creates a new instance of MockRunner.
+func NewMockRunner(name string, delay time.Duration) *MockRunner {
+	return &MockRunner{name: name, delay: delay}
+}
+
+// Setup is a no-op.
+func (r *MockRunner) Setup(ctx context.Context) error { return nil }
+
+// Run waits for the configured delay or until the context is done.
+func (r *MockRunner) Run(ctx context.Context) error {
+	r.calls.Add(1)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(r.delay):
+		return nil
+	}
+}
+
+// Teardown is a no-op.
+func (r *MockRunner) Teardown(ctx context.Context) error { return nil }
+
+// Name returns the runner name.
+func (r *MockRunner) Name() string { return r.name }
+
+// Calls returns the number of Run invocations.
+func (r *MockRunner) Calls() int64 { return r.calls.Load() }
+
+// MockReporter is a core.Reporter implementation that records the reported metrics.
+type MockReporter struct {
+	mu      sync.Mutex    // mu guards the recorded metrics.
+	warmup  *core.Metrics // warmup is the reported warmup metrics.
+	results *core.Metrics // results is the reported final metrics.
+}
+
+// ReportProgress is a no-op.
+func (r *MockReporter) ReportProgress(snapshot *core.MetricsSnapshot) error { return nil }
+
+// ReportWarmup records the warmup metrics.
+func (r *MockReporter) ReportWarmup(metrics *core.Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.warmup = metrics
+	return nil
+}
+
+// ReportResults records the final metrics.
+func (r *MockReporter) ReportResults(metrics *core.Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = metrics
+	return nil
+}
+
+// Name returns the reporter name.
+func (r *MockReporter) Name() string { return "Mock Reporter" }
+
+// Warmup returns the reported warmup metrics.
+func (r *MockReporter) Warmup() *core.Metrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.warmup
+}
+
+// Results returns the reported final metrics.
+func (r *MockReporter) Results() *core.Metrics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results
+}
+
+// HangingRunner is a core.Runner implementation that hangs on every hangEvery-th call until the context is done.
+type HangingRunner struct {
+	hangEvery int64        // hangEvery is the period of the hanging calls.
+	calls     atomic.Int64 // calls is the number of Run invocations.
+	hangs     atomic.Int64 // hangs is the number of hanging Run invocations.
+}
+
+// NewHangingRunner creates a new instance of HangingRunner.
+func NewHangingRunner(hangEvery int64) *HangingRunner {
+	return &HangingRunner{hangEvery: hangEvery}
+}
+
+// Setup is a no-op.
+func (r *HangingRunner) Setup(ctx context.Context) error { return nil }
+
+// Run hangs until the context is done on every hangEvery-th call, otherwise returns immediately.
+func (r *HangingRunner) Run(ctx context.Context) error {
+	if r.calls.Add(1)%r.hangEvery != 1 {
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	r.hangs.Add(1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Teardown is a no-op.
+func (r *HangingRunner) Teardown(ctx context.Context) error { return nil }
+
+// Name returns the runner name.
+func (r *HangingRunner) Name() string { return "hanging" }
+
+// Hangs returns the number of hanging Run invocations.
+func (r *HangingRunner) Hangs() int64 { return r.hangs.Load() }
+
+// CountingRunner is a core.Runner implementation that increments a custom metric on every call.
+type CountingRunner struct {
+	counters *metrics.Counters // counters receives the incremented custom metric.
+	calls    atomic.Int64      // calls is the number of Run invocations.
+}
+
+// NewCountingRunner creates a new instance of CountingRunner.
+func NewCountingRunner(counters *metrics.Counters) *CountingRunner {
+	return &CountingRunner{counters: counters}
+}
+
+// Setup is a no-op.
+func (r *CountingRunner) Setup(ctx context.Context) error { return nil }
+
+// Run increments the counted custom metric.
+func (r *CountingRunner) Run(ctx context.Context) error {
+	r.calls.Add(1)
+	r.counters.IncrementCustomMetric(CountedMetric, 1)
+	time.Sleep(time.Millisecond)
+	return nil
+}
+
+// Teardown is a no-op.
+func (r *CountingRunner) Teardown(ctx context.Context) error { return nil }
+
+// Name returns the runner name.
+func (r *CountingRunner) Name() string { return "counting" }
+
+// Calls returns the number of Run invocations.
+func (r *CountingRunner) Calls() int64 { return r.calls.Load() }
+
+// ConcurrentMockRunner is a core.Runner reporting its own concurrency, recording the peak number of concurrent calls.
+type ConcurrentMockRunner struct {
+	name        string       // name is the runner name.
+	concurrency int          // concurrency is the reported number of workers.
+	inFlight    atomic.Int64 // inFlight is the number of Run invocations in progress.
+	peak        atomic.Int64 // peak is the highest number of Run invocations in progress.
+}
+
+// NewConcurrentMockRunner creates a new instance of ConcurrentMockRunner.
+func NewConcurrentMockRunner(name string, concurrency int) *ConcurrentMockRunner {
+	return &ConcurrentMockRunner{name: name, concurrency: concurrency}
+}
+
+// Setup is a no-op.
+func (r *ConcurrentMockRunner) Setup(ctx context.Context) error { return nil }
+
+// Run holds the operation until the context is done, so that every worker is in a call at the same time.
+func (r *ConcurrentMockRunner) Run(ctx context.Context) error {
+	current := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	for peak := r.peak.Load(); current > peak && !r.peak.CompareAndSwap(peak, current); peak = r.peak.Load() {
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// Teardown is a no-op.
+func (r *ConcurrentMockRunner) Teardown(ctx context.Context) error { return nil }
+
+// Name returns the runner name.
+func (r *ConcurrentMockRunner) Name() string { return r.name }
+
+// Concurrency returns the reported number of workers.
+func (r *ConcurrentMockRunner) Concurrency() int { return r.concurrency }
+
+// Peak returns the highest number of concurrent Run invocations, i.e. the number of workers of the runner.
+func (r *ConcurrentMockRunner) Peak() int { return int(r.peak.Load()) }
+
+// StoppingCollector is a core.MetricsCollector implementation that records whether it was stopped.
+type StoppingCollector struct {
+	stopped atomic.Bool // stopped reports whether Stop was called.
+}
+
+// Start is a no-op.
+func (c *StoppingCollector) Start() error { return nil }
+
+// Stop records the call.
+func (c *StoppingCollector) Stop() error {
+	c.stopped.Store(true)
+	return nil
+}
+
+// GetMetrics returns no metrics.
+func (c *StoppingCollector) GetMetrics() *core.Metrics { return nil }
+
+// Name returns the collector name.
+func (c *StoppingCollector) Name() string { return "Stopping Collector" }
+
+// Stopped reports whether Stop was called.
+func (c *StoppingCollector) Stopped() bool { return c.stopped.Load() }
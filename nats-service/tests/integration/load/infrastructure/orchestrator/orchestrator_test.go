@@ -0,0 +1,217 @@
+package orchestrator
+
+import (
+	"context"
+	"nats-service/tests/load/infrastructure/orchestrator"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrchestrator_AddNilComponents verifies that nil runners, collectors and reporters are rejected.
+func TestOrchestrator_AddNilComponents(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.Orchestrator.Get()
+
+	require.ErrorIs(t, loadTest.AddRunner(nil), orchestrator.ErrNilComponent)
+	require.ErrorIs(t, loadTest.AddCollector(nil), orchestrator.ErrNilComponent)
+	require.ErrorIs(t, loadTest.AddReporter(nil), orchestrator.ErrNilComponent)
+
+	// The rejected runners must not be registered, so Run fails fast instead of panicking.
+	require.Error(t, loadTest.Run(), "Expected Run to fail without runners")
+}
+
+// TestOrchestrator_Run verifies that a valid runner is added and executed.
+func TestOrchestrator_Run(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.Orchestrator.Get()
+	runner := NewMockRunner("mock", 0)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+	require.Positive(t, runner.Calls(), "Expected the runner to be executed")
+}
+
+// TestOrchestrator_WarmupMetrics verifies that warmup metrics are captured separately when enabled.
+func TestOrchestrator_WarmupMetrics(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.WarmupOrchestrator.Get()
+	runner := NewMockRunner("mock", time.Millisecond)
+	mockReporter := new(MockReporter)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+
+	warmup := loadTest.WarmupMetrics()
+	require.NotNil(t, warmup, "Expected warmup metrics to be collected")
+	require.Positive(t, warmup.TotalOperations, "Expected warmup operations to be recorded")
+	require.Same(t, warmup, mockReporter.Warmup(), "Expected warmup metrics to be reported")
+
+	results := mockReporter.Results()
+	require.NotNil(t, results, "Expected final results to be reported")
+	require.NotSame(t, warmup, results, "Expected warmup and final metrics to be separate")
+	require.False(t, results.StartTime.Before(warmup.EndTime), "Expected the test to start after the warmup")
+}
+
+// TestOrchestrator_WarmupMetricsDisabled verifies that warmup metrics are discarded by default.
+func TestOrchestrator_WarmupMetricsDisabled(t *testing.T) {
+	container := SetupTestContainer()
+	container.Config.Get().WarmupDuration = time.Duration(100) * time.Millisecond
+	loadTest := container.Orchestrator.Get()
+	mockReporter := new(MockReporter)
+
+	require.NoError(t, loadTest.AddRunner(NewMockRunner("mock", time.Millisecond)), "Failed to add runner")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+
+	require.Nil(t, loadTest.WarmupMetrics(), "Expected warmup metrics to be discarded")
+	require.Nil(t, mockReporter.Warmup(), "Expected no warmup metrics to be reported")
+}
+
+// TestOrchestrator_OperationTimeout verifies that a hanging operation is timed out and counted as an error.
+func TestOrchestrator_OperationTimeout(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.TimeoutOrchestrator.Get()
+	runner := NewHangingRunner(50)
+	mockReporter := new(MockReporter)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+
+	results := mockReporter.Results()
+	require.NotNil(t, results, "Expected final results to be reported")
+	require.Positive(t, runner.Hangs(), "Expected at least one hanging operation")
+	require.Positive(t, results.TotalOperations, "Expected the workers to continue after a timeout")
+
+	timeouts := int64(results.Custom[orchestrator.TimeoutsMetric])
+	require.Positive(t, timeouts, "Expected timed out operations to be counted")
+	require.GreaterOrEqual(t, results.ErrorCount, timeouts, "Expected timeouts to be counted as errors")
+	require.LessOrEqual(t, timeouts, runner.Hangs(), "Expected only hanging operations to time out")
+}
+
+// TestOrchestrator_Counters verifies that the counters incremented by the runners are reported as custom metrics.
+func TestOrchestrator_Counters(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.Orchestrator.Get()
+	runner := NewCountingRunner(loadTest.Counters())
+	mockReporter := new(MockReporter)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+
+	results := mockReporter.Results()
+	require.NotNil(t, results, "Expected final results to be reported")
+	require.Equal(t, float64(runner.Calls()), results.Custom[CountedMetric], "Expected every increment to be counted")
+}
+
+// TestOrchestrator_RunnerConcurrency verifies that every runner gets its own number of workers: the configured one,
+// the one reported by the runner, or the concurrency of the test configuration by default.
+func TestOrchestrator_RunnerConcurrency(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.ConcurrencyOrchestrator.Get()
+	var (
+		publisher  = NewConcurrentMockRunner("publisher", 3)
+		subscriber = NewConcurrentMockRunner("subscriber", 1)
+		fallback   = NewConcurrentMockRunner("fallback", 0)
+		configured = NewConcurrentMockRunner(ConfiguredRunner, 1)
+	)
+
+	for _, runner := range []*ConcurrentMockRunner{publisher, subscriber, fallback, configured} {
+		require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	}
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+
+	require.Equal(t, 3, publisher.Peak(), "Expected the workers reported by the runner")
+	require.Equal(t, 1, subscriber.Peak(), "Expected the workers reported by the runner")
+	require.Equal(t, container.Config.Get().Concurrency, fallback.Peak(), "Expected the configured concurrency")
+	require.Equal(t, 5, configured.Peak(), "Expected the runner concurrency option to take precedence")
+}
+
+// TestOrchestrator_MaxOperations verifies that the load test stops once the operation limit is reached,
+// well before the test duration elapses.
+func TestOrchestrator_MaxOperations(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.LimitedOrchestrator.Get()
+	runner := NewMockRunner("mock", time.Millisecond)
+	mockReporter := new(MockReporter)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+	start := time.Now()
+	require.NoError(t, loadTest.Run(), "Failed to run load test")
+	require.Less(t, time.Since(start), container.LimitedConfig.Get().TestDuration/2,
+		"Expected the load test to stop before its duration")
+
+	results := mockReporter.Results()
+	require.NotNil(t, results, "Expected final results to be reported")
+	// Operations in progress when the limit is reached may still complete, at most one per worker.
+	require.GreaterOrEqual(t, results.TotalOperations, int64(MaxOperations), "Expected the operation limit to be reached")
+	require.LessOrEqual(t, runner.Calls(), int64(MaxOperations+container.Config.Get().Concurrency),
+		"Expected the workers to stop once the limit is reached")
+}
+
+// TestOrchestrator_Interrupted verifies that a load test canceled early still stops its collectors and reports
+// the partial results, flagged as interrupted.
+func TestOrchestrator_Interrupted(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.InterruptedOrchestrator.Get()
+	runner := NewMockRunner("mock", time.Millisecond)
+	mockReporter := new(MockReporter)
+	mockCollector := new(StoppingCollector)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.AddCollector(mockCollector), "Failed to add collector")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(100)*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	require.ErrorIs(t, loadTest.RunContext(ctx), orchestrator.ErrInterrupted, "Expected the test to be interrupted")
+	require.Less(t, time.Since(start), container.LimitedConfig.Get().TestDuration/2,
+		"Expected the load test to stop before its duration")
+
+	require.True(t, mockCollector.Stopped(), "Expected the collector to be stopped")
+	requireInterruptedResults(t, mockReporter.Results())
+}
+
+// TestOrchestrator_InterruptSignal verifies that an interrupt signal stops Run early and the partial results
+// are still reported.
+func TestOrchestrator_InterruptSignal(t *testing.T) {
+	container := SetupTestContainer()
+	loadTest := container.InterruptedOrchestrator.Get()
+	runner := NewMockRunner("mock", time.Millisecond)
+	mockReporter := new(MockReporter)
+
+	require.NoError(t, loadTest.AddRunner(runner), "Failed to add runner")
+	require.NoError(t, loadTest.AddReporter(mockReporter), "Failed to add reporter")
+
+	timer := time.AfterFunc(time.Duration(100)*time.Millisecond, func() {
+		_ = syscall.Kill(os.Getpid(), InterruptSignal)
+	})
+	defer timer.Stop()
+	start := time.Now()
+	require.ErrorIs(t, loadTest.Run(), orchestrator.ErrInterrupted, "Expected the test to be interrupted")
+	require.Less(t, time.Since(start), container.LimitedConfig.Get().TestDuration/2,
+		"Expected the load test to stop before its duration")
+
+	requireInterruptedResults(t, mockReporter.Results())
+}
+
+// requireInterruptedResults asserts that results hold the operations run before the interrupt.
+func requireInterruptedResults(t *testing.T, results *core.Metrics) {
+	t.Helper()
+
+	require.NotNil(t, results, "Expected the partial results to be reported")
+	require.Positive(t, results.TotalOperations, "Expected the operations before the interrupt to be reported")
+	require.Positive(t, results.Throughput, "Expected the partial throughput to be calculated")
+	require.False(t, results.EndTime.IsZero(), "Expected the end time to be recorded")
+	require.Equal(t, float64(1), results.Custom[orchestrator.InterruptedMetric],
+		"Expected the results to be flagged as interrupted")
+}
@@ -0,0 +1,81 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	loadReporter "nats-service/tests/load/infrastructure/reporter"
+	"nats-service/tests/load/infrastructure/slo"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/mguley/go-loadtest/pkg/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConsoleReporter_JSONSummary verifies that the final line is a parseable JSON summary.
+func TestConsoleReporter_JSONSummary(t *testing.T) {
+	container := SetupTestContainer()
+	consoleReporter := container.ConsoleReporter.Get()
+
+	start := time.Now()
+	metrics := core.NewMetrics()
+	metrics.StartTime = start
+	metrics.EndTime = start.Add(10 * time.Second)
+	metrics.TotalOperations = 1000
+	metrics.ErrorCount = 50
+	metrics.Throughput = 100
+	metrics.Latencies = []float64{1, 2, 3, 4, 5}
+
+	err := consoleReporter.ReportProgress(metrics.GetSnapshot())
+	require.NoError(t, err, "Failed to report progress")
+	err = consoleReporter.ReportResults(metrics)
+	require.NoError(t, err, "Failed to report results")
+
+	lines := strings.Split(strings.TrimSpace(container.Output.Get().String()), "\n")
+	require.Len(t, lines, 3, "Expected progress, text summary and JSON summary lines")
+	assert.True(t, strings.HasPrefix(lines[0], "["), "Expected a human-readable progress line")
+	assert.True(t, strings.HasPrefix(lines[1], "Completed"), "Expected a human-readable summary line")
+
+	var result reporter.ResultOutput
+	err = json.Unmarshal([]byte(lines[2]), &result)
+	require.NoError(t, err, "Expected the final line to be valid JSON")
+	assert.Equal(t, int64(1000), result.TotalOperations)
+	assert.InDelta(t, 100, result.Throughput, 0.001, "Unexpected throughput")
+	assert.InDelta(t, 5, result.ErrorRate, 0.001, "Unexpected error rate")
+	assert.Nil(t, result.Latencies, "Expected raw latencies to be omitted")
+}
+
+// TestConsoleReporter_SLO verifies that the text and JSON summaries are annotated with the verdict of every
+// service level objective.
+func TestConsoleReporter_SLO(t *testing.T) {
+	objectives, err := slo.ParseObjectives("latency_p99<50,error_rate<1")
+	require.NoError(t, err, "Failed to parse objectives")
+
+	var (
+		output          = new(bytes.Buffer)
+		consoleReporter = loadReporter.NewConsoleReporter(output, time.Second, loadReporter.SummaryBoth,
+			loadReporter.WithObjectives(objectives))
+		metrics = core.NewMetrics()
+	)
+	metrics.TotalOperations = 100
+	metrics.ErrorCount = 5
+	metrics.Latencies = []float64{1, 2, 3}
+
+	require.Nil(t, consoleReporter.SLOReport(), "Expected no verdict before the results")
+	require.NoError(t, consoleReporter.ReportResults(metrics), "Failed to report results")
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 4, "Expected text summary, SLO verdicts and JSON summary lines")
+	assert.True(t, strings.HasPrefix(lines[1], "SLO latency_p99<50: PASS"), "Expected the met objective to pass")
+	assert.True(t, strings.HasPrefix(lines[2], "SLO error_rate<1: FAIL"), "Expected the breached objective to fail")
+
+	var summary loadReporter.Summary
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &summary), "Expected the final line to be valid JSON")
+	require.NotNil(t, summary.SLO, "Expected the JSON summary to carry the verdicts")
+	assert.False(t, summary.SLO.Passed, "Expected the breached objective to fail the report")
+	assert.Len(t, summary.SLO.Verdicts, 2, "Expected a verdict per objective")
+	assert.Equal(t, summary.SLO, consoleReporter.SLOReport(), "Expected the reporter to expose the verdicts")
+}
@@ -0,0 +1,43 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"nats-service/tests/load/infrastructure/reporter"
+	"testing"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCSVReporter_Rows verifies that the output is a header followed by a row per progress report and a summary row.
+func TestCSVReporter_Rows(t *testing.T) {
+	container := SetupTestContainer()
+	csvReporter := container.CSVReporter.Get()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	metrics := core.NewMetrics()
+	metrics.StartTime = start
+	metrics.EndTime = start.Add(10 * time.Second)
+	metrics.TotalOperations = 1000
+	metrics.ErrorCount = 50
+	metrics.Throughput = 100
+
+	first := &core.MetricsSnapshot{Timestamp: start.Add(time.Second), Operations: 100, Errors: 5, RatePerSecond: 100}
+	second := &core.MetricsSnapshot{Timestamp: start.Add(2 * time.Second), Operations: 250, Errors: 7, RatePerSecond: 150.5}
+	require.NoError(t, csvReporter.ReportProgress(first), "Failed to report progress")
+	require.NoError(t, csvReporter.ReportProgress(second), "Failed to report progress")
+	require.NoError(t, csvReporter.ReportResults(metrics), "Failed to report results")
+
+	rows, err := csv.NewReader(container.Output.Get()).ReadAll()
+	require.NoError(t, err, "Expected the output to be valid CSV")
+
+	expected := [][]string{
+		{"type", "timestamp", "operations", "errors", "rate_per_second"},
+		{string(reporter.RecordProgress), "2024-01-01T12:00:01Z", "100", "5", "100.00"},
+		{string(reporter.RecordProgress), "2024-01-01T12:00:02Z", "250", "7", "150.50"},
+		{string(reporter.RecordSummary), "2024-01-01T12:00:10Z", "1000", "50", "100.00"},
+	}
+	assert.Equal(t, expected, rows, "Unexpected CSV rows")
+}
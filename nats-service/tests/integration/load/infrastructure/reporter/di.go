@@ -0,0 +1,44 @@
+package reporter
+
+import (
+	"bytes"
+	"nats-service/tests/load/infrastructure/reporter"
+	"shared/dependency"
+	"time"
+)
+
+// TestContainer holds dependencies for the reporter tests.
+type TestContainer struct {
+	Output          dependency.LazyDependency[*bytes.Buffer]
+	ConsoleReporter dependency.LazyDependency[*reporter.ConsoleReporter]
+	NDJSONReporter  dependency.LazyDependency[*reporter.NDJSONReporter]
+	CSVReporter     dependency.LazyDependency[*reporter.CSVReporter]
+}
+
+// NewTestContainer initializes a new test container.
+func NewTestContainer() *TestContainer {
+	c := &TestContainer{}
+
+	c.Output = dependency.LazyDependency[*bytes.Buffer]{
+		InitFunc: func() *bytes.Buffer {
+			return new(bytes.Buffer)
+		},
+	}
+	c.ConsoleReporter = dependency.LazyDependency[*reporter.ConsoleReporter]{
+		InitFunc: func() *reporter.ConsoleReporter {
+			return reporter.NewConsoleReporter(c.Output.Get(), time.Second, reporter.SummaryBoth)
+		},
+	}
+	c.NDJSONReporter = dependency.LazyDependency[*reporter.NDJSONReporter]{
+		InitFunc: func() *reporter.NDJSONReporter {
+			return reporter.NewNDJSONReporter(c.Output.Get(), true)
+		},
+	}
+	c.CSVReporter = dependency.LazyDependency[*reporter.CSVReporter]{
+		InitFunc: func() *reporter.CSVReporter {
+			return reporter.NewCSVReporter(c.Output.Get())
+		},
+	}
+
+	return c
+}
@@ -0,0 +1,63 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"nats-service/tests/load/infrastructure/reporter"
+	"testing"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNDJSONReporter_Records verifies that the output is valid line-delimited JSON with the expected records.
+func TestNDJSONReporter_Records(t *testing.T) {
+	container := SetupTestContainer()
+	ndjsonReporter := container.NDJSONReporter.Get()
+
+	start := time.Now()
+	metrics := core.NewMetrics()
+	metrics.StartTime = start
+	metrics.EndTime = start.Add(10 * time.Second)
+	metrics.TotalOperations = 1000
+	metrics.ErrorCount = 50
+	metrics.Throughput = 100
+	metrics.Latencies = []float64{1, 2, 3}
+
+	require.NoError(t, ndjsonReporter.ReportProgress(metrics.GetSnapshot()), "Failed to report progress")
+	require.NoError(t, ndjsonReporter.ReportWarmup(metrics), "Failed to report warmup")
+	require.NoError(t, ndjsonReporter.ReportResults(metrics), "Failed to report results")
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(container.Output.Get())
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "Expected every line to be valid JSON")
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+
+	expected := []reporter.RecordType{
+		reporter.RecordProgress,
+		reporter.RecordWarmup,
+		reporter.RecordLatency,
+		reporter.RecordLatency,
+		reporter.RecordLatency,
+		reporter.RecordSummary,
+	}
+	require.Len(t, records, len(expected), "Unexpected number of records")
+	for i, recordType := range expected {
+		assert.Equal(t, string(recordType), records[i]["type"], "Unexpected type of record %d", i)
+	}
+
+	assert.Equal(t, float64(1000), records[0]["operations"])
+	assert.Equal(t, []any{float64(1), float64(2), float64(3)},
+		[]any{records[2]["latency_ms"], records[3]["latency_ms"], records[4]["latency_ms"]})
+
+	summary := records[len(records)-1]
+	assert.Equal(t, float64(1000), summary["total_operations"])
+	assert.InDelta(t, 5, summary["error_rate_percent"], 0.001, "Unexpected error rate")
+	assert.NotContains(t, summary, "latencies_ms", "Expected raw latencies to be omitted from the summary")
+}
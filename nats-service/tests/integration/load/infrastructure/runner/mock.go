@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrPublishFailed is returned by FailingClient.Publish.
+var ErrPublishFailed = errors.New("failing client: publish failed")
+
+// EndingClient is a nats_service.Client whose subscriptions are ended by the server right away.
+type EndingClient struct{}
+
+// Publish is a no-op.
+func (c *EndingClient) Publish(ctx context.Context, subject string, data []byte) error { return nil }
+
+// Subscribe returns immediately, as a stream closed by the server does.
+func (c *EndingClient) Subscribe(
+	ctx context.Context,
+	subject, queueGroup string,
+	handler func(data []byte, subject string),
+) error {
+	return nil
+}
+
+// Close is a no-op.
+func (c *EndingClient) Close() error { return nil }
+
+// FailingClient is a nats_service.Client whose publishes always fail, as with a server that died mid-test.
+type FailingClient struct {
+	EndingClient
+	publishes atomic.Int32 // publishes is the number of Publish calls.
+}
+
+// Publish counts the call and fails.
+func (c *FailingClient) Publish(ctx context.Context, subject string, data []byte) error {
+	c.publishes.Add(1)
+	return ErrPublishFailed
+}
+
+// Publishes returns the number of Publish calls.
+func (c *FailingClient) Publishes() int { return int(c.publishes.Load()) }
+
+// FlakyClient is a nats_service.Client whose first publishes fail with Unavailable, as during a broker blip.
+type FlakyClient struct {
+	EndingClient
+	failures  atomic.Int32 // failures is the number of upcoming Publish calls that fail.
+	publishes atomic.Int32 // publishes is the number of Publish calls.
+}
+
+// NewFlakyClient creates a new instance of FlakyClient failing the next n publishes.
+func NewFlakyClient(n int) *FlakyClient {
+	c := &FlakyClient{}
+	c.failures.Store(int32(n))
+	return c
+}
+
+// Publish counts the call and fails while failures are left.
+func (c *FlakyClient) Publish(ctx context.Context, subject string, data []byte) error {
+	c.publishes.Add(1)
+	if c.failures.Add(-1) >= 0 {
+		return status.Error(codes.Unavailable, "flaky client: broker unavailable")
+	}
+	return nil
+}
+
+// Publishes returns the number of Publish calls.
+func (c *FlakyClient) Publishes() int { return int(c.publishes.Load()) }
@@ -0,0 +1,165 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"nats-service/application/services"
+	"nats-service/tests/integration/corefake"
+	"nats-service/tests/integration/harness"
+	"nats-service/tests/load/infrastructure/metrics"
+	"nats-service/tests/load/infrastructure/runner"
+	"os"
+	"shared/grpc/clients/nats_service"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNatsServicePublishRunner_TargetRate verifies that concurrent workers pacing their publishes together
+// publish at the target rate.
+func TestNatsServicePublishRunner_TargetRate(t *testing.T) {
+	var (
+		logger        = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		publishRunner = runner.NewNatsServicePublishRunner(&EndingClient{}, 64, "load.publish", logger,
+			runner.WithTargetRate(100))
+		publishes atomic.Int32
+		wg        sync.WaitGroup
+	)
+	require.NoError(t, publishRunner.Setup(context.Background()), "Failed to set up runner")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(500)*time.Millisecond)
+	defer cancel()
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for publishRunner.Pace(ctx) == nil {
+				assert.NoError(t, publishRunner.Run(ctx), "Failed to publish")
+				publishes.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 100 msg/s for 500ms, plus the initial token.
+	require.InDelta(t, 51, publishes.Load(), 10, "Expected the publishes to be paced to the target rate")
+}
+
+// TestNatsServicePublishRunner_Unthrottled verifies that without a target rate Pace never waits.
+func TestNatsServicePublishRunner_Unthrottled(t *testing.T) {
+	var (
+		logger        = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		publishRunner = runner.NewNatsServicePublishRunner(&EndingClient{}, 64, "load.publish", logger,
+			runner.WithTargetRate(0))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, publishRunner.Pace(ctx), "Expected an unthrottled runner not to wait")
+}
+
+// TestNatsServicePublishRunner_Retries verifies that a publish failing once with a transient error counts as
+// a successful operation with one recorded retry.
+func TestNatsServicePublishRunner_Retries(t *testing.T) {
+	var (
+		logger        = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		counters      = metrics.NewCounters()
+		client        = NewFlakyClient(1)
+		publishRunner = runner.NewNatsServicePublishRunner(client, 64, "load.publish", logger,
+			runner.WithRetries(2, time.Millisecond, counters))
+	)
+	require.NoError(t, publishRunner.Setup(context.Background()), "Failed to set up runner")
+
+	require.NoError(t, publishRunner.Run(context.Background()), "Expected the retried publish to succeed")
+	require.Equal(t, 2, client.Publishes(), "Expected the failed publish to be retried once")
+	require.Equal(t, float64(1), counters.Get(runner.RetriesMetric), "Expected one recorded retry")
+
+	require.NoError(t, publishRunner.Run(context.Background()), "Expected the publish to succeed")
+	require.Equal(t, float64(1), counters.Get(runner.RetriesMetric), "Expected no retry of a successful publish")
+}
+
+// TestNatsServicePublishRunner_RetriesExhausted verifies that a publish still failing once the retries are
+// exhausted fails the operation, and that retries are disabled by default.
+func TestNatsServicePublishRunner_RetriesExhausted(t *testing.T) {
+	var (
+		logger   = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		counters = metrics.NewCounters()
+		client   = NewFlakyClient(10)
+	)
+
+	retrying := runner.NewNatsServicePublishRunner(client, 64, "load.publish", logger,
+		runner.WithRetries(2, 0, counters))
+	require.Error(t, retrying.Run(context.Background()), "Expected the publish to fail once the retries are exhausted")
+	require.Equal(t, 3, client.Publishes(), "Expected the publish and two retries")
+	require.Equal(t, float64(2), counters.Get(runner.RetriesMetric), "Expected two recorded retries")
+
+	defaults := runner.NewNatsServicePublishRunner(client, 64, "load.publish", logger)
+	require.Error(t, defaults.Run(context.Background()), "Expected the publish to fail without retries")
+	require.Equal(t, 4, client.Publishes(), "Expected no retry by default")
+
+	failing := &FailingClient{}
+	permanent := runner.NewNatsServicePublishRunner(failing, 64, "load.publish", logger,
+		runner.WithRetries(2, 0, counters))
+	require.ErrorIs(t, permanent.Run(context.Background()), ErrPublishFailed, "Expected the publish to fail")
+	require.Equal(t, 1, failing.Publishes(), "Expected no retry of a non-transient error")
+}
+
+// TestNatsServicePublishRunner_JetStream verifies under JetStream that every Run returns once the message is
+// persisted, so the runner records a positive publish-to-ack latency. It is skipped when the broker has no JetStream.
+func TestNatsServicePublishRunner_JetStream(t *testing.T) {
+	if corefake.Enabled() {
+		t.Skip("The core NATS fake has no JetStream")
+	}
+	address, err := corefake.Address()
+	require.NoError(t, err, "Failed to resolve the NATS address")
+	conn, err := nats.Connect(address)
+	require.NoError(t, err, "Failed to connect to NATS")
+	t.Cleanup(conn.Close)
+	js, err := conn.JetStream()
+	require.NoError(t, err, "Failed to create JetStream context")
+	if _, err = js.AccountInfo(); err != nil {
+		t.Skipf("JetStream is not available: %v", err)
+	}
+
+	var (
+		logger    = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		stream    = "LOAD_JETSTREAM_PUBLISH"
+		subject   = "load.jetstream.publish"
+		publishes = 5
+		bus       = harness.NewWithOperations(t, services.NewOperations(conn, logger))
+	)
+	client, err := nats_service.NewNatsClient("dev", bus.Address, nats_service.NewBusClientValidator(), logger)
+	require.NoError(t, err, "Failed to create in-process NATS client")
+	t.Cleanup(func() { _ = js.DeleteStream(stream) })
+
+	publishRunner := runner.NewNatsServicePublishRunner(client, 64, subject, logger, runner.WithJetStream(true))
+	require.NoError(t, publishRunner.Setup(context.Background()), "Failed to set up runner")
+	defer func() { _ = publishRunner.Teardown(context.Background()) }()
+
+	for range publishes {
+		start := time.Now()
+		require.NoError(t, publishRunner.Run(context.Background()), "Failed to publish through JetStream")
+		require.Positive(t, time.Since(start), "Expected a positive publish-ack latency")
+	}
+
+	info, err := js.StreamInfo(stream)
+	require.NoError(t, err, "Expected the stream to be created on the first publish")
+	require.Equal(t, uint64(publishes), info.State.Msgs, "Expected every acked message to be persisted")
+}
+
+// TestNatsServicePublishRunner_JetStreamUnsupported verifies that the setup of a JetStream runner fails when the
+// client cannot publish through JetStream.
+func TestNatsServicePublishRunner_JetStreamUnsupported(t *testing.T) {
+	var (
+		logger        = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		publishRunner = runner.NewNatsServicePublishRunner(&EndingClient{}, 64, "load.publish", logger,
+			runner.WithJetStream(true))
+	)
+
+	require.Error(t, publishRunner.Setup(context.Background()), "Expected the setup to fail without JetStream support")
+}
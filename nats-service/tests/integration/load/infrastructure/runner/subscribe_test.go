@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"nats-service/tests/integration/harness"
+	"nats-service/tests/load/infrastructure/runner"
+	"os"
+	"runtime"
+	"shared/grpc/clients/nats_service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNatsServiceSubscribeRunner_NoGoroutineLeak verifies that a full Setup/Run/Teardown cycle,
+// with a repeated Setup, leaves no publisher, subscriber or client goroutine behind.
+func TestNatsServiceSubscribeRunner_NoGoroutineLeak(t *testing.T) {
+	var (
+		bus    = harness.New(t)
+		logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+	)
+	baseline := runtime.NumGoroutine()
+
+	client, err := nats_service.NewNatsClient("dev", bus.Address, nats_service.NewBusClientValidator(), logger)
+	require.NoError(t, err, "Failed to create in-process NATS client")
+
+	subscribeRunner := runner.NewNatsServiceSubscribeRunner(client, "load.subscribe", "", 64, 2,
+		time.Duration(10)*time.Millisecond, time.Second, logger)
+	require.NoError(t, subscribeRunner.Setup(context.Background()), "Failed to set up runner")
+	require.NoError(t, subscribeRunner.Setup(context.Background()), "Failed to set up runner again")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(200)*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, subscribeRunner.Run(ctx), context.DeadlineExceeded, "Expected Run to last until the deadline")
+	require.Positive(t, bus.Operations.Published(), "Expected the publisher to run")
+
+	require.NoError(t, subscribeRunner.Teardown(context.Background()), "Failed to tear down runner")
+	waitForGoroutines(t, baseline)
+}
+
+// TestNatsServiceSubscribeRunner_StreamEnded verifies that Run returns once the server ends the subscription
+// instead of waiting for the context.
+func TestNatsServiceSubscribeRunner_StreamEnded(t *testing.T) {
+	var (
+		logger          = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		subscribeRunner = runner.NewNatsServiceSubscribeRunner(&EndingClient{}, "load.subscribe", "", 64, 1,
+			time.Hour, time.Second, logger)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+	err := subscribeRunner.Run(ctx)
+	require.Error(t, err, "Expected an ended subscription to be reported")
+	require.NoError(t, ctx.Err(), "Expected Run to return before the deadline")
+}
+
+// TestNatsServiceSubscribeRunner_PublisherStops verifies that the publisher backs off after failed publishes
+// and stops after the configured number of consecutive failures, while a shutdown still stops it at once.
+func TestNatsServiceSubscribeRunner_PublisherStops(t *testing.T) {
+	var (
+		logger          = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		client          = &FailingClient{}
+		maxFailures     = 4
+		subscribeRunner = runner.NewNatsServiceSubscribeRunner(client, "load.subscribe", "", 64, 1,
+			time.Millisecond, time.Second, logger, runner.WithMaxPublishFailures(maxFailures))
+	)
+	baseline := runtime.NumGoroutine()
+
+	start := time.Now()
+	require.NoError(t, subscribeRunner.Setup(context.Background()), "Failed to set up runner")
+	require.Eventually(t, func() bool { return client.Publishes() == maxFailures },
+		time.Duration(2)*time.Second, time.Millisecond, "Expected the publisher to stop after consecutive failures")
+	// The delays double after each failure: 1ms, then 2ms, 4ms and 8ms
+	require.GreaterOrEqual(t, time.Since(start), time.Duration(15)*time.Millisecond,
+		"Expected the publisher to back off between failures")
+
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	require.Equal(t, maxFailures, client.Publishes(), "Expected no publish after the publisher stopped")
+	require.NoError(t, subscribeRunner.Teardown(context.Background()), "Failed to tear down runner")
+
+	// Without a limit the publisher keeps backing off until the shutdown
+	unlimited := runner.NewNatsServiceSubscribeRunner(client, "load.subscribe", "", 64, 1,
+		time.Millisecond, time.Second, logger, runner.WithMaxPublishFailures(0))
+	require.NoError(t, unlimited.Setup(context.Background()), "Failed to set up runner")
+	require.Eventually(t, func() bool { return client.Publishes() > 2*maxFailures },
+		time.Duration(2)*time.Second, time.Duration(5)*time.Millisecond, "Expected the publisher to keep retrying")
+	require.NoError(t, unlimited.Teardown(context.Background()), "Failed to tear down runner")
+	waitForGoroutines(t, baseline)
+}
+
+// waitForGoroutines fails the test unless the number of goroutines drops back to baseline.
+// It polls in the test goroutine, require.Eventually would count its own goroutines.
+func waitForGoroutines(t *testing.T, baseline int) {
+	deadline := time.Now().Add(time.Duration(2) * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("Goroutines leaked: %d > %d\n%s", runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(time.Duration(10) * time.Millisecond)
+	}
+}
@@ -0,0 +1,52 @@
+package slo
+
+import (
+	"nats-service/tests/load/infrastructure/slo"
+	"testing"
+
+	"github.com/mguley/go-loadtest/pkg/reporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvaluate verifies the per-objective verdicts and headroom of results meeting some objectives and breaching
+// others.
+func TestEvaluate(t *testing.T) {
+	objectives, err := slo.ParseObjectives("latency_p99<50, error_rate<1,throughput>100,latency_p50<5")
+	require.NoError(t, err, "Failed to parse objectives")
+	require.Len(t, objectives, 4, "Expected four objectives")
+
+	result := &reporter.ResultOutput{LatencyP50: 2, LatencyP99: 62.5, ErrorRate: 0.25, Throughput: 80}
+	report := slo.Evaluate(result, objectives)
+
+	require.False(t, report.Passed, "Expected the breached objectives to fail the report")
+	require.Len(t, report.Verdicts, 4, "Expected a verdict per objective")
+	for i, expected := range []slo.Verdict{
+		{Objective: "latency_p99<50", Actual: 62.5, Headroom: -12.5, Passed: false},
+		{Objective: "error_rate<1", Actual: 0.25, Headroom: 0.75, Passed: true},
+		{Objective: "throughput>100", Actual: 80, Headroom: -20, Passed: false},
+		{Objective: "latency_p50<5", Actual: 2, Headroom: 3, Passed: true},
+	} {
+		verdict := report.Verdicts[i]
+		assert.Equal(t, expected.Objective, verdict.Objective, "Unexpected objective")
+		assert.InDelta(t, expected.Actual, verdict.Actual, 0.001, "Unexpected actual value of %s", verdict.Objective)
+		assert.InDelta(t, expected.Headroom, verdict.Headroom, 0.001, "Unexpected headroom of %s", verdict.Objective)
+		assert.Equal(t, expected.Passed, verdict.Passed, "Unexpected verdict of %s", verdict.Objective)
+	}
+
+	met := slo.Evaluate(&reporter.ResultOutput{LatencyP50: 2, LatencyP99: 40, Throughput: 150}, objectives)
+	assert.True(t, met.Passed, "Expected results within every objective to pass")
+}
+
+// TestParseObjectives_Invalid verifies that malformed definitions and unknown metrics are rejected, and that an
+// empty definition defines no objective.
+func TestParseObjectives_Invalid(t *testing.T) {
+	for _, definitions := range []string{"latency_p99", "<50", "latency_p42<50", "error_rate<one"} {
+		_, err := slo.ParseObjectives(definitions)
+		assert.Error(t, err, "Expected %q to be rejected", definitions)
+	}
+
+	objectives, err := slo.ParseObjectives("")
+	require.NoError(t, err, "Expected an empty definition to be accepted")
+	assert.Empty(t, objectives, "Expected no objective")
+}
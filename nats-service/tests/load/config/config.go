@@ -25,42 +25,78 @@ const (
 // LoadTestConfig holds configuration parameters for NATS service load tests.
 //
 // Fields:
-//   - Duration:          Total duration of the load test.
-//   - Concurrency:       Number of concurrent operations during the test.
-//   - MaxSubscribers:    Maximum number of concurrent subscribers (used in subscribe tests).
-//   - WarmupDuration:    Duration of the warmup period before the actual test begins.
-//   - ReportInterval:    Interval at which progress reports are generated during the test.
-//   - PublishInterval:   Interval between published messages (used in subscribe tests).
-//   - SubscribeTimeout:  Timeout duration for subscription operations.
-//   - LogLevel:          Logging level (e.g., "info", "debug").
-//   - OutputPath:        File path for JSON-formatted test results output.
-//   - Tags:              Custom metadata tags for the load test.
-//   - TestType:          Type of load test to execute ("publish" or "subscribe").
-//   - RpcHost:           Hostname or IP address of the gRPC server.
-//   - RpcPort:           Port number of the gRPC server.
-//   - Subject:           NATS subject for publishing or subscribing to messages.
-//   - QueueGroup:        Queue group name for subscription tests (used for load balancing).
-//   - MessageSize:       Size of the message payload (in bytes).
+//   - Duration:           Total duration of the load test.
+//   - MaxOperations:      Number of successful operations ending the load test before its duration, 0 if unlimited.
+//   - Concurrency:        Number of concurrent operations during the test.
+//   - MaxSubscribers:     Maximum number of concurrent subscribers (used in subscribe tests).
+//   - WarmupDuration:     Duration of the warmup period before the actual test begins.
+//   - WarmupMetrics:      Whether warmup metrics are collected and reported separately instead of discarded.
+//   - ReportInterval:     Interval at which progress reports are generated during the test.
+//   - PublishInterval:    Interval between published messages (used in subscribe tests).
+//   - MaxPublishFailures: Consecutive publish failures stopping the subscribe test publisher, 0 never stops it.
+//   - TargetRate:         Target throughput of publish tests in messages per second, 0 publishes unthrottled.
+//   - PublishRetries:     Retries of a transiently failed publish in publish tests, 0 disables the retries.
+//   - RetryBackoff:       Wait before each retry of a failed publish.
+//   - JetStream:          Whether publish tests publish through JetStream, measuring the publish-to-ack latency.
+//   - SubscribeTimeout:   Timeout duration for subscription operations.
+//   - OperationTimeout:   Timeout of a single test operation, 0 disables it.
+//   - LogLevel:           Logging level (e.g., "info", "debug").
+//   - OutputPath:         File path for JSON-formatted test results output.
+//   - ConsoleSummary:     Format of the final console summary ("text", "json" or "both").
+//   - NDJSONPath:         File path of the streamed NDJSON results, disabled if empty.
+//   - NDJSONLatencies:    Whether raw latency samples are streamed to the NDJSON results.
+//   - CSVPath:            File path of the per-interval CSV progress, disabled if empty.
+//   - Tags:               Custom metadata tags for the load test.
+//   - SLO:                Comma-separated service level objectives, e.g. "latency_p99<50,error_rate<1".
+//   - TestType:           Type of load test to execute ("publish" or "subscribe").
+//   - RpcHost:            Hostname or IP address of the gRPC server.
+//   - RpcPort:            Port number of the gRPC server.
+//   - ProdHostPattern:    Regular expression matching production gRPC hosts.
+//   - AllowProd:          Explicit override allowing a load test against a production host.
+//   - Subject:            NATS subject for publishing or subscribing to messages.
+//   - Namespace:          Subject namespace isolating load test traffic, mandatory in prod.
+//   - RunId:              Identifier of the load test run, part of the namespaced subject.
+//   - Env:                Environment the load test targets (e.g., "dev", "prod").
+//   - QueueGroup:         Queue group name for subscription tests (used for load balancing).
+//   - MessageSize:        Size of the message payload (in bytes).
 type LoadTestConfig struct {
 	// Common test configuration.
-	Duration         time.Duration
-	Concurrency      int
-	MaxSubscribers   int
-	WarmupDuration   time.Duration
-	ReportInterval   time.Duration
-	PublishInterval  time.Duration
-	SubscribeTimeout time.Duration
-	LogLevel         string
-	OutputPath       string
-	Tags             map[string]string
+	Duration           time.Duration
+	MaxOperations      int
+	Concurrency        int
+	MaxSubscribers     int
+	WarmupDuration     time.Duration
+	WarmupMetrics      bool
+	ReportInterval     time.Duration
+	PublishInterval    time.Duration
+	MaxPublishFailures int
+	TargetRate         int
+	PublishRetries     int
+	RetryBackoff       time.Duration
+	JetStream          bool
+	SubscribeTimeout   time.Duration
+	OperationTimeout   time.Duration
+	LogLevel           string
+	OutputPath         string
+	ConsoleSummary     string
+	NDJSONPath         string
+	NDJSONLatencies    bool
+	CSVPath            string
+	Tags               map[string]string
+	SLO                string
 
 	// Service specific configuration.
-	TestType    string
-	RpcHost     string
-	RpcPort     string
-	Subject     string
-	QueueGroup  string
-	MessageSize int
+	TestType        string
+	RpcHost         string
+	RpcPort         string
+	ProdHostPattern string
+	AllowProd       bool
+	Subject         string
+	Namespace       string
+	RunId           string
+	Env             string
+	QueueGroup      string
+	MessageSize     int
 }
 
 var (
@@ -84,27 +120,62 @@ func GetConfig() *LoadTestConfig {
 // Returns:
 //   - *LoadTestConfig: A pointer to the populated load test configuration.
 func loadConfig() *LoadTestConfig {
-	return &LoadTestConfig{
+	cfg := &LoadTestConfig{
 		// Common test configuration with default values.
-		Duration:         getDurationEnv("LOAD_TEST_DURATION", time.Duration(30)*time.Second),
-		Concurrency:      getIntEnv("LOAD_TEST_CONCURRENCY", 10),
-		MaxSubscribers:   getIntEnv("LOAD_TEST_MAX_SUBSCRIBERS", 10),
-		WarmupDuration:   getDurationEnv("LOAD_TEST_WARMUP", time.Duration(5)*time.Second),
-		ReportInterval:   getDurationEnv("LOAD_TEST_REPORT_INTERVAL", time.Duration(1)*time.Second),
-		PublishInterval:  getDurationEnv("LOAD_TEST_PUBLISH_INTERVAL", time.Duration(50)*time.Millisecond),
-		SubscribeTimeout: getDurationEnv("LOAD_TEST_SUBSCRIBE_TIMEOUT", time.Duration(30)*time.Second),
-		LogLevel:         getEnv("LOAD_TEST_LOG_LEVEL", "info"),
-		OutputPath:       getEnv("LOAD_TEST_OUTPUT_PATH", ""),
-		Tags:             parseTags(getEnv("LOAD_TEST_TAGS", "")),
+		Duration:           getDurationEnv("LOAD_TEST_DURATION", time.Duration(30)*time.Second),
+		MaxOperations:      getIntEnv("LOAD_TEST_MAX_OPERATIONS", 0),
+		Concurrency:        getIntEnv("LOAD_TEST_CONCURRENCY", 10),
+		MaxSubscribers:     getIntEnv("LOAD_TEST_MAX_SUBSCRIBERS", 10),
+		WarmupDuration:     getDurationEnv("LOAD_TEST_WARMUP", time.Duration(5)*time.Second),
+		WarmupMetrics:      getBoolEnv("LOAD_TEST_WARMUP_METRICS", false),
+		ReportInterval:     getDurationEnv("LOAD_TEST_REPORT_INTERVAL", time.Duration(1)*time.Second),
+		PublishInterval:    getDurationEnv("LOAD_TEST_PUBLISH_INTERVAL", time.Duration(50)*time.Millisecond),
+		MaxPublishFailures: getIntEnv("LOAD_TEST_MAX_PUBLISH_FAILURES", 10),
+		TargetRate:         getIntEnv("LOAD_TEST_TARGET_RATE", 0),
+		PublishRetries:     getIntEnv("LOAD_TEST_PUBLISH_RETRIES", 0),
+		RetryBackoff:       getDurationEnv("LOAD_TEST_RETRY_BACKOFF", time.Duration(10)*time.Millisecond),
+		JetStream:          getBoolEnv("LOAD_TEST_JETSTREAM", false),
+		SubscribeTimeout:   getDurationEnv("LOAD_TEST_SUBSCRIBE_TIMEOUT", time.Duration(30)*time.Second),
+		OperationTimeout:   getDurationEnv("LOAD_TEST_OPERATION_TIMEOUT", 0),
+		LogLevel:           getEnv("LOAD_TEST_LOG_LEVEL", "info"),
+		OutputPath:         getEnv("LOAD_TEST_OUTPUT_PATH", ""),
+		ConsoleSummary:     getEnv("LOAD_TEST_CONSOLE_SUMMARY", "text"),
+		NDJSONPath:         getEnv("LOAD_TEST_NDJSON_PATH", ""),
+		NDJSONLatencies:    getBoolEnv("LOAD_TEST_NDJSON_LATENCIES", false),
+		CSVPath:            getEnv("LOAD_TEST_CSV_PATH", ""),
+		Tags:               parseTags(getEnv("LOAD_TEST_TAGS", "")),
+		SLO:                getEnv("LOAD_TEST_SLO", ""),
 
 		// Service specific configuration.
-		TestType:    getEnv("LOAD_TEST_TYPE", "publish"),
-		RpcHost:     getEnv("NATS_RPC_HOST", ""),
-		RpcPort:     getEnv("NATS_RPC_PORT", ""),
-		Subject:     getEnv("LOAD_TEST_SUBJECT", "load.test"),
-		QueueGroup:  getEnv("LOAD_TEST_QUEUE_GROUP", ""),
-		MessageSize: getIntEnv("LOAD_TEST_MESSAGE_SIZE", 1024),
+		TestType:        getEnv("LOAD_TEST_TYPE", "publish"),
+		RpcHost:         getEnv("NATS_RPC_HOST", ""),
+		RpcPort:         getEnv("NATS_RPC_PORT", ""),
+		ProdHostPattern: getEnv("LOAD_TEST_PROD_HOST_PATTERN", ""),
+		AllowProd:       getBoolEnv("LOAD_TEST_ALLOW_PROD", false),
+		Subject:         getEnv("LOAD_TEST_SUBJECT", "load.test"),
+		Namespace:       getEnv("LOAD_TEST_NAMESPACE", ""),
+		RunId:           getEnv("LOAD_TEST_RUN_ID", strconv.FormatInt(time.Now().Unix(), 10)),
+		Env:             getEnv("LOAD_TEST_ENV", "dev"),
+		QueueGroup:      getEnv("LOAD_TEST_QUEUE_GROUP", ""),
+		MessageSize:     getIntEnv("LOAD_TEST_MESSAGE_SIZE", 1024),
 	}
+
+	if cfg.Env == "prod" && cfg.Namespace == "" {
+		panic("load test configuration error: LOAD_TEST_NAMESPACE is required in prod")
+	}
+	return cfg
+}
+
+// EffectiveSubject returns the NATS subject used by the load test.
+// When a namespace is configured, the subject is isolated as "<namespace>.<runId>.<subject>".
+//
+// Returns:
+//   - string: The effective NATS subject.
+func (c *LoadTestConfig) EffectiveSubject() string {
+	if c.Namespace == "" {
+		return c.Subject
+	}
+	return strings.Join([]string{c.Namespace, c.RunId, c.Subject}, ".")
 }
 
 // getEnv retrieves the value of an environment variable or returns a fallback value.
@@ -139,6 +210,23 @@ func getIntEnv(key string, fallback int) int {
 	return fallback
 }
 
+// getBoolEnv retrieves a boolean value from an environment variable.
+//
+// Parameters:
+//   - key:      The environment variable name.
+//   - fallback: Default boolean value if parsing fails or variable is not set.
+//
+// Returns:
+//   - bool: The parsed boolean value or the fallback.
+func getBoolEnv(key string, fallback bool) bool {
+	if v, ok := os.LookupEnv(key); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
 // getDurationEnv retrieves a time.Duration value from an environment variable.
 //
 // Parameters:
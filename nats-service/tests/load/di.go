@@ -4,15 +4,17 @@ import (
 	"fmt"
 	"log/slog"
 	"nats-service/tests/load/config"
+	loadCollector "nats-service/tests/load/infrastructure/collector"
+	"nats-service/tests/load/infrastructure/orchestrator"
+	"nats-service/tests/load/infrastructure/reporter"
 	"nats-service/tests/load/infrastructure/runner"
+	"nats-service/tests/load/infrastructure/slo"
 	"os"
 	"shared/dependency"
 	"shared/grpc/clients/nats_service"
 
-	"github.com/mguley/go-loadtest/pkg"
 	"github.com/mguley/go-loadtest/pkg/collector"
 	"github.com/mguley/go-loadtest/pkg/core"
-	"github.com/mguley/go-loadtest/pkg/reporter"
 )
 
 // Container aggregates all the dependencies required to run the load test.
@@ -27,17 +29,21 @@ import (
 //   - SystemCollector:          Collector for system metrics.
 //   - CompositeCollector:       Composite collector to aggregate multiple collectors.
 //   - ConsoleReporter:          Reporter that outputs test results to the console.
+//   - NDJSONReporter:           Reporter that streams test results to an NDJSON file.
+//   - CSVReporter:              Reporter that writes the per-interval progress to a CSV file.
 type Container struct {
 	Config                   dependency.LazyDependency[*config.LoadTestConfig]
 	Logger                   dependency.LazyDependency[*slog.Logger]
-	Orchestrator             dependency.LazyDependency[*pkg.Orchestrator]
+	Orchestrator             dependency.LazyDependency[*orchestrator.Orchestrator]
 	NatsRpcClient            dependency.LazyDependency[*nats_service.NatsClient]
 	NatsRpcValidator         dependency.LazyDependency[nats_service.Validator]
 	NatsServiceRunnerFactory dependency.LazyDependency[*runner.NatsServiceRunnerFactory]
 	TestType                 config.LoadTestType
-	SystemCollector          dependency.LazyDependency[*collector.SystemCollector]
+	SystemCollector          dependency.LazyDependency[*loadCollector.SystemCollector]
 	CompositeCollector       dependency.LazyDependency[*collector.CompositeCollector]
 	ConsoleReporter          dependency.LazyDependency[*reporter.ConsoleReporter]
+	NDJSONReporter           dependency.LazyDependency[*reporter.NDJSONReporter]
+	CSVReporter              dependency.LazyDependency[*reporter.CSVReporter]
 }
 
 // NewContainer creates and initializes a new Container with all required dependencies
@@ -73,8 +79,8 @@ func NewContainer() *Container {
 			return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
 		},
 	}
-	c.Orchestrator = dependency.LazyDependency[*pkg.Orchestrator]{
-		InitFunc: func() *pkg.Orchestrator {
+	c.Orchestrator = dependency.LazyDependency[*orchestrator.Orchestrator]{
+		InitFunc: func() *orchestrator.Orchestrator {
 			var (
 				logger     = c.Logger.Get()
 				cfg        = c.Config.Get()
@@ -87,7 +93,14 @@ func NewContainer() *Container {
 					Tags:           cfg.Tags,
 				}
 			)
-			return pkg.NewOrchestrator(testConfig, logger)
+			opts := []orchestrator.Option{
+				orchestrator.WithOperationTimeout(cfg.OperationTimeout),
+				orchestrator.WithMaxOperations(int64(cfg.MaxOperations)),
+			}
+			if cfg.WarmupMetrics {
+				opts = append(opts, orchestrator.WithWarmupMetrics())
+			}
+			return orchestrator.NewOrchestrator(testConfig, logger, opts...)
 		},
 	}
 	c.NatsRpcValidator = dependency.LazyDependency[nats_service.Validator]{
@@ -117,9 +130,10 @@ func NewContainer() *Container {
 			var (
 				natsRpcClient = c.NatsRpcClient.Get()
 				cfg           = c.Config.Get()
+				counters      = c.Orchestrator.Get().Counters()
 				logger        = c.Logger.Get()
 			)
-			return runner.NewNatsServiceRunnerFactory(natsRpcClient, cfg, logger)
+			return runner.NewNatsServiceRunnerFactory(natsRpcClient, cfg, counters, logger)
 		},
 	}
 	c.TestType = func() config.LoadTestType {
@@ -133,9 +147,9 @@ func NewContainer() *Container {
 			panic(fmt.Sprintf("unknown load test type: %s", cfg.TestType))
 		}
 	}()
-	c.SystemCollector = dependency.LazyDependency[*collector.SystemCollector]{
-		InitFunc: func() *collector.SystemCollector {
-			return collector.NewSystemCollector(c.Logger.Get())
+	c.SystemCollector = dependency.LazyDependency[*loadCollector.SystemCollector]{
+		InitFunc: func() *loadCollector.SystemCollector {
+			return loadCollector.NewSystemCollector(c.Logger.Get())
 		},
 	}
 	c.CompositeCollector = dependency.LazyDependency[*collector.CompositeCollector]{
@@ -145,7 +159,34 @@ func NewContainer() *Container {
 	}
 	c.ConsoleReporter = dependency.LazyDependency[*reporter.ConsoleReporter]{
 		InitFunc: func() *reporter.ConsoleReporter {
-			return reporter.NewConsoleReporter(os.Stdout, c.Config.Get().ReportInterval)
+			var (
+				cfg  = c.Config.Get()
+				mode = reporter.SummaryMode(cfg.ConsoleSummary)
+			)
+			objectives, err := slo.ParseObjectives(cfg.SLO)
+			if err != nil {
+				panic(fmt.Sprintf("invalid service level objectives: %v", err))
+			}
+			return reporter.NewConsoleReporter(os.Stdout, cfg.ReportInterval, mode, reporter.WithObjectives(objectives))
+		},
+	}
+	c.NDJSONReporter = dependency.LazyDependency[*reporter.NDJSONReporter]{
+		InitFunc: func() *reporter.NDJSONReporter {
+			cfg := c.Config.Get()
+			file, err := os.Create(cfg.NDJSONPath)
+			if err != nil {
+				panic(fmt.Sprintf("failed to create NDJSON output file: %v", err))
+			}
+			return reporter.NewNDJSONReporter(file, cfg.NDJSONLatencies)
+		},
+	}
+	c.CSVReporter = dependency.LazyDependency[*reporter.CSVReporter]{
+		InitFunc: func() *reporter.CSVReporter {
+			file, err := os.Create(c.Config.Get().CSVPath)
+			if err != nil {
+				panic(fmt.Sprintf("failed to create CSV output file: %v", err))
+			}
+			return reporter.NewCSVReporter(file)
 		},
 	}
 
@@ -0,0 +1,43 @@
+package load
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"nats-service/tests/load/config"
+	"regexp"
+)
+
+// ErrProdTarget is returned when a load test targets a production host without the explicit override.
+var ErrProdTarget = errors.New("load test targets a production host")
+
+// CheckTarget refuses to run a load test against a host matching the configured production pattern,
+// unless the AllowProd override is set, in which case a warning is logged.
+//
+// Parameters:
+//   - cfg:    Pointer to the load test configuration.
+//   - logger: Logger used to report an overridden production target.
+//
+// Returns:
+//   - error: ErrProdTarget if the target is a production host without the override, otherwise nil.
+func CheckTarget(cfg *config.LoadTestConfig, logger *slog.Logger) (err error) {
+	if cfg.ProdHostPattern == "" {
+		return nil
+	}
+
+	var pattern *regexp.Regexp
+	if pattern, err = regexp.Compile(cfg.ProdHostPattern); err != nil {
+		return fmt.Errorf("invalid production host pattern: %w", err)
+	}
+	if !pattern.MatchString(cfg.RpcHost) {
+		return nil
+	}
+
+	if !cfg.AllowProd {
+		return fmt.Errorf("%w: %s (set LOAD_TEST_ALLOW_PROD=true to override)", ErrProdTarget, cfg.RpcHost)
+	}
+	logger.Warn("!!! RUNNING LOAD TEST AGAINST A PRODUCTION HOST !!!",
+		slog.String("host", cfg.RpcHost),
+		slog.String("pattern", cfg.ProdHostPattern))
+	return nil
+}
@@ -0,0 +1,106 @@
+package comparator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mguley/go-loadtest/pkg/reporter"
+)
+
+// Tolerances defines the accepted regressions between a baseline and a current load test run.
+//
+// Fields:
+//   - MaxThroughputDropPercent:   Maximum accepted throughput decrease, in percent of the baseline.
+//   - MaxLatencyP99RisePercent:   Maximum accepted p99 latency increase, in percent of the baseline.
+//   - MaxErrorRateRisePercentage: Maximum accepted error rate increase, in percentage points.
+type Tolerances struct {
+	MaxThroughputDropPercent   float64
+	MaxLatencyP99RisePercent   float64
+	MaxErrorRateRisePercentage float64
+}
+
+// Diff holds the deltas between a baseline and a current load test run and the resulting verdict.
+//
+// Fields:
+//   - ThroughputDeltaPercent: Throughput change, in percent of the baseline.
+//   - LatencyP99DeltaPercent: p99 latency change, in percent of the baseline.
+//   - ErrorRateDelta:         Error rate change, in percentage points.
+//   - Passed:                 True if all deltas are within the tolerances.
+//   - Violations:             Human-readable descriptions of the exceeded tolerances.
+type Diff struct {
+	ThroughputDeltaPercent float64  `json:"throughput_delta_percent"`
+	LatencyP99DeltaPercent float64  `json:"latency_p99_delta_percent"`
+	ErrorRateDelta         float64  `json:"error_rate_delta_percent"`
+	Passed                 bool     `json:"passed"`
+	Violations             []string `json:"violations,omitempty"`
+}
+
+// LoadResult reads a load test result written by the JSON reporter.
+//
+// Parameters:
+//   - path: The path of the JSON result file.
+//
+// Returns:
+//   - *reporter.ResultOutput: A pointer to the decoded result.
+//   - error:                  An error if the file cannot be read or decoded, otherwise nil.
+func LoadResult(path string) (result *reporter.ResultOutput, err error) {
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("read result %s: %w", path, err)
+	}
+
+	result = new(reporter.ResultOutput)
+	if err = json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("decode result %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Compare computes the deltas between a baseline and a current result and checks them against the tolerances.
+//
+// Parameters:
+//   - baseline:   A pointer to the baseline result.
+//   - current:    A pointer to the current result.
+//   - tolerances: The accepted regressions.
+//
+// Returns:
+//   - *Diff: A pointer to the computed diff and verdict.
+func Compare(baseline, current *reporter.ResultOutput, tolerances Tolerances) *Diff {
+	diff := &Diff{
+		ThroughputDeltaPercent: percentChange(baseline.Throughput, current.Throughput),
+		LatencyP99DeltaPercent: percentChange(baseline.LatencyP99, current.LatencyP99),
+		ErrorRateDelta:         current.ErrorRate - baseline.ErrorRate,
+	}
+
+	if -diff.ThroughputDeltaPercent > tolerances.MaxThroughputDropPercent {
+		diff.Violations = append(diff.Violations, fmt.Sprintf("throughput dropped by %.2f%% (tolerance %.2f%%)",
+			-diff.ThroughputDeltaPercent, tolerances.MaxThroughputDropPercent))
+	}
+	if diff.LatencyP99DeltaPercent > tolerances.MaxLatencyP99RisePercent {
+		diff.Violations = append(diff.Violations, fmt.Sprintf("p99 latency rose by %.2f%% (tolerance %.2f%%)",
+			diff.LatencyP99DeltaPercent, tolerances.MaxLatencyP99RisePercent))
+	}
+	if diff.ErrorRateDelta > tolerances.MaxErrorRateRisePercentage {
+		diff.Violations = append(diff.Violations, fmt.Sprintf("error rate rose by %.2f points (tolerance %.2f)",
+			diff.ErrorRateDelta, tolerances.MaxErrorRateRisePercentage))
+	}
+
+	diff.Passed = len(diff.Violations) == 0
+	return diff
+}
+
+// percentChange returns the change from baseline to current in percent of the baseline.
+//
+// Parameters:
+//   - baseline: The baseline value.
+//   - current:  The current value.
+//
+// Returns:
+//   - float64: The relative change in percent, 0 if the baseline is 0.
+func percentChange(baseline, current float64) float64 {
+	if baseline == 0 {
+		return 0
+	}
+	return (current - baseline) / baseline * 100
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// Counters holds increment-style custom metrics, such as categorized error counts.
+// Unlike the core.Metrics custom metrics, which are overwritten on every set and merged last-writer-wins,
+// counters are incremented under a lock and summed on merge.
+//
+// Fields:
+//   - mu:     Mutex guarding the counter values.
+//   - values: Map of the counter values keyed by metric name.
+type Counters struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounters creates an empty set of counters.
+//
+// Returns:
+//   - *Counters: A pointer to an empty Counters instance.
+func NewCounters() *Counters {
+	return &Counters{values: make(map[string]float64)}
+}
+
+// IncrementCustomMetric atomically adds delta to the named counter, creating it if needed.
+//
+// Parameters:
+//   - name:  The name of the custom metric.
+//   - delta: The value to add to the custom metric.
+func (c *Counters) IncrementCustomMetric(name string, delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[name] += delta
+}
+
+// AddCustomMetric atomically adds every delta of the batch to the corresponding named counter.
+//
+// Parameters:
+//   - deltas: Map of the values to add, keyed by metric name.
+func (c *Counters) AddCustomMetric(deltas map[string]float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, delta := range deltas {
+		c.values[name] += delta
+	}
+}
+
+// Get returns the current value of the named counter.
+//
+// Parameters:
+//   - name: The name of the custom metric.
+//
+// Returns:
+//   - float64: The counter value, zero if the counter does not exist.
+func (c *Counters) Get(name string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[name]
+}
+
+// Snapshot returns a copy of all counter values.
+//
+// Returns:
+//   - map[string]float64: A copy of the counter values keyed by metric name.
+func (c *Counters) Snapshot() map[string]float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := make(map[string]float64, len(c.values))
+	for name, value := range c.values {
+		snapshot[name] = value
+	}
+	return snapshot
+}
+
+// Merge sums the counters of another Counters instance into the current one.
+//
+// Parameters:
+//   - other: A pointer to another Counters instance to merge.
+func (c *Counters) Merge(other *Counters) {
+	if other == nil || other == c {
+		return
+	}
+	c.AddCustomMetric(other.Snapshot())
+}
+
+// Reset removes all counters.
+func (c *Counters) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = make(map[string]float64)
+}
+
+// ApplyTo sets every counter as a custom metric of the given metrics container.
+//
+// Parameters:
+//   - metrics: Pointer to core.Metrics receiving the counter values.
+func (c *Counters) ApplyTo(metrics *core.Metrics) {
+	for name, value := range c.Snapshot() {
+		metrics.SetCustomMetric(name, value)
+	}
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"sync/atomic"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// Aggregator merges metrics from several sources into a single result.
+// Unlike core.Metrics.Merge, which averages resource metrics pairwise and recalculates throughput on every merge,
+// it accumulates the sum and count of every resource metric for a true mean and computes throughput once in Finish.
+//
+// Fields:
+//   - result: Pointer to core.Metrics accumulating the merged metrics.
+//   - cpu:    Accumulator of the CPU usage percentages.
+//   - memory: Accumulator of the memory usages in megabytes.
+//   - gc:     Accumulator of the garbage collection pauses in milliseconds.
+type Aggregator struct {
+	result *core.Metrics
+	cpu    mean
+	memory mean
+	gc     mean
+}
+
+// mean accumulates the sum and count of non-zero samples.
+//
+// Fields:
+//   - sum:   Sum of the samples.
+//   - count: Number of the samples.
+type mean struct {
+	sum   float64
+	count int
+}
+
+// add accumulates the sample, ignoring zero values which denote an unreported metric.
+//
+// Parameters:
+//   - value: The sample to accumulate.
+func (m *mean) add(value float64) {
+	if value == 0 {
+		return
+	}
+	m.sum += value
+	m.count++
+}
+
+// value returns the arithmetic mean of the accumulated samples.
+//
+// Returns:
+//   - float64: The mean, zero if no samples were accumulated.
+func (m *mean) value() float64 {
+	if m.count == 0 {
+		return 0
+	}
+	return m.sum / float64(m.count)
+}
+
+// NewAggregator creates an aggregator merging into the given base metrics.
+//
+// Parameters:
+//   - base: Pointer to core.Metrics receiving the merged metrics; its own resource metrics are part of the mean.
+//
+// Returns:
+//   - *Aggregator: A pointer to a newly created Aggregator.
+func NewAggregator(base *core.Metrics) *Aggregator {
+	a := &Aggregator{result: base}
+	a.addResources(&base.ResourceMetrics)
+	return a
+}
+
+// Add merges another metrics container into the result.
+//
+// Parameters:
+//   - other: A pointer to the core.Metrics instance to merge; nil is ignored.
+func (a *Aggregator) Add(other *core.Metrics) {
+	if other == nil {
+		return
+	}
+
+	// Copy the other metrics through core.Metrics.Merge, which holds the lock of the other container,
+	// so they can be read safely even while their owner is still writing to them.
+	copied := core.NewMetrics()
+	copied.Merge(other)
+
+	if !copied.StartTime.IsZero() {
+		if a.result.StartTime.IsZero() || copied.StartTime.Before(a.result.StartTime) {
+			a.result.StartTime = copied.StartTime
+		}
+	}
+	if !copied.EndTime.IsZero() && copied.EndTime.After(a.result.EndTime) {
+		a.result.EndTime = copied.EndTime
+	}
+
+	atomic.AddInt64(&a.result.TotalOperations, copied.TotalOperations)
+	atomic.AddInt64(&a.result.ErrorCount, copied.ErrorCount)
+	for _, latency := range copied.Latencies {
+		a.result.AddLatency(latency)
+	}
+	for name, value := range copied.Custom {
+		a.result.SetCustomMetric(name, value)
+	}
+
+	a.addResources(&copied.ResourceMetrics)
+}
+
+// addResources accumulates the resource metrics, keeping the maximum goroutine count.
+//
+// Parameters:
+//   - resources: Pointer to the core.ResourceMetrics to accumulate.
+func (a *Aggregator) addResources(resources *core.ResourceMetrics) {
+	a.cpu.add(resources.CPUUsagePercent)
+	a.memory.add(resources.MemoryUsageMB)
+	a.gc.add(resources.GCPauseMs)
+	if resources.ActiveGoroutines > a.result.ResourceMetrics.ActiveGoroutines {
+		a.result.ResourceMetrics.ActiveGoroutines = resources.ActiveGoroutines
+	}
+}
+
+// Finish sets the mean resource metrics and calculates the throughput of the merged result.
+//
+// Returns:
+//   - *core.Metrics: The merged metrics, the same container passed to NewAggregator.
+func (a *Aggregator) Finish() *core.Metrics {
+	a.result.ResourceMetrics.CPUUsagePercent = a.cpu.value()
+	a.result.ResourceMetrics.MemoryUsageMB = a.memory.value()
+	a.result.ResourceMetrics.GCPauseMs = a.gc.value()
+
+	if duration := a.result.EndTime.Sub(a.result.StartTime).Seconds(); duration > 0 {
+		a.result.Throughput = float64(atomic.LoadInt64(&a.result.TotalOperations)) / duration
+	}
+	return a.result
+}
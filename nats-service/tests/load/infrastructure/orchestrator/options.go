@@ -0,0 +1,129 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// Option defines a functional option for configuring the Orchestrator.
+type Option func(*Orchestrator)
+
+// WarmupReporter is implemented by reporters that can report the warmup metrics separately.
+//
+// Methods:
+//   - ReportWarmup: Receives the metrics collected during the warmup period.
+type WarmupReporter interface {
+	// ReportWarmup receives the metrics collected during the warmup period.
+	// It is called before ReportResults.
+	// Parameters:
+	//   - metrics: A pointer to a core.Metrics instance containing the warmup results.
+	// Returns:
+	//   - error: An error if reporting fails, otherwise nil.
+	ReportWarmup(metrics *core.Metrics) error
+}
+
+// ConcurrentRunner is implemented by runners that need their own number of workers.
+//
+// Methods:
+//   - Concurrency: Reports the number of workers of the runner.
+type ConcurrentRunner interface {
+	// Concurrency reports the number of workers running the operations of the runner concurrently.
+	// Non-positive values fall back to the concurrency of the test configuration.
+	// Returns:
+	//   - int: The number of workers of the runner.
+	Concurrency() int
+}
+
+// PacedRunner is implemented by runners throttling their operations, e.g. to a target rate.
+//
+// Methods:
+//   - Pace: Waits until the next operation of the runner is due.
+type PacedRunner interface {
+	// Pace blocks until the next operation is due, it is called by the worker before the operation is started
+	// so that the wait is not measured as part of the operation latency.
+	// Parameters:
+	//   - ctx: The context bounding the wait.
+	// Returns:
+	//   - error: The context error if the context is done before the operation is due, otherwise nil.
+	Pace(ctx context.Context) error
+}
+
+// WithRunnerConcurrency sets the number of workers of the runner with the given name, overriding both the
+// concurrency of the test configuration and the one reported by a ConcurrentRunner.
+// Non-positive values are ignored.
+//
+// Parameters:
+//   - name:        The name of the runner.
+//   - concurrency: The number of workers of the runner.
+//
+// Returns:
+//   - Option: The functional option setting the runner concurrency.
+func WithRunnerConcurrency(name string, concurrency int) Option {
+	return func(o *Orchestrator) {
+		if concurrency > 0 {
+			o.runnerConcurrency[name] = concurrency
+		}
+	}
+}
+
+// WithWarmupMetrics collects warmup metrics into a separate container instead of discarding them.
+// The warmup metrics are passed to every reporter implementing WarmupReporter.
+//
+// Returns:
+//   - Option: The functional option enabling warmup metrics collection.
+func WithWarmupMetrics() Option {
+	return func(o *Orchestrator) {
+		o.collectWarmup = true
+	}
+}
+
+// WithSignals sets the signals interrupting Run, replacing SIGINT and SIGTERM.
+// Without signals Run is not signal-aware, e.g. when the caller interrupts the test through RunContext.
+//
+// Parameters:
+//   - signals: The signals interrupting the load test.
+//
+// Returns:
+//   - Option: The functional option setting the interrupting signals.
+func WithSignals(signals ...os.Signal) Option {
+	return func(o *Orchestrator) {
+		o.signals = signals
+	}
+}
+
+// WithOperationTimeout bounds every single Run invocation by the given timeout.
+// Timed out operations are counted as errors and in the TimeoutsMetric custom metric.
+// Non-positive values disable the timeout.
+//
+// Parameters:
+//   - timeout: The maximum duration of a single operation.
+//
+// Returns:
+//   - Option: The functional option setting the operation timeout.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(o *Orchestrator) {
+		if timeout > 0 {
+			o.operationTimeout = timeout
+		}
+	}
+}
+
+// WithMaxOperations stops the load test once the given number of operations succeeded, or when the test duration
+// elapses, whichever comes first. The warmup operations are not counted.
+// Non-positive values disable the limit.
+//
+// Parameters:
+//   - limit: The number of successful operations ending the load test.
+//
+// Returns:
+//   - Option: The functional option setting the operation limit.
+func WithMaxOperations(limit int64) Option {
+	return func(o *Orchestrator) {
+		if limit > 0 {
+			o.maxOperations = limit
+		}
+	}
+}
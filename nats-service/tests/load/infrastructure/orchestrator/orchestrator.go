@@ -0,0 +1,589 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	loadMetrics "nats-service/tests/load/infrastructure/metrics"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// TimeoutsMetric is the custom metric counting operations that exceeded the operation timeout.
+const TimeoutsMetric = "operation_timeouts"
+
+// InterruptedMetric is the custom metric set to 1 when the load test was interrupted before its end.
+const InterruptedMetric = "interrupted"
+
+// ErrInterrupted is returned by Run once an interrupted load test has reported its partial results.
+var ErrInterrupted = errors.New("load test interrupted")
+
+// ErrNilComponent is returned when a nil runner, collector or reporter is added to the Orchestrator.
+var ErrNilComponent = errors.New("nil load test component")
+
+// Orchestrator coordinates the execution of load tests.
+// It sets up the runners, collectors, and reporters, and manages the test lifecycle.
+// It is based on the go-loadtest orchestrator and extends it with features the NATS service load tests need,
+// starting with warmup metrics the go-loadtest orchestrator discards.
+//
+// Fields:
+//   - config:            Pointer to core.TestConfig containing test configuration parameters.
+//   - runners:           Slice of core.Runner used to execute test operations.
+//   - collectors:        Slice of core.MetricsCollector used to gather metrics during the test.
+//   - reporters:         Slice of core.Reporter used for progress and final result reporting.
+//   - collectWarmup:     Flag indicating whether warmup metrics are collected instead of discarded.
+//   - warmupMetrics:     Pointer to core.Metrics holding the warmup metrics, if collected.
+//   - operationTimeout:  Maximum duration of a single Run invocation, zero if unbounded.
+//   - runnerConcurrency: Number of workers by runner name, overriding the configured concurrency.
+//   - maxOperations:     Number of successful operations ending the load test, zero if unlimited.
+//   - counters:          Pointer to metrics.Counters holding increment-style custom metrics of the runners.
+//   - signals:           Signals interrupting Run, none if Run is not signal-aware.
+//   - logger:            Pointer to slog.Logger used for logging events.
+type Orchestrator struct {
+	config            *core.TestConfig
+	runners           []core.Runner
+	collectors        []core.MetricsCollector
+	reporters         []core.Reporter
+	collectWarmup     bool
+	warmupMetrics     *core.Metrics
+	operationTimeout  time.Duration
+	runnerConcurrency map[string]int
+	maxOperations     int64
+	counters          *loadMetrics.Counters
+	signals           []os.Signal
+	logger            *slog.Logger
+}
+
+// NewOrchestrator creates a new test orchestrator with the provided configuration and logger.
+//
+// Parameters:
+//   - config: Pointer to core.TestConfig containing load test settings.
+//   - logger: Pointer to slog.Logger for logging events.
+//   - opts:   Optional functional options.
+//
+// Returns:
+//   - *Orchestrator: A pointer to a newly created Orchestrator instance.
+func NewOrchestrator(config *core.TestConfig, logger *slog.Logger, opts ...Option) *Orchestrator {
+	o := &Orchestrator{
+		config:            config,
+		runners:           make([]core.Runner, 0),
+		collectors:        make([]core.MetricsCollector, 0),
+		reporters:         make([]core.Reporter, 0),
+		runnerConcurrency: make(map[string]int),
+		counters:          loadMetrics.NewCounters(),
+		signals:           []os.Signal{os.Interrupt, syscall.SIGTERM},
+		logger:            logger,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// AddRunner adds a test runner to the orchestrator.
+//
+// Parameters:
+//   - runner: A core.Runner instance to be added.
+//
+// Returns:
+//   - error: ErrNilComponent if the runner is nil, otherwise nil.
+func (o *Orchestrator) AddRunner(runner core.Runner) error {
+	if runner == nil {
+		return fmt.Errorf("add runner: %w", ErrNilComponent)
+	}
+	o.runners = append(o.runners, runner)
+	return nil
+}
+
+// AddCollector adds a metrics collector to the orchestrator.
+//
+// Parameters:
+//   - collector: A core.MetricsCollector instance to be added.
+//
+// Returns:
+//   - error: ErrNilComponent if the collector is nil, otherwise nil.
+func (o *Orchestrator) AddCollector(collector core.MetricsCollector) error {
+	if collector == nil {
+		return fmt.Errorf("add collector: %w", ErrNilComponent)
+	}
+	o.collectors = append(o.collectors, collector)
+	return nil
+}
+
+// AddReporter adds a reporter to the orchestrator.
+//
+// Parameters:
+//   - reporter: A core.Reporter instance to be added.
+//
+// Returns:
+//   - error: ErrNilComponent if the reporter is nil, otherwise nil.
+func (o *Orchestrator) AddReporter(reporter core.Reporter) error {
+	if reporter == nil {
+		return fmt.Errorf("add reporter: %w", ErrNilComponent)
+	}
+	o.reporters = append(o.reporters, reporter)
+	return nil
+}
+
+// WarmupMetrics returns the metrics collected during the warmup period.
+//
+// Returns:
+//   - *core.Metrics: The warmup metrics, or nil if they were not collected.
+func (o *Orchestrator) WarmupMetrics() *core.Metrics {
+	return o.warmupMetrics
+}
+
+// Counters returns the increment-style custom metrics shared with the runners.
+// Runners increment them concurrently, and they are reported as custom metrics at the end of each phase.
+//
+// Returns:
+//   - *loadMetrics.Counters: The counters of the orchestrator.
+func (o *Orchestrator) Counters() *loadMetrics.Counters {
+	return o.counters
+}
+
+// Run executes the load test managed by the Orchestrator, see RunContext.
+// An interrupt (SIGINT or SIGTERM unless set WithSignals) stops the test early, the partial results are still
+// reported. A second interrupt is no longer caught and ends the process abruptly.
+//
+// Returns:
+//   - error: ErrInterrupted once an interrupted test reported its results, an error if any stage of test
+//     execution fails; otherwise nil.
+func (o *Orchestrator) Run() error {
+	if len(o.signals) == 0 {
+		return o.RunContext(context.Background())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), o.signals...)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	return o.RunContext(ctx)
+}
+
+// RunContext executes the load test managed by the Orchestrator.
+// It sets up the collectors, runners, and progress reporting, runs the test operations,
+// then cleans up and collects the final results.
+// Once parent is done the warmup and the test operations stop early, and the collectors are stopped, the runners
+// torn down and the partial results reported as at the end of a complete test, with the InterruptedMetric set.
+//
+// Parameters:
+//   - parent: The context interrupting the load test when done.
+//
+// Returns:
+//   - error: ErrInterrupted once an interrupted test reported its results, an error if any stage of test
+//     execution fails; otherwise nil.
+func (o *Orchestrator) RunContext(parent context.Context) error {
+	if len(o.runners) == 0 {
+		return fmt.Errorf("no test runners configured")
+	}
+
+	// Create a context that automatically cancels when the test duration elapses
+	// The operations stop earlier once the operation limit is reached, if set, or the test is interrupted
+	ctx, cancel := context.WithTimeout(parent, o.config.TestDuration)
+	defer cancel()
+
+	o.logger.Info("Starting load test",
+		"duration", o.config.TestDuration.String(),
+		"max_operations", o.maxOperations,
+		"concurrency", o.config.Concurrency,
+		"runners", len(o.runners),
+		"collectors", len(o.collectors),
+		"reporters", len(o.reporters))
+
+	if err := o.startCollectors(); err != nil {
+		return err
+	}
+	if err := o.setupRunners(ctx); err != nil {
+		return err
+	}
+	if err := o.warmup(parent); err != nil {
+		return err
+	}
+
+	// Create the base metrics container and record the start time.
+	metrics := core.NewMetrics()
+	metrics.StartTime = time.Now()
+
+	// Start background progress reporting.
+	progressCancel, progressWg := o.startProgressReporting(o.config.ReportInterval, metrics)
+
+	// Run the main test operations.
+	o.runOperations(ctx, metrics, o.maxOperations)
+
+	metrics.EndTime = time.Now()
+	progressCancel()
+	progressWg.Wait()
+
+	interrupted := parent.Err() != nil
+	if interrupted {
+		o.logger.Warn("Load test interrupted, reporting partial results",
+			"elapsed", metrics.EndTime.Sub(metrics.StartTime).String(),
+			"operations", metrics.TotalOperations)
+		metrics.SetCustomMetric(InterruptedMetric, 1)
+	}
+
+	// Clean up runners and collectors.
+	o.cleanup(ctx)
+	// Merge any additional metrics from collectors and calculate final throughput.
+	o.collectData(metrics)
+
+	if interrupted {
+		return ErrInterrupted
+	}
+	return nil
+}
+
+// startCollectors starts all registered metrics collectors.
+//
+// Returns:
+//   - error: An error if any collector fails to start; otherwise nil.
+func (o *Orchestrator) startCollectors() error {
+	for _, item := range o.collectors {
+		o.logger.Info("Starting metrics collector", "collector", item.Name())
+		if err := item.Start(); err != nil {
+			return fmt.Errorf("failed to start collector %s: %w", item.Name(), err)
+		}
+	}
+	return nil
+}
+
+// setupRunners prepares each test runner for execution.
+//
+// Parameters:
+//   - ctx: The context used for managing runner setup.
+//
+// Returns:
+//   - error: An error if any runner fails to set up; otherwise nil.
+func (o *Orchestrator) setupRunners(ctx context.Context) error {
+	for _, runner := range o.runners {
+		o.logger.Info("Setting up runner", "runner", runner.Name(), "concurrency", o.concurrency(runner))
+		if err := runner.Setup(ctx); err != nil {
+			return fmt.Errorf("failed to setup runner %s: %w", runner.Name(), err)
+		}
+	}
+	return nil
+}
+
+// warmup executes a warmup period if WarmupDuration is set.
+// The warmup metrics are discarded unless the orchestrator was created WithWarmupMetrics.
+//
+// Parameters:
+//   - ctx: The context interrupting the warmup when done.
+//
+// Returns:
+//   - error: Nil unless the warmup is canceled by the context.
+func (o *Orchestrator) warmup(ctx context.Context) error {
+	if o.config.WarmupDuration <= 0 {
+		return nil
+	}
+
+	o.logger.Info("Starting warmup period", "duration", o.config.WarmupDuration.String())
+	warmupCtx, warmupCancel := context.WithTimeout(ctx, o.config.WarmupDuration)
+	defer warmupCancel()
+
+	// Run warmup operations, collecting metrics only if requested.
+	if o.collectWarmup {
+		o.warmupMetrics = core.NewMetrics()
+		o.warmupMetrics.StartTime = time.Now()
+	}
+	o.runOperations(warmupCtx, o.warmupMetrics, 0)
+	if o.warmupMetrics != nil {
+		o.counters.ApplyTo(o.warmupMetrics)
+		o.warmupMetrics.EndTime = time.Now()
+		if duration := o.warmupMetrics.EndTime.Sub(o.warmupMetrics.StartTime).Seconds(); duration > 0 {
+			o.warmupMetrics.Throughput = float64(o.warmupMetrics.TotalOperations) / duration
+		}
+	}
+	o.counters.Reset()
+	o.logger.Info("Warmup period completed")
+
+	return nil
+}
+
+// startProgressReporting spawns a goroutine that periodically collects and reports progress.
+//
+// Parameters:
+//   - interval: Duration between progress reports.
+//   - metrics:  Base metrics container to merge live metrics into.
+//
+// Returns:
+//   - context.CancelFunc: Function to cancel the progress reporting.
+//   - *sync.WaitGroup:    WaitGroup that signals when progress reporting has ended.
+func (o *Orchestrator) startProgressReporting(
+	interval time.Duration,
+	metrics *core.Metrics,
+) (context.CancelFunc, *sync.WaitGroup) {
+	progressCtx, progressCancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		o.reportProgress(progressCtx, interval, metrics)
+	}()
+
+	return progressCancel, &wg
+}
+
+// cleanup stops all collectors and tears down all runners.
+//
+// Parameters:
+//   - ctx: The context used to manage cleanup operations.
+func (o *Orchestrator) cleanup(ctx context.Context) {
+	// Stop all metrics collectors.
+	for _, item := range o.collectors {
+		o.logger.Info("Stopping metrics collector", "collector", item.Name())
+		if err := item.Stop(); err != nil {
+			o.logger.Error("Failed to stop collector", "collector", item.Name(), "error", err.Error())
+		}
+	}
+
+	// Teardown all runners.
+	for _, runner := range o.runners {
+		o.logger.Info("Tearing down runner", "runner", runner.Name())
+		if err := runner.Teardown(ctx); err != nil {
+			o.logger.Error("Failed to teardown runner", "runner", runner.Name(), "error", err.Error())
+		}
+	}
+}
+
+// collectData merges metrics from all collectors, calculates throughput once all of them are merged,
+// and reports the final results using all configured reporters.
+//
+// Parameters:
+//   - metrics: Pointer to core.Metrics containing test results.
+func (o *Orchestrator) collectData(metrics *core.Metrics) {
+	// Merge metrics from each collector.
+	aggregator := loadMetrics.NewAggregator(metrics)
+	for _, item := range o.collectors {
+		aggregator.Add(item.GetMetrics())
+	}
+
+	// Report the counters incremented by the runners.
+	o.counters.ApplyTo(metrics)
+
+	// Calculate the mean resource metrics and the throughput.
+	aggregator.Finish()
+
+	// Report warmup metrics to the reporters supporting them.
+	if o.warmupMetrics != nil {
+		for _, reporter := range o.reporters {
+			if warmupReporter, ok := reporter.(WarmupReporter); ok {
+				if err := warmupReporter.ReportWarmup(o.warmupMetrics); err != nil {
+					o.logger.Error("Failed to report warmup", "reporter", reporter.Name(), "error", err.Error())
+				}
+			}
+		}
+	}
+
+	// Generate final reports using all registered reporters.
+	for _, reporter := range o.reporters {
+		o.logger.Info("Generating final report", "reporter", reporter.Name())
+		if err := reporter.ReportResults(metrics); err != nil {
+			o.logger.Error("Failed to report results", "reporter", reporter.Name(), "error", err.Error())
+		}
+	}
+
+	// Format the test duration for final logging.
+	d := metrics.EndTime.Sub(metrics.StartTime)
+	formatted := fmt.Sprintf("%d minutes %d seconds", int(d.Minutes()), int(d.Seconds())%60)
+	o.logger.Info("Load test completed successfully",
+		"duration", formatted,
+		"operations", metrics.TotalOperations,
+		"errors", metrics.ErrorCount,
+		"throughput", fmt.Sprintf("%.0f ops/s", metrics.Throughput))
+}
+
+// runOperations executes the test operations using the configured runners.
+// It spawns worker goroutines per runner based on the concurrency level of the runner.
+// The workers stop when the context is done or, if maxOperations is positive and metrics are recorded,
+// once the number of successful operations reaches maxOperations.
+//
+// Parameters:
+//   - ctx:           Context governing test operation execution.
+//   - metrics:       Pointer to core.Metrics for recording test results; if nil, metrics recording is skipped.
+//   - maxOperations: Number of successful operations stopping the workers, non-positive if unlimited.
+func (o *Orchestrator) runOperations(ctx context.Context, metrics *core.Metrics, maxOperations int64) {
+	var (
+		wg       sync.WaitGroup
+		timeouts atomic.Int64
+		limited  = maxOperations > 0 && metrics != nil
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start worker goroutines for each runner.
+	for _, runner := range o.runners {
+		for i := 0; i < o.concurrency(runner); i++ {
+			wg.Add(1)
+			go func(runner core.Runner, workerId int) {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						o.logger.Warn("Recovered in runner runOperations", "runner", runner.Name())
+					}
+				}()
+
+				o.logger.Debug("Starting worker", "runner", runner.Name(), "worker_id", workerId)
+				for {
+					select {
+					case <-ctx.Done():
+						o.logger.Debug("Worker stopping due to context done",
+							"runner", runner.Name(),
+							"worker_id", workerId)
+						return
+					default:
+						// Wait for a paced operation to be due, outside of the measured latency.
+						if paced, ok := runner.(PacedRunner); ok {
+							if err := paced.Pace(ctx); err != nil {
+								continue
+							}
+						}
+
+						// Execute the test operation and record its latency.
+						start := time.Now()
+						timedOut, err := o.runOperation(ctx, runner)
+						latency := time.Since(start).Seconds() * 1_000 // milliseconds
+
+						// Update metrics if provided.
+						if metrics != nil {
+							switch {
+							case timedOut:
+								timeouts.Add(1)
+								metrics.IncrementErrors()
+							case err != nil:
+								metrics.IncrementErrors()
+							default:
+								// The count returned by the add is seen by a single worker, unlike a later load,
+								// so exactly one worker reaches the limit and cancels the run.
+								operations := atomic.AddInt64(&metrics.TotalOperations, 1)
+								metrics.AddLatency(latency)
+								if limited && operations == maxOperations {
+									o.logger.Info("Operation limit reached", "operations", maxOperations)
+									cancel()
+								}
+							}
+						}
+					}
+				}
+			}(runner, i)
+		}
+	}
+
+	// Wait for the context to be canceled (i.e. test duration elapsed or operation limit reached)
+	// then wait for all workers to finish.
+	<-ctx.Done()
+	wg.Wait()
+
+	if metrics != nil && o.operationTimeout > 0 {
+		metrics.SetCustomMetric(TimeoutsMetric, float64(timeouts.Load()))
+	}
+}
+
+// concurrency returns the number of workers of the runner.
+// A concurrency set WithRunnerConcurrency takes precedence over the one reported by a ConcurrentRunner,
+// and the concurrency of the test configuration is the default.
+//
+// Parameters:
+//   - runner: The runner to get the number of workers of.
+//
+// Returns:
+//   - int: The number of workers of the runner.
+func (o *Orchestrator) concurrency(runner core.Runner) int {
+	if concurrency, ok := o.runnerConcurrency[runner.Name()]; ok {
+		return concurrency
+	}
+	if concurrent, ok := runner.(ConcurrentRunner); ok && concurrent.Concurrency() > 0 {
+		return concurrent.Concurrency()
+	}
+	return o.config.Concurrency
+}
+
+// runOperation executes a single test operation, bounded by the operation timeout if set.
+//
+// Parameters:
+//   - ctx:    Context governing test operation execution.
+//   - runner: The runner executing the operation.
+//
+// Returns:
+//   - timedOut: True if the operation exceeded the operation timeout.
+//   - err:      An error if the operation fails, otherwise nil.
+func (o *Orchestrator) runOperation(ctx context.Context, runner core.Runner) (timedOut bool, err error) {
+	if o.operationTimeout <= 0 {
+		return false, runner.Run(ctx)
+	}
+
+	operationCtx, cancel := context.WithTimeout(ctx, o.operationTimeout)
+	defer cancel()
+
+	err = runner.Run(operationCtx)
+	timedOut = errors.Is(operationCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	return timedOut, err
+}
+
+// reportProgress periodically collects and reports metrics during the test.
+// It uses the provided base metrics container to merge live metrics.
+//
+// Parameters:
+//   - ctx:      Context for canceling progress reporting.
+//   - interval: Duration between progress reports.
+//   - metrics:  Base metrics container to merge live metrics.
+func (o *Orchestrator) reportProgress(ctx context.Context, interval time.Duration, metrics *core.Metrics) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			snapshot := o.collectMetricsSnapshot(metrics)
+			for name, value := range o.counters.Snapshot() {
+				snapshot.Custom[name] = value
+			}
+			for _, reporter := range o.reporters {
+				if err := reporter.ReportProgress(snapshot); err != nil {
+					o.logger.Error("Failed to report progress", "reporter", reporter.Name(), "error", err.Error())
+				}
+			}
+		}
+	}
+}
+
+// collectMetricsSnapshot gathers current metrics from all collectors and adds them to a snapshot of baseMetrics.
+// The base metrics are not modified, so collector metrics are not counted again on every progress report.
+//
+// Parameters:
+//   - baseMetrics: Pointer to core.Metrics containing the live test metrics.
+//
+// Returns:
+//   - *core.MetricsSnapshot: A snapshot of the current aggregated metrics.
+func (o *Orchestrator) collectMetricsSnapshot(baseMetrics *core.Metrics) *core.MetricsSnapshot {
+	snapshot := baseMetrics.GetSnapshot()
+
+	for _, item := range o.collectors {
+		if metrics := item.GetMetrics(); metrics != nil {
+			collectorSnapshot := metrics.GetSnapshot()
+			snapshot.Operations += collectorSnapshot.Operations
+			snapshot.Errors += collectorSnapshot.Errors
+			for name, value := range collectorSnapshot.Custom {
+				snapshot.Custom[name] = value
+			}
+		}
+	}
+
+	// Recalculate the rate from the aggregated operations and the elapsed time of the test.
+	if elapsed := snapshot.Timestamp.Sub(baseMetrics.StartTime).Seconds(); !baseMetrics.StartTime.IsZero() && elapsed > 0 {
+		snapshot.RatePerSecond = float64(snapshot.Operations) / elapsed
+	}
+	return snapshot
+}
@@ -0,0 +1,215 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"nats-service/tests/load/infrastructure/slo"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/mguley/go-loadtest/pkg/reporter"
+)
+
+// SummaryMode defines how the ConsoleReporter writes the final test results.
+//
+// Values:
+//   - SummaryText: Writes a human-readable summary line.
+//   - SummaryJSON: Writes a single-line JSON summary.
+//   - SummaryBoth: Writes the human-readable summary followed by the JSON summary.
+type SummaryMode string
+
+const (
+	// SummaryText writes a human-readable summary line.
+	SummaryText SummaryMode = "text"
+	// SummaryJSON writes a single-line JSON summary.
+	SummaryJSON SummaryMode = "json"
+	// SummaryBoth writes the human-readable summary followed by the JSON summary.
+	SummaryBoth SummaryMode = "both"
+)
+
+// ConsoleReporter extends the go-loadtest console reporter with a final results summary.
+// Progress output is delegated to the embedded reporter and stays human-friendly.
+//
+// Fields:
+//   - ConsoleReporter: The embedded go-loadtest console reporter used for progress output.
+//   - writer:          The io.Writer destination for the summary (typically stdout).
+//   - mode:            The summary mode (text, json or both).
+//   - warmup:          The warmup results, if reported.
+//   - objectives:      The service level objectives evaluated against the final results.
+//   - sloReport:       The verdicts of the objectives, nil until the final results are reported.
+type ConsoleReporter struct {
+	*reporter.ConsoleReporter
+	writer     io.Writer
+	mode       SummaryMode
+	warmup     *reporter.ResultOutput
+	objectives []slo.Objective
+	sloReport  *slo.Report
+}
+
+// ConsoleOption defines a functional option for configuring ConsoleReporter.
+type ConsoleOption func(*ConsoleReporter)
+
+// WithObjectives evaluates the service level objectives against the final results, and annotates the summary
+// with the verdict of each objective.
+//
+// Parameters:
+//   - objectives: The objectives to evaluate, none disables the evaluation.
+//
+// Returns:
+//   - ConsoleOption: A functional option that sets the objectives.
+func WithObjectives(objectives []slo.Objective) ConsoleOption {
+	return func(r *ConsoleReporter) {
+		r.objectives = objectives
+	}
+}
+
+// Summary defines the JSON structure of the final console summary.
+//
+// Fields:
+//   - ResultOutput: The final test results, inlined.
+//   - Warmup:       The optional warmup results.
+//   - SLO:          The optional verdicts of the service level objectives.
+type Summary struct {
+	reporter.ResultOutput
+	Warmup *reporter.ResultOutput `json:"warmup,omitempty"`
+	SLO    *slo.Report            `json:"slo,omitempty"`
+}
+
+// NewConsoleReporter creates a new ConsoleReporter.
+//
+// Parameters:
+//   - writer:   The io.Writer to which output is written.
+//   - interval: The duration that defines how frequently the rate is calculated.
+//   - mode:     The summary mode; unknown values fall back to SummaryText.
+//   - opts:     Optional functional options for configuring the reporter.
+//
+// Returns:
+//   - *ConsoleReporter: A pointer to the newly created ConsoleReporter instance.
+func NewConsoleReporter(
+	writer io.Writer,
+	interval time.Duration,
+	mode SummaryMode,
+	opts ...ConsoleOption,
+) *ConsoleReporter {
+	switch mode {
+	case SummaryText, SummaryJSON, SummaryBoth:
+	default:
+		mode = SummaryText
+	}
+
+	r := &ConsoleReporter{
+		ConsoleReporter: reporter.NewConsoleReporter(writer, interval),
+		writer:          writer,
+		mode:            mode,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ReportWarmup stores the warmup results to be included in the final summary.
+//
+// Parameters:
+//   - metrics: A pointer to a core.Metrics instance containing the warmup results.
+//
+// Returns:
+//   - error: Always returns nil.
+func (r *ConsoleReporter) ReportWarmup(metrics *core.Metrics) error {
+	r.warmup = NewResultOutput(metrics)
+	return nil
+}
+
+// ReportResults writes the final test results according to the summary mode.
+//
+// Parameters:
+//   - metrics: A pointer to a core.Metrics instance containing the test results.
+//
+// Returns:
+//   - error: An error if marshaling or writing fails, otherwise nil.
+func (r *ConsoleReporter) ReportResults(metrics *core.Metrics) (err error) {
+	result := NewResultOutput(metrics)
+	if len(r.objectives) > 0 {
+		r.sloReport = slo.Evaluate(result, r.objectives)
+	}
+
+	if r.mode == SummaryText || r.mode == SummaryBoth {
+		if r.warmup != nil {
+			if _, err = fmt.Fprintf(r.writer,
+				"Warmup in %.1fs | Total: %d ops | Errors: %d (%.2f%%) | Throughput: %.2f ops/s | p99: %.2f ms\n",
+				r.warmup.TestDuration,
+				r.warmup.TotalOperations,
+				r.warmup.ErrorCount,
+				r.warmup.ErrorRate,
+				r.warmup.Throughput,
+				r.warmup.LatencyP99,
+			); err != nil {
+				return fmt.Errorf("write text warmup summary: %w", err)
+			}
+		}
+		if _, err = fmt.Fprintf(r.writer,
+			"Completed in %.1fs | Total: %d ops | Errors: %d (%.2f%%) | Throughput: %.2f ops/s | p99: %.2f ms\n",
+			result.TestDuration,
+			result.TotalOperations,
+			result.ErrorCount,
+			result.ErrorRate,
+			result.Throughput,
+			result.LatencyP99,
+		); err != nil {
+			return fmt.Errorf("write text summary: %w", err)
+		}
+		if err = r.writeVerdicts(); err != nil {
+			return err
+		}
+	}
+
+	if r.mode == SummaryJSON || r.mode == SummaryBoth {
+		var data []byte
+		if data, err = json.Marshal(&Summary{ResultOutput: *result, Warmup: r.warmup, SLO: r.sloReport}); err != nil {
+			return fmt.Errorf("marshal summary: %w", err)
+		}
+		if _, err = fmt.Fprintf(r.writer, "%s\n", data); err != nil {
+			return fmt.Errorf("write json summary: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SLOReport returns the verdicts of the service level objectives.
+//
+// Returns:
+//   - *slo.Report: A pointer to the verdicts, nil if no objective is set or the results are not reported yet.
+func (r *ConsoleReporter) SLOReport() *slo.Report {
+	return r.sloReport
+}
+
+// writeVerdicts writes a human-readable line per service level objective, if any.
+//
+// Returns:
+//   - error: An error if writing fails, otherwise nil.
+func (r *ConsoleReporter) writeVerdicts() (err error) {
+	if r.sloReport == nil {
+		return nil
+	}
+	for _, verdict := range r.sloReport.Verdicts {
+		outcome := "PASS"
+		if !verdict.Passed {
+			outcome = "FAIL"
+		}
+		if _, err = fmt.Fprintf(r.writer, "SLO %s: %s | Actual: %.2f | Headroom: %.2f\n",
+			verdict.Objective, outcome, verdict.Actual, verdict.Headroom); err != nil {
+			return fmt.Errorf("write text SLO verdict: %w", err)
+		}
+	}
+	return nil
+}
+
+// Name returns the name of this reporter.
+//
+// Returns:
+//   - string: The name "Console Reporter".
+func (r *ConsoleReporter) Name() string {
+	return "Console Reporter"
+}
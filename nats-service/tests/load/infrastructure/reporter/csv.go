@@ -0,0 +1,115 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+)
+
+// csvHeader is the header row of the CSV output.
+var csvHeader = []string{"type", "timestamp", "operations", "errors", "rate_per_second"}
+
+// CSVReporter writes the test progress as CSV, one row per progress report followed by a summary row,
+// e.g. to plot the throughput over time with gnuplot.
+//
+// Rows are typed like the NDJSON records (RecordProgress or RecordSummary), the summary row carries the end time,
+// the totals and the overall throughput.
+//
+// Fields:
+//   - writer:        The CSV writer of the rows.
+//   - headerWritten: Flag indicating whether the header row was written.
+//   - mu:            Mutex serializing the writes of the rows.
+type CSVReporter struct {
+	writer        *csv.Writer
+	headerWritten bool
+	mu            sync.Mutex
+}
+
+// NewCSVReporter creates a new CSVReporter.
+//
+// Parameters:
+//   - writer: The io.Writer to which the rows are written.
+//
+// Returns:
+//   - *CSVReporter: A pointer to the newly created CSVReporter instance.
+func NewCSVReporter(writer io.Writer) *CSVReporter {
+	return &CSVReporter{writer: csv.NewWriter(writer)}
+}
+
+// ReportProgress writes a progress row.
+//
+// Parameters:
+//   - snapshot: A pointer to a core.MetricsSnapshot representing the current test metrics.
+//
+// Returns:
+//   - error: An error if writing fails, otherwise nil.
+func (r *CSVReporter) ReportProgress(snapshot *core.MetricsSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.write(RecordProgress, snapshot.Timestamp, snapshot.Operations, snapshot.Errors, snapshot.RatePerSecond)
+}
+
+// ReportResults writes the summary row.
+//
+// Parameters:
+//   - metrics: A pointer to a core.Metrics instance containing the test results.
+//
+// Returns:
+//   - error: An error if writing fails, otherwise nil.
+func (r *CSVReporter) ReportResults(metrics *core.Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.write(RecordSummary, metrics.EndTime, metrics.TotalOperations, metrics.ErrorCount, metrics.Throughput)
+}
+
+// Name returns the name of this reporter.
+//
+// Returns:
+//   - string: The name "CSV Reporter".
+func (r *CSVReporter) Name() string {
+	return "CSV Reporter"
+}
+
+// write writes a row, preceded by the header row on the first call, and flushes it.
+//
+// Parameters:
+//   - recordType: The row type (RecordProgress or RecordSummary).
+//   - timestamp:  The time of the row.
+//   - operations: The number of operations completed.
+//   - errors:     The number of errors encountered.
+//   - rate:       The throughput in operations per second.
+//
+// Returns:
+//   - error: An error if writing fails, otherwise nil.
+func (r *CSVReporter) write(recordType RecordType, timestamp time.Time, operations, errors int64, rate float64) error {
+	if !r.headerWritten {
+		if err := r.writer.Write(csvHeader); err != nil {
+			return fmt.Errorf("write header row: %w", err)
+		}
+		r.headerWritten = true
+	}
+
+	row := []string{
+		string(recordType),
+		timestamp.Format(time.RFC3339),
+		strconv.FormatInt(operations, 10),
+		strconv.FormatInt(errors, 10),
+		strconv.FormatFloat(rate, 'f', 2, 64),
+	}
+	if err := r.writer.Write(row); err != nil {
+		return fmt.Errorf("write %s row: %w", recordType, err)
+	}
+
+	r.writer.Flush()
+	if err := r.writer.Error(); err != nil {
+		return fmt.Errorf("flush rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package reporter
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/mguley/go-loadtest/pkg/reporter"
+)
+
+// RecordType identifies the kind of NDJSON record.
+//
+// Values:
+//   - RecordProgress: A progress snapshot taken during the test.
+//   - RecordWarmup:   The results of the warmup period.
+//   - RecordLatency:  A single raw latency sample.
+//   - RecordSummary:  The final test results, always the last record.
+type RecordType string
+
+const (
+	// RecordProgress is a progress snapshot taken during the test.
+	RecordProgress RecordType = "progress"
+	// RecordWarmup is the results of the warmup period.
+	RecordWarmup RecordType = "warmup"
+	// RecordLatency is a single raw latency sample.
+	RecordLatency RecordType = "latency"
+	// RecordSummary is the final test results, always the last record.
+	RecordSummary RecordType = "summary"
+)
+
+// NDJSONReporter streams the test results as newline-delimited JSON records.
+// Every record is encoded and written on its own, so raw latencies of very large runs are never
+// marshaled into a single in-memory document.
+//
+// Fields:
+//   - writer:           The buffered destination of the records.
+//   - encoder:          The JSON encoder writing one record per line.
+//   - includeLatencies: Flag indicating whether raw latency samples are written.
+//   - mu:               Mutex serializing the writes of the records.
+type NDJSONReporter struct {
+	writer           *bufio.Writer
+	encoder          *json.Encoder
+	includeLatencies bool
+	mu               sync.Mutex
+}
+
+// progressRecord defines the JSON structure of a progress record.
+//
+// Fields:
+//   - Type:          The record type (RecordProgress).
+//   - Timestamp:     The snapshot time in RFC3339 format.
+//   - Operations:    The number of operations completed so far.
+//   - Errors:        The number of errors encountered so far.
+//   - RatePerSecond: The throughput so far in operations per second.
+//   - Custom:        Optional custom metrics.
+type progressRecord struct {
+	Type          RecordType         `json:"type"`
+	Timestamp     string             `json:"timestamp"`
+	Operations    int64              `json:"operations"`
+	Errors        int64              `json:"errors"`
+	RatePerSecond float64            `json:"rate_per_second"`
+	Custom        map[string]float64 `json:"custom,omitempty"`
+}
+
+// latencyRecord defines the JSON structure of a latency record.
+//
+// Fields:
+//   - Type:      The record type (RecordLatency).
+//   - LatencyMs: The latency of a single operation in milliseconds.
+type latencyRecord struct {
+	Type      RecordType `json:"type"`
+	LatencyMs float64    `json:"latency_ms"`
+}
+
+// resultRecord defines the JSON structure of a warmup or summary record.
+//
+// Fields:
+//   - Type:         The record type (RecordWarmup or RecordSummary).
+//   - ResultOutput: The results, inlined.
+type resultRecord struct {
+	Type RecordType `json:"type"`
+	reporter.ResultOutput
+}
+
+// NewNDJSONReporter creates a new NDJSONReporter.
+//
+// Parameters:
+//   - writer:           The io.Writer to which the records are written.
+//   - includeLatencies: Whether a latency record is written for every raw latency sample.
+//
+// Returns:
+//   - *NDJSONReporter: A pointer to the newly created NDJSONReporter instance.
+func NewNDJSONReporter(writer io.Writer, includeLatencies bool) *NDJSONReporter {
+	buffered := bufio.NewWriter(writer)
+	return &NDJSONReporter{
+		writer:           buffered,
+		encoder:          json.NewEncoder(buffered),
+		includeLatencies: includeLatencies,
+	}
+}
+
+// ReportProgress writes a progress record.
+//
+// Parameters:
+//   - snapshot: A pointer to a core.MetricsSnapshot representing the current test metrics.
+//
+// Returns:
+//   - error: An error if encoding or writing fails, otherwise nil.
+func (r *NDJSONReporter) ReportProgress(snapshot *core.MetricsSnapshot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record := &progressRecord{
+		Type:          RecordProgress,
+		Timestamp:     snapshot.Timestamp.Format(time.RFC3339),
+		Operations:    snapshot.Operations,
+		Errors:        snapshot.Errors,
+		RatePerSecond: snapshot.RatePerSecond,
+		Custom:        snapshot.Custom,
+	}
+	if err := r.encoder.Encode(record); err != nil {
+		return fmt.Errorf("write progress record: %w", err)
+	}
+	return r.flush()
+}
+
+// ReportWarmup writes a warmup record.
+//
+// Parameters:
+//   - metrics: A pointer to a core.Metrics instance containing the warmup results.
+//
+// Returns:
+//   - error: An error if encoding or writing fails, otherwise nil.
+func (r *NDJSONReporter) ReportWarmup(metrics *core.Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.encoder.Encode(&resultRecord{Type: RecordWarmup, ResultOutput: *NewResultOutput(metrics)}); err != nil {
+		return fmt.Errorf("write warmup record: %w", err)
+	}
+	return r.flush()
+}
+
+// ReportResults writes a latency record per raw latency sample, if enabled, followed by the summary record.
+//
+// Parameters:
+//   - metrics: A pointer to a core.Metrics instance containing the test results.
+//
+// Returns:
+//   - error: An error if encoding or writing fails, otherwise nil.
+func (r *NDJSONReporter) ReportResults(metrics *core.Metrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.includeLatencies {
+		record := &latencyRecord{Type: RecordLatency}
+		for _, latency := range metrics.Latencies {
+			record.LatencyMs = latency
+			if err := r.encoder.Encode(record); err != nil {
+				return fmt.Errorf("write latency record: %w", err)
+			}
+		}
+	}
+
+	if err := r.encoder.Encode(&resultRecord{Type: RecordSummary, ResultOutput: *NewResultOutput(metrics)}); err != nil {
+		return fmt.Errorf("write summary record: %w", err)
+	}
+	return r.flush()
+}
+
+// Name returns the name of this reporter.
+//
+// Returns:
+//   - string: The name "NDJSON Reporter".
+func (r *NDJSONReporter) Name() string {
+	return "NDJSON Reporter"
+}
+
+// flush writes the buffered records to the underlying writer.
+//
+// Returns:
+//   - error: An error if writing fails, otherwise nil.
+func (r *NDJSONReporter) flush() error {
+	if err := r.writer.Flush(); err != nil {
+		return fmt.Errorf("flush records: %w", err)
+	}
+	return nil
+}
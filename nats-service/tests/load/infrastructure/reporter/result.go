@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"time"
+
+	"github.com/mguley/go-loadtest/pkg/core"
+	"github.com/mguley/go-loadtest/pkg/reporter"
+	"github.com/mguley/go-loadtest/pkg/util"
+)
+
+// NewResultOutput builds the JSON result structure used by the JSON reporter from the final test metrics.
+// Raw latencies are never included.
+//
+// Parameters:
+//   - metrics: A pointer to a core.Metrics instance containing the test results.
+//
+// Returns:
+//   - *reporter.ResultOutput: A pointer to the populated result structure.
+func NewResultOutput(metrics *core.Metrics) *reporter.ResultOutput {
+	var (
+		latencies = util.Float64Data(metrics.Latencies)
+		result    = &reporter.ResultOutput{
+			StartTime:        metrics.StartTime.Format(time.RFC3339),
+			EndTime:          metrics.EndTime.Format(time.RFC3339),
+			TestDuration:     metrics.EndTime.Sub(metrics.StartTime).Seconds(),
+			TotalOperations:  metrics.TotalOperations,
+			ErrorCount:       metrics.ErrorCount,
+			Throughput:       metrics.Throughput,
+			CPUUsagePercent:  metrics.ResourceMetrics.CPUUsagePercent,
+			MemoryUsageMB:    metrics.ResourceMetrics.MemoryUsageMB,
+			ActiveGoroutines: metrics.ResourceMetrics.ActiveGoroutines,
+			GCPauseMs:        metrics.ResourceMetrics.GCPauseMs,
+			Custom:           metrics.Custom,
+		}
+	)
+
+	if len(latencies) > 0 {
+		result.LatencyP50, _ = latencies.Percentile(50)
+		result.LatencyP90, _ = latencies.Percentile(90)
+		result.LatencyP95, _ = latencies.Percentile(95)
+		result.LatencyP99, _ = latencies.Percentile(99)
+		result.LatencyMin = latencies.Min()
+		result.LatencyMax = latencies.Max()
+		result.LatencyMean = latencies.Mean()
+	}
+	if metrics.TotalOperations > 0 {
+		result.ErrorRate = float64(metrics.ErrorCount) / float64(metrics.TotalOperations) * 100
+	}
+
+	return result
+}
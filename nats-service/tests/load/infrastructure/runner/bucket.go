@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket throttles concurrent callers to a fixed rate of operations per second.
+// Tokens are refilled continuously up to the bucket capacity; a caller finding the bucket empty reserves the next
+// token and sleeps until it is due, so concurrent callers are spread evenly over time instead of bursting.
+//
+// Fields:
+//   - rate:     Number of tokens refilled per second.
+//   - capacity: Maximum number of tokens held by the bucket, i.e. the largest burst.
+//   - tokens:   Number of tokens available, negative while tokens are reserved by waiting callers.
+//   - last:     Time of the last refill.
+//   - mu:       Mutex guarding tokens and last.
+type tokenBucket struct {
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+	mu       sync.Mutex
+}
+
+// newTokenBucket creates a new full token bucket.
+//
+// Parameters:
+//   - rate:     Number of tokens refilled per second, must be positive.
+//   - capacity: Maximum number of tokens held by the bucket, at least 1.
+//
+// Returns:
+//   - *tokenBucket: A pointer to the newly created tokenBucket.
+func newTokenBucket(rate float64, capacity float64) *tokenBucket {
+	capacity = max(capacity, 1)
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		last:     time.Now(),
+	}
+}
+
+// Wait takes a token, blocking until one is available or the context is done.
+// A token reserved by a caller whose context is done is given back to the bucket.
+//
+// Parameters:
+//   - ctx: The context bounding the wait.
+//
+// Returns:
+//   - error: The context error if the context is done before a token is available, otherwise nil.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	tokens := b.tokens
+	b.mu.Unlock()
+
+	if tokens >= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(-tokens / b.rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
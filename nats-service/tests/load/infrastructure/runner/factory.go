@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"nats-service/tests/load/config"
+	"nats-service/tests/load/infrastructure/metrics"
 	"shared/grpc/clients/nats_service"
 
 	"github.com/mguley/go-loadtest/pkg/core"
@@ -12,33 +13,38 @@ import (
 // NatsServiceRunnerFactory creates NATS service runners based on the load test configuration.
 //
 // Fields:
-//   - client: Pointer to the NatsRpcClient for communicating with the NATS service.
-//   - config: Pointer to the load test configuration.
-//   - logger: Logger instance for event logging.
+//   - client:   NATS client for communicating with the NATS service.
+//   - config:   Pointer to the load test configuration.
+//   - counters: Increment-style custom metrics of the runners, shared with the orchestrator.
+//   - logger:   Logger instance for event logging.
 type NatsServiceRunnerFactory struct {
-	client *nats_service.NatsClient
-	config *config.LoadTestConfig
-	logger *slog.Logger
+	client   nats_service.Client
+	config   *config.LoadTestConfig
+	counters *metrics.Counters
+	logger   *slog.Logger
 }
 
 // NewNatsServiceRunnerFactory creates a new instance of NatsServiceRunnerFactory.
 //
 // Parameters:
-//   - client: Pointer to the NatsRpcClient for gRPC communication.
-//   - config: Pointer to the load test configuration.
-//   - logger: Logger instance for logging events.
+//   - client:   NATS client used for gRPC communication.
+//   - config:   Pointer to the load test configuration.
+//   - counters: Increment-style custom metrics of the runners, see orchestrator.Orchestrator.Counters.
+//   - logger:   Logger instance for logging events.
 //
 // Returns:
 //   - *NatsServiceRunnerFactory: A pointer to the newly created NatsServiceRunnerFactory.
 func NewNatsServiceRunnerFactory(
-	client *nats_service.NatsClient,
+	client nats_service.Client,
 	config *config.LoadTestConfig,
+	counters *metrics.Counters,
 	logger *slog.Logger,
 ) *NatsServiceRunnerFactory {
 	return &NatsServiceRunnerFactory{
-		client: client,
-		config: config,
-		logger: logger,
+		client:   client,
+		config:   config,
+		counters: counters,
+		logger:   logger,
 	}
 }
 
@@ -56,18 +62,22 @@ func (f *NatsServiceRunnerFactory) CreateRunner(testType config.LoadTestType) (r
 		return NewNatsServicePublishRunner(
 			f.client,
 			f.config.MessageSize,
-			f.config.Subject,
-			f.logger), nil
+			f.config.EffectiveSubject(),
+			f.logger,
+			WithTargetRate(f.config.TargetRate),
+			WithRetries(f.config.PublishRetries, f.config.RetryBackoff, f.counters),
+			WithJetStream(f.config.JetStream)), nil
 	case config.SubscribeTest:
 		return NewNatsServiceSubscribeRunner(
 			f.client,
-			f.config.Subject,
+			f.config.EffectiveSubject(),
 			f.config.QueueGroup,
 			f.config.MessageSize,
 			f.config.MaxSubscribers,
 			f.config.PublishInterval,
 			f.config.SubscribeTimeout,
-			f.logger), nil
+			f.logger,
+			WithMaxPublishFailures(f.config.MaxPublishFailures)), nil
 	default:
 		return nil, fmt.Errorf("unknown load test type: %s", testType)
 	}
@@ -5,7 +5,12 @@ import (
 	"crypto/rand"
 	"fmt"
 	"log/slog"
+	"nats-service/tests/load/infrastructure/metrics"
 	"shared/grpc/clients/nats_service"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // NatsServicePublishRunner implements the core.Runner interface for testing NATS service publish operations.
@@ -15,15 +20,90 @@ import (
 //   - payload:     The generated random payload used in publish operations.
 //   - subject:     The NATS subject to which messages will be published.
 //   - messageSize: The size of the message payload in bytes.
+//   - targetRate:  Target number of messages published per second, 0 publishes unthrottled.
+//   - limiter:     Token bucket pacing the publishes to the target rate, nil when unthrottled.
+//   - retries:     Maximum number of retries of a transiently failed publish, 0 disables the retries.
+//   - backoff:     Wait before each retry of a failed publish.
+//   - counters:    Custom metrics receiving the RetriesMetric, nil if the retries are not recorded.
+//   - jetStream:   Flag indicating whether the messages are published through JetStream, awaiting their PubAck.
+//   - acked:       The client publishing through JetStream, set by Setup in JetStream mode.
 //   - logger:      Logger for structured logging.
 type NatsServicePublishRunner struct {
-	client      *nats_service.NatsClient
+	client      nats_service.Client
 	payload     []byte
 	subject     string
 	messageSize int
+	targetRate  int
+	limiter     *tokenBucket
+	retries     int
+	backoff     time.Duration
+	counters    *metrics.Counters
+	jetStream   bool
+	acked       AckedPublisher
 	logger      *slog.Logger
 }
 
+// RetriesMetric is the custom metric counting the retries of transiently failed publishes.
+const RetriesMetric = "publish_retries"
+
+// AckedPublisher is a client publishing through JetStream, e.g. nats_service.NatsClient.
+type AckedPublisher interface {
+	// PublishAcked sends data to the subject through JetStream and returns once the message is persisted.
+	PublishAcked(ctx context.Context, subject string, data []byte) (err error)
+}
+
+// PublishRunnerOption defines a functional option for configuring NatsServicePublishRunner.
+type PublishRunnerOption func(*NatsServicePublishRunner)
+
+// WithTargetRate paces the publishes to rate messages per second across all workers of the runner, e.g. to
+// measure the latency at a fixed throughput rather than at saturation. A rate of 0 or less publishes unthrottled.
+//
+// Parameters:
+//   - rate: Target number of messages published per second.
+//
+// Returns:
+//   - PublishRunnerOption: A functional option that sets the target rate.
+func WithTargetRate(rate int) PublishRunnerOption {
+	return func(r *NatsServicePublishRunner) {
+		r.targetRate = max(rate, 0)
+	}
+}
+
+// WithRetries retries a publish failing with a transient error (e.g. Unavailable during a broker blip) up to
+// retries times within a single Run, waiting backoff before each retry, so that the blip is not counted as failed
+// operations. The retries are counted in the RetriesMetric of counters, if not nil. Retries are disabled by default,
+// keeping the error rate a pure measure of the failed publishes; non-positive retries keep them disabled.
+//
+// Parameters:
+//   - retries:  Maximum number of retries of a failed publish.
+//   - backoff:  Wait before each retry, non-positive retries at once.
+//   - counters: Custom metrics receiving the number of retries.
+//
+// Returns:
+//   - PublishRunnerOption: A functional option that enables the retries.
+func WithRetries(retries int, backoff time.Duration, counters *metrics.Counters) PublishRunnerOption {
+	return func(r *NatsServicePublishRunner) {
+		r.retries = max(retries, 0)
+		r.backoff = max(backoff, 0)
+		r.counters = counters
+	}
+}
+
+// WithJetStream publishes the messages through JetStream, each Run returning once the PubAck is received, so the
+// measured latency is the publish-to-ack (durable persistence) latency instead of the fire-and-forget one.
+// The subject is captured by an existing stream, or one is created by the NATS service on the first publish.
+//
+// Parameters:
+//   - enabled: Flag indicating whether the messages are published through JetStream.
+//
+// Returns:
+//   - PublishRunnerOption: A functional option that sets the publish mode.
+func WithJetStream(enabled bool) PublishRunnerOption {
+	return func(r *NatsServicePublishRunner) {
+		r.jetStream = enabled
+	}
+}
+
 // NewNatsServicePublishRunner creates a new instance of NatsServicePublishRunner.
 //
 // Parameters:
@@ -31,32 +111,49 @@ type NatsServicePublishRunner struct {
 //   - messageSize: The size (in bytes) of the message payload to be generated.
 //   - subject:     The NATS subject to publish messages to.
 //   - logger:      Logger for structured logging.
+//   - opts:        Optional functional options for configuring the runner.
 //
 // Returns:
 //   - *NatsServicePublishRunner: A pointer to the newly created NatsServicePublishRunner.
 func NewNatsServicePublishRunner(
-	client *nats_service.NatsClient,
+	client nats_service.Client,
 	messageSize int,
 	subject string,
 	logger *slog.Logger,
+	opts ...PublishRunnerOption,
 ) *NatsServicePublishRunner {
-	return &NatsServicePublishRunner{
+	r := &NatsServicePublishRunner{
 		client:      client,
 		messageSize: messageSize,
 		subject:     subject,
 		logger:      logger,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.targetRate > 0 {
+		r.limiter = newTokenBucket(float64(r.targetRate), 1)
+	}
+	return r
 }
 
 // Setup performs necessary initialization for the publish runner.
-// It generates a random payload of the specified message size and logs the setup status.
+// It resolves the publish mode, generates a random payload of the specified message size and logs the setup status.
 //
 // Parameters:
 //   - ctx: The context for the setup process.
 //
 // Returns:
-//   - err: An error if payload generation fails; otherwise, nil.
+//   - err: An error if the client cannot publish through JetStream in JetStream mode or payload generation fails;
+//     otherwise, nil.
 func (r *NatsServicePublishRunner) Setup(ctx context.Context) (err error) {
+	if r.jetStream {
+		var ok bool
+		if r.acked, ok = r.client.(AckedPublisher); !ok {
+			return fmt.Errorf("client %T does not publish through JetStream", r.client)
+		}
+	}
+
 	r.payload = make([]byte, r.messageSize)
 	if _, err = rand.Read(r.payload); err != nil {
 		return fmt.Errorf("failed to generate payload: %w", err)
@@ -64,12 +161,17 @@ func (r *NatsServicePublishRunner) Setup(ctx context.Context) (err error) {
 
 	r.logger.Info("NatsServicePublishRunner setup complete",
 		slog.String("subject", r.subject),
-		slog.Int("messageSize", r.messageSize))
+		slog.Int("messageSize", r.messageSize),
+		slog.Int("targetRate", r.targetRate),
+		slog.Bool("jetStream", r.jetStream))
 
 	return nil
 }
 
 // Run publishes a message to the configured NATS subject using the underlying gRPC client.
+// In JetStream mode it returns once the message is acked by the stream.
+// With retries enabled, a transiently failed publish is retried until it succeeds, the retries are exhausted
+// or the context is done.
 //
 // Parameters:
 //   - ctx: The context for the publishing operation.
@@ -77,7 +179,55 @@ func (r *NatsServicePublishRunner) Setup(ctx context.Context) (err error) {
 // Returns:
 //   - err: An error if the publishing operation fails; otherwise, nil.
 func (r *NatsServicePublishRunner) Run(ctx context.Context) (err error) {
-	return r.client.Publish(ctx, r.subject, r.payload)
+	publish := r.client.Publish
+	if r.acked != nil {
+		publish = r.acked.PublishAcked
+	}
+	for attempt := 0; ; attempt++ {
+		if err = publish(ctx, r.subject, r.payload); err == nil || attempt == r.retries || !transient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(r.backoff):
+		}
+		if r.counters != nil {
+			r.counters.IncrementCustomMetric(RetriesMetric, 1)
+		}
+	}
+}
+
+// transient reports whether a publish failed by err may succeed when retried.
+//
+// Parameters:
+//   - err: The error of the failed publish.
+//
+// Returns:
+//   - bool: True if the error is a transient gRPC error, otherwise false.
+func transient(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Pace blocks until the next publish is due under the target rate, returning at once when unthrottled.
+// The orchestrator calls it before each Run, so the publish latency is measured without the wait.
+//
+// Parameters:
+//   - ctx: The context bounding the wait.
+//
+// Returns:
+//   - err: The context error if the context is done before the publish is due; otherwise, nil.
+func (r *NatsServicePublishRunner) Pace(ctx context.Context) (err error) {
+	if r.limiter == nil {
+		return nil
+	}
+	return r.limiter.Wait(ctx)
 }
 
 // Teardown cleans up resources by closing the underlying gRPC connection.
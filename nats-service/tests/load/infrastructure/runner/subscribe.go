@@ -3,6 +3,7 @@ package runner
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
 	"shared/grpc/clients/nats_service"
@@ -10,6 +11,14 @@ import (
 	"time"
 )
 
+const (
+	// DefaultMaxPublishFailures is the number of consecutive publish failures stopping the publisher by default.
+	DefaultMaxPublishFailures = 10
+
+	// maxPublishBackoff caps the delay between publish attempts after failures.
+	maxPublishBackoff = time.Duration(30) * time.Second
+)
+
 // NatsServiceSubscribeRunner implements the core.Runner interface to test subscribe operations against the NATS service
 //
 // Fields:
@@ -20,19 +29,21 @@ import (
 //   - messageSize:         The size of the payload in bytes.
 //   - subscriberSemaphore: Semaphore to limit concurrent subscriber goroutines.
 //   - publishInterval:     Interval between published messages.
+//   - maxPublishFailures:  Consecutive publish failures stopping the publisher, 0 never stops it.
 //   - subscribeTimeout:    Timeout for subscription operations.
 //   - publisherCtx:        Context controlling the lifecycle of the publisher goroutine.
 //   - publisherCancel:     Function to cancel the publisher goroutine.
 //   - wg:                  WaitGroup for managing goroutines lifecycle.
 //   - logger:              Logger instance for structured logging.
 type NatsServiceSubscribeRunner struct {
-	client              *nats_service.NatsClient
+	client              nats_service.Client
 	subject             string
 	queueGroup          string
 	payload             []byte
 	messageSize         int
 	subscriberSemaphore chan struct{}
 	publishInterval     time.Duration
+	maxPublishFailures  int
 	subscribeTimeout    time.Duration
 	publisherCtx        context.Context
 	publisherCancel     context.CancelFunc
@@ -40,6 +51,26 @@ type NatsServiceSubscribeRunner struct {
 	logger              *slog.Logger
 }
 
+// SubscribeRunnerOption defines a functional option for configuring NatsServiceSubscribeRunner.
+type SubscribeRunnerOption func(*NatsServiceSubscribeRunner)
+
+// WithMaxPublishFailures stops the background publisher after limit consecutive publish failures, e.g. once the
+// server died mid-test, instead of logging an error per interval until the test ends. Negative limits are ignored,
+// 0 never stops the publisher; failed publishes are backed off either way.
+//
+// Parameters:
+//   - limit: Number of consecutive publish failures stopping the publisher.
+//
+// Returns:
+//   - SubscribeRunnerOption: A functional option that sets the publish failure limit.
+func WithMaxPublishFailures(limit int) SubscribeRunnerOption {
+	return func(r *NatsServiceSubscribeRunner) {
+		if limit >= 0 {
+			r.maxPublishFailures = limit
+		}
+	}
+}
+
 // NewNatsServiceSubscribeRunner creates a new instance of NatsServiceSubscribeRunner.
 //
 // Parameters:
@@ -51,11 +82,12 @@ type NatsServiceSubscribeRunner struct {
 //   - publishInterval:  Interval between each published message.
 //   - subscribeTimeout: Maximum duration to wait for subscription messages.
 //   - logger:           Logger instance for structured logging.
+//   - opts:             Optional functional options for configuring the runner.
 //
 // Returns:
 //   - *NatsServiceSubscribeRunner: A pointer to the newly created subscribe runner.
 func NewNatsServiceSubscribeRunner(
-	client *nats_service.NatsClient,
+	client nats_service.Client,
 	subject string,
 	queueGroup string,
 	messageSize int,
@@ -63,20 +95,27 @@ func NewNatsServiceSubscribeRunner(
 	publishInterval time.Duration,
 	subscribeTimeout time.Duration,
 	logger *slog.Logger,
+	opts ...SubscribeRunnerOption,
 ) *NatsServiceSubscribeRunner {
-	return &NatsServiceSubscribeRunner{
+	r := &NatsServiceSubscribeRunner{
 		client:              client,
 		subject:             subject,
 		queueGroup:          queueGroup,
 		messageSize:         messageSize,
 		publishInterval:     publishInterval,
+		maxPublishFailures:  DefaultMaxPublishFailures,
 		subscribeTimeout:    subscribeTimeout,
 		subscriberSemaphore: make(chan struct{}, maxSubscribers),
 		logger:              logger,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Setup initializes the subscribe runner by generating a random payload and starting a background publisher goroutine.
+// The publisher is started last, so a failed Setup never leaves it running; a repeated Setup stops the previous one.
 //
 // Parameters:
 //   - ctx: The context used for controlling the setup lifecycle.
@@ -84,14 +123,17 @@ func NewNatsServiceSubscribeRunner(
 // Returns:
 //   - err: An error if payload generation fails; otherwise, nil.
 func (r *NatsServiceSubscribeRunner) Setup(ctx context.Context) (err error) {
-	r.payload = make([]byte, r.messageSize)
-	if _, err = rand.Read(r.payload); err != nil {
+	r.stopPublisher()
+
+	payload := make([]byte, r.messageSize)
+	if _, err = rand.Read(payload); err != nil {
 		return fmt.Errorf("failed to generate payload: %w", err)
 	}
+	r.payload = payload
 
 	r.publisherCtx, r.publisherCancel = context.WithCancel(ctx)
 	r.wg.Add(1)
-	go r.runPublisher()
+	go r.runPublisher(r.publisherCtx)
 
 	r.logger.Info("NatsServiceSubscribeRunner setup complete",
 		slog.String("subject", r.subject),
@@ -104,35 +146,73 @@ func (r *NatsServiceSubscribeRunner) Setup(ctx context.Context) (err error) {
 	return nil
 }
 
+// stopPublisher cancels the background publisher goroutine, if any, and waits for it to exit.
+func (r *NatsServiceSubscribeRunner) stopPublisher() {
+	if r.publisherCancel == nil {
+		return
+	}
+	r.publisherCancel()
+	r.wg.Wait()
+	r.publisherCtx, r.publisherCancel = nil, nil
+}
+
 // runPublisher is a background goroutine responsible for periodically publishing messages to the configured subject.
 //
-// This method continuously publishes messages at specified intervals until the publisher context is canceled.
-func (r *NatsServiceSubscribeRunner) runPublisher() {
+// This method publishes messages at specified intervals until the publisher context is canceled. After a failed
+// publish the delay doubles, up to maxPublishBackoff, and is reset by the next successful publish; the publisher
+// stops once maxPublishFailures consecutive publishes failed. A publish failing because of the shutdown is not
+// counted as a failure.
+//
+// Parameters:
+//   - ctx: The publisher context, bound at start so a later Setup cannot swap it under the goroutine.
+func (r *NatsServiceSubscribeRunner) runPublisher(ctx context.Context) {
 	defer r.wg.Done()
-	ticker := time.NewTicker(r.publishInterval)
-	defer ticker.Stop()
+	var (
+		failures int
+		delay    = r.publishInterval
+		timer    = time.NewTimer(delay)
+	)
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-r.publisherCtx.Done():
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		err := r.client.Publish(ctx, r.subject, r.payload)
+		switch {
+		case err == nil:
+			failures, delay = 0, r.publishInterval
+		case ctx.Err() != nil:
 			return
-		case <-ticker.C:
-			if err := r.client.Publish(r.publisherCtx, r.subject, r.payload); err != nil {
-				r.logger.Error("Error publishing message",
-					slog.String("subject", r.subject), slog.String("error", err.Error()))
+		default:
+			failures++
+			if r.maxPublishFailures > 0 && failures >= r.maxPublishFailures {
+				r.logger.Error("Stopping publisher after consecutive publish failures",
+					slog.String("subject", r.subject), slog.Int("failures", failures),
+					slog.String("error", err.Error()))
+				return
 			}
+			delay = min(delay*2, maxPublishBackoff)
+			r.logger.Warn("Error publishing message, backing off",
+				slog.String("subject", r.subject), slog.Int("failures", failures),
+				slog.String("backoff", delay.String()), slog.String("error", err.Error()))
 		}
+		timer.Reset(delay)
 	}
 }
 
 // Run executes the subscription operation, listening for messages published on the specified subject.
 // It respects the maximum subscriber limit using a semaphore to control concurrency.
+// The subscription is bound to a context canceled on return, so its goroutine always exits with Run.
 //
 // Parameters:
 //   - ctx: The context controlling the subscription lifecycle.
 //
 // Returns:
-//   - err: An error if the subscription fails or times out; otherwise, nil upon receiving a message.
+//   - err: The context error once it is done, or the error ending the subscription early.
 func (r *NatsServiceSubscribeRunner) Run(ctx context.Context) (err error) {
 	select {
 	case r.subscriberSemaphore <- struct{}{}:
@@ -145,7 +225,7 @@ func (r *NatsServiceSubscribeRunner) Run(ctx context.Context) (err error) {
 	var (
 		wg      sync.WaitGroup
 		msgCh   = make(chan struct{}, 1)
-		errCh   = make(chan error, 1)
+		doneCh  = make(chan error, 1)
 		handler = func(_ []byte, _ string) {
 			select {
 			case msgCh <- struct{}{}:
@@ -154,13 +234,16 @@ func (r *NatsServiceSubscribeRunner) Run(ctx context.Context) (err error) {
 		}
 	)
 
+	subscribeCtx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if subErr := r.client.Subscribe(ctx, r.subject, r.queueGroup, handler); subErr != nil {
-			errCh <- subErr
-			return
-		}
+		doneCh <- r.client.Subscribe(subscribeCtx, r.subject, r.queueGroup, handler)
 	}()
 
 	// Continuously listen for messages
@@ -168,11 +251,16 @@ func (r *NatsServiceSubscribeRunner) Run(ctx context.Context) (err error) {
 		select {
 		case <-ctx.Done():
 			r.logger.Debug("Context canceled, existing receiver loop")
-			wg.Wait()
 			return ctx.Err()
-		case err = <-errCh:
+		case err = <-doneCh:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == nil {
+				// The server ended the stream, report it rather than waiting for messages that never come.
+				err = errors.New("subscription ended by the server")
+			}
 			r.logger.Error("Subscription error received", slog.String("error", err.Error()))
-			wg.Wait()
 			return err
 		case <-msgCh:
 		}
@@ -188,10 +276,7 @@ func (r *NatsServiceSubscribeRunner) Run(ctx context.Context) (err error) {
 // Returns:
 //   - err: An error if closing the NATS client fails; otherwise, nil.
 func (r *NatsServiceSubscribeRunner) Teardown(ctx context.Context) (err error) {
-	if r.publisherCancel != nil {
-		r.publisherCancel()
-		r.wg.Wait()
-	}
+	r.stopPublisher()
 
 	if r.client != nil {
 		if err = r.client.Close(); err != nil {
@@ -0,0 +1,173 @@
+package slo
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mguley/go-loadtest/pkg/reporter"
+)
+
+// Metric names a value of the load test results an objective applies to.
+type Metric string
+
+const (
+	// LatencyP50 is the median latency in milliseconds.
+	LatencyP50 Metric = "latency_p50"
+	// LatencyP90 is the 90th percentile latency in milliseconds.
+	LatencyP90 Metric = "latency_p90"
+	// LatencyP95 is the 95th percentile latency in milliseconds.
+	LatencyP95 Metric = "latency_p95"
+	// LatencyP99 is the 99th percentile latency in milliseconds.
+	LatencyP99 Metric = "latency_p99"
+	// LatencyMax is the maximum latency in milliseconds.
+	LatencyMax Metric = "latency_max"
+	// ErrorRate is the error rate in percent of the operations.
+	ErrorRate Metric = "error_rate"
+	// Throughput is the throughput in operations per second.
+	Throughput Metric = "throughput"
+)
+
+// metrics lists the supported metrics.
+var metrics = []Metric{LatencyP50, LatencyP90, LatencyP95, LatencyP99, LatencyMax, ErrorRate, Throughput}
+
+// Objective defines a service level objective, a bound a metric of the results must stay within.
+//
+// Fields:
+//   - Metric:  The metric the objective applies to.
+//   - Target:  The bound of the metric.
+//   - Minimum: True if the metric must stay above the target (e.g. throughput), false if it must stay below it.
+type Objective struct {
+	Metric  Metric
+	Target  float64
+	Minimum bool
+}
+
+// String returns the objective as it is defined, e.g. "latency_p99<50".
+//
+// Returns:
+//   - string: The objective definition.
+func (o Objective) String() string {
+	operator := "<"
+	if o.Minimum {
+		operator = ">"
+	}
+	return fmt.Sprintf("%s%s%s", o.Metric, operator, strconv.FormatFloat(o.Target, 'f', -1, 64))
+}
+
+// Verdict holds the outcome of evaluating an objective against the results.
+//
+// Fields:
+//   - Objective: The objective definition, e.g. "latency_p99<50".
+//   - Actual:    The value of the metric in the results.
+//   - Headroom:  The margin left before the target, negative if the objective is breached.
+//   - Passed:    True if the metric is within the target.
+type Verdict struct {
+	Objective string  `json:"objective"`
+	Actual    float64 `json:"actual"`
+	Headroom  float64 `json:"headroom"`
+	Passed    bool    `json:"passed"`
+}
+
+// Report holds the verdicts of all the objectives.
+//
+// Fields:
+//   - Passed:   True if every objective is met.
+//   - Verdicts: The verdict of each objective, in the order of the objectives.
+type Report struct {
+	Passed   bool      `json:"passed"`
+	Verdicts []Verdict `json:"verdicts"`
+}
+
+// ParseObjectives parses a comma-separated list of objectives, e.g. "latency_p99<50,error_rate<1,throughput>100".
+// Latencies are in milliseconds, the error rate in percent and the throughput in operations per second.
+//
+// Parameters:
+//   - definitions: The comma-separated objectives, an empty string defines none.
+//
+// Returns:
+//   - []Objective: The parsed objectives.
+//   - error:       An error if a definition is malformed or names an unknown metric, otherwise nil.
+func ParseObjectives(definitions string) (objectives []Objective, err error) {
+	for _, definition := range strings.Split(definitions, ",") {
+		if definition = strings.TrimSpace(definition); definition == "" {
+			continue
+		}
+
+		index := strings.IndexAny(definition, "<>")
+		if index <= 0 {
+			return nil, fmt.Errorf("parse objective %q: expected <metric><|><target>", definition)
+		}
+		objective := Objective{
+			Metric:  Metric(strings.TrimSpace(definition[:index])),
+			Minimum: definition[index] == '>',
+		}
+		if !slices.Contains(metrics, objective.Metric) {
+			return nil, fmt.Errorf("parse objective %q: unknown metric %s", definition, objective.Metric)
+		}
+		if objective.Target, err = strconv.ParseFloat(strings.TrimSpace(definition[index+1:]), 64); err != nil {
+			return nil, fmt.Errorf("parse objective %q: invalid target: %w", definition, err)
+		}
+		objectives = append(objectives, objective)
+	}
+	return objectives, nil
+}
+
+// Evaluate checks the results against the objectives.
+//
+// Parameters:
+//   - result:     A pointer to the load test results.
+//   - objectives: The objectives to evaluate.
+//
+// Returns:
+//   - *Report: A pointer to the verdicts, passed if every objective is met.
+func Evaluate(result *reporter.ResultOutput, objectives []Objective) *Report {
+	report := &Report{Passed: true, Verdicts: make([]Verdict, 0, len(objectives))}
+	for _, objective := range objectives {
+		var (
+			actual   = value(result, objective.Metric)
+			headroom = objective.Target - actual
+		)
+		if objective.Minimum {
+			headroom = -headroom
+		}
+
+		verdict := Verdict{
+			Objective: objective.String(),
+			Actual:    actual,
+			Headroom:  headroom,
+			Passed:    headroom > 0,
+		}
+		report.Passed = report.Passed && verdict.Passed
+		report.Verdicts = append(report.Verdicts, verdict)
+	}
+	return report
+}
+
+// value returns the value of a metric in the results.
+//
+// Parameters:
+//   - result: A pointer to the load test results.
+//   - metric: The metric to read.
+//
+// Returns:
+//   - float64: The value of the metric.
+func value(result *reporter.ResultOutput, metric Metric) float64 {
+	switch metric {
+	case LatencyP50:
+		return result.LatencyP50
+	case LatencyP90:
+		return result.LatencyP90
+	case LatencyP95:
+		return result.LatencyP95
+	case LatencyP99:
+		return result.LatencyP99
+	case LatencyMax:
+		return result.LatencyMax
+	case ErrorRate:
+		return result.ErrorRate
+	default:
+		return result.Throughput
+	}
+}
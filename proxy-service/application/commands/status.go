@@ -11,11 +11,37 @@ import (
 )
 
 // StatusCommand checks service status by pinging the URL.
+// It shares the connection pool with the workload, the concurrency and borrow timeout options keep
+// concurrent status checks from starving it.
 type StatusCommand struct {
-	timeout    time.Duration          // timeout specifies the timeout duration for the HTTP request.
-	pingUrl    string                 // pingUrl is the URL to be pinged to check the service status.
-	socks5Pool *socks5.ConnectionPool // socks5Pool is the pool to obtain HTTP clients configured for SOCKS5.
-	logger     *slog.Logger           // logger for structured logging.
+	timeout       time.Duration          // timeout specifies the timeout duration for the HTTP request.
+	pingUrl       string                 // pingUrl is the URL to be pinged to check the service status.
+	socks5Pool    *socks5.ConnectionPool // socks5Pool is the pool to obtain HTTP clients configured for SOCKS5.
+	slots         chan struct{}          // slots limits the concurrent status checks, nil is unlimited.
+	borrowTimeout time.Duration          // borrowTimeout bounds the wait for a pooled client, 0 waits until the deadline.
+	logger        *slog.Logger           // logger for structured logging.
+}
+
+// StatusOption defines a functional option for configuring StatusCommand.
+type StatusOption func(*StatusCommand)
+
+// WithStatusConcurrency limits the number of concurrent status checks, and so the pooled clients they hold.
+// Checks beyond the limit wait for a running one until their deadline, a non-positive limit disables it.
+func WithStatusConcurrency(limit int) StatusOption {
+	return func(c *StatusCommand) {
+		c.slots = nil
+		if limit > 0 {
+			c.slots = make(chan struct{}, limit)
+		}
+	}
+}
+
+// WithBorrowTimeout bounds the wait of a status check for a pooled client while the workload holds all of them,
+// the check then fails instead of queueing behind the workload. A non-positive timeout waits until the deadline.
+func WithBorrowTimeout(timeout time.Duration) StatusOption {
+	return func(c *StatusCommand) {
+		c.borrowTimeout = max(timeout, 0)
+	}
 }
 
 // NewStatusCommand creates a new instance of StatusCommand.
@@ -24,31 +50,52 @@ func NewStatusCommand(
 	url string,
 	pool *socks5.ConnectionPool,
 	logger *slog.Logger,
+	opts ...StatusOption,
 ) *StatusCommand {
-	return &StatusCommand{
+	c := &StatusCommand{
 		timeout:    timeout,
 		pingUrl:    url,
 		socks5Pool: pool,
 		logger:     logger,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Execute performs the status check by sending the HTTP request.
-func (c *StatusCommand) Execute() (status string, err error) {
+// The configured timeout is applied only when ctx has no deadline of its own.
+func (c *StatusCommand) Execute(ctx context.Context) (status string, err error) {
 	var (
 		httpClient *http.Client
-		ctx        context.Context
-		cancel     context.CancelFunc
 		request    *http.Request
 		response   *http.Response
 		body       []byte
 	)
 
-	c.logger.Info("Initiating status check", "url", c.pingUrl, "timeout", c.timeout)
-	ctx, cancel = context.WithTimeout(context.Background(), c.timeout)
-	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+	c.logger.Info("Initiating status check", "url", c.pingUrl, "timeout", time.Until(deadline))
+
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+			defer func() { <-c.slots }()
+		case <-ctx.Done():
+			c.logger.Error("Too many concurrent status checks", "limit", cap(c.slots), "error", ctx.Err())
+			return "", fmt.Errorf("wait for status check slot: %w", ctx.Err())
+		}
+	}
 
-	httpClient = c.socks5Pool.Borrow()
+	if httpClient, err = c.borrow(ctx); err != nil {
+		c.logger.Error("Error borrowing HTTP client", "error", err)
+		return "", fmt.Errorf("borrow client: %w", err)
+	}
 	defer c.socks5Pool.Return(httpClient)
 
 	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, c.pingUrl, http.NoBody); err != nil {
@@ -77,3 +124,13 @@ func (c *StatusCommand) Execute() (status string, err error) {
 	c.logger.Info("Status check completed", "result", string(body))
 	return string(body), nil
 }
+
+// borrow borrows a pooled client, waiting at most the borrow timeout and never past the deadline of ctx.
+func (c *StatusCommand) borrow(ctx context.Context) (client *http.Client, err error) {
+	if c.borrowTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.borrowTimeout)
+		defer cancel()
+	}
+	return c.socks5Pool.BorrowContext(ctx)
+}
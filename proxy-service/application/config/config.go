@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -20,6 +21,12 @@ func GetConfig() *Config {
 	return config
 }
 
+// Load reads a fresh configuration from the environment, bypassing the cached one.
+// It is used on reload, only the hot-reloadable parameters of the result are applied.
+func Load() *Config {
+	return loadConfig()
+}
+
 // Config holds configuration settings.
 type Config struct {
 	Nats         NatsConfig         // NATS configuration.
@@ -28,28 +35,82 @@ type Config struct {
 	Proxy        ProxyConfig        // Proxy configuration.
 	Pool         PoolConfig         // Pool configuration.
 	UrlProcessor UrlProcessorConfig // UrlProcessor configuration.
+	Metrics      MetricsConfig      // Metrics configuration.
+	Health       HealthConfig       // Health endpoint configuration.
+	Run          RunConfig          // Job-style run limits.
+	LogLevel     string             // LogLevel is the minimum log level (debug, info, warn, error), hot-reloadable.
+	LogFormat    string             // LogFormat is the log handler, json or console.
+	LogAddSource bool               // LogAddSource adds the source file:line of the logging call to every record.
+	ReloadFile   string             // ReloadFile is the env file re-read on SIGHUP, empty re-reads the process environment.
 	Env          string             // Environment type (e.g., dev, prod).
 }
 
+// MetricsConfig holds configuration settings for the metrics server.
+type MetricsConfig struct {
+	ServerPort string // ServerPort is the address of the metrics HTTP server (e.g., ":50556").
+}
+
+// HealthConfig holds configuration settings for the health server.
+type HealthConfig struct {
+	ServerPort string // ServerPort is the address of the health HTTP server (e.g., ":50557"), empty disables it.
+	StatusTTL  int    // StatusTTL is the seconds a status check result is reused by the health endpoint.
+
+	FailureThreshold  int // FailureThreshold is the number of consecutive failed status checks turning unhealthy.
+	FailureWindow     int // FailureWindow is the seconds a failed status check counts, 0 counts them all.
+	RecoveryThreshold int // RecoveryThreshold is the number of consecutive passed status checks turning healthy.
+}
+
+// RunConfig holds the limits of job-style runs, reaching one shuts the service down gracefully, 0 disables a limit.
+type RunConfig struct {
+	MaxRuntime  int // MaxRuntime is the max. seconds the service runs.
+	MaxMessages int // MaxMessages is the max. number of URLs the service processes.
+}
+
 // UrlProcessorConfig holds configuration settings for UrlProcessorService.
 type UrlProcessorConfig struct {
-	BatchSize  int    // BatchSize is the max. number of concurrent URL processing goroutines.
-	QueueGroup string // QueueGroup is the NATS queue group for load balancing.
+	BatchSize     int    // BatchSize is the max. number of concurrent URL processing goroutines.
+	QueueGroup    string // QueueGroup is the NATS queue group for load balancing.
+	RetryAttempts int    // RetryAttempts is the retry budget shared by the stages of a message arriving without one.
+	RetryTimeout  int    // RetryTimeout is the seconds within which a message arriving without a budget is retried.
+
+	// SubscribeMaxAttempts caps the consecutive resubscribes after transient subscription failures, 0 is unlimited.
+	SubscribeMaxAttempts int
+	// HostRulesFile is the JSON file of per-host max. concurrent requests, empty applies the batch size only.
+	HostRulesFile string
+	// MaxMessageAge is the max. seconds since the publish of a request for it to be fetched, 0 disables it.
+	MaxMessageAge int
+	// MaxRetryAfter is the max. seconds honored from the Retry-After header of rate limited fetches.
+	MaxRetryAfter int
+	// AllowedContentTypes are the response media types published, e.g. "text/*", empty allows all.
+	AllowedContentTypes []string
+	// BorrowTimeout is the max. seconds a fetch waits for a pooled HTTP client, 0 waits indefinitely.
+	BorrowTimeout int
 }
 
 // ProxyConfig holds configuration settings for Proxy.
 type ProxyConfig struct {
-	Host            string // Host is the hostname of the proxy server.
-	Port            string // Port is the port number of the proxy server.
-	ControlPassword string // ControlPassword is the auth password used for the proxy's control port.
-	ControlPort     string // ControlPort is the port number of the proxy's control port.
-	Url             string // Url is the URL used to check the proxy's status or connectivity.
+	Host             string // Host is the hostname of the proxy server.
+	Port             string // Port is the port number of the proxy server.
+	ControlPassword  string // ControlPassword is the auth password used for the proxy's control port.
+	ControlPort      string // ControlPort is the port number of the proxy's control port.
+	Url              string // Url is the URL used to check the proxy's status or connectivity.
+	RotationCooldown int    // RotationCooldown is the minimum number of seconds between circuit rotations.
+	SelfTest         bool   // SelfTest enables the startup check that the exit IP differs from the local IP.
+	SelfTestTimeout  int    // SelfTestTimeout is the maximum number of seconds the startup self-test may take.
+
+	StatusConcurrency   int // StatusConcurrency is the max. number of concurrent status checks, 0 is unlimited.
+	StatusBorrowTimeout int // StatusBorrowTimeout is the max. seconds a status check waits for a pooled client.
 }
 
 // PoolConfig holds configuration options for the connection pool.
 type PoolConfig struct {
-	MaxSize         int // MaxSize is the maximum number of connections in the pool.
-	RefreshInterval int // RefreshInterval is the interval at which connections are refreshed.
+	MaxSize         int    // MaxSize is the maximum number of connections in the pool.
+	RefreshInterval int    // RefreshInterval is the interval at which connections are refreshed, hot-reloadable.
+	OverflowPolicy  string // OverflowPolicy is the behavior on an exhausted pool ("block", "overflow" or "fail").
+	OverflowCap     int    // OverflowCap is the maximum number of transient clients beyond MaxSize.
+	ClientTimeout   int    // ClientTimeout is the number of seconds a single request through a pooled client may take.
+	Parallelism     int    // Parallelism is the max. number of clients created at once on startup, 1 is sequential.
+	CreateAttempts  int    // CreateAttempts is the number of attempts to create each client on startup.
 }
 
 // RPCConfig holds configuration settings for RPC.
@@ -65,10 +126,14 @@ type TLSConfig struct {
 
 // NatsConfig holds configuration settings for NATS.
 type NatsConfig struct {
-	Host    string // Host is the hostname of the NATS server.
-	Port    string // Port is the port number of the NATS server.
-	RpcHost string // RpcHost is the address of the NATS gRPC server.
-	RpcPort string // RpcPort is the port number of the NATS gRPC server.
+	Host         string // Host is the hostname of the NATS server.
+	Port         string // Port is the port number of the NATS server.
+	RpcHost      string // RpcHost is the address of the NATS gRPC server.
+	RpcPort      string // RpcPort is the port number of the NATS gRPC server.
+	RpcTransport string // RpcTransport is "tcp", or "inprocess" to reach a BusService co-located in the process.
+	RpcTLSName   string // RpcTLSName overrides the name the NATS gRPC server certificate is verified against.
+	MaxStreams   int    // MaxStreams caps the concurrent NATS subscribe streams of the client, 0 is unlimited.
+	QueueStreams bool   // QueueStreams waits for a free subscribe stream beyond MaxStreams instead of failing.
 }
 
 // loadConfig loads configuration falling back to default values.
@@ -80,15 +145,63 @@ func loadConfig() *Config {
 		Proxy:        loadProxyConfig(),
 		Pool:         loadPoolConfig(),
 		UrlProcessor: loadUrlProcessorConfig(),
+		Metrics:      loadMetricsConfig(),
+		Health:       loadHealthConfig(),
+		Run:          loadRunConfig(),
+		LogLevel:     getEnv("LOG_LEVEL", "info"),
+		LogFormat:    getEnv("LOG_FORMAT", "json"),
+		LogAddSource: getEnvAsBool("LOG_ADD_SOURCE", false),
+		ReloadFile:   getEnv("RELOAD_ENV_FILE", ""),
 		Env:          getEnv("ENV", "dev"),
 	}
 }
 
+// loadHealthConfig loads health server configuration.
+func loadHealthConfig() HealthConfig {
+	return HealthConfig{
+		ServerPort: getEnv("HEALTH_SERVER_PORT", ""),
+		StatusTTL:  getEnvAsInt("HEALTH_STATUS_TTL", 30),
+
+		FailureThreshold:  getEnvAsInt("HEALTH_FAILURE_THRESHOLD", 1),
+		FailureWindow:     getEnvAsInt("HEALTH_FAILURE_WINDOW", 0),
+		RecoveryThreshold: getEnvAsInt("HEALTH_RECOVERY_THRESHOLD", 1),
+	}
+}
+
+// loadMetricsConfig loads metrics server configuration.
+func loadMetricsConfig() MetricsConfig {
+	metrics := MetricsConfig{
+		ServerPort: getEnv("METRICS_SERVER_PORT", ""),
+	}
+
+	checkRequiredVars("METRICS", map[string]string{
+		"METRICS_SERVER_PORT": metrics.ServerPort,
+	})
+	return metrics
+}
+
+// loadRunConfig loads job-style run limits, by default the service runs until a signal.
+func loadRunConfig() RunConfig {
+	return RunConfig{
+		MaxRuntime:  getEnvAsInt("RUN_MAX_RUNTIME", 0),
+		MaxMessages: getEnvAsInt("RUN_MAX_MESSAGES", 0),
+	}
+}
+
 // loadUrlProcessorConfig loads url processor service configuration.
 func loadUrlProcessorConfig() UrlProcessorConfig {
 	processor := UrlProcessorConfig{
-		BatchSize:  getEnvAsInt("URL_PROCESSOR_BATCH_SIZE", 0),
-		QueueGroup: getEnv("URL_PROCESSOR_QUEUE_GROUP", ""),
+		BatchSize:     getEnvAsInt("URL_PROCESSOR_BATCH_SIZE", 0),
+		QueueGroup:    getEnv("URL_PROCESSOR_QUEUE_GROUP", ""),
+		RetryAttempts: getEnvAsInt("URL_PROCESSOR_RETRY_ATTEMPTS", 5),
+		RetryTimeout:  getEnvAsInt("URL_PROCESSOR_RETRY_TIMEOUT", 120),
+
+		SubscribeMaxAttempts: getEnvAsInt("URL_PROCESSOR_SUBSCRIBE_MAX_ATTEMPTS", 0),
+		HostRulesFile:        getEnv("URL_PROCESSOR_HOST_RULES_FILE", ""),
+		MaxMessageAge:        getEnvAsInt("URL_PROCESSOR_MAX_MESSAGE_AGE", 0),
+		MaxRetryAfter:        getEnvAsInt("URL_PROCESSOR_MAX_RETRY_AFTER", 60),
+		AllowedContentTypes:  getEnvAsList("URL_PROCESSOR_ALLOWED_CONTENT_TYPES"),
+		BorrowTimeout:        getEnvAsInt("URL_PROCESSOR_BORROW_TIMEOUT", 30),
 	}
 
 	checkRequiredVars("URL PROCESSOR", map[string]string{
@@ -102,23 +215,37 @@ func loadPoolConfig() PoolConfig {
 	pool := PoolConfig{
 		MaxSize:         getEnvAsInt("POOL_MAX_SIZE", 0),
 		RefreshInterval: getEnvAsInt("POOL_REFRESH_INTERVAL", 0),
+		OverflowPolicy:  getEnv("POOL_OVERFLOW_POLICY", "block"),
+		OverflowCap:     getEnvAsInt("POOL_OVERFLOW_CAP", 0),
+		ClientTimeout:   getEnvAsInt("POOL_CLIENT_TIMEOUT", 30),
+		Parallelism:     getEnvAsInt("POOL_PREWARM_PARALLELISM", 1),
+		CreateAttempts:  getEnvAsInt("POOL_PREWARM_ATTEMPTS", 1),
 	}
 
 	checkRequiredVars("POOL", map[string]string{
 		"POOL_MAX_SIZE":         string(rune(pool.MaxSize)),
 		"POOL_REFRESH_INTERVAL": string(rune(pool.RefreshInterval)),
 	})
+	if pool.ClientTimeout <= 0 {
+		panic(fmt.Sprintf("POOL configuration error: POOL_CLIENT_TIMEOUT must be positive, got %d", pool.ClientTimeout))
+	}
 	return pool
 }
 
 // loadProxyConfig loads Proxy configuration.
 func loadProxyConfig() ProxyConfig {
 	proxy := ProxyConfig{
-		Host:            getEnv("PROXY_HOST", ""),
-		Port:            getEnv("PROXY_PORT", ""),
-		ControlPassword: getEnv("PROXY_CONTROL_PASSWORD", ""),
-		ControlPort:     getEnv("PROXY_CONTROL_PORT", ""),
-		Url:             getEnv("PROXY_URL", ""),
+		Host:             getEnv("PROXY_HOST", ""),
+		Port:             getEnv("PROXY_PORT", ""),
+		ControlPassword:  getEnv("PROXY_CONTROL_PASSWORD", ""),
+		ControlPort:      getEnv("PROXY_CONTROL_PORT", ""),
+		Url:              getEnv("PROXY_URL", ""),
+		RotationCooldown: getEnvAsInt("PROXY_ROTATION_COOLDOWN", 10),
+		SelfTest:         getEnvAsBool("PROXY_SELF_TEST", false),
+		SelfTestTimeout:  getEnvAsInt("PROXY_SELF_TEST_TIMEOUT", 30),
+
+		StatusConcurrency:   getEnvAsInt("PROXY_STATUS_CONCURRENCY", 1),
+		StatusBorrowTimeout: getEnvAsInt("PROXY_STATUS_BORROW_TIMEOUT", 2),
 	}
 
 	checkRequiredVars("PROXY", map[string]string{
@@ -160,10 +287,14 @@ func loadTLSConfig() TLSConfig {
 // loadNatsConfig loads NATS configuration.
 func loadNatsConfig() NatsConfig {
 	nats := NatsConfig{
-		Host:    getEnv("NATS_HOST", "localhost"),
-		Port:    getEnv("NATS_PORT", ""),
-		RpcHost: getEnv("NATS_RPC_HOST", "localhost"),
-		RpcPort: getEnv("NATS_RPC_PORT", ""),
+		Host:         getEnv("NATS_HOST", "localhost"),
+		Port:         getEnv("NATS_PORT", ""),
+		RpcHost:      getEnv("NATS_RPC_HOST", "localhost"),
+		RpcPort:      getEnv("NATS_RPC_PORT", ""),
+		RpcTransport: getEnv("NATS_RPC_TRANSPORT", "tcp"),
+		RpcTLSName:   getEnv("NATS_RPC_TLS_SERVER_NAME", ""),
+		MaxStreams:   getEnvAsInt("NATS_RPC_MAX_STREAMS", 0),
+		QueueStreams: getEnvAsBool("NATS_RPC_QUEUE_STREAMS", false),
 	}
 
 	// Ensure required values are present
@@ -193,6 +324,25 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+// getEnvAsBool fetches the value of an environment variable as a boolean or returns a fallback.
+func getEnvAsBool(key string, fallback bool) bool {
+	v := getEnv(key, "")
+	if value, err := strconv.ParseBool(v); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvAsList fetches the value of an environment variable as a comma-separated list, blank items are dropped.
+func getEnvAsList(key string) (list []string) {
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // checkRequiredVars ensures required environment variables are set.
 func checkRequiredVars(section string, vars map[string]string) {
 	for key, value := range vars {
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"net/http"
 	"proxy-service/application/commands"
 	"proxy-service/application/commands/control"
 	"proxy-service/application/config"
@@ -8,8 +9,11 @@ import (
 	"proxy-service/domain/entities"
 	"proxy-service/domain/interfaces"
 	"proxy-service/infrastructure"
+	"proxy-service/infrastructure/health"
+	"shared/clock"
 	"shared/dependency"
 	"shared/grpc/clients/nats_service"
+	"shared/runlimit"
 	"time"
 )
 
@@ -21,9 +25,15 @@ type Container struct {
 	SignalCommand       dependency.LazyDependency[*control.SignalCommand]
 	StatusCommand       dependency.LazyDependency[*commands.StatusCommand]
 	RetryStrategy       dependency.LazyDependency[interfaces.RetryStrategy]
+	SubscribeStrategy   dependency.LazyDependency[interfaces.RetryStrategy]
 	NatsGrpcValidator   dependency.LazyDependency[nats_service.Validator]
 	NatsGrpcClient      dependency.LazyDependency[*nats_service.NatsClient]
+	RunBudget           dependency.LazyDependency[*runlimit.Budget]
 	UrlProcessorService dependency.LazyDependency[*services.UrlProcessorService]
+	RotationCoordinator dependency.LazyDependency[*services.RotationCoordinator]
+	ProxySelfTest       dependency.LazyDependency[*services.ProxySelfTest]
+	HealthService       dependency.LazyDependency[*services.HealthService]
+	HealthServer        dependency.LazyDependency[*health.Server]
 }
 
 // NewContainer initializes and returns a new Container with dependencies.
@@ -48,6 +58,18 @@ func NewContainer() *Container {
 			return services.NewExponentialBackoffStrategy(baseDelay, maxDelay, attempts, multiplier, logger)
 		},
 	}
+	c.SubscribeStrategy = dependency.LazyDependency[interfaces.RetryStrategy]{
+		InitFunc: func() interfaces.RetryStrategy {
+			var (
+				logger     = c.Infrastructure.Get().Logger.Get()
+				baseDelay  = time.Second
+				maxDelay   = time.Duration(30) * time.Second
+				attempts   = 0 // Bounded by URL_PROCESSOR_SUBSCRIBE_MAX_ATTEMPTS instead.
+				multiplier = 2.0
+			)
+			return services.NewExponentialBackoffStrategy(baseDelay, maxDelay, attempts, multiplier, logger)
+		},
+	}
 	c.NatsGrpcValidator = dependency.LazyDependency[nats_service.Validator]{
 		InitFunc: func() nats_service.Validator {
 			return nats_service.NewBusClientValidator()
@@ -59,6 +81,8 @@ func NewContainer() *Container {
 				logger     = c.Infrastructure.Get().Logger.Get()
 				env        = c.Config.Get().Env
 				validator  = c.NatsGrpcValidator.Get()
+				serverName = nats_service.WithServerName(c.Config.Get().Nats.RpcTLSName)
+				maxStreams = nats_service.WithMaxStreams(c.Config.Get().Nats.MaxStreams, c.Config.Get().Nats.QueueStreams)
 				natsClient *nats_service.NatsClient
 				address    string
 				err        error
@@ -66,12 +90,22 @@ func NewContainer() *Container {
 			if address, err = entities.GetNats().Address(); err != nil {
 				panic(err)
 			}
-			if natsClient, err = nats_service.NewNatsClient(env, address, validator, logger); err != nil {
+			natsClient, err = nats_service.NewNatsClient(env, address, validator, logger, serverName, maxStreams)
+			if err != nil {
 				panic(err)
 			}
 			return natsClient
 		},
 	}
+	c.RunBudget = dependency.LazyDependency[*runlimit.Budget]{
+		InitFunc: func() *runlimit.Budget {
+			run := c.Config.Get().Run
+			return runlimit.NewBudget(runlimit.Limits{
+				MaxRuntime:  time.Duration(run.MaxRuntime) * time.Second,
+				MaxMessages: int64(run.MaxMessages),
+			})
+		},
+	}
 	c.UrlProcessorService = dependency.LazyDependency[*services.UrlProcessorService]{
 		InitFunc: func() *services.UrlProcessorService {
 			var (
@@ -80,8 +114,27 @@ func NewContainer() *Container {
 				natsClient = c.NatsGrpcClient.Get()
 				batchSize  = c.Config.Get().UrlProcessor.BatchSize
 				queueGroup = c.Config.Get().UrlProcessor.QueueGroup
+				budget     = c.RunBudget.Get()
+				metrics    = c.Infrastructure.Get().ConsumerMetrics.Get()
+				processor  = c.Config.Get().UrlProcessor
+				hosts      *services.HostLimiter
+				err        error
 			)
-			return services.NewUrlProcessorService(pool, natsClient, batchSize, queueGroup, logger)
+			if hosts, err = services.LoadHostLimiter(processor.HostRulesFile); err != nil {
+				logger.Error("Failed to load host rules", "path", processor.HostRulesFile, "error", err)
+				panic(err)
+			}
+			return services.NewUrlProcessorService(pool, natsClient, batchSize, queueGroup, logger,
+				services.WithBudget(budget), services.WithConsumerMetrics(metrics),
+				services.WithRetries(c.RetryStrategy.Get(), processor.RetryAttempts,
+					time.Duration(processor.RetryTimeout)*time.Second),
+				services.WithResubscribe(c.SubscribeStrategy.Get(), processor.SubscribeMaxAttempts),
+				services.WithHostLimits(hosts),
+				services.WithMaxMessageAge(time.Duration(processor.MaxMessageAge)*time.Second),
+				services.WithMaxRetryAfter(time.Duration(processor.MaxRetryAfter)*time.Second),
+				services.WithAllowedContentTypes(processor.AllowedContentTypes...),
+				services.WithBorrowTimeout(time.Duration(processor.BorrowTimeout)*time.Second),
+				services.WithRotator(c.RotationCoordinator.Get()))
 		},
 	}
 
@@ -111,10 +164,62 @@ func NewContainer() *Container {
 			var (
 				logger  = c.Infrastructure.Get().Logger.Get()
 				timeout = time.Duration(10) * time.Second
-				url     = c.Config.Get().Proxy.Url
+				proxy   = c.Config.Get().Proxy
 				pool    = c.Infrastructure.Get().ConnectionPool.Get()
 			)
-			return commands.NewStatusCommand(timeout, url, pool, logger)
+			return commands.NewStatusCommand(timeout, proxy.Url, pool, logger,
+				commands.WithStatusConcurrency(proxy.StatusConcurrency),
+				commands.WithBorrowTimeout(time.Duration(proxy.StatusBorrowTimeout)*time.Second))
+		},
+	}
+	c.RotationCoordinator = dependency.LazyDependency[*services.RotationCoordinator]{
+		InitFunc: func() *services.RotationCoordinator {
+			var (
+				logger       = c.Infrastructure.Get().Logger.Get()
+				authenticate = c.AuthenticateCommand.Get()
+				signal       = c.SignalCommand.Get()
+				cooldown     = time.Duration(c.Config.Get().Proxy.RotationCooldown) * time.Second
+				metrics      = c.Infrastructure.Get().RotationMetrics.Get()
+			)
+			return services.NewRotationCoordinator(authenticate, signal, cooldown, metrics, clock.NewReal(), logger)
+		},
+	}
+	c.ProxySelfTest = dependency.LazyDependency[*services.ProxySelfTest]{
+		InitFunc: func() *services.ProxySelfTest {
+			var (
+				logger  = c.Infrastructure.Get().Logger.Get()
+				status  = c.StatusCommand.Get()
+				url     = c.Config.Get().Proxy.Url
+				timeout = time.Duration(c.Config.Get().Proxy.SelfTestTimeout) * time.Second
+				direct  = &http.Client{}
+			)
+			return services.NewProxySelfTest(status, direct, url, timeout, logger)
+		},
+	}
+	c.HealthService = dependency.LazyDependency[*services.HealthService]{
+		InitFunc: func() *services.HealthService {
+			var (
+				logger    = c.Infrastructure.Get().Logger.Get()
+				status    = c.StatusCommand.Get()
+				pool      = c.Infrastructure.Get().ConnectionPool.Get()
+				cfg       = c.Config.Get().Health
+				statusTTL = time.Duration(cfg.StatusTTL) * time.Second
+				window    = time.Duration(cfg.FailureWindow) * time.Second
+				processor = c.UrlProcessorService.Get()
+			)
+			return services.NewHealthService(status, pool, logger,
+				services.WithStatusTTL(statusTTL), services.WithLastSuccess(processor.LastSuccess),
+				services.WithFailureThreshold(cfg.FailureThreshold, window),
+				services.WithRecoveryThreshold(cfg.RecoveryThreshold))
+		},
+	}
+	c.HealthServer = dependency.LazyDependency[*health.Server]{
+		InitFunc: func() *health.Server {
+			var (
+				logger = c.Infrastructure.Get().Logger.Get()
+				port   = c.Config.Get().Health.ServerPort
+			)
+			return health.NewServer(port, c.HealthService.Get(), logger)
 		},
 	}
 
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"proxy-service/application/commands"
+	"proxy-service/infrastructure/http/socks5"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultStatusTTL is how long the result of a status check is reused when no TTL is configured.
+const DefaultStatusTTL = time.Duration(30) * time.Second
+
+// Breaker states reported by the health check.
+const (
+	BreakerNone   = "none"   // BreakerNone is reported when no circuit breaker guards the proxy.
+	BreakerClosed = "closed" // BreakerClosed lets requests through to the proxy.
+	BreakerOpen   = "open"   // BreakerOpen fast-fails requests, the service is unhealthy.
+)
+
+// HealthReport is the health of the service served by the health endpoint.
+// The service is healthy unless the breaker is open or the status checks fail, a saturated pool alone
+// is not unhealthy, the status check fails once it cannot borrow a client. Status check failures are smoothed,
+// see WithFailureThreshold and WithRecoveryThreshold.
+type HealthReport struct {
+	Healthy        bool       `json:"healthy"`               // Healthy is the result reflected in the HTTP status.
+	PoolSize       int        `json:"poolSize"`              // PoolSize is the number of pooled clients.
+	PoolIdle       int        `json:"poolIdle"`              // PoolIdle is the number of clients available to borrow.
+	PoolSaturation float64    `json:"poolSaturation"`        // PoolSaturation is the share of borrowed clients.
+	Breaker        string     `json:"breaker"`               // Breaker is the circuit breaker state.
+	LastSuccess    *time.Time `json:"lastSuccess,omitempty"` // LastSuccess is the last request answered by the proxy.
+	Status         string     `json:"status,omitempty"`      // Status is the response of the last status check.
+	StatusError    string     `json:"statusError,omitempty"` // StatusError is the error of the last status check.
+	StatusAt       time.Time  `json:"statusAt"`              // StatusAt is the time of the last status check.
+	Failures       int        `json:"failures,omitempty"`    // Failures is the number of recent failed status checks.
+}
+
+// HealthService reports whether the proxy path works, for orchestration liveness and readiness probes.
+// The status check fetches the ping URL through the pool, so its result is reused for the status TTL
+// to keep frequent probes from loading the proxy.
+type HealthService struct {
+	status      *commands.StatusCommand // status fetches the ping URL through a pooled SOCKS5 client.
+	pool        *socks5.ConnectionPool  // pool is the connection pool whose saturation is reported.
+	statusTTL   time.Duration           // statusTTL is how long a status check result is reused.
+	breaker     func() bool             // breaker reports whether the circuit breaker is open, nil reports none.
+	lastSuccess func() time.Time        // lastSuccess returns the last request answered by the proxy, nil omits it.
+
+	failureThreshold  int           // failureThreshold is the number of failed status checks turning unhealthy.
+	failureWindow     time.Duration // failureWindow is how long a failed status check counts, 0 is unbounded.
+	recoveryThreshold int           // recoveryThreshold is the number of passed status checks turning healthy.
+
+	mu         sync.Mutex  // mu serializes the status checks and guards their result.
+	statusBody string      // statusBody is the response of the last status check.
+	statusErr  error       // statusErr is the error of the last status check.
+	statusAt   time.Time   // statusAt is the time of the last status check, zero before the first one.
+	unhealthy  bool        // unhealthy is the smoothed result of the status checks.
+	failures   []time.Time // failures are the times of the consecutive failed status checks within the window.
+	successes  int         // successes is the number of consecutive passed status checks.
+	logger     *slog.Logger
+}
+
+// HealthOption defines a functional option for configuring HealthService.
+type HealthOption func(*HealthService)
+
+// WithStatusTTL sets how long the result of a status check is reused, a non-positive TTL keeps the default.
+func WithStatusTTL(ttl time.Duration) HealthOption {
+	return func(s *HealthService) {
+		if ttl > 0 {
+			s.statusTTL = ttl
+		}
+	}
+}
+
+// WithBreaker reports the state of the circuit breaker guarding the proxy, an open breaker is unhealthy.
+func WithBreaker(open func() bool) HealthOption {
+	return func(s *HealthService) {
+		s.breaker = open
+	}
+}
+
+// WithLastSuccess reports the time of the last request answered through the proxy, e.g. by the URL processor.
+func WithLastSuccess(lastSuccess func() time.Time) HealthOption {
+	return func(s *HealthService) {
+		s.lastSuccess = lastSuccess
+	}
+}
+
+// WithFailureThreshold turns the service unhealthy only after threshold consecutive failed status checks within
+// the window, so a single transient failure of a flapping proxy does not flip the health. A non-positive window
+// counts the failures however far apart, a threshold below 1 keeps the default of 1.
+func WithFailureThreshold(threshold int, window time.Duration) HealthOption {
+	return func(s *HealthService) {
+		s.failureThreshold = max(threshold, 1)
+		s.failureWindow = max(window, 0)
+	}
+}
+
+// WithRecoveryThreshold turns an unhealthy service healthy again only after threshold consecutive passed status
+// checks, a threshold below 1 keeps the default of 1.
+func WithRecoveryThreshold(threshold int) HealthOption {
+	return func(s *HealthService) {
+		s.recoveryThreshold = max(threshold, 1)
+	}
+}
+
+// NewHealthService creates a new instance of HealthService.
+func NewHealthService(
+	status *commands.StatusCommand,
+	pool *socks5.ConnectionPool,
+	logger *slog.Logger,
+	opts ...HealthOption,
+) *HealthService {
+	s := &HealthService{
+		status:            status,
+		pool:              pool,
+		statusTTL:         DefaultStatusTTL,
+		failureThreshold:  1,
+		recoveryThreshold: 1,
+		logger:            logger,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Check reports the health of the service, running a status check unless a recent result is reused.
+func (s *HealthService) Check(ctx context.Context) (report HealthReport) {
+	report = HealthReport{
+		PoolSize: s.pool.Size(),
+		PoolIdle: s.pool.Idle(),
+		Breaker:  BreakerNone,
+	}
+	if report.PoolSize > 0 {
+		report.PoolSaturation = float64(report.PoolSize-report.PoolIdle) / float64(report.PoolSize)
+	}
+	if s.breaker != nil {
+		report.Breaker = BreakerClosed
+		if s.breaker() {
+			report.Breaker = BreakerOpen
+		}
+	}
+	if s.lastSuccess != nil {
+		if lastSuccess := s.lastSuccess(); !lastSuccess.IsZero() {
+			report.LastSuccess = &lastSuccess
+		}
+	}
+
+	// An open breaker fails the proxied requests anyway, the status check is not worth a pooled client.
+	if report.Breaker == BreakerOpen {
+		s.logger.Warn("Health check failed", "breaker", report.Breaker)
+		return report
+	}
+
+	// The result is shared by the probes, so a probe going away must not fail it.
+	s.mu.Lock()
+	if s.statusAt.IsZero() || time.Since(s.statusAt) >= s.statusTTL {
+		s.statusBody, s.statusErr = s.status.Execute(context.WithoutCancel(ctx))
+		s.statusAt = time.Now()
+		s.observe()
+	}
+	report.Status, report.StatusAt, report.Failures = s.statusBody, s.statusAt, len(s.failures)
+	if s.statusErr != nil {
+		report.StatusError = s.statusErr.Error()
+	}
+	report.Healthy = !s.unhealthy
+	s.mu.Unlock()
+
+	if !report.Healthy {
+		s.logger.Warn("Health check failed", "statusError", report.StatusError, "failures", report.Failures)
+	} else if report.StatusError != "" {
+		s.logger.Warn("Status check failed, health kept within the failure threshold",
+			"statusError", report.StatusError, "failures", report.Failures)
+	}
+	return report
+}
+
+// observe records the result of the status check that just ran and updates the smoothed health, s.mu is held.
+func (s *HealthService) observe() {
+	if s.statusErr == nil {
+		s.failures = s.failures[:0]
+		if s.successes++; s.unhealthy && s.successes >= s.recoveryThreshold {
+			s.unhealthy = false
+			s.logger.Info("Health recovered", "successes", s.successes)
+		}
+		return
+	}
+
+	s.successes = 0
+	if s.failureWindow > 0 {
+		s.failures = slices.DeleteFunc(s.failures, func(at time.Time) bool {
+			return s.statusAt.Sub(at) > s.failureWindow
+		})
+	}
+	s.failures = append(s.failures, s.statusAt)
+	if len(s.failures) > s.failureThreshold {
+		s.failures = s.failures[1:]
+	}
+	if !s.unhealthy && len(s.failures) >= s.failureThreshold {
+		s.unhealthy = true
+	}
+}
+
+// ServeHTTP writes the health report as JSON, with 200 OK if the service is healthy and 503 otherwise.
+func (s *HealthService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := s.Check(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	if report.Healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		s.logger.Error("Could not write health report", "error", err)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// HostLimiter caps the concurrent requests to hosts listed in its rules, subdomains included.
+// Unlisted hosts are only bounded by the global concurrency of the processor.
+type HostLimiter struct {
+	slots map[string]chan struct{} // slots holds a semaphore per ruled host.
+}
+
+// NewHostLimiter creates a new instance of HostLimiter from host to max. concurrent requests rules.
+// Non-positive limits are rejected.
+func NewHostLimiter(rules map[string]int) (limiter *HostLimiter, err error) {
+	limiter = &HostLimiter{slots: make(map[string]chan struct{}, len(rules))}
+	for host, limit := range rules {
+		if limit <= 0 {
+			return nil, fmt.Errorf("host %q: max. concurrent requests must be positive, got %d", host, limit)
+		}
+		limiter.slots[normalizeHost(host)] = make(chan struct{}, limit)
+	}
+	return limiter, nil
+}
+
+// LoadHostLimiter creates a HostLimiter from a JSON rules file mapping hosts to max. concurrent requests,
+// e.g. {"api.example.com": 1}. An empty path creates a limiter without rules.
+func LoadHostLimiter(path string) (limiter *HostLimiter, err error) {
+	rules := make(map[string]int)
+	if path == "" {
+		return NewHostLimiter(rules)
+	}
+
+	var data []byte
+	if data, err = os.ReadFile(path); err != nil {
+		return nil, fmt.Errorf("read host rules: %w", err)
+	}
+	if err = json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("decode host rules %s: %w", path, err)
+	}
+	return NewHostLimiter(rules)
+}
+
+// Acquire blocks until a request to the host may start and returns the function releasing its slot.
+// The most specific rule matching the host or one of its parent domains applies.
+func (l *HostLimiter) Acquire(host string) (release func()) {
+	slot := l.match(normalizeHost(host))
+	if slot == nil {
+		return func() {}
+	}
+	slot <- struct{}{}
+	return func() { <-slot }
+}
+
+// match returns the semaphore of the most specific rule matching the host, or nil if none does.
+func (l *HostLimiter) match(host string) chan struct{} {
+	for host != "" {
+		if slot, ok := l.slots[host]; ok {
+			return slot
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return nil
+}
+
+// normalizeHost lower-cases the host and drops its trailing dot.
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
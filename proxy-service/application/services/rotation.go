@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"proxy-service/domain/entities"
+	"proxy-service/domain/interfaces"
+	"proxy-service/infrastructure/metrics"
+	"shared/clock"
+	"sync"
+	"time"
+)
+
+// ErrRotationCooldown is returned when a rotation is requested before the cooldown has elapsed.
+var ErrRotationCooldown = errors.New("circuit rotation is cooling down")
+
+// RotationCoordinator serializes proxy circuit rotations and enforces a cooldown between them.
+type RotationCoordinator struct {
+	authenticate interfaces.Command       // authenticate authenticates with the proxy control port.
+	signal       interfaces.Command       // signal sends the rotation signal (e.g., "NEWNYM").
+	cooldown     time.Duration            // cooldown is the minimum duration between two rotations.
+	lastRotation time.Time                // lastRotation is the time of the last successful rotation.
+	mu           sync.Mutex               // mu serializes rotations.
+	metrics      *metrics.RotationMetrics // metrics records rotation metrics.
+	clock        clock.Clock              // clock is the time source of the cooldown.
+	logger       *slog.Logger             // logger for structured logging.
+}
+
+// NewRotationCoordinator creates a new instance of RotationCoordinator.
+func NewRotationCoordinator(
+	authenticate, signal interfaces.Command,
+	cooldown time.Duration,
+	metrics *metrics.RotationMetrics,
+	clock clock.Clock,
+	logger *slog.Logger,
+) *RotationCoordinator {
+	return &RotationCoordinator{
+		authenticate: authenticate,
+		signal:       signal,
+		cooldown:     cooldown,
+		metrics:      metrics,
+		clock:        clock,
+		logger:       logger,
+	}
+}
+
+// Rotate requests a new proxy circuit for the given cause.
+// It returns ErrRotationCooldown if the previous rotation happened less than cooldown ago.
+func (r *RotationCoordinator) Rotate(cause entities.RotationCause) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.lastRotation.IsZero() && r.clock.Now().Sub(r.lastRotation) < r.cooldown {
+		r.metrics.ObserveCooldownRejection()
+		r.logger.Warn("Rotation rejected during cooldown", "cause", cause, "cooldown", r.cooldown)
+		return ErrRotationCooldown
+	}
+
+	if err = r.authenticate.Execute(); err != nil {
+		r.logger.Error("Could not authenticate for rotation", "cause", cause, "error", err)
+		return fmt.Errorf("authenticate for rotation: %w", err)
+	}
+	if err = r.signal.Execute(); err != nil {
+		r.logger.Error("Could not rotate circuit", "cause", cause, "error", err)
+		return fmt.Errorf("rotate circuit: %w", err)
+	}
+
+	r.lastRotation = r.clock.Now()
+	r.metrics.ObserveRotation(cause)
+	r.logger.Info("Circuit rotated", "cause", cause)
+	return nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"proxy-service/application/commands"
+	"strings"
+	"time"
+)
+
+// ErrProxyNotProxying is returned when the exit IP seen through the proxy matches the local IP.
+var ErrProxyNotProxying = errors.New("proxy exit IP matches the local IP")
+
+// ProxySelfTest verifies on startup that requests sent through the pool actually leave via the proxy.
+type ProxySelfTest struct {
+	status  *commands.StatusCommand // status fetches the check URL through a pooled SOCKS5 client.
+	direct  *http.Client            // direct fetches the check URL without the proxy.
+	url     string                  // url is the IP echo endpoint used for both requests.
+	timeout time.Duration           // timeout bounds the whole self-test.
+	logger  *slog.Logger            // logger for structured logging.
+}
+
+// NewProxySelfTest creates a new instance of ProxySelfTest.
+func NewProxySelfTest(
+	status *commands.StatusCommand,
+	direct *http.Client,
+	url string,
+	timeout time.Duration,
+	logger *slog.Logger,
+) *ProxySelfTest {
+	return &ProxySelfTest{
+		status:  status,
+		direct:  direct,
+		url:     url,
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Run fetches the check URL through the proxy and directly, and compares the reported IPs.
+// It returns ErrProxyNotProxying if both requests report the same address.
+func (s *ProxySelfTest) Run(ctx context.Context) (err error) {
+	var (
+		proxied string
+		local   string
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	s.logger.Info("Running proxy self-test", "url", s.url, "timeout", s.timeout)
+
+	if proxied, err = s.status.Execute(ctx); err != nil {
+		s.logger.Error("Proxy self-test could not fetch through the proxy", "error", err)
+		return fmt.Errorf("fetch through proxy: %w", err)
+	}
+	if local, err = s.fetchDirect(ctx); err != nil {
+		s.logger.Error("Proxy self-test could not fetch directly", "error", err)
+		return fmt.Errorf("fetch directly: %w", err)
+	}
+
+	exitIP, localIP := extractIP(proxied), extractIP(local)
+	if exitIP == "" {
+		return fmt.Errorf("empty exit IP in response %q", proxied)
+	}
+	if exitIP == localIP {
+		s.logger.Error("Proxy self-test failed, traffic is not proxied", "exitIP", exitIP, "localIP", localIP)
+		return fmt.Errorf("%w: %s", ErrProxyNotProxying, exitIP)
+	}
+
+	s.logger.Info("Proxy self-test passed", "exitIP", exitIP, "localIP", localIP)
+	return nil
+}
+
+// fetchDirect fetches the check URL without the proxy and returns the response body.
+func (s *ProxySelfTest) fetchDirect(ctx context.Context) (body string, err error) {
+	var (
+		request  *http.Request
+		response *http.Response
+		data     []byte
+	)
+
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, s.url, http.NoBody); err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	if response, err = s.direct.Do(request); err != nil {
+		return "", fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if data, err = io.ReadAll(response.Body); err != nil {
+		return "", fmt.Errorf("read body: %w", err)
+	}
+	return string(data), nil
+}
+
+// extractIP returns the "origin" field of an IP echo response, or the trimmed body if it is not JSON.
+func extractIP(body string) string {
+	var data struct {
+		Origin string `json:"origin"`
+	}
+	if err := json.Unmarshal([]byte(body), &data); err == nil && data.Origin != "" {
+		return strings.TrimSpace(data.Origin)
+	}
+	return strings.TrimSpace(body)
+}
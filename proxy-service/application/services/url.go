@@ -2,59 +2,274 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"net/url"
+	"proxy-service/domain/entities"
+	"proxy-service/domain/interfaces"
 	"proxy-service/infrastructure/http/socks5"
+	"proxy-service/infrastructure/metrics"
 	"shared/grpc/clients/nats_service"
 	"shared/grpc/clients/nats_service/messaging"
+	"shared/retry"
+	"shared/runlimit"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// ErrSubscribeGaveUp is returned by Start once the subscription failed transiently more often than allowed.
+var ErrSubscribeGaveUp = errors.New("gave up resubscribing")
+
+// ErrContentTypeNotAllowed is returned by fetch when the response content type is not in the allowlist.
+var ErrContentTypeNotAllowed = errors.New("response content type not allowed")
+
+// DefaultMaxRetryAfter caps the Retry-After delays honored when no cap is configured.
+const DefaultMaxRetryAfter = time.Duration(60) * time.Second
+
+// DefaultBorrowTimeout bounds the wait of a fetch for a pooled HTTP client when no timeout is configured.
+const DefaultBorrowTimeout = time.Duration(30) * time.Second
+
+// rateLimitedError is returned by fetch when the target answers 429 Too Many Requests.
+type rateLimitedError struct {
+	status     string        // status is the status line of the response.
+	retryAfter time.Duration // retryAfter is the delay requested by the Retry-After header, 0 when absent.
+}
+
+// Error implements the error interface.
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("rate limited: %s", e.status)
+}
+
+// Retryable implements retry.Classifier, a rate limited fetch is retried once the target allows it.
+func (e *rateLimitedError) Retryable() bool {
+	return true
+}
+
 // UrlProcessorService coordinates processing of URL messages received from a NATS subject.
 type UrlProcessorService struct {
 	pool       *socks5.ConnectionPool   // pool is the connection pool used to borrow/return HTTP clients.
-	natsClient *nats_service.NatsClient // natsClient is used for NATS subscriptions and publishing.
+	natsClient nats_service.Client      // natsClient is used for NATS subscriptions and publishing.
 	batchSize  int                      // batchSize determines the max. number of concurrent URL processing goroutines.
 	semaphore  chan struct{}            // semaphore is used to limit the number of concurrently processing goroutines.
 	queueGroup string                   // queueGroup is the NATS queue group for load balancing.
-	logger     *slog.Logger             // logger for structured logging.
+	budget     *runlimit.Budget         // budget counts the processed URLs of a job-style run, nil is unlimited.
+	metrics    *metrics.ConsumerMetrics // metrics records the consumer lag, nil disables it.
+
+	retryStrategy interfaces.RetryStrategy // retryStrategy paces the retries of failed stages, nil disables them.
+	retryAttempts int                      // retryAttempts is the retry budget of messages arriving without one.
+	retryTimeout  time.Duration            // retryTimeout bounds the retries of messages arriving without a budget.
+	maxRetryAfter time.Duration            // maxRetryAfter caps the Retry-After delays of rate limited fetches.
+
+	subscribeStrategy    interfaces.RetryStrategy // subscribeStrategy paces resubscribes, nil disables them.
+	subscribeMaxAttempts int                      // subscribeMaxAttempts caps consecutive resubscribes, 0 is unlimited.
+	received             atomic.Bool              // received reports whether a message arrived since the last subscribe.
+
+	hosts         *HostLimiter  // hosts caps the concurrent requests to ruled hosts, nil disables it.
+	maxMessageAge time.Duration // maxMessageAge drops requests published longer ago, 0 disables it.
+	contentTypes  []string      // contentTypes is the allowlist of response media types, empty allows all.
+	borrowTimeout time.Duration // borrowTimeout bounds the wait for a pooled HTTP client, 0 waits indefinitely.
+	lastSuccess   atomic.Int64  // lastSuccess is the unix nanoseconds of the last successful fetch, 0 is none.
+
+	rotator interfaces.Rotator // rotator requests a new circuit after a transiently failed fetch, nil disables it.
+
+	logger *slog.Logger // logger for structured logging.
+}
+
+// UrlProcessorOption defines a functional option for configuring UrlProcessorService.
+type UrlProcessorOption func(*UrlProcessorService)
+
+// WithBudget counts every processed URL against the budget of a job-style run.
+// Messages delivered before the run stops are still processed, so a run may process a few more URLs than its limit.
+func WithBudget(budget *runlimit.Budget) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.budget = budget
+	}
+}
+
+// WithConsumerMetrics records the delivery delay of enveloped messages carrying their publish time.
+func WithConsumerMetrics(metrics *metrics.ConsumerMetrics) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.metrics = metrics
+	}
+}
+
+// WithRetries retries failed fetches and publishes paced by strategy. The retries of a message are bounded
+// across the stages by the retry budget carried in its envelope, or by attempts retries within timeout.
+func WithRetries(strategy interfaces.RetryStrategy, attempts int, timeout time.Duration) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.retryStrategy = strategy
+		s.retryAttempts = attempts
+		s.retryTimeout = timeout
+	}
+}
+
+// WithMaxRetryAfter caps the delay honored when a target rate limits a fetch with a Retry-After header,
+// a longer requested delay is shortened to maxDelay. Retries must be enabled by WithRetries for it to apply.
+func WithMaxRetryAfter(maxDelay time.Duration) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.maxRetryAfter = maxDelay
+	}
+}
+
+// WithResubscribe keeps the service consuming across nats-service restarts: a subscription failing with a transient
+// error is re-established paced by strategy, up to maxAttempts consecutive times (0 is unlimited).
+// Fatal errors such as invalid requests or missing permissions still stop the service.
+func WithResubscribe(strategy interfaces.RetryStrategy, maxAttempts int) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.subscribeStrategy = strategy
+		s.subscribeMaxAttempts = maxAttempts
+	}
+}
+
+// WithHostLimits caps the concurrent requests to the hosts ruled by limiter, on top of the global batch size.
+func WithHostLimits(limiter *HostLimiter) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.hosts = limiter
+	}
+}
+
+// WithMaxMessageAge drops requests published more than maxAge ago instead of fetching them, e.g. after an outage.
+// Requests without a publish time are always processed, a zero maxAge disables the check.
+func WithMaxMessageAge(maxAge time.Duration) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.maxMessageAge = maxAge
+	}
+}
+
+// WithAllowedContentTypes skips the responses whose Content-Type is not one of types instead of publishing them,
+// before their body is read. Types are media types such as "text/html", or a "text/*" wildcard matching every
+// subtype, parameters are ignored. Without types every response is published.
+func WithAllowedContentTypes(types ...string) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		for _, contentType := range types {
+			if contentType = strings.ToLower(strings.TrimSpace(contentType)); contentType != "" {
+				s.contentTypes = append(s.contentTypes, contentType)
+			}
+		}
+	}
+}
+
+// WithBorrowTimeout bounds the wait of a fetch for a pooled HTTP client while all of them are borrowed, e.g. behind
+// a slow proxy, the fetch then fails and is retried like any other failed fetch. A zero timeout waits indefinitely.
+func WithBorrowTimeout(timeout time.Duration) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.borrowTimeout = max(timeout, 0)
+	}
+}
+
+// WithRotator requests a new proxy circuit for every fetch given up on with a transient failure, e.g. a broken or
+// blocked exit. The rotator enforces its own cooldown, so a burst of failures rotates the circuit once.
+func WithRotator(rotator interfaces.Rotator) UrlProcessorOption {
+	return func(s *UrlProcessorService) {
+		s.rotator = rotator
+	}
 }
 
 // NewUrlProcessorService creates a new instance of UrlProcessorService.
 func NewUrlProcessorService(
 	pool *socks5.ConnectionPool,
-	natsClient *nats_service.NatsClient,
+	natsClient nats_service.Client,
 	batchSize int,
 	queueGroup string,
 	logger *slog.Logger,
+	opts ...UrlProcessorOption,
 ) *UrlProcessorService {
-	return &UrlProcessorService{
+	s := &UrlProcessorService{
 		pool:       pool,
 		natsClient: natsClient,
 		batchSize:  batchSize,
 		queueGroup: queueGroup,
 		semaphore:  make(chan struct{}, batchSize),
 		logger:     logger,
+
+		maxRetryAfter: DefaultMaxRetryAfter,
+		borrowTimeout: DefaultBorrowTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // Start subscribes to the ProxyUrlRequest subject and processes incoming URL messages.
+// With resubscribes enabled it only returns on a fatal error, on context cancellation or once it gives up.
 func (s *UrlProcessorService) Start(ctx context.Context) (err error) {
 	s.logger.Info("Starting URL processor service", "queueGroup", s.queueGroup)
-	return s.natsClient.Subscribe(ctx, messaging.ProxyUrlRequest, s.queueGroup, s.messageHandler)
+
+	for attempt := 0; ; attempt++ {
+		s.received.Store(false)
+		err = s.natsClient.Subscribe(ctx, messaging.ProxyUrlRequest, s.queueGroup, s.messageHandler)
+		if ctx.Err() != nil || s.subscribeStrategy == nil || !transient(err) {
+			return err
+		}
+
+		// A subscription that delivered messages was healthy, the next failure starts a fresh series.
+		if s.received.Load() {
+			attempt = 0
+		}
+		if s.subscribeMaxAttempts > 0 && attempt >= s.subscribeMaxAttempts {
+			s.logger.Error("Giving up resubscribing", "subject", messaging.ProxyUrlRequest, "attempts", attempt,
+				"error", err)
+			return fmt.Errorf("%w after %d attempts: %w", ErrSubscribeGaveUp, attempt, err)
+		}
+
+		var wait time.Duration
+		if wait, err = s.subscribeStrategy.WaitDuration(attempt); err != nil {
+			return fmt.Errorf("resubscribe: %w", err)
+		}
+		s.logger.Warn("Subscription interrupted, resubscribing", "subject", messaging.ProxyUrlRequest,
+			"attempt", attempt+1, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// transient reports whether a subscription ended by err may succeed when re-established.
+// A nil error is a stream closed by the server, e.g. on a nats-service restart.
+func transient(err error) bool {
+	return err == nil || retry.IsRetryable(err)
 }
 
 // messageHandler is the callback function that processes each incoming message.
-// It validates the URL, makes an HTTP GET request using a borrowed client from the connection pool,
-// and publishes the response body to the ProxyUrlResponse subject.
+// It validates the fetch request, makes the HTTP request using a borrowed client from the connection pool,
+// and publishes the response body to the ProxyUrlResponse subject, or a FetchError to the ProxyUrlError subject
+// once the fetch is given up. Messages may be enveloped, legacy messages without an envelope carry the plain URL.
+// The payload is a JSON fetch request, or the plain URL of a GET request.
 func (s *UrlProcessorService) messageHandler(data []byte, subject string) {
+	s.received.Store(true)
+	envelope, err := messaging.Open(data)
+	if err != nil {
+		s.logger.Error("Invalid message envelope", "subject", subject, "error", err)
+		return
+	}
+	now := time.Now()
+	if lag, ok := envelope.Lag(now); ok && s.metrics != nil {
+		s.metrics.ObserveLag(subject, lag)
+	}
+	if envelope.Stale(now, s.maxMessageAge) {
+		s.logger.Warn("Dropping stale message", "subject", subject, "publishedAt", envelope.PublishedAt,
+			"maxAge", s.maxMessageAge)
+		if s.metrics != nil {
+			s.metrics.IncStaleDropped(subject)
+		}
+		return
+	}
+
 	// Acquire a semaphore slot.
 	s.semaphore <- struct{}{}
 
 	// Process a message.
-	go func(data []byte, subject string) {
+	go func(envelope messaging.Envelope, subject string) {
 		defer func() { <-s.semaphore }()
 		defer func() {
 			if r := recover(); r != nil {
@@ -64,59 +279,310 @@ func (s *UrlProcessorService) messageHandler(data []byte, subject string) {
 
 		// Workload
 		var (
-			rawURL     = string(data)
-			client     *http.Client
-			parsedURL  *url.URL
-			request    *http.Request
-			response   *http.Response
-			requestCtx context.Context
-			cancel     context.CancelFunc
-			body       []byte
-			err        error
+			budget    = envelope.Retry
+			request   messaging.FetchRequest
+			parsedURL *url.URL
+			body      []byte
+			err       error
 		)
 
-		s.logger.Info("Processing URL", "url", rawURL, "subject", subject)
+		if request, err = messaging.ParseFetchRequest(envelope.Payload); err != nil {
+			s.logger.Error("Invalid fetch request received", "subject", subject, "error", err)
+			return
+		}
+		s.logger.Info("Processing URL", "url", request.URL, "method", request.Method, "subject", subject)
 
 		// Validate that URL is well-formed.
-		if parsedURL, err = url.ParseRequestURI(rawURL); err != nil {
-			s.logger.Error("Invalid URL received", "url", rawURL, "error", err)
+		if parsedURL, err = url.ParseRequestURI(request.URL); err != nil {
+			s.logger.Error("Invalid URL received", "url", request.URL, "error", err)
 			return
 		}
 
-		// Borrow HTTP client from the pool.
-		client = s.pool.Borrow()
-		defer s.pool.Return(client)
-
-		requestCtx, cancel = context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
-		defer cancel()
+		// Messages without a retry budget get a fresh one, the stages below share it.
+		if budget == nil {
+			budget = messaging.NewRetryBudget(s.retryAttempts, s.retryTimeout, time.Now())
+		}
 
-		// Create and execute HTTP request.
-		request, err = http.NewRequestWithContext(requestCtx, http.MethodGet, parsedURL.String(), http.NoBody)
-		if err != nil {
-			s.logger.Error("Could not create HTTP request", "url", parsedURL.String(), "error", err)
+		if err = s.retry(budget, "fetch", parsedURL, func() (err error) {
+			body, err = s.fetch(request, parsedURL)
+			return err
+		}); errors.Is(err, ErrContentTypeNotAllowed) {
+			if s.metrics != nil {
+				s.metrics.IncContentTypeSkipped(subject)
+			}
+			s.budget.Done()
+			return
+		} else if err != nil {
+			s.rotate(err)
+			s.publishError(request, err)
 			return
 		}
 
-		if response, err = client.Do(request); err != nil {
-			s.logger.Error("Could not make HTTP request", "url", parsedURL.String(), "error", err)
+		if err = s.retry(budget, "publish", parsedURL, func() error {
+			return s.publish(parsedURL, body, envelope.Retry != nil, budget)
+		}); err != nil {
 			return
 		}
-		defer func() {
-			if closeErr := response.Body.Close(); closeErr != nil {
-				s.logger.Error("Could not close response body", "url", parsedURL.String(), "error", closeErr)
+
+		s.logger.Info("Successfully processed URL", "url", parsedURL.String())
+		s.budget.Done()
+	}(envelope, subject)
+}
+
+// fetch makes the HTTP request described by fetchRequest to the URL using a borrowed client from the connection
+// pool and returns the response body. Server errors are returned as errors, so the request is retried.
+func (s *UrlProcessorService) fetch(fetchRequest messaging.FetchRequest, parsedURL *url.URL) (body []byte, err error) {
+	var (
+		client     *http.Client
+		request    *http.Request
+		payload    io.Reader = http.NoBody
+		response   *http.Response
+		borrowCtx  = context.Background()
+		requestCtx context.Context
+		cancel     context.CancelFunc
+	)
+
+	// Wait for a slot of the host before borrowing a client, so a busy host does not hold clients idle.
+	if s.hosts != nil {
+		release := s.hosts.Acquire(parsedURL.Hostname())
+		defer release()
+	}
+
+	// Borrow HTTP client from the pool.
+	if s.borrowTimeout > 0 {
+		var cancelBorrow context.CancelFunc
+		borrowCtx, cancelBorrow = context.WithTimeout(borrowCtx, s.borrowTimeout)
+		defer cancelBorrow()
+	}
+	if client, err = s.pool.BorrowContext(borrowCtx); err != nil {
+		s.logger.Error("Could not borrow HTTP client", "url", parsedURL.String(), "error", err)
+		return nil, err
+	}
+	defer s.pool.Return(client)
+
+	requestCtx, cancel = context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	// Create and execute HTTP request, the body is read anew on every attempt.
+	if fetchRequest.Body != "" {
+		payload = strings.NewReader(fetchRequest.Body)
+	}
+	request, err = http.NewRequestWithContext(requestCtx, fetchRequest.Method, parsedURL.String(), payload)
+	if err != nil {
+		s.logger.Error("Could not create HTTP request", "url", parsedURL.String(), "error", err)
+		return nil, err
+	}
+	for name, value := range fetchRequest.Headers {
+		request.Header.Set(name, value)
+	}
+
+	if response, err = client.Do(request); err != nil {
+		s.logger.Error("Could not make HTTP request", "url", parsedURL.String(), "error", err)
+		return nil, err
+	}
+	// Any response, server errors included, went through the proxy.
+	s.lastSuccess.Store(time.Now().UnixNano())
+	defer func() {
+		if closeErr := response.Body.Close(); closeErr != nil {
+			s.logger.Error("Could not close response body", "url", parsedURL.String(), "error", closeErr)
+		}
+	}()
+
+	// Rate limited fetches are retried no earlier than the target asks for.
+	if response.StatusCode == http.StatusTooManyRequests {
+		delay, _ := retryAfter(response.Header.Get("Retry-After"), time.Now())
+		s.logger.Warn("Rate limited by URL", "url", parsedURL.String(), "retryAfter", delay)
+		return nil, &rateLimitedError{status: response.Status, retryAfter: delay}
+	}
+	if response.StatusCode >= http.StatusInternalServerError {
+		s.logger.Error("Server error for URL", "url", parsedURL.String(), "status", response.StatusCode)
+		return nil, &retry.StatusError{Code: response.StatusCode, Status: response.Status}
+	}
+
+	// Skip disallowed responses before reading their body.
+	if contentType := response.Header.Get("Content-Type"); !s.allowed(contentType) {
+		s.logger.Info("Skipped URL with a disallowed content type", "url", parsedURL.String(),
+			"contentType", contentType)
+		return nil, fmt.Errorf("%w: %q", ErrContentTypeNotAllowed, contentType)
+	}
+
+	// Process the response.
+	if body, err = io.ReadAll(response.Body); err != nil {
+		s.logger.Error("Could not read response body", "url", parsedURL.String(), "error", err)
+		return nil, err
+	}
+	return body, nil
+}
+
+// LastSuccess returns the time of the last request answered through the proxy, zero if there was none.
+func (s *UrlProcessorService) LastSuccess() time.Time {
+	if nanos := s.lastSuccess.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// allowed reports whether a response of the Content-Type header value may be published.
+// A missing or invalid Content-Type is only allowed when every type is.
+func (s *UrlProcessorService) allowed(contentType string) bool {
+	if len(s.contentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range s.contentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
 			}
-		}()
+		} else if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
 
-		// Process the response.
-		if body, err = io.ReadAll(response.Body); err != nil {
-			s.logger.Error("Could not read response body", "url", parsedURL.String(), "error", err)
-			return
+// publish publishes the response body to the ProxyUrlResponse subject.
+// Responses to requests carrying a retry budget are enveloped and pass the remaining budget on, they are built
+// on every attempt so the budget reflects the retries of the publish itself.
+func (s *UrlProcessorService) publish(
+	parsedURL *url.URL,
+	body []byte,
+	enveloped bool,
+	budget *messaging.RetryBudget,
+) (err error) {
+	var (
+		response   = body
+		publishCtx context.Context
+		cancel     context.CancelFunc
+	)
+
+	if enveloped {
+		response, err = messaging.Encode(messaging.Envelope{
+			Format:      messaging.FormatRaw,
+			Payload:     body,
+			PublishedAt: time.Now(),
+			Retry:       budget,
+		})
+		if err != nil {
+			s.logger.Error("Could not envelope URL response", "url", parsedURL.String(), "error", err)
+			return err
 		}
-		if err = s.natsClient.Publish(request.Context(), messaging.ProxyUrlResponse, body); err != nil {
-			s.logger.Error("Could not publish URL response", "url", parsedURL.String(), "error", err)
-			return
+	}
+
+	publishCtx, cancel = context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+	if err = s.natsClient.Publish(publishCtx, messaging.ProxyUrlResponse, response); err != nil {
+		s.logger.Error("Could not publish URL response", "url", parsedURL.String(), "error", err)
+		return err
+	}
+	return nil
+}
+
+// rotate requests a new proxy circuit after a fetch failed transiently, a failed rotation is only logged
+// and a rotation rejected during the cooldown is logged by the rotator.
+func (s *UrlProcessorService) rotate(fetchErr error) {
+	if s.rotator == nil || !retry.IsRetryable(fetchErr) {
+		return
+	}
+	if err := s.rotator.Rotate(entities.RotationCauseFailure); err != nil && !errors.Is(err, ErrRotationCooldown) {
+		s.logger.Warn("Could not rotate circuit after a failed fetch", "error", err)
+	}
+}
+
+// publishError publishes a FetchError of a request given up on to the ProxyUrlError subject, so consumers learn
+// about URLs no response will be published for. A failed publish is only logged.
+func (s *UrlProcessorService) publishError(request messaging.FetchRequest, fetchErr error) {
+	var (
+		payload    []byte
+		data       []byte
+		err        error
+		publishCtx context.Context
+		cancel     context.CancelFunc
+	)
+
+	payload, err = json.Marshal(messaging.FetchError{
+		Method: request.Method,
+		URL:    request.URL,
+		Error:  fetchErr.Error(),
+		At:     time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Could not marshal URL error", "url", request.URL, "error", err)
+		return
+	}
+	if data, err = messaging.Encode(messaging.Envelope{
+		Format:      messaging.FormatJSON,
+		Payload:     payload,
+		PublishedAt: time.Now(),
+	}); err != nil {
+		s.logger.Error("Could not envelope URL error", "url", request.URL, "error", err)
+		return
+	}
+
+	publishCtx, cancel = context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+	if err = s.natsClient.Publish(publishCtx, messaging.ProxyUrlError, data); err != nil {
+		s.logger.Error("Could not publish URL error", "url", request.URL, "error", err)
+	}
+}
+
+// retry runs the stage until it succeeds, fails with an error that is not retryable (see retry.IsRetryable),
+// the retry strategy gives up, or the retry budget of the message runs out.
+// Once the budget is exhausted, by this stage or an earlier one, a failed stage fails fast.
+func (s *UrlProcessorService) retry(
+	budget *messaging.RetryBudget,
+	stage string,
+	parsedURL *url.URL,
+	run func() error,
+) (err error) {
+	for attempt := 0; ; attempt++ {
+		if err = run(); err == nil || s.retryStrategy == nil {
+			return err
+		}
+		if !retry.IsRetryable(err) {
+			s.logger.Warn("Stage failed with a final error", "url", parsedURL.String(), "stage", stage, "error", err)
+			return err
 		}
 
-		s.logger.Info("Successfully processed URL", "url", parsedURL.String())
-	}(data, subject)
+		wait, strategyErr := s.retryStrategy.WaitDuration(attempt)
+		if strategyErr != nil {
+			s.logger.Error("Retries exhausted", "url", parsedURL.String(), "stage", stage, "attempts", attempt+1)
+			return err
+		}
+		if !budget.Take(time.Now()) {
+			s.logger.Error("Retry budget exhausted, failing fast", "url", parsedURL.String(), "stage", stage,
+				"attempts", attempt+1)
+			return err
+		}
+
+		// A Retry-After delay replaces the delay of the strategy, capped so a target cannot stall the slot.
+		var rateLimited *rateLimitedError
+		if errors.As(err, &rateLimited) && rateLimited.retryAfter > 0 {
+			wait = min(rateLimited.retryAfter, s.maxRetryAfter)
+		}
+
+		s.logger.Info("Retrying stage", "url", parsedURL.String(), "stage", stage, "wait", wait)
+		time.Sleep(wait)
+	}
+}
+
+// retryAfter parses a Retry-After header value, given either in seconds or as an HTTP date.
+// ok is false for an absent or invalid value, a date in the past is no delay.
+func retryAfter(value string, now time.Time) (delay time.Duration, ok bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
 }
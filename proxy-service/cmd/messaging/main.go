@@ -2,8 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
 	"os/signal"
 	"proxy-service/application"
+	"proxy-service/application/config"
+	"shared/logging"
+	"shared/reload"
+	"shared/runlimit"
 	"syscall"
 	"time"
 )
@@ -15,32 +24,95 @@ func main() {
 		urlProcessor    = app.UrlProcessorService.Get()
 		connectionPool  = app.Infrastructure.Get().ConnectionPool.Get()
 		natsClient      = app.NatsGrpcClient.Get()
+		metricsServer   = app.Infrastructure.Get().MetricsServer.Get()
+		healthPort      = app.Config.Get().Health.ServerPort
+		selfTestEnabled = app.Config.Get().Proxy.SelfTest
 		gracePeriod     = time.Duration(2) * time.Second
 		processorCtx    context.Context
 		processorCancel context.CancelFunc
 	)
 
-	processorCtx, processorCancel = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	signalCtx, signalCancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer signalCancel()
+
+	// Job-style runs also stop once their max. runtime or max. number of URLs is reached.
+	processorCtx, processorCancel = app.RunBudget.Get().Bind(signalCtx)
 	defer processorCancel()
 
 	logger.Info("Starting messaging service")
+	logger.Info("Effective configuration", logging.Config("config", app.Config.Get()))
+
+	// Verify the proxy path before accepting any work.
+	if selfTestEnabled {
+		if err := app.ProxySelfTest.Get().Run(processorCtx); err != nil {
+			logger.Error("Proxy self-test failed, refusing to start", "error", err)
+			connectionPool.Shutdown()
+			_ = natsClient.Close()
+			processorCancel()
+			os.Exit(1)
+		}
+	}
+
+	// Start the metrics server.
+	go func() {
+		if err := metricsServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Error running metrics server", "error", err)
+		}
+	}()
+
+	// Start the health server, it reports the pool and the proxy path to orchestration probes.
+	if healthPort != "" {
+		go func() {
+			if err := app.HealthServer.Get().Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Error running health server", "error", err)
+			}
+		}()
+	}
 
 	// Start the URL processor, it will listen for messages until the context is canceled.
+	// A processor stopped by a fatal error shuts the service down rather than leaving it idle.
 	go func() {
-		if err := urlProcessor.Start(processorCtx); err != nil {
+		if err := urlProcessor.Start(processorCtx); err != nil && processorCtx.Err() == nil {
 			logger.Error("Error running URL processor", "error", err)
+			processorCancel()
 		}
 	}()
 
+	// Apply the hot-reloadable parameters on SIGHUP.
+	reloadSignals, stopReload := reload.Notify()
+	defer stopReload()
+	go reload.Watch(processorCtx, reloadSignals, reloadConfig(app), logger)
+
 	<-processorCtx.Done()
-	logger.Info("Shutdown signal received, commencing graceful shutdown")
+	if cause := context.Cause(processorCtx); runlimit.Reached(cause) {
+		logger.Info("Run limit reached, commencing graceful shutdown", "reason", cause,
+			"processed", app.RunBudget.Get().Processed())
+	} else {
+		logger.Info("Shutdown signal received, commencing graceful shutdown")
+	}
 	logger.Info("Waiting for in-flight operations to complete", "gracePeriod", gracePeriod)
 	time.Sleep(gracePeriod)
 
-	// Clean up resources.
+	// Clean up resources, the health server borrows pooled clients so it stops first.
+	if healthPort != "" {
+		logger.Info("Stopping health server")
+		healthCtx, healthCancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+		defer healthCancel()
+		if err := app.HealthServer.Get().Stop(healthCtx); err != nil {
+			logger.Error("Error stopping health server", "error", err)
+		}
+	}
+
 	logger.Info("Shutting down connection pool")
 	connectionPool.Shutdown()
 
+	logger.Info("Stopping metrics server")
+	metricsCtx, metricsCancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer metricsCancel()
+	if err := metricsServer.Stop(metricsCtx); err != nil {
+		logger.Error("Error stopping metrics server", "error", err)
+	}
+
 	logger.Info("Closing NATS client connection")
 	if err := natsClient.Close(); err != nil {
 		logger.Error("Error closing NATS client", "error", err)
@@ -48,3 +120,33 @@ func main() {
 
 	logger.Info("Service gracefully shutdown")
 }
+
+// reloadConfig re-reads the configuration and applies the hot-reloadable parameters without reconnecting:
+//   - LOG_LEVEL: minimum log level.
+//   - POOL_REFRESH_INTERVAL: seconds between refreshes of idle pool connections, the pool is not rebuilt.
+//
+// Every other parameter (NATS, proxy, TLS, pool size and overflow, batch sizes, metrics) requires a restart.
+// The service has no rate limits to reload, rotations are bounded by PROXY_ROTATION_COOLDOWN which needs a restart.
+func reloadConfig(app *application.Container) reload.Func {
+	return func() error {
+		var (
+			reloadFile = app.Config.Get().ReloadFile
+			cfg        *config.Config
+			level      slog.Level
+			err        error
+		)
+		if reloadFile != "" {
+			if err = reload.LoadEnvFile(reloadFile); err != nil {
+				return err
+			}
+		}
+		cfg = config.Load()
+
+		if level, err = reload.ParseLevel(cfg.LogLevel); err != nil {
+			return fmt.Errorf("invalid log level: %w", err)
+		}
+		app.Infrastructure.Get().LogLevel.Get().Set(level)
+		app.Infrastructure.Get().ConnectionPool.Get().SetRefreshInterval(time.Duration(cfg.Pool.RefreshInterval) * time.Second)
+		return nil
+	}
+}
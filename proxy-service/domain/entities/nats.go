@@ -3,6 +3,8 @@ package entities
 import (
 	"fmt"
 	"proxy-service/application/config"
+	"shared/grpc/clients/nats_service"
+	"shared/grpc/inprocess"
 	"sync"
 )
 
@@ -16,8 +18,9 @@ func GetNats() *Nats {
 	natsOnce.Do(func() {
 		cfg := config.GetConfig()
 		nats = &Nats{
-			RpcHost: cfg.Nats.RpcHost,
-			RpcPort: cfg.Nats.RpcPort,
+			RpcHost:      cfg.Nats.RpcHost,
+			RpcPort:      cfg.Nats.RpcPort,
+			RpcTransport: cfg.Nats.RpcTransport,
 		}
 	})
 	return nats
@@ -25,12 +28,16 @@ func GetNats() *Nats {
 
 // Nats represents NATS configuration details.
 type Nats struct {
-	RpcHost string // RpcHost is the address of the NATS gRPC server.
-	RpcPort string // RpcPort is the port number of the NATS gRPC server.
+	RpcHost      string // RpcHost is the address of the NATS gRPC server.
+	RpcPort      string // RpcPort is the port number of the NATS gRPC server.
+	RpcTransport string // RpcTransport is "tcp", or "inprocess" to reach a BusService co-located in the process.
 }
 
-// Address returns the full address of the NATS server.
+// Address returns the full address of the NATS server, or its in-process address.
 func (b *Nats) Address() (address string, err error) {
+	if b.RpcTransport == "inprocess" {
+		return inprocess.Address(nats_service.InProcessName), nil
+	}
 	if b.RpcHost == "" || b.RpcPort == "" {
 		return "", fmt.Errorf("invalid address: host or port is empty")
 	}
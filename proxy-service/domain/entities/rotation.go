@@ -0,0 +1,10 @@
+package entities
+
+// RotationCause describes why a proxy circuit rotation was requested.
+type RotationCause string
+
+const (
+	RotationCauseFailure      RotationCause = "failure"       // RotationCauseFailure is a rotation triggered by a failed request.
+	RotationCauseRequestCount RotationCause = "request_count" // RotationCauseRequestCount is a rotation triggered by a request budget.
+	RotationCauseTime         RotationCause = "time"          // RotationCauseTime is a rotation triggered by a schedule.
+)
@@ -0,0 +1,9 @@
+package interfaces
+
+import "proxy-service/domain/entities"
+
+// Rotator defines the contract for requesting a new proxy circuit.
+type Rotator interface {
+	// Rotate requests a new proxy circuit for the given cause.
+	Rotate(cause entities.RotationCause) (err error)
+}
@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
@@ -8,19 +9,29 @@ import (
 	"proxy-service/domain/interfaces"
 	"proxy-service/infrastructure/http/socks5"
 	"proxy-service/infrastructure/http/socks5/agent"
+	"proxy-service/infrastructure/metrics"
 	"proxy-service/infrastructure/proxy"
 	"shared/dependency"
+	"shared/logging"
+	"shared/reload"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Container provides a lazily initialized set of dependencies.
 type Container struct {
-	Logger         dependency.LazyDependency[*slog.Logger]
-	Config         dependency.LazyDependency[*config.Config]
-	PortConnection dependency.LazyDependency[*proxy.Connection]
-	UserAgent      dependency.LazyDependency[interfaces.Agent]
-	Socks5Client   dependency.LazyDependency[*socks5.Client]
-	ConnectionPool dependency.LazyDependency[*socks5.ConnectionPool]
+	Logger          dependency.LazyDependency[*slog.Logger]
+	LogLevel        dependency.LazyDependency[*slog.LevelVar]
+	Config          dependency.LazyDependency[*config.Config]
+	PortConnection  dependency.LazyDependency[*proxy.Connection]
+	UserAgent       dependency.LazyDependency[interfaces.Agent]
+	Socks5Client    dependency.LazyDependency[*socks5.Client]
+	ConnectionPool  dependency.LazyDependency[*socks5.ConnectionPool]
+	MetricsRegistry dependency.LazyDependency[*prometheus.Registry]
+	RotationMetrics dependency.LazyDependency[*metrics.RotationMetrics]
+	ConsumerMetrics dependency.LazyDependency[*metrics.ConsumerMetrics]
+	MetricsServer   dependency.LazyDependency[*metrics.Server]
 }
 
 // NewContainer initializes and returns a new Container with dependencies.
@@ -37,10 +48,29 @@ func NewContainer() *Container {
 				log.Fatalf("Failed to create log directory: %v", err)
 			}
 			file, err = os.OpenFile(interfaces.LogFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			options := logging.HandlerOptions{
+				Format:    c.Config.Get().LogFormat,
+				AddSource: c.Config.Get().LogAddSource,
+				Level:     c.LogLevel.Get(),
+			}
 			if err != nil {
-				return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+				return slog.New(logging.NewHandler(os.Stdout, options))
+			}
+			return slog.New(logging.NewHandler(file, options))
+		},
+	}
+	c.LogLevel = dependency.LazyDependency[*slog.LevelVar]{
+		InitFunc: func() *slog.LevelVar {
+			var (
+				levelVar = &slog.LevelVar{}
+				level    slog.Level
+				err      error
+			)
+			if level, err = reload.ParseLevel(c.Config.Get().LogLevel); err != nil {
+				panic(fmt.Sprintf("invalid log level: %v", err))
 			}
-			return slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{}))
+			levelVar.Set(level)
+			return levelVar
 		},
 	}
 	c.Config = dependency.LazyDependency[*config.Config]{
@@ -56,7 +86,7 @@ func NewContainer() *Container {
 			var (
 				logger    = c.Logger.Get()
 				userAgent = c.UserAgent.Get()
-				timeout   = time.Duration(10) * time.Second
+				timeout   = time.Duration(c.Config.Get().Pool.ClientTimeout) * time.Second
 			)
 			return socks5.NewClient(userAgent, timeout, logger)
 		},
@@ -77,8 +107,57 @@ func NewContainer() *Container {
 				poolSize        = c.Config.Get().Pool.MaxSize
 				refreshInterval = c.Config.Get().Pool.RefreshInterval
 				creator         = c.Socks5Client.Get().Create
+				overflow        = socks5.WithOverflowPolicy(
+					socks5.OverflowPolicy(c.Config.Get().Pool.OverflowPolicy),
+					c.Config.Get().Pool.OverflowCap)
+				prewarm = socks5.WithPrewarm(c.Config.Get().Pool.Parallelism, c.Config.Get().Pool.CreateAttempts)
+			)
+			pool := socks5.NewConnectionPool(poolSize, time.Duration(refreshInterval)*time.Second, creator, logger,
+				overflow, prewarm)
+			if err := metrics.NewPoolMetrics("proxy_service", pool.Stats).Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return pool
+		},
+	}
+
+	c.MetricsRegistry = dependency.LazyDependency[*prometheus.Registry]{
+		InitFunc: prometheus.NewRegistry,
+	}
+	c.RotationMetrics = dependency.LazyDependency[*metrics.RotationMetrics]{
+		InitFunc: func() *metrics.RotationMetrics {
+			var (
+				namespace       = "proxy_service"
+				rotationMetrics = metrics.NewRotationMetrics(namespace)
+			)
+			if err := rotationMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return rotationMetrics
+		},
+	}
+	c.ConsumerMetrics = dependency.LazyDependency[*metrics.ConsumerMetrics]{
+		InitFunc: func() *metrics.ConsumerMetrics {
+			var (
+				namespace       = "proxy_service"
+				consumerMetrics = metrics.NewConsumerMetrics(namespace)
+			)
+			if err := consumerMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return consumerMetrics
+		},
+	}
+	c.MetricsServer = dependency.LazyDependency[*metrics.Server]{
+		InitFunc: func() *metrics.Server {
+			var (
+				logger   = c.Logger.Get()
+				port     = c.Config.Get().Metrics.ServerPort
+				registry = c.MetricsRegistry.Get()
 			)
-			return socks5.NewConnectionPool(poolSize, time.Duration(refreshInterval)*time.Second, creator, logger)
+			// Rotation metrics are registered eagerly, so they are exposed before the first rotation.
+			c.RotationMetrics.Get()
+			return metrics.NewServer(port, registry, logger)
 		},
 	}
 
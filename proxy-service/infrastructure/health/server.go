@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Server provides an HTTP server exposing the health endpoint for orchestration probes.
+type Server struct {
+	server *http.Server // server is the underlying HTTP server.
+	logger *slog.Logger // logger for structured logging.
+}
+
+// NewServer creates a new instance of Server serving handler on /health.
+// The write timeout leaves room for a status check through the proxy.
+func NewServer(port string, handler http.Handler, logger *slog.Logger) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/health", handler)
+
+	return &Server{
+		server: &http.Server{
+			Addr:              port,
+			Handler:           mux,
+			ReadHeaderTimeout: time.Duration(5) * time.Second,
+			WriteTimeout:      time.Duration(30) * time.Second,
+			IdleTimeout:       time.Duration(10) * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Start launches the HTTP health server.
+func (s *Server) Start() (err error) {
+	s.logger.Info("Starting health server", "address", s.server.Addr)
+	return s.server.ListenAndServe()
+}
+
+// Stop gracefully shuts down the HTTP health server.
+func (s *Server) Stop(ctx context.Context) (err error) {
+	s.logger.Info("Stopping health server", "address", s.server.Addr)
+	return s.server.Shutdown(ctx)
+}
@@ -1,26 +1,96 @@
 package socks5
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // CreatorFunc defines a function signature that returns a new *http.Client or an error.
 type CreatorFunc func() (client *http.Client, err error)
 
+// OverflowPolicy defines how Borrow behaves when all pooled clients are borrowed.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits until a client is returned to the pool.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowCreate creates transient clients beyond the pool size, up to the overflow cap,
+	// and waits once the cap is reached. Transient clients are closed on Return instead of being pooled.
+	OverflowCreate OverflowPolicy = "overflow"
+	// OverflowFail returns ErrPoolExhausted immediately.
+	OverflowFail OverflowPolicy = "fail"
+)
+
+// ErrPoolExhausted is returned by Borrow under the OverflowFail policy when no pooled client is available.
+var ErrPoolExhausted error = poolExhaustedError{}
+
+// poolExhaustedError is the type of ErrPoolExhausted.
+type poolExhaustedError struct{}
+
+// Error implements the error interface.
+func (poolExhaustedError) Error() string {
+	return "connection pool exhausted"
+}
+
+// Retryable implements retry.Classifier, a client may be returned to the pool in the meantime.
+func (poolExhaustedError) Retryable() bool {
+	return true
+}
+
+// PoolOption defines a functional option for configuring ConnectionPool.
+type PoolOption func(*ConnectionPool)
+
+// WithOverflowPolicy sets the behavior of Borrow on an exhausted pool.
+// The overflow cap is the maximum number of transient clients alive at once under OverflowCreate.
+// Unknown policies are ignored and the pool keeps blocking.
+func WithOverflowPolicy(policy OverflowPolicy, overflowCap int) PoolOption {
+	return func(cp *ConnectionPool) {
+		switch policy {
+		case OverflowBlock, OverflowCreate, OverflowFail:
+			cp.policy = policy
+			cp.overflowCap = max(overflowCap, 0)
+		default:
+			cp.logger.Warn("Unknown connection pool overflow policy, blocking instead", "policy", policy)
+		}
+	}
+}
+
+// WithPrewarm creates the initial clients of the pool with up to parallelism creations at once, instead of one
+// after another, each creation is attempted up to attempts times. The pool panics with the errors of every client
+// that could not be created. Non-positive values keep creating the clients sequentially with a single attempt.
+func WithPrewarm(parallelism, attempts int) PoolOption {
+	return func(cp *ConnectionPool) {
+		cp.prewarmParallelism = max(parallelism, 1)
+		cp.prewarmAttempts = max(attempts, 1)
+	}
+}
+
 // ConnectionPool manages a pool of HTTP clients configured to use a SOCKS5 proxy.
 type ConnectionPool struct {
-	pool          chan *http.Client // pool holds available HTTP clients.
-	mu            sync.Mutex        // mu protects concurrent access during refresh and shutdown.
-	maxPoolSize   int               // maxPoolSize is the maximum number of connections in the pool.
-	refreshTicker *time.Ticker      // refreshTicker triggers periodic refreshes of idle connections.
-	stopChan      chan struct{}     // stopChan signals the refresh goroutine to stop.
-	creator       CreatorFunc       // creator is a function that returns a new HTTP client.
-	shutdownOnce  sync.Once         // shutdownOnce ensures Shutdown is executed only once.
+	pool          chan *http.Client         // pool holds available HTTP clients.
+	mu            sync.Mutex                // mu protects concurrent access during refresh and shutdown.
+	maxPoolSize   int                       // maxPoolSize is the maximum number of connections in the pool.
+	refreshTicker *time.Ticker              // refreshTicker triggers periodic refreshes of idle connections.
+	refreshEvery  time.Duration             // refreshEvery is the current refresh interval, guarded by mu.
+	stopChan      chan struct{}             // stopChan signals the refresh goroutine to stop.
+	creator       CreatorFunc               // creator is a function that returns a new HTTP client.
+	shutdownOnce  sync.Once                 // shutdownOnce ensures Shutdown is executed only once.
+	policy        OverflowPolicy            // policy is the behavior of Borrow on an exhausted pool.
+	overflowCap   int                       // overflowCap is the maximum number of transient clients under OverflowCreate.
+	overflowMu    sync.Mutex                // overflowMu protects the transient clients.
+	overflow      map[*http.Client]struct{} // overflow holds the borrowed transient clients.
+	borrows       atomic.Uint64             // borrows is the cumulative number of borrowed clients.
+	returns       atomic.Uint64             // returns is the cumulative number of returned clients.
 	logger        *slog.Logger
+
+	prewarmParallelism int // prewarmParallelism is the max. number of initial clients created at once.
+	prewarmAttempts    int // prewarmAttempts is the number of attempts to create an initial client.
 }
 
 // NewConnectionPool creates a new instance of ConnectionPool.
@@ -29,13 +99,22 @@ func NewConnectionPool(
 	refreshInterval time.Duration,
 	creator CreatorFunc,
 	logger *slog.Logger,
+	opts ...PoolOption,
 ) *ConnectionPool {
 	pool := &ConnectionPool{
 		pool:        make(chan *http.Client, poolSize),
 		maxPoolSize: poolSize,
 		stopChan:    make(chan struct{}),
 		creator:     creator,
+		policy:      OverflowBlock,
+		overflow:    make(map[*http.Client]struct{}),
 		logger:      logger,
+
+		prewarmParallelism: 1,
+		prewarmAttempts:    1,
+	}
+	for _, opt := range opts {
+		opt(pool)
 	}
 
 	pool.initialize(refreshInterval)
@@ -44,28 +123,64 @@ func NewConnectionPool(
 
 // initialize creates initial connections and starts the periodic refresh routine.
 func (cp *ConnectionPool) initialize(refreshInterval time.Duration) {
-	var (
-		httpClient *http.Client
-		err        error
-	)
-
-	cp.logger.Info("Initializing connection pool", "maxPoolSize", cp.maxPoolSize)
+	cp.logger.Info("Initializing connection pool", "maxPoolSize", cp.maxPoolSize,
+		"parallelism", cp.prewarmParallelism)
 
-	for i := 0; i < cp.maxPoolSize; i++ {
-		if httpClient, err = cp.creator(); err != nil {
-			cp.logger.Error("Could not create HTTP client for connection pool", "error", err)
-			cp.logger.Warn("Panic due to failure in connection pool initialization")
-			panic(fmt.Sprintf("could not create HTTP client for connection pool: %v", err))
-		}
-		cp.pool <- httpClient
+	if err := cp.prewarm(); err != nil {
+		cp.logger.Error("Could not create HTTP clients for connection pool", "error", err)
+		cp.logger.Warn("Panic due to failure in connection pool initialization")
+		panic(fmt.Sprintf("could not create HTTP clients for connection pool: %v", err))
 	}
 
 	// Start the periodic refresh routine.
+	cp.refreshEvery = refreshInterval
 	cp.refreshTicker = time.NewTicker(refreshInterval)
 	go cp.startRefresh()
 	cp.logger.Info("Connection pool initialized and refresh routine started", "refreshInterval", refreshInterval)
 }
 
+// prewarm fills the pool with up to prewarmParallelism clients created at once.
+// It returns the joined errors of the clients that could not be created within prewarmAttempts attempts.
+func (cp *ConnectionPool) prewarm() error {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		errs  []error
+		slots = make(chan struct{}, cp.prewarmParallelism)
+	)
+
+	for i := range cp.maxPoolSize {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(index int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			var (
+				httpClient *http.Client
+				err        error
+			)
+			for attempt := 1; attempt <= cp.prewarmAttempts; attempt++ {
+				if httpClient, err = cp.creator(); err == nil {
+					cp.pool <- httpClient
+					return
+				}
+				cp.logger.Warn("Could not create HTTP client for connection pool", "client", index,
+					"attempt", attempt, "error", err)
+			}
+
+			mu.Lock()
+			errs = append(errs, fmt.Errorf("client %d: %w", index, err))
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
 // startRefresh periodically refreshes connections in the pool to ensure they remain healthy.
 func (cp *ConnectionPool) startRefresh() {
 	for {
@@ -113,18 +228,161 @@ func (cp *ConnectionPool) refreshConnections() {
 }
 
 // Borrow retrieves an available HTTP client from the pool.
-func (cp *ConnectionPool) Borrow() (client *http.Client) {
-	client = <-cp.pool
-	cp.logger.Debug("HTTP client borrowed from pool")
-	return client
+// When all pooled clients are borrowed, it behaves according to the overflow policy and may wait indefinitely,
+// callers that must not block forever use BorrowContext.
+func (cp *ConnectionPool) Borrow() (client *http.Client, err error) {
+	return cp.BorrowContext(context.Background())
+}
+
+// BorrowContext retrieves an available HTTP client from the pool like Borrow, but waits for a returned client
+// only until ctx is done, so that a caller with a deadline never queues behind the workload for longer.
+func (cp *ConnectionPool) BorrowContext(ctx context.Context) (client *http.Client, err error) {
+	select {
+	case client = <-cp.pool:
+		cp.borrows.Add(1)
+		cp.logger.Debug("HTTP client borrowed from pool")
+		return client, nil
+	default:
+	}
+
+	switch cp.policy {
+	case OverflowFail:
+		cp.logger.Warn("Connection pool exhausted", "maxPoolSize", cp.maxPoolSize)
+		return nil, ErrPoolExhausted
+	case OverflowCreate:
+		if client, err = cp.borrowOverflow(); err != nil || client != nil {
+			return client, err
+		}
+	}
+
+	select {
+	case client = <-cp.pool:
+		cp.borrows.Add(1)
+		cp.logger.Debug("HTTP client borrowed from pool")
+		return client, nil
+	case <-ctx.Done():
+		cp.logger.Warn("Gave up waiting for a pooled HTTP client", "maxPoolSize", cp.maxPoolSize, "error", ctx.Err())
+		return nil, fmt.Errorf("wait for pooled client: %w", ctx.Err())
+	}
+}
+
+// borrowOverflow creates a transient client unless the overflow cap is reached, in which case it returns nil.
+func (cp *ConnectionPool) borrowOverflow() (client *http.Client, err error) {
+	cp.overflowMu.Lock()
+	defer cp.overflowMu.Unlock()
+
+	if len(cp.overflow) >= cp.overflowCap {
+		return nil, nil
+	}
+	if client, err = cp.creator(); err != nil {
+		cp.logger.Error("Could not create overflow HTTP client", "error", err)
+		return nil, fmt.Errorf("create overflow client: %w", err)
+	}
+	cp.overflow[client] = struct{}{}
+	cp.borrows.Add(1)
+	cp.logger.Debug("Overflow HTTP client created", "overflow", len(cp.overflow), "overflowCap", cp.overflowCap)
+	return client, nil
 }
 
 // Return places an HTTP client back into the pool for reuse.
+// Transient overflow clients are closed instead.
 func (cp *ConnectionPool) Return(client *http.Client) {
+	if client == nil {
+		return
+	}
+	cp.returns.Add(1)
+
+	cp.overflowMu.Lock()
+	_, transient := cp.overflow[client]
+	delete(cp.overflow, client)
+	cp.overflowMu.Unlock()
+
+	if transient {
+		if transport, ok := client.Transport.(*http.Transport); ok {
+			transport.CloseIdleConnections()
+		}
+		cp.logger.Debug("Overflow HTTP client closed")
+		return
+	}
+
 	cp.pool <- client
 	cp.logger.Debug("HTTP client returned to pool")
 }
 
+// Size returns the number of pooled clients, transient overflow clients excluded.
+func (cp *ConnectionPool) Size() int {
+	return cp.maxPoolSize
+}
+
+// Idle returns the number of pooled clients available to Borrow.
+func (cp *ConnectionPool) Idle() int {
+	return len(cp.pool)
+}
+
+// OverflowCount returns the number of borrowed transient overflow clients.
+func (cp *ConnectionPool) OverflowCount() int {
+	cp.overflowMu.Lock()
+	defer cp.overflowMu.Unlock()
+	return len(cp.overflow)
+}
+
+// PoolStats is a point-in-time snapshot of the utilization of a ConnectionPool.
+type PoolStats struct {
+	MaxSize     int    // MaxSize is the number of pooled clients.
+	Idle        int    // Idle is the number of pooled clients available to Borrow.
+	InUse       int    // InUse is the number of borrowed clients, transient overflow clients included.
+	Overflow    int    // Overflow is the number of borrowed transient overflow clients.
+	BorrowCount uint64 // BorrowCount is the cumulative number of borrowed clients.
+	ReturnCount uint64 // ReturnCount is the cumulative number of returned clients.
+}
+
+// Stats returns a snapshot of the pool utilization.
+// The values are read without a common lock, so they may be slightly inconsistent under concurrent use.
+func (cp *ConnectionPool) Stats() PoolStats {
+	var (
+		borrows = cp.borrows.Load()
+		returns = cp.returns.Load()
+		inUse   int
+	)
+	if borrows > returns {
+		inUse = int(borrows - returns)
+	}
+	return PoolStats{
+		MaxSize:     cp.maxPoolSize,
+		Idle:        len(cp.pool),
+		InUse:       inUse,
+		Overflow:    cp.OverflowCount(),
+		BorrowCount: borrows,
+		ReturnCount: returns,
+	}
+}
+
+// RefreshInterval returns the current interval at which idle connections are refreshed.
+func (cp *ConnectionPool) RefreshInterval() time.Duration {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.refreshEvery
+}
+
+// SetRefreshInterval changes the refresh interval of a running pool, borrowed and idle connections are kept.
+// The next refresh happens one new interval from now. Non-positive intervals and calls after Shutdown are ignored.
+func (cp *ConnectionPool) SetRefreshInterval(refreshInterval time.Duration) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	select {
+	case <-cp.stopChan:
+		return
+	default:
+	}
+	if refreshInterval <= 0 || refreshInterval == cp.refreshEvery {
+		return
+	}
+	cp.refreshEvery = refreshInterval
+	cp.refreshTicker.Reset(refreshInterval)
+	cp.logger.Info("Connection pool refresh interval updated", "refreshInterval", refreshInterval)
+}
+
 // Shutdown gracefully stops the connection pool's refresh routine and cleans up resources.
 func (cp *ConnectionPool) Shutdown() {
 	cp.shutdownOnce.Do(func() {
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConsumerMetrics exposes Prometheus metrics describing the NATS consumers of the service.
+type ConsumerMetrics struct {
+	lag          *prometheus.HistogramVec // lag observes the publish to delivery delay of messages by subject.
+	staleDropped *prometheus.CounterVec   // staleDropped counts the messages dropped for exceeding the max. age.
+	skipped      *prometheus.CounterVec   // skipped counts the messages whose response content type is not allowed.
+}
+
+// NewConsumerMetrics creates a new instance of ConsumerMetrics.
+func NewConsumerMetrics(namespace string) *ConsumerMetrics {
+	return &ConsumerMetrics{
+		lag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "lag_seconds",
+			Help:      "Delay between the publish and the delivery of consumed messages by subject.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 16),
+		}, []string{"subject"}),
+		staleDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "stale_dropped_total",
+			Help:      "Total number of consumed messages dropped for exceeding the max. message age by subject.",
+		}, []string{"subject"}),
+		skipped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "content_type_skipped_total",
+			Help:      "Total number of consumed messages skipped for a disallowed response content type by subject.",
+		}, []string{"subject"}),
+	}
+}
+
+// Register registers the consumer metrics with the given registerer.
+func (m *ConsumerMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range []prometheus.Collector{m.lag, m.staleDropped, m.skipped} {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register consumer metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// ObserveLag records the delivery delay of a message consumed from the given subject.
+func (m *ConsumerMetrics) ObserveLag(subject string, lag time.Duration) {
+	m.lag.WithLabelValues(subject).Observe(lag.Seconds())
+}
+
+// IncStaleDropped records a message consumed from the given subject dropped for exceeding the max. age.
+func (m *ConsumerMetrics) IncStaleDropped(subject string) {
+	m.staleDropped.WithLabelValues(subject).Inc()
+}
+
+// IncContentTypeSkipped records a message consumed from the given subject skipped for its response content type.
+func (m *ConsumerMetrics) IncContentTypeSkipped(subject string) {
+	m.skipped.WithLabelValues(subject).Inc()
+}
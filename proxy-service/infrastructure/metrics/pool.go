@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"fmt"
+	"proxy-service/infrastructure/http/socks5"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolMetrics exposes Prometheus metrics describing the utilization of the connection pool.
+type PoolMetrics struct {
+	collectors []prometheus.Collector // collectors read the pool stats at scrape time.
+}
+
+// NewPoolMetrics creates a new instance of PoolMetrics reading the pool utilization from stats.
+func NewPoolMetrics(namespace string, stats func() socks5.PoolStats) *PoolMetrics {
+	gauge := func(name, help string, value func(s socks5.PoolStats) float64) prometheus.Collector {
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+	counter := func(name, help string, value func(s socks5.PoolStats) float64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pool",
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return value(stats()) })
+	}
+
+	return &PoolMetrics{collectors: []prometheus.Collector{
+		gauge("max_size", "Number of pooled HTTP clients.",
+			func(s socks5.PoolStats) float64 { return float64(s.MaxSize) }),
+		gauge("idle", "Number of pooled HTTP clients available to borrow.",
+			func(s socks5.PoolStats) float64 { return float64(s.Idle) }),
+		gauge("in_use", "Number of borrowed HTTP clients, transient overflow clients included.",
+			func(s socks5.PoolStats) float64 { return float64(s.InUse) }),
+		gauge("overflow", "Number of borrowed transient overflow HTTP clients.",
+			func(s socks5.PoolStats) float64 { return float64(s.Overflow) }),
+		counter("borrows_total", "Total number of HTTP clients borrowed from the pool.",
+			func(s socks5.PoolStats) float64 { return float64(s.BorrowCount) }),
+		counter("returns_total", "Total number of HTTP clients returned to the pool.",
+			func(s socks5.PoolStats) float64 { return float64(s.ReturnCount) }),
+	}}
+}
+
+// Register registers the pool metrics with the given registerer.
+func (m *PoolMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range m.collectors {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register pool metrics: %w", err)
+		}
+	}
+	return nil
+}
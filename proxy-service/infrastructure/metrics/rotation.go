@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"fmt"
+	"proxy-service/domain/entities"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RotationMetrics exposes Prometheus metrics describing proxy circuit rotations.
+type RotationMetrics struct {
+	rotations          *prometheus.CounterVec // rotations counts successful rotations by cause.
+	cooldownRejections prometheus.Counter     // cooldownRejections counts rotations rejected during the cooldown.
+	sinceLastRotation  prometheus.GaugeFunc   // sinceLastRotation reports the seconds elapsed since the last rotation.
+	lastRotation       atomic.Int64           // lastRotation is the unix nano timestamp of the last rotation.
+}
+
+// NewRotationMetrics creates a new instance of RotationMetrics.
+func NewRotationMetrics(namespace string) *RotationMetrics {
+	m := &RotationMetrics{}
+
+	m.rotations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rotation",
+		Name:      "total",
+		Help:      "Total number of proxy circuit rotations by cause.",
+	}, []string{"cause"})
+	m.cooldownRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "rotation",
+		Name:      "cooldown_rejections_total",
+		Help:      "Total number of proxy circuit rotations rejected during the cooldown.",
+	})
+	m.sinceLastRotation = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "rotation",
+		Name:      "seconds_since_last",
+		Help:      "Seconds elapsed since the last proxy circuit rotation (0 if none happened yet).",
+	}, m.secondsSinceLastRotation)
+
+	// Pre-initialize the known causes so they are exported with a zero value.
+	for _, cause := range []entities.RotationCause{
+		entities.RotationCauseFailure,
+		entities.RotationCauseRequestCount,
+		entities.RotationCauseTime,
+	} {
+		m.rotations.WithLabelValues(string(cause))
+	}
+
+	return m
+}
+
+// Register registers the rotation metrics with the given registerer.
+func (m *RotationMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range []prometheus.Collector{m.rotations, m.cooldownRejections, m.sinceLastRotation} {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register rotation metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// ObserveRotation records a successful rotation triggered by the given cause.
+func (m *RotationMetrics) ObserveRotation(cause entities.RotationCause) {
+	m.rotations.WithLabelValues(string(cause)).Inc()
+	m.lastRotation.Store(time.Now().UnixNano())
+}
+
+// ObserveCooldownRejection records a rotation rejected because of the cooldown.
+func (m *RotationMetrics) ObserveCooldownRejection() {
+	m.cooldownRejections.Inc()
+}
+
+// secondsSinceLastRotation returns the seconds elapsed since the last rotation.
+func (m *RotationMetrics) secondsSinceLastRotation() float64 {
+	last := m.lastRotation.Load()
+	if last == 0 {
+		return 0
+	}
+	return time.Since(time.Unix(0, last)).Seconds()
+}
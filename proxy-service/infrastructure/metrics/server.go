@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server provides an HTTP server exposing Prometheus metrics.
+type Server struct {
+	server *http.Server // server is the underlying HTTP server.
+	logger *slog.Logger // logger for structured logging.
+}
+
+// NewServer creates a new instance of Server exposing the metrics of the given registry.
+func NewServer(port string, registry *prometheus.Registry, logger *slog.Logger) *Server {
+	mux := http.NewServeMux()
+
+	// Prometheus metrics endpoint
+	mux.Handle("/proxy-service/metrics", promhttp.HandlerFor(
+		registry,
+		promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+
+	// Health check endpoint
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write([]byte("OK")); err != nil {
+			return
+		}
+	})
+
+	return &Server{
+		server: &http.Server{
+			Addr:              port,
+			Handler:           mux,
+			ReadHeaderTimeout: time.Duration(5) * time.Second,
+			WriteTimeout:      time.Duration(5) * time.Second,
+			IdleTimeout:       time.Duration(10) * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Start launches the HTTP metrics server.
+func (s *Server) Start() (err error) {
+	s.logger.Info("Starting metrics server", "address", s.server.Addr)
+	return s.server.ListenAndServe()
+}
+
+// Stop gracefully shuts down the HTTP metrics server.
+func (s *Server) Stop(ctx context.Context) (err error) {
+	s.logger.Info("Stopping metrics server", "address", s.server.Addr)
+	return s.server.Shutdown(ctx)
+}
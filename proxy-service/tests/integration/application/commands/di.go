@@ -2,6 +2,8 @@ package commands
 
 import (
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"proxy-service/application/commands"
 	"proxy-service/application/config"
@@ -20,6 +22,10 @@ type TestContainer struct {
 	Socks5Client   dependency.LazyDependency[*socks5.Client]
 	ConnectionPool dependency.LazyDependency[*socks5.ConnectionPool]
 	StatusCommand  dependency.LazyDependency[*commands.StatusCommand]
+
+	SlowServer        dependency.LazyDependency[*httptest.Server]
+	LocalPool         dependency.LazyDependency[*socks5.ConnectionPool]
+	SlowStatusCommand dependency.LazyDependency[*commands.StatusCommand]
 }
 
 // NewTestContainer initializes a new test container.
@@ -72,5 +78,33 @@ func NewTestContainer() *TestContainer {
 		},
 	}
 
+	c.SlowServer = dependency.LazyDependency[*httptest.Server]{
+		InitFunc: func() *httptest.Server {
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+			}))
+		},
+	}
+	c.LocalPool = dependency.LazyDependency[*socks5.ConnectionPool]{
+		InitFunc: func() *socks5.ConnectionPool {
+			creator := func() (*http.Client, error) { return &http.Client{}, nil }
+			return socks5.NewConnectionPool(1, time.Minute, creator, c.Logger.Get())
+		},
+	}
+	c.SlowStatusCommand = dependency.LazyDependency[*commands.StatusCommand]{
+		InitFunc: func() *commands.StatusCommand {
+			var (
+				logger  = c.Logger.Get()
+				timeout = time.Duration(10) * time.Second
+				url     = c.SlowServer.Get().URL
+				pool    = c.LocalPool.Get()
+			)
+			return commands.NewStatusCommand(timeout, url, pool, logger)
+		},
+	}
+
 	return c
 }
@@ -1,9 +1,15 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/commands"
+	"proxy-service/infrastructure/http/socks5"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,7 +20,7 @@ func TestStatusCommand_ValidResponse(t *testing.T) {
 	container := SetupTestContainer()
 	statusCmd := container.StatusCommand.Get()
 
-	response, err := statusCmd.Execute()
+	response, err := statusCmd.Execute(context.Background())
 	require.NoError(t, err, "Expected no error when executing status command")
 	assert.NotEmpty(t, response, "Expected a non-empty response")
 }
@@ -45,7 +51,7 @@ func TestStatusCommand_CircuitRotation(t *testing.T) {
 				err      error
 			)
 
-			if response, err = statusCmd.Execute(); err != nil {
+			if response, err = statusCmd.Execute(context.Background()); err != nil {
 				errs <- err
 				return
 			}
@@ -78,3 +84,72 @@ func TestStatusCommand_CircuitRotation(t *testing.T) {
 	assert.Equal(t, numRequests, len(ipAddresses), "Expected each request to have a unique IP address")
 	t.Logf("Collected IP addresses: %v", list)
 }
+
+// TestStatusCommand_ContextDeadline verifies that Execute respects the deadline of the caller's context.
+func TestStatusCommand_ContextDeadline(t *testing.T) {
+	container := SetupTestContainer()
+	statusCmd := container.SlowStatusCommand.Get()
+	t.Cleanup(container.SlowServer.Get().Close)
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := statusCmd.Execute(ctx)
+	elapsed := time.Since(start)
+
+	require.Error(t, err, "Expected the status check to fail on the context deadline")
+	require.ErrorIs(t, err, context.DeadlineExceeded, "Expected a deadline exceeded error")
+	assert.Less(t, elapsed, time.Second, "Expected the status check to return at the context deadline")
+}
+
+// TestStatusCommand_BoundedBorrow verifies that status checks give up on a pool held by the workload after the
+// borrow timeout, and that concurrent status checks never hold more pooled clients than their concurrency.
+func TestStatusCommand_BoundedBorrow(t *testing.T) {
+	var (
+		container = SetupTestContainer()
+		logger    = container.Logger.Get()
+		server    = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Duration(200) * time.Millisecond)
+			_, _ = w.Write([]byte("ok"))
+		}))
+		creator = func() (*http.Client, error) { return &http.Client{}, nil }
+		pool    = socks5.NewConnectionPool(2, time.Minute, creator, logger)
+		status  = commands.NewStatusCommand(time.Duration(5)*time.Second, server.URL, pool, logger,
+			commands.WithStatusConcurrency(1), commands.WithBorrowTimeout(time.Duration(100)*time.Millisecond))
+	)
+	t.Cleanup(server.Close)
+	t.Cleanup(pool.Shutdown)
+
+	// The workload holds every pooled client, so the status check fails once the borrow timeout elapsed
+	first, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow the first client")
+	second, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow the second client")
+
+	start := time.Now()
+	_, err = status.Execute(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded, "Expected the borrow to time out")
+	assert.Less(t, time.Since(start), time.Second, "Expected the status check to give up at the borrow timeout")
+	pool.Return(first)
+	pool.Return(second)
+
+	// Concurrent status checks hold a single pooled client, the other one stays available for the workload
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = status.Execute(context.Background())
+		}()
+	}
+	time.Sleep(time.Duration(50) * time.Millisecond)
+	workload, err := pool.BorrowContext(context.Background())
+	require.NoError(t, err, "Expected a pooled client to remain available for the workload")
+	pool.Return(workload)
+	wg.Wait()
+
+	_, err = status.Execute(context.Background())
+	require.NoError(t, err, "Expected the status check to succeed once the pool is idle")
+}
@@ -2,17 +2,37 @@ package services
 
 import (
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"proxy-service/application/commands"
 	"proxy-service/application/services"
 	"proxy-service/domain/interfaces"
+	"proxy-service/infrastructure/http/socks5"
+	"proxy-service/infrastructure/metrics"
+	"shared/clock"
 	"shared/dependency"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // TestContainer holds dependencies for the integration tests.
 type TestContainer struct {
 	Logger        dependency.LazyDependency[*slog.Logger]
 	RetryStrategy dependency.LazyDependency[interfaces.RetryStrategy]
+
+	MetricsRegistry     dependency.LazyDependency[*prometheus.Registry]
+	RotationMetrics     dependency.LazyDependency[*metrics.RotationMetrics]
+	AuthenticateCommand dependency.LazyDependency[*MockCommand]
+	SignalCommand       dependency.LazyDependency[*MockCommand]
+	RotationCoordinator dependency.LazyDependency[*services.RotationCoordinator]
+	Clock               dependency.LazyDependency[*clock.Fake]
+
+	EchoServer    dependency.LazyDependency[*httptest.Server]
+	LocalPool     dependency.LazyDependency[*socks5.ConnectionPool]
+	StatusCommand dependency.LazyDependency[*commands.StatusCommand]
+	ProxySelfTest dependency.LazyDependency[*services.ProxySelfTest]
 }
 
 // NewTestContainer initializes a new test container.
@@ -37,5 +57,75 @@ func NewTestContainer() *TestContainer {
 		},
 	}
 
+	c.MetricsRegistry = dependency.LazyDependency[*prometheus.Registry]{
+		InitFunc: prometheus.NewRegistry,
+	}
+	c.RotationMetrics = dependency.LazyDependency[*metrics.RotationMetrics]{
+		InitFunc: func() *metrics.RotationMetrics {
+			rotationMetrics := metrics.NewRotationMetrics("proxy_service")
+			if err := rotationMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return rotationMetrics
+		},
+	}
+	c.Clock = dependency.LazyDependency[*clock.Fake]{
+		InitFunc: func() *clock.Fake { return clock.NewFake(time.Now()) },
+	}
+	c.AuthenticateCommand = dependency.LazyDependency[*MockCommand]{
+		InitFunc: func() *MockCommand { return &MockCommand{} },
+	}
+	c.SignalCommand = dependency.LazyDependency[*MockCommand]{
+		InitFunc: func() *MockCommand { return &MockCommand{} },
+	}
+	c.RotationCoordinator = dependency.LazyDependency[*services.RotationCoordinator]{
+		InitFunc: func() *services.RotationCoordinator {
+			var (
+				logger       = c.Logger.Get()
+				authenticate = c.AuthenticateCommand.Get()
+				signal       = c.SignalCommand.Get()
+				cooldown     = time.Duration(1) * time.Minute
+				metrics      = c.RotationMetrics.Get()
+			)
+			return services.NewRotationCoordinator(authenticate, signal, cooldown, metrics, c.Clock.Get(), logger)
+		},
+	}
+	c.EchoServer = dependency.LazyDependency[*httptest.Server]{
+		InitFunc: func() *httptest.Server {
+			// Every client reaches the server from the same address, as if the proxy were not in the path.
+			return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"origin": "203.0.113.7"}`))
+			}))
+		},
+	}
+	c.LocalPool = dependency.LazyDependency[*socks5.ConnectionPool]{
+		InitFunc: func() *socks5.ConnectionPool {
+			creator := func() (*http.Client, error) { return &http.Client{}, nil }
+			return socks5.NewConnectionPool(1, time.Minute, creator, c.Logger.Get())
+		},
+	}
+	c.StatusCommand = dependency.LazyDependency[*commands.StatusCommand]{
+		InitFunc: func() *commands.StatusCommand {
+			var (
+				logger  = c.Logger.Get()
+				timeout = time.Duration(5) * time.Second
+				url     = c.EchoServer.Get().URL
+				pool    = c.LocalPool.Get()
+			)
+			return commands.NewStatusCommand(timeout, url, pool, logger)
+		},
+	}
+	c.ProxySelfTest = dependency.LazyDependency[*services.ProxySelfTest]{
+		InitFunc: func() *services.ProxySelfTest {
+			var (
+				logger  = c.Logger.Get()
+				status  = c.StatusCommand.Get()
+				url     = c.EchoServer.Get().URL
+				timeout = time.Duration(5) * time.Second
+			)
+			return services.NewProxySelfTest(status, &http.Client{}, url, timeout, logger)
+		},
+	}
+
 	return c
 }
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/commands"
+	"proxy-service/application/services"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// serveHealth requests the health endpoint of service and decodes its report.
+func serveHealth(t *testing.T, service *services.HealthService) (status int, report services.HealthReport) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	service.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+	require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report), "Failed to decode health report")
+	return recorder.Code, report
+}
+
+// TestHealthService_Healthy verifies that a working proxy path with a closed breaker is reported as healthy,
+// along with the pool saturation and the last successful proxied request.
+func TestHealthService_Healthy(t *testing.T) {
+	var (
+		container   = SetupTestContainer()
+		lastSuccess = time.Now().Add(-time.Minute).Round(0)
+	)
+	t.Cleanup(container.EchoServer.Get().Close)
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+
+	service := services.NewHealthService(container.StatusCommand.Get(), container.LocalPool.Get(),
+		container.Logger.Get(), services.WithBreaker(func() bool { return false }),
+		services.WithLastSuccess(func() time.Time { return lastSuccess }))
+
+	status, report := serveHealth(t, service)
+	require.Equal(t, http.StatusOK, status, "Expected a healthy service")
+	require.True(t, report.Healthy)
+	require.Equal(t, services.BreakerClosed, report.Breaker)
+	require.Equal(t, 1, report.PoolSize)
+	require.Equal(t, 1, report.PoolIdle)
+	require.Zero(t, report.PoolSaturation)
+	require.NotNil(t, report.LastSuccess)
+	require.True(t, lastSuccess.Equal(*report.LastSuccess), "Expected the last successful request")
+	require.Contains(t, report.Status, "203.0.113.7", "Expected the status check response")
+	require.Empty(t, report.StatusError)
+}
+
+// TestHealthService_BreakerOpen verifies that an open breaker is reported as unhealthy without a status check.
+func TestHealthService_BreakerOpen(t *testing.T) {
+	container := SetupTestContainer()
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+
+	// The status check would fail, the pool holds no client
+	client, err := container.LocalPool.Get().Borrow()
+	require.NoError(t, err, "Failed to borrow the pooled client")
+	defer container.LocalPool.Get().Return(client)
+
+	service := services.NewHealthService(container.StatusCommand.Get(), container.LocalPool.Get(),
+		container.Logger.Get(), services.WithBreaker(func() bool { return true }))
+
+	status, report := serveHealth(t, service)
+	require.Equal(t, http.StatusServiceUnavailable, status, "Expected an unhealthy service")
+	require.False(t, report.Healthy)
+	require.Equal(t, services.BreakerOpen, report.Breaker)
+	require.Equal(t, float64(1), report.PoolSaturation, "Expected a saturated pool")
+	require.True(t, report.StatusAt.IsZero(), "Expected no status check behind an open breaker")
+	require.Nil(t, report.LastSuccess)
+}
+
+// TestHealthService_ProxyDown verifies that a failing status check is reported as unhealthy, and that its
+// result is reused within the status TTL.
+func TestHealthService_ProxyDown(t *testing.T) {
+	var (
+		container = SetupTestContainer()
+		down      = httptest.NewServer(http.NotFoundHandler())
+	)
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+	down.Close()
+
+	status := commands.NewStatusCommand(time.Second, down.URL, container.LocalPool.Get(), container.Logger.Get())
+	service := services.NewHealthService(status, container.LocalPool.Get(), container.Logger.Get(),
+		services.WithStatusTTL(time.Minute))
+
+	code, report := serveHealth(t, service)
+	require.Equal(t, http.StatusServiceUnavailable, code, "Expected an unhealthy service")
+	require.False(t, report.Healthy)
+	require.Equal(t, services.BreakerNone, report.Breaker)
+	require.NotEmpty(t, report.StatusError, "Expected the status check error")
+
+	_, cached := serveHealth(t, service)
+	require.True(t, report.StatusAt.Equal(cached.StatusAt), "Expected the status check result to be reused")
+}
+
+// TestHealthService_IntermittentProxy verifies that a single failed status check of a flapping proxy keeps the
+// service healthy, that sustained failures turn it unhealthy, and that it recovers after enough passed checks.
+func TestHealthService_IntermittentProxy(t *testing.T) {
+	var (
+		container = SetupTestContainer()
+		failing   atomic.Bool
+		proxy     = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing.Load() {
+				panic(http.ErrAbortHandler) // Drops the connection, as a flapping proxy does.
+			}
+			_, _ = w.Write([]byte(`{"origin": "203.0.113.7"}`))
+		}))
+	)
+	t.Cleanup(proxy.Close)
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+
+	// Every probe runs a status check.
+	status := commands.NewStatusCommand(time.Second, proxy.URL, container.LocalPool.Get(), container.Logger.Get())
+	service := services.NewHealthService(status, container.LocalPool.Get(), container.Logger.Get(),
+		services.WithStatusTTL(time.Nanosecond), services.WithFailureThreshold(3, time.Minute),
+		services.WithRecoveryThreshold(2))
+
+	probe := func(fail bool) (healthy bool, report services.HealthReport) {
+		failing.Store(fail)
+		code, report := serveHealth(t, service)
+		require.Equal(t, report.Healthy, code == http.StatusOK, "HTTP status should reflect the health")
+		return report.Healthy, report
+	}
+
+	healthy, _ := probe(false)
+	require.True(t, healthy, "Expected a healthy service")
+
+	healthy, report := probe(true)
+	require.True(t, healthy, "Expected a single failure to keep the service healthy")
+	require.NotEmpty(t, report.StatusError, "Expected the failed status check to be reported")
+	require.Equal(t, 1, report.Failures)
+
+	healthy, report = probe(false)
+	require.True(t, healthy, "Expected the service to stay healthy after the blip")
+	require.Zero(t, report.Failures, "Expected a passed status check to reset the failures")
+
+	for i := 1; i < 3; i++ {
+		healthy, _ = probe(true)
+		require.True(t, healthy, "Expected failure %d to stay within the threshold", i)
+	}
+	healthy, report = probe(true)
+	require.False(t, healthy, "Expected sustained failures to turn the service unhealthy")
+	require.Equal(t, 3, report.Failures)
+
+	healthy, _ = probe(false)
+	require.False(t, healthy, "Expected a single passed status check not to recover the service")
+	healthy, _ = probe(false)
+	require.True(t, healthy, "Expected the service to recover after consecutive passed status checks")
+}
+
+// TestHealthService_FailureWindow verifies that failed status checks older than the window do not count.
+func TestHealthService_FailureWindow(t *testing.T) {
+	var (
+		container = SetupTestContainer()
+		down      = httptest.NewServer(http.NotFoundHandler())
+	)
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+	down.Close()
+
+	status := commands.NewStatusCommand(time.Second, down.URL, container.LocalPool.Get(), container.Logger.Get())
+	service := services.NewHealthService(status, container.LocalPool.Get(), container.Logger.Get(),
+		services.WithStatusTTL(time.Nanosecond), services.WithFailureThreshold(2, time.Duration(50)*time.Millisecond))
+
+	_, report := serveHealth(t, service)
+	require.True(t, report.Healthy, "Expected the first failure to stay within the threshold")
+
+	time.Sleep(time.Duration(100) * time.Millisecond)
+	_, report = serveHealth(t, service)
+	require.True(t, report.Healthy, "Expected the failure outside the window not to count")
+	require.Equal(t, 1, report.Failures)
+
+	_, report = serveHealth(t, service)
+	require.False(t, report.Healthy, "Expected two failures within the window to turn the service unhealthy")
+}
@@ -0,0 +1,18 @@
+package services
+
+import "sync/atomic"
+
+// MockCommand is a mock implementation of interfaces.Command for testing.
+type MockCommand struct {
+	Err   error        // Err is the error returned by Execute.
+	calls atomic.Int32 // calls counts Execute invocations.
+}
+
+// Execute records the call and returns the configured error.
+func (m *MockCommand) Execute() (err error) {
+	m.calls.Add(1)
+	return m.Err
+}
+
+// Calls returns the number of Execute invocations.
+func (m *MockCommand) Calls() int { return int(m.calls.Load()) }
@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/services"
+	"proxy-service/infrastructure/http/socks5"
+	"shared/grpc/clients/nats_service/messaging"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUrlProcessorService_BorrowTimeout verifies that a fetch gives up waiting for a pooled client once the borrow
+// timeout expires, so that a request never pins its processing slot while the pool is exhausted.
+func TestUrlProcessorService_BorrowTimeout(t *testing.T) {
+	var (
+		container = NewTestContainer()
+		logger    = container.Logger.Get()
+		client    = NewMockNatsClient()
+		mu        sync.Mutex
+		fetched   []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(server.Close)
+
+	pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+		return server.Client(), nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	processor := services.NewUrlProcessorService(pool, client, 1, "", logger,
+		services.WithBorrowTimeout(time.Duration(50)*time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	// Hold the only pooled client, as a slow proxy would
+	borrowed, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow the pooled client")
+
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL+"/first"))
+	time.Sleep(time.Duration(200) * time.Millisecond)
+	pool.Return(borrowed)
+
+	// The first request gave up and freed its slot, so only the second one is fetched
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL+"/second"))
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 1 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Response not published")
+	require.Equal(t, []byte("/second"), client.Published(messaging.ProxyUrlResponse)[0])
+	mu.Lock()
+	require.Equal(t, []string{"/second"}, fetched, "Expected the first request to give up before fetching")
+	mu.Unlock()
+}
@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/services"
+	"proxy-service/infrastructure/http/socks5"
+	"proxy-service/infrastructure/metrics"
+	"shared/grpc/clients/nats_service/messaging"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUrlProcessorService_AllowedContentTypes verifies that a response whose content type is not allowed is
+// skipped and counted without a retry or a publish, while an allowed response is published.
+func TestUrlProcessorService_AllowedContentTypes(t *testing.T) {
+	var (
+		container       = NewTestContainer()
+		logger          = container.Logger.Get()
+		client          = NewMockNatsClient()
+		registry        = prometheus.NewRegistry()
+		consumerMetrics = metrics.NewConsumerMetrics("proxy_service")
+		strategy        = services.NewExponentialBackoffStrategy(
+			time.Millisecond, time.Duration(5)*time.Millisecond, 5, 2.0, logger)
+		mu      sync.Mutex
+		fetched []string
+	)
+	require.NoError(t, consumerMetrics.Register(registry), "Failed to register consumer metrics")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte{0x89, 'P', 'N', 'G'})
+		default:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte("<html></html>"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+		return server.Client(), nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	processor := services.NewUrlProcessorService(pool, client, 1, "", logger,
+		services.WithConsumerMetrics(consumerMetrics), services.WithRetries(strategy, 5, time.Minute),
+		services.WithAllowedContentTypes("text/*", "application/json"))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	// The single processing slot handles the requests in order, the page is published after the image is skipped
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL+"/image.png"))
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL+"/page"))
+
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 1 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Response not published")
+	require.Equal(t, []byte("<html></html>"), client.Published(messaging.ProxyUrlResponse)[0])
+	mu.Lock()
+	require.Equal(t, []string{"/image.png", "/page"}, fetched, "Expected the image to be fetched once")
+	mu.Unlock()
+	require.Equal(t, float64(1), contentTypeSkipped(t, registry), "Expected the image to be counted as skipped")
+}
+
+// contentTypeSkipped returns the number of requests skipped for a disallowed response content type.
+func contentTypeSkipped(t *testing.T, registry *prometheus.Registry) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() == "proxy_service_consumer_content_type_skipped_total" && len(family.GetMetric()) > 0 {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
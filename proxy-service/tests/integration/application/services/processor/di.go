@@ -50,7 +50,7 @@ func NewTestContainer() *TestContainer {
 			var (
 				logger    = c.Logger.Get()
 				userAgent = c.UserAgent.Get()
-				timeout   = time.Duration(10) * time.Second
+				timeout   = time.Duration(c.Config.Get().Pool.ClientTimeout) * time.Second
 			)
 			return socks5.NewClient(userAgent, timeout, logger)
 		},
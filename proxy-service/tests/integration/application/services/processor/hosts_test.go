@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"proxy-service/application/services"
+	"proxy-service/infrastructure/http/socks5"
+	"shared/grpc/clients/nats_service/messaging"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUrlProcessorService_HostLimits verifies that requests to a host limited to one concurrent request
+// serialize, subdomains included, while requests to unlisted hosts run in parallel.
+func TestUrlProcessorService_HostLimits(t *testing.T) {
+	var (
+		container = NewTestContainer()
+		logger    = container.Logger.Get()
+		client    = NewMockNatsClient()
+		mu        sync.Mutex
+		active    = make(map[string]int)
+		highest   = make(map[string]int)
+		rules     = filepath.Join(t.TempDir(), "hosts.json")
+	)
+	require.NoError(t, os.WriteFile(rules, []byte(`{"slow.test": 1}`), 0o600), "Failed to write host rules")
+
+	// Track the concurrent requests by rule, the subdomain shares the limit of its parent domain.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		if host == "api.slow.test" {
+			host = "slow.test"
+		}
+		mu.Lock()
+		active[host]++
+		highest[host] = max(highest[host], active[host])
+		mu.Unlock()
+
+		time.Sleep(time.Duration(100) * time.Millisecond)
+
+		mu.Lock()
+		active[host]--
+		mu.Unlock()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	// Every host resolves to the test server.
+	dialer := &net.Dialer{}
+	pool := socks5.NewConnectionPool(8, time.Hour, func() (*http.Client, error) {
+		return &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+			},
+		}}, nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	limiter, err := services.LoadHostLimiter(rules)
+	require.NoError(t, err, "Failed to load host rules")
+	processor := services.NewUrlProcessorService(pool, client, 8, "", logger, services.WithHostLimits(limiter))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	for _, host := range []string{"slow.test", "api.slow.test", "slow.test", "fast.test", "fast.test", "fast.test"} {
+		client.Deliver(messaging.ProxyUrlRequest, []byte("http://"+net.JoinHostPort(host, port)+"/"))
+	}
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 6 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Not all URLs were processed")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 1, highest["slow.test"], "Requests to the limited host did not serialize")
+	require.Greater(t, highest["fast.test"], 1, "Requests to the unlisted host did not run in parallel")
+}
+
+// TestLoadHostLimiter_InvalidLimit verifies that rules with a non-positive limit are rejected.
+func TestLoadHostLimiter_InvalidLimit(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "hosts.json")
+	require.NoError(t, os.WriteFile(rules, []byte(`{"slow.test": 0}`), 0o600), "Failed to write host rules")
+
+	_, err := services.LoadHostLimiter(rules)
+	require.Error(t, err, "Expected a non-positive limit to be rejected")
+}
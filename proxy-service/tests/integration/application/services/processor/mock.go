@@ -0,0 +1,120 @@
+package processor
+
+import (
+	"context"
+	"proxy-service/domain/entities"
+	"sync"
+)
+
+// MockNatsClient is an in-memory implementation of nats_service.Client for testing.
+type MockNatsClient struct {
+	mu         sync.Mutex                                   // mu guards the fields below.
+	published  map[string][][]byte                          // published holds the published messages by subject.
+	handlers   map[string]func(data []byte, subject string) // handlers holds the subscription handlers by subject.
+	failures   []error                                      // failures are returned by the next Subscribe calls.
+	subscribes int                                          // subscribes is the number of Subscribe calls.
+}
+
+// NewMockNatsClient creates a new instance of MockNatsClient.
+func NewMockNatsClient() *MockNatsClient {
+	return &MockNatsClient{
+		published: make(map[string][][]byte),
+		handlers:  make(map[string]func(data []byte, subject string)),
+	}
+}
+
+// Publish records the message under its subject.
+func (c *MockNatsClient) Publish(ctx context.Context, subject string, data []byte) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published[subject] = append(c.published[subject], append([]byte(nil), data...))
+	return nil
+}
+
+// FailSubscribes makes the next Subscribe calls return the errors in order.
+func (c *MockNatsClient) FailSubscribes(errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = append(c.failures, errs...)
+}
+
+// Subscribes returns the number of Subscribe calls.
+func (c *MockNatsClient) Subscribes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.subscribes
+}
+
+// Subscribe registers the handler of the subject and blocks until the context is canceled,
+// unless a failure is queued, which is returned right away.
+func (c *MockNatsClient) Subscribe(
+	ctx context.Context,
+	subject, queueGroup string,
+	handler func(data []byte, subject string),
+) (err error) {
+	c.mu.Lock()
+	c.subscribes++
+	if len(c.failures) > 0 {
+		err, c.failures = c.failures[0], c.failures[1:]
+		c.mu.Unlock()
+		return err
+	}
+	c.handlers[subject] = handler
+	c.mu.Unlock()
+
+	<-ctx.Done()
+
+	c.mu.Lock()
+	delete(c.handlers, subject)
+	c.mu.Unlock()
+	return nil
+}
+
+// Close is a no-op.
+func (c *MockNatsClient) Close() (err error) { return nil }
+
+// Subscribed reports whether a handler is registered for the subject.
+func (c *MockNatsClient) Subscribed(subject string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.handlers[subject]
+	return ok
+}
+
+// Deliver passes the message to the handler of the subject, as a subscription would.
+func (c *MockNatsClient) Deliver(subject string, data []byte) {
+	c.mu.Lock()
+	handler := c.handlers[subject]
+	c.mu.Unlock()
+	if handler != nil {
+		handler(data, subject)
+	}
+}
+
+// Published returns the messages published to the subject.
+func (c *MockNatsClient) Published(subject string) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.published[subject]...)
+}
+
+// MockRotator is an in-memory implementation of interfaces.Rotator recording the requested rotations.
+type MockRotator struct {
+	mu     sync.Mutex               // mu guards causes.
+	causes []entities.RotationCause // causes holds the causes of the requested rotations.
+}
+
+// Rotate records the cause of the rotation.
+func (r *MockRotator) Rotate(cause entities.RotationCause) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.causes = append(r.causes, cause)
+	return nil
+}
+
+// Causes returns the causes of the requested rotations.
+func (r *MockRotator) Causes() []entities.RotationCause {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entities.RotationCause(nil), r.causes...)
+}
@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/services"
+	"proxy-service/infrastructure/http/socks5"
+	"shared/grpc/clients/nats_service/messaging"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUrlProcessorService_FetchRequest verifies that JSON fetch requests are made with their method, headers and
+// body, while plain URLs are still fetched with a GET.
+func TestUrlProcessorService_FetchRequest(t *testing.T) {
+	type received struct {
+		method string
+		header string
+		body   string
+	}
+
+	var (
+		container = NewTestContainer()
+		logger    = container.Logger.Get()
+		client    = NewMockNatsClient()
+		mu        sync.Mutex
+		requests  []received
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, received{method: r.Method, header: r.Header.Get("X-Api-Key"), body: string(body)})
+		mu.Unlock()
+		_, _ = w.Write([]byte(r.Method))
+	}))
+	t.Cleanup(server.Close)
+
+	pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+		return server.Client(), nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	processor := services.NewUrlProcessorService(pool, client, 1, "", logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	request, err := json.Marshal(messaging.FetchRequest{
+		Method:  http.MethodPost,
+		URL:     server.URL + "/api",
+		Headers: map[string]string{"X-Api-Key": "secret"},
+		Body:    `{"query":"pulse"}`,
+	})
+	require.NoError(t, err, "Failed to marshal fetch request")
+
+	// The single processing slot handles the requests in order.
+	client.Deliver(messaging.ProxyUrlRequest, request)
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL+"/page"))
+	client.Deliver(messaging.ProxyUrlRequest, []byte(`{"url":`))
+
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 2 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Responses not published")
+	require.Equal(t, [][]byte{[]byte(http.MethodPost), []byte(http.MethodGet)},
+		client.Published(messaging.ProxyUrlResponse))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []received{
+		{method: http.MethodPost, header: "secret", body: `{"query":"pulse"}`},
+		{method: http.MethodGet},
+	}, requests, "Expected the malformed request to be dropped")
+}
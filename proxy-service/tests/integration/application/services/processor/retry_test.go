@@ -0,0 +1,318 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"nats-service/tests/integration/harness"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/services"
+	"proxy-service/domain/entities"
+	"proxy-service/infrastructure/http/socks5"
+	"shared/grpc/clients/nats_service"
+	"shared/grpc/clients/nats_service/messaging"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestUrlProcessorService_RetryBudget verifies that the fetch and publish stages share the retry budget
+// of the message: once the fetch retries exhaust it, a failed publish fails fast instead of retrying,
+// while a larger budget lets the publish retry and passes the remaining budget on with the response.
+func TestUrlProcessorService_RetryBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  int
+		published bool
+		remaining int
+	}{
+		{name: "ExhaustedByFetch", attempts: 2, published: false},
+		{name: "LeftForPublish", attempts: 5, published: true, remaining: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				container = NewTestContainer()
+				logger    = container.Logger.Get()
+				bus       = harness.New(t)
+				hits      atomic.Int32
+				strategy  = services.NewExponentialBackoffStrategy(
+					time.Millisecond, time.Duration(5)*time.Millisecond, 5, 2.0, logger)
+				responses = make(chan []byte, 1)
+				ready     = make(chan struct{})
+			)
+
+			// The first two requests fail with a server error, the fetch stage retries twice.
+			// The third one waits until the publish failure is injected.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if hits.Add(1) <= 2 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				<-ready
+				_, _ = w.Write([]byte("ok"))
+			}))
+			t.Cleanup(server.Close)
+
+			pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+				return server.Client(), nil
+			}, logger)
+			t.Cleanup(pool.Shutdown)
+
+			clients := make([]*nats_service.NatsClient, 2)
+			for i := range clients {
+				client, err := nats_service.NewNatsClient("dev", bus.Address, container.NatsGrpcValidator.Get(), logger)
+				require.NoError(t, err, "Failed to create in-process NATS client")
+				t.Cleanup(func() { _ = client.Close() })
+				clients[i] = client
+			}
+			processor := services.NewUrlProcessorService(pool, clients[0], 1, "", logger,
+				services.WithRetries(strategy, 0, 0))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go func() { _ = processor.Start(ctx) }()
+			go func() {
+				_ = clients[1].Subscribe(ctx, messaging.ProxyUrlResponse, "", func(data []byte, subject string) {
+					responses <- append([]byte(nil), data...)
+				})
+			}()
+			require.Eventually(t, func() bool {
+				return bus.Operations.Subscribers(messaging.ProxyUrlRequest) == 1 &&
+					bus.Operations.Subscribers(messaging.ProxyUrlResponse) == 1
+			}, time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscribers not ready")
+
+			request, err := messaging.Encode(messaging.Envelope{
+				Payload: []byte(server.URL),
+				Retry:   messaging.NewRetryBudget(test.attempts, time.Minute, time.Now()),
+			})
+			require.NoError(t, err, "Failed to encode request")
+			require.NoError(t, clients[1].Publish(ctx, messaging.ProxyUrlRequest, request), "Failed to publish request")
+			require.Eventually(t, func() bool { return hits.Load() == 3 },
+				time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Fetch stage not retried")
+
+			// The first publish of the response fails, only a retry can deliver it.
+			bus.Operations.FailPublishes(1)
+			close(ready)
+			if !test.published {
+				select {
+				case <-responses:
+					t.Fatal("Publish retried with an exhausted retry budget")
+				case <-time.After(time.Duration(300) * time.Millisecond):
+				}
+				return
+			}
+
+			select {
+			case data := <-responses:
+				envelope, decodeErr := messaging.Decode(data)
+				require.NoError(t, decodeErr, "Response not enveloped")
+				require.Equal(t, []byte("ok"), envelope.Payload)
+				require.NotNil(t, envelope.Retry, "Retry budget not passed on")
+				require.Equal(t, test.remaining, envelope.Retry.Attempts)
+			case <-time.After(time.Duration(5) * time.Second):
+				t.Fatal("Response not published")
+			}
+		})
+	}
+}
+
+// TestUrlProcessorService_RetryAfter verifies that a fetch rate limited with 429 and a Retry-After header
+// is retried after the indicated delay rather than the backoff of the strategy, capped at the configured max.
+func TestUrlProcessorService_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		maxDelay   time.Duration
+		minWait    time.Duration
+		maxWait    time.Duration
+	}{
+		{name: "Honored", retryAfter: "1", maxDelay: time.Minute, minWait: time.Second,
+			maxWait: time.Duration(3) * time.Second},
+		{name: "Capped", retryAfter: "3600", maxDelay: time.Duration(200) * time.Millisecond,
+			minWait: time.Duration(200) * time.Millisecond, maxWait: time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				container = NewTestContainer()
+				logger    = container.Logger.Get()
+				client    = NewMockNatsClient()
+				strategy  = services.NewExponentialBackoffStrategy(
+					time.Millisecond, time.Duration(5)*time.Millisecond, 5, 2.0, logger)
+				hits     = make(chan time.Time, 2)
+				rejected atomic.Bool
+			)
+
+			// The first request is rate limited, the retry succeeds.
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				hits <- time.Now()
+				if rejected.CompareAndSwap(false, true) {
+					w.Header().Set("Retry-After", test.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				_, _ = w.Write([]byte("ok"))
+			}))
+			t.Cleanup(server.Close)
+
+			pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+				return server.Client(), nil
+			}, logger)
+			t.Cleanup(pool.Shutdown)
+
+			processor := services.NewUrlProcessorService(pool, client, 1, "", logger,
+				services.WithRetries(strategy, 5, time.Minute), services.WithMaxRetryAfter(test.maxDelay))
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go func() { _ = processor.Start(ctx) }()
+			require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+				time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+			client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL))
+			require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 1 },
+				time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Response not published")
+
+			first, retried := <-hits, <-hits
+			wait := retried.Sub(first)
+			require.GreaterOrEqual(t, wait, test.minWait, "Retry not delayed by Retry-After")
+			require.Less(t, wait, test.maxWait, "Retry delayed beyond the expected wait")
+			require.Equal(t, []byte("ok"), client.Published(messaging.ProxyUrlResponse)[0],
+				"Expected the rate limited response not to be published")
+		})
+	}
+}
+
+// TestUrlProcessorService_MockNatsClient verifies that the processor consumes requests and publishes
+// responses through any nats_service.Client implementation.
+func TestUrlProcessorService_MockNatsClient(t *testing.T) {
+	var (
+		container = NewTestContainer()
+		logger    = container.Logger.Get()
+		client    = NewMockNatsClient()
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+		return server.Client(), nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	processor := services.NewUrlProcessorService(pool, client, 1, "", logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL))
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 1 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Response not published")
+
+	envelope, err := messaging.Open(client.Published(messaging.ProxyUrlResponse)[0])
+	require.NoError(t, err, "Invalid response")
+	require.Equal(t, []byte("ok"), envelope.Payload)
+}
+
+// TestUrlProcessorService_RetriesExhausted verifies that a fetch failing on every attempt is retried with a fresh
+// borrowed client, and that a FetchError is published to the ProxyUrlError subject instead of a response.
+func TestUrlProcessorService_RetriesExhausted(t *testing.T) {
+	var (
+		container = NewTestContainer()
+		logger    = container.Logger.Get()
+		client    = NewMockNatsClient()
+		hits      atomic.Int32
+		strategy  = services.NewExponentialBackoffStrategy(
+			time.Millisecond, time.Duration(5)*time.Millisecond, 5, 2.0, logger)
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+		return server.Client(), nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	processor := services.NewUrlProcessorService(pool, client, 1, "", logger,
+		services.WithRetries(strategy, 2, time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL))
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlError)) == 1 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Error not published")
+	require.Empty(t, client.Published(messaging.ProxyUrlResponse), "Expected no response for a failed fetch")
+	require.Equal(t, int32(3), hits.Load(), "Expected the fetch and two retries")
+	require.Equal(t, uint64(3), pool.Stats().BorrowCount, "Expected a client borrowed per attempt")
+
+	envelope, err := messaging.Decode(client.Published(messaging.ProxyUrlError)[0])
+	require.NoError(t, err, "Error not enveloped")
+	require.Equal(t, messaging.FormatJSON, envelope.Format)
+
+	var fetchErr messaging.FetchError
+	require.NoError(t, json.Unmarshal(envelope.Payload, &fetchErr), "Invalid error payload")
+	require.Equal(t, http.MethodGet, fetchErr.Method)
+	require.Equal(t, server.URL, fetchErr.URL)
+	require.Contains(t, fetchErr.Error, "502")
+}
+
+// TestUrlProcessorService_RotateOnFailure verifies that a fetch given up on with a transient failure requests
+// a new circuit, and that a final failure does not.
+func TestUrlProcessorService_RotateOnFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		causes []entities.RotationCause
+	}{
+		{name: "Transient", status: http.StatusBadGateway, causes: []entities.RotationCause{entities.RotationCauseFailure}},
+		{name: "Final", status: http.StatusNotImplemented},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				container = NewTestContainer()
+				logger    = container.Logger.Get()
+				client    = NewMockNatsClient()
+				rotator   = &MockRotator{}
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+			}))
+			t.Cleanup(server.Close)
+
+			pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+				return server.Client(), nil
+			}, logger)
+			t.Cleanup(pool.Shutdown)
+
+			processor := services.NewUrlProcessorService(pool, client, 1, "", logger, services.WithRotator(rotator))
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go func() { _ = processor.Start(ctx) }()
+			require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+				time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+			client.Deliver(messaging.ProxyUrlRequest, []byte(server.URL))
+			require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlError)) == 1 },
+				time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Error not published")
+			require.Equal(t, test.causes, rotator.Causes(), "Unexpected rotations")
+		})
+	}
+}
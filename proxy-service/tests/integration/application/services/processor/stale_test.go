@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"proxy-service/application/services"
+	"proxy-service/infrastructure/http/socks5"
+	"proxy-service/infrastructure/metrics"
+	"shared/grpc/clients/nats_service/messaging"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUrlProcessorService_MaxMessageAge verifies that requests published longer ago than the max. message age
+// are dropped and counted without being fetched, while fresh requests are fetched and answered.
+func TestUrlProcessorService_MaxMessageAge(t *testing.T) {
+	var (
+		container       = NewTestContainer()
+		logger          = container.Logger.Get()
+		client          = NewMockNatsClient()
+		registry        = prometheus.NewRegistry()
+		consumerMetrics = metrics.NewConsumerMetrics("proxy_service")
+		mu              sync.Mutex
+		fetched         []string
+	)
+	require.NoError(t, consumerMetrics.Register(registry), "Failed to register consumer metrics")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	pool := socks5.NewConnectionPool(1, time.Hour, func() (*http.Client, error) {
+		return server.Client(), nil
+	}, logger)
+	t.Cleanup(pool.Shutdown)
+
+	processor := services.NewUrlProcessorService(pool, client, 1, "", logger,
+		services.WithConsumerMetrics(consumerMetrics), services.WithMaxMessageAge(time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() { _ = processor.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Processor did not subscribe")
+
+	for path, publishedAt := range map[string]time.Time{
+		"/stale": time.Now().Add(-time.Hour),
+		"/fresh": time.Now(),
+	} {
+		request, err := messaging.Encode(messaging.Envelope{Payload: []byte(server.URL + path), PublishedAt: publishedAt})
+		require.NoError(t, err, "Failed to encode request")
+		client.Deliver(messaging.ProxyUrlRequest, request)
+	}
+
+	require.Eventually(t, func() bool { return len(client.Published(messaging.ProxyUrlResponse)) == 1 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Response not published")
+	mu.Lock()
+	require.Equal(t, []string{"/fresh"}, fetched, "Only the fresh request should be fetched")
+	mu.Unlock()
+	require.Equal(t, float64(1), staleDropped(t, registry), "Expected the stale request to be counted")
+}
+
+// staleDropped returns the number of requests dropped for exceeding the max. message age.
+func staleDropped(t *testing.T, registry *prometheus.Registry) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() == "proxy_service_consumer_stale_dropped_total" && len(family.GetMetric()) > 0 {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"proxy-service/application/services"
+	"shared/grpc/clients/nats_service/messaging"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestUrlProcessorService_Resubscribe verifies that transient subscription failures are retried
+// and the service keeps consuming, while fatal ones stop it and repeated transient ones make it give up.
+func TestUrlProcessorService_Resubscribe(t *testing.T) {
+	var (
+		unavailable = status.Error(codes.Unavailable, "nats-service restarting")
+		denied      = status.Error(codes.PermissionDenied, "subject not allowed")
+	)
+	tests := []struct {
+		name        string
+		failures    []error
+		maxAttempts int
+		subscribes  int
+		running     bool
+		expected    error
+	}{
+		{name: "Transient", failures: []error{unavailable, unavailable}, subscribes: 3, running: true},
+		{name: "StreamClosed", failures: []error{nil}, subscribes: 2, running: true},
+		{name: "Fatal", failures: []error{unavailable, denied}, subscribes: 2, expected: denied},
+		{name: "GiveUp", failures: []error{unavailable, unavailable, unavailable}, maxAttempts: 2, subscribes: 3,
+			expected: services.ErrSubscribeGaveUp},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				container = NewTestContainer()
+				logger    = container.Logger.Get()
+				client    = NewMockNatsClient()
+				strategy  = services.NewExponentialBackoffStrategy(
+					time.Millisecond, time.Duration(5)*time.Millisecond, 0, 2.0, logger)
+				processor = services.NewUrlProcessorService(nil, client, 1, "", logger,
+					services.WithResubscribe(strategy, test.maxAttempts))
+				result = make(chan error, 1)
+			)
+			client.FailSubscribes(test.failures...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+			go func() { result <- processor.Start(ctx) }()
+
+			if !test.running {
+				select {
+				case err := <-result:
+					require.ErrorIs(t, err, test.expected)
+				case <-time.After(time.Duration(2) * time.Second):
+					t.Fatal("Start did not return")
+				}
+				require.Equal(t, test.subscribes, client.Subscribes(), "Unexpected number of subscribes")
+				return
+			}
+
+			require.Eventually(t, func() bool { return client.Subscribed(messaging.ProxyUrlRequest) },
+				time.Duration(2)*time.Second, time.Duration(5)*time.Millisecond, "Service did not resubscribe")
+			require.Equal(t, test.subscribes, client.Subscribes(), "Unexpected number of subscribes")
+			select {
+			case err := <-result:
+				t.Fatalf("Start returned while subscribed: %v", err)
+			default:
+			}
+
+			cancel()
+			select {
+			case err := <-result:
+				require.True(t, err == nil || errors.Is(err, context.Canceled), "Unexpected error: %v", err)
+			case <-time.After(time.Duration(2) * time.Second):
+				t.Fatal("Start did not return after cancellation")
+			}
+		})
+	}
+}
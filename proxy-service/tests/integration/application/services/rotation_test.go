@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"proxy-service/application/services"
+	"proxy-service/domain/entities"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRotationCoordinator_FailureRotation verifies that a failure-triggered rotation increments the failure counter.
+func TestRotationCoordinator_FailureRotation(t *testing.T) {
+	container := SetupTestContainer()
+	coordinator := container.RotationCoordinator.Get()
+
+	err := coordinator.Rotate(entities.RotationCauseFailure)
+	require.NoError(t, err, "Rotation should succeed")
+
+	registry := container.MetricsRegistry.Get()
+	assert.Equal(t, 1.0, counterValue(t, registry, "proxy_service_rotation_total", "failure"))
+	assert.Equal(t, 0.0, counterValue(t, registry, "proxy_service_rotation_total", "request_count"))
+	assert.Equal(t, 0.0, counterValue(t, registry, "proxy_service_rotation_total", "time"))
+	assert.Equal(t, 1, container.SignalCommand.Get().Calls(), "Signal command should be executed once")
+}
+
+// TestRotationCoordinator_Cooldown verifies that rotations within the cooldown are rejected and counted.
+func TestRotationCoordinator_Cooldown(t *testing.T) {
+	container := SetupTestContainer()
+	coordinator := container.RotationCoordinator.Get()
+
+	require.NoError(t, coordinator.Rotate(entities.RotationCauseTime), "First rotation should succeed")
+
+	err := coordinator.Rotate(entities.RotationCauseRequestCount)
+	require.ErrorIs(t, err, services.ErrRotationCooldown, "Second rotation should be rejected")
+
+	registry := container.MetricsRegistry.Get()
+	assert.Equal(t, 1.0, counterValue(t, registry, "proxy_service_rotation_total", "time"))
+	assert.Equal(t, 0.0, counterValue(t, registry, "proxy_service_rotation_total", "request_count"))
+	assert.Equal(t, 1.0, counterValue(t, registry, "proxy_service_rotation_cooldown_rejections_total", ""))
+	assert.Equal(t, 1, container.SignalCommand.Get().Calls(), "Rejected rotation should not send a signal")
+}
+
+// TestRotationCoordinator_SignalFailure verifies that a failed signal is not counted as a rotation.
+func TestRotationCoordinator_SignalFailure(t *testing.T) {
+	container := SetupTestContainer()
+	container.SignalCommand.Get().Err = errors.New("signal failed")
+	coordinator := container.RotationCoordinator.Get()
+
+	err := coordinator.Rotate(entities.RotationCauseFailure)
+	require.Error(t, err, "Rotation should fail when the signal fails")
+
+	registry := container.MetricsRegistry.Get()
+	assert.Equal(t, 0.0, counterValue(t, registry, "proxy_service_rotation_total", "failure"))
+}
+
+// counterValue returns the value of a counter in the registry, optionally filtered by its "cause" label.
+func counterValue(t *testing.T, registry *prometheus.Registry, name, cause string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if cause == "" {
+				return metric.GetCounter().GetValue()
+			}
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "cause" && label.GetValue() == cause {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// TestRotationCoordinator_CooldownElapsed verifies that a rotation is allowed again once the cooldown has elapsed.
+func TestRotationCoordinator_CooldownElapsed(t *testing.T) {
+	container := SetupTestContainer()
+	coordinator := container.RotationCoordinator.Get()
+	fakeClock := container.Clock.Get()
+
+	require.NoError(t, coordinator.Rotate(entities.RotationCauseTime), "First rotation should succeed")
+
+	fakeClock.Advance(time.Duration(59) * time.Second)
+	err := coordinator.Rotate(entities.RotationCauseTime)
+	require.ErrorIs(t, err, services.ErrRotationCooldown, "Rotation within the cooldown should be rejected")
+
+	fakeClock.Advance(time.Second)
+	require.NoError(t, coordinator.Rotate(entities.RotationCauseTime), "Rotation after the cooldown should succeed")
+
+	registry := container.MetricsRegistry.Get()
+	assert.Equal(t, 2.0, counterValue(t, registry, "proxy_service_rotation_total", "time"))
+	assert.Equal(t, 2, container.SignalCommand.Get().Calls(), "Signal command should be executed twice")
+}
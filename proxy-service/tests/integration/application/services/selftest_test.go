@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"proxy-service/application/services"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestProxySelfTest_LocalExitIP verifies that the self-test flags a proxy whose exit IP equals the local IP.
+func TestProxySelfTest_LocalExitIP(t *testing.T) {
+	container := SetupTestContainer()
+	selfTest := container.ProxySelfTest.Get()
+	t.Cleanup(container.EchoServer.Get().Close)
+	t.Cleanup(container.LocalPool.Get().Shutdown)
+
+	err := selfTest.Run(context.Background())
+	require.ErrorIs(t, err, services.ErrProxyNotProxying, "Expected the self-test to flag a non-proxying setup")
+}
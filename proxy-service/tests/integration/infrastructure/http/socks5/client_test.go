@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,3 +118,15 @@ func TestClient_CircuitRotation(t *testing.T) {
 	// Debug output: print collected IP addresses
 	t.Logf("Collected IP addresses: %v", collectedIPs)
 }
+
+// TestClient_Timeout verifies that the created HTTP client uses the configured per-request timeout.
+func TestClient_Timeout(t *testing.T) {
+	container := SetupTestContainer()
+	client := container.Socks5Client.Get()
+	expected := time.Duration(container.Config.Get().Pool.ClientTimeout) * time.Second
+
+	httpClient, err := client.Create()
+	require.NoError(t, err, "Failed to create HTTP client")
+	require.Positive(t, expected, "Expected a positive configured client timeout")
+	assert.Equal(t, expected, httpClient.Timeout, "Expected the configured client timeout")
+}
@@ -2,6 +2,7 @@ package socks5
 
 import (
 	"log/slog"
+	"net/http"
 	"os"
 	"proxy-service/application/config"
 	"proxy-service/domain/interfaces"
@@ -18,6 +19,11 @@ type TestContainer struct {
 	UserAgent      dependency.LazyDependency[interfaces.Agent]
 	Socks5Client   dependency.LazyDependency[*socks5.Client]
 	ConnectionPool dependency.LazyDependency[*socks5.ConnectionPool]
+
+	StubCreator  dependency.LazyDependency[socks5.CreatorFunc]
+	BlockingPool dependency.LazyDependency[*socks5.ConnectionPool]
+	OverflowPool dependency.LazyDependency[*socks5.ConnectionPool]
+	FailingPool  dependency.LazyDependency[*socks5.ConnectionPool]
 }
 
 // NewTestContainer initializes a new test container.
@@ -42,7 +48,7 @@ func NewTestContainer() *TestContainer {
 			var (
 				logger    = c.Logger.Get()
 				userAgent = c.UserAgent.Get()
-				timeout   = time.Duration(10) * time.Second
+				timeout   = time.Duration(c.Config.Get().Pool.ClientTimeout) * time.Second
 			)
 			return socks5.NewClient(userAgent, timeout, logger)
 		},
@@ -59,5 +65,32 @@ func NewTestContainer() *TestContainer {
 		},
 	}
 
+	// Single-client pools with stub clients that never dial the proxy, used to exercise the overflow policies.
+	c.StubCreator = dependency.LazyDependency[socks5.CreatorFunc]{
+		InitFunc: func() socks5.CreatorFunc {
+			return func() (*http.Client, error) {
+				return &http.Client{Transport: &http.Transport{}}, nil
+			}
+		},
+	}
+	c.BlockingPool = dependency.LazyDependency[*socks5.ConnectionPool]{
+		InitFunc: func() *socks5.ConnectionPool {
+			return socks5.NewConnectionPool(1, time.Hour, c.StubCreator.Get(), c.Logger.Get(),
+				socks5.WithOverflowPolicy(socks5.OverflowBlock, 0))
+		},
+	}
+	c.OverflowPool = dependency.LazyDependency[*socks5.ConnectionPool]{
+		InitFunc: func() *socks5.ConnectionPool {
+			return socks5.NewConnectionPool(1, time.Hour, c.StubCreator.Get(), c.Logger.Get(),
+				socks5.WithOverflowPolicy(socks5.OverflowCreate, 1))
+		},
+	}
+	c.FailingPool = dependency.LazyDependency[*socks5.ConnectionPool]{
+		InitFunc: func() *socks5.ConnectionPool {
+			return socks5.NewConnectionPool(1, time.Hour, c.StubCreator.Get(), c.Logger.Get(),
+				socks5.WithOverflowPolicy(socks5.OverflowFail, 0))
+		},
+	}
+
 	return c
 }
@@ -0,0 +1,105 @@
+package socks5
+
+import (
+	"net/http"
+	"proxy-service/infrastructure/http/socks5"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConnectionPool_OverflowBlock verifies that Borrow waits on an exhausted pool until a client is returned.
+func TestConnectionPool_OverflowBlock(t *testing.T) {
+	container := SetupTestContainer()
+	pool := container.BlockingPool.Get()
+	defer pool.Shutdown()
+
+	client, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
+
+	borrowed := make(chan *http.Client, 1)
+	go func() {
+		waiting, _ := pool.Borrow()
+		borrowed <- waiting
+	}()
+
+	select {
+	case <-borrowed:
+		t.Fatal("Expected Borrow to block on an exhausted pool")
+	case <-time.After(time.Duration(100) * time.Millisecond):
+	}
+
+	pool.Return(client)
+	select {
+	case waiting := <-borrowed:
+		require.Same(t, client, waiting, "Expected the returned client to be borrowed")
+		pool.Return(waiting)
+	case <-time.After(time.Second):
+		t.Fatal("Expected Borrow to proceed once a client is returned")
+	}
+}
+
+// TestConnectionPool_OverflowCreate verifies that transient clients are created up to the overflow cap
+// and closed instead of pooled on Return.
+func TestConnectionPool_OverflowCreate(t *testing.T) {
+	container := SetupTestContainer()
+	pool := container.OverflowPool.Get()
+	defer pool.Shutdown()
+
+	pooled, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow pooled client")
+
+	transient, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow overflow client")
+	require.NotSame(t, pooled, transient, "Expected a transient client beyond the pool size")
+	require.Equal(t, 1, pool.OverflowCount())
+
+	// The overflow cap is reached, so the next Borrow waits for a pooled client.
+	borrowed := make(chan *http.Client, 1)
+	go func() {
+		waiting, _ := pool.Borrow()
+		borrowed <- waiting
+	}()
+	select {
+	case <-borrowed:
+		t.Fatal("Expected Borrow to block once the overflow cap is reached")
+	case <-time.After(time.Duration(100) * time.Millisecond):
+	}
+
+	// Returning the transient client closes it rather than pooling it, so the waiting Borrow stays blocked.
+	pool.Return(transient)
+	require.Zero(t, pool.OverflowCount(), "Expected the overflow client to be released")
+	select {
+	case <-borrowed:
+		t.Fatal("Expected the overflow client not to be pooled")
+	case <-time.After(time.Duration(100) * time.Millisecond):
+	}
+
+	pool.Return(pooled)
+	select {
+	case waiting := <-borrowed:
+		require.Same(t, pooled, waiting, "Expected the pooled client to be borrowed")
+		pool.Return(waiting)
+	case <-time.After(time.Second):
+		t.Fatal("Expected Borrow to proceed once the pooled client is returned")
+	}
+}
+
+// TestConnectionPool_OverflowFail verifies that Borrow fails immediately on an exhausted pool.
+func TestConnectionPool_OverflowFail(t *testing.T) {
+	container := SetupTestContainer()
+	pool := container.FailingPool.Get()
+	defer pool.Shutdown()
+
+	client, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
+
+	_, err = pool.Borrow()
+	require.ErrorIs(t, err, socks5.ErrPoolExhausted)
+
+	pool.Return(client)
+	client, err = pool.Borrow()
+	require.NoError(t, err, "Expected Borrow to succeed once the client is returned")
+	pool.Return(client)
+}
@@ -22,7 +22,8 @@ func TestConnectionPool_BorrowReturn(t *testing.T) {
 	pool := container.ConnectionPool.Get()
 
 	// Borrow a connection from the pool.
-	client := pool.Borrow()
+	client, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
 	require.NotNil(t, client, "Borrowed client should not be nil")
 
 	// Use the borrowed client to make a simple HTTP request.
@@ -73,7 +74,8 @@ func TestConnectionPool_ConcurrentBorrow(t *testing.T) {
 			}()
 
 			// Workload
-			client := pool.Borrow()
+			client, err := pool.Borrow()
+			require.NoError(t, err, "Failed to borrow client")
 			defer pool.Return(client)
 			require.NotNil(t, client, "Borrowed client should not be nil")
 
@@ -123,7 +125,8 @@ func TestConnectionPool_Shutdown(t *testing.T) {
 	pool := container.ConnectionPool.Get()
 
 	// Borrow one connection to simulate usage.
-	client := pool.Borrow()
+	client, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
 	require.NotNil(t, client, "Borrowed client should not be nil")
 
 	// Return the connection.
@@ -133,7 +136,8 @@ func TestConnectionPool_Shutdown(t *testing.T) {
 	pool.Shutdown()
 
 	// After shutdown, attempting to borrow should return nil since the channel is closed.
-	client = pool.Borrow()
+	client, err = pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
 	require.Nil(t, client, "Expected borrowed client to be nil after shutdown")
 
 	// Intentionally called to make sure that Shutdown is executed only once.
@@ -146,7 +150,8 @@ func TestConnectionPool_Refresh(t *testing.T) {
 	pool := container.ConnectionPool.Get()
 
 	// Borrow and return a connection to ensure it's part of the idle pool.
-	client := pool.Borrow()
+	client, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
 	require.NotNil(t, client, "Borrowed client should not be nil")
 	pool.Return(client)
 
@@ -158,7 +163,8 @@ func TestConnectionPool_Refresh(t *testing.T) {
 	time.Sleep(refreshDuration)
 
 	// Borrow a connection and make sure it still works.
-	refreshedClient := pool.Borrow()
+	refreshedClient, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow client")
 	require.NotNil(t, refreshedClient, "Borrowed client after refresh should not be nil")
 
 	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, urlGet, http.NoBody)
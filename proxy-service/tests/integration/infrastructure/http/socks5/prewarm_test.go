@@ -0,0 +1,94 @@
+package socks5
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"proxy-service/infrastructure/http/socks5"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConnectionPool_ParallelPrewarm verifies that the pool is filled with up to the configured parallelism of
+// clients created at once, and that transient creation failures are retried.
+func TestConnectionPool_ParallelPrewarm(t *testing.T) {
+	var (
+		logger   = SetupTestContainer().Logger.Get()
+		calls    atomic.Int64
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+	)
+
+	creator := func() (*http.Client, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(time.Duration(20) * time.Millisecond)
+		// Every third creation fails once, the retry succeeds
+		if calls.Add(1)%3 == 0 {
+			return nil, errors.New("circuit not established")
+		}
+		return &http.Client{}, nil
+	}
+
+	pool := socks5.NewConnectionPool(8, time.Hour, creator, logger, socks5.WithPrewarm(4, 3))
+	defer pool.Shutdown()
+
+	clients := make([]*http.Client, 0, 8)
+	for range 8 {
+		client, err := pool.BorrowContext(t.Context())
+		require.NoError(t, err, "Failed to borrow client")
+		clients = append(clients, client)
+	}
+	for _, client := range clients {
+		pool.Return(client)
+	}
+
+	require.Greater(t, peak, 1, "Expected clients to be created in parallel")
+	require.LessOrEqual(t, peak, 4, "Expected the parallelism to bound the concurrent creations")
+	require.Greater(t, calls.Load(), int64(8), "Expected failed creations to be retried")
+}
+
+// TestConnectionPool_PrewarmErrors verifies that the pool panics with the errors of every client that could not be
+// created within the attempts, while the other clients are still created.
+func TestConnectionPool_PrewarmErrors(t *testing.T) {
+	var (
+		logger = SetupTestContainer().Logger.Get()
+		calls  atomic.Int64
+	)
+
+	creator := func() (*http.Client, error) {
+		// Odd creations fail
+		if n := calls.Add(1); n%2 == 1 {
+			return nil, fmt.Errorf("creation %d failed", n)
+		}
+		return &http.Client{}, nil
+	}
+
+	defer func() {
+		recovered := recover()
+		require.NotNil(t, recovered, "Expected the pool initialization to panic")
+		message := fmt.Sprint(recovered)
+		require.Contains(t, message, "could not create HTTP clients for connection pool")
+		require.Equal(t, 2, strings.Count(message, "failed"), "Expected the errors of all failed clients")
+		require.Contains(t, message, "client 0: creation 1 failed")
+		require.Contains(t, message, "client 2: creation 3 failed")
+		require.Equal(t, int64(4), calls.Load(), "Expected a single attempt per client")
+	}()
+
+	// Sequential creation with a single attempt fails the first and the third client
+	socks5.NewConnectionPool(4, time.Hour, creator, logger, socks5.WithPrewarm(1, 1))
+}
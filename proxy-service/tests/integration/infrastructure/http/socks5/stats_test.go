@@ -0,0 +1,28 @@
+package socks5
+
+import (
+	"proxy-service/infrastructure/http/socks5"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConnectionPool_Stats verifies that Stats tracks idle, borrowed and overflow clients
+// along with the cumulative borrow and return counts.
+func TestConnectionPool_Stats(t *testing.T) {
+	container := SetupTestContainer()
+	pool := container.OverflowPool.Get()
+	defer pool.Shutdown()
+
+	require.Equal(t, socks5.PoolStats{MaxSize: 1, Idle: 1}, pool.Stats())
+
+	pooled, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow pooled client")
+	transient, err := pool.Borrow()
+	require.NoError(t, err, "Failed to borrow overflow client")
+	require.Equal(t, socks5.PoolStats{MaxSize: 1, InUse: 2, Overflow: 1, BorrowCount: 2}, pool.Stats())
+
+	pool.Return(transient)
+	pool.Return(pooled)
+	require.Equal(t, socks5.PoolStats{MaxSize: 1, Idle: 1, BorrowCount: 2, ReturnCount: 2}, pool.Stats())
+}
@@ -0,0 +1,52 @@
+package clock
+
+import "time"
+
+// Clock abstracts the time source so time-dependent components can be tested deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTicker returns a new Ticker delivering ticks every d.
+	NewTicker(d time.Duration) Ticker
+
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker abstracts time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// Real is a Clock backed by the time package.
+type Real struct{}
+
+// NewReal creates a new instance of Real.
+func NewReal() *Real {
+	return &Real{}
+}
+
+// Now returns the current time.
+func (c *Real) Now() time.Time { return time.Now() }
+
+// NewTicker returns a new Ticker backed by time.Ticker.
+func (c *Real) NewTicker(d time.Duration) Ticker { return &realTicker{ticker: time.NewTicker(d)} }
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (c *Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// realTicker wraps time.Ticker to implement Ticker.
+type realTicker struct {
+	ticker *time.Ticker // ticker is the underlying ticker.
+}
+
+// C returns the channel on which the ticks are delivered.
+func (t *realTicker) C() <-chan time.Time { return t.ticker.C }
+
+// Stop turns off the ticker.
+func (t *realTicker) Stop() { t.ticker.Stop() }
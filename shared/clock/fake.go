@@ -0,0 +1,133 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a manually advanced Clock for tests.
+type Fake struct {
+	mu      sync.Mutex    // mu guards the fields below.
+	created *sync.Cond    // created is signaled whenever a ticker is created.
+	now     time.Time     // now is the current fake time.
+	tickers []*fakeTicker // tickers holds the active tickers.
+	timers  []*fakeTimer  // timers holds the pending After channels.
+	total   int           // total is the number of tickers created so far.
+}
+
+// NewFake creates a new instance of Fake starting at the given time.
+func NewFake(now time.Time) *Fake {
+	c := &Fake{now: now}
+	c.created = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current fake time.
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a new Ticker that fires when the fake time is advanced past its period.
+func (c *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ticker := &fakeTicker{clock: c, period: d, next: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, ticker)
+	c.total++
+	c.created.Broadcast()
+	return ticker
+}
+
+// BlockUntilTickers blocks until n tickers have been created since the clock started.
+// Stopped tickers still count, so a component restarting its ticker can be awaited with the next n.
+func (c *Fake) BlockUntilTickers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.total < n {
+		c.created.Wait()
+	}
+}
+
+// After returns a channel that receives the fake time once it is advanced by at least d.
+func (c *Fake) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, &fakeTimer{deadline: c.now.Add(d), c: ch})
+	return ch
+}
+
+// Advance moves the fake time forward and fires the due tickers and timers.
+// Like time.Ticker, a ticker drops ticks its reader is not keeping up with.
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	for _, ticker := range c.tickers {
+		for !ticker.next.After(c.now) {
+			select {
+			case ticker.c <- ticker.next:
+			default:
+			}
+			ticker.next = ticker.next.Add(ticker.period)
+		}
+	}
+
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.deadline.After(c.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+}
+
+// remove stops delivering ticks to the given ticker.
+func (c *Fake) remove(ticker *fakeTicker) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, item := range c.tickers {
+		if item == ticker {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+// fakeTicker is a Ticker driven by a Fake clock.
+type fakeTicker struct {
+	clock  *Fake          // clock is the owning fake clock.
+	period time.Duration  // period is the ticker interval.
+	next   time.Time      // next is the time of the next tick.
+	c      chan time.Time // c delivers the ticks.
+}
+
+// C returns the channel on which the ticks are delivered.
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+
+// Stop turns off the ticker.
+func (t *fakeTicker) Stop() { t.clock.remove(t) }
+
+// fakeTimer is a pending After channel of a Fake clock.
+type fakeTimer struct {
+	deadline time.Time      // deadline is the time the channel fires.
+	c        chan time.Time // c receives the fake time once the deadline is reached.
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"shared/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestFake_BlockUntilTickers verifies that BlockUntilTickers waits for the tickers created by another goroutine,
+// including a ticker created after a previous one was stopped.
+func TestFake_BlockUntilTickers(t *testing.T) {
+	var (
+		fakeClock = clock.NewFake(time.Now())
+		restart   = make(chan struct{})
+		blocked   = make(chan struct{})
+	)
+
+	// Nothing to wait for without a ticker.
+	fakeClock.BlockUntilTickers(0)
+
+	go func() {
+		ticker := fakeClock.NewTicker(time.Minute)
+		<-restart
+		ticker.Stop()
+		fakeClock.NewTicker(time.Minute)
+	}()
+
+	fakeClock.BlockUntilTickers(1)
+
+	go func() {
+		defer close(blocked)
+		fakeClock.BlockUntilTickers(2)
+	}()
+	require.Never(t, func() bool {
+		select {
+		case <-blocked:
+			return true
+		default:
+			return false
+		}
+	}, time.Duration(100)*time.Millisecond, time.Duration(10)*time.Millisecond, "Returned before the restart")
+
+	close(restart)
+	select {
+	case <-blocked:
+	case <-time.After(time.Duration(2) * time.Second):
+		t.Fatal("BlockUntilTickers did not return after the restart")
+	}
+}
+
+// TestFake_Advance verifies that a ticker fires once per elapsed period and drops the ticks not read in time.
+func TestFake_Advance(t *testing.T) {
+	var (
+		start     = time.Now()
+		fakeClock = clock.NewFake(start)
+		ticker    = fakeClock.NewTicker(time.Minute)
+	)
+	defer ticker.Stop()
+
+	fakeClock.Advance(time.Duration(59) * time.Second)
+	require.Empty(t, ticker.C(), "Ticked before the period elapsed")
+
+	fakeClock.Advance(time.Duration(3) * time.Minute)
+	require.Len(t, ticker.C(), 1, "Expected the missed ticks to be dropped")
+	require.Equal(t, start.Add(time.Minute), <-ticker.C(), "Unexpected tick time")
+}
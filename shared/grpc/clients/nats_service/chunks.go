@@ -0,0 +1,129 @@
+package nats_service
+
+import (
+	"fmt"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"sync"
+	"time"
+)
+
+const (
+	defaultReassemblyTimeout = 30 * time.Second // defaultReassemblyTimeout bounds how long partial messages are kept.
+	defaultMaxChunks         = 1024             // defaultMaxChunks bounds the number of chunks of a single message.
+	defaultMaxChunkBytes     = 64 * 1024 * 1024 // defaultMaxChunkBytes bounds the bytes buffered by open partials.
+	defaultMaxPartials       = 64               // defaultMaxPartials bounds the number of open partial messages.
+)
+
+// partialMessage holds the chunks received so far for a single chunked message.
+type partialMessage struct {
+	chunks    [][]byte  // chunks holds the received chunks indexed by sequence.
+	received  uint32    // received is the number of distinct chunks received.
+	size      int       // size is the accumulated payload size.
+	startedAt time.Time // startedAt is the time the first chunk was received.
+}
+
+// chunkAssembler reassembles chunked SubscribeResponse messages into complete payloads.
+//
+// The chunk count of a message, the number of open partials, and the bytes they buffer are bounded, so a peer
+// cannot make the client allocate or retain unbounded memory. It is safe for concurrent use.
+type chunkAssembler struct {
+	mu          sync.Mutex
+	partials    map[string]*partialMessage // partials holds incomplete messages keyed by message id.
+	buffered    int                        // buffered is the payload size held by all partials.
+	timeout     time.Duration              // timeout is the maximum time to wait for all chunks of a message.
+	maxChunks   uint32                     // maxChunks is the maximum number of chunks of a message.
+	maxBytes    int                        // maxBytes is the maximum payload size held by all partials.
+	maxPartials int                        // maxPartials is the maximum number of open partials.
+}
+
+// newChunkAssembler creates a new instance of chunkAssembler.
+func newChunkAssembler(timeout time.Duration, maxChunks, maxBytes int) *chunkAssembler {
+	return &chunkAssembler{
+		partials:    make(map[string]*partialMessage),
+		timeout:     timeout,
+		maxChunks:   uint32(maxChunks),
+		maxBytes:    maxBytes,
+		maxPartials: defaultMaxPartials,
+	}
+}
+
+// add stores a chunk and returns the complete payload once all chunks of the message have arrived.
+// A chunk exceeding a limit is rejected and its partial message dropped.
+func (a *chunkAssembler) add(message *natsservicev1.SubscribeResponse, now time.Time) (data []byte, complete bool, err error) {
+	var (
+		id       = message.GetMessageId()
+		sequence = message.GetSequence()
+		total    = message.GetTotal()
+		chunk    = message.GetData()
+	)
+
+	if id == "" {
+		return nil, false, fmt.Errorf("chunk without message id")
+	}
+	if sequence >= total {
+		return nil, false, fmt.Errorf("chunk sequence %d out of range for message %s with %d chunks", sequence, id, total)
+	}
+	if total > a.maxChunks {
+		return nil, false, fmt.Errorf("message %s has %d chunks, more than the limit of %d", id, total, a.maxChunks)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	partial, exists := a.partials[id]
+	if !exists {
+		if len(a.partials) >= a.maxPartials {
+			return nil, false, fmt.Errorf("too many partial messages, limit is %d", a.maxPartials)
+		}
+		partial = &partialMessage{chunks: make([][]byte, total), startedAt: now}
+		a.partials[id] = partial
+	}
+	if int(total) != len(partial.chunks) {
+		a.drop(id)
+		return nil, false, fmt.Errorf("chunk count mismatch for message %s", id)
+	}
+
+	if partial.chunks[sequence] == nil {
+		if a.buffered+len(chunk) > a.maxBytes {
+			a.drop(id)
+			return nil, false, fmt.Errorf("message %s exceeds the reassembly budget of %d bytes", id, a.maxBytes)
+		}
+		partial.chunks[sequence] = chunk
+		partial.received++
+		partial.size += len(chunk)
+		a.buffered += len(chunk)
+	}
+
+	if partial.received < total {
+		return nil, false, nil
+	}
+
+	a.drop(id)
+	data = make([]byte, 0, partial.size)
+	for _, chunk := range partial.chunks {
+		data = append(data, chunk...)
+	}
+	return data, true, nil
+}
+
+// evictExpired drops partial messages that did not complete within the reassembly timeout.
+func (a *chunkAssembler) evictExpired(now time.Time) (evicted []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for id, partial := range a.partials {
+		if now.Sub(partial.startedAt) > a.timeout {
+			a.drop(id)
+			evicted = append(evicted, id)
+		}
+	}
+	return evicted
+}
+
+// drop removes a partial message and releases its bytes from the budget, the caller holds mu.
+func (a *chunkAssembler) drop(id string) {
+	if partial, exists := a.partials[id]; exists {
+		a.buffered -= partial.size
+		delete(a.partials, id)
+	}
+}
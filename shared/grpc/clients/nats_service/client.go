@@ -6,31 +6,96 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"shared/grpc/inprocess"
 	natsservicev1 "shared/proto/nats-service/gen"
+	"time"
 
 	"google.golang.org/grpc"
 )
 
+// Client defines the contract of a NATS client, services depend on it rather than on a concrete transport.
+type Client interface {
+	// Publish sends a message to the specified NATS subject.
+	Publish(ctx context.Context, subject string, data []byte) (err error)
+
+	// Subscribe processes messages of the subject via the handler until the context is canceled.
+	Subscribe(ctx context.Context, subject, queueGroup string, handler func(data []byte, subject string)) (err error)
+
+	// Close releases the resources held by the client.
+	Close() (err error)
+}
+
+// AckHandler processes a message of an acked subscription, ack confirms the message once it is durably handled.
+// Messages that are not acked within the ack wait of the subscription are redelivered.
+type AckHandler func(data []byte, subject string, ack func(ctx context.Context) error)
+
+// AckClient is a Client that also subscribes with manual acks.
+type AckClient interface {
+	Client
+
+	// SubscribeWithAck processes messages of the subject via the handler until the context is canceled.
+	// The subject must be captured by a JetStream stream, a queue group keeps the unacked messages of a
+	// crashed subscriber for the next one.
+	SubscribeWithAck(
+		ctx context.Context,
+		subject, queueGroup string,
+		ackWait time.Duration,
+		handler AckHandler,
+	) (err error)
+}
+
+// StreamAckHandler processes a message of a SubscribeAck stream, the message is acked on the stream once it
+// returns nil. A message the handler fails is not acked, and is delivered again after the ack timeout of the server.
+type StreamAckHandler func(data []byte, subject string) error
+
+// AckPayload is the payload published to the reply subject of a message to ack it.
+var AckPayload = []byte("+ACK")
+
+// ErrNoReplySubject is returned when acking a message delivered without a reply subject.
+var ErrNoReplySubject = errors.New("message has no reply subject to ack")
+
+// ErrTooManyStreams is returned by Subscribe when the client already runs its maximum of concurrent streams.
+var ErrTooManyStreams = errors.New("too many concurrent subscribe streams")
+
 // NatsClient is a wrapper over the underlying gRPC client connection to BusService.
+// It implements AckClient.
 type NatsClient struct {
 	conn      *grpc.ClientConn               // conn is the underlying gRPC client connection.
 	client    natsservicev1.BusServiceClient // client is the generated BusService client.
 	validator Validator                      // validator is the gRPC client requests validator.
+	timeout   time.Duration                  // timeout is the reassembly timeout for chunked messages.
+	maxChunks int                            // maxChunks caps the chunks of a single chunked message.
+	maxBytes  int                            // maxBytes caps the bytes buffered by incomplete chunked messages.
+	streams   chan struct{}                  // streams holds a slot per open Subscribe stream, nil is unlimited.
+	queue     bool                           // queue waits for a free stream slot instead of failing.
 	logger    *slog.Logger                   // logger for structured logging.
 }
 
+// InProcessName is the name the in-process BusService listener is registered under.
+const InProcessName = "nats-service"
+
 // NewNatsClient creates a new instance of NatsClient.
-func NewNatsClient(env, address string, validator Validator, logger *slog.Logger) (natsClient *NatsClient, err error) {
+// An in-process address (see inprocess.Address) connects to a BusService co-located in the same process,
+// whatever the environment, without a network listener. Options such as WithServerName refine the connection.
+func NewNatsClient(
+	env, address string,
+	validator Validator,
+	logger *slog.Logger,
+	opts ...Option,
+) (natsClient *NatsClient, err error) {
 	var (
 		conn   *grpc.ClientConn
 		config *Config
 	)
 
-	switch env {
-	case "prod":
-		conn, config, err = NewGRPCClient(WithAddress(address), WithTLS(""))
-	case "dev":
-		conn, config, err = NewGRPCClient(WithAddress(address))
+	name, inProcess := inprocess.Name(address)
+	switch {
+	case inProcess:
+		conn, config, err = NewGRPCClient(append(opts, WithAddress(address), WithInProcess(name))...)
+	case env == "prod":
+		conn, config, err = NewGRPCClient(append(opts, WithAddress(address), WithTLS(""))...)
+	case env == "dev":
+		conn, config, err = NewGRPCClient(append(opts, WithAddress(address))...)
 	default:
 		return nil, errors.New("unsupported environment; must be \"prod\" or \"dev\"")
 	}
@@ -41,29 +106,49 @@ func NewNatsClient(env, address string, validator Validator, logger *slog.Logger
 	}
 
 	logger.Info("New channel established", "address", address, "tls_enabled", config.TLSEnabled)
-	return &NatsClient{
+	natsClient = &NatsClient{
 		conn:      conn,
 		client:    natsservicev1.NewBusServiceClient(conn),
 		validator: validator,
+		timeout:   defaultReassemblyTimeout,
+		maxChunks: config.MaxChunks,
+		maxBytes:  config.MaxChunkBytes,
+		queue:     config.QueueStreams,
 		logger:    logger,
-	}, nil
+	}
+	if config.MaxStreams > 0 {
+		natsClient.streams = make(chan struct{}, config.MaxStreams)
+	}
+	return natsClient, nil
 }
 
 // Publish sends a message to the specified NATS subject.
+// Publish requests are not chunked, so data must fit into a single gRPC message.
 func (c *NatsClient) Publish(ctx context.Context, subject string, data []byte) (err error) {
+	return c.publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: data})
+}
+
+// PublishAcked sends a message to the specified NATS subject through JetStream, it returns once the message is
+// persisted by the stream capturing the subject.
+func (c *NatsClient) PublishAcked(ctx context.Context, subject string, data []byte) (err error) {
+	return c.publish(ctx, &natsservicev1.PublishRequest{Subject: subject, Data: data, Jetstream: true})
+}
+
+// publish validates and sends a publish request.
+func (c *NatsClient) publish(ctx context.Context, request *natsservicev1.PublishRequest) (err error) {
 	var (
-		request  = natsservicev1.PublishRequest{Subject: subject, Data: data}
+		subject  = request.GetSubject()
 		response *natsservicev1.PublishResponse
 	)
 
 	// Validate request before sending
-	if err = c.validator.ValidatePublishRequest(&request); err != nil {
+	if err = c.validator.ValidatePublishRequest(request); err != nil {
 		c.logger.Error("Validation failed for publish request", "subject", subject, "error", err)
 		return fmt.Errorf("validate publish request: %w", err)
 	}
 
 	// RPC call
-	if response, err = c.client.Publish(ctx, &request); err != nil {
+	if response, err = c.client.Publish(ctx, request); err != nil {
 		c.logger.Error("Failed to publish message", "subject", subject, "error", err)
 		return fmt.Errorf("message publish: %w", err)
 	}
@@ -77,54 +162,233 @@ func (c *NatsClient) Publish(ctx context.Context, subject string, data []byte) (
 }
 
 // Subscribe listens for messages on a specified NATS subject and processes them via a callback function.
+// Chunked messages are reassembled before being passed to the handler, those exceeding the chunk limits
+// (see WithChunkLimits) or not completing within the reassembly timeout are dropped.
 func (c *NatsClient) Subscribe(
 	ctx context.Context,
 	subject, queueGroup string,
 	handler func(data []byte, subject string),
+) (err error) {
+	request := natsservicev1.SubscribeRequest{Subject: subject, QueueGroup: queueGroup}
+	open := func(ctx context.Context) (receiver, error) { return c.client.Subscribe(ctx, &request) }
+	return c.subscribe(ctx, &request, open, func(data []byte, message *natsservicev1.SubscribeResponse) {
+		handler(data, message.GetSubject())
+	})
+}
+
+// SubscribeWithAck listens for messages on a specified JetStream subject with manual acks.
+// ackWait is rounded up to whole seconds, messages the handler does not ack in time are redelivered.
+func (c *NatsClient) SubscribeWithAck(
+	ctx context.Context,
+	subject, queueGroup string,
+	ackWait time.Duration,
+	handler AckHandler,
+) (err error) {
+	request := natsservicev1.SubscribeRequest{
+		Subject: subject, QueueGroup: queueGroup, AckWaitSeconds: ackWaitSeconds(ackWait),
+	}
+	open := func(ctx context.Context) (receiver, error) { return c.client.Subscribe(ctx, &request) }
+	return c.subscribe(ctx, &request, open, func(data []byte, message *natsservicev1.SubscribeResponse) {
+		reply := message.GetReply()
+		handler(data, message.GetSubject(), func(ctx context.Context) error {
+			if reply == "" {
+				return ErrNoReplySubject
+			}
+			return c.Publish(ctx, reply, AckPayload)
+		})
+	})
+}
+
+// SubscribeAck listens for messages on a specified NATS subject over a SubscribeAck stream, and acks every message
+// on the stream once the handler processed it. Messages the handler fails, or does not process within the ack
+// timeout of the server, are delivered again while the stream is open, chunked messages are reassembled first.
+// A positive ackWait subscribes through JetStream, which also keeps the unacked messages once the stream ended;
+// without it they are lost with the stream.
+func (c *NatsClient) SubscribeAck(
+	ctx context.Context,
+	subject, queueGroup string,
+	ackWait time.Duration,
+	handler StreamAckHandler,
 ) (err error) {
 	var (
 		request = natsservicev1.SubscribeRequest{Subject: subject, QueueGroup: queueGroup}
-		stream  grpc.ServerStreamingClient[natsservicev1.SubscribeResponse]
-		message *natsservicev1.SubscribeResponse
+		stream  grpc.BidiStreamingClient[natsservicev1.SubscribeAckRequest, natsservicev1.SubscribeResponse]
+	)
+	if ackWait > 0 {
+		request.AckWaitSeconds = ackWaitSeconds(ackWait)
+	}
+
+	open := func(ctx context.Context) (receiver, error) {
+		var err error
+		if stream, err = c.client.SubscribeAck(ctx); err != nil {
+			return nil, err
+		}
+		subscribe := &natsservicev1.SubscribeAckRequest{
+			Frame: &natsservicev1.SubscribeAckRequest_Subscribe{Subscribe: &request},
+		}
+		if err = stream.Send(subscribe); err != nil {
+			return nil, fmt.Errorf("send subscribe request: %w", err)
+		}
+		return stream, nil
+	}
+	return c.subscribe(ctx, &request, open, func(data []byte, message *natsservicev1.SubscribeResponse) {
+		id := message.GetMessageId()
+		if handlerErr := handler(data, message.GetSubject()); handlerErr != nil {
+			c.logger.Warn("Message not acked, it is delivered again", "subject", subject, "message_id", id,
+				"error", handlerErr)
+			return
+		}
+		ack := &natsservicev1.SubscribeAckRequest{
+			Frame: &natsservicev1.SubscribeAckRequest_Ack{Ack: &natsservicev1.Ack{MessageId: id}},
+		}
+		// A failed send means the stream ended, the next receive reports why
+		if sendErr := stream.Send(ack); sendErr != nil {
+			c.logger.Warn("Failed to ack message", "subject", subject, "message_id", id, "error", sendErr)
+		}
+	})
+}
+
+// ackWaitSeconds rounds ackWait up to the whole seconds of SubscribeRequest.ack_wait_seconds, at least one.
+func ackWaitSeconds(ackWait time.Duration) uint32 {
+	return uint32(max((ackWait+time.Second-1)/time.Second, 1))
+}
+
+// receiver is the receiving side of the Subscribe and SubscribeAck streams.
+type receiver interface {
+	Recv() (*natsservicev1.SubscribeResponse, error)
+}
+
+// subscribe streams the messages of the stream opened by open to the handler, chunked messages are reassembled
+// first and passed with their last chunk.
+func (c *NatsClient) subscribe(
+	ctx context.Context,
+	request *natsservicev1.SubscribeRequest,
+	open func(ctx context.Context) (receiver, error),
+	handler func(data []byte, message *natsservicev1.SubscribeResponse),
+) (err error) {
+	var (
+		subject    = request.GetSubject()
+		queueGroup = request.GetQueueGroup()
+		stream     receiver
+		message    *natsservicev1.SubscribeResponse
+		assembler  = newChunkAssembler(c.timeout, c.maxChunks, c.maxBytes)
 	)
 
 	// Validate request before subscribing
-	if err = c.validator.ValidateSubscribeRequest(&request); err != nil {
+	if err = c.validator.ValidateSubscribeRequest(request); err != nil {
 		c.logger.Error("Validation failed for subscribe request",
 			"subject", subject, "queueGroup", queueGroup, "error", err)
 		return fmt.Errorf("validate subscribe request: %w", err)
 	}
 
-	// Open a gRPC streaming connection
-	if stream, err = c.client.Subscribe(ctx, &request); err != nil {
+	// Take a stream slot for the lifetime of the subscription
+	if err = c.acquireStream(ctx, subject); err != nil {
+		return err
+	}
+	defer c.releaseStream()
+
+	// Open a gRPC streaming connection bound to the subscription context, so a blocked Recv is interrupted
+	// as soon as the context is done, and the stream is released when the subscription returns
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if stream, err = open(streamCtx); err != nil {
 		c.logger.Error("Failed to subscribe to subject", "subject", subject, "error", err)
 		return fmt.Errorf("subscribe to subject %s: %w", subject, err)
 	}
 	c.logger.Info("Subscribed to NATS subject", "subject", subject)
 
-	// Continuously listen for messages from the gRPC stream
+	// Drop incomplete chunked messages on a timer, so a stalled message does not stay buffered until the
+	// next chunked message arrives
+	go c.evictChunks(streamCtx, subject, assembler)
+
+	// Continuously listen for messages from the gRPC stream; a canceled context is reported as such
+	// rather than as a receive error
+	for {
+		if message, err = stream.Recv(); err != nil {
+			switch {
+			case ctx.Err() != nil:
+				c.logger.Info("Subscription canceled", "subject", subject)
+				return ctx.Err()
+			case errors.Is(err, io.EOF):
+				c.logger.Info("End of stream reached for subscription", "subject", subject)
+				return nil
+			default:
+				c.logger.Error("Error receiving message from NATS", "subject", subject, "error", err)
+				return fmt.Errorf("receive message from NATS: %w", err)
+			}
+		}
+		// Deliver unchunked messages as is
+		if message.GetTotal() <= 1 {
+			handler(message.GetData(), message)
+			continue
+		}
+
+		// Reassemble chunked messages
+		data, complete, chunkErr := assembler.add(message, time.Now())
+		if chunkErr != nil {
+			c.logger.Warn("Discarded invalid message chunk", "subject", subject, "error", chunkErr)
+			continue
+		}
+		if complete {
+			handler(data, message)
+		}
+	}
+}
+
+// evictChunks drops the chunked messages that did not complete within the reassembly timeout until ctx is done.
+func (c *NatsClient) evictChunks(ctx context.Context, subject string, assembler *chunkAssembler) {
+	ticker := time.NewTicker(max(c.timeout/2, time.Millisecond))
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
-			c.logger.Info("Subscription canceled", "subject", subject)
-			return ctx.Err()
-		default:
-			if message, err = stream.Recv(); err != nil {
-				switch err {
-				case io.EOF:
-					c.logger.Info("End of stream reached for subscription", "subject", subject)
-					return nil
-				default:
-					c.logger.Error("Error receiving message from NATS", "subject", subject, "error", err)
-					return fmt.Errorf("receive message from NATS: %w", err)
-				}
+			return
+		case now := <-ticker.C:
+			for _, id := range assembler.evictExpired(now) {
+				c.logger.Warn("Dropped incomplete chunked message", "subject", subject, "message_id", id)
 			}
-			// Process the received message using the provided handler
-			handler(message.GetData(), message.GetSubject())
 		}
 	}
 }
 
+// acquireStream takes a stream slot, failing with ErrTooManyStreams when none is free unless the client queues.
+func (c *NatsClient) acquireStream(ctx context.Context, subject string) error {
+	if c.streams == nil {
+		return nil
+	}
+	select {
+	case c.streams <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if !c.queue {
+		c.logger.Error("Too many concurrent subscribe streams", "subject", subject, "maxStreams", cap(c.streams))
+		return fmt.Errorf("subscribe to subject %s: %w", subject, ErrTooManyStreams)
+	}
+	c.logger.Warn("Waiting for a free subscribe stream", "subject", subject, "maxStreams", cap(c.streams))
+	select {
+	case c.streams <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseStream frees the stream slot taken by acquireStream.
+func (c *NatsClient) releaseStream() {
+	if c.streams != nil {
+		<-c.streams
+	}
+}
+
+// Streams returns the number of Subscribe streams holding a slot, always 0 without a stream limit.
+func (c *NatsClient) Streams() int {
+	return len(c.streams)
+}
+
 // Close closes the underlying gRPC connection.
 func (c *NatsClient) Close() (err error) {
 	if err = c.conn.Close(); err != nil {
@@ -1,7 +1,13 @@
 package nats_service
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"shared/grpc/inprocess"
 	"strings"
 
 	"google.golang.org/grpc"
@@ -14,8 +20,20 @@ type Config struct {
 	TLSEnabled bool   // TLSEnabled is used to indicate whether to use TLS.
 	Address    string // Address is a target server address.
 	CertFile   string // CertFile is a path to the certificate file (TLS).
+	InProcess  string // InProcess is the name of an in-process listener to dial instead of the network.
+	ServerName string // ServerName overrides the name the server certificate is verified against (TLS).
+
+	MaxStreams   int  // MaxStreams caps the concurrent Subscribe streams of the client, 0 is unlimited.
+	QueueStreams bool // QueueStreams waits for a stream to end beyond MaxStreams instead of failing.
+
+	MaxChunks     int // MaxChunks caps the chunks of a single subscribed message, 0 keeps the default.
+	MaxChunkBytes int // MaxChunkBytes caps the bytes buffered while reassembling chunked messages, 0 keeps the default.
 }
 
+// ErrServerNameRequired is returned when TLS is enabled towards an IP address without a server name override,
+// the certificate could not be verified against the name it was issued for.
+var ErrServerNameRequired = errors.New("TLS server name is required when dialing an IP address")
+
 // Option defines a functional option for configuring the client.
 type Option func(*Config)
 
@@ -27,6 +45,34 @@ func WithTLS(certFile string) Option {
 	}
 }
 
+// WithServerName verifies the server certificate against name instead of the dialed address,
+// e.g. when dialing a load balancer or an IP address. An empty name keeps the default.
+func WithServerName(name string) Option {
+	return func(config *Config) {
+		config.ServerName = name
+	}
+}
+
+// WithMaxStreams caps the concurrent Subscribe streams of the client at limit, so that a service opening many
+// subscriptions cannot exhaust the stream budget of the connection. Beyond the limit a Subscribe fails with
+// ErrTooManyStreams, or waits until another subscription ends when queue is set. A non-positive limit is unlimited.
+func WithMaxStreams(limit int, queue bool) Option {
+	return func(config *Config) {
+		config.MaxStreams = max(limit, 0)
+		config.QueueStreams = queue
+	}
+}
+
+// WithChunkLimits bounds the reassembly of chunked Subscribe deliveries: a message split into more than maxChunks
+// chunks, or one that would take the bytes buffered by all incomplete messages beyond maxBytes, is dropped.
+// Non-positive values keep the defaults.
+func WithChunkLimits(maxChunks, maxBytes int) Option {
+	return func(config *Config) {
+		config.MaxChunks = max(maxChunks, 0)
+		config.MaxChunkBytes = max(maxBytes, 0)
+	}
+}
+
 // WithAddress sets the target server address.
 func WithAddress(address string) Option {
 	return func(config *Config) {
@@ -34,6 +80,15 @@ func WithAddress(address string) Option {
 	}
 }
 
+// WithInProcess dials the in-process listener registered under name instead of a network address.
+// The connection never leaves the process, so it is never encrypted.
+func WithInProcess(name string) Option {
+	return func(config *Config) {
+		config.InProcess = name
+		config.TLSEnabled = false
+	}
+}
+
 // NewGRPCClient initializes a gRPC client connection with the provided options.
 func NewGRPCClient(opts ...Option) (client *grpc.ClientConn, config *Config, err error) {
 	config = &Config{
@@ -44,34 +99,69 @@ func NewGRPCClient(opts ...Option) (client *grpc.ClientConn, config *Config, err
 	for _, opt := range opts {
 		opt(config)
 	}
+	if config.MaxChunks == 0 {
+		config.MaxChunks = defaultMaxChunks
+	}
+	if config.MaxChunkBytes == 0 {
+		config.MaxChunkBytes = defaultMaxChunkBytes
+	}
 
 	var (
 		dialOpts             []grpc.DialOption
 		transportCredentials credentials.TransportCredentials
 		conn                 *grpc.ClientConn
+		target               = config.Address
 	)
 
+	if config.InProcess != "" {
+		// The passthrough resolver hands the name as is to the in-process dialer
+		target = "passthrough:///" + config.InProcess
+		dialOpts = append(dialOpts, grpc.WithContextDialer(inprocess.Dial))
+	}
+
 	if config.TLSEnabled {
-		if transportCredentials, err = getTransportCredentials(config.CertFile); err != nil {
+		var tlsConfig *tls.Config
+		if tlsConfig, err = TLSConfig(config); err != nil {
 			return nil, nil, fmt.Errorf("could not get transport credentials: %w", err)
 		}
+		transportCredentials = credentials.NewTLS(tlsConfig)
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(transportCredentials))
 	} else {
 		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
-	if conn, err = grpc.NewClient(config.Address, dialOpts...); err != nil {
+	if conn, err = grpc.NewClient(target, dialOpts...); err != nil {
 		return nil, nil, fmt.Errorf("could not create client: %w", err)
 	}
 	return conn, config, nil
 }
 
-// getTransportCredentials determines and returns the correct transport credentials.
-func getTransportCredentials(certFile string) (transportCredentials credentials.TransportCredentials, err error) {
-	if strings.TrimSpace(certFile) != "" {
-		// Use client-side TLS with the provided certificate
-		return credentials.NewClientTLSFromFile(certFile, "")
+// TLSConfig builds the client TLS configuration: the certificate file, if any, is the trusted root,
+// otherwise the system CA trust store is used. The server name override is validated against the address.
+func TLSConfig(config *Config) (tlsConfig *tls.Config, err error) {
+	if config.ServerName == "" {
+		host, _, splitErr := net.SplitHostPort(config.Address)
+		if splitErr != nil {
+			host = config.Address
+		}
+		if net.ParseIP(host) != nil {
+			return nil, fmt.Errorf("%w: %s", ErrServerNameRequired, config.Address)
+		}
+	}
+
+	tlsConfig = &tls.Config{ServerName: config.ServerName, MinVersion: tls.VersionTLS12}
+	if strings.TrimSpace(config.CertFile) == "" {
+		return tlsConfig, nil
+	}
+
+	// Use client-side TLS with the provided certificate
+	var pem []byte
+	if pem, err = os.ReadFile(config.CertFile); err != nil {
+		return nil, fmt.Errorf("read certificate: %w", err)
+	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", config.CertFile)
 	}
-	// Use system CA trust store for validation
-	return credentials.NewTLS(nil), nil
+	return tlsConfig, nil
 }
@@ -0,0 +1,75 @@
+package nats_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	natsservicev1 "shared/proto/nats-service/gen"
+	"sync"
+)
+
+// PublishResult is the outcome of publishing a fanned-out message to one of its subjects.
+type PublishResult struct {
+	Subject string // Subject is the NATS subject the message was published to.
+	Err     error  // Err is the error of the publish, nil if it succeeded.
+}
+
+// PublishAll publishes the same message to each of the subjects, e.g. a processed URL to both a results and
+// an audit subject. All the subjects are validated before anything is published, so an invalid or duplicated
+// subject publishes nothing. The publishes run concurrently on a best effort basis, a failed one does not stop
+// the others; results report the outcome per subject in the order of subjects, and err joins the failures.
+func (c *NatsClient) PublishAll(
+	ctx context.Context,
+	subjects []string,
+	data []byte,
+) (results []PublishResult, err error) {
+	if err = c.validateFanOut(subjects, data); err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	results = make([]PublishResult, len(subjects))
+	for i, subject := range subjects {
+		results[i].Subject = subject
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Err = c.Publish(ctx, subject, data)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("subject %s: %w", result.Subject, result.Err))
+		}
+	}
+	if len(errs) > 0 {
+		c.logger.Error("Fan-out publish partially failed", "subjects", len(subjects), "failed", len(errs))
+		return results, fmt.Errorf("fan-out publish: %w", errors.Join(errs...))
+	}
+	return results, nil
+}
+
+// validateFanOut validates the publish request of every subject and rejects an empty or duplicated list.
+func (c *NatsClient) validateFanOut(subjects []string, data []byte) (err error) {
+	if len(subjects) == 0 {
+		return errors.New("validate fan-out publish: subjects required")
+	}
+
+	seen := make(map[string]struct{}, len(subjects))
+	for _, subject := range subjects {
+		if _, duplicate := seen[subject]; duplicate {
+			return fmt.Errorf("validate fan-out publish: duplicate subject %s", subject)
+		}
+		seen[subject] = struct{}{}
+
+		request := natsservicev1.PublishRequest{Subject: subject, Data: data}
+		if err = c.validator.ValidatePublishRequest(&request); err != nil {
+			c.logger.Error("Validation failed for fan-out publish request", "subject", subject, "error", err)
+			return fmt.Errorf("validate fan-out publish request: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package messaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Envelope versions.
+const (
+	// Version1 is a header of magic and version followed by the raw payload.
+	Version1 uint8 = 1
+	// Version2 extends Version1 with a format byte describing the payload encoding.
+	Version2 uint8 = 2
+	// Version3 extends Version2 with the publish timestamp in unix nanoseconds, used to measure consumer lag.
+	Version3 uint8 = 3
+	// Version4 extends Version3 with the retry budget of the message shared across the pipeline.
+	Version4 uint8 = 4
+	// CurrentVersion is the version set by Encode when none is given.
+	CurrentVersion = Version4
+)
+
+// Format describes the encoding of an enveloped payload.
+type Format uint8
+
+// Payload formats.
+const (
+	FormatRaw  Format = iota // FormatRaw is an unencoded payload, such as a plain URL.
+	FormatJSON               // FormatJSON is a JSON document.
+	FormatGzip               // FormatGzip is a gzip-compressed payload.
+)
+
+// magic marks the start of an enveloped message; it is outside the ASCII range and differs from the gzip
+// magic, so it never collides with legacy URL, JSON or compressed payloads.
+var magic = [2]byte{0xB5, 0x4D}
+
+// noRetryBudget is the attempts of a Version4 envelope without a retry budget, attempts are capped below it.
+const noRetryBudget = 0xFFFF
+
+var (
+	// ErrNotEnveloped is returned by Decode when the data does not start with the envelope magic.
+	ErrNotEnveloped = errors.New("message is not enveloped")
+	// ErrUnknownVersion is returned when the envelope version is not supported.
+	ErrUnknownVersion = errors.New("unknown envelope version")
+	// ErrUnknownFormat is returned when the payload format is not supported.
+	ErrUnknownFormat = errors.New("unknown payload format")
+	// ErrTruncated is returned when the data is shorter than the envelope header.
+	ErrTruncated = errors.New("truncated envelope")
+)
+
+// Envelope is a versioned wrapper of a NATS message payload.
+type Envelope struct {
+	Version uint8  // Version is the envelope version; zero means CurrentVersion.
+	Format  Format // Format is the payload encoding; Version1 envelopes always carry FormatRaw.
+	Payload []byte // Payload is the wrapped message payload.
+
+	// PublishedAt is the time the message was published, carried from Version3 on; zero means unknown.
+	PublishedAt time.Time
+	// Retry is the retry budget left to the pipeline, carried from Version4 on; nil means none.
+	Retry *RetryBudget
+}
+
+// Lag returns the delay between the publish of the message and now, ok is false when the publish time is unknown.
+// A publish time ahead of now, due to clock skew between hosts, is reported as no lag.
+func (e Envelope) Lag(now time.Time) (lag time.Duration, ok bool) {
+	if e.PublishedAt.IsZero() {
+		return 0, false
+	}
+	return max(now.Sub(e.PublishedAt), 0), true
+}
+
+// Stale reports whether the message was published more than maxAge before now.
+// A zero maxAge disables the check, messages with an unknown publish time are never stale.
+func (e Envelope) Stale(now time.Time, maxAge time.Duration) bool {
+	lag, ok := e.Lag(now)
+	return ok && maxAge > 0 && lag > maxAge
+}
+
+// headerSize returns the size of the envelope header of the given version.
+func headerSize(version uint8) (size int, err error) {
+	switch version {
+	case Version1:
+		return len(magic) + 1, nil
+	case Version2:
+		return len(magic) + 2, nil
+	case Version3:
+		return len(magic) + 2 + 8, nil
+	case Version4:
+		return len(magic) + 2 + 8 + 2 + 8, nil
+	default:
+		return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+}
+
+// Encode serializes the envelope into a message.
+func Encode(envelope Envelope) (data []byte, err error) {
+	var (
+		version = envelope.Version
+		size    int
+	)
+	if version == 0 {
+		version = CurrentVersion
+	}
+	if size, err = headerSize(version); err != nil {
+		return nil, err
+	}
+	if envelope.Format > FormatGzip {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownFormat, envelope.Format)
+	}
+	if version == Version1 && envelope.Format != FormatRaw {
+		return nil, fmt.Errorf("%w: version %d carries raw payloads only", ErrUnknownFormat, version)
+	}
+
+	data = make([]byte, 0, size+len(envelope.Payload))
+	data = append(data, magic[0], magic[1], version)
+	if version >= Version2 {
+		data = append(data, byte(envelope.Format))
+	}
+	if version >= Version3 {
+		var publishedAt int64
+		if !envelope.PublishedAt.IsZero() {
+			publishedAt = envelope.PublishedAt.UnixNano()
+		}
+		data = binary.BigEndian.AppendUint64(data, uint64(publishedAt))
+	}
+	if version >= Version4 {
+		data = appendRetryBudget(data, envelope.Retry)
+	}
+	return append(data, envelope.Payload...), nil
+}
+
+// Decode parses a message into an envelope.
+// The payload of the returned envelope shares the memory of data.
+func Decode(data []byte) (envelope Envelope, err error) {
+	var size int
+
+	if len(data) < len(magic) || data[0] != magic[0] || data[1] != magic[1] {
+		return Envelope{}, ErrNotEnveloped
+	}
+	if len(data) < len(magic)+1 {
+		return Envelope{}, ErrTruncated
+	}
+
+	envelope.Version = data[len(magic)]
+	if size, err = headerSize(envelope.Version); err != nil {
+		return Envelope{}, err
+	}
+	if len(data) < size {
+		return Envelope{}, ErrTruncated
+	}
+
+	if envelope.Version >= Version2 {
+		if envelope.Format = Format(data[len(magic)+1]); envelope.Format > FormatGzip {
+			return Envelope{}, fmt.Errorf("%w: %d", ErrUnknownFormat, envelope.Format)
+		}
+	}
+	if envelope.Version >= Version3 {
+		if publishedAt := int64(binary.BigEndian.Uint64(data[len(magic)+2:])); publishedAt != 0 {
+			envelope.PublishedAt = time.Unix(0, publishedAt)
+		}
+	}
+	if envelope.Version >= Version4 {
+		envelope.Retry = readRetryBudget(data[len(magic)+2+8:])
+	}
+	envelope.Payload = data[size:]
+	return envelope, nil
+}
+
+// appendRetryBudget appends the attempts and the deadline in unix nanoseconds of the retry budget.
+func appendRetryBudget(data []byte, budget *RetryBudget) []byte {
+	if budget == nil {
+		data = binary.BigEndian.AppendUint16(data, noRetryBudget)
+		return binary.BigEndian.AppendUint64(data, 0)
+	}
+
+	var deadline int64
+	if !budget.Deadline.IsZero() {
+		deadline = budget.Deadline.UnixNano()
+	}
+	data = binary.BigEndian.AppendUint16(data, uint16(min(max(budget.Attempts, 0), noRetryBudget-1)))
+	return binary.BigEndian.AppendUint64(data, uint64(deadline))
+}
+
+// readRetryBudget reads a retry budget appended by appendRetryBudget.
+func readRetryBudget(data []byte) *RetryBudget {
+	attempts := binary.BigEndian.Uint16(data)
+	if attempts == noRetryBudget {
+		return nil
+	}
+
+	budget := &RetryBudget{Attempts: int(attempts)}
+	if deadline := int64(binary.BigEndian.Uint64(data[2:])); deadline != 0 {
+		budget.Deadline = time.Unix(0, deadline)
+	}
+	return budget
+}
+
+// Open parses a message into an envelope, treating messages without an envelope as raw payloads
+// published before envelopes were introduced; their envelope has a zero version.
+func Open(data []byte) (envelope Envelope, err error) {
+	envelope, err = Decode(data)
+	switch {
+	case errors.Is(err, ErrNotEnveloped):
+		return Envelope{Format: FormatRaw, Payload: data}, nil
+	case err != nil:
+		return Envelope{}, err
+	default:
+		return envelope, nil
+	}
+}
+
+// Unwrap returns the payload and format of a message, treating messages without an envelope
+// as raw payloads published before envelopes were introduced.
+func Unwrap(data []byte) (payload []byte, format Format, err error) {
+	envelope, err := Open(data)
+	if err != nil {
+		return nil, FormatRaw, err
+	}
+	return envelope.Payload, envelope.Format, nil
+}
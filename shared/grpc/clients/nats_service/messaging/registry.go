@@ -0,0 +1,133 @@
+package messaging
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+)
+
+// External names the side of a subject outside the microservices of this repository.
+const External = "external"
+
+// Direction describes which service publishes to a subject and which one subscribes to it.
+type Direction struct {
+	Publisher  string // Publisher is the service publishing to the subject.
+	Subscriber string // Subscriber is the service subscribing to the subject.
+}
+
+// String returns the direction as "publisher -> subscriber".
+func (d Direction) String() string {
+	return d.Publisher + " -> " + d.Subscriber
+}
+
+// Subject describes a NATS subject used for inter-microservice communication.
+type Subject struct {
+	Name        string    // Name is the NATS subject.
+	Description string    // Description is the purpose of the subject.
+	Version     uint8     // Version is the envelope version of the published messages, 0 means not enveloped.
+	Format      Format    // Format is the encoding of the message payload.
+	Direction   Direction // Direction is the flow of the messages between the services.
+}
+
+// registry holds the subjects used for inter-microservice communication, ordered by name.
+var registry = []Subject{
+	{
+		Name:        ProxyUrlError,
+		Description: "Failures of the URLs the proxy-service gave up fetching",
+		Version:     CurrentVersion,
+		Format:      FormatJSON,
+		Direction:   Direction{Publisher: "proxy-service", Subscriber: External},
+	},
+	{
+		Name:        ProxyUrlRequest,
+		Description: "Fetch requests, JSON or plain URLs, to be made by the proxy-service",
+		Version:     CurrentVersion,
+		Format:      FormatRaw,
+		Direction:   Direction{Publisher: External, Subscriber: "proxy-service"},
+	},
+	{
+		Name:        ProxyUrlResponse,
+		Description: "Response bodies of the URLs fetched by the proxy-service",
+		Version:     CurrentVersion,
+		Format:      FormatRaw,
+		Direction:   Direction{Publisher: "proxy-service", Subscriber: External},
+	},
+	{
+		Name:        UrlIncoming,
+		Description: "URL documents to be saved by the url-service",
+		Version:     CurrentVersion,
+		Format:      FormatJSON,
+		Direction:   Direction{Publisher: External, Subscriber: "url-service"},
+	},
+	{
+		Name:        UrlOutgoing,
+		Description: "Pending URL records published by the url-service",
+		Format:      FormatJSON,
+		Direction:   Direction{Publisher: "url-service", Subscriber: External},
+	},
+}
+
+// Subjects returns the registered subjects ordered by name.
+func Subjects() []Subject {
+	return slices.Clone(registry)
+}
+
+// Names returns the names of the registered subjects ordered by name, e.g. for an allowlist.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for _, subject := range registry {
+		names = append(names, subject.Name)
+	}
+	return names
+}
+
+// Lookup returns the registered subject of the given name, ok is false for an unregistered one.
+func Lookup(name string) (subject Subject, ok bool) {
+	for _, subject = range registry {
+		if subject.Name == name {
+			return subject, true
+		}
+	}
+	return Subject{}, false
+}
+
+// Registered reports whether a subject of the given name is registered.
+func Registered(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Dump writes the registered subjects as a table, for documentation and debugging.
+func Dump(w io.Writer) (err error) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err = fmt.Fprintln(table, "SUBJECT\tVERSION\tFORMAT\tDIRECTION\tDESCRIPTION"); err != nil {
+		return err
+	}
+	for _, subject := range registry {
+		version := "-"
+		if subject.Version != 0 {
+			version = fmt.Sprintf("v%d", subject.Version)
+		}
+		_, err = fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", subject.Name, version, formatName(subject.Format),
+			subject.Direction, subject.Description)
+		if err != nil {
+			return err
+		}
+	}
+	return table.Flush()
+}
+
+// formatName returns the name of a payload format.
+func formatName(format Format) string {
+	switch format {
+	case FormatRaw:
+		return "raw"
+	case FormatJSON:
+		return "json"
+	case FormatGzip:
+		return "gzip"
+	default:
+		return fmt.Sprintf("unknown(%d)", format)
+	}
+}
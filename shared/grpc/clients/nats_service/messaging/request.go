@@ -0,0 +1,45 @@
+package messaging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrInvalidFetchRequest is returned by ParseFetchRequest when a JSON fetch request cannot be decoded.
+var ErrInvalidFetchRequest = errors.New("invalid fetch request")
+
+// FetchRequest is the payload of a ProxyUrlRequest message, describing the HTTP request to make.
+type FetchRequest struct {
+	Method  string            `json:"method,omitempty"`  // Method is the HTTP method, empty means GET.
+	URL     string            `json:"url"`               // URL is the absolute URL to request.
+	Headers map[string]string `json:"headers,omitempty"` // Headers are set on the request, the client's User-Agent prevails.
+	Body    string            `json:"body,omitempty"`    // Body is the optional request body.
+}
+
+// ParseFetchRequest parses the payload of a ProxyUrlRequest message.
+// A JSON object is decoded as a FetchRequest, any other payload is the plain URL of a GET request, as published
+// before fetch requests were introduced.
+func ParseFetchRequest(payload []byte) (request FetchRequest, err error) {
+	if trimmed := bytes.TrimSpace(payload); len(trimmed) == 0 || trimmed[0] != '{' {
+		return FetchRequest{Method: http.MethodGet, URL: string(payload)}, nil
+	}
+	if err = json.Unmarshal(payload, &request); err != nil {
+		return FetchRequest{}, fmt.Errorf("%w: %w", ErrInvalidFetchRequest, err)
+	}
+	if request.Method == "" {
+		request.Method = http.MethodGet
+	}
+	return request, nil
+}
+
+// FetchError is the payload of a ProxyUrlError message, describing a fetch request that finally failed.
+type FetchError struct {
+	Method string    `json:"method"` // Method is the HTTP method of the failed request.
+	URL    string    `json:"url"`    // URL is the URL of the failed request.
+	Error  string    `json:"error"`  // Error is the error of the last attempt.
+	At     time.Time `json:"at"`     // At is the time the fetch was given up.
+}
@@ -0,0 +1,40 @@
+package messaging
+
+import "time"
+
+// RetryBudget bounds the retries spent on a message across every stage of the pipeline.
+// Stages keep their own retry caps, the budget bounds their sum, so the retries of successive stages never multiply.
+// It is carried from stage to stage in the envelope and is not safe for concurrent use.
+type RetryBudget struct {
+	Attempts int       // Attempts is the number of retries left to the pipeline.
+	Deadline time.Time // Deadline is the time after which no stage retries, zero means none.
+}
+
+// NewRetryBudget creates a new instance of RetryBudget allowing attempts retries within timeout from now,
+// a non-positive timeout sets no deadline.
+func NewRetryBudget(attempts int, timeout time.Duration, now time.Time) *RetryBudget {
+	budget := &RetryBudget{Attempts: max(attempts, 0)}
+	if timeout > 0 {
+		budget.Deadline = now.Add(timeout)
+	}
+	return budget
+}
+
+// Exhausted reports whether no retry is left at now, a nil budget is never exhausted.
+func (b *RetryBudget) Exhausted(now time.Time) bool {
+	if b == nil {
+		return false
+	}
+	return b.Attempts <= 0 || (!b.Deadline.IsZero() && !now.Before(b.Deadline))
+}
+
+// Take consumes a retry, it reports false without consuming anything once the budget is exhausted.
+func (b *RetryBudget) Take(now time.Time) bool {
+	if b.Exhausted(now) {
+		return false
+	}
+	if b != nil {
+		b.Attempts--
+	}
+	return true
+}
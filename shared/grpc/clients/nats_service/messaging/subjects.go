@@ -3,7 +3,7 @@ package messaging
 // Subjects hold the NATS subjects used for inter-microservice communication.
 const (
 	// ProxyUrlRequest is the subject on which the proxy-service microservice listens for incoming URL requests.
-	// Each message published to this subject should contain a valid URL that needs to be processed.
+	// Each message published to this subject should contain a FetchRequest, or a valid URL that needs to be processed.
 	ProxyUrlRequest = "proxy.url.request"
 
 	// ProxyUrlResponse is the subject on which the proxy-service microservice publishes the results
@@ -11,6 +11,10 @@ const (
 	// Other microservices can subscribe to this subject to receive the processed data.
 	ProxyUrlResponse = "proxy.url.response"
 
+	// ProxyUrlError is the subject on which the proxy-service microservice publishes a FetchError
+	// for every URL it failed to fetch once the retries are exhausted.
+	ProxyUrlError = "proxy.url.error"
+
 	// UrlIncoming is the subject on which the url-service microservice listens for incoming URL messages.
 	// The background job will subscribe to this subject and process the messages accordingly.
 	UrlIncoming = "url.incoming"
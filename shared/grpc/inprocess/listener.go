@@ -0,0 +1,121 @@
+package inprocess
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Scheme prefixes the addresses of in-process listeners, e.g., "inprocess://nats-service".
+const Scheme = "inprocess://"
+
+var (
+	// ErrClosed is returned when accepting on, or dialing, a closed listener.
+	ErrClosed = errors.New("inprocess: listener closed")
+	// ErrNotFound is returned when dialing a name no listener is registered under.
+	ErrNotFound = errors.New("inprocess: no listener registered")
+
+	registryMu sync.Mutex
+	registry   = make(map[string]*Listener)
+)
+
+// Listener is an in-memory net.Listener, connections are synchronous pipes and never touch the network.
+// It serves the same purpose as google.golang.org/grpc/test/bufconn, which is not part of the gRPC module.
+type Listener struct {
+	name  string        // name is the registered name of the listener.
+	conns chan net.Conn // conns hands the server side of dialed pipes to Accept.
+	done  chan struct{} // done is closed once the listener is closed.
+	once  sync.Once     // once ensures Close is executed only once.
+}
+
+// Listen registers a new listener under name, a name can be registered by a single listener at a time.
+func Listen(name string) (listener *Listener, err error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		return nil, fmt.Errorf("inprocess: listener %q already registered", name)
+	}
+	listener = &Listener{
+		name:  name,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+	registry[name] = listener
+	return listener, nil
+}
+
+// Accept waits for and returns the next dialed connection.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, ErrClosed
+	}
+}
+
+// Close unregisters the listener, established connections are not affected.
+func (l *Listener) Close() error {
+	l.once.Do(func() {
+		registryMu.Lock()
+		delete(registry, l.name)
+		registryMu.Unlock()
+		close(l.done)
+	})
+	return nil
+}
+
+// Addr returns the in-process address of the listener.
+func (l *Listener) Addr() net.Addr { return addr(l.name) }
+
+// DialContext connects to the listener, blocking until it accepts the connection or ctx is done.
+func (l *Listener) DialContext(ctx context.Context) (net.Conn, error) {
+	client, server := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.done:
+		_, _ = client.Close(), server.Close()
+		return nil, ErrClosed
+	case <-ctx.Done():
+		_, _ = client.Close(), server.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// Dial connects to the listener registered under name, its signature matches grpc.WithContextDialer.
+// The name may be given with or without the Scheme prefix.
+func Dial(ctx context.Context, name string) (net.Conn, error) {
+	registryMu.Lock()
+	listener, ok := registry[strings.TrimPrefix(name, Scheme)]
+	registryMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, name)
+	}
+	return listener.DialContext(ctx)
+}
+
+// Address returns the in-process address of name.
+func Address(name string) string { return Scheme + name }
+
+// Name returns the listener name of an in-process address, ok is false for any other address.
+func Name(address string) (name string, ok bool) {
+	if !strings.HasPrefix(address, Scheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(address, Scheme), true
+}
+
+// addr implements net.Addr for in-process listeners.
+type addr string
+
+// Network returns the name of the network.
+func (a addr) Network() string { return "inprocess" }
+
+// String returns the in-process address.
+func (a addr) String() string { return Address(string(a)) }
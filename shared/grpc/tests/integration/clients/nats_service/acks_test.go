@@ -0,0 +1,70 @@
+package nats_service
+
+import (
+	"context"
+	"errors"
+	"shared/grpc/tests/integration/clients/nats_service/server"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNatsClient_SubscribeAck verifies that a chunked message of a SubscribeAck stream is reassembled, and acked
+// on the stream once the handler processed it.
+func TestNatsClient_SubscribeAck(t *testing.T) {
+	var (
+		env       = SetupTestEnvironment(t)
+		subject   = "test.ack.chunked"
+		chunkSize = 1024
+		data      = make([]byte, chunkSize*2+chunkSize/2)
+		received  = make(chan []byte, 2)
+	)
+
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	env.Mock.SetChunkSize(chunkSize)
+	require.NoError(t, env.Client.Publish(context.Background(), subject, data), "Expected successful publish")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(3)*time.Second)
+	defer cancel()
+
+	err := env.Client.SubscribeAck(ctx, subject, "", 0, func(data []byte, topic string) error {
+		assert.Equal(t, subject, topic, "Subject mismatch")
+		received <- data
+		return nil
+	})
+	require.NoError(t, err, "Expected the stream to end cleanly once the message is acked")
+	require.Len(t, received, 1, "Expected a single, reassembled message")
+	assert.Equal(t, data, <-received, "Reassembled payload does not match published data")
+	assert.Equal(t, 1, env.Mock.Acks(server.MockMessageId), "Expected the message to be acked once")
+}
+
+// TestNatsClient_SubscribeAckHandlerError verifies that a message the handler fails is not acked, and is processed
+// again once delivered again.
+func TestNatsClient_SubscribeAckHandlerError(t *testing.T) {
+	var (
+		env      = SetupTestEnvironment(t)
+		subject  = "test.ack.retry"
+		attempts int
+	)
+	require.NoError(t, env.Client.Publish(context.Background(), subject, []byte("data")),
+		"Expected successful publish")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(3)*time.Second)
+	defer cancel()
+
+	err := env.Client.SubscribeAck(ctx, subject, "", 0, func(data []byte, topic string) error {
+		attempts++
+		if attempts == 1 {
+			assert.Zero(t, env.Mock.Acks(server.MockMessageId), "Expected no ack before the handler returned")
+			return errors.New("processing failed")
+		}
+		return nil
+	})
+	require.NoError(t, err, "Expected the stream to end cleanly once the message is acked")
+	assert.Equal(t, 2, attempts, "Expected the failed message to be delivered again")
+	assert.Equal(t, 1, env.Mock.Acks(server.MockMessageId), "Expected only the successful attempt to be acked")
+}
@@ -2,6 +2,7 @@ package nats_service
 
 import (
 	"context"
+	"shared/grpc/clients/nats_service"
 	"sync"
 	"testing"
 	"time"
@@ -182,3 +183,127 @@ func TestNatsClient_Subscribe_MultipleSubscribers(t *testing.T) {
 		t.Logf("Received message at index %d: %s", res.index, string(res.msg))
 	}
 }
+
+// TestNatsClient_Subscribe_ChunkedPayload verifies that a payload delivered in several chunks is reassembled.
+func TestNatsClient_Subscribe_ChunkedPayload(t *testing.T) {
+	var (
+		env       = SetupTestEnvironment(t)
+		subject   = "test.chunked.payload"
+		chunkSize = 1024
+		data      = make([]byte, chunkSize*4+chunkSize/2)
+		err       error
+	)
+
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	env.Mock.SetChunkSize(chunkSize)
+
+	err = env.Client.Publish(context.Background(), subject, data)
+	require.NoError(t, err, "Expected successful publish")
+
+	var (
+		received = make(chan []byte, 5)
+		subErr   = make(chan error, 1)
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(3)*time.Second)
+	defer cancel()
+
+	go func() {
+		subErr <- env.Client.Subscribe(ctx, subject, "", func(data []byte, topic string) {
+			assert.Equal(t, subject, topic, "Subject mismatch")
+			received <- data
+		})
+	}()
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, data, msg, "Reassembled payload does not match published data")
+	case err = <-subErr:
+		t.Fatalf("Subscription failed: %v", err)
+	case <-ctx.Done():
+		t.Fatal("Did not receive the reassembled message in time")
+	}
+
+	// Only a single, complete message must be delivered.
+	err = <-subErr
+	require.NoError(t, err, "Expected subscription to end cleanly")
+	assert.Empty(t, received, "Expected chunks not to be delivered individually")
+}
+
+// TestNatsClient_Subscribe_ChunkLimits verifies that a chunked message beyond the chunk count or the reassembly
+// byte budget of the client is dropped instead of being buffered and delivered.
+func TestNatsClient_Subscribe_ChunkLimits(t *testing.T) {
+	const chunkSize = 1024
+
+	tests := []struct {
+		name      string
+		maxChunks int
+		maxBytes  int
+	}{
+		{name: "chunk count", maxChunks: 4},
+		{name: "byte budget", maxBytes: chunkSize * 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				container = NewTestContainer()
+				mock      = container.MockBusServiceServer.Get()
+				subject   = "test.chunked.limits"
+				data      = make([]byte, chunkSize*4+chunkSize/2)
+				received  = make(chan []byte, 1)
+			)
+			t.Cleanup(container.TestServerContainer.Get().Stop)
+			mock.SetChunkSize(chunkSize)
+
+			client, err := nats_service.NewNatsClient("dev", container.TestServerContainer.Get().Address,
+				container.NatsValidator.Get(), container.Logger.Get(),
+				nats_service.WithChunkLimits(tt.maxChunks, tt.maxBytes))
+			require.NoError(t, err, "Failed to create NATS client")
+			t.Cleanup(func() { _ = client.Close() })
+
+			require.NoError(t, client.Publish(context.Background(), subject, data), "Expected successful publish")
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(3)*time.Second)
+			defer cancel()
+			err = client.Subscribe(ctx, subject, "", func(data []byte, topic string) { received <- data })
+			require.NoError(t, err, "Expected subscription to end cleanly")
+			assert.Empty(t, received, "Expected the message beyond the limits to be dropped")
+		})
+	}
+}
+
+// TestNatsClient_Subscribe_CancelSilentSubject verifies that canceling the context interrupts a blocked receive
+// and that the subscription returns promptly with the context error.
+func TestNatsClient_Subscribe_CancelSilentSubject(t *testing.T) {
+	var (
+		env     = SetupTestEnvironment(t)
+		subject = "test.silent"
+		subErr  = make(chan error, 1)
+	)
+	env.Mock.SetSilent(subject)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		subErr <- env.Client.Subscribe(ctx, subject, "", func(data []byte, topic string) {
+			t.Errorf("Unexpected message on silent subject %s", topic)
+		})
+	}()
+
+	// Let the subscription block on the silent stream before canceling it.
+	time.Sleep(time.Duration(200) * time.Millisecond)
+	canceledAt := time.Now()
+	cancel()
+
+	select {
+	case err := <-subErr:
+		require.ErrorIs(t, err, context.Canceled, "Expected the context error")
+		assert.Less(t, time.Since(canceledAt), time.Duration(500)*time.Millisecond, "Expected a prompt return")
+	case <-time.After(time.Duration(3) * time.Second):
+		t.Fatal("Subscription did not return after cancellation")
+	}
+}
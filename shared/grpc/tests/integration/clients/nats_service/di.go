@@ -15,6 +15,10 @@ type TestContainer struct {
 	TestServerContainer  dependency.LazyDependency[*server.TestServerContainer]
 	NatsValidator        dependency.LazyDependency[nats_service.Validator]
 	NatsClient           dependency.LazyDependency[*nats_service.NatsClient]
+
+	// In-process transport, the client reaches the mock server without a network listener.
+	InProcessServerContainer dependency.LazyDependency[*server.TestServerContainer]
+	InProcessNatsClient      dependency.LazyDependency[*nats_service.NatsClient]
 }
 
 // NewTestContainer initializes the test container.
@@ -62,6 +66,35 @@ func NewTestContainer() *TestContainer {
 			return natsClient
 		},
 	}
+	c.InProcessServerContainer = dependency.LazyDependency[*server.TestServerContainer]{
+		InitFunc: func() *server.TestServerContainer {
+			var (
+				testServer *server.TestServerContainer
+				name       = nats_service.InProcessName
+				err        error
+			)
+			if testServer, err = server.NewInProcessTestServerContainer(name, c.MockBusServiceServer.Get()); err != nil {
+				panic(err)
+			}
+			return testServer
+		},
+	}
+	c.InProcessNatsClient = dependency.LazyDependency[*nats_service.NatsClient]{
+		InitFunc: func() *nats_service.NatsClient {
+			var (
+				logger     = c.Logger.Get()
+				address    = c.InProcessServerContainer.Get().Address
+				validator  = c.NatsValidator.Get()
+				natsClient *nats_service.NatsClient
+				env        = "prod" // In-process connections never use TLS.
+				err        error
+			)
+			if natsClient, err = nats_service.NewNatsClient(env, address, validator, logger); err != nil {
+				panic(err)
+			}
+			return natsClient
+		},
+	}
 
 	return c
 }
@@ -0,0 +1,94 @@
+package nats_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNatsClient_PublishAll verifies that a fanned-out message is received on each subject
+// and that the outcome of every publish is reported.
+func TestNatsClient_PublishAll(t *testing.T) {
+	var (
+		env      = SetupTestEnvironment(t)
+		subjects = []string{"test.fanout.results", "test.fanout.audit", "test.fanout.archive"}
+		data     = []byte("Hello fan-out")
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(3)*time.Second)
+	defer cancel()
+
+	results, err := env.Client.PublishAll(ctx, subjects, data)
+	require.NoError(t, err, "Expected successful fan-out publish")
+	require.Len(t, results, len(subjects), "Expected a result per subject")
+	for i, result := range results {
+		require.Equal(t, subjects[i], result.Subject, "Results should follow the order of the subjects")
+		require.NoError(t, result.Err, "Expected successful publish to %s", result.Subject)
+	}
+	require.Equal(t, len(subjects), env.Mock.Published(), "Expected a publish per subject")
+
+	for _, subject := range subjects {
+		received := make(chan []byte, 1)
+		subErr := make(chan error, 1)
+		go func() {
+			subErr <- env.Client.Subscribe(ctx, subject, "", func(data []byte, topic string) {
+				received <- data
+			})
+		}()
+
+		select {
+		case msg := <-received:
+			require.Equal(t, data, msg, "Message received on %s does not match published data", subject)
+		case err = <-subErr:
+			t.Fatalf("Subscription to %s failed: %v", subject, err)
+		case <-ctx.Done():
+			t.Fatalf("Did not receive the message on %s in time", subject)
+		}
+	}
+}
+
+// TestNatsClient_PublishAll_PartialFailure verifies that a failed publish does not stop the others
+// and is reported against its subject.
+func TestNatsClient_PublishAll_PartialFailure(t *testing.T) {
+	var (
+		env      = SetupTestEnvironment(t)
+		subjects = []string{"test.fanout.results", "test.fanout.audit", "test.fanout.archive"}
+		data     = []byte("Hello fan-out")
+	)
+	env.Mock.FailPublishes(1)
+
+	results, err := env.Client.PublishAll(context.Background(), subjects, data)
+	require.Error(t, err, "Expected the failed publish to be reported")
+	require.Len(t, results, len(subjects), "Expected a result per subject")
+
+	var failed int
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			require.Contains(t, err.Error(), result.Subject, "Error should name the failed subject")
+		}
+	}
+	require.Equal(t, 1, failed, "Expected exactly one failed publish")
+	require.Equal(t, len(subjects)-1, env.Mock.Published(), "Expected the other publishes to succeed")
+}
+
+// TestNatsClient_PublishAll_InvalidSubject verifies that an invalid or duplicated subject publishes nothing.
+func TestNatsClient_PublishAll_InvalidSubject(t *testing.T) {
+	var (
+		env  = SetupTestEnvironment(t)
+		data = []byte("Hello fan-out")
+	)
+
+	for _, subjects := range [][]string{
+		{"test.fanout.results", ""},
+		{"test.fanout.results", "test.fanout.results"},
+		nil,
+	} {
+		results, err := env.Client.PublishAll(context.Background(), subjects, data)
+		require.Error(t, err, "Expected subjects %q to be rejected", subjects)
+		require.Nil(t, results, "Expected no results for rejected subjects %q", subjects)
+	}
+	require.Zero(t, env.Mock.Published(), "Expected nothing to be published")
+}
@@ -0,0 +1,49 @@
+package nats_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestNatsClient_InProcess_Publish_Subscribe verifies that a client wired to an in-process server publishes
+// and receives messages over the in-memory connection, without a network listener.
+func TestNatsClient_InProcess_Publish_Subscribe(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		grpcServer = container.InProcessServerContainer.Get()
+		client     = container.InProcessNatsClient.Get()
+		subject    = "test.inprocess"
+		data       = []byte("Hello in-process Nats")
+		received   = make(chan []byte, 1)
+		subErr     = make(chan error, 1)
+	)
+	t.Cleanup(func() {
+		_ = client.Close()
+		grpcServer.Stop()
+	})
+	require.Equal(t, "inprocess", grpcServer.Network(), "Server must not listen on the network")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(3)*time.Second)
+	defer cancel()
+
+	require.NoError(t, client.Publish(ctx, subject, data), "Expected successful in-process publish")
+	require.Equal(t, 1, container.MockBusServiceServer.Get().Published(), "Publish did not reach the server")
+
+	go func() {
+		subErr <- client.Subscribe(ctx, subject, "", func(data []byte, topic string) {
+			received <- data
+		})
+	}()
+
+	select {
+	case msg := <-received:
+		require.Equal(t, data, msg, "Received message does not match published data")
+	case err := <-subErr:
+		t.Fatalf("Subscription failed: %v", err)
+	case <-ctx.Done():
+		t.Fatal("Did not receive the published message in time")
+	}
+}
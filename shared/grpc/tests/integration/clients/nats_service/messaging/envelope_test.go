@@ -0,0 +1,167 @@
+package messaging
+
+import (
+	"shared/grpc/clients/nats_service/messaging"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEnvelope_RoundTrip verifies that v1 and v2 envelopes survive encoding and decoding.
+func TestEnvelope_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope messaging.Envelope
+		expected messaging.Envelope
+	}{
+		{
+			name:     "v1 raw",
+			envelope: messaging.Envelope{Version: messaging.Version1, Payload: []byte("https://example.com")},
+			expected: messaging.Envelope{Version: messaging.Version1, Payload: []byte("https://example.com")},
+		},
+		{
+			name:     "v2 json",
+			envelope: messaging.Envelope{Version: messaging.Version2, Format: messaging.FormatJSON, Payload: []byte(`{"a":1}`)},
+			expected: messaging.Envelope{Version: messaging.Version2, Format: messaging.FormatJSON, Payload: []byte(`{"a":1}`)},
+		},
+		{
+			name:     "default version",
+			envelope: messaging.Envelope{Format: messaging.FormatGzip, Payload: []byte{0x1f, 0x8b}},
+			expected: messaging.Envelope{Version: messaging.CurrentVersion, Format: messaging.FormatGzip, Payload: []byte{0x1f, 0x8b}},
+		},
+		{
+			name:     "v2 empty payload",
+			envelope: messaging.Envelope{Version: messaging.Version2},
+			expected: messaging.Envelope{Version: messaging.Version2, Payload: []byte{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := messaging.Encode(test.envelope)
+			require.NoError(t, err, "Failed to encode envelope")
+
+			decoded, err := messaging.Decode(data)
+			require.NoError(t, err, "Failed to decode envelope")
+			assert.Equal(t, test.expected, decoded)
+		})
+	}
+}
+
+// TestEnvelope_UnknownVersion verifies that unknown versions are rejected on both encoding and decoding.
+func TestEnvelope_UnknownVersion(t *testing.T) {
+	_, err := messaging.Encode(messaging.Envelope{Version: 9, Payload: []byte("data")})
+	require.ErrorIs(t, err, messaging.ErrUnknownVersion)
+
+	data, err := messaging.Encode(messaging.Envelope{Version: messaging.Version1, Payload: []byte("data")})
+	require.NoError(t, err, "Failed to encode envelope")
+	data[2] = 9
+
+	_, err = messaging.Decode(data)
+	require.ErrorIs(t, err, messaging.ErrUnknownVersion)
+	_, _, err = messaging.Unwrap(data)
+	require.ErrorIs(t, err, messaging.ErrUnknownVersion)
+}
+
+// TestEnvelope_Invalid verifies that invalid formats and truncated envelopes are rejected.
+func TestEnvelope_Invalid(t *testing.T) {
+	_, err := messaging.Encode(messaging.Envelope{Version: messaging.Version1, Format: messaging.FormatJSON})
+	require.ErrorIs(t, err, messaging.ErrUnknownFormat, "Expected v1 to reject non-raw payloads")
+
+	data, err := messaging.Encode(messaging.Envelope{Version: messaging.Version2, Payload: []byte("data")})
+	require.NoError(t, err, "Failed to encode envelope")
+
+	_, err = messaging.Decode(data[:3])
+	require.ErrorIs(t, err, messaging.ErrTruncated)
+
+	data[3] = 0xff
+	_, err = messaging.Decode(data)
+	require.ErrorIs(t, err, messaging.ErrUnknownFormat)
+}
+
+// TestEnvelope_Unwrap verifies that legacy messages without an envelope are returned as raw payloads.
+func TestEnvelope_Unwrap(t *testing.T) {
+	legacy := []byte("https://example.com")
+	payload, format, err := messaging.Unwrap(legacy)
+	require.NoError(t, err)
+	assert.Equal(t, legacy, payload)
+	assert.Equal(t, messaging.FormatRaw, format)
+
+	_, err = messaging.Decode(legacy)
+	require.ErrorIs(t, err, messaging.ErrNotEnveloped)
+
+	data, err := messaging.Encode(messaging.Envelope{Format: messaging.FormatJSON, Payload: []byte(`{}`)})
+	require.NoError(t, err, "Failed to encode envelope")
+	payload, format, err = messaging.Unwrap(data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{}`), payload)
+	assert.Equal(t, messaging.FormatJSON, format)
+}
+
+// TestEnvelope_PublishedAt verifies that v3 envelopes carry the publish time and report the lag of the message.
+func TestEnvelope_PublishedAt(t *testing.T) {
+	publishedAt := time.Unix(1700000000, 123456789)
+	data, err := messaging.Encode(messaging.Envelope{Format: messaging.FormatRaw, Payload: []byte("https://example.com"),
+		PublishedAt: publishedAt})
+	require.NoError(t, err, "Failed to encode envelope")
+
+	envelope, err := messaging.Decode(data)
+	require.NoError(t, err, "Failed to decode envelope")
+	assert.Equal(t, messaging.CurrentVersion, envelope.Version)
+	assert.True(t, publishedAt.Equal(envelope.PublishedAt), "Publish time not preserved")
+
+	lag, ok := envelope.Lag(publishedAt.Add(time.Duration(250) * time.Millisecond))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(250)*time.Millisecond, lag)
+
+	lag, ok = envelope.Lag(publishedAt.Add(-time.Second))
+	assert.True(t, ok)
+	assert.Zero(t, lag, "Clock skew should not report a negative lag")
+
+	legacy, err := messaging.Open([]byte("https://example.com"))
+	require.NoError(t, err)
+	_, ok = legacy.Lag(publishedAt)
+	assert.False(t, ok, "Legacy messages have no publish time")
+}
+
+// TestEnvelope_RetryBudget verifies that v4 envelopes carry the retry budget, and that a message without one
+// decodes without a budget.
+func TestEnvelope_RetryBudget(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	budget := messaging.NewRetryBudget(3, time.Duration(30)*time.Second, now)
+	data, err := messaging.Encode(messaging.Envelope{Payload: []byte("https://example.com"), Retry: budget})
+	require.NoError(t, err, "Failed to encode envelope")
+
+	envelope, err := messaging.Decode(data)
+	require.NoError(t, err, "Failed to decode envelope")
+	require.NotNil(t, envelope.Retry, "Retry budget not preserved")
+	assert.Equal(t, 3, envelope.Retry.Attempts)
+	assert.True(t, budget.Deadline.Equal(envelope.Retry.Deadline), "Retry deadline not preserved")
+
+	data, err = messaging.Encode(messaging.Envelope{Payload: []byte("https://example.com")})
+	require.NoError(t, err, "Failed to encode envelope")
+	envelope, err = messaging.Decode(data)
+	require.NoError(t, err, "Failed to decode envelope")
+	assert.Nil(t, envelope.Retry, "Unexpected retry budget")
+}
+
+// TestRetryBudget_Take verifies that retries are consumed until the attempts or the deadline run out,
+// and that a nil budget never runs out.
+func TestRetryBudget_Take(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	budget := messaging.NewRetryBudget(2, time.Duration(10)*time.Second, now)
+	assert.True(t, budget.Take(now))
+	assert.True(t, budget.Take(now))
+	assert.False(t, budget.Take(now), "Attempts exhausted")
+	assert.Equal(t, 0, budget.Attempts)
+
+	budget = messaging.NewRetryBudget(5, time.Duration(10)*time.Second, now)
+	assert.False(t, budget.Take(now.Add(time.Duration(10)*time.Second)), "Deadline passed")
+	assert.Equal(t, 5, budget.Attempts, "An exhausted budget is not consumed")
+
+	var unlimited *messaging.RetryBudget
+	assert.True(t, unlimited.Take(now))
+	assert.False(t, unlimited.Exhausted(now))
+}
@@ -0,0 +1,66 @@
+package messaging
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"shared/grpc/clients/nats_service/messaging"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// subjectsFile is the source file declaring the subject constants.
+const subjectsFile = "../../../../../clients/nats_service/messaging/subjects.go"
+
+// declaredSubjects parses the subject constants declared in subjectsFile.
+func declaredSubjects(t *testing.T) (subjects []string) {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), subjectsFile, nil, 0)
+	require.NoError(t, err, "Failed to parse the subjects file")
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if literal, ok := node.(*ast.BasicLit); ok && literal.Kind == token.STRING {
+			subject, err := strconv.Unquote(literal.Value)
+			require.NoError(t, err, "Failed to unquote subject %s", literal.Value)
+			subjects = append(subjects, subject)
+		}
+		return true
+	})
+	require.NotEmpty(t, subjects, "No subjects declared")
+	return subjects
+}
+
+// TestRegistry_Subjects verifies that every declared subject is registered exactly once with its metadata.
+func TestRegistry_Subjects(t *testing.T) {
+	declared := declaredSubjects(t)
+
+	for _, name := range declared {
+		subject, ok := messaging.Lookup(name)
+		require.True(t, ok, "Subject %q is not registered", name)
+		require.True(t, messaging.Registered(name), "Subject %q should be reported as registered", name)
+		require.NotEmpty(t, subject.Description, "Subject %q has no description", name)
+		require.NotEmpty(t, subject.Direction.Publisher, "Subject %q has no publisher", name)
+		require.NotEmpty(t, subject.Direction.Subscriber, "Subject %q has no subscriber", name)
+	}
+	require.ElementsMatch(t, declared, messaging.Names(), "Registered subjects should match the declared ones")
+	require.Len(t, messaging.Subjects(), len(declared), "Subjects should be registered once")
+	require.False(t, messaging.Registered("unknown.subject"), "Unknown subject should not be registered")
+}
+
+// TestRegistry_Dump verifies that the dump lists every registered subject with its direction.
+func TestRegistry_Dump(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, messaging.Dump(&buffer), "Failed to dump the registry")
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, len(messaging.Subjects())+1, "Dump should list a header and every subject")
+	for i, subject := range messaging.Subjects() {
+		require.True(t, strings.HasPrefix(lines[i+1], subject.Name+" "), "Line %d should list %q", i+1, subject.Name)
+		require.Contains(t, lines[i+1], subject.Direction.String(), "Line %d should list the direction", i+1)
+	}
+}
@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"net/http"
+	"shared/grpc/clients/nats_service/messaging"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFetchRequest verifies that JSON payloads are decoded as fetch requests and plain URLs as GET requests.
+func TestParseFetchRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected messaging.FetchRequest
+	}{
+		{
+			name:     "plain URL",
+			payload:  "https://example.com/page",
+			expected: messaging.FetchRequest{Method: http.MethodGet, URL: "https://example.com/page"},
+		},
+		{
+			name:     "default method",
+			payload:  `{"url":"https://example.com/page"}`,
+			expected: messaging.FetchRequest{Method: http.MethodGet, URL: "https://example.com/page"},
+		},
+		{
+			name: "post with headers and body",
+			payload: ` {"method":"POST","url":"https://example.com/api",` +
+				`"headers":{"Content-Type":"application/json"},"body":"{}"}`,
+			expected: messaging.FetchRequest{
+				Method:  http.MethodPost,
+				URL:     "https://example.com/api",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    "{}",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request, err := messaging.ParseFetchRequest([]byte(test.payload))
+			require.NoError(t, err, "Failed to parse fetch request")
+			require.Equal(t, test.expected, request)
+		})
+	}
+
+	_, err := messaging.ParseFetchRequest([]byte(`{"url":`))
+	require.ErrorIs(t, err, messaging.ErrInvalidFetchRequest, "Expected malformed JSON to be rejected")
+}
@@ -6,6 +6,7 @@ import (
 	natsservicev1 "shared/proto/nats-service/gen"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -15,12 +16,49 @@ import (
 // MockBusService is a mock implementation of BusServiceServer for testing.
 type MockBusService struct {
 	natsservicev1.UnimplementedBusServiceServer
-	messages sync.Map // Concurrent map for storing messages
+	messages  sync.Map     // Concurrent map for storing messages
+	chunkSize int          // Maximum payload size of a single streamed response (0 disables chunking)
+	silent    sync.Map     // Subjects whose streams stay open without sending any message
+	failures  atomic.Int32 // Number of upcoming Publish calls that fail
+	published atomic.Int32 // Number of successful Publish calls
+	acks      sync.Map     // Acked message ids of SubscribeAck streams with their ack count
+}
+
+// MockMessageId is the id of the messages streamed by SubscribeAck and of chunked messages.
+const MockMessageId = "mock-message"
+
+// redeliveryDelay is how long SubscribeAck waits for the ack of a message before streaming it again.
+const redeliveryDelay = time.Duration(200) * time.Millisecond
+
+// sender is the sending side of the Subscribe and SubscribeAck streams.
+type sender interface {
+	Send(*natsservicev1.SubscribeResponse) error
 }
 
 // NewMockBusService creates a new instance of MockBusService.
 func NewMockBusService() *MockBusService { return &MockBusService{} }
 
+// SetChunkSize enables chunked delivery of messages larger than size.
+func (m *MockBusService) SetChunkSize(size int) { m.chunkSize = size }
+
+// SetSilent keeps the streams of the subject open without sending any message until the client cancels.
+func (m *MockBusService) SetSilent(subject string) { m.silent.Store(subject, struct{}{}) }
+
+// FailPublishes makes the next n Publish calls fail with an Unavailable error.
+func (m *MockBusService) FailPublishes(n int) { m.failures.Store(int32(n)) }
+
+// Published returns the number of successful Publish calls.
+func (m *MockBusService) Published() int { return int(m.published.Load()) }
+
+// Acks returns how many times the message with id was acked.
+func (m *MockBusService) Acks(id string) int {
+	count, ok := m.acks.Load(id)
+	if !ok {
+		return 0
+	}
+	return count.(int)
+}
+
 // Publish simulates message publishing.
 func (m *MockBusService) Publish(
 	ctx context.Context,
@@ -33,9 +71,13 @@ func (m *MockBusService) Publish(
 		if vErr := m.validatePublishRequest(request); vErr != nil {
 			return nil, vErr
 		}
+		if m.failures.Add(-1) >= 0 {
+			return nil, status.Error(codes.Unavailable, "injected publish failure")
+		}
 
 		// Store the message
 		m.messages.Store(request.GetSubject(), request.GetData())
+		m.published.Add(1)
 
 		return &natsservicev1.PublishResponse{
 			Success: true,
@@ -53,6 +95,12 @@ func (m *MockBusService) Subscribe(
 		return vErr
 	}
 
+	// Hold silent subscriptions open until the client goes away
+	if _, silent := m.silent.Load(request.GetSubject()); silent {
+		<-stream.Context().Done()
+		return stream.Context().Err()
+	}
+
 	// Retrieve message
 	data, exists := m.getMessage(request.GetSubject())
 	if !exists {
@@ -62,9 +110,74 @@ func (m *MockBusService) Subscribe(
 	// Simulate streaming a message with a delay to mimic real NATS behavior
 	time.Sleep(time.Duration(500) * time.Millisecond)
 
+	return m.send(stream, request.GetSubject(), data, "")
+}
+
+// SubscribeAck simulates an acked subscription: the stored message is streamed with an id, streamed again while
+// its ack does not arrive within the redelivery delay, and the stream ends once it is acked.
+func (m *MockBusService) SubscribeAck(stream natsservicev1.BusService_SubscribeAckServer) (err error) {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	request := first.GetSubscribe()
+	if request == nil {
+		return status.Error(codes.InvalidArgument, "subscribe request required")
+	}
+	if vErr := m.validateSubscribeRequest(request); vErr != nil {
+		return vErr
+	}
+
+	data, exists := m.getMessage(request.GetSubject())
+	if !exists {
+		return status.Error(codes.NotFound, "message not found")
+	}
+
+	acks := make(chan string, 1)
+	go func() {
+		defer close(acks)
+		for {
+			frame, rErr := stream.Recv()
+			if rErr != nil {
+				return
+			}
+			select {
+			case acks <- frame.GetAck().GetMessageId():
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		if err = m.send(stream, request.GetSubject(), data, MockMessageId); err != nil {
+			return err
+		}
+		select {
+		case id, ok := <-acks:
+			if !ok {
+				return nil
+			}
+			count, _ := m.acks.LoadOrStore(id, 0)
+			m.acks.Store(id, count.(int)+1)
+			return nil
+		case <-time.After(redeliveryDelay):
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// send streams a message, chunked when it is larger than chunkSize.
+func (m *MockBusService) send(stream sender, subject string, data []byte, id string) (err error) {
+	if m.chunkSize > 0 && len(data) > m.chunkSize {
+		return m.sendChunks(stream, subject, data)
+	}
+
 	response := &natsservicev1.SubscribeResponse{
-		Data:    data,
-		Subject: request.GetSubject(),
+		Data:      data,
+		Subject:   subject,
+		MessageId: id,
 	}
 
 	if err = stream.Send(response); err != nil {
@@ -74,6 +187,30 @@ func (m *MockBusService) Subscribe(
 	return nil
 }
 
+// sendChunks streams a message split into chunks of at most chunkSize bytes.
+func (m *MockBusService) sendChunks(stream sender, subject string, data []byte) (err error) {
+	total := (len(data) + m.chunkSize - 1) / m.chunkSize
+
+	for sequence := 0; sequence < total; sequence++ {
+		var (
+			start    = sequence * m.chunkSize
+			end      = min(start+m.chunkSize, len(data))
+			response = &natsservicev1.SubscribeResponse{
+				Data:      data[start:end],
+				Subject:   subject,
+				MessageId: MockMessageId,
+				Sequence:  uint32(sequence),
+				Total:     uint32(total),
+			}
+		)
+		if err = stream.Send(response); err != nil {
+			return fmt.Errorf("could not send chunk to stream: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // getMessage retrieves a stored message from sync.Map.
 func (m *MockBusService) getMessage(subject string) (message []byte, exists bool) {
 	value, ok := m.messages.Load(subject)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"shared/grpc/inprocess"
 	natsservicev1 "shared/proto/nats-service/gen"
 
 	"google.golang.org/grpc"
@@ -45,6 +46,42 @@ func NewTestServerContainer(busServer natsservicev1.BusServiceServer) (container
 	}, nil
 }
 
+// NewInProcessTestServerContainer initializes and starts a test gRPC server on an in-process listener.
+// No network listener is created, clients dial the returned Address.
+func NewInProcessTestServerContainer(
+	name string,
+	busServer natsservicev1.BusServiceServer,
+) (container *TestServerContainer, err error) {
+	var (
+		listener   *inprocess.Listener
+		grpcServer *grpc.Server
+	)
+
+	if listener, err = inprocess.Listen(name); err != nil {
+		return nil, fmt.Errorf("could not listen : %w", err)
+	}
+
+	grpcServer = grpc.NewServer()
+	natsservicev1.RegisterBusServiceServer(grpcServer, busServer)
+
+	go func() {
+		if err = grpcServer.Serve(listener); err != nil {
+			log.Fatalf("could not serve : %v", err)
+		}
+	}()
+
+	return &TestServerContainer{
+		grpcServer: grpcServer,
+		listener:   listener,
+		Address:    listener.Addr().String(),
+	}, nil
+}
+
+// Network returns the network of the server listener, "inprocess" for in-process servers.
+func (s *TestServerContainer) Network() string {
+	return s.listener.Addr().Network()
+}
+
 // Stop gracefully stops the test gRPC server.
 func (s *TestServerContainer) Stop() {
 	s.grpcServer.GracefulStop()
@@ -8,6 +8,7 @@ import (
 
 // TestEnvironment encapsulates the mock server and gRPC client for integration tests.
 type TestEnvironment struct {
+	Mock   *server.MockBusService
 	Server *server.TestServerContainer
 	Client *nats_service.NatsClient
 }
@@ -25,6 +26,7 @@ func SetupTestEnvironment(t *testing.T) *TestEnvironment {
 	})
 
 	return &TestEnvironment{
+		Mock:   container.MockBusServiceServer.Get(),
 		Server: grpcServer,
 		Client: grpcClient,
 	}
@@ -0,0 +1,99 @@
+package nats_service
+
+import (
+	"context"
+	"shared/grpc/clients/nats_service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamsClient creates an in-process client limited to a single concurrent Subscribe stream.
+func newStreamsClient(t *testing.T, container *TestContainer, queue bool) *nats_service.NatsClient {
+	client, err := nats_service.NewNatsClient("dev", container.InProcessServerContainer.Get().Address,
+		container.NatsValidator.Get(), container.Logger.Get(), nats_service.WithMaxStreams(1, queue))
+	require.NoError(t, err, "Failed to create NATS client")
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+// holdStream opens a subscription to a silent subject and waits until it holds the only stream slot of client.
+func holdStream(t *testing.T, client *nats_service.NatsClient, subject string) (release func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = client.Subscribe(ctx, subject, "", func(data []byte, subject string) {})
+	}()
+
+	require.Eventually(t, func() bool { return client.Streams() == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Subscription did not take the slot")
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// TestNatsClient_MaxStreams_Reject verifies that a Subscribe beyond the stream limit fails fast with
+// ErrTooManyStreams, and that the slot of an ended subscription is reused.
+func TestNatsClient_MaxStreams_Reject(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		grpcServer = container.InProcessServerContainer.Get()
+		subject    = "test.streams.reject"
+	)
+	t.Cleanup(grpcServer.Stop)
+	container.MockBusServiceServer.Get().SetSilent(subject)
+	client := newStreamsClient(t, container, false)
+
+	release := holdStream(t, client, subject)
+	err := client.Subscribe(context.Background(), subject, "", func(data []byte, subject string) {})
+	require.ErrorIs(t, err, nats_service.ErrTooManyStreams, "Expected the excess subscription to be rejected")
+
+	release()
+	require.Zero(t, client.Streams(), "Expected the ended subscription to free its slot")
+	release = holdStream(t, client, subject)
+	release()
+}
+
+// TestNatsClient_MaxStreams_Queue verifies that a Subscribe beyond the stream limit waits for a free slot
+// when the client queues, and subscribes once another subscription ends.
+func TestNatsClient_MaxStreams_Queue(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		grpcServer = container.InProcessServerContainer.Get()
+		mock       = container.MockBusServiceServer.Get()
+		silent     = "test.streams.queue.silent"
+		subject    = "test.streams.queue"
+		data       = []byte("queued")
+		received   = make(chan []byte, 1)
+	)
+	t.Cleanup(grpcServer.Stop)
+	mock.SetSilent(silent)
+	client := newStreamsClient(t, container, true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	defer cancel()
+	require.NoError(t, client.Publish(ctx, subject, data), "Failed to publish message")
+
+	release := holdStream(t, client, silent)
+	go func() {
+		_ = client.Subscribe(ctx, subject, "", func(data []byte, subject string) { received <- data })
+	}()
+
+	select {
+	case <-received:
+		t.Fatal("Expected the excess subscription to wait for a free slot")
+	case <-time.After(time.Duration(700) * time.Millisecond):
+	}
+
+	release()
+	select {
+	case message := <-received:
+		require.Equal(t, data, message, "Received message does not match published data")
+	case <-ctx.Done():
+		t.Fatal("Queued subscription did not proceed once a slot was freed")
+	}
+}
@@ -0,0 +1,71 @@
+package nats_service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"shared/grpc/clients/nats_service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestTLSConfig_ServerName verifies that the TLS configuration verifies the server certificate against
+// the override name, trusting the configured certificate file.
+func TestTLSConfig_ServerName(t *testing.T) {
+	config := &nats_service.Config{
+		TLSEnabled: true,
+		Address:    "10.0.0.1:61355",
+		CertFile:   writeCertificate(t, "nats.internal"),
+		ServerName: "nats.internal",
+	}
+
+	tlsConfig, err := nats_service.TLSConfig(config)
+	require.NoError(t, err, "Failed to build TLS config")
+	require.Equal(t, "nats.internal", tlsConfig.ServerName, "Expected the override server name")
+	require.NotNil(t, tlsConfig.RootCAs, "Expected the certificate file to be trusted")
+}
+
+// TestNewGRPCClient_ServerNameRequired verifies that TLS towards an IP address requires a server name override,
+// while host names and overridden IP addresses are accepted.
+func TestNewGRPCClient_ServerNameRequired(t *testing.T) {
+	_, _, err := nats_service.NewGRPCClient(nats_service.WithAddress("10.0.0.1:61355"), nats_service.WithTLS(""))
+	require.ErrorIs(t, err, nats_service.ErrServerNameRequired, "Expected IP address without override to fail")
+
+	conn, config, err := nats_service.NewGRPCClient(nats_service.WithAddress("10.0.0.1:61355"),
+		nats_service.WithTLS(""), nats_service.WithServerName("nats.internal"))
+	require.NoError(t, err, "Expected IP address with override to succeed")
+	require.Equal(t, "nats.internal", config.ServerName)
+	require.NoError(t, conn.Close())
+
+	conn, _, err = nats_service.NewGRPCClient(nats_service.WithAddress("nats.internal:61355"), nats_service.WithTLS(""))
+	require.NoError(t, err, "Expected host name without override to succeed")
+	require.NoError(t, conn.Close())
+}
+
+// writeCertificate writes a self-signed certificate for name to a temporary file and returns its path.
+func writeCertificate(t *testing.T, name string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Failed to generate key")
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err, "Failed to create certificate")
+
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return path
+}
@@ -0,0 +1,143 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Redacted replaces the value of a secret in a configuration dump, empty secrets are kept to show they are unset.
+const Redacted = "[REDACTED]"
+
+// secretWords are the lowercase name words marking a field, or a map key, as a secret.
+var secretWords = map[string]bool{
+	"pass": true, "passwd": true, "password": true, "passphrase": true, "secret": true, "token": true,
+	"key": true, "apikey": true, "credential": true, "credentials": true, "auth": true, "authorization": true,
+	"cookie": true, "private": true, "signature": true, "dsn": true,
+}
+
+// Config returns config, a struct or a pointer to one, as a group attribute named key for a startup log.
+// Nested structs and maps become nested groups, Stringers and slices are logged as strings.
+// Values of fields or map keys named like secrets (password, token, key, ...), PEM contents and the passwords
+// of URLs are replaced by Redacted, unexported fields are skipped.
+func Config(key string, config any) slog.Attr {
+	return configAttr(key, reflect.ValueOf(config), false)
+}
+
+// configAttr returns the attribute of value, secret redacts every non-empty leaf below it.
+func configAttr(key string, value reflect.Value, secret bool) slog.Attr {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return slog.Any(key, nil)
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if stringer, ok := value.Interface().(fmt.Stringer); ok {
+			return slog.String(key, redact(stringer.String(), secret))
+		}
+		attrs := make([]any, 0, value.NumField())
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			attrs = append(attrs, configAttr(field.Name, value.Field(i), secret || isSecretName(field.Name)))
+		}
+		return slog.Group(key, attrs...)
+	case reflect.Map:
+		// Keys are sorted, so that dumps of the same configuration are identical.
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		attrs := make([]any, 0, len(keys))
+		for _, mapKey := range keys {
+			name := fmt.Sprint(mapKey.Interface())
+			attrs = append(attrs, configAttr(name, value.MapIndex(mapKey), secret || isSecretName(name)))
+		}
+		return slog.Group(key, attrs...)
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, redact(fmt.Sprint(value.Index(i).Interface()), secret))
+		}
+		return slog.String(key, strings.Join(items, ","))
+	case reflect.String:
+		return slog.String(key, redact(value.String(), secret))
+	case reflect.Invalid:
+		return slog.Any(key, nil)
+	default:
+		if secret && !value.IsZero() {
+			return slog.String(key, Redacted)
+		}
+		return slog.Any(key, value.Interface())
+	}
+}
+
+// redact returns value, or Redacted when it is a non-empty secret or PEM contents.
+// The password of a URL value is redacted while the rest of the URL is kept.
+func redact(value string, secret bool) string {
+	switch {
+	case value == "":
+		return value
+	case secret, strings.Contains(value, "-----BEGIN"):
+		return Redacted
+	}
+	if parsed, err := url.Parse(value); err == nil && parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), Redacted)
+			return parsed.String()
+		}
+	}
+	return value
+}
+
+// isSecretName reports whether a field or map key name contains a secret word.
+// Names are split on camel case, digits and separators, so "ControlPassword" and "api_key" match
+// while "Keyspace" or "MonkeyCount" do not.
+func isSecretName(name string) bool {
+	for _, word := range splitWords(name) {
+		if secretWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWords returns the lowercase words of name.
+func splitWords(name string) []string {
+	var (
+		words   []string
+		current []rune
+		runes   = []rune(name)
+	)
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && len(current) > 0:
+			// A new word starts at an upper case letter following a lower case one ("ControlPassword"),
+			// or at the last upper case letter of an acronym followed by a lower case one ("TLSKey" is "tls", "key").
+			previous := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || (unicode.IsUpper(previous) && next) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
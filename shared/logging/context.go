@@ -0,0 +1,28 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// contextKey is the context key of the scoped logger.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, every log line of the scope is written through it.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or fallback when ctx carries none.
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return fallback
+}
+
+// With binds fields (key-value pairs or slog.Attr) to the logger carried by ctx, or to fallback when
+// ctx carries none, and returns a copy of ctx carrying the resulting logger.
+func With(ctx context.Context, fallback *slog.Logger, args ...any) context.Context {
+	return NewContext(ctx, FromContext(ctx, fallback).With(args...))
+}
@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats of the handlers built by NewHandler.
+const (
+	FormatJSON    = "json"    // FormatJSON writes a JSON object per record, the default.
+	FormatConsole = "console" // FormatConsole writes key=value records readable on a console.
+)
+
+// HandlerOptions configures the handler built by NewHandler.
+type HandlerOptions struct {
+	Format    string       // Format is FormatJSON or FormatConsole, anything else is FormatJSON.
+	AddSource bool         // AddSource adds the source file:line of the logging call to every record.
+	Level     slog.Leveler // Level is the minimum level of the records, nil is info.
+}
+
+// NewHandler returns the handler writing the records to w in the format of options.
+// It is shared by the loggers of the DI containers, so every service formats its records alike.
+func NewHandler(w io.Writer, options HandlerOptions) slog.Handler {
+	handlerOptions := &slog.HandlerOptions{AddSource: options.AddSource, Level: options.Level}
+	if strings.EqualFold(strings.TrimSpace(options.Format), FormatConsole) {
+		return slog.NewTextHandler(w, handlerOptions)
+	}
+	return slog.NewJSONHandler(w, handlerOptions)
+}
@@ -0,0 +1,35 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"shared/logging"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogging_FromContext verifies that a logger retrieved from the context includes the fields bound
+// to it, accumulated across scopes, and that the fallback is used when the context carries none.
+func TestLogging_FromContext(t *testing.T) {
+	var (
+		buffer   bytes.Buffer
+		fallback = slog.New(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{}))
+		entry    map[string]any
+	)
+
+	require.Same(t, fallback, logging.FromContext(context.Background(), fallback), "Fallback expected")
+
+	ctx := logging.With(context.Background(), fallback, "method", "/bus.BusService/Publish", "peer", "127.0.0.1:1")
+	ctx = logging.With(ctx, fallback, slog.String("correlation_id", "abc-123"))
+	logging.FromContext(ctx, fallback).Info("Published", "subject", "test.subject")
+
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry), "Failed to decode the log line")
+	require.Equal(t, "Published", entry["msg"])
+	require.Equal(t, "/bus.BusService/Publish", entry["method"])
+	require.Equal(t, "127.0.0.1:1", entry["peer"])
+	require.Equal(t, "abc-123", entry["correlation_id"])
+	require.Equal(t, "test.subject", entry["subject"])
+}
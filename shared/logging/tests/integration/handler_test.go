@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"shared/logging"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogging_NewHandler_AddSource verifies that a handler built with AddSource adds the source file and line
+// of the logging call to every record, and that it is omitted by default.
+func TestLogging_NewHandler_AddSource(t *testing.T) {
+	var (
+		buffer bytes.Buffer
+		entry  map[string]any
+		logger = slog.New(logging.NewHandler(&buffer, logging.HandlerOptions{AddSource: true}))
+	)
+
+	logger.Info("With source")
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry), "Expected a JSON record by default")
+	source, ok := entry[slog.SourceKey].(map[string]any)
+	require.True(t, ok, "Expected the record to include a source attribute")
+	require.True(t, strings.HasSuffix(source["file"].(string), "handler_test.go"), "Expected the calling file")
+	require.Positive(t, source["line"], "Expected the calling line")
+
+	buffer.Reset()
+	entry = nil
+	logger = slog.New(logging.NewHandler(&buffer, logging.HandlerOptions{}))
+	logger.Info("Without source")
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &entry), "Failed to decode the log line")
+	require.NotContains(t, entry, slog.SourceKey, "Expected no source attribute by default")
+}
+
+// TestLogging_NewHandler_Format verifies that the console format writes key=value records and that
+// the level option filters records.
+func TestLogging_NewHandler_Format(t *testing.T) {
+	var (
+		buffer bytes.Buffer
+		logger = slog.New(logging.NewHandler(&buffer, logging.HandlerOptions{
+			Format: logging.FormatConsole,
+			Level:  slog.LevelWarn,
+		}))
+	)
+
+	logger.Info("Filtered out")
+	logger.Warn("Console record", "key", "value")
+
+	line := buffer.String()
+	require.NotContains(t, line, "Filtered out", "Expected records below the level to be dropped")
+	require.Contains(t, line, `msg="Console record"`, "Expected a key=value record")
+	require.Contains(t, line, "key=value")
+	require.False(t, json.Valid(buffer.Bytes()), "Expected no JSON record")
+}
@@ -1,11 +1,18 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 )
 
+const (
+	maxDatabaseNameLength = 63  // maxDatabaseNameLength is the max. length of a MongoDB database name in bytes.
+	maxNamespaceLength    = 255 // maxNamespaceLength is the max. length of "<db>.<collection>" in bytes.
+)
+
 var (
 	once   sync.Once
 	config *Config
@@ -32,6 +39,11 @@ type MongoConfig struct {
 	Pass       string // Pass is the password used to connect to the MongoDB server.
 	DB         string // DB is the name of the MongoDB database.
 	Collection string // Collection is the name of the MongoDB collection.
+
+	TransitionsCollection string // TransitionsCollection is the optional status transitions audit log collection.
+
+	ReadPreference string // ReadPreference is the read preference mode of the collections, empty keeps the default.
+	WriteConcern   string // WriteConcern is the write concern of the collections, empty keeps the default.
 }
 
 // loadConfig loads configuration falling back to default values.
@@ -50,6 +62,11 @@ func loadMongoConfig() MongoConfig {
 		Pass:       getEnv("MONGO_PASS", ""),
 		DB:         getEnv("MONGO_DB", ""),
 		Collection: getEnv("MONGO_COLLECTION", ""),
+
+		TransitionsCollection: getEnv("MONGO_TRANSITIONS_COLLECTION", ""),
+
+		ReadPreference: getEnv("MONGO_READ_PREFERENCE", ""),
+		WriteConcern:   getEnv("MONGO_WRITE_CONCERN", ""),
 	}
 
 	checkRequiredVars("MONGO", map[string]string{
@@ -60,10 +77,61 @@ func loadMongoConfig() MongoConfig {
 		"MONGO_DB":         mongo.DB,
 		"MONGO_COLLECTION": mongo.Collection,
 	})
+	checkNames(mongo)
 
 	return mongo
 }
 
+// checkNames ensures the database and collection names conform to the MongoDB naming rules.
+func checkNames(mongo MongoConfig) {
+	var errs []error
+	if err := ValidateDatabaseName(mongo.DB); err != nil {
+		errs = append(errs, fmt.Errorf("MONGO_DB: %w", err))
+	}
+	if err := ValidateCollectionName(mongo.DB, mongo.Collection); err != nil {
+		errs = append(errs, fmt.Errorf("MONGO_COLLECTION: %w", err))
+	}
+	if mongo.TransitionsCollection != "" {
+		if err := ValidateCollectionName(mongo.DB, mongo.TransitionsCollection); err != nil {
+			errs = append(errs, fmt.Errorf("MONGO_TRANSITIONS_COLLECTION: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		panic(fmt.Sprintf("MONGO configuration error: %v", errors.Join(errs...)))
+	}
+}
+
+// ValidateDatabaseName checks the database name against the MongoDB naming rules.
+func ValidateDatabaseName(name string) error {
+	if name == "" {
+		return errors.New("database name is empty")
+	}
+	if len(name) > maxDatabaseNameLength {
+		return fmt.Errorf("database name %q exceeds %d bytes", name, maxDatabaseNameLength)
+	}
+	if i := strings.IndexAny(name, "/\\. \"$*<>:|?\x00"); i >= 0 {
+		return fmt.Errorf("database name %q contains invalid character %q", name, name[i])
+	}
+	return nil
+}
+
+// ValidateCollectionName checks the collection name against the MongoDB naming rules.
+func ValidateCollectionName(db, name string) error {
+	if name == "" {
+		return errors.New("collection name is empty")
+	}
+	if strings.HasPrefix(name, "system.") {
+		return fmt.Errorf("collection name %q uses the reserved system. prefix", name)
+	}
+	if i := strings.IndexAny(name, "$\x00"); i >= 0 {
+		return fmt.Errorf("collection name %q contains invalid character %q", name, name[i])
+	}
+	if namespace := len(db) + 1 + len(name); namespace > maxNamespaceLength {
+		return fmt.Errorf("namespace %s.%s exceeds %d bytes", db, name, maxNamespaceLength)
+	}
+	return nil
+}
+
 // getEnv fetches the value of an environment variable or returns a fallback.
 func getEnv(key, fallback string) string {
 	if v, ok := os.LookupEnv(key); ok {
@@ -0,0 +1,48 @@
+package mongodb
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// CollectionOptions builds collection options from a read preference mode (e.g., "secondaryPreferred")
+// and a write concern ("majority", a number of acknowledging nodes or a tag set name).
+// Empty values keep the driver defaults inherited from the client.
+func CollectionOptions(readPreference, writeConcern string) (opts *options.CollectionOptions, err error) {
+	opts = options.Collection()
+
+	if readPreference != "" {
+		var (
+			mode readpref.Mode
+			rp   *readpref.ReadPref
+		)
+		if mode, err = readpref.ModeFromString(readPreference); err != nil {
+			return nil, fmt.Errorf("parse read preference: %w", err)
+		}
+		if rp, err = readpref.New(mode); err != nil {
+			return nil, fmt.Errorf("create read preference: %w", err)
+		}
+		opts.SetReadPreference(rp)
+	}
+
+	if writeConcern != "" {
+		opts.SetWriteConcern(parseWriteConcern(writeConcern))
+	}
+
+	return opts, nil
+}
+
+// parseWriteConcern converts a write concern string into a WriteConcern.
+func parseWriteConcern(value string) *writeconcern.WriteConcern {
+	if value == "majority" {
+		return writeconcern.Majority()
+	}
+	if w, err := strconv.Atoi(value); err == nil {
+		return &writeconcern.WriteConcern{W: w}
+	}
+	return writeconcern.Custom(value)
+}
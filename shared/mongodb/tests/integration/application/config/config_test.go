@@ -0,0 +1,66 @@
+package config
+
+import (
+	"shared/mongodb/application/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateDatabaseName verifies that database names are checked against the MongoDB naming rules.
+func TestValidateDatabaseName(t *testing.T) {
+	tests := []struct {
+		name  string
+		db    string
+		valid bool
+	}{
+		{name: "simple", db: "url", valid: true},
+		{name: "with dash and underscore", db: "url-service_db", valid: true},
+		{name: "empty", db: "", valid: false},
+		{name: "with dot", db: "url.db", valid: false},
+		{name: "with slash", db: "url/db", valid: false},
+		{name: "with space", db: "url db", valid: false},
+		{name: "with dollar", db: "url$", valid: false},
+		{name: "too long", db: strings.Repeat("a", 64), valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := config.ValidateDatabaseName(test.db)
+			if test.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestValidateCollectionName verifies that collection names are checked against the MongoDB naming rules.
+func TestValidateCollectionName(t *testing.T) {
+	tests := []struct {
+		name       string
+		collection string
+		valid      bool
+	}{
+		{name: "simple", collection: "list", valid: true},
+		{name: "with dot", collection: "url.list", valid: true},
+		{name: "empty", collection: "", valid: false},
+		{name: "system prefix", collection: "system.users", valid: false},
+		{name: "with dollar", collection: "list$", valid: false},
+		{name: "with null", collection: "li\x00st", valid: false},
+		{name: "namespace too long", collection: strings.Repeat("a", 252), valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := config.ValidateCollectionName("url", test.collection)
+			if test.valid {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package mongodb
+
+import (
+	"shared/mongodb/application/config"
+	"shared/mongodb/infrastructure/mongodb"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// TestCollectionOptions_Applied verifies that the configured read preference and write concern are applied
+// to the collection options.
+func TestCollectionOptions_Applied(t *testing.T) {
+	mongoConfig := config.MongoConfig{ReadPreference: "secondaryPreferred", WriteConcern: "majority"}
+
+	opts, err := mongodb.CollectionOptions(mongoConfig.ReadPreference, mongoConfig.WriteConcern)
+	require.NoError(t, err, "Expected valid collection options")
+	require.NotNil(t, opts.ReadPreference, "Expected a read preference")
+	assert.Equal(t, readpref.SecondaryPreferredMode, opts.ReadPreference.Mode())
+	assert.Equal(t, writeconcern.Majority(), opts.WriteConcern)
+}
+
+// TestCollectionOptions_Defaults verifies that empty values leave the client defaults untouched.
+func TestCollectionOptions_Defaults(t *testing.T) {
+	opts, err := mongodb.CollectionOptions("", "")
+	require.NoError(t, err, "Expected valid collection options")
+	assert.Nil(t, opts.ReadPreference, "Expected no explicit read preference")
+	assert.Nil(t, opts.WriteConcern, "Expected no explicit write concern")
+}
+
+// TestCollectionOptions_NumericWriteConcern verifies that a numeric write concern sets the number of acknowledgements.
+func TestCollectionOptions_NumericWriteConcern(t *testing.T) {
+	opts, err := mongodb.CollectionOptions("", "2")
+	require.NoError(t, err, "Expected valid collection options")
+	require.NotNil(t, opts.WriteConcern, "Expected a write concern")
+	assert.Equal(t, 2, opts.WriteConcern.W)
+}
+
+// TestCollectionOptions_InvalidReadPreference verifies that an unknown read preference mode is rejected.
+func TestCollectionOptions_InvalidReadPreference(t *testing.T) {
+	_, err := mongodb.CollectionOptions("fastest", "")
+	require.Error(t, err, "Expected an unknown read preference to be rejected")
+}
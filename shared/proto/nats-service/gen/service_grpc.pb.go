@@ -19,8 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	BusService_Publish_FullMethodName   = "/nats.service.v1.BusService/Publish"
-	BusService_Subscribe_FullMethodName = "/nats.service.v1.BusService/Subscribe"
+	BusService_Publish_FullMethodName      = "/nats.service.v1.BusService/Publish"
+	BusService_Subscribe_FullMethodName    = "/nats.service.v1.BusService/Subscribe"
+	BusService_SubscribeAck_FullMethodName = "/nats.service.v1.BusService/SubscribeAck"
 )
 
 // BusServiceClient is the client API for BusService service.
@@ -33,6 +34,10 @@ type BusServiceClient interface {
 	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
 	// Subscribes to a specified NATS subject and receives messages.
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribeResponse], error)
+	// Subscribes to a specified NATS subject and receives messages that the client acknowledges explicitly.
+	// The first request opens the subscription, the following ones ack the delivered messages by id, and the
+	// messages left unacked within the ack timeout of the server are delivered again.
+	SubscribeAck(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SubscribeAckRequest, SubscribeResponse], error)
 }
 
 type busServiceClient struct {
@@ -72,6 +77,19 @@ func (c *busServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest,
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type BusService_SubscribeClient = grpc.ServerStreamingClient[SubscribeResponse]
 
+func (c *busServiceClient) SubscribeAck(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[SubscribeAckRequest, SubscribeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BusService_ServiceDesc.Streams[1], BusService_SubscribeAck_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeAckRequest, SubscribeResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BusService_SubscribeAckClient = grpc.BidiStreamingClient[SubscribeAckRequest, SubscribeResponse]
+
 // BusServiceServer is the server API for BusService service.
 // All implementations must embed UnimplementedBusServiceServer
 // for forward compatibility.
@@ -82,6 +100,10 @@ type BusServiceServer interface {
 	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
 	// Subscribes to a specified NATS subject and receives messages.
 	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeResponse]) error
+	// Subscribes to a specified NATS subject and receives messages that the client acknowledges explicitly.
+	// The first request opens the subscription, the following ones ack the delivered messages by id, and the
+	// messages left unacked within the ack timeout of the server are delivered again.
+	SubscribeAck(grpc.BidiStreamingServer[SubscribeAckRequest, SubscribeResponse]) error
 	mustEmbedUnimplementedBusServiceServer()
 }
 
@@ -98,6 +120,9 @@ func (UnimplementedBusServiceServer) Publish(context.Context, *PublishRequest) (
 func (UnimplementedBusServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
 }
+func (UnimplementedBusServiceServer) SubscribeAck(grpc.BidiStreamingServer[SubscribeAckRequest, SubscribeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAck not implemented")
+}
 func (UnimplementedBusServiceServer) mustEmbedUnimplementedBusServiceServer() {}
 func (UnimplementedBusServiceServer) testEmbeddedByValue()                    {}
 
@@ -148,6 +173,13 @@ func _BusService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) er
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type BusService_SubscribeServer = grpc.ServerStreamingServer[SubscribeResponse]
 
+func _BusService_SubscribeAck_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BusServiceServer).SubscribeAck(&grpc.GenericServerStream[SubscribeAckRequest, SubscribeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BusService_SubscribeAckServer = grpc.BidiStreamingServer[SubscribeAckRequest, SubscribeResponse]
+
 // BusService_ServiceDesc is the grpc.ServiceDesc for BusService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -166,6 +198,12 @@ var BusService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _BusService_Subscribe_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "SubscribeAck",
+			Handler:       _BusService_SubscribeAck_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "shared/proto/nats-service/service.proto",
 }
@@ -0,0 +1,91 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+)
+
+// Func re-reads the configuration and applies the hot-reloadable parameters to the running components.
+type Func func() error
+
+// Notify relays SIGHUP to the returned channel until stop is called.
+func Notify() (signals <-chan os.Signal, stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	return ch, func() { signal.Stop(ch) }
+}
+
+// Watch calls reload for every signal received until ctx is done or signals is closed.
+// A failed reload is logged and keeps the previously applied values, so a bad edit never stops the service.
+func Watch(ctx context.Context, signals <-chan os.Signal, reload Func, logger *slog.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig, ok := <-signals:
+			if !ok {
+				return
+			}
+			logger.Info("Reload signal received, applying configuration", "signal", sig.String())
+			if err := apply(reload); err != nil {
+				logger.Error("Configuration reload failed, keeping the previous values", "error", err)
+				continue
+			}
+			logger.Info("Configuration reloaded")
+		}
+	}
+}
+
+// LoadEnvFile sets the variables of an env file (KEY=VALUE or export KEY=VALUE per line) in the process environment.
+// The environment of a running process cannot be changed from outside, the file is how new values reach a reload.
+// Blank lines and # comments are skipped, values may be wrapped in single or double quotes.
+func LoadEnvFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read env file: %w", err)
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, value, found := strings.Cut(line, "=")
+		if !found || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("env file %s: malformed line %d", path, i+1)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if err = os.Setenv(strings.TrimSpace(key), value); err != nil {
+			return fmt.Errorf("set %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// apply runs reload, turning a panic (e.g., a missing required variable) into an error.
+func apply(reload Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("reload panicked: %v", r)
+		}
+	}()
+	return reload()
+}
+
+// ParseLevel parses a log level name (debug, info, warn, error) case-insensitively, an empty value is info.
+func ParseLevel(value string) (level slog.Level, err error) {
+	if strings.TrimSpace(value) == "" {
+		return slog.LevelInfo, nil
+	}
+	err = level.UnmarshalText([]byte(strings.TrimSpace(value)))
+	return level, err
+}
@@ -0,0 +1,115 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Classifier is implemented by errors deciding their own retryability, it takes precedence over the other rules.
+type Classifier interface {
+	// Retryable reports whether the operation failing with the error may succeed when retried.
+	Retryable() bool
+}
+
+// StatusError is an unsuccessful HTTP response status returned as an error.
+type StatusError struct {
+	Code   int    // Code is the status code of the response.
+	Status string // Status is the status line of the response, e.g. "502 Bad Gateway".
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %s", e.Status)
+}
+
+// Retryable implements Classifier, see RetryableStatus.
+func (e *StatusError) Retryable() bool {
+	return RetryableStatus(e.Code)
+}
+
+// RetryableStatus reports whether a request answered with the HTTP status code may succeed when retried:
+// timeouts, rate limits and server errors are, client errors and unsupported features are not.
+func RetryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return code >= http.StatusInternalServerError
+	}
+}
+
+// retryableCodes are the gRPC status codes of failures that may succeed when retried.
+var retryableCodes = map[codes.Code]bool{
+	codes.Unavailable:       true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+	codes.Aborted:           true,
+}
+
+// mongoLabels are the labels MongoDB attaches to errors of operations that may succeed when retried.
+var mongoLabels = []string{"RetryableWriteError", "TransientTransactionError", "RetryableReadError"}
+
+// IsRetryable reports whether an operation failing with err may succeed when retried. It recognizes, in order:
+// errors implementing Classifier (such as StatusError), context errors, gRPC status codes, MongoDB transient errors
+// and network errors. A canceled context is never retryable, an expired deadline is, and unknown errors are not.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var classifier Classifier
+	if errors.As(err, &classifier) {
+		return classifier.Retryable()
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+	if grpcStatus, ok := status.FromError(err); ok {
+		return retryableCodes[grpcStatus.Code()]
+	}
+	if mongo.IsTimeout(err) || mongo.IsNetworkError(err) {
+		return true
+	}
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) {
+		for _, label := range mongoLabels {
+			if labeled.HasErrorLabel(label) {
+				return true
+			}
+		}
+	}
+	return isNetworkError(err)
+}
+
+// isNetworkError reports whether err is a failure of the connection rather than of the operation.
+func isNetworkError(err error) bool {
+	var (
+		netErr net.Error
+		opErr  *net.OpError
+	)
+	switch {
+	case errors.As(err, &opErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"shared/retry"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// classified is an error deciding its own retryability.
+type classified bool
+
+// Error implements the error interface.
+func (c classified) Error() string { return "classified" }
+
+// Retryable implements retry.Classifier.
+func (c classified) Retryable() bool { return bool(c) }
+
+// TestIsRetryable verifies the retryability of every error category.
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "Nil", err: nil, retryable: false},
+		{name: "Unknown", err: errors.New("invalid URL"), retryable: false},
+
+		{name: "ClassifierRetryable", err: fmt.Errorf("wrapped: %w", classified(true)), retryable: true},
+		{name: "ClassifierFinal", err: classified(false), retryable: false},
+
+		{name: "ContextCanceled", err: fmt.Errorf("update: %w", context.Canceled), retryable: false},
+		{name: "ContextDeadline", err: fmt.Errorf("update: %w", context.DeadlineExceeded), retryable: true},
+
+		{name: "GRPCUnavailable", err: status.Error(codes.Unavailable, "restarting"), retryable: true},
+		{name: "GRPCDeadlineExceeded", err: status.Error(codes.DeadlineExceeded, "slow"), retryable: true},
+		{name: "GRPCResourceExhausted", err: status.Error(codes.ResourceExhausted, "busy"), retryable: true},
+		{name: "GRPCAborted", err: status.Error(codes.Aborted, "conflict"), retryable: true},
+		{name: "GRPCWrapped", err: fmt.Errorf("publish: %w", status.Error(codes.Unavailable, "down")), retryable: true},
+		{name: "GRPCInvalidArgument", err: status.Error(codes.InvalidArgument, "bad subject"), retryable: false},
+		{name: "GRPCPermissionDenied", err: status.Error(codes.PermissionDenied, "acl"), retryable: false},
+
+		{name: "HTTPServerError", err: &retry.StatusError{Code: http.StatusBadGateway}, retryable: true},
+		{name: "HTTPTooManyRequests", err: &retry.StatusError{Code: http.StatusTooManyRequests}, retryable: true},
+		{name: "HTTPRequestTimeout", err: &retry.StatusError{Code: http.StatusRequestTimeout}, retryable: true},
+		{name: "HTTPNotImplemented", err: &retry.StatusError{Code: http.StatusNotImplemented}, retryable: false},
+		{name: "HTTPNotFound", err: &retry.StatusError{Code: http.StatusNotFound}, retryable: false},
+
+		{name: "MongoNetwork", err: mongo.CommandError{Labels: []string{"NetworkError"}}, retryable: true},
+		{name: "MongoRetryableWrite", err: mongo.CommandError{Labels: []string{"RetryableWriteError"}}, retryable: true},
+		{name: "MongoTransientTransaction", err: mongo.WriteException{Labels: []string{"TransientTransactionError"}},
+			retryable: true},
+		{name: "MongoDuplicateKey", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}},
+			retryable: false},
+		{name: "MongoNoDocuments", err: mongo.ErrNoDocuments, retryable: false},
+
+		{name: "NetDial", err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, retryable: true},
+		{name: "NetTimeout", err: &net.DNSError{Err: "timeout", IsTimeout: true}, retryable: true},
+		{name: "NetNotFound", err: &net.DNSError{Err: "no such host", IsNotFound: true}, retryable: false},
+		{name: "ConnectionReset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), retryable: true},
+		{name: "UnexpectedEOF", err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), retryable: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.retryable, retry.IsRetryable(test.err))
+		})
+	}
+}
+
+// TestRetryableStatus verifies the retryability of the HTTP status categories.
+func TestRetryableStatus(t *testing.T) {
+	for code := http.StatusOK; code < 600; code++ {
+		expected := code >= http.StatusInternalServerError && code != http.StatusNotImplemented &&
+			code != http.StatusHTTPVersionNotSupported ||
+			code == http.StatusRequestTimeout || code == http.StatusTooEarly || code == http.StatusTooManyRequests
+		require.Equal(t, expected, retry.RetryableStatus(code), "Unexpected retryability of status %d", code)
+	}
+}
@@ -0,0 +1,97 @@
+package runlimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrMaxRuntime is the cancellation cause of a run that reached its max. runtime.
+	ErrMaxRuntime = errors.New("max. runtime reached")
+	// ErrMaxMessages is the cancellation cause of a run that processed its max. number of messages.
+	ErrMaxMessages = errors.New("max. messages processed")
+)
+
+// Reached reports whether cause is the cancellation cause of a run stopped by one of its limits.
+func Reached(cause error) bool {
+	return errors.Is(cause, ErrMaxRuntime) || errors.Is(cause, ErrMaxMessages)
+}
+
+// Limits bounds a job-style run, a zero value disables a limit.
+type Limits struct {
+	MaxRuntime  time.Duration // MaxRuntime is the max. duration of the run.
+	MaxMessages int64         // MaxMessages is the max. number of messages processed by the run.
+}
+
+// Budget counts the messages processed by a run and stops the run once a limit is reached.
+// A nil Budget is unlimited, so services can hold one unconditionally.
+type Budget struct {
+	limits    Limits        // limits is the limits of the run.
+	processed atomic.Int64  // processed is the number of messages processed so far.
+	exhausted chan struct{} // exhausted is closed once MaxMessages messages are processed.
+	once      sync.Once     // once guards closing exhausted.
+}
+
+// NewBudget creates a new instance of Budget, it returns nil when no limit is set.
+func NewBudget(limits Limits) *Budget {
+	if limits.MaxRuntime <= 0 && limits.MaxMessages <= 0 {
+		return nil
+	}
+	return &Budget{limits: limits, exhausted: make(chan struct{})}
+}
+
+// Bind returns a context canceled when ctx is done or a limit of the budget is reached,
+// context.Cause reports ErrMaxRuntime or ErrMaxMessages in the latter case.
+func (b *Budget) Bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b == nil {
+		return context.WithCancel(ctx)
+	}
+
+	runCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		var runtime <-chan time.Time
+		if b.limits.MaxRuntime > 0 {
+			timer := time.NewTimer(b.limits.MaxRuntime)
+			defer timer.Stop()
+			runtime = timer.C
+		}
+
+		select {
+		case <-runCtx.Done():
+		case <-runtime:
+			cancel(ErrMaxRuntime)
+		case <-b.exhausted:
+			cancel(ErrMaxMessages)
+		}
+	}()
+	return runCtx, func() { cancel(context.Canceled) }
+}
+
+// Done records a processed message, the run is stopped once MaxMessages messages are processed.
+func (b *Budget) Done() {
+	if b == nil {
+		return
+	}
+	if processed := b.processed.Add(1); b.limits.MaxMessages > 0 && processed >= b.limits.MaxMessages {
+		b.once.Do(func() { close(b.exhausted) })
+	}
+}
+
+// Remaining returns the number of messages the run may still process, or -1 when it is unlimited.
+func (b *Budget) Remaining() int64 {
+	if b == nil || b.limits.MaxMessages <= 0 {
+		return -1
+	}
+	return max(b.limits.MaxMessages-b.processed.Load(), 0)
+}
+
+// Processed returns the number of messages processed so far.
+func (b *Budget) Processed() int64 {
+	if b == nil {
+		return 0
+	}
+	return b.processed.Load()
+}
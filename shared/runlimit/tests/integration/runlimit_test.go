@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"context"
+	"shared/runlimit"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestBudget_MaxMessages verifies that the bound context is canceled with ErrMaxMessages
+// once the configured number of messages is processed, and not before.
+func TestBudget_MaxMessages(t *testing.T) {
+	budget := runlimit.NewBudget(runlimit.Limits{MaxMessages: 3})
+	ctx, cancel := budget.Bind(context.Background())
+	defer cancel()
+
+	budget.Done()
+	budget.Done()
+	require.Equal(t, int64(1), budget.Remaining())
+	require.Never(t, func() bool { return ctx.Err() != nil },
+		time.Duration(100)*time.Millisecond, time.Duration(10)*time.Millisecond, "Run stopped before the limit")
+
+	budget.Done()
+	require.Eventually(t, func() bool { return ctx.Err() != nil },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Run not stopped at the limit")
+	require.ErrorIs(t, context.Cause(ctx), runlimit.ErrMaxMessages)
+	require.Equal(t, int64(0), budget.Remaining())
+}
+
+// TestBudget_MaxRuntime verifies that the bound context is canceled with ErrMaxRuntime once the runtime elapses.
+func TestBudget_MaxRuntime(t *testing.T) {
+	budget := runlimit.NewBudget(runlimit.Limits{MaxRuntime: time.Duration(50) * time.Millisecond})
+	ctx, cancel := budget.Bind(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Duration(5) * time.Second):
+		t.Fatal("Run not stopped after the max. runtime")
+	}
+	require.ErrorIs(t, context.Cause(ctx), runlimit.ErrMaxRuntime)
+	require.Equal(t, int64(-1), budget.Remaining())
+}
+
+// TestBudget_Unlimited verifies that a budget without limits is nil and never stops the run.
+func TestBudget_Unlimited(t *testing.T) {
+	budget := runlimit.NewBudget(runlimit.Limits{})
+	require.Nil(t, budget)
+
+	ctx, cancel := budget.Bind(context.Background())
+	budget.Done()
+	require.NoError(t, ctx.Err())
+	require.Equal(t, int64(-1), budget.Remaining())
+
+	cancel()
+	require.ErrorIs(t, context.Cause(ctx), context.Canceled)
+}
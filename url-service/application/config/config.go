@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -20,30 +21,92 @@ func GetConfig() *Config {
 	return config
 }
 
+// Load reads a fresh configuration from the environment, bypassing the cached one.
+// It is used on reload, only the hot-reloadable parameters of the result are applied.
+func Load() *Config {
+	return loadConfig()
+}
+
 // Config holds configuration settings.
 type Config struct {
 	Nats            NatsConfig      // NATS configuration.
 	TLS             TLSConfig       // TLS configuration.
 	InboundMessage  InboundMessage  // Inbound message service configuration.
 	OutboundMessage OutboundMessage // Outbound message service configuration.
+	Limits          Limits          // URL document size limits.
+	Metrics         MetricsConfig   // Metrics configuration.
+	Run             RunConfig       // Job-style run limits.
+	Retention       Retention       // Retention policy of failed URLs.
+	Breaker         Breaker         // MongoDB circuit breaker configuration.
+	LogLevel        string          // LogLevel is the minimum log level (debug, info, warn, error), hot-reloadable.
+	LogFormat       string          // LogFormat is the log handler, json or console.
+	LogAddSource    bool            // LogAddSource adds the source file:line of the logging call to every record.
+	ReloadFile      string          // ReloadFile is the env file re-read on SIGHUP, empty re-reads the process environment.
 	Env             string          // Environment type (e.g., dev, prod).
 }
 
+// MetricsConfig holds configuration settings for the metrics server.
+type MetricsConfig struct {
+	ServerPort string // ServerPort is the address of the metrics HTTP server (e.g., ":50555").
+}
+
+// RunConfig holds the limits of job-style runs, reaching one shuts the service down gracefully, 0 disables a limit.
+type RunConfig struct {
+	MaxRuntime  int // MaxRuntime is the max. seconds the service runs.
+	MaxMessages int // MaxMessages is the max. number of URLs the service processes.
+}
+
+// Retention holds the retention policy of failed URLs, 0 disables the cleanup.
+type Retention struct {
+	FailedMaxAge int // FailedMaxAge is the hours failed URLs are kept after their last update.
+}
+
+// Breaker holds the MongoDB circuit breaker configuration, a threshold of 0 disables the breaker.
+type Breaker struct {
+	Threshold int // Threshold is the number of consecutive MongoDB failures that open the breaker.
+	Cooldown  int // Cooldown is the seconds the breaker fast-fails before letting a trial operation through.
+}
+
+// Limits holds the size limits applied to URL documents, 0 disables a limit.
+type Limits struct {
+	MaxAddressLength int // MaxAddressLength is the max. length of the URL address in bytes.
+	MaxSourceLength  int // MaxSourceLength is the max. length of the URL source in bytes.
+	MaxDocumentSize  int // MaxDocumentSize is the max. size of the encoded URL document in bytes.
+}
+
 // OutboundMessage holds configuration settings for outbound message service.
 type OutboundMessage struct {
-	BatchSize int // BatchSize is the max. number of concurrent URL processing goroutines.
+	BatchSize      int // BatchSize is the max. number of URLs fetched per scan.
+	ConcurrencyCap int // ConcurrencyCap caps concurrent publishes to the downstream (proxy) capacity, 0 disables it.
+	ClaimLease     int // ClaimLease is the seconds a claimed URL may stay unpublished before it is released.
+	Prefetch       int // Prefetch is the number of batches claimed ahead of the one being published, 0 disables it.
+	ScanInterval   int // ScanInterval is the seconds between scans for pending URLs, hot-reloadable.
+
+	// DisableClaims opts out of claiming URLs before publishing them, only safe with a single outbound instance.
+	DisableClaims bool
+
+	// DisallowedHosts are hosts, subdomains included, whose URLs are skipped instead of published.
+	DisallowedHosts []string
 }
 
 // InboundMessage holds configuration settings for inbound message service.
 type InboundMessage struct {
 	BatchSize  int    // BatchSize is the max. number of concurrent URL processing goroutines.
-	QueueGroup string // QueueGroup is the NATS queue group for load balancing.
+	QueueGroup string // QueueGroup is the NATS queue group for load balancing, defaults to the NATS one.
+	MaxAge     int    // MaxAge is the max. seconds since the publish of a message for it to be saved, 0 disables it.
+	AckWait    int    // AckWait is the seconds before an unacked JetStream message is redelivered, 0 disables acks.
+	Timeout    int    // Timeout is the max. seconds a message may take to be processed, 0 keeps the default.
 }
 
 // NatsConfig holds configuration settings for NATS.
 type NatsConfig struct {
-	RpcHost string // RpcHost is the address of the NATS gRPC server.
-	RpcPort string // RpcPort is the port number of the NATS gRPC server.
+	RpcHost      string // RpcHost is the address of the NATS gRPC server.
+	RpcPort      string // RpcPort is the port number of the NATS gRPC server.
+	RpcTransport string // RpcTransport is "tcp", or "inprocess" to reach a BusService co-located in the process.
+	RpcTLSName   string // RpcTLSName overrides the name the NATS gRPC server certificate is verified against.
+	MaxStreams   int    // MaxStreams caps the concurrent NATS subscribe streams of the client, 0 is unlimited.
+	QueueStreams bool   // QueueStreams waits for a free subscribe stream beyond MaxStreams instead of failing.
+	QueueGroup   string // QueueGroup is the default NATS queue group of the url-service consumers.
 }
 
 // TLSConfig holds configuration settings for TLS.
@@ -54,24 +117,77 @@ type TLSConfig struct {
 
 // loadConfig loads configuration falling back to default values.
 func loadConfig() *Config {
+	nats := loadNatsConfig()
 	return &Config{
-		Nats:            loadNatsConfig(),
+		Nats:            nats,
 		TLS:             loadTLSConfig(),
-		InboundMessage:  loadInboundMessageConfig(),
+		InboundMessage:  loadInboundMessageConfig(nats.QueueGroup),
 		OutboundMessage: loadOutboundMessageConfig(),
+		Limits:          loadLimitsConfig(),
+		Metrics:         loadMetricsConfig(),
+		Run:             loadRunConfig(),
+		Retention:       loadRetentionConfig(),
+		Breaker:         loadBreakerConfig(),
+		LogLevel:        getEnv("LOG_LEVEL", "info"),
+		LogFormat:       getEnv("LOG_FORMAT", "json"),
+		LogAddSource:    getEnvAsBool("LOG_ADD_SOURCE", false),
+		ReloadFile:      getEnv("RELOAD_ENV_FILE", ""),
 		Env:             getEnv("ENV", "dev"),
 	}
 }
 
+// loadMetricsConfig loads metrics server configuration.
+func loadMetricsConfig() MetricsConfig {
+	metrics := MetricsConfig{
+		ServerPort: getEnv("METRICS_SERVER_PORT", ""),
+	}
+
+	checkRequiredVars("METRICS", map[string]string{
+		"METRICS_SERVER_PORT": metrics.ServerPort,
+	})
+	return metrics
+}
+
+// loadRunConfig loads job-style run limits, by default the service runs until a signal.
+func loadRunConfig() RunConfig {
+	return RunConfig{
+		MaxRuntime:  getEnvAsInt("RUN_MAX_RUNTIME", 0),
+		MaxMessages: getEnvAsInt("RUN_MAX_MESSAGES", 0),
+	}
+}
+
+// loadRetentionConfig loads the retention policy, failed URLs are kept for 30 days by default.
+func loadRetentionConfig() Retention {
+	return Retention{
+		FailedMaxAge: getEnvAsInt("RETENTION_FAILED_MAX_AGE_HOURS", 720),
+	}
+}
+
+// loadBreakerConfig loads the MongoDB circuit breaker configuration.
+func loadBreakerConfig() Breaker {
+	return Breaker{
+		Threshold: getEnvAsInt("MONGO_BREAKER_THRESHOLD", 5),
+		Cooldown:  getEnvAsInt("MONGO_BREAKER_COOLDOWN", 30),
+	}
+}
+
 // loadInboundMessageConfig loads inbound message service configuration.
-func loadInboundMessageConfig() InboundMessage {
+// The consumer is load-balanced, an unset queue group falls back to defaultQueueGroup.
+func loadInboundMessageConfig(defaultQueueGroup string) InboundMessage {
 	inboundMessage := InboundMessage{
 		BatchSize:  getEnvAsInt("INBOUND_MESSAGE_BATCH_SIZE", 0),
-		QueueGroup: getEnv("INBOUND_MESSAGE_QUEUE_GROUP", ""),
+		QueueGroup: strings.TrimSpace(getEnv("INBOUND_MESSAGE_QUEUE_GROUP", "")),
+		MaxAge:     getEnvAsInt("INBOUND_MESSAGE_MAX_AGE", 0),
+		AckWait:    getEnvAsInt("INBOUND_MESSAGE_ACK_WAIT", 0),
+		Timeout:    getEnvAsInt("INBOUND_MESSAGE_TIMEOUT", 0),
+	}
+	if inboundMessage.QueueGroup == "" {
+		inboundMessage.QueueGroup = strings.TrimSpace(defaultQueueGroup)
 	}
 
 	checkRequiredVars("INBOUND_MESSAGE", map[string]string{
-		"INBOUND_MESSAGE_BATCH_SIZE": string(rune(inboundMessage.BatchSize)),
+		"INBOUND_MESSAGE_BATCH_SIZE":  string(rune(inboundMessage.BatchSize)),
+		"INBOUND_MESSAGE_QUEUE_GROUP": inboundMessage.QueueGroup,
 	})
 	return inboundMessage
 }
@@ -79,7 +195,14 @@ func loadInboundMessageConfig() InboundMessage {
 // loadOutboundMessageConfig loads outbound message service configuration.
 func loadOutboundMessageConfig() OutboundMessage {
 	outboundMessage := OutboundMessage{
-		BatchSize: getEnvAsInt("OUTBOUND_MESSAGE_BATCH_SIZE", 0),
+		BatchSize:      getEnvAsInt("OUTBOUND_MESSAGE_BATCH_SIZE", 0),
+		ConcurrencyCap: getEnvAsInt("OUTBOUND_MESSAGE_CONCURRENCY_CAP", 0),
+		ClaimLease:     getEnvAsInt("OUTBOUND_MESSAGE_CLAIM_LEASE", 300),
+		Prefetch:       getEnvAsInt("OUTBOUND_MESSAGE_PREFETCH", 0),
+		ScanInterval:   getEnvAsInt("OUTBOUND_MESSAGE_SCAN_INTERVAL", 300),
+
+		DisallowedHosts: getEnvAsList("OUTBOUND_MESSAGE_DISALLOWED_HOSTS"),
+		DisableClaims:   getEnvAsBool("OUTBOUND_MESSAGE_DISABLE_CLAIMS", false),
 	}
 
 	checkRequiredVars("OUTBOUND_MESSAGE_BATCH_SIZE", map[string]string{
@@ -88,6 +211,15 @@ func loadOutboundMessageConfig() OutboundMessage {
 	return outboundMessage
 }
 
+// loadLimitsConfig loads URL document size limits, the defaults are deliberately generous.
+func loadLimitsConfig() Limits {
+	return Limits{
+		MaxAddressLength: getEnvAsInt("URL_MAX_ADDRESS_LENGTH", 8*1024),
+		MaxSourceLength:  getEnvAsInt("URL_MAX_SOURCE_LENGTH", 1024),
+		MaxDocumentSize:  getEnvAsInt("URL_MAX_DOCUMENT_SIZE", 64*1024),
+	}
+}
+
 // loadTLSConfig loads TLS configuration.
 func loadTLSConfig() TLSConfig {
 	tls := TLSConfig{
@@ -105,8 +237,13 @@ func loadTLSConfig() TLSConfig {
 // loadNatsConfig loads NATS configuration.
 func loadNatsConfig() NatsConfig {
 	nats := NatsConfig{
-		RpcHost: getEnv("NATS_RPC_HOST", "localhost"),
-		RpcPort: getEnv("NATS_RPC_PORT", ""),
+		RpcHost:      getEnv("NATS_RPC_HOST", "localhost"),
+		RpcPort:      getEnv("NATS_RPC_PORT", ""),
+		RpcTransport: getEnv("NATS_RPC_TRANSPORT", "tcp"),
+		RpcTLSName:   getEnv("NATS_RPC_TLS_SERVER_NAME", ""),
+		MaxStreams:   getEnvAsInt("NATS_RPC_MAX_STREAMS", 0),
+		QueueStreams: getEnvAsBool("NATS_RPC_QUEUE_STREAMS", false),
+		QueueGroup:   getEnv("NATS_QUEUE_GROUP", "url-service"),
 	}
 
 	checkRequiredVars("NATS", map[string]string{
@@ -133,6 +270,25 @@ func getEnvAsInt(key string, fallback int) int {
 	return fallback
 }
 
+// getEnvAsBool fetches the value of an environment variable as a boolean or returns a fallback.
+func getEnvAsBool(key string, fallback bool) bool {
+	v := getEnv(key, "")
+	if value, err := strconv.ParseBool(v); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvAsList fetches the value of an environment variable as a comma-separated list, blank items are dropped.
+func getEnvAsList(key string) (list []string) {
+	for _, item := range strings.Split(getEnv(key, ""), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // checkRequiredVars ensures required environment variables are set.
 func checkRequiredVars(section string, vars map[string]string) {
 	for key, value := range vars {
@@ -1,11 +1,15 @@
 package application
 
 import (
+	"shared/clock"
 	"shared/dependency"
 	"shared/grpc/clients/nats_service"
+	"shared/runlimit"
 	"time"
 	"url-service/application/config"
 	"url-service/application/services/messages"
+	"url-service/application/services/migrations"
+	"url-service/application/services/retention"
 	"url-service/domain/entities"
 	"url-service/infrastructure"
 )
@@ -16,8 +20,11 @@ type Container struct {
 	Infrastructure         dependency.LazyDependency[*infrastructure.Container]
 	NatsGrpcValidator      dependency.LazyDependency[nats_service.Validator]
 	NatsGrpcClient         dependency.LazyDependency[*nats_service.NatsClient]
+	RunBudget              dependency.LazyDependency[*runlimit.Budget]
 	InboundMessageService  dependency.LazyDependency[*messages.InboundMessageService]
 	OutboundMessageService dependency.LazyDependency[*messages.OutboundMessageService]
+	BackfillService        dependency.LazyDependency[*migrations.BackfillService]
+	CleanupService         dependency.LazyDependency[*retention.CleanupService]
 }
 
 // NewContainer initializes and returns a new Container with dependencies.
@@ -41,6 +48,8 @@ func NewContainer() *Container {
 				logger     = c.Infrastructure.Get().Logger.Get()
 				env        = c.Config.Get().Env
 				validator  = c.NatsGrpcValidator.Get()
+				serverName = nats_service.WithServerName(c.Config.Get().Nats.RpcTLSName)
+				maxStreams = nats_service.WithMaxStreams(c.Config.Get().Nats.MaxStreams, c.Config.Get().Nats.QueueStreams)
 				natsClient *nats_service.NatsClient
 				address    string
 				err        error
@@ -48,12 +57,22 @@ func NewContainer() *Container {
 			if address, err = entities.GetNats().Address(); err != nil {
 				panic(err)
 			}
-			if natsClient, err = nats_service.NewNatsClient(env, address, validator, logger); err != nil {
+			natsClient, err = nats_service.NewNatsClient(env, address, validator, logger, serverName, maxStreams)
+			if err != nil {
 				panic(err)
 			}
 			return natsClient
 		},
 	}
+	c.RunBudget = dependency.LazyDependency[*runlimit.Budget]{
+		InitFunc: func() *runlimit.Budget {
+			run := c.Config.Get().Run
+			return runlimit.NewBudget(runlimit.Limits{
+				MaxRuntime:  time.Duration(run.MaxRuntime) * time.Second,
+				MaxMessages: int64(run.MaxMessages),
+			})
+		},
+	}
 	c.InboundMessageService = dependency.LazyDependency[*messages.InboundMessageService]{
 		InitFunc: func() *messages.InboundMessageService {
 			var (
@@ -62,20 +81,64 @@ func NewContainer() *Container {
 				urlRepository = c.Infrastructure.Get().MongoRepository.Get()
 				batchSize     = c.Config.Get().InboundMessage.BatchSize
 				queueGroup    = c.Config.Get().InboundMessage.QueueGroup
+				limits        = c.Config.Get().Limits
+				budget        = c.RunBudget.Get()
+				metrics       = c.Infrastructure.Get().ConsumerMetrics.Get()
+				maxAge        = time.Duration(c.Config.Get().InboundMessage.MaxAge) * time.Second
+				ackWait       = time.Duration(c.Config.Get().InboundMessage.AckWait) * time.Second
+				timeout       = time.Duration(c.Config.Get().InboundMessage.Timeout) * time.Second
 			)
-			return messages.NewInboundMessageService(natsClient, urlRepository, batchSize, queueGroup, logger)
+			return messages.NewInboundMessageService(natsClient, urlRepository, batchSize, queueGroup,
+				entities.SizeLimits{
+					MaxAddressLength: limits.MaxAddressLength,
+					MaxSourceLength:  limits.MaxSourceLength,
+					MaxDocumentSize:  limits.MaxDocumentSize,
+				}, logger, messages.WithInboundBudget(budget), messages.WithConsumerMetrics(metrics),
+				messages.WithMaxMessageAge(maxAge), messages.WithAckWait(ackWait), messages.WithProcessTimeout(timeout))
 		},
 	}
 	c.OutboundMessageService = dependency.LazyDependency[*messages.OutboundMessageService]{
 		InitFunc: func() *messages.OutboundMessageService {
+			var (
+				logger         = c.Infrastructure.Get().Logger.Get()
+				natsClient     = c.NatsGrpcClient.Get()
+				urlRepository  = c.Infrastructure.Get().MongoRepository.Get()
+				interval       = time.Duration(c.Config.Get().OutboundMessage.ScanInterval) * time.Second
+				batchSize      = c.Config.Get().OutboundMessage.BatchSize
+				concurrencyCap = c.Config.Get().OutboundMessage.ConcurrencyCap
+				metrics        = c.Infrastructure.Get().OutboundMetrics.Get()
+				claimLease     = time.Duration(c.Config.Get().OutboundMessage.ClaimLease) * time.Second
+				prefetch       = c.Config.Get().OutboundMessage.Prefetch
+				budget         = c.RunBudget.Get()
+				claims         = messages.WithClaims(claimLease)
+			)
+			if c.Config.Get().OutboundMessage.DisableClaims {
+				claims = messages.WithoutClaims()
+			}
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, clock.NewReal(), logger,
+				claims, messages.WithPrefetch(prefetch), messages.WithBudget(budget),
+				messages.WithDisallowedHosts(c.Config.Get().OutboundMessage.DisallowedHosts...))
+		},
+	}
+	c.BackfillService = dependency.LazyDependency[*migrations.BackfillService]{
+		InitFunc: func() *migrations.BackfillService {
+			var (
+				logger        = c.Infrastructure.Get().Logger.Get()
+				urlRepository = c.Infrastructure.Get().MongoRepository.Get()
+				batchSize     = 500
+			)
+			return migrations.NewBackfillService(urlRepository, batchSize, logger)
+		},
+	}
+	c.CleanupService = dependency.LazyDependency[*retention.CleanupService]{
+		InitFunc: func() *retention.CleanupService {
 			var (
 				logger        = c.Infrastructure.Get().Logger.Get()
-				natsClient    = c.NatsGrpcClient.Get()
 				urlRepository = c.Infrastructure.Get().MongoRepository.Get()
-				interval      = time.Duration(5) * time.Minute
-				batchSize     = c.Config.Get().OutboundMessage.BatchSize
+				maxAge        = time.Duration(c.Config.Get().Retention.FailedMaxAge) * time.Hour
 			)
-			return messages.NewOutboundMessageService(natsClient, urlRepository, interval, batchSize, logger)
+			return retention.NewCleanupService(urlRepository, maxAge, clock.NewReal(), logger)
 		},
 	}
 
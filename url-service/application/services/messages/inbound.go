@@ -3,54 +3,248 @@ package messages
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"shared/grpc/clients/nats_service"
 	"shared/grpc/clients/nats_service/messaging"
+	"shared/retry"
+	"shared/runlimit"
+	"strings"
 	"time"
 	"url-service/domain/entities"
 	"url-service/domain/interfaces"
+	"url-service/infrastructure/metrics"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrQueueGroupRequired is returned when a load-balanced consumer is started without a queue group.
+var ErrQueueGroupRequired = errors.New("queue group is required for load-balanced consumers")
+
+// ErrAcksUnsupported is returned when acks are enabled with a NATS client that cannot subscribe with acks.
+var ErrAcksUnsupported = errors.New("NATS client does not support acked subscriptions")
+
+// ErrProcessTimeout is returned when a message is not processed within the processing timeout.
+var ErrProcessTimeout = errors.New("message processing timed out")
+
+// DefaultProcessTimeout is the default time a message may take to be processed, its save included.
+const DefaultProcessTimeout = time.Duration(10) * time.Second
+
+// Default subscribe retry delays, the delay doubles after every failed attempt up to the max. delay.
+const (
+	DefaultSubscribeRetryDelay    = time.Second
+	DefaultMaxSubscribeRetryDelay = time.Duration(30) * time.Second
 )
 
 // InboundMessageService coordinates processing of URL messages received from a NATS subject.
+// It is load-balanced: instances sharing the queue group each receive a share of the messages,
+// so scaling it out never saves a URL twice. URLs are upserted by address, a republished URL is not saved again.
 type InboundMessageService struct {
-	natsClient    *nats_service.NatsClient // natsClient is used for NATS subscriptions and publishing.
-	urlRepository interfaces.UrlRepository // urlRepository is used for interacting with the persistence layer.
-	batchSize     int                      // batchSize determines the max. number of URL processing goroutines.
-	semaphore     chan struct{}            // semaphore is used to limit the number of processing goroutines.
-	queueGroup    string                   // queueGroup is the NATS queue group for load balancing.
-	logger        *slog.Logger             // logger for structured logging.
+	natsClient     nats_service.Client      // natsClient is used for NATS subscriptions and publishing.
+	urlRepository  interfaces.UrlRepository // urlRepository is used for interacting with the persistence layer.
+	batchSize      int                      // batchSize determines the max. number of URL processing goroutines.
+	semaphore      chan struct{}            // semaphore is used to limit the number of processing goroutines.
+	queueGroup     string                   // queueGroup is the NATS queue group for load balancing.
+	limits         entities.SizeLimits      // limits is the size limits applied to incoming URLs.
+	budget         *runlimit.Budget         // budget counts the saved URLs of a job-style run, nil is unlimited.
+	metrics        *metrics.ConsumerMetrics // metrics records the consumer lag, in-flight and saved URLs, nil disables it.
+	maxAge         time.Duration            // maxAge drops messages published longer ago, 0 disables it.
+	ackWait        time.Duration            // ackWait is the redelivery delay of unacked messages, 0 disables acks.
+	retryDelay     time.Duration            // retryDelay is the delay before the first subscribe retry.
+	maxRetryDelay  time.Duration            // maxRetryDelay caps the delay between subscribe retries.
+	processTimeout time.Duration            // processTimeout is the time a message may take to be processed.
+	logger         *slog.Logger             // logger for structured logging.
+}
+
+// InboundOption defines a functional option for configuring InboundMessageService.
+type InboundOption func(*InboundMessageService)
+
+// WithInboundBudget counts every saved URL against the budget of a job-style run.
+// Messages delivered before the run stops are still saved, so a run may save a few more URLs than its limit.
+func WithInboundBudget(budget *runlimit.Budget) InboundOption {
+	return func(s *InboundMessageService) {
+		s.budget = budget
+	}
+}
+
+// WithConsumerMetrics records the delivery delay of enveloped messages carrying their publish time,
+// the number of messages being processed, the number of new and duplicate URLs and the failed messages.
+func WithConsumerMetrics(metrics *metrics.ConsumerMetrics) InboundOption {
+	return func(s *InboundMessageService) {
+		s.metrics = metrics
+	}
+}
+
+// WithMaxMessageAge drops messages published more than maxAge ago instead of saving them, e.g. after an outage.
+// Messages without a publish time are always saved, a zero maxAge disables the check.
+func WithMaxMessageAge(maxAge time.Duration) InboundOption {
+	return func(s *InboundMessageService) {
+		s.maxAge = maxAge
+	}
+}
+
+// WithAckWait subscribes through JetStream and acks every message only once its URL is saved, or once it is
+// dropped as invalid or stale. Messages that are not acked within ackWait, e.g. after a failed save or a crash
+// between receive and save, are redelivered; a message whose ack is lost after the save may be saved twice.
+// UrlIncoming must be captured by a JetStream stream, a zero ackWait keeps the core NATS subscription.
+func WithAckWait(ackWait time.Duration) InboundOption {
+	return func(s *InboundMessageService) {
+		s.ackWait = ackWait
+	}
+}
+
+// WithSubscribeRetry sets the delay before resubscribing after the subscription failed or ended,
+// e.g. while NATS is unavailable. The delay doubles after every failed attempt up to maxDelay.
+// Non-positive delays keep the defaults.
+func WithSubscribeRetry(delay, maxDelay time.Duration) InboundOption {
+	return func(s *InboundMessageService) {
+		if delay > 0 {
+			s.retryDelay = delay
+		}
+		if maxDelay > 0 {
+			s.maxRetryDelay = maxDelay
+		}
+	}
+}
+
+// WithProcessTimeout sets the time a message may take to be processed, its save included.
+// A message exceeding it is abandoned and counted as failed, so that a stuck save does not hold its processing slot;
+// with acks it is left unacked and redelivered. A non-positive timeout keeps the default.
+func WithProcessTimeout(timeout time.Duration) InboundOption {
+	return func(s *InboundMessageService) {
+		if timeout > 0 {
+			s.processTimeout = timeout
+		}
+	}
 }
 
 // NewInboundMessageService creates a new instance of InboundMessageService.
 func NewInboundMessageService(
-	natsClient *nats_service.NatsClient,
+	natsClient nats_service.Client,
 	urlRepository interfaces.UrlRepository,
 	batchSize int,
 	queueGroup string,
+	limits entities.SizeLimits,
 	logger *slog.Logger,
+	opts ...InboundOption,
 ) *InboundMessageService {
-	return &InboundMessageService{
-		natsClient:    natsClient,
-		urlRepository: urlRepository,
-		batchSize:     batchSize,
-		semaphore:     make(chan struct{}, batchSize),
-		queueGroup:    queueGroup,
-		logger:        logger,
+	s := &InboundMessageService{
+		natsClient:     natsClient,
+		urlRepository:  urlRepository,
+		batchSize:      batchSize,
+		semaphore:      make(chan struct{}, batchSize),
+		queueGroup:     queueGroup,
+		limits:         limits,
+		retryDelay:     DefaultSubscribeRetryDelay,
+		maxRetryDelay:  DefaultMaxSubscribeRetryDelay,
+		processTimeout: DefaultProcessTimeout,
+		logger:         logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Start subscribes to the UrlIncoming subject and processes incoming URL messages.
+// Start subscribes to the UrlIncoming subject and processes incoming URL messages until the context is canceled.
+// A subscription that ends or fails transiently (see retry.IsRetryable), e.g. while NATS is unavailable, is retried
+// with backoff, so that the service recovers from a dependency outage instead of idling.
+// Any other failure, such as an invalid request, stops the service.
 func (s *InboundMessageService) Start(ctx context.Context) (err error) {
-	return s.natsClient.Subscribe(ctx, messaging.UrlIncoming, s.queueGroup, s.messageHandler)
+	if strings.TrimSpace(s.queueGroup) == "" {
+		return ErrQueueGroupRequired
+	}
+	subscribe := func(ctx context.Context) error {
+		return s.natsClient.Subscribe(ctx, messaging.UrlIncoming, s.queueGroup, s.messageHandler)
+	}
+	if s.ackWait > 0 {
+		ackClient, ok := s.natsClient.(nats_service.AckClient)
+		if !ok {
+			return ErrAcksUnsupported
+		}
+		subscribe = func(ctx context.Context) error {
+			return ackClient.SubscribeWithAck(ctx, messaging.UrlIncoming, s.queueGroup, s.ackWait,
+				s.ackedMessageHandler)
+		}
+	}
+
+	delay := s.retryDelay
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+		if err = subscribe(ctx); ctx.Err() != nil {
+			return err
+		}
+		if err != nil && !retry.IsRetryable(err) {
+			s.logger.Error("Subscription failed permanently", "subject", messaging.UrlIncoming, "error", err)
+			return fmt.Errorf("subscribe: %w", err)
+		}
+		// A subscription that lasted longer than the max. delay was established, the backoff starts over
+		if time.Since(started) > s.maxRetryDelay {
+			delay, attempt = s.retryDelay, 1
+		}
+		s.logger.Warn("Subscription ended, retrying", "subject", messaging.UrlIncoming, "attempt", attempt,
+			"retryIn", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, s.maxRetryDelay)
+	}
 }
 
 // messageHandler is the callback function that processes each incoming message.
 func (s *InboundMessageService) messageHandler(data []byte, subject string) {
+	s.ackedMessageHandler(data, subject, nil)
+}
+
+// ackedMessageHandler processes an incoming message and acks it once it is handled for good, a nil ack is skipped.
+// Messages may be enveloped, legacy messages without an envelope are processed as they are.
+func (s *InboundMessageService) ackedMessageHandler(data []byte, subject string, ack func(ctx context.Context) error) {
+	envelope, err := messaging.Open(data)
+	if err != nil {
+		s.logger.Error("Invalid message envelope", "subject", subject, "error", err)
+		s.ack(ack, subject)
+		return
+	}
+	now := time.Now()
+	if lag, ok := envelope.Lag(now); ok && s.metrics != nil {
+		s.metrics.ObserveLag(subject, lag)
+	}
+	if envelope.Stale(now, s.maxAge) {
+		s.logger.Warn("Dropping stale message", "subject", subject, "publishedAt", envelope.PublishedAt,
+			"maxAge", s.maxAge)
+		if s.metrics != nil {
+			s.metrics.IncStaleDropped(subject)
+		}
+		s.ack(ack, subject)
+		return
+	}
+	data = envelope.Payload
+
+	if s.limits.MaxDocumentSize > 0 && len(data) > s.limits.MaxDocumentSize {
+		s.logger.Error("Message exceeds max. document size", "subject", subject,
+			"size", len(data), "limit", s.limits.MaxDocumentSize)
+		s.ack(ack, subject)
+		return
+	}
 	s.semaphore <- struct{}{}
+	if s.metrics != nil {
+		s.metrics.IncInFlight()
+	}
 
 	// Process a message.
 	go func(data []byte, subject string) {
-		defer func() { <-s.semaphore }()
+		defer func() {
+			if s.metrics != nil {
+				s.metrics.DecInFlight()
+			}
+			<-s.semaphore
+		}()
 		defer func() {
 			if r := recover(); r != nil {
 				s.logger.Error("Panic recovered in message handler", "subject", subject, "panic", r)
@@ -64,25 +258,109 @@ func (s *InboundMessageService) messageHandler(data []byte, subject string) {
 			err          error
 			url          = entities.GetUrl()
 			now          = time.Now()
-			saveCtx      context.Context
-			cancel       context.CancelFunc
+			release      = true
 		)
-		defer url.Release()
-
-		saveCtx, cancel = context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
-		defer cancel()
+		defer func() {
+			if release {
+				url.Release()
+			}
+		}()
 
 		if unmarshalErr = json.Unmarshal(data, url); unmarshalErr != nil {
 			s.logger.Error("JSON unmarshal failed", "subject", subject, "error", unmarshalErr)
+			s.ack(ack, subject)
+			return
+		}
+		if err = url.CheckSize(s.limits); err != nil {
+			s.logger.Error("URL exceeds size limits", "subject", subject, "error", err)
+			s.ack(ack, subject)
 			return
 		}
 
+		// The ID and the deletion are assigned by the repository, never taken from the wire
+		url.Id = primitive.NilObjectID
+		url.DeletedAt = time.Time{}
 		url.Status = entities.StatusPending
 		url.CreatedAt = now
-		if err = s.urlRepository.Save(saveCtx, url); err != nil {
-			s.logger.Error("Failed to save URL", "subject", subject, "error", err)
+		// An unsaved message is left unacked, so that it is redelivered
+		var inserted bool
+		if inserted, err = s.save(url); err != nil {
+			reason := "save"
+			if errors.Is(err, ErrProcessTimeout) || errors.Is(err, context.DeadlineExceeded) {
+				reason = "timeout"
+			}
+			// An abandoned save still uses the URL, it is released once the save returns
+			release = !errors.Is(err, ErrProcessTimeout)
+			s.logger.Error("Failed to save URL", "subject", subject, "reason", reason, "error", err)
+			if s.metrics != nil {
+				s.metrics.IncFailed(subject, reason)
+			}
 			return
 		}
-		s.logger.Info("Successfully saved URL", "url", url)
+		if inserted {
+			s.logger.Info("Successfully saved URL", "url", url)
+		} else {
+			s.logger.Info("Skipped duplicate URL", "address", url.Address)
+		}
+		s.countSaved(inserted)
+		s.ack(ack, subject)
+		s.budget.Done()
 	}(data, subject)
 }
+
+// save upserts the URL within the processing timeout and reports whether it was inserted.
+// A save that has not returned by then, e.g. one ignoring its context, is abandoned with ErrProcessTimeout
+// and releases the URL once it returns.
+func (s *InboundMessageService) save(url *entities.Url) (inserted bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.processTimeout)
+	defer cancel()
+
+	type result struct {
+		inserted bool
+		err      error
+	}
+	saved := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				saved <- result{err: fmt.Errorf("panic in save: %v", r)}
+			}
+		}()
+		inserted, err := s.urlRepository.Upsert(ctx, url)
+		saved <- result{inserted: inserted, err: err}
+	}()
+
+	select {
+	case res := <-saved:
+		return res.inserted, res.err
+	case <-ctx.Done():
+		go func() {
+			<-saved
+			url.Release()
+		}()
+		return false, ErrProcessTimeout
+	}
+}
+
+// countSaved records a new or a duplicate URL, a nil metrics is skipped.
+func (s *InboundMessageService) countSaved(inserted bool) {
+	switch {
+	case s.metrics == nil:
+	case inserted:
+		s.metrics.IncNewUrls()
+	default:
+		s.metrics.IncDuplicateUrls()
+	}
+}
+
+// ack confirms a handled message, a failed ack is logged and the message is redelivered.
+func (s *InboundMessageService) ack(ack func(ctx context.Context) error, subject string) {
+	if ack == nil {
+		return
+	}
+	ackCtx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+	if err := ack(ackCtx); err != nil {
+		s.logger.Error("Failed to ack message", "subject", subject, "error", err)
+	}
+}
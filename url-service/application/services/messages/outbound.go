@@ -4,87 +4,419 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	neturl "net/url"
+	"shared/clock"
 	"shared/grpc/clients/nats_service"
 	"shared/grpc/clients/nats_service/messaging"
+	"shared/runlimit"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"url-service/domain/entities"
 	"url-service/domain/interfaces"
+	"url-service/infrastructure/metrics"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
+// maxScanBackoff caps the backoff of inconsistent scans, the scan interval doubles at most that many times.
+const maxScanBackoff = 3
+
+// defaultClaimLease is the time a claimed URL may stay unpublished before it is released to pending.
+const defaultClaimLease = 5 * time.Minute
+
 // OutboundMessageService periodically scans MongoDB for pending URL entities and pushes them to a NATS subject.
+//
+// The service follows claim -> publish -> mark: URLs are atomically moved to processing before being published,
+// and only marked succeeded once the publish succeeded. A URL whose publish fails is released to pending right
+// away, and a URL whose owner crashed before marking it succeeded is released once its claim is older than the
+// lease. Delivery stays at-least-once, but a URL is never marked succeeded without having been published, and
+// concurrent instances never publish the same claim.
+// URLs that can never be published, e.g., with an invalid address, are marked permanently failed, and URLs of
+// disallowed hosts are marked skipped, so neither is scanned again.
+//
+// WithoutClaims opts out to read -> publish -> mark: pending URLs are read, published and then marked succeeded,
+// so a crash between the publish and the update republishes the URL on the next scan, and concurrent instances
+// may publish the same URL. It is only safe with a single instance.
+//
+// With WithPrefetch, a scan that finds a full batch keeps going through the backlog: the next batches are claimed
+// while the current one is published, so the publishes never wait for a fetch or a tick.
+//
+// A scan that finds no work while URLs are still pending points at a misconfigured scan filter: the inconsistency
+// is reported, and the scan cadence backs off until a scan finds work again.
 type OutboundMessageService struct {
-	natsClient    *nats_service.NatsClient
+	natsClient    nats_service.Client
 	urlRepository interfaces.UrlRepository
 	batchSize     int
 	semaphore     chan struct{}
 	interval      time.Duration
+	intervalMu    sync.Mutex
+	intervalSet   chan struct{}
+	claimLease    time.Duration
+	prefetch      int // prefetch is the number of batches claimed ahead of the one being published.
+	inconsistent  int // inconsistent is the number of consecutive empty scans while URLs were pending.
+	budget        *runlimit.Budget
+	disallowed    []string
+	metrics       *metrics.OutboundMetrics
+	clock         clock.Clock
 	logger        *slog.Logger
 }
 
+// OutboundOption defines a functional option for configuring OutboundMessageService.
+type OutboundOption func(*OutboundMessageService)
+
+// WithClaims sets the lease of the claim -> publish -> mark flow, claims older than lease are released to pending.
+// A non-positive lease keeps the default lease.
+func WithClaims(lease time.Duration) OutboundOption {
+	return func(s *OutboundMessageService) {
+		if lease > 0 {
+			s.claimLease = lease
+		}
+	}
+}
+
+// WithoutClaims opts out of claims for the read -> publish -> mark flow, concurrent instances may then publish
+// the same URL.
+func WithoutClaims() OutboundOption {
+	return func(s *OutboundMessageService) {
+		s.claimLease = 0
+	}
+}
+
+// WithPrefetch claims up to depth batches ahead of the one being published while a scan finds full batches,
+// keeping the publishes busy under a large backlog. It relies on claims, so that overlapping batches never
+// return the same URL, and the lease must cover the wait of the prefetched batches. A non-positive depth,
+// or WithoutClaims, processes a single batch per scan.
+func WithPrefetch(depth int) OutboundOption {
+	return func(s *OutboundMessageService) {
+		s.prefetch = depth
+	}
+}
+
+// WithBudget counts every published and updated URL against the budget of a job-style run.
+// A scan never fetches more URLs than the budget has left, so a run never processes more URLs than its limit.
+func WithBudget(budget *runlimit.Budget) OutboundOption {
+	return func(s *OutboundMessageService) {
+		s.budget = budget
+	}
+}
+
+// WithDisallowedHosts skips the URLs of the given hosts and their subdomains instead of publishing them.
+func WithDisallowedHosts(hosts ...string) OutboundOption {
+	return func(s *OutboundMessageService) {
+		for _, host := range hosts {
+			s.disallowed = append(s.disallowed, strings.ToLower(strings.TrimSuffix(host, ".")))
+		}
+	}
+}
+
 // NewOutboundMessageService creates a new instance of OutboundMessageService.
+// The number of concurrent publishes is bounded by batchSize and, when positive, by concurrencyCap,
+// which should be aligned with the downstream (proxy) capacity, e.g., its connection pool size.
 func NewOutboundMessageService(
-	natsClient *nats_service.NatsClient,
+	natsClient nats_service.Client,
 	urlRepository interfaces.UrlRepository,
 	interval time.Duration,
 	batchSize int,
+	concurrencyCap int,
+	metrics *metrics.OutboundMetrics,
+	clock clock.Clock,
 	logger *slog.Logger,
+	opts ...OutboundOption,
 ) *OutboundMessageService {
-	return &OutboundMessageService{
+	concurrency := batchSize
+	if concurrencyCap > 0 && concurrencyCap < batchSize {
+		concurrency = concurrencyCap
+	}
+
+	s := &OutboundMessageService{
 		natsClient:    natsClient,
 		urlRepository: urlRepository,
 		batchSize:     batchSize,
-		semaphore:     make(chan struct{}, batchSize),
+		semaphore:     make(chan struct{}, concurrency),
 		interval:      interval,
+		intervalSet:   make(chan struct{}, 1),
+		claimLease:    defaultClaimLease,
+		metrics:       metrics,
+		clock:         clock,
 		logger:        logger,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Start begins the periodic scanning and publishing process.
 func (s *OutboundMessageService) Start(ctx context.Context) {
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	current := s.scanInterval()
+	ticker := s.clock.NewTicker(current)
+	defer func() { ticker.Stop() }()
 
 	for {
 		select {
 		case <-ctx.Done():
 			s.logger.Info("Context canceled, outbound service stopped.")
 			return
-		case <-ticker.C:
+		case <-s.intervalSet:
+			// Restart the ticker, the next scan happens one new interval from now.
+			ticker.Stop()
+			current = s.scanInterval()
+			ticker = s.clock.NewTicker(current)
+		case <-ticker.C():
 			s.scan(ctx)
+			if next := s.scanInterval(); next != current {
+				// The scan started or stopped backing off.
+				ticker.Stop()
+				current = next
+				ticker = s.clock.NewTicker(current)
+			}
 		}
 	}
 }
 
+// Interval returns the current scan interval.
+func (s *OutboundMessageService) Interval() time.Duration {
+	s.intervalMu.Lock()
+	defer s.intervalMu.Unlock()
+	return s.interval
+}
+
+// scanInterval returns the interval until the next scan, the scan interval doubled for each consecutive
+// inconsistent scan, up to maxScanBackoff times.
+func (s *OutboundMessageService) scanInterval() time.Duration {
+	return s.Interval() << min(s.inconsistent, maxScanBackoff)
+}
+
+// SetInterval changes the scan interval of a running service without interrupting an ongoing scan.
+// Non-positive or unchanged intervals are ignored.
+func (s *OutboundMessageService) SetInterval(interval time.Duration) {
+	s.intervalMu.Lock()
+	if interval <= 0 || interval == s.interval {
+		s.intervalMu.Unlock()
+		return
+	}
+	s.interval = interval
+	s.intervalMu.Unlock()
+
+	s.logger.Info("Scan interval updated", "interval", interval)
+	select {
+	case s.intervalSet <- struct{}{}:
+	default:
+		// A pending notification already picks up the latest interval.
+	}
+}
+
 // scan retrieves for pending URL entities (up to the batchSize) and processes them.
+// It waits for the whole cycle to complete and records the number of successfully processed URLs.
 func (s *OutboundMessageService) scan(ctx context.Context) {
 	var (
-		filter = bson.M{"status": entities.StatusPending}
-		list   []*entities.Url
-		err    error
+		list      []*entities.Url
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+		err       error
 	)
+	defer func() { s.metrics.SetCycleSuccess(int(succeeded.Load())) }()
+
+	limit := s.batchLimit(0)
+	if limit == 0 {
+		return
+	}
 
-	if list, err = s.urlRepository.FetchBatch(ctx, filter, s.batchSize); err != nil {
+	if list, err = s.fetchPending(ctx, limit); err != nil {
 		s.logger.Error("Failed to fetch pending URLs", "error", err)
 		return
 	}
 
+	pending, err := s.countPending(ctx, list)
 	if len(list) == 0 {
-		s.logger.Info("No pending URLs found")
+		if err == nil {
+			s.checkBacklog(ctx, pending)
+		}
+		return
+	}
+	s.inconsistent = 0
+
+	if s.prefetch > 0 && s.claimLease > 0 {
+		s.pipeline(ctx, list, limit, &succeeded)
 		return
 	}
+	s.dispatch(ctx, list, &wg, &succeeded)
+	wg.Wait()
+}
+
+// batchLimit returns the number of URLs to fetch, the batch size bounded by what is left of the budget once the
+// outstanding URLs, fetched but not processed yet, are accounted for. Zero means no URL may be fetched.
+func (s *OutboundMessageService) batchLimit(outstanding int64) int {
+	remaining := s.budget.Remaining()
+	if remaining < 0 {
+		return s.batchSize
+	}
+	return int(max(min(remaining-outstanding, int64(s.batchSize)), 0))
+}
 
-	// Launch a goroutine for each URL while respecting the semaphore limit.
+// dispatch launches a goroutine for each URL while respecting the semaphore limit,
+// wg is done once every URL is processed.
+func (s *OutboundMessageService) dispatch(
+	ctx context.Context,
+	list []*entities.Url,
+	wg *sync.WaitGroup,
+	succeeded *atomic.Int32,
+) {
 	for _, url := range list {
 		s.semaphore <- struct{}{}
-		go s.processMessage(ctx, url)
+		s.metrics.IncInFlight()
+		wg.Add(1)
+		go func(url *entities.Url) {
+			defer wg.Done()
+			if s.processMessage(ctx, url) {
+				succeeded.Add(1)
+				s.budget.Done()
+			}
+		}(url)
+	}
+}
+
+// pipeline processes the backlog batch after batch, starting with the claimed list: while a batch is published,
+// up to prefetch further batches are claimed, until a batch comes back short of its limit, the budget is used up
+// or the context is canceled. Stale claims are only released by the first fetch of the scan.
+func (s *OutboundMessageService) pipeline(
+	ctx context.Context,
+	list []*entities.Url,
+	limit int,
+	succeeded *atomic.Int32,
+) {
+	var (
+		wg          sync.WaitGroup
+		batches     = make(chan []*entities.Url, s.prefetch)
+		slots       = make(chan struct{}, s.prefetch+1) // slots holds one token per claimed, unprocessed batch.
+		outstanding atomic.Int64                        // outstanding is the number of claimed, unprocessed URLs.
+	)
+	slots <- struct{}{}
+	outstanding.Add(int64(len(list)))
+	batches <- list
+
+	go func(full bool) {
+		defer close(batches)
+		for full {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+			var (
+				next []*entities.Url
+				err  error
+			)
+			if limit = s.batchLimit(outstanding.Load()); limit == 0 {
+				<-slots
+				return
+			}
+			if next, err = s.urlRepository.ClaimPending(ctx, limit); err != nil || len(next) == 0 {
+				if err != nil {
+					s.logger.Error("Failed to prefetch pending URLs", "error", err)
+				}
+				<-slots
+				return
+			}
+			outstanding.Add(int64(len(next)))
+			full = len(next) == limit
+			batches <- next
+		}
+	}(len(list) == limit)
+
+	for batch := range batches {
+		var batchWg sync.WaitGroup
+		s.dispatch(ctx, batch, &batchWg, succeeded)
+		wg.Add(1)
+		go func(size int) {
+			defer wg.Done()
+			batchWg.Wait()
+			outstanding.Add(-int64(size))
+			<-slots
+		}(len(batch))
+	}
+	wg.Wait()
+}
+
+// checkBacklog checks the pending URLs counted after a scan found no work. Pending URLs the scan cannot see
+// point at a misconfigured scan filter, the inconsistency is counted and the scan cadence backs off.
+func (s *OutboundMessageService) checkBacklog(ctx context.Context, pending int64) {
+	if pending == 0 {
+		s.inconsistent = 0
+		s.logger.Info("No pending URLs found")
+		return
+	}
+
+	// URLs scheduled for later are invisible to the scan by design, only eligible ones point at the filter.
+	eligible, err := s.urlRepository.Count(ctx, entities.PendingFilter(s.clock.Now()))
+	if err != nil {
+		s.logger.Error("Failed to count eligible pending URLs", "error", err)
+		return
+	}
+	if eligible == 0 {
+		s.inconsistent = 0
+		s.logger.Info("Only scheduled pending URLs found", "pending", pending)
+		return
+	}
+	s.inconsistent++
+	s.metrics.ObserveInconsistency()
+	s.logger.Error("Scan found no work while URLs are pending, check the scan filter",
+		"pending", pending, "inconsistentScans", s.inconsistent, "nextScan", s.scanInterval())
+}
+
+// countPending reports and returns the pending URLs left once the scan fetched its batch, so a growing backlog
+// shows while every scan still finds work. Without claims the fetched batch is still pending until it is marked,
+// so it is not counted as backlog.
+func (s *OutboundMessageService) countPending(ctx context.Context, list []*entities.Url) (pending int64, err error) {
+	if pending, err = s.urlRepository.Count(ctx, bson.M{"status": entities.StatusPending}); err != nil {
+		s.logger.Error("Failed to count pending URLs", "error", err)
+		return 0, err
+	}
+	if s.claimLease <= 0 {
+		pending = max(pending-int64(len(list)), 0)
+	}
+	s.metrics.SetPending(pending)
+	return pending, nil
+}
+
+// fetchPending returns up to limit pending URLs of the cycle, claiming them unless claims are disabled.
+func (s *OutboundMessageService) fetchPending(ctx context.Context, limit int) (list []*entities.Url, err error) {
+	if s.claimLease <= 0 {
+		return s.urlRepository.FetchBatch(ctx, entities.PendingFilter(s.clock.Now()), limit)
+	}
+
+	var released int
+	if released, err = s.urlRepository.ReleaseStale(ctx, s.clock.Now().Add(-s.claimLease)); err != nil {
+		// Stale claims are retried on the next cycle, the fresh pending URLs can still be processed.
+		s.logger.Error("Failed to release stale claims", "error", err)
+	} else if released > 0 {
+		s.logger.Warn("Released stale claims", "count", released, "lease", s.claimLease)
+	}
+	return s.urlRepository.ClaimPending(ctx, limit)
+}
+
+// releaseClaim returns a claimed URL whose publish failed to pending, so the next cycle retries it.
+func (s *OutboundMessageService) releaseClaim(ctx context.Context, url *entities.Url) {
+	updateFields := bson.M{
+		"status":        entities.StatusPending,
+		"status_reason": "publish failed",
+		"updated_at":    s.clock.Now(),
+	}
+	if err := s.urlRepository.UpdateFields(ctx, url.Id.Hex(), updateFields); err != nil {
+		// The claim expires after the lease and the URL is released by a later cycle.
+		s.logger.Error("Failed to release claimed URL", "urlID", url.Id.Hex(), "error", err)
 	}
 }
 
 // processMessage serializes URL entity, publishes it to a NATS subject, and updates its status.
-func (s *OutboundMessageService) processMessage(ctx context.Context, url *entities.Url) {
-	defer func() { <-s.semaphore }()
+// It reports whether the URL was both published and updated, failures are counted by category.
+func (s *OutboundMessageService) processMessage(ctx context.Context, url *entities.Url) (ok bool) {
+	defer func() {
+		s.metrics.DecInFlight()
+		<-s.semaphore
+	}()
 	defer func() {
 		if r := recover(); r != nil {
 			s.logger.Error("Panic recovered in processMessage", "urlID", url.Id.Hex(), "panic", r)
@@ -99,27 +431,73 @@ func (s *OutboundMessageService) processMessage(ctx context.Context, url *entiti
 		updateErr  error
 	)
 
+	if status, reason := s.classify(url); status != "" {
+		s.logger.Warn("URL not published", "urlID", url.Id.Hex(), "status", status, "reason", reason)
+		s.markTerminal(ctx, url, status, reason)
+		return false
+	}
 	if data, marshalErr = json.Marshal(url); marshalErr != nil {
+		s.metrics.ObserveError(metrics.ErrorMarshal)
 		s.logger.Error("Failed to marshal URL", "urlID", url.Id.Hex(), "error", marshalErr)
-		return
+		s.markTerminal(ctx, url, entities.StatusPermanentlyFailed, "marshal failed")
+		return false
 	}
 	if pubErr = s.natsClient.Publish(ctx, messaging.UrlOutgoing, data); pubErr != nil {
+		s.metrics.ObserveError(metrics.ErrorPublish)
 		s.logger.Error("Failed to publish URL", "urlID", url.Id.Hex(), "error", pubErr)
-		return
+		if s.claimLease > 0 {
+			s.releaseClaim(ctx, url)
+		}
+		return false
 	}
 	s.logger.Info("Published URL", "urlID", url.Id.Hex(), "subject", messaging.UrlOutgoing)
 
-	// Update the URL's status to processed to avoid republishing.
-	now := time.Now()
+	// Update the URL's status to succeeded to avoid republishing.
+	now := s.clock.Now()
 	updateFields := bson.M{
-		"status":     entities.StatusProcessed,
-		"processed":  now,
-		"updated_at": now,
+		"status":        entities.StatusSucceeded,
+		"status_reason": "published",
+		"processed":     now,
+		"updated_at":    now,
 	}
 	if updateErr = s.urlRepository.UpdateFields(ctx, url.Id.Hex(), updateFields); updateErr != nil {
+		s.metrics.ObserveError(metrics.ErrorUpdate)
 		s.logger.Error("Failed to update URL", "urlID", url.Id.Hex(), "error", updateErr)
-		return
+		return false
 	}
 
 	s.logger.Info("Updated URL", "urlID", url.Id.Hex(), "updateFields", updateFields)
+	return true
+}
+
+// classify returns the terminal status and its reason of a URL that must not be published,
+// or an empty status when the URL is publishable.
+func (s *OutboundMessageService) classify(url *entities.Url) (status, reason string) {
+	parsed, err := neturl.Parse(url.Address)
+	if err != nil || parsed.Hostname() == "" {
+		return entities.StatusPermanentlyFailed, "invalid address"
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	for _, disallowed := range s.disallowed {
+		if host == disallowed || strings.HasSuffix(host, "."+disallowed) {
+			return entities.StatusSkipped, "disallowed host"
+		}
+	}
+	return "", ""
+}
+
+// markTerminal moves a URL that is not published to a terminal status, so it is never scanned again.
+func (s *OutboundMessageService) markTerminal(ctx context.Context, url *entities.Url, status, reason string) {
+	now := s.clock.Now()
+	updateFields := bson.M{
+		"status":        status,
+		"status_reason": reason,
+		"processed":     now,
+		"updated_at":    now,
+	}
+	if err := s.urlRepository.UpdateFields(ctx, url.Id.Hex(), updateFields); err != nil {
+		s.metrics.ObserveError(metrics.ErrorUpdate)
+		s.logger.Error("Failed to update URL", "urlID", url.Id.Hex(), "error", err)
+	}
 }
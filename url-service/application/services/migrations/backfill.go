@@ -0,0 +1,106 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"url-service/domain/entities"
+	"url-service/domain/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// BackfillService migrates URL documents written by an older schema version to the current one.
+type BackfillService struct {
+	urlRepository interfaces.UrlRepository // urlRepository is used for interacting with the persistence layer.
+	batchSize     int                      // batchSize is the number of documents migrated per batch.
+	logger        *slog.Logger             // logger for structured logging.
+}
+
+// NewBackfillService creates a new instance of BackfillService.
+func NewBackfillService(urlRepository interfaces.UrlRepository, batchSize int, logger *slog.Logger) *BackfillService {
+	return &BackfillService{urlRepository: urlRepository, batchSize: batchSize, logger: logger}
+}
+
+// Defaults returns the values backfilled into documents of an older schema version.
+// Defaults of newly added fields go here, together with a bump of entities.CurrentSchemaVersion.
+func Defaults() bson.M {
+	return bson.M{
+		"schema_version": entities.CurrentSchemaVersion,
+	}
+}
+
+// ProcessedStatus returns the fields replacing the deprecated processed status. Processed URLs were only ever
+// marked after a successful publish, so they map to the succeeded status.
+func ProcessedStatus() bson.M {
+	return bson.M{
+		"status":        entities.StatusSucceeded,
+		"status_reason": "migrated from processed",
+	}
+}
+
+// Run deletes the duplicate addresses, keeping the oldest URL of each, so the unique address index is built on the
+// next start, then backfills the defaults in batches until no outdated document is left.
+// Migrated documents are stamped with the current schema version, so running it repeatedly is safe.
+func (s *BackfillService) Run(ctx context.Context) (migrated int, err error) {
+	var (
+		filter = bson.M{"$or": bson.A{
+			bson.M{"schema_version": bson.M{"$exists": false}},
+			bson.M{"schema_version": bson.M{"$lt": entities.CurrentSchemaVersion}},
+		}}
+		defaults = Defaults()
+		list     []*entities.Url
+		result   *entities.BulkUpdateResult
+		deleted  int
+	)
+
+	if deleted, err = s.urlRepository.DeleteDuplicateAddresses(ctx); err != nil {
+		return migrated, fmt.Errorf("delete duplicate addresses: %w", err)
+	}
+	if deleted > 0 {
+		s.logger.Warn("Deleted duplicate addresses", "deleted", deleted)
+	}
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return migrated, err
+		}
+		if list, err = s.urlRepository.FetchBatch(ctx, filter, s.batchSize); err != nil {
+			return migrated, fmt.Errorf("fetch outdated batch: %w", err)
+		}
+		if len(list) == 0 {
+			s.logger.Info("Backfill completed", "migrated", migrated, "schemaVersion", entities.CurrentSchemaVersion)
+			return migrated, nil
+		}
+
+		var (
+			ids       = make([]string, 0, len(list))
+			processed []string
+		)
+		for _, url := range list {
+			ids = append(ids, url.Id.Hex())
+			if url.Status == entities.StatusProcessed {
+				processed = append(processed, url.Id.Hex())
+			}
+		}
+		// Statuses are migrated first, a failed batch is retried as a whole since its schema version is unchanged.
+		// Documents deleted since the fetch, e.g. by the retention cleanup, are skipped and not counted.
+		if len(processed) > 0 {
+			if result, err = s.urlRepository.BulkUpdateFieldsDetailed(ctx, processed, ProcessedStatus()); err != nil {
+				return migrated, fmt.Errorf("migrate processed status: %w", err)
+			}
+			if result.Partial() {
+				s.logger.Warn("Skipped processed URLs not found", "ids", result.Unmatched)
+			}
+		}
+		if result, err = s.urlRepository.BulkUpdateFieldsDetailed(ctx, ids, defaults); err != nil {
+			return migrated, fmt.Errorf("backfill batch: %w", err)
+		}
+		if result.Partial() {
+			s.logger.Warn("Skipped outdated URLs not found", "ids", result.Unmatched)
+		}
+
+		migrated += int(result.Matched)
+		s.logger.Info("Backfilled batch", "size", len(ids), "migrated", migrated)
+	}
+}
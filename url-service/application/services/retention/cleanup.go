@@ -0,0 +1,48 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"shared/clock"
+	"time"
+	"url-service/domain/entities"
+	"url-service/domain/interfaces"
+)
+
+// FailedStatuses are the statuses of the URLs removed by CleanupService.
+var FailedStatuses = []string{entities.StatusFailed, entities.StatusPermanentlyFailed}
+
+// CleanupService enforces the retention policy of failed URLs, deleting the ones older than the max. age.
+type CleanupService struct {
+	urlRepository interfaces.UrlRepository // urlRepository is used for interacting with the persistence layer.
+	maxAge        time.Duration            // maxAge is the time failed URLs are kept after their last update.
+	clock         clock.Clock              // clock is the time source of the cutoff.
+	logger        *slog.Logger             // logger for structured logging.
+}
+
+// NewCleanupService creates a new instance of CleanupService, a non-positive maxAge disables the cleanup.
+func NewCleanupService(
+	urlRepository interfaces.UrlRepository,
+	maxAge time.Duration,
+	clock clock.Clock,
+	logger *slog.Logger,
+) *CleanupService {
+	return &CleanupService{urlRepository: urlRepository, maxAge: maxAge, clock: clock, logger: logger}
+}
+
+// Run deletes the failed URLs whose last update is older than the max. age.
+// Only the age matters, so running it repeatedly is safe.
+func (s *CleanupService) Run(ctx context.Context) (deleted int, err error) {
+	if s.maxAge <= 0 {
+		s.logger.Info("Failed URLs retention disabled, nothing to clean up")
+		return 0, nil
+	}
+
+	cutoff := s.clock.Now().Add(-s.maxAge)
+	if deleted, err = s.urlRepository.DeleteByStatus(ctx, FailedStatuses, cutoff); err != nil {
+		return 0, fmt.Errorf("delete failed URLs: %w", err)
+	}
+	s.logger.Info("Failed URLs cleaned up", "deleted", deleted, "cutoff", cutoff, "statuses", FailedStatuses)
+	return deleted, nil
+}
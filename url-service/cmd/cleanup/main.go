@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"shared/logging"
+	mongoConfig "shared/mongodb/application/config"
+	"syscall"
+	"url-service/application"
+)
+
+func main() {
+	var (
+		app            = application.NewContainer()
+		logger         = app.Infrastructure.Get().Logger.Get()
+		cleanupService = app.CleanupService.Get()
+		deleted        int
+		err            error
+	)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	logger.Info("Starting failed URLs cleanup")
+	logger.Info("Effective configuration", logging.Config("config", app.Config.Get()),
+		logging.Config("mongo", mongoConfig.GetConfig().Mongo))
+	if deleted, err = cleanupService.Run(ctx); err != nil {
+		logger.Error("Cleanup failed", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("Failed URLs cleanup finished", "deleted", deleted)
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"os/signal"
+	"shared/logging"
+	mongoConfig "shared/mongodb/application/config"
+	"shared/runlimit"
 	"syscall"
 	"time"
 	"url-service/application"
@@ -14,15 +19,30 @@ func main() {
 		logger         = app.Infrastructure.Get().Logger.Get()
 		inboundService = app.InboundMessageService.Get()
 		natsClient     = app.NatsGrpcClient.Get()
+		metricsServer  = app.Infrastructure.Get().MetricsServer.Get()
 		gracePeriod    = time.Duration(2) * time.Second
 		inboundCtx     context.Context
 		inboundCancel  context.CancelFunc
 	)
 
-	inboundCtx, inboundCancel = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	signalCtx, signalCancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer signalCancel()
+
+	// Job-style runs also stop once their max. runtime or max. number of URLs is reached.
+	inboundCtx, inboundCancel = app.RunBudget.Get().Bind(signalCtx)
 	defer inboundCancel()
 
 	logger.Info("Starting inbound service")
+	logger.Info("Effective configuration", logging.Config("config", app.Config.Get()),
+		logging.Config("mongo", mongoConfig.GetConfig().Mongo))
+
+	// Start the metrics server, it exposes the consumer lag.
+	go func() {
+		if err := metricsServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Error running metrics server", "error", err)
+		}
+	}()
+
 	go func() {
 		if err := inboundService.Start(inboundCtx); err != nil {
 			logger.Error("Error starting inbound service", "error", err)
@@ -30,9 +50,21 @@ func main() {
 	}()
 
 	<-inboundCtx.Done()
-	logger.Info("Shutdown signal received, stopping inbound service", "gracePeriod", gracePeriod)
+	if cause := context.Cause(inboundCtx); runlimit.Reached(cause) {
+		logger.Info("Run limit reached, stopping inbound service", "reason", cause,
+			"processed", app.RunBudget.Get().Processed(), "gracePeriod", gracePeriod)
+	} else {
+		logger.Info("Shutdown signal received, stopping inbound service", "gracePeriod", gracePeriod)
+	}
 	time.Sleep(gracePeriod)
 
+	logger.Info("Stopping metrics server")
+	metricsCtx, metricsCancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer metricsCancel()
+	if err := metricsServer.Stop(metricsCtx); err != nil {
+		logger.Error("Error stopping metrics server", "error", err)
+	}
+
 	logger.Info("Closing NATS connection...")
 	if err := natsClient.Close(); err != nil {
 		logger.Error("Error closing NATS connection", "error", err)
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"shared/logging"
+	mongoConfig "shared/mongodb/application/config"
+	"syscall"
+	"url-service/application"
+)
+
+func main() {
+	var (
+		app             = application.NewContainer()
+		logger          = app.Infrastructure.Get().Logger.Get()
+		backfillService = app.BackfillService.Get()
+		migrated        int
+		err             error
+	)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	logger.Info("Starting URL documents backfill")
+	logger.Info("Effective configuration", logging.Config("config", app.Config.Get()),
+		logging.Config("mongo", mongoConfig.GetConfig().Mongo))
+	if migrated, err = backfillService.Run(ctx); err != nil {
+		logger.Error("Backfill failed", "migrated", migrated, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("URL documents backfill finished", "migrated", migrated)
+}
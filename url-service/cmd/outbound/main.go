@@ -2,10 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os/signal"
+	"shared/logging"
+	mongoConfig "shared/mongodb/application/config"
+	"shared/reload"
+	"shared/runlimit"
 	"syscall"
 	"time"
 	"url-service/application"
+	"url-service/application/config"
 )
 
 func main() {
@@ -14,24 +23,86 @@ func main() {
 		logger          = app.Infrastructure.Get().Logger.Get()
 		outboundService = app.OutboundMessageService.Get()
 		natsClient      = app.NatsGrpcClient.Get()
+		metricsServer   = app.Infrastructure.Get().MetricsServer.Get()
 		gracePeriod     = time.Duration(2) * time.Second
 		outboundCtx     context.Context
 		outboundCancel  context.CancelFunc
 	)
 
-	outboundCtx, outboundCancel = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	signalCtx, signalCancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer signalCancel()
+
+	// Job-style runs also stop once their max. runtime or max. number of URLs is reached.
+	outboundCtx, outboundCancel = app.RunBudget.Get().Bind(signalCtx)
 	defer outboundCancel()
 
 	logger.Info("Starting outbound service")
+	logger.Info("Effective configuration", logging.Config("config", app.Config.Get()),
+		logging.Config("mongo", mongoConfig.GetConfig().Mongo))
+
+	// Start the metrics server.
+	go func() {
+		if err := metricsServer.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Error running metrics server", "error", err)
+		}
+	}()
+
 	go outboundService.Start(outboundCtx)
 
+	// Apply the hot-reloadable parameters on SIGHUP.
+	reloadSignals, stopReload := reload.Notify()
+	defer stopReload()
+	go reload.Watch(outboundCtx, reloadSignals, reloadConfig(app), logger)
+
 	<-outboundCtx.Done()
-	logger.Info("Shutdown signal received, stopping outbound service", "gracePeriod", gracePeriod)
+	if cause := context.Cause(outboundCtx); runlimit.Reached(cause) {
+		logger.Info("Run limit reached, stopping outbound service", "reason", cause,
+			"processed", app.RunBudget.Get().Processed(), "gracePeriod", gracePeriod)
+	} else {
+		logger.Info("Shutdown signal received, stopping outbound service", "gracePeriod", gracePeriod)
+	}
 	time.Sleep(gracePeriod)
 
+	logger.Info("Stopping metrics server")
+	metricsCtx, metricsCancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer metricsCancel()
+	if err := metricsServer.Stop(metricsCtx); err != nil {
+		logger.Error("Error stopping metrics server", "error", err)
+	}
+
 	logger.Info("Closing NATS connection...")
 	if err := natsClient.Close(); err != nil {
 		logger.Error("Error closing NATS connection", "error", err)
 	}
 	logger.Info("Outbound service gracefully shutdown.")
 }
+
+// reloadConfig re-reads the configuration and applies the hot-reloadable parameters without reconnecting:
+//   - LOG_LEVEL: minimum log level.
+//   - OUTBOUND_MESSAGE_SCAN_INTERVAL: seconds between scans, the next scan is one new interval away.
+//
+// Every other parameter (NATS, MongoDB, TLS, batch sizes, claims, metrics) requires a restart.
+// The service has no rate limits to reload.
+func reloadConfig(app *application.Container) reload.Func {
+	return func() error {
+		var (
+			reloadFile = app.Config.Get().ReloadFile
+			cfg        *config.Config
+			level      slog.Level
+			err        error
+		)
+		if reloadFile != "" {
+			if err = reload.LoadEnvFile(reloadFile); err != nil {
+				return err
+			}
+		}
+		cfg = config.Load()
+
+		if level, err = reload.ParseLevel(cfg.LogLevel); err != nil {
+			return fmt.Errorf("invalid log level: %w", err)
+		}
+		app.Infrastructure.Get().LogLevel.Get().Set(level)
+		app.OutboundMessageService.Get().SetInterval(time.Duration(cfg.OutboundMessage.ScanInterval) * time.Second)
+		return nil
+	}
+}
@@ -0,0 +1,13 @@
+package entities
+
+// BulkUpdateResult is the outcome of updating multiple URL entities by their IDs.
+type BulkUpdateResult struct {
+	Matched   int64    // Matched is the number of documents found for the IDs.
+	Modified  int64    // Modified is the number of matched documents actually changed by the update.
+	Unmatched []string // Unmatched are the IDs no document was found for, e.g. deleted concurrently.
+}
+
+// Partial reports whether some of the IDs were not found.
+func (r *BulkUpdateResult) Partial() bool {
+	return len(r.Unmatched) > 0
+}
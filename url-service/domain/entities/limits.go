@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SizeLimits defines the maximum sizes accepted for a URL document. Zero values disable the limit.
+type SizeLimits struct {
+	MaxAddressLength int // MaxAddressLength is the max. length of the address in bytes.
+	MaxSourceLength  int // MaxSourceLength is the max. length of the source in bytes.
+	MaxDocumentSize  int // MaxDocumentSize is the max. size of the BSON-encoded document in bytes.
+}
+
+// SizeError is returned when a URL document exceeds the configured size limits.
+type SizeError struct {
+	Field string // Field is the name of the field (or "document") exceeding the limit.
+	Size  int    // Size is the actual size in bytes.
+	Limit int    // Limit is the configured limit in bytes.
+}
+
+// Error implements the error interface.
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("%s size %d exceeds limit of %d bytes", e.Field, e.Size, e.Limit)
+}
+
+// CheckSize validates the Url entity against the given size limits.
+func (e *Url) CheckSize(limits SizeLimits) (err error) {
+	if limits.MaxAddressLength > 0 && len(e.Address) > limits.MaxAddressLength {
+		return &SizeError{Field: "address", Size: len(e.Address), Limit: limits.MaxAddressLength}
+	}
+	if limits.MaxSourceLength > 0 && len(e.Source) > limits.MaxSourceLength {
+		return &SizeError{Field: "source", Size: len(e.Source), Limit: limits.MaxSourceLength}
+	}
+	if limits.MaxDocumentSize > 0 {
+		var document []byte
+		if document, err = bson.Marshal(e); err != nil {
+			return fmt.Errorf("encode document: %w", err)
+		}
+		if len(document) > limits.MaxDocumentSize {
+			return &SizeError{Field: "document", Size: len(document), Limit: limits.MaxDocumentSize}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatusTransition represents a single, append-only record of a URL status change.
+type StatusTransition struct {
+	Id     primitive.ObjectID `bson:"_id,omitempty" json:"id"`        // Id is the unique identifier of the transition.
+	UrlId  primitive.ObjectID `bson:"url_id" json:"url_id"`           // UrlId is the identifier of the URL that changed.
+	From   string             `bson:"from" json:"from"`               // From is the status before the change.
+	To     string             `bson:"to" json:"to"`                   // To is the status after the change.
+	Reason string             `bson:"reason,omitempty" json:"reason"` // Reason is the optional reason of the change.
+	At     time.Time          `bson:"at" json:"at"`                   // At is the time of the change.
+}
@@ -1,34 +1,62 @@
 package entities
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
 	// StatusPending represents URL that is pending processing.
 	StatusPending = "pending"
-	// StatusProcessed represents URL that has been successfully processed.
+	// StatusProcessing represents URL that has been claimed for processing.
+	StatusProcessing = "processing"
+	// StatusProcessed represents URL that has been processed, written before the terminal statuses below.
+	// Deprecated: documents of schema version 1 in this status are migrated to StatusSucceeded.
 	StatusProcessed = "processed"
-	// StatusFailed represents URL that failed processing.
+	// StatusFailed represents URL that failed processing and may be retried.
 	StatusFailed = "failed"
+
+	// StatusSucceeded represents URL that has been handed off downstream successfully.
+	StatusSucceeded = "succeeded"
+	// StatusPermanentlyFailed represents URL that can never be processed, e.g., with an invalid address.
+	StatusPermanentlyFailed = "permanently_failed"
+	// StatusSkipped represents URL that is deliberately not processed, e.g., with a disallowed host.
+	StatusSkipped = "skipped"
+
+	// StatusDeleted represents URL that has been soft-deleted, it is kept until purged.
+	StatusDeleted = "deleted"
 )
 
+// CurrentSchemaVersion is the version of the URL document schema written by this code.
+// It must be bumped whenever new fields requiring a backfill are added.
+//
+// Version 2 replaces StatusProcessed by the terminal statuses.
+const CurrentSchemaVersion = 2
+
+// ErrDuplicateAddress is returned when a URL is saved with the address of a stored one.
+var ErrDuplicateAddress = errors.New("URL address already exists")
+
 // urlEntityPool is the on-demand pool for Url entities.
 var urlEntityPool = urlPool()
 
 // Url represents the URL entity.
 type Url struct {
-	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`      // Id is the unique identifier of the URL.
-	Address   string             `bson:"address" json:"address"`       // Address is the URL address to be processed.
-	Status    string             `bson:"status" json:"status"`         // Status is the processing status of the URL.
-	Source    string             `bson:"source" json:"source"`         // Source is the source who created the record.
-	Processed time.Time          `bson:"processed" json:"processed"`   // Processed is the time when URL was processed.
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"` // CreatedAt is the time when URL was created.
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"` // UpdatedAt is the time when URL was updated.
+	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`                                // Id is the unique identifier of the URL.
+	Address   string             `bson:"address" json:"address"`                                 // Address is the URL address to be processed.
+	Status    string             `bson:"status" json:"status"`                                   // Status is the processing status of the URL.
+	Reason    string             `bson:"status_reason,omitempty" json:"status_reason,omitempty"` // Reason is the reason of the last status change.
+	Source    string             `bson:"source" json:"source"`                                   // Source is the source who created the record.
+	Processed time.Time          `bson:"processed" json:"processed"`                             // Processed is the time when URL was processed.
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`                           // CreatedAt is the time when URL was created.
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`                           // UpdatedAt is the time when URL was updated.
+	Schema    int                `bson:"schema_version" json:"schema_version"`                   // Schema is the version of the document schema.
+	NotBefore time.Time          `bson:"not_before,omitempty" json:"not_before"`                 // NotBefore is the earliest time the URL is processed, zero is at once.
+	DeletedAt time.Time          `bson:"deleted_at,omitempty" json:"deleted_at"`                 // DeletedAt is the time the URL was soft-deleted, zero is not deleted.
 }
 
 // String implements the fmt.Stringer interface, providing a readable string representation of the Url entity.
@@ -36,6 +64,38 @@ func (e *Url) String() (result string) {
 	return fmt.Sprintf("Id: %s, Address: %s, Status: %s, Source: %s", e.Id, e.Address, e.Status, e.Source)
 }
 
+// PendingFilter returns the filter of pending URLs eligible for processing at now.
+// URLs without a NotBefore, including the ones saved before it was introduced, are eligible at once.
+func PendingFilter(now time.Time) bson.M {
+	return bson.M{"status": StatusPending, "$or": bson.A{
+		bson.M{"not_before": bson.M{"$exists": false}},
+		bson.M{"not_before": bson.M{"$lte": now}},
+	}}
+}
+
+// FetchOptions holds the options of a fetch of URLs.
+type FetchOptions struct {
+	IncludeDeleted bool // IncludeDeleted returns soft-deleted URLs too.
+}
+
+// FetchOption defines a functional option for configuring FetchOptions.
+type FetchOption func(*FetchOptions)
+
+// IncludeDeleted makes a fetch return soft-deleted URLs too, they are excluded by default.
+func IncludeDeleted() FetchOption {
+	return func(o *FetchOptions) {
+		o.IncludeDeleted = true
+	}
+}
+
+// NewFetchOptions returns the fetch options with the given options applied.
+func NewFetchOptions(opts ...FetchOption) (options FetchOptions) {
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
 // urlPool returns a function that provides access to a *sync.Pool for Url entities.
 // It uses sync.Once to ensure the pool is created only once.
 func urlPool() func() *sync.Pool {
@@ -65,10 +125,14 @@ func (e *Url) Reset() *Url {
 	e.Id = primitive.NilObjectID
 	e.Address = ""
 	e.Status = ""
+	e.Reason = ""
 	e.Source = ""
 	e.Processed = time.Time{}
 	e.CreatedAt = time.Time{}
 	e.UpdatedAt = time.Time{}
+	e.Schema = 0
+	e.NotBefore = time.Time{}
+	e.DeletedAt = time.Time{}
 	return e
 }
 
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 	"url-service/domain/entities"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -10,14 +11,50 @@ import (
 // UrlRepository defines the contract for interacting with URL entities in the persistence layer.
 type UrlRepository interface {
 	// Save persists a new URL entity into the data source.
+	// It returns an error wrapping entities.ErrDuplicateAddress if a URL with the same address is stored.
 	Save(ctx context.Context, url *entities.Url) (err error)
+	// Upsert persists a new URL entity unless one with the same address exists, which is left untouched.
+	// It reports whether the entity was inserted, a soft-deleted URL counts as existing until it is purged.
+	Upsert(ctx context.Context, url *entities.Url) (inserted bool, err error)
 
-	// FetchBatch retrieves a batch of URLs matching the given filter.
-	FetchBatch(ctx context.Context, filter bson.M, limit int) (list []*entities.Url, err error)
+	// FetchBatch retrieves a batch of URLs matching the given filter, soft-deleted URLs are excluded
+	// unless entities.IncludeDeleted is given.
+	FetchBatch(ctx context.Context, filter bson.M, limit int, opts ...entities.FetchOption) (
+		list []*entities.Url, err error)
+	// FetchPage retrieves up to limit URLs matching the filter in ID order, after the afterID cursor if given,
+	// and returns the ID of the last one as the cursor of the next page. Soft-deleted URLs are excluded
+	// unless entities.IncludeDeleted is given.
+	FetchPage(ctx context.Context, filter bson.M, limit int, afterID string, opts ...entities.FetchOption) (
+		list []*entities.Url, next string, err error)
+
+	// Count returns the number of URLs matching the given filter.
+	Count(ctx context.Context, filter bson.M) (count int64, err error)
 
 	// UpdateFields updates URL entity in the MongoDB collection by its ID using dynamic update fields.
 	UpdateFields(ctx context.Context, id string, updateFields bson.M) (err error)
 
 	// BulkUpdateFields updates multiple entities in the MongoDB collection by their IDs using dynamic update fields.
 	BulkUpdateFields(ctx context.Context, ids []string, updateFields bson.M) (err error)
+	// BulkUpdateFieldsDetailed updates multiple entities like BulkUpdateFields and reports the matched
+	// and modified counts and the IDs not found, IDs not found are not an error.
+	BulkUpdateFieldsDetailed(ctx context.Context, ids []string, updateFields bson.M) (
+		result *entities.BulkUpdateResult, err error)
+
+	// ClaimPending atomically claims up to limit pending URLs eligible now by flipping them to processing.
+	ClaimPending(ctx context.Context, limit int) (list []*entities.Url, err error)
+	// ReleaseStale returns URLs stuck in processing since before cutoff to pending.
+	ReleaseStale(ctx context.Context, cutoff time.Time) (released int, err error)
+
+	// DeleteByStatus deletes URLs in one of the statuses whose last update happened before cutoff.
+	DeleteByStatus(ctx context.Context, statuses []string, cutoff time.Time) (deleted int, err error)
+
+	// SoftDelete marks a URL entity deleted by its ID, keeping the document until it is purged.
+	SoftDelete(ctx context.Context, id string) (err error)
+	// PurgeDeleted deletes the soft-deleted URLs whose deletion happened before olderThan.
+	PurgeDeleted(ctx context.Context, olderThan time.Time) (purged int, err error)
+	// DeleteDuplicateAddresses deletes all but the oldest URL of each address stored more than once.
+	DeleteDuplicateAddresses(ctx context.Context) (deleted int, err error)
+
+	// FetchTransitions retrieves the status transitions of a URL entity in the order they happened.
+	FetchTransitions(ctx context.Context, id string) (list []*entities.StatusTransition, err error)
 }
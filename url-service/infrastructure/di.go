@@ -1,24 +1,42 @@
 package infrastructure
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
+	"shared/clock"
 	"shared/dependency"
+	"shared/logging"
 	"shared/mongodb/application/config"
 	"shared/mongodb/domain/entities"
 	"shared/mongodb/infrastructure/mongodb"
+	"shared/reload"
+	"time"
+	urlServiceConfig "url-service/application/config"
+	urlEntities "url-service/domain/entities"
 	"url-service/domain/interfaces"
+	"url-service/infrastructure/metrics"
 	"url-service/infrastructure/url"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Container provides a lazily initialized set of dependencies.
 type Container struct {
-	Logger          dependency.LazyDependency[*slog.Logger]
-	MongoClient     dependency.LazyDependency[*mongodb.Client]
-	MongoRepository dependency.LazyDependency[interfaces.UrlRepository]
+	Logger            dependency.LazyDependency[*slog.Logger]
+	LogLevel          dependency.LazyDependency[*slog.LevelVar]
+	MongoClient       dependency.LazyDependency[*mongodb.Client]
+	MongoRepository   dependency.LazyDependency[interfaces.UrlRepository]
+	MetricsRegistry   dependency.LazyDependency[*prometheus.Registry]
+	OutboundMetrics   dependency.LazyDependency[*metrics.OutboundMetrics]
+	RepositoryMetrics dependency.LazyDependency[*metrics.RepositoryMetrics]
+	ConsumerMetrics   dependency.LazyDependency[*metrics.ConsumerMetrics]
+	MetricsServer     dependency.LazyDependency[*metrics.Server]
 }
 
 // NewContainer initializes and returns a new Container with dependencies.
@@ -36,10 +54,29 @@ func NewContainer() *Container {
 			}
 
 			file, err = os.OpenFile(interfaces.LogFilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			options := logging.HandlerOptions{
+				Format:    urlServiceConfig.GetConfig().LogFormat,
+				AddSource: urlServiceConfig.GetConfig().LogAddSource,
+				Level:     c.LogLevel.Get(),
+			}
 			if err != nil {
-				return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{}))
+				return slog.New(logging.NewHandler(os.Stdout, options))
 			}
-			return slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{}))
+			return slog.New(logging.NewHandler(file, options))
+		},
+	}
+	c.LogLevel = dependency.LazyDependency[*slog.LevelVar]{
+		InitFunc: func() *slog.LevelVar {
+			var (
+				levelVar = &slog.LevelVar{}
+				level    slog.Level
+				err      error
+			)
+			if level, err = reload.ParseLevel(urlServiceConfig.GetConfig().LogLevel); err != nil {
+				panic(fmt.Sprintf("invalid log level: %v", err))
+			}
+			levelVar.Set(level)
+			return levelVar
 		},
 	}
 	c.MongoClient = dependency.LazyDependency[*mongodb.Client]{
@@ -62,16 +99,104 @@ func NewContainer() *Container {
 				logger         = c.Logger.Get()
 				mongoClient    *mongo.Client
 				collection     *mongo.Collection
-				collectionName = config.GetConfig().Mongo.Collection
-				dbName         = config.GetConfig().Mongo.DB
-				err            error
+				collectionOpts *options.CollectionOptions
+				mongoConfig    = config.GetConfig().Mongo
+				collectionName = mongoConfig.Collection
+				dbName         = mongoConfig.DB
+				transitions    = mongoConfig.TransitionsCollection
+				breaker        = urlServiceConfig.GetConfig().Breaker
+				limits         = urlServiceConfig.GetConfig().Limits
+				opts           = []url.Option{url.WithSizeLimits(urlEntities.SizeLimits{
+					MaxAddressLength: limits.MaxAddressLength,
+					MaxSourceLength:  limits.MaxSourceLength,
+					MaxDocumentSize:  limits.MaxDocumentSize,
+				})}
+				err error
 			)
+			if collectionOpts, err = mongodb.CollectionOptions(mongoConfig.ReadPreference, mongoConfig.WriteConcern); err != nil {
+				logger.Error("Invalid MongoDB collection options", "error", err)
+				panic(err)
+			}
 			if mongoClient, err = c.MongoClient.Get().Connect(); err != nil {
 				logger.Error("Failed to connect to MongoDB", "error", err)
 				panic(err)
 			}
-			collection = mongoClient.Database(dbName).Collection(collectionName)
-			return url.NewRepository(mongoClient, collection, logger)
+			collection = mongoClient.Database(dbName).Collection(collectionName, collectionOpts)
+			if transitions != "" {
+				opts = append(opts, url.WithTransitionLog(mongoClient.Database(dbName).Collection(transitions, collectionOpts)))
+			}
+			repository := url.NewRepository(mongoClient, collection, logger, opts...)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(30)*time.Second)
+			defer cancel()
+			// Duplicate addresses stored before the unique index existed only leave it out, reported by the
+			// unique address index gauge, the migrate command deletes them and the index is built on the next start
+			switch err = repository.EnsureIndexes(ctx); {
+			case errors.Is(err, urlEntities.ErrDuplicateAddress):
+				logger.Warn("Running without the unique address index, run the migrate command", "error", err)
+			case err != nil:
+				logger.Error("Failed to ensure MongoDB indexes", "error", err)
+				panic(err)
+			}
+			c.RepositoryMetrics.Get().SetUniqueIndex(repository.UniqueIndex())
+			if breaker.Threshold <= 0 {
+				return repository
+			}
+			return url.NewBreakerRepository(repository, url.NewBreaker(
+				breaker.Threshold,
+				time.Duration(breaker.Cooldown)*time.Second,
+				clock.NewReal(),
+				c.RepositoryMetrics.Get(),
+				logger))
+		},
+	}
+
+	c.MetricsRegistry = dependency.LazyDependency[*prometheus.Registry]{
+		InitFunc: prometheus.NewRegistry,
+	}
+	c.OutboundMetrics = dependency.LazyDependency[*metrics.OutboundMetrics]{
+		InitFunc: func() *metrics.OutboundMetrics {
+			var (
+				namespace       = "url_service"
+				outboundMetrics = metrics.NewOutboundMetrics(namespace)
+			)
+			if err := outboundMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return outboundMetrics
+		},
+	}
+	c.RepositoryMetrics = dependency.LazyDependency[*metrics.RepositoryMetrics]{
+		InitFunc: func() *metrics.RepositoryMetrics {
+			var (
+				namespace   = "url_service"
+				repoMetrics = metrics.NewRepositoryMetrics(namespace)
+			)
+			if err := repoMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return repoMetrics
+		},
+	}
+	c.ConsumerMetrics = dependency.LazyDependency[*metrics.ConsumerMetrics]{
+		InitFunc: func() *metrics.ConsumerMetrics {
+			var (
+				namespace       = "url_service"
+				consumerMetrics = metrics.NewConsumerMetrics(namespace)
+			)
+			if err := consumerMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return consumerMetrics
+		},
+	}
+	c.MetricsServer = dependency.LazyDependency[*metrics.Server]{
+		InitFunc: func() *metrics.Server {
+			var (
+				logger   = c.Logger.Get()
+				port     = urlServiceConfig.GetConfig().Metrics.ServerPort
+				registry = c.MetricsRegistry.Get()
+			)
+			return metrics.NewServer(port, registry, logger)
 		},
 	}
 
@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ConsumerMetrics exposes Prometheus metrics describing the NATS consumers of the service.
+type ConsumerMetrics struct {
+	lag          *prometheus.HistogramVec // lag observes the publish to delivery delay of messages by subject.
+	inFlight     prometheus.Gauge         // inFlight reports the messages currently being processed.
+	staleDropped *prometheus.CounterVec   // staleDropped counts the messages dropped for exceeding the max. age.
+	failed       *prometheus.CounterVec   // failed counts the messages that failed processing by subject and reason.
+	newUrls      prometheus.Counter       // newUrls counts the consumed URLs saved as new documents.
+	duplicates   prometheus.Counter       // duplicates counts the consumed URLs whose address was already saved.
+}
+
+// NewConsumerMetrics creates a new instance of ConsumerMetrics.
+func NewConsumerMetrics(namespace string) *ConsumerMetrics {
+	return &ConsumerMetrics{
+		lag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "lag_seconds",
+			Help:      "Delay between the publish and the delivery of consumed messages by subject.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 16),
+		}, []string{"subject"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "in_flight",
+			Help:      "Number of consumed messages currently being processed, bounded by the batch size.",
+		}),
+		staleDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "stale_dropped_total",
+			Help:      "Total number of consumed messages dropped for exceeding the max. message age by subject.",
+		}, []string{"subject"}),
+		failed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "failed_total",
+			Help:      "Total number of consumed messages that failed processing by subject and reason.",
+		}, []string{"subject", "reason"}),
+		newUrls: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "new_urls_total",
+			Help:      "Total number of consumed URLs saved as new documents.",
+		}),
+		duplicates: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "consumer",
+			Name:      "duplicate_urls_total",
+			Help:      "Total number of consumed URLs skipped as their address was already saved.",
+		}),
+	}
+}
+
+// Register registers the consumer metrics with the given registerer.
+func (m *ConsumerMetrics) Register(registerer prometheus.Registerer) (err error) {
+	collectors := []prometheus.Collector{m.lag, m.inFlight, m.staleDropped, m.failed, m.newUrls, m.duplicates}
+	for _, collector := range collectors {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register consumer metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// ObserveLag records the delivery delay of a message consumed from the given subject.
+func (m *ConsumerMetrics) ObserveLag(subject string, lag time.Duration) {
+	m.lag.WithLabelValues(subject).Observe(lag.Seconds())
+}
+
+// IncInFlight records a message entering processing.
+func (m *ConsumerMetrics) IncInFlight() {
+	m.inFlight.Inc()
+}
+
+// DecInFlight records a message leaving processing.
+func (m *ConsumerMetrics) DecInFlight() {
+	m.inFlight.Dec()
+}
+
+// IncStaleDropped records a message consumed from the given subject dropped for exceeding the max. age.
+func (m *ConsumerMetrics) IncStaleDropped(subject string) {
+	m.staleDropped.WithLabelValues(subject).Inc()
+}
+
+// IncFailed records a message consumed from the given subject that failed processing for the given reason,
+// e.g. "timeout" or "save".
+func (m *ConsumerMetrics) IncFailed(subject, reason string) {
+	m.failed.WithLabelValues(subject, reason).Inc()
+}
+
+// IncNewUrls records a consumed URL saved as a new document.
+func (m *ConsumerMetrics) IncNewUrls() {
+	m.newUrls.Inc()
+}
+
+// IncDuplicateUrls records a consumed URL skipped as its address was already saved.
+func (m *ConsumerMetrics) IncDuplicateUrls() {
+	m.duplicates.Inc()
+}
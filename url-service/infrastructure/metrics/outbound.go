@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrorCategory identifies the stage of the outbound path that failed.
+type ErrorCategory string
+
+const (
+	ErrorMarshal ErrorCategory = "marshal" // ErrorMarshal is a failure to serialize a URL entity.
+	ErrorPublish ErrorCategory = "publish" // ErrorPublish is a failure to publish a URL to NATS.
+	ErrorUpdate  ErrorCategory = "update"  // ErrorUpdate is a failure to update a published URL in MongoDB.
+)
+
+// OutboundMetrics exposes Prometheus metrics describing the outbound message path.
+type OutboundMetrics struct {
+	errors       *prometheus.CounterVec // errors counts failed URLs by error category.
+	cycleSuccess prometheus.Gauge       // cycleSuccess reports the URLs published and updated in the last scan cycle.
+	pending      prometheus.Gauge       // pending reports the pending URLs outside the batch of the last scan cycle.
+	inconsistent prometheus.Counter     // inconsistent counts empty scan cycles while URLs were still pending.
+	inFlight     prometheus.Gauge       // inFlight reports the URLs currently being published.
+}
+
+// NewOutboundMetrics creates a new instance of OutboundMetrics.
+func NewOutboundMetrics(namespace string) *OutboundMetrics {
+	m := &OutboundMetrics{}
+
+	m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbound",
+		Name:      "errors_total",
+		Help:      "Total number of outbound URL failures by category.",
+	}, []string{"category"})
+	m.cycleSuccess = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbound",
+		Name:      "cycle_success",
+		Help:      "Number of URLs published and updated in the last completed scan cycle.",
+	})
+	m.pending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbound",
+		Name:      "pending",
+		Help:      "Number of pending URLs outside the batch of the last scan cycle, the queue depth to alert on.",
+	})
+	m.inconsistent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbound",
+		Name:      "scan_inconsistencies_total",
+		Help:      "Total number of scan cycles that found no work while URLs were pending, hinting at a scan filter bug.",
+	})
+
+	m.inFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "outbound",
+		Name:      "in_flight",
+		Help:      "Number of URLs currently being published, bounded by the concurrency limit.",
+	})
+
+	// Pre-initialize the known categories so they are exported with a zero value.
+	for _, category := range []ErrorCategory{ErrorMarshal, ErrorPublish, ErrorUpdate} {
+		m.errors.WithLabelValues(string(category))
+	}
+
+	return m
+}
+
+// Register registers the outbound metrics with the given registerer.
+func (m *OutboundMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range []prometheus.Collector{
+		m.errors, m.cycleSuccess, m.pending, m.inconsistent, m.inFlight,
+	} {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register outbound metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// ObserveError records a failed URL in the given category.
+func (m *OutboundMetrics) ObserveError(category ErrorCategory) {
+	m.errors.WithLabelValues(string(category)).Inc()
+}
+
+// SetCycleSuccess records the number of URLs processed successfully in the last scan cycle.
+func (m *OutboundMetrics) SetCycleSuccess(count int) {
+	m.cycleSuccess.Set(float64(count))
+}
+
+// SetPending records the number of pending URLs left by the last scan cycle.
+func (m *OutboundMetrics) SetPending(count int64) {
+	m.pending.Set(float64(count))
+}
+
+// ObserveInconsistency records a scan cycle that found no work while URLs were pending.
+func (m *OutboundMetrics) ObserveInconsistency() {
+	m.inconsistent.Inc()
+}
+
+// IncInFlight records a URL entering processing.
+func (m *OutboundMetrics) IncInFlight() {
+	m.inFlight.Inc()
+}
+
+// DecInFlight records a URL leaving processing.
+func (m *OutboundMetrics) DecInFlight() {
+	m.inFlight.Dec()
+}
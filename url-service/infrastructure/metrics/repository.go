@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"fmt"
+	"url-service/infrastructure/url"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RepositoryMetrics exposes Prometheus metrics describing the MongoDB repository.
+type RepositoryMetrics struct {
+	breakerState prometheus.Gauge // breakerState reports the circuit breaker state (0 closed, 1 half-open, 2 open).
+	uniqueIndex  prometheus.Gauge // uniqueIndex reports whether the unique address index is built (1) or not (0).
+}
+
+// NewRepositoryMetrics creates a new instance of RepositoryMetrics.
+func NewRepositoryMetrics(namespace string) *RepositoryMetrics {
+	return &RepositoryMetrics{
+		breakerState: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "breaker_state",
+			Help:      "State of the MongoDB circuit breaker: 0 closed, 1 half-open, 2 open.",
+		}),
+		uniqueIndex: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "unique_address_index",
+			Help:      "Whether the unique address index is built: 1 built, 0 missing until duplicate addresses are deleted.",
+		}),
+	}
+}
+
+// Register registers the repository metrics with the given registerer.
+func (m *RepositoryMetrics) Register(registerer prometheus.Registerer) (err error) {
+	for _, collector := range []prometheus.Collector{m.breakerState, m.uniqueIndex} {
+		if err = registerer.Register(collector); err != nil {
+			return fmt.Errorf("register repository metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetBreakerState records the current state of the circuit breaker.
+func (m *RepositoryMetrics) SetBreakerState(state url.BreakerState) {
+	m.breakerState.Set(float64(state))
+}
+
+// SetUniqueIndex records whether the unique address index is built.
+func (m *RepositoryMetrics) SetUniqueIndex(built bool) {
+	if built {
+		m.uniqueIndex.Set(1)
+		return
+	}
+	m.uniqueIndex.Set(0)
+}
@@ -0,0 +1,326 @@
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"shared/clock"
+	"shared/retry"
+	"sync"
+	"time"
+	"url-service/domain/entities"
+	"url-service/domain/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrCircuitOpen is returned without reaching MongoDB while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("mongo circuit breaker is open")
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // BreakerClosed lets every operation through.
+	BreakerHalfOpen                     // BreakerHalfOpen lets a single trial operation through.
+	BreakerOpen                         // BreakerOpen fast-fails every operation until the cooldown elapses.
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// BreakerObserver receives the state changes of a Breaker.
+type BreakerObserver interface {
+	// SetBreakerState records the current state of the breaker.
+	SetBreakerState(state BreakerState)
+}
+
+// Breaker is a consecutive-error circuit breaker.
+// It opens after threshold consecutive failures and fast-fails for cooldown, then half-opens
+// and lets a single trial operation decide whether it closes again or re-opens.
+type Breaker struct {
+	threshold int             // threshold is the number of consecutive failures that open the breaker.
+	cooldown  time.Duration   // cooldown is how long the breaker stays open before half-opening.
+	clock     clock.Clock     // clock is the time source of the cooldown.
+	observer  BreakerObserver // observer is notified of state changes, nil disables it.
+	logger    *slog.Logger
+
+	mu       sync.Mutex   // mu guards the fields below.
+	state    BreakerState // state is the current state.
+	failures int          // failures is the number of consecutive failures while closed.
+	openedAt time.Time    // openedAt is when the breaker last opened.
+	trial    bool         // trial reports whether the half-open trial operation is in flight.
+}
+
+// NewBreaker creates a new instance of Breaker, observer may be nil.
+func NewBreaker(
+	threshold int,
+	cooldown time.Duration,
+	clock clock.Clock,
+	observer BreakerObserver,
+	logger *slog.Logger,
+) *Breaker {
+	b := &Breaker{threshold: threshold, cooldown: cooldown, clock: clock, observer: observer, logger: logger}
+	b.observe(BreakerClosed)
+	return b
+}
+
+// State returns the current state, an open breaker whose cooldown elapsed reports half-open.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.halfOpenIfCooled()
+	return b.state
+}
+
+// Do runs the operation unless the breaker is open, in which case it returns ErrCircuitOpen.
+// Only failures reported by isFailure count towards opening the breaker.
+func (b *Breaker) Do(operation func() error) (err error) {
+	if err = b.acquire(); err != nil {
+		return err
+	}
+	err = operation()
+	b.release(isFailure(err))
+	return err
+}
+
+// acquire admits an operation or rejects it with ErrCircuitOpen.
+func (b *Breaker) acquire() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenIfCooled()
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// release records the outcome of an admitted operation.
+func (b *Breaker) release(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.trial = false
+		if failed {
+			b.open()
+			return
+		}
+		b.failures = 0
+		b.transition(BreakerClosed)
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+	if b.failures++; b.failures >= b.threshold && b.state == BreakerClosed {
+		b.open()
+	}
+}
+
+// open opens the breaker and restarts the cooldown.
+func (b *Breaker) open() {
+	b.failures = 0
+	b.openedAt = b.clock.Now()
+	b.transition(BreakerOpen)
+}
+
+// halfOpenIfCooled half-opens an open breaker whose cooldown elapsed, b.mu must be held.
+func (b *Breaker) halfOpenIfCooled() {
+	if b.state == BreakerOpen && !b.clock.Now().Before(b.openedAt.Add(b.cooldown)) {
+		b.transition(BreakerHalfOpen)
+	}
+}
+
+// transition moves the breaker to the given state, b.mu must be held.
+func (b *Breaker) transition(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	b.logger.Warn("Mongo circuit breaker state changed", "from", b.state.String(), "to", state.String())
+	b.state = state
+	b.observe(state)
+}
+
+// observe reports the state to the observer, if any.
+func (b *Breaker) observe(state BreakerState) {
+	if b.observer != nil {
+		b.observer.SetBreakerState(state)
+	}
+}
+
+// isFailure reports whether the error hints at an overloaded or unreachable MongoDB, that is whether it is
+// transient (see retry.IsRetryable): timeouts include expired contexts and server selection timeouts.
+// Caller errors such as invalid IDs, size limits or missing documents do not count.
+func isFailure(err error) bool {
+	return retry.IsRetryable(err)
+}
+
+// BreakerRepository is an interfaces.UrlRepository guarding another one with a Breaker.
+type BreakerRepository struct {
+	repository interfaces.UrlRepository // repository is the guarded repository.
+	breaker    *Breaker                 // breaker is the circuit breaker of the guarded operations.
+}
+
+// NewBreakerRepository creates a new instance of BreakerRepository.
+func NewBreakerRepository(repository interfaces.UrlRepository, breaker *Breaker) *BreakerRepository {
+	return &BreakerRepository{repository: repository, breaker: breaker}
+}
+
+// Save persists a new URL entity unless the breaker is open.
+func (r *BreakerRepository) Save(ctx context.Context, url *entities.Url) (err error) {
+	return r.breaker.Do(func() error { return r.repository.Save(ctx, url) })
+}
+
+// Upsert persists a new URL entity unless one with the same address exists or the breaker is open.
+func (r *BreakerRepository) Upsert(ctx context.Context, url *entities.Url) (inserted bool, err error) {
+	err = r.breaker.Do(func() (err error) {
+		inserted, err = r.repository.Upsert(ctx, url)
+		return err
+	})
+	return inserted, err
+}
+
+// FetchBatch retrieves a batch of URLs matching the given filter unless the breaker is open.
+func (r *BreakerRepository) FetchBatch(ctx context.Context, filter bson.M, limit int, opts ...entities.FetchOption) (
+	list []*entities.Url, err error,
+) {
+	err = r.breaker.Do(func() (err error) {
+		list, err = r.repository.FetchBatch(ctx, filter, limit, opts...)
+		return err
+	})
+	return list, err
+}
+
+// FetchPage retrieves a page of URLs matching the given filter unless the breaker is open.
+func (r *BreakerRepository) FetchPage(
+	ctx context.Context,
+	filter bson.M,
+	limit int,
+	afterID string,
+	opts ...entities.FetchOption,
+) (list []*entities.Url, next string, err error) {
+	err = r.breaker.Do(func() (err error) {
+		list, next, err = r.repository.FetchPage(ctx, filter, limit, afterID, opts...)
+		return err
+	})
+	return list, next, err
+}
+
+// Count returns the number of URLs matching the given filter unless the breaker is open.
+func (r *BreakerRepository) Count(ctx context.Context, filter bson.M) (count int64, err error) {
+	err = r.breaker.Do(func() (err error) {
+		count, err = r.repository.Count(ctx, filter)
+		return err
+	})
+	return count, err
+}
+
+// UpdateFields updates URL entity by its ID unless the breaker is open.
+func (r *BreakerRepository) UpdateFields(ctx context.Context, id string, updateFields bson.M) (err error) {
+	return r.breaker.Do(func() error { return r.repository.UpdateFields(ctx, id, updateFields) })
+}
+
+// BulkUpdateFields updates multiple URL entities by their IDs unless the breaker is open.
+func (r *BreakerRepository) BulkUpdateFields(ctx context.Context, ids []string, updateFields bson.M) (err error) {
+	return r.breaker.Do(func() error { return r.repository.BulkUpdateFields(ctx, ids, updateFields) })
+}
+
+// BulkUpdateFieldsDetailed updates multiple URL entities by their IDs and reports the IDs not found
+// unless the breaker is open.
+func (r *BreakerRepository) BulkUpdateFieldsDetailed(ctx context.Context, ids []string, updateFields bson.M) (
+	result *entities.BulkUpdateResult, err error,
+) {
+	err = r.breaker.Do(func() (err error) {
+		result, err = r.repository.BulkUpdateFieldsDetailed(ctx, ids, updateFields)
+		return err
+	})
+	return result, err
+}
+
+// ClaimPending atomically claims up to limit pending URLs unless the breaker is open.
+func (r *BreakerRepository) ClaimPending(ctx context.Context, limit int) (list []*entities.Url, err error) {
+	err = r.breaker.Do(func() (err error) {
+		list, err = r.repository.ClaimPending(ctx, limit)
+		return err
+	})
+	return list, err
+}
+
+// ReleaseStale returns URLs stuck in processing since before cutoff to pending unless the breaker is open.
+func (r *BreakerRepository) ReleaseStale(ctx context.Context, cutoff time.Time) (released int, err error) {
+	err = r.breaker.Do(func() (err error) {
+		released, err = r.repository.ReleaseStale(ctx, cutoff)
+		return err
+	})
+	return released, err
+}
+
+// DeleteByStatus deletes URLs in one of the statuses updated before cutoff unless the breaker is open.
+func (r *BreakerRepository) DeleteByStatus(
+	ctx context.Context,
+	statuses []string,
+	cutoff time.Time,
+) (deleted int, err error) {
+	err = r.breaker.Do(func() (err error) {
+		deleted, err = r.repository.DeleteByStatus(ctx, statuses, cutoff)
+		return err
+	})
+	return deleted, err
+}
+
+// SoftDelete marks a URL entity deleted by its ID unless the breaker is open.
+func (r *BreakerRepository) SoftDelete(ctx context.Context, id string) (err error) {
+	return r.breaker.Do(func() error { return r.repository.SoftDelete(ctx, id) })
+}
+
+// PurgeDeleted deletes the soft-deleted URLs deleted before olderThan unless the breaker is open.
+func (r *BreakerRepository) PurgeDeleted(ctx context.Context, olderThan time.Time) (purged int, err error) {
+	err = r.breaker.Do(func() (err error) {
+		purged, err = r.repository.PurgeDeleted(ctx, olderThan)
+		return err
+	})
+	return purged, err
+}
+
+// DeleteDuplicateAddresses deletes all but the oldest URL of each duplicate address unless the breaker is open.
+func (r *BreakerRepository) DeleteDuplicateAddresses(ctx context.Context) (deleted int, err error) {
+	err = r.breaker.Do(func() (err error) {
+		deleted, err = r.repository.DeleteDuplicateAddresses(ctx)
+		return err
+	})
+	return deleted, err
+}
+
+// FetchTransitions retrieves the status transitions of a URL entity unless the breaker is open.
+func (r *BreakerRepository) FetchTransitions(
+	ctx context.Context,
+	id string,
+) (list []*entities.StatusTransition, err error) {
+	err = r.breaker.Do(func() (err error) {
+		list, err = r.repository.FetchTransitions(ctx, id)
+		return err
+	})
+	return list, err
+}
@@ -2,8 +2,11 @@ package url
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
+	"time"
 	"url-service/domain/entities"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,26 +15,114 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrCanceled is wrapped into the errors of updates that failed because their context was canceled or expired,
+// for instance on shutdown, so that callers can tell them apart from failures of the database.
+var ErrCanceled = errors.New("update canceled")
+
 // Repository provides a MongoDB-based implementation for managing URL entities.
 type Repository struct {
-	client     *mongo.Client     // client is the MongoDB client.
-	collection *mongo.Collection // collection is the MongoDB collection.
-	logger     *slog.Logger
+	client      *mongo.Client       // client is the MongoDB client.
+	collection  *mongo.Collection   // collection is the MongoDB collection.
+	transitions *mongo.Collection   // transitions is the optional status transitions audit log collection.
+	limits      entities.SizeLimits // limits is the size limits enforced on saved documents.
+	uniqueIndex atomic.Bool         // uniqueIndex reports whether EnsureIndexes built the unique address index.
+	logger      *slog.Logger
+}
+
+// Option defines a functional option for configuring Repository.
+type Option func(*Repository)
+
+// WithTransitionLog enables the append-only status transitions audit log in the given collection.
+func WithTransitionLog(collection *mongo.Collection) Option {
+	return func(r *Repository) {
+		r.transitions = collection
+	}
+}
+
+// WithSizeLimits enforces the given size limits on saved documents.
+func WithSizeLimits(limits entities.SizeLimits) Option {
+	return func(r *Repository) {
+		r.limits = limits
+	}
 }
 
 // NewRepository creates a new instance of Repository.
-func NewRepository(mongoClient *mongo.Client, collection *mongo.Collection, logger *slog.Logger) *Repository {
-	return &Repository{client: mongoClient, collection: collection, logger: logger}
+func NewRepository(mongoClient *mongo.Client, collection *mongo.Collection, logger *slog.Logger, opts ...Option) *Repository {
+	r := &Repository{client: mongoClient, collection: collection, logger: logger}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// EnsureIndexes creates the status and last update, the source, and the unique address indexes unless they exist.
+// It returns an error wrapping entities.ErrDuplicateAddress if duplicate addresses block the unique index.
+func (r *Repository) EnsureIndexes(ctx context.Context) (err error) {
+	var (
+		models = []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}},
+				Options: options.Index().SetName("status_updated_at"),
+			},
+			{
+				Keys:    bson.D{{Key: "source", Value: 1}},
+				Options: options.Index().SetName("source"),
+			},
+		}
+		unique = mongo.IndexModel{
+			Keys:    bson.D{{Key: "address", Value: 1}},
+			Options: options.Index().SetName("address_unique").SetUnique(true),
+		}
+		names []string
+		name  string
+	)
+
+	if names, err = r.collection.Indexes().CreateMany(ctx, models); err != nil {
+		r.logger.Error("Failed to create indexes", "error", err)
+		return fmt.Errorf("create indexes: %w", err)
+	}
+	if name, err = r.collection.Indexes().CreateOne(ctx, unique); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			r.uniqueIndex.Store(false)
+			r.logger.Warn("Unique address index not built over duplicate addresses", "error", err)
+			return fmt.Errorf("create unique index: %w: %w", entities.ErrDuplicateAddress, err)
+		}
+		r.logger.Error("Failed to create unique index", "error", err)
+		return fmt.Errorf("create unique index: %w", err)
+	}
+	r.uniqueIndex.Store(true)
+	r.logger.Info("Ensured indexes", "indexes", append(names, name))
+	return nil
+}
+
+// UniqueIndex reports whether EnsureIndexes built the unique address index. Until it is built, Save and Upsert
+// do not reject nor skip stored addresses reliably, and concurrent saves may store an address twice.
+func (r *Repository) UniqueIndex() bool {
+	return r.uniqueIndex.Load()
 }
 
 // Save persists a new URL entity into the MongoDB collection.
+// It returns an *entities.SizeError if the entity exceeds the configured size limits, and, once the unique address
+// index is built (see UniqueIndex), an error wrapping entities.ErrDuplicateAddress if the address is already stored.
 func (r *Repository) Save(ctx context.Context, url *entities.Url) (err error) {
+	if err = url.CheckSize(r.limits); err != nil {
+		r.logger.Error("Rejected URL exceeding size limits", "error", err)
+		return fmt.Errorf("check size: %w", err)
+	}
 	if url.Id.IsZero() {
 		url.Id = primitive.NewObjectID()
 	}
+	if url.Schema == 0 {
+		url.Schema = entities.CurrentSchemaVersion
+	}
 
 	var insertResult *mongo.InsertOneResult
 	if insertResult, err = r.collection.InsertOne(ctx, url); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			r.uniqueIndex.Store(false)
+			r.logger.Warn("Rejected URL with a stored address", "address", url.Address)
+			return fmt.Errorf("insert one: %w: %w", entities.ErrDuplicateAddress, err)
+		}
 		r.logger.Error("Failed to execute an insert command", "error", err)
 		return fmt.Errorf("insert one: %w", err)
 	}
@@ -39,14 +130,59 @@ func (r *Repository) Save(ctx context.Context, url *entities.Url) (err error) {
 	return nil
 }
 
+// Upsert persists a new URL entity into the MongoDB collection unless one with the same address exists,
+// the existing entity, and its status, are left untouched. It reports whether the entity was inserted,
+// and returns an *entities.SizeError if the entity exceeds the configured size limits.
+// A soft-deleted URL counts as existing, its address is not queued again until PurgeDeleted removed it, since
+// the unique address index spans the soft-deleted URLs.
+// Concurrent upserts of the same address insert a single document only once the unique address index is built
+// (see UniqueIndex), without it each of them may insert one.
+func (r *Repository) Upsert(ctx context.Context, url *entities.Url) (inserted bool, err error) {
+	if err = url.CheckSize(r.limits); err != nil {
+		r.logger.Error("Rejected URL exceeding size limits", "error", err)
+		return false, fmt.Errorf("check size: %w", err)
+	}
+	if url.Id.IsZero() {
+		url.Id = primitive.NewObjectID()
+	}
+	if url.Schema == 0 {
+		url.Schema = entities.CurrentSchemaVersion
+	}
+
+	var (
+		opts         = options.Update().SetUpsert(true)
+		updateResult *mongo.UpdateResult
+	)
+	updateResult, err = r.collection.UpdateOne(ctx, bson.M{"address": url.Address}, bson.M{"$setOnInsert": url}, opts)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent upsert of the same address won the race on the unique index
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to execute an upsert command", "address", url.Address, "error", err)
+		return false, fmt.Errorf("upsert one: %w", err)
+	}
+
+	if inserted = updateResult.UpsertedCount > 0; inserted {
+		r.logger.Info("Inserted URL", "address", url.Address, "insertedID", updateResult.UpsertedID)
+	}
+	return inserted, nil
+}
+
 // FetchBatch retrieves a batch of URLs matching the given filter.
 // The filter parameter is of type bson.M, allowing dynamic filtering.
-func (r *Repository) FetchBatch(ctx context.Context, filter bson.M, limit int) (list []*entities.Url, err error) {
+// Soft-deleted URLs are excluded unless entities.IncludeDeleted is given.
+func (r *Repository) FetchBatch(ctx context.Context, filter bson.M, limit int, fetchOpts ...entities.FetchOption) (
+	list []*entities.Url, err error,
+) {
 	var (
 		opts   = options.Find().SetLimit(int64(limit))
 		cursor *mongo.Cursor
 	)
 
+	if !entities.NewFetchOptions(fetchOpts...).IncludeDeleted {
+		filter = bson.M{"$and": []bson.M{filter, {"deleted_at": bson.M{"$exists": false}}}}
+	}
 	if cursor, err = r.collection.Find(ctx, filter, opts); err != nil {
 		r.logger.Error("Failed to execute a find command", "error", err)
 		return nil, fmt.Errorf("find by filter: %w", err)
@@ -64,6 +200,70 @@ func (r *Repository) FetchBatch(ctx context.Context, filter bson.M, limit int) (
 	return list, nil
 }
 
+// FetchPage retrieves up to limit URLs matching the given filter sorted by ascending ID, starting after the
+// afterID cursor, or from the beginning when the cursor is empty. next is the ID of the last URL of the page,
+// pass it as afterID to fetch the next page; it is empty once a page comes back empty.
+// Pages are stable while URLs are added, since new IDs sort after the existing ones.
+// Soft-deleted URLs are excluded unless entities.IncludeDeleted is given.
+func (r *Repository) FetchPage(
+	ctx context.Context,
+	filter bson.M,
+	limit int,
+	afterID string,
+	fetchOpts ...entities.FetchOption,
+) (list []*entities.Url, next string, err error) {
+	var (
+		opts       = options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+		conditions = []bson.M{filter}
+		query      = filter
+		cursor     *mongo.Cursor
+	)
+
+	if afterID != "" {
+		var after primitive.ObjectID
+		if after, err = primitive.ObjectIDFromHex(afterID); err != nil {
+			r.logger.Error("Failed to parse page cursor", "afterID", afterID, "error", err)
+			return nil, "", fmt.Errorf("cursor format: %w", err)
+		}
+		// The caller's filter may constrain the ID as well, so both conditions are combined rather than merged.
+		conditions = append(conditions, bson.M{"_id": bson.M{"$gt": after}})
+	}
+	if !entities.NewFetchOptions(fetchOpts...).IncludeDeleted {
+		conditions = append(conditions, bson.M{"deleted_at": bson.M{"$exists": false}})
+	}
+	if len(conditions) > 1 {
+		query = bson.M{"$and": conditions}
+	}
+
+	if cursor, err = r.collection.Find(ctx, query, opts); err != nil {
+		r.logger.Error("Failed to execute a find command", "error", err)
+		return nil, "", fmt.Errorf("find page: %w", err)
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Error("Failed to close cursor", "error", closeErr)
+		}
+	}()
+
+	if err = cursor.All(ctx, &list); err != nil {
+		r.logger.Error("Failed to execute cursor's command", "error", err)
+		return nil, "", fmt.Errorf("decode URL documents: %w", err)
+	}
+	if len(list) > 0 {
+		next = list[len(list)-1].Id.Hex()
+	}
+	return list, next, nil
+}
+
+// Count returns the number of URLs matching the given filter.
+func (r *Repository) Count(ctx context.Context, filter bson.M) (count int64, err error) {
+	if count, err = r.collection.CountDocuments(ctx, filter); err != nil {
+		r.logger.Error("Failed to execute a count command", "filter", filter, "error", err)
+		return 0, fmt.Errorf("count: %w", err)
+	}
+	return count, nil
+}
+
 // UpdateFields updates URL entity in the MongoDB collection by its ID using dynamic update fields.
 // The updateFields parameter is a bson.M map that specifies the fields to update.
 func (r *Repository) UpdateFields(ctx context.Context, id string, updateFields bson.M) (err error) {
@@ -77,9 +277,11 @@ func (r *Repository) UpdateFields(ctx context.Context, id string, updateFields b
 		r.logger.Error("Failed to parse object ID", "error", err)
 		return fmt.Errorf("ID format: %w", err)
 	}
+	if r.tracksTransition(updateFields) {
+		return r.updateWithTransition(ctx, objectId, updateFields)
+	}
 	if updateResult, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectId}, update); err != nil {
-		r.logger.Error("Failed to execute an update command", "objectId", objectId, "error", err)
-		return fmt.Errorf("update for ID %s: %w", id, err)
+		return r.updateFailed(ctx, fmt.Sprintf("update for ID %s", id), err, "objectId", objectId)
 	}
 
 	if updateResult.MatchedCount == 0 {
@@ -91,29 +293,260 @@ func (r *Repository) UpdateFields(ctx context.Context, id string, updateFields b
 
 // BulkUpdateFields updates multiple URL entities in the MongoDB collection by their IDs using dynamic update fields.
 // The updateFields parameter allows updating any set of fields provided in a bson.M map.
+// It fails only when none of the IDs is found, use BulkUpdateFieldsDetailed to learn about partial matches.
 func (r *Repository) BulkUpdateFields(ctx context.Context, ids []string, updateFields bson.M) (err error) {
+	var result *entities.BulkUpdateResult
+	if result, err = r.BulkUpdateFieldsDetailed(ctx, ids, updateFields); err != nil {
+		return err
+	}
+
+	if result.Matched == 0 {
+		r.logger.Error("No rows were updated", "ids", ids)
+		return fmt.Errorf("no documents found for ids: %s", ids)
+	}
+	return nil
+}
+
+// BulkUpdateFieldsDetailed updates multiple URL entities in the MongoDB collection by their IDs using dynamic
+// update fields, and reports the matched and modified counts and the IDs no document was found for.
+// IDs not found are reported in the result rather than as an error.
+func (r *Repository) BulkUpdateFieldsDetailed(ctx context.Context, ids []string, updateFields bson.M) (
+	result *entities.BulkUpdateResult, err error,
+) {
 	var objectIds []primitive.ObjectID
 	if objectIds, err = r.parseObjectIDs(ids); err != nil {
-		return err
+		return nil, err
 	}
 
 	var (
 		filter       = bson.M{"_id": bson.M{"$in": objectIds}}
 		update       = bson.M{"$set": updateFields}
 		updateResult *mongo.UpdateResult
+		previous     map[primitive.ObjectID]string
 	)
 
+	if r.tracksTransition(updateFields) {
+		if previous, err = r.fetchStatuses(ctx, filter); err != nil {
+			return nil, err
+		}
+	}
+
 	if updateResult, err = r.collection.UpdateMany(ctx, filter, update); err != nil {
-		r.logger.Error("Failed to execute an update command", "filter", filter, "error", err)
-		return fmt.Errorf("bulk update: %w", err)
+		return nil, r.updateFailed(ctx, "bulk update", err, "filter", filter)
 	}
 
-	if updateResult.MatchedCount == 0 {
-		r.logger.Error("No rows were updated", "ids", ids)
-		return fmt.Errorf("no documents found for ids: %s", ids)
+	result = &entities.BulkUpdateResult{Matched: updateResult.MatchedCount, Modified: updateResult.ModifiedCount}
+	if result.Unmatched, err = r.findUnmatched(ctx, objectIds, updateResult.MatchedCount); err != nil {
+		return nil, err
+	}
+	if result.Partial() {
+		r.logger.Warn("Some documents were not found", "matched", result.Matched, "unmatched", result.Unmatched)
 	}
 
-	return nil
+	if previous != nil {
+		r.recordTransitions(ctx, previous, updateFields)
+	}
+	return result, nil
+}
+
+// findUnmatched returns the hex IDs of objectIds no document exists for.
+// The documents are only looked up when fewer than the distinct IDs were matched.
+func (r *Repository) findUnmatched(ctx context.Context, objectIds []primitive.ObjectID, matched int64) (
+	unmatched []string, err error,
+) {
+	distinct := make(map[primitive.ObjectID]struct{}, len(objectIds))
+	for _, objectId := range objectIds {
+		distinct[objectId] = struct{}{}
+	}
+	if matched >= int64(len(distinct)) {
+		return nil, nil
+	}
+
+	var (
+		filter = bson.M{"_id": bson.M{"$in": objectIds}}
+		opts   = options.Find().SetProjection(bson.M{"_id": 1})
+		cursor *mongo.Cursor
+		found  []struct {
+			Id primitive.ObjectID `bson:"_id"`
+		}
+	)
+	if cursor, err = r.collection.Find(ctx, filter, opts); err != nil {
+		r.logger.Error("Failed to execute a find command", "error", err)
+		return nil, fmt.Errorf("find matched IDs: %w", err)
+	}
+	if err = cursor.All(ctx, &found); err != nil {
+		r.logger.Error("Failed to execute cursor's command", "error", err)
+		return nil, fmt.Errorf("decode matched IDs: %w", err)
+	}
+
+	for _, document := range found {
+		delete(distinct, document.Id)
+	}
+	// Keep the order of the given IDs, so the result is deterministic.
+	for _, objectId := range objectIds {
+		if _, ok := distinct[objectId]; ok {
+			unmatched = append(unmatched, objectId.Hex())
+			delete(distinct, objectId)
+		}
+	}
+	return unmatched, nil
+}
+
+// ClaimPending atomically claims up to limit pending URLs by flipping them to processing.
+// Each document is claimed with a single findAndModify, so concurrent callers never claim the same URL.
+// URLs scheduled for later by their NotBefore are left pending.
+func (r *Repository) ClaimPending(ctx context.Context, limit int) (list []*entities.Url, err error) {
+	var (
+		filter   = entities.PendingFilter(time.Now())
+		opts     = options.FindOneAndUpdate().SetReturnDocument(options.After).SetSort(bson.M{"created_at": 1})
+		previous = make(map[primitive.ObjectID]string, limit)
+	)
+
+	list = make([]*entities.Url, 0, limit)
+	for len(list) < limit {
+		var (
+			url    = &entities.Url{}
+			update = bson.M{"$set": bson.M{
+				"status":        entities.StatusProcessing,
+				"status_reason": "claimed",
+				"updated_at":    time.Now(),
+			}}
+		)
+
+		if err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(url); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				break
+			}
+			r.logger.Error("Failed to claim pending URL", "claimed", len(list), "error", err)
+			return list, fmt.Errorf("claim pending: %w", err)
+		}
+		list = append(list, url)
+		previous[url.Id] = entities.StatusPending
+	}
+
+	if r.transitions != nil && len(previous) > 0 {
+		r.recordTransitions(ctx, previous, bson.M{"status": entities.StatusProcessing, "status_reason": "claimed"})
+	}
+	return list, nil
+}
+
+// ReleaseStale returns URLs stuck in processing since before cutoff to pending, so they are claimed again.
+// It is the recovery path of claims whose owner crashed before marking them processed.
+func (r *Repository) ReleaseStale(ctx context.Context, cutoff time.Time) (released int, err error) {
+	var (
+		filter       = bson.M{"status": entities.StatusProcessing, "updated_at": bson.M{"$lt": cutoff}}
+		fields       = bson.M{"status": entities.StatusPending, "status_reason": "claim expired", "updated_at": time.Now()}
+		updateResult *mongo.UpdateResult
+		previous     map[primitive.ObjectID]string
+	)
+
+	if r.transitions != nil {
+		if previous, err = r.fetchStatuses(ctx, filter); err != nil {
+			return 0, err
+		}
+	}
+
+	if updateResult, err = r.collection.UpdateMany(ctx, filter, bson.M{"$set": fields}); err != nil {
+		r.logger.Error("Failed to release stale claims", "cutoff", cutoff, "error", err)
+		return 0, fmt.Errorf("release stale claims: %w", err)
+	}
+
+	if previous != nil && updateResult.ModifiedCount > 0 {
+		r.recordTransitions(ctx, previous, fields)
+	}
+	return int(updateResult.ModifiedCount), nil
+}
+
+// DeleteByStatus deletes URLs in one of the statuses whose last update happened before cutoff.
+// It is the cleanup path of the retention policy, the status transitions of deleted URLs are kept.
+func (r *Repository) DeleteByStatus(ctx context.Context, statuses []string, cutoff time.Time) (deleted int, err error) {
+	var (
+		filter       = bson.M{"status": bson.M{"$in": statuses}, "updated_at": bson.M{"$lt": cutoff}}
+		deleteResult *mongo.DeleteResult
+	)
+
+	if deleteResult, err = r.collection.DeleteMany(ctx, filter); err != nil {
+		r.logger.Error("Failed to delete URLs by status", "statuses", statuses, "cutoff", cutoff, "error", err)
+		return 0, fmt.Errorf("delete by status: %w", err)
+	}
+	return int(deleteResult.DeletedCount), nil
+}
+
+// SoftDelete marks a URL entity deleted by its ID, setting its deleted status and deletion time.
+// The document and its status transitions are kept until PurgeDeleted removes it.
+func (r *Repository) SoftDelete(ctx context.Context, id string) (err error) {
+	now := time.Now()
+	return r.UpdateFields(ctx, id, bson.M{
+		"status":        entities.StatusDeleted,
+		"status_reason": "deleted",
+		"deleted_at":    now,
+		"updated_at":    now,
+	})
+}
+
+// PurgeDeleted deletes the soft-deleted URLs whose deletion happened before olderThan.
+// It is the cleanup path of soft deletes, the status transitions of purged URLs are kept.
+func (r *Repository) PurgeDeleted(ctx context.Context, olderThan time.Time) (purged int, err error) {
+	var (
+		filter       = bson.M{"deleted_at": bson.M{"$lt": olderThan}}
+		deleteResult *mongo.DeleteResult
+	)
+
+	if deleteResult, err = r.collection.DeleteMany(ctx, filter); err != nil {
+		r.logger.Error("Failed to purge deleted URLs", "olderThan", olderThan, "error", err)
+		return 0, fmt.Errorf("purge deleted: %w", err)
+	}
+	return int(deleteResult.DeletedCount), nil
+}
+
+// DeleteDuplicateAddresses deletes all but the oldest URL of each address stored more than once, so the unique
+// address index can be built. The status transitions of deleted URLs are kept.
+func (r *Repository) DeleteDuplicateAddresses(ctx context.Context) (deleted int, err error) {
+	var (
+		pipeline = mongo.Pipeline{
+			{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+			{{Key: "$group", Value: bson.D{
+				{Key: "_id", Value: "$address"},
+				{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+			}}},
+			{{Key: "$match", Value: bson.D{{Key: "ids.1", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+		}
+		cursor       *mongo.Cursor
+		deleteResult *mongo.DeleteResult
+		duplicates   []primitive.ObjectID
+	)
+
+	if cursor, err = r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true)); err != nil {
+		r.logger.Error("Failed to find duplicate addresses", "error", err)
+		return 0, fmt.Errorf("find duplicates: %w", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	for cursor.Next(ctx) {
+		var group struct {
+			Ids []primitive.ObjectID `bson:"ids"`
+		}
+		if err = cursor.Decode(&group); err != nil {
+			r.logger.Error("Failed to decode duplicate addresses", "error", err)
+			return 0, fmt.Errorf("decode duplicates: %w", err)
+		}
+		// The IDs are pushed in creation order, the oldest URL is kept
+		duplicates = append(duplicates, group.Ids[1:]...)
+	}
+	if err = cursor.Err(); err != nil {
+		r.logger.Error("Failed to iterate duplicate addresses", "error", err)
+		return 0, fmt.Errorf("iterate duplicates: %w", err)
+	}
+	if len(duplicates) == 0 {
+		return 0, nil
+	}
+
+	if deleteResult, err = r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": duplicates}}); err != nil {
+		r.logger.Error("Failed to delete duplicate addresses", "error", err)
+		return 0, fmt.Errorf("delete duplicates: %w", err)
+	}
+	r.logger.Info("Deleted duplicate addresses", "deleted", deleteResult.DeletedCount)
+	return int(deleteResult.DeletedCount), nil
 }
 
 // parseObjectIDs converts a slice of string IDs to a slice of MongoDB ObjectIDs.
@@ -131,3 +564,136 @@ func (r *Repository) parseObjectIDs(ids []string) (list []primitive.ObjectID, er
 
 	return list, nil
 }
+
+// FetchTransitions retrieves the status transitions of a URL entity in the order they happened.
+func (r *Repository) FetchTransitions(ctx context.Context, id string) (list []*entities.StatusTransition, err error) {
+	if r.transitions == nil {
+		return nil, fmt.Errorf("status transitions log is disabled")
+	}
+
+	var (
+		objectId primitive.ObjectID
+		opts     = options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}})
+		cursor   *mongo.Cursor
+	)
+
+	if objectId, err = primitive.ObjectIDFromHex(id); err != nil {
+		r.logger.Error("Failed to parse object ID", "error", err)
+		return nil, fmt.Errorf("ID format: %w", err)
+	}
+	if cursor, err = r.transitions.Find(ctx, bson.M{"url_id": objectId}, opts); err != nil {
+		r.logger.Error("Failed to execute a find command on transitions", "error", err)
+		return nil, fmt.Errorf("find transitions: %w", err)
+	}
+	defer func() {
+		if closeErr := cursor.Close(ctx); closeErr != nil {
+			r.logger.Error("Failed to close cursor", "error", closeErr)
+		}
+	}()
+
+	if err = cursor.All(ctx, &list); err != nil {
+		r.logger.Error("Failed to execute cursor's command", "error", err)
+		return nil, fmt.Errorf("decode transition documents: %w", err)
+	}
+	return list, nil
+}
+
+// updateFailed logs a failed update command and wraps err with the given message.
+// Failures caused by the context being canceled or expired are logged as warnings and also wrap ErrCanceled,
+// so that shutdown-time cancellations do not show up as update failures.
+func (r *Repository) updateFailed(ctx context.Context, message string, err error, args ...any) error {
+	args = append(args, "error", err)
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		r.logger.Warn("Update command canceled", args...)
+		return fmt.Errorf("%s: %w: %w", message, ErrCanceled, err)
+	}
+	r.logger.Error("Failed to execute an update command", args...)
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// tracksTransition reports whether the update changes the status and the transitions log is enabled.
+func (r *Repository) tracksTransition(updateFields bson.M) bool {
+	if r.transitions == nil {
+		return false
+	}
+	_, ok := updateFields["status"].(string)
+	return ok
+}
+
+// updateWithTransition updates a single URL entity and records its status transition.
+func (r *Repository) updateWithTransition(ctx context.Context, objectId primitive.ObjectID, updateFields bson.M) (err error) {
+	var (
+		before   entities.Url
+		update   = bson.M{"$set": updateFields}
+		opts     = options.FindOneAndUpdate().SetReturnDocument(options.Before).SetProjection(bson.M{"status": 1})
+		previous = make(map[primitive.ObjectID]string, 1)
+	)
+
+	if err = r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objectId}, update, opts).Decode(&before); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			r.logger.Error("No rows were updated", "id", objectId.Hex())
+			return fmt.Errorf("ID %s not found", objectId.Hex())
+		}
+		return r.updateFailed(ctx, fmt.Sprintf("update for ID %s", objectId.Hex()), err, "objectId", objectId)
+	}
+
+	previous[objectId] = before.Status
+	r.recordTransitions(ctx, previous, updateFields)
+	return nil
+}
+
+// fetchStatuses retrieves the current statuses of the URL entities matching the filter.
+func (r *Repository) fetchStatuses(ctx context.Context, filter bson.M) (statuses map[primitive.ObjectID]string, err error) {
+	var (
+		opts   = options.Find().SetProjection(bson.M{"status": 1})
+		cursor *mongo.Cursor
+		list   []*entities.Url
+	)
+
+	if cursor, err = r.collection.Find(ctx, filter, opts); err != nil {
+		r.logger.Error("Failed to fetch current statuses", "error", err)
+		return nil, fmt.Errorf("find current statuses: %w", err)
+	}
+	if err = cursor.All(ctx, &list); err != nil {
+		r.logger.Error("Failed to decode current statuses", "error", err)
+		return nil, fmt.Errorf("decode current statuses: %w", err)
+	}
+
+	statuses = make(map[primitive.ObjectID]string, len(list))
+	for _, url := range list {
+		statuses[url.Id] = url.Status
+	}
+	return statuses, nil
+}
+
+// recordTransitions appends a transition for every URL entity whose status changed.
+// The audit log is best effort: failures are logged and do not fail the update.
+func (r *Repository) recordTransitions(ctx context.Context, previous map[primitive.ObjectID]string, updateFields bson.M) {
+	var (
+		to, _       = updateFields["status"].(string)
+		reason, _   = updateFields["status_reason"].(string)
+		at          = time.Now()
+		transitions = make([]interface{}, 0, len(previous))
+	)
+
+	for id, from := range previous {
+		if from == to {
+			continue
+		}
+		transitions = append(transitions, &entities.StatusTransition{
+			Id:     primitive.NewObjectID(),
+			UrlId:  id,
+			From:   from,
+			To:     to,
+			Reason: reason,
+			At:     at,
+		})
+	}
+	if len(transitions) == 0 {
+		return
+	}
+
+	if _, err := r.transitions.InsertMany(ctx, transitions); err != nil {
+		r.logger.Error("Failed to record status transitions", "count", len(transitions), "error", err)
+	}
+}
@@ -4,18 +4,23 @@ import (
 	"log/slog"
 	natsServiceInfrastructure "nats-service/infrastructure"
 	"os"
+	"shared/clock"
 	"shared/dependency"
 	"shared/grpc/clients/nats_service"
+	"shared/grpc/tests/integration/clients/nats_service/server"
 	sharedConfig "shared/mongodb/application/config"
 	sharedDomain "shared/mongodb/domain/entities"
 	"shared/mongodb/infrastructure/mongodb"
+	"shared/runlimit"
 	"time"
 	urlServiceConfig "url-service/application/config"
 	"url-service/application/services/messages"
 	urlServiceDomain "url-service/domain/entities"
 	"url-service/domain/interfaces"
+	"url-service/infrastructure/metrics"
 	"url-service/infrastructure/url"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -30,6 +35,26 @@ type TestContainer struct {
 	InboundMessageService     dependency.LazyDependency[*messages.InboundMessageService]
 	OutboundMessageService    dependency.LazyDependency[*messages.OutboundMessageService]
 	NatsServiceInfrastructure dependency.LazyDependency[*natsServiceInfrastructure.Container]
+	MetricsRegistry           dependency.LazyDependency[*prometheus.Registry]
+	OutboundMetrics           dependency.LazyDependency[*metrics.OutboundMetrics]
+	ConsumerMetrics           dependency.LazyDependency[*metrics.ConsumerMetrics]
+
+	// Dependencies backed by mocks, usable without external services.
+	MockUrlRepository            dependency.LazyDependency[*MockUrlRepository]
+	MockBusServiceServer         dependency.LazyDependency[*server.MockBusService]
+	MockServerContainer          dependency.LazyDependency[*server.TestServerContainer]
+	MockNatsGrpcClient           dependency.LazyDependency[*nats_service.NatsClient]
+	CappedOutboundMessageService dependency.LazyDependency[*messages.OutboundMessageService]
+	FakeClock                    dependency.LazyDependency[*clock.Fake]
+	FakeClockOutboundService     dependency.LazyDependency[*messages.OutboundMessageService]
+	RunBudget                    dependency.LazyDependency[*runlimit.Budget]
+	BudgetedOutboundService      dependency.LazyDependency[*messages.OutboundMessageService]
+	FilteringOutboundService     dependency.LazyDependency[*messages.OutboundMessageService]
+
+	// Claim -> publish -> mark flow backed by MongoDB whose first mark as succeeded crashes.
+	AuditedMongoRepository  dependency.LazyDependency[interfaces.UrlRepository]
+	CrashingUrlRepository   dependency.LazyDependency[*CrashingUrlRepository]
+	ClaimingOutboundService dependency.LazyDependency[*messages.OutboundMessageService]
 }
 
 // NewTestContainer initializes a new test container.
@@ -44,6 +69,33 @@ func NewTestContainer() *TestContainer {
 	c.Config = dependency.LazyDependency[*urlServiceConfig.Config]{
 		InitFunc: urlServiceConfig.GetConfig,
 	}
+	c.MetricsRegistry = dependency.LazyDependency[*prometheus.Registry]{
+		InitFunc: prometheus.NewRegistry,
+	}
+	c.OutboundMetrics = dependency.LazyDependency[*metrics.OutboundMetrics]{
+		InitFunc: func() *metrics.OutboundMetrics {
+			var (
+				namespace       = "url_service"
+				outboundMetrics = metrics.NewOutboundMetrics(namespace)
+			)
+			if err := outboundMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return outboundMetrics
+		},
+	}
+	c.ConsumerMetrics = dependency.LazyDependency[*metrics.ConsumerMetrics]{
+		InitFunc: func() *metrics.ConsumerMetrics {
+			var (
+				namespace       = "url_service"
+				consumerMetrics = metrics.NewConsumerMetrics(namespace)
+			)
+			if err := consumerMetrics.Register(c.MetricsRegistry.Get()); err != nil {
+				panic(err)
+			}
+			return consumerMetrics
+		},
+	}
 	c.MongoClient = dependency.LazyDependency[*mongodb.Client]{
 		InitFunc: func() *mongodb.Client {
 			var (
@@ -106,20 +158,29 @@ func NewTestContainer() *TestContainer {
 				urlRepository = c.MongoRepository.Get()
 				batchSize     = c.Config.Get().InboundMessage.BatchSize
 				queueGroup    = c.Config.Get().InboundMessage.QueueGroup
+				limits        = c.Config.Get().Limits
 			)
-			return messages.NewInboundMessageService(natsClient, urlRepository, batchSize, queueGroup, logger)
+			return messages.NewInboundMessageService(natsClient, urlRepository, batchSize, queueGroup,
+				urlServiceDomain.SizeLimits{
+					MaxAddressLength: limits.MaxAddressLength,
+					MaxSourceLength:  limits.MaxSourceLength,
+					MaxDocumentSize:  limits.MaxDocumentSize,
+				}, logger)
 		},
 	}
 	c.OutboundMessageService = dependency.LazyDependency[*messages.OutboundMessageService]{
 		InitFunc: func() *messages.OutboundMessageService {
 			var (
-				logger        = c.Logger.Get()
-				natsClient    = c.NatsGrpcClient.Get()
-				urlRepository = c.MongoRepository.Get()
-				interval      = time.Duration(5) * time.Second
-				batchSize     = c.Config.Get().OutboundMessage.BatchSize
+				logger         = c.Logger.Get()
+				natsClient     = c.NatsGrpcClient.Get()
+				urlRepository  = c.MongoRepository.Get()
+				interval       = time.Duration(5) * time.Second
+				batchSize      = c.Config.Get().OutboundMessage.BatchSize
+				concurrencyCap = c.Config.Get().OutboundMessage.ConcurrencyCap
+				metrics        = c.OutboundMetrics.Get()
 			)
-			return messages.NewOutboundMessageService(natsClient, urlRepository, interval, batchSize, logger)
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, clock.NewReal(), logger)
 		},
 	}
 
@@ -128,5 +189,153 @@ func NewTestContainer() *TestContainer {
 		InitFunc: natsServiceInfrastructure.NewContainer,
 	}
 
+	c.MockUrlRepository = dependency.LazyDependency[*MockUrlRepository]{
+		InitFunc: func() *MockUrlRepository {
+			return NewMockUrlRepository(time.Duration(50) * time.Millisecond)
+		},
+	}
+	c.MockBusServiceServer = dependency.LazyDependency[*server.MockBusService]{
+		InitFunc: server.NewMockBusService,
+	}
+	c.MockServerContainer = dependency.LazyDependency[*server.TestServerContainer]{
+		InitFunc: func() *server.TestServerContainer {
+			var (
+				testServer *server.TestServerContainer
+				err        error
+			)
+			if testServer, err = server.NewTestServerContainer(c.MockBusServiceServer.Get()); err != nil {
+				panic(err)
+			}
+			return testServer
+		},
+	}
+	c.MockNatsGrpcClient = dependency.LazyDependency[*nats_service.NatsClient]{
+		InitFunc: func() *nats_service.NatsClient {
+			var (
+				logger     = c.Logger.Get()
+				address    = c.MockServerContainer.Get().Address
+				validator  = c.NatsGrpcValidator.Get()
+				natsClient *nats_service.NatsClient
+				env        = "dev"
+				err        error
+			)
+			if natsClient, err = nats_service.NewNatsClient(env, address, validator, logger); err != nil {
+				panic(err)
+			}
+			return natsClient
+		},
+	}
+	c.CappedOutboundMessageService = dependency.LazyDependency[*messages.OutboundMessageService]{
+		InitFunc: func() *messages.OutboundMessageService {
+			var (
+				logger         = c.Logger.Get()
+				natsClient     = c.MockNatsGrpcClient.Get()
+				urlRepository  = c.MockUrlRepository.Get()
+				interval       = time.Duration(100) * time.Millisecond
+				batchSize      = 20
+				concurrencyCap = 3
+				metrics        = c.OutboundMetrics.Get()
+			)
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, clock.NewReal(), logger)
+		},
+	}
+	c.FakeClock = dependency.LazyDependency[*clock.Fake]{
+		InitFunc: func() *clock.Fake { return clock.NewFake(time.Now()) },
+	}
+	c.FakeClockOutboundService = dependency.LazyDependency[*messages.OutboundMessageService]{
+		InitFunc: func() *messages.OutboundMessageService {
+			var (
+				logger         = c.Logger.Get()
+				natsClient     = c.MockNatsGrpcClient.Get()
+				urlRepository  = c.MockUrlRepository.Get()
+				interval       = time.Duration(5) * time.Minute
+				batchSize      = 20
+				concurrencyCap = 0
+				metrics        = c.OutboundMetrics.Get()
+			)
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, c.FakeClock.Get(), logger)
+		},
+	}
+	c.RunBudget = dependency.LazyDependency[*runlimit.Budget]{
+		InitFunc: func() *runlimit.Budget {
+			return runlimit.NewBudget(runlimit.Limits{MaxMessages: 3})
+		},
+	}
+	c.BudgetedOutboundService = dependency.LazyDependency[*messages.OutboundMessageService]{
+		InitFunc: func() *messages.OutboundMessageService {
+			var (
+				logger         = c.Logger.Get()
+				natsClient     = c.MockNatsGrpcClient.Get()
+				urlRepository  = c.MockUrlRepository.Get()
+				interval       = time.Duration(5) * time.Minute
+				batchSize      = 20
+				concurrencyCap = 0
+				metrics        = c.OutboundMetrics.Get()
+			)
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, c.FakeClock.Get(), logger,
+				messages.WithBudget(c.RunBudget.Get()))
+		},
+	}
+	c.FilteringOutboundService = dependency.LazyDependency[*messages.OutboundMessageService]{
+		InitFunc: func() *messages.OutboundMessageService {
+			var (
+				logger         = c.Logger.Get()
+				natsClient     = c.MockNatsGrpcClient.Get()
+				urlRepository  = c.MockUrlRepository.Get()
+				interval       = time.Duration(5) * time.Minute
+				batchSize      = 20
+				concurrencyCap = 0
+				metrics        = c.OutboundMetrics.Get()
+			)
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, c.FakeClock.Get(), logger,
+				messages.WithDisallowedHosts("blocked.example.com"))
+		},
+	}
+	c.AuditedMongoRepository = dependency.LazyDependency[interfaces.UrlRepository]{
+		InitFunc: func() interfaces.UrlRepository {
+			var (
+				logger         = c.Logger.Get()
+				mongoClient    *mongo.Client
+				collection     *mongo.Collection
+				transitions    *mongo.Collection
+				collectionName = sharedConfig.GetConfig().Mongo.Collection
+				dbName         = sharedConfig.GetConfig().Mongo.DB
+				err            error
+			)
+			if mongoClient, err = c.MongoClient.Get().Connect(); err != nil {
+				panic(err)
+			}
+			collection = mongoClient.Database(dbName).Collection(collectionName)
+			transitions = mongoClient.Database(dbName).Collection("transitions")
+			return url.NewRepository(mongoClient, collection, logger, url.WithTransitionLog(transitions))
+		},
+	}
+	c.CrashingUrlRepository = dependency.LazyDependency[*CrashingUrlRepository]{
+		InitFunc: func() *CrashingUrlRepository {
+			return NewCrashingUrlRepository(c.AuditedMongoRepository.Get(), 1)
+		},
+	}
+	c.ClaimingOutboundService = dependency.LazyDependency[*messages.OutboundMessageService]{
+		InitFunc: func() *messages.OutboundMessageService {
+			var (
+				logger         = c.Logger.Get()
+				natsClient     = c.MockNatsGrpcClient.Get()
+				urlRepository  = c.CrashingUrlRepository.Get()
+				interval       = time.Duration(5) * time.Minute
+				batchSize      = 20
+				concurrencyCap = 0
+				metrics        = c.OutboundMetrics.Get()
+				claimLease     = time.Duration(1) * time.Minute
+			)
+			return messages.NewOutboundMessageService(
+				natsClient, urlRepository, interval, batchSize, concurrencyCap, metrics, c.FakeClock.Get(), logger,
+				messages.WithClaims(claimLease))
+		},
+	}
+
 	return c
 }
@@ -4,14 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"nats-service/tests/integration/harness"
+	"shared/grpc/clients/nats_service"
 	"shared/grpc/clients/nats_service/messaging"
 	"sync"
 	"testing"
 	"time"
+	"url-service/application/services/messages"
 	"url-service/domain/entities"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TestInboundMessageService_ProcessMessage verifies that when a valid URL message is published
@@ -114,3 +121,578 @@ func TestInboundMessageService_ConcurrentMessages(t *testing.T) {
 
 	require.Len(t, list, numMessages, "Expected %d messages to be saved, got %d", numMessages, len(list))
 }
+
+// TestInboundMessageService_QueueGroup verifies that two instances in the same queue group share the
+// UrlIncoming messages, so each message is saved exactly once, and that a consumer without a queue group
+// refuses to start.
+func TestInboundMessageService_QueueGroup(t *testing.T) {
+	var (
+		container   = NewTestContainer()
+		bus         = harness.New(t)
+		logger      = container.Logger.Get()
+		validator   = container.NatsGrpcValidator.Get()
+		queueGroup  = "url-service"
+		numMessages = 20
+		clients     = make([]*nats_service.NatsClient, 3)
+		instances   = make([]*MockUrlRepository, 2)
+		err         error
+	)
+	for i := range clients {
+		clients[i], err = nats_service.NewNatsClient("dev", bus.Address, validator, logger)
+		require.NoError(t, err, "Failed to create in-process NATS client")
+		client := clients[i]
+		t.Cleanup(func() { _ = client.Close() })
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	for i := range instances {
+		instances[i] = NewMockUrlRepository(0)
+		service := messages.NewInboundMessageService(clients[i], instances[i], 5, queueGroup,
+			entities.SizeLimits{}, logger)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = service.Start(ctx)
+		}()
+	}
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(messaging.UrlIncoming) == len(instances) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Instances did not subscribe")
+
+	publisher := clients[len(clients)-1]
+	for i := 0; i < numMessages; i++ {
+		data, marshalErr := json.Marshal(map[string]string{
+			"address": fmt.Sprintf("https://example.com/queue/%d", i),
+			"source":  "queue_group_test",
+		})
+		require.NoError(t, marshalErr, "Failed to marshal message payload")
+		require.NoError(t, publisher.Publish(ctx, messaging.UrlIncoming, data), "Failed to publish message %d", i)
+	}
+
+	saved := func() (all []string) {
+		for _, instance := range instances {
+			all = append(all, instance.Saved()...)
+		}
+		return all
+	}
+	require.Eventually(t, func() bool { return len(saved()) == numMessages },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Not every message was saved")
+	time.Sleep(time.Duration(100) * time.Millisecond)
+
+	all := saved()
+	require.Len(t, all, numMessages, "Messages were processed more than once")
+	seen := make(map[string]struct{}, numMessages)
+	for _, address := range all {
+		_, duplicate := seen[address]
+		require.False(t, duplicate, "Message %s processed twice", address)
+		seen[address] = struct{}{}
+	}
+	for i, instance := range instances {
+		require.NotEmpty(t, instance.Saved(), "Instance %d received no messages", i)
+	}
+
+	noGroup := messages.NewInboundMessageService(publisher, NewMockUrlRepository(0), 5, " ", entities.SizeLimits{}, logger)
+	require.ErrorIs(t, noGroup.Start(ctx), messages.ErrQueueGroupRequired)
+}
+
+// TestInboundMessageService_ConsumerLag verifies that a message enveloped with its publish time records
+// a plausible delivery delay in the consumer lag histogram, and that legacy messages record none.
+func TestInboundMessageService_ConsumerLag(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		bus        = harness.New(t)
+		logger     = container.Logger.Get()
+		validator  = container.NatsGrpcValidator.Get()
+		registry   = container.MetricsRegistry.Get()
+		repository = NewMockUrlRepository(0)
+		delay      = time.Duration(2) * time.Second
+	)
+	client, err := nats_service.NewNatsClient("dev", bus.Address, validator, logger)
+	require.NoError(t, err, "Failed to create in-process NATS client")
+	t.Cleanup(func() { _ = client.Close() })
+
+	service := messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{}, logger,
+		messages.WithConsumerMetrics(container.ConsumerMetrics.Get()))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(messaging.UrlIncoming) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	payload, err := json.Marshal(map[string]string{"address": "https://example.com/lag", "source": "lag_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	enveloped, err := messaging.Encode(messaging.Envelope{
+		Format:      messaging.FormatJSON,
+		Payload:     payload,
+		PublishedAt: time.Now().Add(-delay),
+	})
+	require.NoError(t, err, "Failed to encode envelope")
+	require.NoError(t, client.Publish(ctx, messaging.UrlIncoming, enveloped), "Failed to publish enveloped message")
+
+	legacy, err := json.Marshal(map[string]string{"address": "https://example.com/legacy", "source": "lag_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	require.NoError(t, client.Publish(ctx, messaging.UrlIncoming, legacy), "Failed to publish legacy message")
+
+	require.Eventually(t, func() bool { return len(repository.Saved()) == 2 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Messages were not saved")
+	require.ElementsMatch(t, []string{"https://example.com/lag", "https://example.com/legacy"}, repository.Saved())
+
+	count, sum := histogramValue(t, registry, "url_service_consumer_lag_seconds", messaging.UrlIncoming)
+	require.Equal(t, uint64(1), count, "Only the enveloped message carries a publish time")
+	require.GreaterOrEqual(t, sum, delay.Seconds(), "Lag shorter than the publish delay")
+	require.Less(t, sum, delay.Seconds()+5, "Lag implausibly long")
+}
+
+// histogramValue returns the sample count and sum of a histogram in the registry for the given subject.
+func histogramValue(t *testing.T, registry *prometheus.Registry, name, subject string) (count uint64, sum float64) {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "subject" && label.GetValue() == subject {
+					return metric.GetHistogram().GetSampleCount(), metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	return 0, 0
+}
+
+// TestInboundMessageService_MockNatsClient verifies that the inbound service subscribes and saves URLs
+// through any nats_service.Client implementation.
+func TestInboundMessageService_MockNatsClient(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		service    = messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+			container.Logger.Get())
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	payload, err := json.Marshal(map[string]string{"address": "https://example.com/mock-client", "source": "mock"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	client.Deliver(messaging.UrlIncoming, payload)
+
+	require.Eventually(t, func() bool { return len(repository.Saved()) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Message was not saved")
+	require.Equal(t, []string{"https://example.com/mock-client"}, repository.Saved())
+}
+
+// TestInboundMessageService_ProcessTimeout verifies that a stuck save is abandoned once the processing timeout
+// elapses, releasing its processing slot for the next message and counting the message as failed.
+func TestInboundMessageService_ProcessTimeout(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		registry   = container.MetricsRegistry.Get()
+		stall      = time.Second
+		service    = messages.NewInboundMessageService(client, repository, 1, "url-service", entities.SizeLimits{},
+			container.Logger.Get(), messages.WithConsumerMetrics(container.ConsumerMetrics.Get()),
+			messages.WithProcessTimeout(time.Duration(50)*time.Millisecond))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	repository.StallSaves(stall)
+	stuck, err := json.Marshal(map[string]string{"address": "https://example.com/stuck", "source": "timeout_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	client.Deliver(messaging.UrlIncoming, stuck)
+	require.Eventually(t, func() bool {
+		return counterValue(t, registry, "url_service_consumer_failed_total") == 1
+	}, stall/2, time.Duration(10)*time.Millisecond, "Expected the stuck message to be counted as failed")
+
+	// The only processing slot is free again while the stuck save is still running
+	repository.StallSaves(0)
+	next, err := json.Marshal(map[string]string{"address": "https://example.com/next", "source": "timeout_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	client.Deliver(messaging.UrlIncoming, next)
+	require.Eventually(t, func() bool { return len(repository.Saved()) == 1 },
+		stall/2, time.Duration(10)*time.Millisecond, "Next message was not saved while the save was stuck")
+	require.Equal(t, []string{"https://example.com/next"}, repository.Saved())
+
+	// The abandoned save still completes on its own
+	require.Eventually(t, func() bool { return len(repository.Saved()) == 2 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Stuck save did not complete")
+}
+
+// TestInboundMessageService_SubscribeRetry verifies that the inbound service keeps retrying to subscribe
+// while NATS is unavailable, then subscribes and saves URLs once it is back.
+func TestInboundMessageService_SubscribeRetry(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		service    = messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+			container.Logger.Get(), messages.WithSubscribeRetry(time.Millisecond, time.Duration(20)*time.Millisecond))
+	)
+	client.FailSubscribes(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- service.Start(ctx) }()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+	require.Equal(t, 4, client.Subscribes(), "Expected the service to retry every failed subscribe")
+
+	payload, err := json.Marshal(map[string]string{"address": "https://example.com/retry", "source": "retry"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	client.Deliver(messaging.UrlIncoming, payload)
+	require.Eventually(t, func() bool { return len(repository.Saved()) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Message was not saved")
+
+	// A canceled context stops the retries, including while waiting for the next attempt
+	cancel()
+	select {
+	case err = <-done:
+		require.NoError(t, err, "Expected the service to stop without an error")
+	case <-time.After(time.Duration(2) * time.Second):
+		t.Fatal("Service did not stop after the context was canceled")
+	}
+	require.Equal(t, 4, client.Subscribes(), "Expected no subscribe after the context was canceled")
+}
+
+// TestInboundMessageService_SubscribeFatal verifies that the inbound service stops instead of retrying
+// once a subscribe fails with an error that is not retryable.
+func TestInboundMessageService_SubscribeFatal(t *testing.T) {
+	var (
+		container = NewTestContainer()
+		client    = NewMockNatsClient()
+		service   = messages.NewInboundMessageService(client, NewMockUrlRepository(0), 5, "url-service",
+			entities.SizeLimits{}, container.Logger.Get(), messages.WithSubscribeRetry(time.Millisecond, time.Millisecond))
+		rejected = status.Error(codes.PermissionDenied, "subject not allowed")
+	)
+	client.FailSubscribesWith(3, rejected)
+
+	done := make(chan error, 1)
+	go func() { done <- service.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, rejected, "Expected the subscribe failure to be returned")
+	case <-time.After(time.Duration(2) * time.Second):
+		t.Fatal("Service kept retrying a permanent subscribe failure")
+	}
+	require.Equal(t, 1, client.Subscribes(), "Expected no retry of a permanent subscribe failure")
+}
+
+// TestInboundMessageService_MaxMessageAge verifies that messages published longer ago than the max. message age
+// are dropped and counted, while fresh messages and messages without a publish time are saved.
+func TestInboundMessageService_MaxMessageAge(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		registry   = container.MetricsRegistry.Get()
+		maxAge     = time.Duration(1) * time.Minute
+		service    = messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+			container.Logger.Get(), messages.WithConsumerMetrics(container.ConsumerMetrics.Get()),
+			messages.WithMaxMessageAge(maxAge))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	for _, message := range []struct {
+		address     string
+		publishedAt time.Time
+	}{
+		{address: "https://example.com/stale", publishedAt: time.Now().Add(-time.Hour)},
+		{address: "https://example.com/fresh", publishedAt: time.Now()},
+		{address: "https://example.com/unknown"},
+	} {
+		payload, err := json.Marshal(map[string]string{"address": message.address, "source": "max_age_test"})
+		require.NoError(t, err, "Failed to marshal message payload")
+		enveloped, err := messaging.Encode(messaging.Envelope{
+			Format:      messaging.FormatJSON,
+			Payload:     payload,
+			PublishedAt: message.publishedAt,
+		})
+		require.NoError(t, err, "Failed to encode envelope")
+		client.Deliver(messaging.UrlIncoming, enveloped)
+	}
+
+	require.Eventually(t, func() bool { return len(repository.Saved()) == 2 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Messages were not saved")
+	require.ElementsMatch(t, []string{"https://example.com/fresh", "https://example.com/unknown"}, repository.Saved())
+	require.Equal(t, float64(1), counterValue(t, registry, "url_service_consumer_stale_dropped_total"),
+		"Expected the stale message to be counted")
+}
+
+// TestInboundMessageService_Duplicates verifies that a republished URL is saved once, and that new and duplicate
+// URLs are counted separately.
+func TestInboundMessageService_Duplicates(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		registry   = container.MetricsRegistry.Get()
+		service    = messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+			container.Logger.Get(), messages.WithConsumerMetrics(container.ConsumerMetrics.Get()))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	for _, address := range []string{"https://example.com/once", "https://example.com/once", "https://example.com/other"} {
+		payload, err := json.Marshal(map[string]string{"address": address, "source": "duplicate_test"})
+		require.NoError(t, err, "Failed to marshal message payload")
+		client.Deliver(messaging.UrlIncoming, payload)
+	}
+
+	handled := func() float64 {
+		return counterValue(t, registry, "url_service_consumer_new_urls_total") +
+			counterValue(t, registry, "url_service_consumer_duplicate_urls_total")
+	}
+	require.Eventually(t, func() bool { return handled() == 3 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Messages were not handled")
+	require.ElementsMatch(t, []string{"https://example.com/once", "https://example.com/other"}, repository.Saved())
+	require.Equal(t, float64(2), counterValue(t, registry, "url_service_consumer_new_urls_total"),
+		"Expected the new URLs to be counted")
+	require.Equal(t, float64(1), counterValue(t, registry, "url_service_consumer_duplicate_urls_total"),
+		"Expected the republished URL to be counted as a duplicate")
+}
+
+// TestInboundMessageService_WireFields verifies that the ID and the deletion time of an incoming URL are not taken
+// from the wire, so that a message cannot save a URL already soft-deleted.
+func TestInboundMessageService_WireFields(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		service    = messages.NewInboundMessageService(client, repository, 1, "url-service", entities.SizeLimits{},
+			container.Logger.Get())
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	payload, err := json.Marshal(map[string]string{
+		"id":         primitive.NewObjectID().Hex(),
+		"address":    "https://example.com/deleted-on-the-wire",
+		"source":     "wire_test",
+		"deleted_at": time.Now().Format(time.RFC3339),
+	})
+	require.NoError(t, err, "Failed to marshal message payload")
+	client.Deliver(messaging.UrlIncoming, payload)
+
+	require.Eventually(t, func() bool { return len(repository.Upserted()) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Message was not saved")
+	upserted := repository.Upserted()[0]
+	require.True(t, upserted.Id.IsZero(), "Expected the ID not to be taken from the wire")
+	require.True(t, upserted.DeletedAt.IsZero(), "Expected the deletion time not to be taken from the wire")
+	require.Equal(t, entities.StatusPending, upserted.Status, "Expected the URL to be pending")
+}
+
+// TestInboundMessageService_Acks verifies that with acks enabled a message is acked only once its URL is saved,
+// that a message whose save failed stays unacked, that invalid messages are acked as they will never be saved,
+// and that a client without acked subscriptions is refused.
+func TestInboundMessageService_Acks(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		service    = messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+			container.Logger.Get(), messages.WithAckWait(time.Second))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	payload, err := json.Marshal(map[string]string{"address": "https://example.com/acked", "source": "ack_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+
+	repository.FailSaves(1)
+	acked := client.DeliverWithAck(messaging.UrlIncoming, payload)
+	require.Never(t, func() bool { return isClosed(acked) },
+		time.Duration(200)*time.Millisecond, time.Duration(10)*time.Millisecond, "Unsaved message was acked")
+	require.Empty(t, repository.Saved(), "Expected the failed save to save nothing")
+
+	acked = client.DeliverWithAck(messaging.UrlIncoming, payload)
+	require.Eventually(t, func() bool { return isClosed(acked) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Redelivered message was not acked")
+	require.Equal(t, []string{"https://example.com/acked"}, repository.Saved())
+
+	acked = client.DeliverWithAck(messaging.UrlIncoming, []byte("{not json"))
+	require.Eventually(t, func() bool { return isClosed(acked) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Invalid message was not acked")
+
+	plain := messages.NewInboundMessageService(struct{ nats_service.Client }{client}, repository, 5, "url-service",
+		entities.SizeLimits{}, container.Logger.Get(), messages.WithAckWait(time.Second))
+	require.ErrorIs(t, plain.Start(ctx), messages.ErrAcksUnsupported)
+}
+
+// TestInboundMessageService_DuplicateAddress verifies that a message whose address is already saved is acked
+// as handled rather than left for redelivery or counted as failed.
+func TestInboundMessageService_DuplicateAddress(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		registry   = container.MetricsRegistry.Get()
+		service    = messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+			container.Logger.Get(), messages.WithConsumerMetrics(container.ConsumerMetrics.Get()),
+			messages.WithAckWait(time.Second))
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return client.Subscribed(messaging.UrlIncoming) },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	payload, err := json.Marshal(map[string]string{"address": "https://example.com/duplicate", "source": "dup_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+	for i := 0; i < 2; i++ {
+		acked := client.DeliverWithAck(messaging.UrlIncoming, payload)
+		require.Eventually(t, func() bool { return isClosed(acked) },
+			time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Message was not acked")
+	}
+	require.Equal(t, []string{"https://example.com/duplicate"}, repository.Saved())
+	require.Zero(t, counterValue(t, registry, "url_service_consumer_failed_total"),
+		"Expected the duplicate not to be counted as failed")
+}
+
+// TestInboundMessageService_AckRedelivery verifies end to end, through BusService and its JetStream ack
+// semantics, that a message received by an instance crashing before the save is redelivered once the ack wait
+// elapsed, saved, and acked.
+func TestInboundMessageService_AckRedelivery(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		bus        = harness.New(t)
+		logger     = container.Logger.Get()
+		validator  = container.NatsGrpcValidator.Get()
+		repository = NewMockUrlRepository(0)
+		client     *nats_service.NatsClient
+		err        error
+	)
+	client, err = nats_service.NewNatsClient("dev", bus.Address, validator, logger)
+	require.NoError(t, err, "Failed to create in-process NATS client")
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	service := messages.NewInboundMessageService(client, repository, 5, "url-service", entities.SizeLimits{},
+		logger, messages.WithAckWait(time.Second))
+	go func() {
+		defer close(done)
+		_ = service.Start(ctx)
+	}()
+	require.Eventually(t, func() bool { return bus.Operations.Subscribers(messaging.UrlIncoming) == 1 },
+		time.Duration(2)*time.Second, time.Duration(10)*time.Millisecond, "Service did not subscribe")
+
+	payload, err := json.Marshal(map[string]string{"address": "https://example.com/redelivered", "source": "ack_test"})
+	require.NoError(t, err, "Failed to marshal message payload")
+
+	repository.FailSaves(1)
+	require.NoError(t, client.Publish(ctx, messaging.UrlIncoming, payload), "Failed to publish message")
+	time.Sleep(time.Duration(300) * time.Millisecond)
+	require.Empty(t, repository.Saved(), "Expected the crashed save to save nothing")
+	require.Equal(t, 0, bus.Operations.Acks(), "Unsaved message was acked")
+	require.Equal(t, 1, bus.Operations.Unacked(), "Expected the message to wait for its ack")
+
+	require.Eventually(t, func() bool { return bus.Operations.Acks() == 1 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Redelivered message was not acked")
+	require.Equal(t, []string{"https://example.com/redelivered"}, repository.Saved())
+	require.Equal(t, 0, bus.Operations.Unacked(), "Expected no message waiting for its ack")
+}
+
+// isClosed reports whether the channel is closed.
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,435 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"shared/grpc/clients/nats_service"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"url-service/domain/entities"
+	"url-service/domain/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockUrlRepository is an in-memory implementation of interfaces.UrlRepository for testing.
+type MockUrlRepository struct {
+	mu          sync.Mutex        // mu guards pending, processing, saved and statuses.
+	pending     []*entities.Url   // pending holds the pending URLs returned by FetchBatch and ClaimPending.
+	processing  []*entities.Url   // processing holds the URLs claimed by ClaimPending and not marked yet.
+	saved       []string          // saved holds the addresses of the saved URLs.
+	upserted    []entities.Url    // upserted holds copies of the URLs passed to Upsert.
+	statuses    map[string]string // statuses holds the last status set by UpdateFields by URL ID.
+	hidden      atomic.Int64      // hidden is the number of pending URLs counted but never returned by FetchBatch.
+	updateDelay time.Duration     // updateDelay simulates a slow UpdateFields call.
+	inFlight    atomic.Int32      // inFlight is the number of UpdateFields calls in progress.
+	maxInFlight atomic.Int32      // maxInFlight is the highest observed number of concurrent UpdateFields calls.
+	updated     atomic.Int32      // updated is the number of completed UpdateFields calls.
+	failures    atomic.Int32      // failures is the number of upcoming UpdateFields calls that fail.
+	saveFails   atomic.Int32      // saveFails is the number of upcoming Save or Upsert calls that fail.
+	saveDelay   atomic.Int64      // saveDelay simulates a stuck Save or Upsert call ignoring its context.
+	claimed     atomic.Int32      // claimed is the number of URLs returned by ClaimPending.
+	claims      atomic.Int32      // claims is the number of ClaimPending calls.
+	overlapping atomic.Int32      // overlapping is the number of claims made while claimed URLs were unprocessed.
+}
+
+// NewMockUrlRepository creates a new instance of MockUrlRepository.
+func NewMockUrlRepository(updateDelay time.Duration) *MockUrlRepository {
+	return &MockUrlRepository{updateDelay: updateDelay}
+}
+
+// AddPending adds pending URLs returned by FetchBatch and ClaimPending.
+func (r *MockUrlRepository) AddPending(urls ...*entities.Url) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = append(r.pending, urls...)
+}
+
+// HidePending adds n pending URLs that are counted but never returned by FetchBatch,
+// simulating a scan filter that does not match the pending status.
+func (r *MockUrlRepository) HidePending(n int) { r.hidden.Add(int64(n)) }
+
+// FailUpdates makes the next n UpdateFields calls fail, releases of claimed URLs to pending are never failed.
+func (r *MockUrlRepository) FailUpdates(n int) { r.failures.Store(int32(n)) }
+
+// FailSaves makes the next n Save or Upsert calls fail, simulating a crash before the URL is saved.
+func (r *MockUrlRepository) FailSaves(n int) { r.saveFails.Store(int32(n)) }
+
+// StallSaves makes every upcoming Save or Upsert call take the given delay regardless of its context.
+func (r *MockUrlRepository) StallSaves(delay time.Duration) { r.saveDelay.Store(int64(delay)) }
+
+// Save records the address of the URL, an address recorded before is rejected like the unique index does.
+func (r *MockUrlRepository) Save(ctx context.Context, url *entities.Url) (err error) {
+	time.Sleep(time.Duration(r.saveDelay.Load()))
+	if r.saveFails.Add(-1) >= 0 {
+		return errors.New("injected save failure")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if slices.Contains(r.saved, url.Address) {
+		return fmt.Errorf("insert one: %w", entities.ErrDuplicateAddress)
+	}
+	r.saved = append(r.saved, url.Address)
+	return nil
+}
+
+// Upsert records the address of the URL unless it is already recorded.
+func (r *MockUrlRepository) Upsert(ctx context.Context, url *entities.Url) (inserted bool, err error) {
+	time.Sleep(time.Duration(r.saveDelay.Load()))
+	if r.saveFails.Add(-1) >= 0 {
+		return false, errors.New("injected save failure")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.upserted = append(r.upserted, *url)
+	if slices.Contains(r.saved, url.Address) {
+		return false, nil
+	}
+	r.saved = append(r.saved, url.Address)
+	return true, nil
+}
+
+// Upserted returns copies of the URLs passed to Upsert, in the order of the calls.
+func (r *MockUrlRepository) Upserted() []entities.Url {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entities.Url(nil), r.upserted...)
+}
+
+// Saved returns the addresses of the saved URLs.
+func (r *MockUrlRepository) Saved() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.saved...)
+}
+
+// FetchBatch returns up to limit pending URLs, the options are not evaluated. Like in MongoDB, the fetched URLs
+// stay pending until UpdateFields moves them to another status.
+func (r *MockUrlRepository) FetchBatch(ctx context.Context, filter bson.M, limit int, opts ...entities.FetchOption) (
+	list []*entities.Url, err error,
+) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*entities.Url(nil), r.pending[:min(limit, len(r.pending))]...), nil
+}
+
+// FetchPage returns up to limit pending URLs with an ID after afterID in ID order, without removing them,
+// the options are not evaluated.
+func (r *MockUrlRepository) FetchPage(
+	ctx context.Context,
+	filter bson.M,
+	limit int,
+	afterID string,
+	opts ...entities.FetchOption,
+) (list []*entities.Url, next string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sorted := append([]*entities.Url(nil), r.pending...)
+	slices.SortFunc(sorted, func(a, b *entities.Url) int { return strings.Compare(a.Id.Hex(), b.Id.Hex()) })
+	for _, url := range sorted {
+		if len(list) < limit && url.Id.Hex() > afterID {
+			list = append(list, url)
+		}
+	}
+	if len(list) > 0 {
+		next = list[len(list)-1].Id.Hex()
+	}
+	return list, next, nil
+}
+
+// Count returns the number of pending and hidden URLs, the filter is not evaluated.
+func (r *MockUrlRepository) Count(ctx context.Context, filter bson.M) (count int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.pending)) + r.hidden.Load(), nil
+}
+
+// UpdateFields records the concurrency of the call and waits for updateDelay.
+func (r *MockUrlRepository) UpdateFields(ctx context.Context, id string, updateFields bson.M) (err error) {
+	if updateFields["status"] != entities.StatusPending && r.failures.Add(-1) >= 0 {
+		return errors.New("injected update failure")
+	}
+	if status, ok := updateFields["status"].(string); ok {
+		r.mu.Lock()
+		if r.statuses == nil {
+			r.statuses = make(map[string]string)
+		}
+		r.statuses[id] = status
+		r.move(id, status)
+		r.mu.Unlock()
+	}
+
+	current := r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+
+	for {
+		highest := r.maxInFlight.Load()
+		if current <= highest || r.maxInFlight.CompareAndSwap(highest, current) {
+			break
+		}
+	}
+
+	time.Sleep(r.updateDelay)
+	r.updated.Add(1)
+	return nil
+}
+
+// BulkUpdateFields is a no-op.
+func (r *MockUrlRepository) BulkUpdateFields(ctx context.Context, ids []string, updateFields bson.M) (err error) {
+	return nil
+}
+
+// BulkUpdateFieldsDetailed is a no-op matching every ID.
+func (r *MockUrlRepository) BulkUpdateFieldsDetailed(ctx context.Context, ids []string, updateFields bson.M) (
+	result *entities.BulkUpdateResult, err error,
+) {
+	return &entities.BulkUpdateResult{Matched: int64(len(ids)), Modified: int64(len(ids))}, nil
+}
+
+// move moves the URL with the given ID between pending and processing according to its new status, a URL moved
+// to any other status is removed from both. The caller holds mu.
+func (r *MockUrlRepository) move(id, status string) {
+	var url *entities.Url
+	for _, list := range []*[]*entities.Url{&r.pending, &r.processing} {
+		if i := slices.IndexFunc(*list, func(url *entities.Url) bool { return url.Id.Hex() == id }); i >= 0 {
+			url = (*list)[i]
+			*list = slices.Delete(*list, i, i+1)
+		}
+	}
+	switch {
+	case url == nil:
+	case status == entities.StatusPending:
+		r.pending = append(r.pending, url)
+	case status == entities.StatusProcessing:
+		r.processing = append(r.processing, url)
+	}
+}
+
+// ClaimPending moves up to limit pending URLs to processing and returns them.
+// It records whether previously claimed URLs were still being processed, i.e., whether the batches overlap.
+func (r *MockUrlRepository) ClaimPending(ctx context.Context, limit int) (list []*entities.Url, err error) {
+	r.claims.Add(1)
+	if r.claimed.Load() > r.updated.Load() {
+		r.overlapping.Add(1)
+	}
+
+	r.mu.Lock()
+	n := min(limit, len(r.pending))
+	list, r.pending = append([]*entities.Url(nil), r.pending[:n]...), r.pending[n:]
+	r.processing = append(r.processing, list...)
+	r.mu.Unlock()
+
+	r.claimed.Add(int32(len(list)))
+	return list, nil
+}
+
+// FetchTransitions returns no transitions.
+func (r *MockUrlRepository) FetchTransitions(ctx context.Context, id string) (list []*entities.StatusTransition, err error) {
+	return nil, nil
+}
+
+// ReleaseStale releases nothing, the claims of the mock never expire.
+func (r *MockUrlRepository) ReleaseStale(ctx context.Context, cutoff time.Time) (released int, err error) {
+	return 0, nil
+}
+
+// SoftDelete records the deleted status of the URL.
+func (r *MockUrlRepository) SoftDelete(ctx context.Context, id string) (err error) {
+	return r.UpdateFields(ctx, id, bson.M{"status": entities.StatusDeleted})
+}
+
+// PurgeDeleted purges nothing, the mock keeps no deleted URLs.
+func (r *MockUrlRepository) PurgeDeleted(ctx context.Context, olderThan time.Time) (purged int, err error) {
+	return 0, nil
+}
+
+// DeleteDuplicateAddresses deletes nothing, the mock keeps no duplicate addresses.
+func (r *MockUrlRepository) DeleteDuplicateAddresses(ctx context.Context) (deleted int, err error) {
+	return 0, nil
+}
+
+// DeleteByStatus deletes nothing, the mock keeps no terminal URLs.
+func (r *MockUrlRepository) DeleteByStatus(ctx context.Context, statuses []string, cutoff time.Time) (deleted int, err error) {
+	return 0, nil
+}
+
+// Status returns the last status set by UpdateFields for the URL, or an empty string.
+func (r *MockUrlRepository) Status(id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.statuses[id]
+}
+
+// MaxInFlight returns the highest observed number of concurrent UpdateFields calls.
+func (r *MockUrlRepository) MaxInFlight() int { return int(r.maxInFlight.Load()) }
+
+// Updated returns the number of completed UpdateFields calls.
+func (r *MockUrlRepository) Updated() int { return int(r.updated.Load()) }
+
+// Claims returns the number of ClaimPending calls.
+func (r *MockUrlRepository) Claims() int { return int(r.claims.Load()) }
+
+// Overlapping returns the number of ClaimPending calls made while claimed URLs were still being processed.
+func (r *MockUrlRepository) Overlapping() int { return int(r.overlapping.Load()) }
+
+// CrashingUrlRepository wraps a repository and fails the next marks as succeeded,
+// simulating a crash between the publish and the status update.
+type CrashingUrlRepository struct {
+	interfaces.UrlRepository
+	crashes atomic.Int32 // crashes is the number of upcoming marks as succeeded that fail.
+}
+
+// NewCrashingUrlRepository creates a new instance of CrashingUrlRepository failing the next crashes marks.
+func NewCrashingUrlRepository(repository interfaces.UrlRepository, crashes int) *CrashingUrlRepository {
+	r := &CrashingUrlRepository{UrlRepository: repository}
+	r.crashes.Store(int32(crashes))
+	return r
+}
+
+// UpdateFields fails marks as succeeded while crashes remain and delegates every other update.
+func (r *CrashingUrlRepository) UpdateFields(ctx context.Context, id string, updateFields bson.M) (err error) {
+	if updateFields["status"] == entities.StatusSucceeded && r.crashes.Add(-1) >= 0 {
+		return errors.New("simulated crash before marking succeeded")
+	}
+	return r.UrlRepository.UpdateFields(ctx, id, updateFields)
+}
+
+// MockNatsClient is an in-memory implementation of nats_service.AckClient for testing.
+type MockNatsClient struct {
+	mu          sync.Mutex                                   // mu guards published, handlers and ackHandlers.
+	published   map[string][][]byte                          // published holds the published messages by subject.
+	handlers    map[string]func(data []byte, subject string) // handlers holds the subscription handlers by subject.
+	ackHandlers map[string]nats_service.AckHandler           // ackHandlers holds the acked handlers by subject.
+	subscribes  atomic.Int32                                 // subscribes is the number of subscribe attempts.
+	subFails    atomic.Int32                                 // subFails is the number of upcoming failing subscribes.
+	subErr      error                                        // subErr is the error of the failing subscribes.
+}
+
+// ErrNatsUnavailable is returned by the failing subscribes of MockNatsClient by default.
+var ErrNatsUnavailable = status.Error(codes.Unavailable, "mock: NATS unavailable")
+
+// NewMockNatsClient creates a new instance of MockNatsClient.
+func NewMockNatsClient() *MockNatsClient {
+	return &MockNatsClient{
+		published:   make(map[string][][]byte),
+		handlers:    make(map[string]func(data []byte, subject string)),
+		ackHandlers: make(map[string]nats_service.AckHandler),
+		subErr:      ErrNatsUnavailable,
+	}
+}
+
+// Publish records the message under its subject.
+func (c *MockNatsClient) Publish(ctx context.Context, subject string, data []byte) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published[subject] = append(c.published[subject], append([]byte(nil), data...))
+	return nil
+}
+
+// FailSubscribes makes the next n subscribes fail, as if NATS was unavailable.
+func (c *MockNatsClient) FailSubscribes(n int) { c.FailSubscribesWith(n, ErrNatsUnavailable) }
+
+// FailSubscribesWith makes the next n subscribes fail with err, it must be called before subscribing.
+func (c *MockNatsClient) FailSubscribesWith(n int, err error) {
+	c.subErr = err
+	c.subFails.Store(int32(n))
+}
+
+// Subscribes returns the number of subscribe attempts.
+func (c *MockNatsClient) Subscribes() int { return int(c.subscribes.Load()) }
+
+// Subscribe registers the handler of the subject and blocks until the context is canceled.
+func (c *MockNatsClient) Subscribe(
+	ctx context.Context,
+	subject, queueGroup string,
+	handler func(data []byte, subject string),
+) (err error) {
+	if c.subscribes.Add(1); c.subFails.Add(-1) >= 0 {
+		return c.subErr
+	}
+	c.mu.Lock()
+	c.handlers[subject] = handler
+	c.mu.Unlock()
+
+	<-ctx.Done()
+
+	c.mu.Lock()
+	delete(c.handlers, subject)
+	c.mu.Unlock()
+	return nil
+}
+
+// SubscribeWithAck registers the acked handler of the subject and blocks until the context is canceled.
+func (c *MockNatsClient) SubscribeWithAck(
+	ctx context.Context,
+	subject, queueGroup string,
+	ackWait time.Duration,
+	handler nats_service.AckHandler,
+) (err error) {
+	c.mu.Lock()
+	c.ackHandlers[subject] = handler
+	c.mu.Unlock()
+
+	<-ctx.Done()
+
+	c.mu.Lock()
+	delete(c.ackHandlers, subject)
+	c.mu.Unlock()
+	return nil
+}
+
+// Close is a no-op.
+func (c *MockNatsClient) Close() (err error) { return nil }
+
+// Subscribed reports whether a handler is registered for the subject.
+func (c *MockNatsClient) Subscribed(subject string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.handlers[subject]
+	_, acked := c.ackHandlers[subject]
+	return ok || acked
+}
+
+// Deliver passes the message to the handler of the subject, as a subscription would.
+func (c *MockNatsClient) Deliver(subject string, data []byte) {
+	c.mu.Lock()
+	handler := c.handlers[subject]
+	c.mu.Unlock()
+	if handler != nil {
+		handler(data, subject)
+	}
+}
+
+// DeliverWithAck passes the message to the acked handler of the subject, as a JetStream subscription would.
+// The returned channel is closed once the handler acks the message, the message is not redelivered by itself.
+func (c *MockNatsClient) DeliverWithAck(subject string, data []byte) (acked <-chan struct{}) {
+	var (
+		ch   = make(chan struct{})
+		once sync.Once
+	)
+	c.mu.Lock()
+	handler := c.ackHandlers[subject]
+	c.mu.Unlock()
+	if handler != nil {
+		handler(data, subject, func(ctx context.Context) error {
+			once.Do(func() { close(ch) })
+			return nil
+		})
+	}
+	return ch
+}
+
+// Published returns the messages published to the subject.
+func (c *MockNatsClient) Published(subject string) [][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]byte(nil), c.published[subject]...)
+}
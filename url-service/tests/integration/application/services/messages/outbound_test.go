@@ -4,19 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"shared/clock"
 	"shared/grpc/clients/nats_service/messaging"
+	sharedConfig "shared/mongodb/application/config"
+	"shared/reload"
+	"shared/runlimit"
+	"strconv"
 	"sync"
+	"syscall"
 	"testing"
 	"time"
+	"url-service/application/services/messages"
 	"url-service/domain/entities"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // TestOutboundMessageService_ProcessMessage verifies that when a pending URL exists in MongoDB,
 // the OutboundMessageService publishes its JSON representation to the outbound NATS subject and
-// subsequently updates its status to processed.
+// subsequently updates its status to succeeded.
 func TestOutboundMessageService_ProcessMessage(t *testing.T) {
 	container, teardown := SetupTestContainer(t)
 	defer teardown()
@@ -70,7 +82,7 @@ func TestOutboundMessageService_ProcessMessage(t *testing.T) {
 		t.Fatal("Timeout waiting for outbound message to be published")
 	}
 
-	// Poll MongoDB until the URL status is updated to the processed status.
+	// Poll MongoDB until the URL status is updated to the succeeded status.
 	var (
 		fetchedUrls []*entities.Url
 		filter      = bson.M{"address": testUrl}
@@ -79,7 +91,7 @@ func TestOutboundMessageService_ProcessMessage(t *testing.T) {
 	for {
 		fetchedUrls, err = repository.FetchBatch(context.Background(), filter, 5)
 		require.NoError(t, err, "Failed to fetch URLs from MongoDB")
-		if len(fetchedUrls) > 0 && fetchedUrls[0].Status == entities.StatusProcessed {
+		if len(fetchedUrls) > 0 && fetchedUrls[0].Status == entities.StatusSucceeded {
 			break
 		}
 
@@ -92,7 +104,7 @@ func TestOutboundMessageService_ProcessMessage(t *testing.T) {
 	}
 
 	require.NotEmpty(t, fetchedUrls, "Failed to fetch URLs from MongoDB")
-	require.Equal(t, entities.StatusProcessed, fetchedUrls[0].Status, "Fetched URL status mismatch")
+	require.Equal(t, entities.StatusSucceeded, fetchedUrls[0].Status, "Fetched URL status mismatch")
 }
 
 // TestOutboundMessageService_ConcurrentProcessing verifies that the outbound service can handle
@@ -138,7 +150,7 @@ func TestOutboundMessageService_ConcurrentProcessing(t *testing.T) {
 	}
 	wg.Wait()
 
-	// Wait until all URLs are processed (status updated to processed).
+	// Wait until all URLs are processed (status updated to succeeded).
 	var (
 		filter  = bson.M{"source": "concurrent_test_outbound"}
 		fetched []*entities.Url
@@ -157,10 +169,10 @@ Loop:
 			fetched, err = repository.FetchBatch(context.Background(), filter, numMessages)
 			require.NoError(t, err, "Failed to fetch URLs from MongoDB")
 			if len(fetched) == numMessages {
-				// Ensure every URL has been marked as processed.
+				// Ensure every URL has been marked as succeeded.
 				allProcessed := true
 				for _, url := range fetched {
-					if url.Status != entities.StatusProcessed {
+					if url.Status != entities.StatusSucceeded {
 						allProcessed = false
 						break
 					}
@@ -174,3 +186,813 @@ Loop:
 
 	require.Len(t, fetched, numMessages, "Mismatch in expected number of processed URLs")
 }
+
+// TestOutboundMessageService_ConcurrencyCap verifies that the outbound service never processes more URLs
+// concurrently than the configured downstream concurrency cap, even when the batch size is larger.
+func TestOutboundMessageService_ConcurrencyCap(t *testing.T) {
+	var (
+		container      = NewTestContainer()
+		repository     = container.MockUrlRepository.Get()
+		service        = container.CappedOutboundMessageService.Get()
+		numMessages    = 20
+		concurrencyCap = 3
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	for i := 0; i < numMessages; i++ {
+		repository.AddPending(&entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: fmt.Sprintf("https://example.com/capped/%d", i),
+			Status:  entities.StatusPending,
+			Source:  "concurrency_cap_test",
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return repository.Updated() == numMessages },
+		time.Duration(10)*time.Second, time.Duration(50)*time.Millisecond, "Not all URLs were processed")
+	cancel()
+	<-done
+
+	require.LessOrEqual(t, repository.MaxInFlight(), concurrencyCap, "Concurrency cap exceeded")
+	require.Equal(t, concurrencyCap, repository.MaxInFlight(), "Expected the cap to be fully utilized")
+}
+
+// TestOutboundMessageService_InFlight verifies that the in-flight gauge reflects the number of URLs
+// processed concurrently, saturating at the concurrency cap and draining to zero once the batch completes.
+func TestOutboundMessageService_InFlight(t *testing.T) {
+	var (
+		container      = NewTestContainer()
+		repository     = container.MockUrlRepository.Get()
+		service        = container.CappedOutboundMessageService.Get()
+		registry       = container.MetricsRegistry.Get()
+		numMessages    = 12
+		concurrencyCap = 3
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	for i := 0; i < numMessages; i++ {
+		repository.AddPending(&entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: fmt.Sprintf("https://example.com/in-flight/%d", i),
+			Status:  entities.StatusPending,
+			Source:  "in_flight_test",
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return metricValue(t, registry, "url_service_outbound_in_flight", "") == float64(concurrencyCap)
+	}, time.Duration(5)*time.Second, time.Duration(5)*time.Millisecond, "In-flight gauge never reached the cap")
+
+	require.Eventually(t, func() bool { return repository.Updated() == numMessages },
+		time.Duration(10)*time.Second, time.Duration(50)*time.Millisecond, "Not all URLs were processed")
+	cancel()
+	<-done
+
+	require.Equal(t, float64(0), metricValue(t, registry, "url_service_outbound_in_flight", ""),
+		"Expected the in-flight gauge to drain once processing completes")
+}
+
+// TestOutboundMessageService_ScanInterval verifies that pending URLs are only scanned once the interval elapses,
+// driven deterministically by a fake clock.
+func TestOutboundMessageService_ScanInterval(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		repository = container.MockUrlRepository.Get()
+		service    = container.FakeClockOutboundService.Get()
+		fakeClock  = container.FakeClock.Get()
+		interval   = time.Duration(5) * time.Minute
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	repository.AddPending(&entities.Url{
+		Id:      primitive.NewObjectID(),
+		Address: "https://example.com/interval",
+		Status:  entities.StatusPending,
+		Source:  "scan_interval_test",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Without the clock advancing no scan happens.
+	require.Never(t, func() bool { return repository.Updated() > 0 },
+		time.Duration(200)*time.Millisecond, time.Duration(20)*time.Millisecond, "URL scanned before the interval")
+
+	fakeClock.Advance(interval - time.Second)
+	require.Never(t, func() bool { return repository.Updated() > 0 },
+		time.Duration(200)*time.Millisecond, time.Duration(20)*time.Millisecond, "URL scanned before the interval")
+
+	fakeClock.Advance(time.Second)
+	require.Eventually(t, func() bool { return repository.Updated() == 1 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "URL not scanned after the interval")
+	cancel()
+	<-done
+}
+
+// TestOutboundMessageService_ErrorCategories verifies that injected publish and update failures increment
+// their own category counters and that the cycle success gauge only counts fully processed URLs.
+func TestOutboundMessageService_ErrorCategories(t *testing.T) {
+	var (
+		container   = NewTestContainer()
+		repository  = container.MockUrlRepository.Get()
+		busService  = container.MockBusServiceServer.Get()
+		service     = container.FakeClockOutboundService.Get()
+		fakeClock   = container.FakeClock.Get()
+		registry    = container.MetricsRegistry.Get()
+		numMessages = 5
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	for i := 0; i < numMessages; i++ {
+		repository.AddPending(&entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: fmt.Sprintf("https://example.com/categories/%d", i),
+			Status:  entities.StatusPending,
+			Source:  "error_categories_test",
+		})
+	}
+	busService.FailPublishes(2)
+	repository.FailUpdates(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(time.Duration(5) * time.Minute)
+	require.Eventually(t, func() bool { return metricValue(t, registry, "url_service_outbound_cycle_success", "") == 2 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Cycle success gauge not updated")
+	cancel()
+	<-done
+
+	require.Equal(t, 0.0, metricValue(t, registry, "url_service_outbound_errors_total", "marshal"))
+	require.Equal(t, 2.0, metricValue(t, registry, "url_service_outbound_errors_total", "publish"))
+	require.Equal(t, 1.0, metricValue(t, registry, "url_service_outbound_errors_total", "update"))
+}
+
+// TestOutboundMessageService_ReloadOnSignal verifies that a simulated SIGHUP re-reads the env file and applies
+// the new log level and scan interval to the running service, the next scan follows the new interval.
+func TestOutboundMessageService_ReloadOnSignal(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		repository = container.MockUrlRepository.Get()
+		busService = container.MockBusServiceServer.Get()
+		service    = container.FakeClockOutboundService.Get()
+		fakeClock  = container.FakeClock.Get()
+		logger     = container.Logger.Get()
+		levelVar   = &slog.LevelVar{}
+		envFile    = filepath.Join(t.TempDir(), "reload.env")
+		signals    = make(chan os.Signal, 1)
+		reloaded   = make(chan struct{}, 1)
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	// Restore the variables touched by the env file once the test is done.
+	t.Setenv("LOG_LEVEL", "info")
+	t.Setenv("OUTBOUND_MESSAGE_SCAN_INTERVAL", "300")
+	envData := "export LOG_LEVEL=debug\nexport OUTBOUND_MESSAGE_SCAN_INTERVAL=60\n"
+	require.NoError(t, os.WriteFile(envFile, []byte(envData), 0o644), "Failed to write env file")
+
+	repository.AddPending(&entities.Url{
+		Id:      primitive.NewObjectID(),
+		Address: "https://example.com/reload",
+		Status:  entities.StatusPending,
+		Source:  "reload_test",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{}, 2)
+	go func() {
+		defer func() { done <- struct{}{} }()
+		service.Start(ctx)
+	}()
+	go func() {
+		defer func() { done <- struct{}{} }()
+		reload.Watch(ctx, signals, func() error {
+			defer func() { reloaded <- struct{}{} }()
+			var (
+				level    slog.Level
+				interval int
+				err      error
+			)
+			if err = reload.LoadEnvFile(envFile); err != nil {
+				return err
+			}
+			if level, err = reload.ParseLevel(os.Getenv("LOG_LEVEL")); err != nil {
+				return err
+			}
+			if interval, err = strconv.Atoi(os.Getenv("OUTBOUND_MESSAGE_SCAN_INTERVAL")); err != nil {
+				return err
+			}
+			levelVar.Set(level)
+			service.SetInterval(time.Duration(interval) * time.Second)
+			return nil
+		}, logger)
+	}()
+
+	// One minute is shorter than the initial interval, nothing is published.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(time.Duration(1) * time.Minute)
+	require.Never(t, func() bool { return busService.Published() > 0 },
+		time.Duration(200)*time.Millisecond, time.Duration(20)*time.Millisecond,
+		"Published before the initial interval elapsed")
+
+	signals <- syscall.SIGHUP
+	select {
+	case <-reloaded:
+	case <-time.After(time.Duration(5) * time.Second):
+		t.Fatal("Reload was not applied")
+	}
+	require.Equal(t, slog.LevelDebug, levelVar.Level(), "Log level not reloaded")
+	require.Equal(t, time.Duration(1)*time.Minute, service.Interval(), "Scan interval not reloaded")
+
+	// Wait for the service to restart its ticker, the next scan is one new interval away.
+	fakeClock.BlockUntilTickers(2)
+	fakeClock.Advance(time.Duration(1) * time.Minute)
+	require.Eventually(t, func() bool { return busService.Published() == 1 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Scan did not follow the reloaded interval")
+
+	cancel()
+	<-done
+	<-done
+}
+
+// metricValue returns the value of a counter or gauge in the registry, optionally filtered by its "category" label.
+func metricValue(t *testing.T, registry *prometheus.Registry, name, category string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if category == "" {
+				return metric.GetGauge().GetValue()
+			}
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "category" && label.GetValue() == category {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// TestOutboundMessageService_CrashBetweenPublishAndMark verifies the at-least-once semantics of the claim flow:
+// a URL whose mark as succeeded crashed after the publish stays claimed, is released once the lease expires,
+// is published again, and only ends up succeeded after a successful publish.
+func TestOutboundMessageService_CrashBetweenPublishAndMark(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		repository = container.AuditedMongoRepository.Get()
+		busService = container.MockBusServiceServer.Get()
+		service    = container.ClaimingOutboundService.Get()
+		fakeClock  = container.FakeClock.Get()
+		interval   = time.Duration(5) * time.Minute
+		filter     = bson.M{"source": "crash_test_outbound"}
+		ctx        = context.Background()
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+		client, err := container.MongoClient.Get().Connect()
+		require.NoError(t, err, "Failed to connect to MongoDB")
+		require.NoError(t, client.Database(sharedConfig.GetConfig().Mongo.DB).Drop(ctx), "Failed to drop database")
+		require.NoError(t, container.MongoClient.Get().Close(), "Failed to close MongoDB client")
+	})
+
+	url := &entities.Url{Address: "https://example.com/crash", Status: entities.StatusPending, Source: "crash_test_outbound"}
+	require.NoError(t, repository.Save(ctx, url), "Failed to save URL entity to MongoDB")
+
+	status := func() string {
+		list, err := repository.FetchBatch(ctx, filter, 1)
+		require.NoError(t, err, "Failed to fetch URL from MongoDB")
+		require.Len(t, list, 1, "Expected the URL to exist")
+		return list[0].Status
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(runCtx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+
+	// First cycle: claimed and published, the mark as succeeded crashes.
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return busService.Published() == 1 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "URL not published")
+	require.Never(t, func() bool { return status() != entities.StatusProcessing },
+		time.Duration(200)*time.Millisecond, time.Duration(20)*time.Millisecond, "Crashed claim left processing")
+
+	// Second cycle: the lease expired, the URL is released, claimed and published again.
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return status() == entities.StatusSucceeded },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "URL not processed after the lease")
+	cancel()
+	<-done
+
+	require.Equal(t, 2, busService.Published(), "Expected the crashed URL to be published again")
+
+	transitions, err := repository.FetchTransitions(ctx, url.Id.Hex())
+	require.NoError(t, err, "Failed to fetch transitions")
+	var path []string
+	for _, transition := range transitions {
+		path = append(path, transition.From+"->"+transition.To)
+	}
+	require.Equal(t, []string{
+		"pending->processing",
+		"processing->pending",
+		"pending->processing",
+		"processing->succeeded",
+	}, path, "Unexpected status transitions")
+}
+
+// TestOutboundMessageService_PendingGauge verifies that a scan finding work reports the pending URLs it leaves,
+// so a growing backlog shows even though no scan comes back empty. Without claims the fetched batch stays
+// pending until it is marked, it must not be counted as backlog either.
+func TestOutboundMessageService_PendingGauge(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []messages.OutboundOption
+	}{
+		{name: "claims"},
+		{name: "without claims", opts: []messages.OutboundOption{messages.WithoutClaims()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				container  = NewTestContainer()
+				repository = container.MockUrlRepository.Get()
+				fakeClock  = container.FakeClock.Get()
+				registry   = container.MetricsRegistry.Get()
+				interval   = time.Duration(5) * time.Minute
+				name       = "url_service_outbound_pending"
+			)
+			t.Cleanup(func() {
+				_ = container.MockNatsGrpcClient.Get().Close()
+				container.MockServerContainer.Get().Stop()
+			})
+			service := messages.NewOutboundMessageService(container.MockNatsGrpcClient.Get(), repository, interval,
+				20, 0, container.OutboundMetrics.Get(), fakeClock, container.Logger.Get(), tt.opts...)
+
+			// More pending URLs than a batch of 20.
+			for i := 0; i < 25; i++ {
+				repository.AddPending(&entities.Url{
+					Id:      primitive.NewObjectID(),
+					Address: fmt.Sprintf("https://example.com/pending/%d", i),
+					Status:  entities.StatusPending,
+					Source:  "pending_gauge_test",
+				})
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				service.Start(ctx)
+			}()
+
+			// Wait for the service to create its ticker before advancing the clock.
+			fakeClock.BlockUntilTickers(1)
+			fakeClock.Advance(interval)
+			require.Eventually(t, func() bool { return repository.Updated() == 20 },
+				time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "First batch not processed")
+			require.Equal(t, 5.0, metricValue(t, registry, name, ""), "Expected the pending URLs left by the scan")
+
+			fakeClock.Advance(interval)
+			require.Eventually(t, func() bool { return repository.Updated() == 25 },
+				time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Second batch not processed")
+			require.Zero(t, metricValue(t, registry, name, ""), "Expected the backlog to be drained")
+
+			cancel()
+			<-done
+		})
+	}
+}
+
+// TestOutboundMessageService_ScanInconsistency verifies that a scan finding no work while URLs are pending
+// increments the inconsistency counter, reports the backlog, and doubles the interval until the next scan.
+func TestOutboundMessageService_ScanInconsistency(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		repository = container.MockUrlRepository.Get()
+		service    = container.FakeClockOutboundService.Get()
+		fakeClock  = container.FakeClock.Get()
+		registry   = container.MetricsRegistry.Get()
+		interval   = time.Duration(5) * time.Minute
+		name       = "url_service_outbound_scan_inconsistencies_total"
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	// The pending URLs are counted but never matched by the scan filter.
+	repository.HidePending(1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return counterValue(t, registry, name) == 1 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Inconsistency not reported")
+	require.Equal(t, 1000.0, metricValue(t, registry, "url_service_outbound_pending", ""))
+
+	// The next scan is backed off to twice the interval, wait for the service to restart its ticker.
+	fakeClock.BlockUntilTickers(2)
+	fakeClock.Advance(interval)
+	require.Never(t, func() bool { return counterValue(t, registry, name) > 1 },
+		time.Duration(200)*time.Millisecond, time.Duration(20)*time.Millisecond, "Scan did not back off")
+
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return counterValue(t, registry, name) == 2 },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Backed off scan did not happen")
+
+	cancel()
+	<-done
+}
+
+// counterValue returns the value of an unlabeled counter in the registry.
+func counterValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+
+	for _, family := range families {
+		if family.GetName() == name && len(family.GetMetric()) > 0 {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+// TestOutboundMessageService_MaxMessages verifies that a job-style run publishes exactly the configured number
+// of URLs, leaves the remaining ones pending, and stops the service on its own.
+func TestOutboundMessageService_MaxMessages(t *testing.T) {
+	var (
+		container   = NewTestContainer()
+		repository  = container.MockUrlRepository.Get()
+		busService  = container.MockBusServiceServer.Get()
+		service     = container.BudgetedOutboundService.Get()
+		budget      = container.RunBudget.Get()
+		fakeClock   = container.FakeClock.Get()
+		interval    = time.Duration(5) * time.Minute
+		numMessages = 5
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	for i := 0; i < numMessages; i++ {
+		repository.AddPending(&entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: fmt.Sprintf("https://example.com/max-messages/%d", i),
+			Status:  entities.StatusPending,
+			Source:  "max_messages_test",
+		})
+	}
+
+	ctx, cancel := budget.Bind(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(interval)
+	select {
+	case <-done:
+	case <-time.After(time.Duration(5) * time.Second):
+		t.Fatal("Service did not stop after the max. number of messages")
+	}
+
+	require.ErrorIs(t, context.Cause(ctx), runlimit.ErrMaxMessages)
+	require.Equal(t, 3, busService.Published(), "Unexpected number of published URLs")
+	require.Equal(t, 3, repository.Updated(), "Unexpected number of processed URLs")
+	count, err := repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	require.NoError(t, err)
+	require.Equal(t, int64(numMessages-3), count, "Remaining URLs should stay pending")
+}
+
+// TestOutboundMessageService_TerminalStatuses verifies that a published URL is marked succeeded, a URL with an
+// invalid address permanently failed, and URLs of a disallowed host or its subdomains skipped without a publish.
+func TestOutboundMessageService_TerminalStatuses(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		repository = container.MockUrlRepository.Get()
+		busService = container.MockBusServiceServer.Get()
+		service    = container.FilteringOutboundService.Get()
+		fakeClock  = container.FakeClock.Get()
+		interval   = time.Duration(5) * time.Minute
+	)
+	t.Cleanup(func() {
+		_ = container.MockNatsGrpcClient.Get().Close()
+		container.MockServerContainer.Get().Stop()
+	})
+
+	tests := []struct {
+		address string
+		status  string
+	}{
+		{address: "https://example.com/ok", status: entities.StatusSucceeded},
+		{address: "example.com/missing-scheme", status: entities.StatusPermanentlyFailed},
+		{address: "https://blocked.example.com/page", status: entities.StatusSkipped},
+		{address: "https://WWW.Blocked.Example.com/page", status: entities.StatusSkipped},
+	}
+	ids := make([]string, len(tests))
+	for i, test := range tests {
+		url := &entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: test.address,
+			Status:  entities.StatusPending,
+			Source:  "terminal_statuses_test",
+		}
+		ids[i] = url.Id.Hex()
+		repository.AddPending(url)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return repository.Updated() == len(tests) },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "Not every URL reached a terminal status")
+	cancel()
+	<-done
+
+	for i, test := range tests {
+		require.Equal(t, test.status, repository.Status(ids[i]), "Unexpected status for %s", test.address)
+	}
+	require.Equal(t, 1, busService.Published(), "Only the publishable URL should be published")
+}
+
+// TestOutboundMessageService_MockNatsClient verifies that the outbound service publishes pending URLs
+// through any nats_service.Client implementation and marks them succeeded.
+func TestOutboundMessageService_MockNatsClient(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		client     = NewMockNatsClient()
+		repository = NewMockUrlRepository(0)
+		fakeClock  = clock.NewFake(time.Now())
+		interval   = time.Minute
+		url        = &entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: "https://example.com/mock-client",
+			Status:  entities.StatusPending,
+			Source:  "mock_client_test",
+		}
+	)
+	repository.AddPending(url)
+	service := messages.NewOutboundMessageService(client, repository, interval, 10, 0,
+		container.OutboundMetrics.Get(), fakeClock, container.Logger.Get())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return repository.Updated() == 1 },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "URL was not processed")
+	cancel()
+	<-done
+
+	published := client.Published(messaging.UrlOutgoing)
+	require.Len(t, published, 1, "Expected a single published URL")
+	var decoded entities.Url
+	require.NoError(t, json.Unmarshal(published[0], &decoded), "Failed to decode published URL")
+	require.Equal(t, url.Address, decoded.Address)
+	require.Equal(t, entities.StatusSucceeded, repository.Status(url.Id.Hex()))
+}
+
+// TestOutboundMessageService_ClaimsByDefault verifies that the service claims pending URLs before publishing them
+// unless claims are explicitly disabled.
+func TestOutboundMessageService_ClaimsByDefault(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []messages.OutboundOption
+		claims int
+	}{
+		{name: "default", claims: 1},
+		{name: "without claims", opts: []messages.OutboundOption{messages.WithoutClaims()}, claims: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				container  = NewTestContainer()
+				client     = NewMockNatsClient()
+				repository = NewMockUrlRepository(0)
+				fakeClock  = clock.NewFake(time.Now())
+				interval   = time.Minute
+			)
+			repository.AddPending(&entities.Url{
+				Id:      primitive.NewObjectID(),
+				Address: "https://example.com/claims",
+				Status:  entities.StatusPending,
+				Source:  "claims_test",
+			})
+			service := messages.NewOutboundMessageService(client, repository, interval, 10, 0,
+				container.OutboundMetrics.Get(), fakeClock, container.Logger.Get(), tt.opts...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				service.Start(ctx)
+			}()
+
+			// Wait for the service to create its ticker before advancing the clock.
+			fakeClock.BlockUntilTickers(1)
+			fakeClock.Advance(interval)
+			require.Eventually(t, func() bool { return repository.Updated() == 1 },
+				time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "URL was not processed")
+			cancel()
+			<-done
+
+			require.Equal(t, tt.claims, repository.Claims(), "Unexpected number of claims")
+			require.Len(t, client.Published(messaging.UrlOutgoing), 1, "Expected a single published URL")
+		})
+	}
+}
+
+// TestOutboundMessageService_NotBefore verifies that a pending URL scheduled for later by its NotBefore
+// is skipped by the scans until the time passes, and is then published and marked succeeded.
+func TestOutboundMessageService_NotBefore(t *testing.T) {
+	var (
+		container  = NewTestContainer()
+		repository = container.MongoRepository.Get()
+		client     = NewMockNatsClient()
+		fakeClock  = clock.NewFake(time.Now())
+		interval   = time.Minute
+		id         = primitive.NewObjectID()
+		url        = &entities.Url{
+			Id:        id,
+			Address:   "https://example.com/not-before/" + id.Hex(),
+			Status:    entities.StatusPending,
+			Source:    "not_before_test",
+			CreatedAt: fakeClock.Now(),
+			UpdatedAt: fakeClock.Now(),
+			NotBefore: fakeClock.Now().Add(interval + interval/2),
+		}
+	)
+	require.NoError(t, repository.Save(context.Background(), url), "Failed to save URL entity")
+	service := messages.NewOutboundMessageService(client, repository, interval, 100, 0,
+		container.OutboundMetrics.Get(), fakeClock, container.Logger.Get())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	defer func() {
+		cancel()
+		<-done
+	}()
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	status := func() string {
+		list, err := repository.FetchBatch(context.Background(), bson.M{"_id": id}, 1)
+		require.NoError(t, err, "Failed to fetch URL")
+		require.Len(t, list, 1, "URL not found")
+		return list[0].Status
+	}
+	published := func() bool {
+		for _, data := range client.Published(messaging.UrlOutgoing) {
+			var decoded entities.Url
+			if json.Unmarshal(data, &decoded) == nil && decoded.Address == url.Address {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Wait for the service to create its ticker before advancing the clock.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(interval)
+	require.Never(t, published, time.Duration(500)*time.Millisecond, time.Duration(20)*time.Millisecond,
+		"URL published before its NotBefore")
+	require.Equal(t, entities.StatusPending, status(), "URL processed before its NotBefore")
+
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return published() && status() == entities.StatusSucceeded },
+		time.Duration(5)*time.Second, time.Duration(20)*time.Millisecond, "URL not processed after its NotBefore")
+}
+
+// TestOutboundMessageService_Prefetch verifies that with prefetching a single scan works through a large backlog:
+// the next batches are claimed while the previous ones are being published, and no URL is published twice.
+func TestOutboundMessageService_Prefetch(t *testing.T) {
+	var (
+		container   = NewTestContainer()
+		client      = NewMockNatsClient()
+		repository  = NewMockUrlRepository(time.Duration(20) * time.Millisecond)
+		fakeClock   = clock.NewFake(time.Now())
+		interval    = time.Minute
+		batchSize   = 5
+		numBatches  = 8
+		numMessages = batchSize * numBatches
+	)
+	for i := 0; i < numMessages; i++ {
+		repository.AddPending(&entities.Url{
+			Id:      primitive.NewObjectID(),
+			Address: fmt.Sprintf("https://example.com/prefetch/%d", i),
+			Status:  entities.StatusPending,
+			Source:  "prefetch_test",
+		})
+	}
+	service := messages.NewOutboundMessageService(client, repository, interval, batchSize, 0,
+		container.OutboundMetrics.Get(), fakeClock, container.Logger.Get(),
+		messages.WithClaims(time.Hour), messages.WithPrefetch(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Start(ctx)
+	}()
+
+	// Wait for the service to create its ticker before advancing the clock, a single scan is triggered.
+	fakeClock.BlockUntilTickers(1)
+	fakeClock.Advance(interval)
+	require.Eventually(t, func() bool { return repository.Updated() == numMessages },
+		time.Duration(5)*time.Second, time.Duration(10)*time.Millisecond, "Backlog was not processed in a single scan")
+	cancel()
+	<-done
+
+	// Every batch after the first one, and the final empty claim, overlap with the processing of a claimed batch.
+	require.Equal(t, numBatches+1, repository.Claims(), "Unexpected number of claims")
+	require.Equal(t, numBatches, repository.Overlapping(), "Expected the batches to be claimed while publishing")
+	require.Equal(t, batchSize, repository.MaxInFlight(), "Expected the publishes to stay at full concurrency")
+
+	published := make(map[string]bool)
+	for _, data := range client.Published(messaging.UrlOutgoing) {
+		var decoded entities.Url
+		require.NoError(t, json.Unmarshal(data, &decoded), "Failed to decode published URL")
+		require.False(t, published[decoded.Address], "URL published twice: %s", decoded.Address)
+		published[decoded.Address] = true
+	}
+	require.Len(t, published, numMessages, "Expected every URL to be published once")
+}
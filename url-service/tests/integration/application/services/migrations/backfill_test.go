@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"url-service/domain/entities"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestBackfillService_Run verifies that legacy documents are backfilled in batches, that the deprecated processed
+// status is migrated to succeeded, and that reruns are no-ops.
+func TestBackfillService_Run(t *testing.T) {
+	container := SetupTestContainer(t)
+	collection := container.Collection.Get()
+	service := container.BackfillService.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	// Insert legacy documents written before schema versioning.
+	const legacyCount = 7
+	now := time.Now()
+	for i := 0; i < legacyCount; i++ {
+		_, err := collection.InsertOne(ctx, bson.M{
+			"address":    fmt.Sprintf("https://legacy.example.com/%d", i),
+			"status":     entities.StatusPending,
+			"source":     "legacy",
+			"created_at": now,
+			"updated_at": now,
+		})
+		require.NoError(t, err, "Failed to insert legacy document")
+	}
+
+	// Insert a schema version 1 document in the deprecated processed status.
+	_, err := collection.InsertOne(ctx, bson.M{
+		"address":        "https://processed.example.com",
+		"status":         entities.StatusProcessed,
+		"source":         "legacy-processed",
+		"created_at":     now,
+		"updated_at":     now,
+		"schema_version": 1,
+	})
+	require.NoError(t, err, "Failed to insert processed document")
+
+	// Save a document with the current schema that must be left untouched.
+	current := &entities.Url{Address: "https://current.example.com", Status: entities.StatusPending, Source: "current"}
+	require.NoError(t, container.MongoRepository.Get().Save(ctx, current), "Failed to save current document")
+
+	migrated, err := service.Run(ctx)
+	require.NoError(t, err, "Backfill failed")
+	require.Equal(t, legacyCount+1, migrated, "Expected only legacy documents to be migrated")
+
+	var list []*entities.Url
+	cursor, err := collection.Find(ctx, bson.M{"source": "legacy"})
+	require.NoError(t, err, "Failed to fetch legacy documents")
+	require.NoError(t, cursor.All(ctx, &list), "Failed to decode legacy documents")
+	require.Len(t, list, legacyCount)
+	for _, url := range list {
+		require.Equal(t, entities.CurrentSchemaVersion, url.Schema, "Expected schema version to be backfilled")
+		require.Equal(t, entities.StatusPending, url.Status, "Expected existing fields to be preserved")
+	}
+
+	var processed entities.Url
+	require.NoError(t, collection.FindOne(ctx, bson.M{"source": "legacy-processed"}).Decode(&processed))
+	require.Equal(t, entities.StatusSucceeded, processed.Status, "Expected processed status to be migrated")
+	require.Equal(t, entities.CurrentSchemaVersion, processed.Schema)
+
+	// Running the migration again is a no-op.
+	migrated, err = service.Run(ctx)
+	require.NoError(t, err, "Backfill rerun failed")
+	require.Zero(t, migrated, "Expected no documents to be migrated on rerun")
+}
+
+// TestBackfillService_Duplicates verifies that the duplicate addresses are deleted before the backfill,
+// keeping the oldest document of each address.
+func TestBackfillService_Duplicates(t *testing.T) {
+	container := SetupTestContainer(t)
+	collection := container.Collection.Get()
+	service := container.BackfillService.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	// Insert legacy documents stored before the unique address index existed.
+	const duplicates = 3
+	now := time.Now()
+	for i := 0; i < duplicates; i++ {
+		_, err := collection.InsertOne(ctx, bson.M{
+			"address":    "https://duplicate.example.com",
+			"status":     entities.StatusPending,
+			"source":     fmt.Sprintf("legacy-%d", i),
+			"created_at": now,
+			"updated_at": now,
+		})
+		require.NoError(t, err, "Failed to insert legacy document")
+	}
+
+	migrated, err := service.Run(ctx)
+	require.NoError(t, err, "Backfill failed")
+	require.Equal(t, 1, migrated, "Expected only the kept document to be migrated")
+
+	var list []*entities.Url
+	cursor, err := collection.Find(ctx, bson.M{"address": "https://duplicate.example.com"})
+	require.NoError(t, err, "Failed to fetch documents")
+	require.NoError(t, cursor.All(ctx, &list), "Failed to decode documents")
+	require.Len(t, list, 1, "Expected a single document per address")
+	require.Equal(t, "legacy-0", list[0].Source, "Expected the oldest document to be kept")
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"log/slog"
+	"os"
+	"shared/dependency"
+	"shared/mongodb/application/config"
+	"shared/mongodb/domain/entities"
+	"shared/mongodb/infrastructure/mongodb"
+	"url-service/application/services/migrations"
+	"url-service/domain/interfaces"
+	"url-service/infrastructure/url"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TestContainer holds dependencies for the integration tests.
+type TestContainer struct {
+	Logger          dependency.LazyDependency[*slog.Logger]
+	MongoClient     dependency.LazyDependency[*mongodb.Client]
+	Collection      dependency.LazyDependency[*mongo.Collection]
+	MongoRepository dependency.LazyDependency[interfaces.UrlRepository]
+	BackfillService dependency.LazyDependency[*migrations.BackfillService]
+}
+
+// NewTestContainer initializes a new test container.
+func NewTestContainer() *TestContainer {
+	c := &TestContainer{}
+
+	c.Logger = dependency.LazyDependency[*slog.Logger]{
+		InitFunc: func() *slog.Logger {
+			return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		},
+	}
+	c.MongoClient = dependency.LazyDependency[*mongodb.Client]{
+		InitFunc: func() *mongodb.Client {
+			var (
+				logger  = c.Logger.Get()
+				address string
+				err     error
+			)
+			if address, err = entities.GetMongo().Address(); err != nil {
+				panic(err)
+			}
+			return mongodb.NewClient(address, logger)
+		},
+	}
+	c.Collection = dependency.LazyDependency[*mongo.Collection]{
+		InitFunc: func() *mongo.Collection {
+			var (
+				mongoClient    *mongo.Client
+				collectionName = config.GetConfig().Mongo.Collection
+				dbName         = config.GetConfig().Mongo.DB
+				err            error
+			)
+			if mongoClient, err = c.MongoClient.Get().Connect(); err != nil {
+				panic(err)
+			}
+			return mongoClient.Database(dbName).Collection(collectionName)
+		},
+	}
+	c.MongoRepository = dependency.LazyDependency[interfaces.UrlRepository]{
+		InitFunc: func() interfaces.UrlRepository {
+			var (
+				logger      = c.Logger.Get()
+				collection  = c.Collection.Get()
+				mongoClient = collection.Database().Client()
+			)
+			return url.NewRepository(mongoClient, collection, logger)
+		},
+	}
+	c.BackfillService = dependency.LazyDependency[*migrations.BackfillService]{
+		InitFunc: func() *migrations.BackfillService {
+			var (
+				logger        = c.Logger.Get()
+				urlRepository = c.MongoRepository.Get()
+				batchSize     = 3
+			)
+			return migrations.NewBackfillService(urlRepository, batchSize, logger)
+		},
+	}
+
+	return c
+}
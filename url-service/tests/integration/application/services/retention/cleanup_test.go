@@ -0,0 +1,71 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+	"url-service/domain/entities"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestCleanupService_Run verifies that only failed URLs last updated before the retention max. age are deleted,
+// while recent failed URLs and URLs in other statuses are kept, and that reruns are no-ops.
+func TestCleanupService_Run(t *testing.T) {
+	container := SetupTestContainer(t)
+	collection := container.Collection.Get()
+	service := container.CleanupService.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	var (
+		now    = container.FakeClock.Get().Now()
+		stale  = now.Add(-time.Duration(48) * time.Hour)
+		recent = now.Add(-time.Duration(1) * time.Hour)
+	)
+	documents := []struct {
+		address string
+		status  string
+		updated time.Time
+		kept    bool
+	}{
+		{address: "https://old-failed.example.com", status: entities.StatusFailed, updated: stale},
+		{address: "https://old-permanent.example.com", status: entities.StatusPermanentlyFailed, updated: stale},
+		{address: "https://new-failed.example.com", status: entities.StatusFailed, updated: recent, kept: true},
+		{address: "https://new-permanent.example.com", status: entities.StatusPermanentlyFailed, updated: recent, kept: true},
+		{address: "https://old-succeeded.example.com", status: entities.StatusSucceeded, updated: stale, kept: true},
+		{address: "https://old-pending.example.com", status: entities.StatusPending, updated: stale, kept: true},
+	}
+	for _, document := range documents {
+		_, err := collection.InsertOne(ctx, bson.M{
+			"address":        document.address,
+			"status":         document.status,
+			"source":         "retention",
+			"created_at":     document.updated,
+			"updated_at":     document.updated,
+			"schema_version": entities.CurrentSchemaVersion,
+		})
+		require.NoError(t, err, "Failed to insert document")
+	}
+
+	deleted, err := service.Run(ctx)
+	require.NoError(t, err, "Cleanup failed")
+	require.Equal(t, 2, deleted, "Expected only old failed URLs to be deleted")
+
+	for _, document := range documents {
+		count, countErr := collection.CountDocuments(ctx, bson.M{"address": document.address})
+		require.NoError(t, countErr, "Failed to count documents")
+		if document.kept {
+			require.Equal(t, int64(1), count, "Expected %s to be kept", document.address)
+		} else {
+			require.Zero(t, count, "Expected %s to be deleted", document.address)
+		}
+	}
+
+	// Running the cleanup again is a no-op.
+	deleted, err = service.Run(ctx)
+	require.NoError(t, err, "Cleanup rerun failed")
+	require.Zero(t, deleted, "Expected no URLs to be deleted on rerun")
+}
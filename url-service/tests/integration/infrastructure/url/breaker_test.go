@@ -0,0 +1,107 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"shared/clock"
+	"testing"
+	"time"
+	"url-service/domain/entities"
+	"url-service/infrastructure/metrics"
+	"url-service/infrastructure/url"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestBreakerRepository_TripAndRecover verifies that repeated timeouts open the breaker,
+// operations fast-fail without reaching MongoDB during the cooldown and a successful trial closes it again.
+func TestBreakerRepository_TripAndRecover(t *testing.T) {
+	var (
+		ctx         = context.Background()
+		fake        = clock.NewFake(time.Now())
+		registry    = prometheus.NewRegistry()
+		repoMetrics = metrics.NewRepositoryMetrics("test")
+		logger      = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		failing     = &FailingUrlRepository{}
+	)
+	require.NoError(t, repoMetrics.Register(registry), "Failed to register repository metrics")
+
+	var (
+		breaker    = url.NewBreaker(3, time.Minute, fake, repoMetrics, logger)
+		repository = url.NewBreakerRepository(failing, breaker)
+	)
+
+	failing.Fail(fmt.Errorf("count by status: %w", context.DeadlineExceeded))
+	for i := 0; i < 3; i++ {
+		_, err := repository.Count(ctx, bson.M{"status": entities.StatusPending})
+		require.ErrorIs(t, err, context.DeadlineExceeded, "Expected the timeout to reach the caller")
+	}
+	assert.Equal(t, url.BreakerOpen, breaker.State(), "Expected the breaker to open after the threshold")
+	assert.Equal(t, float64(url.BreakerOpen), gaugeValue(t, registry, "test_repository_breaker_state"))
+
+	failing.Fail(nil)
+	_, err := repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	require.ErrorIs(t, err, url.ErrCircuitOpen, "Expected a fast-fail while the breaker is open")
+	assert.Equal(t, 3, failing.Calls(), "Expected the open breaker not to reach the repository")
+
+	fake.Advance(time.Minute)
+	assert.Equal(t, url.BreakerHalfOpen, breaker.State(), "Expected the breaker to half-open after the cooldown")
+
+	count, err := repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	require.NoError(t, err, "Expected the trial operation to succeed")
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, url.BreakerClosed, breaker.State(), "Expected a successful trial to close the breaker")
+	assert.Equal(t, float64(url.BreakerClosed), gaugeValue(t, registry, "test_repository_breaker_state"))
+}
+
+// TestBreakerRepository_FailedTrial verifies that a failed half-open trial re-opens the breaker
+// and that caller errors do not count towards the threshold.
+func TestBreakerRepository_FailedTrial(t *testing.T) {
+	var (
+		ctx        = context.Background()
+		fake       = clock.NewFake(time.Now())
+		logger     = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{}))
+		failing    = &FailingUrlRepository{}
+		breaker    = url.NewBreaker(2, time.Minute, fake, nil, logger)
+		repository = url.NewBreakerRepository(failing, breaker)
+	)
+
+	failing.Fail(fmt.Errorf("ID %s not found", "missing"))
+	for i := 0; i < 5; i++ {
+		_, _ = repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	}
+	assert.Equal(t, url.BreakerClosed, breaker.State(), "Expected caller errors not to open the breaker")
+
+	failing.Fail(context.DeadlineExceeded)
+	for i := 0; i < 2; i++ {
+		_, _ = repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	}
+	require.Equal(t, url.BreakerOpen, breaker.State(), "Expected timeouts to open the breaker")
+
+	fake.Advance(time.Minute)
+	_, err := repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	require.ErrorIs(t, err, context.DeadlineExceeded, "Expected the trial operation to reach the repository")
+	assert.Equal(t, url.BreakerOpen, breaker.State(), "Expected a failed trial to re-open the breaker")
+
+	fake.Advance(time.Duration(59) * time.Second)
+	_, err = repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	assert.ErrorIs(t, err, url.ErrCircuitOpen, "Expected a failed trial to restart the cooldown")
+}
+
+// gaugeValue returns the value of the named gauge.
+func gaugeValue(t *testing.T, registry *prometheus.Registry, name string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err, "Failed to gather metrics")
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
@@ -7,6 +7,7 @@ import (
 	"shared/mongodb/application/config"
 	"shared/mongodb/domain/entities"
 	"shared/mongodb/infrastructure/mongodb"
+	urlEntities "url-service/domain/entities"
 	"url-service/domain/interfaces"
 	"url-service/infrastructure/url"
 
@@ -18,6 +19,9 @@ type TestContainer struct {
 	Logger          dependency.LazyDependency[*slog.Logger]
 	MongoClient     dependency.LazyDependency[*mongodb.Client]
 	MongoRepository dependency.LazyDependency[interfaces.UrlRepository]
+
+	AuditedMongoRepository dependency.LazyDependency[interfaces.UrlRepository]
+	LimitedMongoRepository dependency.LazyDependency[interfaces.UrlRepository]
 }
 
 // NewTestContainer initializes a new test container.
@@ -60,5 +64,43 @@ func NewTestContainer() *TestContainer {
 		},
 	}
 
+	c.AuditedMongoRepository = dependency.LazyDependency[interfaces.UrlRepository]{
+		InitFunc: func() interfaces.UrlRepository {
+			var (
+				logger         = c.Logger.Get()
+				mongoClient    *mongo.Client
+				collection     *mongo.Collection
+				transitions    *mongo.Collection
+				collectionName = config.GetConfig().Mongo.Collection
+				dbName         = config.GetConfig().Mongo.DB
+				err            error
+			)
+			if mongoClient, err = c.MongoClient.Get().Connect(); err != nil {
+				panic(err)
+			}
+			collection = mongoClient.Database(dbName).Collection(collectionName)
+			transitions = mongoClient.Database(dbName).Collection("transitions")
+			return url.NewRepository(mongoClient, collection, logger, url.WithTransitionLog(transitions))
+		},
+	}
+	c.LimitedMongoRepository = dependency.LazyDependency[interfaces.UrlRepository]{
+		InitFunc: func() interfaces.UrlRepository {
+			var (
+				logger         = c.Logger.Get()
+				mongoClient    *mongo.Client
+				collection     *mongo.Collection
+				collectionName = config.GetConfig().Mongo.Collection
+				dbName         = config.GetConfig().Mongo.DB
+				limits         = urlEntities.SizeLimits{MaxAddressLength: 256, MaxSourceLength: 64, MaxDocumentSize: 1024}
+				err            error
+			)
+			if mongoClient, err = c.MongoClient.Get().Connect(); err != nil {
+				panic(err)
+			}
+			collection = mongoClient.Database(dbName).Collection(collectionName)
+			return url.NewRepository(mongoClient, collection, logger, url.WithSizeLimits(limits))
+		},
+	}
+
 	return c
 }
@@ -0,0 +1,33 @@
+package url
+
+import (
+	"context"
+	"sync/atomic"
+	"url-service/domain/interfaces"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FailingUrlRepository is an interfaces.UrlRepository whose Count returns a configurable error.
+// The remaining operations are not implemented.
+type FailingUrlRepository struct {
+	interfaces.UrlRepository
+
+	err   atomic.Pointer[error] // err is the error returned by Count, nil succeeds.
+	calls atomic.Int32          // calls is the number of Count calls that reached the repository.
+}
+
+// Fail makes the following Count calls return err, nil makes them succeed.
+func (r *FailingUrlRepository) Fail(err error) { r.err.Store(&err) }
+
+// Calls returns the number of Count calls that reached the repository.
+func (r *FailingUrlRepository) Calls() int { return int(r.calls.Load()) }
+
+// Count returns the configured error.
+func (r *FailingUrlRepository) Count(ctx context.Context, filter bson.M) (count int64, err error) {
+	r.calls.Add(1)
+	if stored := r.err.Load(); stored != nil && *stored != nil {
+		return 0, *stored
+	}
+	return 1, nil
+}
@@ -2,9 +2,15 @@ package url
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"shared/mongodb/application/config"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 	"url-service/domain/entities"
+	"url-service/infrastructure/url"
 
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
@@ -42,6 +48,73 @@ func TestRepository_Save(t *testing.T) {
 	require.Equal(t, urlEntity.Address, urls[0].Address, "Expected matching address")
 }
 
+// TestRepository_Upsert verifies that a URL entity is inserted once per address, and that upserting an existing
+// address leaves the saved entity and its status untouched.
+func TestRepository_Upsert(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	first := &entities.Url{Address: "https://example.com/upsert", Status: entities.StatusPending, Source: "test",
+		CreatedAt: now, UpdatedAt: now}
+	inserted, err := repository.Upsert(ctx, first)
+	require.NoError(t, err, "Failed to upsert URL entity")
+	require.True(t, inserted, "Expected a new address to be inserted")
+	require.NoError(t, repository.UpdateFields(ctx, first.Id.Hex(), bson.M{"status": entities.StatusSucceeded}),
+		"Failed to update URL status")
+
+	duplicate := &entities.Url{Address: first.Address, Status: entities.StatusPending, Source: "duplicate",
+		CreatedAt: now, UpdatedAt: now}
+	inserted, err = repository.Upsert(ctx, duplicate)
+	require.NoError(t, err, "Failed to upsert duplicate URL entity")
+	require.False(t, inserted, "Expected an existing address not to be inserted")
+
+	urls, err := repository.FetchBatch(ctx, bson.M{"address": first.Address}, 10)
+	require.NoError(t, err, "Failed to fetch URLs")
+	require.Len(t, urls, 1, "Expected a single document per address")
+	require.Equal(t, first.Id, urls[0].Id, "Expected the first entity to be kept")
+	require.Equal(t, entities.StatusSucceeded, urls[0].Status, "Expected the status to be left untouched")
+	require.Equal(t, "test", urls[0].Source, "Expected the source to be left untouched")
+}
+
+// TestRepository_UpsertSoftDeleted verifies that the address of a soft-deleted URL is not queued again, with the
+// unique address index built, until the URL is purged.
+func TestRepository_UpsertSoftDeleted(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository, ok := container.MongoRepository.Get().(*url.Repository)
+	require.True(t, ok, "Expected a MongoDB repository")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	require.NoError(t, repository.EnsureIndexes(ctx), "Failed to create indexes")
+
+	first := &entities.Url{Address: "https://example.com/deleted", Status: entities.StatusPending, Source: "test"}
+	inserted, err := repository.Upsert(ctx, first)
+	require.NoError(t, err, "Failed to upsert URL entity")
+	require.True(t, inserted, "Expected a new address to be inserted")
+	require.NoError(t, repository.SoftDelete(ctx, first.Id.Hex()), "Failed to soft-delete URL")
+
+	again := &entities.Url{Address: first.Address, Status: entities.StatusPending, Source: "again"}
+	inserted, err = repository.Upsert(ctx, again)
+	require.NoError(t, err, "Failed to upsert soft-deleted address")
+	require.False(t, inserted, "Expected a soft-deleted address not to be queued again")
+
+	urls, err := repository.FetchBatch(ctx, bson.M{"address": first.Address}, 10, entities.IncludeDeleted())
+	require.NoError(t, err, "Failed to fetch URLs")
+	require.Len(t, urls, 1, "Expected a single document per address")
+	require.Equal(t, entities.StatusDeleted, urls[0].Status, "Expected the URL to stay deleted")
+
+	_, err = repository.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err, "Failed to purge deleted URLs")
+	inserted, err = repository.Upsert(ctx, again)
+	require.NoError(t, err, "Failed to upsert purged address")
+	require.True(t, inserted, "Expected a purged address to be queued again")
+}
+
 // TestRepository_FetchBatch verifies that filtering works as expected.
 func TestRepository_FetchBatch(t *testing.T) {
 	container := SetupTestContainer(t)
@@ -83,6 +156,30 @@ func TestRepository_FetchBatch(t *testing.T) {
 	require.GreaterOrEqual(t, len(fetched), 2, "Expected to find 2 URLs but found %d", len(fetched))
 }
 
+// TestRepository_Count verifies that Count returns the number of URLs matching the filter.
+func TestRepository_Count(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	for i, status := range []string{entities.StatusPending, entities.StatusPending, entities.StatusSucceeded} {
+		url := &entities.Url{Address: fmt.Sprintf("https://example.com/count/%d", i), Status: status,
+			Source: "count_test", CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, repository.Save(ctx, url), "Failed to save URL entity")
+	}
+
+	count, err := repository.Count(ctx, bson.M{"source": "count_test", "status": entities.StatusPending})
+	require.NoError(t, err, "Failed to count URLs")
+	require.Equal(t, int64(2), count, "Expected the pending URLs to be counted")
+
+	count, err = repository.Count(ctx, bson.M{"source": "count_test"})
+	require.NoError(t, err, "Failed to count URLs")
+	require.Equal(t, int64(3), count, "Expected every URL of the source to be counted")
+}
+
 // TestRepository_UpdateFields verifies that updating specific fields works as expected.
 func TestRepository_UpdateFields(t *testing.T) {
 	container := SetupTestContainer(t)
@@ -105,7 +202,7 @@ func TestRepository_UpdateFields(t *testing.T) {
 	require.NoError(t, err, "Failed to save URL entity")
 
 	// Update the URL entity by changing its status and updating the timestamp.
-	newStatus := entities.StatusProcessed
+	newStatus := entities.StatusSucceeded
 	updateTime := time.Now()
 	updateFields := bson.M{
 		"status":     newStatus,
@@ -186,3 +283,454 @@ func TestRepository_BulkUpdateFields(t *testing.T) {
 		require.WithinDuration(t, updateTime, doc.UpdatedAt, time.Second, "Expected updated_at to be updated")
 	}
 }
+
+// TestRepository_BulkUpdateFieldsDetailed verifies that a bulk update of existing and missing IDs updates
+// the existing URL entities and reports the missing IDs, while BulkUpdateFields keeps succeeding on partial matches.
+func TestRepository_BulkUpdateFieldsDetailed(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	urlEntity := &entities.Url{
+		Address:   "https://bulk-detailed.example.com",
+		Status:    entities.StatusPending,
+		Source:    "bulk_update_detailed_test",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := repository.Save(ctx, urlEntity)
+	require.NoError(t, err, "Failed to save URL entity")
+
+	var (
+		existing = urlEntity.Id.Hex()
+		missing  = []string{primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()}
+		ids      = []string{missing[0], existing, missing[1]}
+	)
+	result, err := repository.BulkUpdateFieldsDetailed(ctx, ids, bson.M{"status": entities.StatusProcessed})
+	require.NoError(t, err, "Partial matches must not fail")
+	require.Equal(t, int64(1), result.Matched, "Expected the existing URL to match")
+	require.Equal(t, int64(1), result.Modified, "Expected the existing URL to be modified")
+	require.True(t, result.Partial(), "Expected a partial match")
+	require.Equal(t, missing, result.Unmatched, "Expected the missing IDs in the given order")
+
+	fetched, err := repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1)
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Len(t, fetched, 1)
+	require.Equal(t, entities.StatusProcessed, fetched[0].Status, "Expected status to be updated")
+
+	result, err = repository.BulkUpdateFieldsDetailed(ctx, missing, bson.M{"status": entities.StatusProcessed})
+	require.NoError(t, err, "Missing IDs must be reported, not returned as an error")
+	require.Zero(t, result.Matched)
+	require.Equal(t, missing, result.Unmatched)
+
+	require.NoError(t, repository.BulkUpdateFields(ctx, ids, bson.M{"status": entities.StatusPending}),
+		"Expected a partial match to succeed")
+	require.Error(t, repository.BulkUpdateFields(ctx, missing, bson.M{"status": entities.StatusPending}),
+		"Expected no match to fail")
+}
+
+// TestRepository_StatusTransitions verifies that status changes are captured in order by the transitions log.
+func TestRepository_StatusTransitions(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.AuditedMongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	urlEntity := &entities.Url{
+		Address:   "https://transitions.example.com",
+		Status:    entities.StatusPending,
+		Source:    "transitions_test",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := repository.Save(ctx, urlEntity)
+	require.NoError(t, err, "Failed to save URL entity")
+	id := urlEntity.Id.Hex()
+
+	// pending -> failed -> pending (retry) -> processed, mixing single and bulk updates.
+	err = repository.UpdateFields(ctx, id, bson.M{"status": entities.StatusFailed, "status_reason": "publish error"})
+	require.NoError(t, err, "Failed to update URL status")
+	err = repository.BulkUpdateFields(ctx, []string{id}, bson.M{"status": entities.StatusPending, "status_reason": "retry"})
+	require.NoError(t, err, "Failed to bulk update URL status")
+	err = repository.UpdateFields(ctx, id, bson.M{"updated_at": time.Now()})
+	require.NoError(t, err, "Failed to update URL fields")
+	err = repository.UpdateFields(ctx, id, bson.M{"status": entities.StatusSucceeded, "status_reason": "published"})
+	require.NoError(t, err, "Failed to update URL status")
+
+	transitions, err := repository.FetchTransitions(ctx, id)
+	require.NoError(t, err, "Failed to fetch transitions")
+	require.Len(t, transitions, 3, "Expected one transition per status change")
+
+	expected := []struct{ from, to, reason string }{
+		{entities.StatusPending, entities.StatusFailed, "publish error"},
+		{entities.StatusFailed, entities.StatusPending, "retry"},
+		{entities.StatusPending, entities.StatusSucceeded, "published"},
+	}
+	for i, transition := range transitions {
+		require.Equal(t, urlEntity.Id, transition.UrlId, "Transition URL mismatch")
+		require.Equal(t, expected[i].from, transition.From, "Transition %d from status mismatch", i)
+		require.Equal(t, expected[i].to, transition.To, "Transition %d to status mismatch", i)
+		require.Equal(t, expected[i].reason, transition.Reason, "Transition %d reason mismatch", i)
+	}
+}
+
+// TestRepository_SaveSizeLimits verifies that documents within the size limits are saved and oversized ones are rejected.
+func TestRepository_SaveSizeLimits(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.LimitedMongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	accepted := &entities.Url{
+		Address:   "https://example.com/limits",
+		Status:    entities.StatusPending,
+		Source:    "test",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, repository.Save(ctx, accepted), "Expected URL within limits to be saved")
+
+	oversized := &entities.Url{
+		Address:   "https://example.com/" + strings.Repeat("a", 512),
+		Status:    entities.StatusPending,
+		Source:    "test",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := repository.Save(ctx, oversized)
+	require.Error(t, err, "Expected oversized URL to be rejected")
+
+	var sizeErr *entities.SizeError
+	require.True(t, errors.As(err, &sizeErr), "Expected a SizeError")
+	require.Equal(t, "address", sizeErr.Field)
+	require.Equal(t, 256, sizeErr.Limit)
+
+	urls, err := repository.FetchBatch(ctx, bson.M{"address": oversized.Address}, 1)
+	require.NoError(t, err, "Failed to fetch URLs")
+	require.Empty(t, urls, "Expected oversized URL not to be persisted")
+}
+
+// TestRepository_ClaimPending verifies that concurrent callers claim disjoint sets of pending URLs.
+func TestRepository_ClaimPending(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	// Drain pending URLs left by other tests.
+	_, err := repository.ClaimPending(ctx, 1000)
+	require.NoError(t, err, "Failed to drain pending URLs")
+
+	const total = 20
+	now := time.Now()
+	for i := 0; i < total; i++ {
+		urlEntity := &entities.Url{
+			Address:   fmt.Sprintf("https://claim.example.com/%d", i),
+			Status:    entities.StatusPending,
+			Source:    "claim_test",
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity")
+	}
+
+	var (
+		wg     sync.WaitGroup
+		claims = make([][]*entities.Url, 2)
+		errs   = make([]error, 2)
+	)
+	for i := range claims {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			claims[i], errs[i] = repository.ClaimPending(ctx, total)
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[primitive.ObjectID]struct{}, total)
+	for i, claimed := range claims {
+		require.NoError(t, errs[i], "Failed to claim pending URLs")
+		for _, url := range claimed {
+			_, exists := seen[url.Id]
+			require.False(t, exists, "URL %s claimed twice", url.Id.Hex())
+			seen[url.Id] = struct{}{}
+			require.Equal(t, entities.StatusProcessing, url.Status, "Expected claimed URL to be processing")
+		}
+	}
+	require.Len(t, seen, total, "Expected all pending URLs to be claimed")
+
+	remaining, err := repository.ClaimPending(ctx, total)
+	require.NoError(t, err, "Failed to claim pending URLs")
+	require.Empty(t, remaining, "Expected no pending URLs to remain")
+}
+
+// TestRepository_ReleaseStale verifies that only claims older than the cutoff are released to pending.
+func TestRepository_ReleaseStale(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.AuditedMongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	// Drain pending URLs left by other tests.
+	_, err := repository.ClaimPending(ctx, 1000)
+	require.NoError(t, err, "Failed to drain pending URLs")
+	_, err = repository.ReleaseStale(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err, "Failed to release stale claims")
+
+	urlEntity := &entities.Url{Address: "https://stale.example.com", Status: entities.StatusPending, Source: "stale_test"}
+	require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity")
+	claimed, err := repository.ClaimPending(ctx, 1)
+	require.NoError(t, err, "Failed to claim pending URL")
+	require.Len(t, claimed, 1, "Expected the URL to be claimed")
+
+	released, err := repository.ReleaseStale(ctx, time.Now().Add(-time.Minute))
+	require.NoError(t, err, "Failed to release stale claims")
+	require.Zero(t, released, "Expected a fresh claim to be kept")
+
+	released, err = repository.ReleaseStale(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err, "Failed to release stale claims")
+	require.GreaterOrEqual(t, released, 1, "Expected the expired claim to be released")
+
+	urls, err := repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1)
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Len(t, urls, 1, "Expected the URL to exist")
+	require.Equal(t, entities.StatusPending, urls[0].Status, "Expected the released URL to be pending")
+
+	transitions, err := repository.FetchTransitions(ctx, urlEntity.Id.Hex())
+	require.NoError(t, err, "Failed to fetch transitions")
+	require.Len(t, transitions, 2, "Expected the claim and the release to be recorded")
+	require.Equal(t, entities.StatusProcessing, transitions[1].From)
+	require.Equal(t, entities.StatusPending, transitions[1].To)
+	require.Equal(t, "claim expired", transitions[1].Reason)
+}
+
+// TestRepository_UpdateCanceled verifies that updates failing because of a canceled context are identified
+// as cancellations rather than update failures.
+func TestRepository_UpdateCanceled(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	urlEntity := &entities.Url{Address: "https://canceled.example.com", Status: entities.StatusPending, Source: "test"}
+	require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity")
+
+	canceledCtx, cancelUpdate := context.WithCancel(ctx)
+	cancelUpdate()
+	updateFields := bson.M{"status": entities.StatusSucceeded, "updated_at": time.Now()}
+
+	err := repository.UpdateFields(canceledCtx, urlEntity.Id.Hex(), updateFields)
+	require.ErrorIs(t, err, url.ErrCanceled, "Expected the update to be identified as canceled")
+	require.ErrorIs(t, err, context.Canceled, "Expected the context error to be wrapped")
+
+	err = repository.BulkUpdateFields(canceledCtx, []string{urlEntity.Id.Hex()}, updateFields)
+	require.ErrorIs(t, err, url.ErrCanceled, "Expected the bulk update to be identified as canceled")
+
+	// A real failure is not reported as a cancellation.
+	err = repository.UpdateFields(ctx, primitive.NewObjectID().Hex(), updateFields)
+	require.Error(t, err, "Expected an unknown ID to fail")
+	require.NotErrorIs(t, err, url.ErrCanceled, "Expected a missing document not to be a cancellation")
+
+	urls, err := repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1)
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Len(t, urls, 1, "Expected the URL to exist")
+	require.Equal(t, entities.StatusPending, urls[0].Status, "Expected the canceled updates not to apply")
+}
+
+// TestRepository_FetchPage verifies that pages of URLs are fetched in ID order without gaps or repeats,
+// starting from the beginning with an empty cursor.
+func TestRepository_FetchPage(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	var (
+		source = fmt.Sprintf("page_test_%d", time.Now().UnixNano())
+		filter = bson.M{"source": source}
+		saved  []string
+	)
+	for i := 0; i < 5; i++ {
+		urlEntity := &entities.Url{
+			Address: fmt.Sprintf("https://page.example.com/%d", i),
+			Status:  entities.StatusPending,
+			Source:  source,
+		}
+		require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity")
+		saved = append(saved, urlEntity.Id.Hex())
+	}
+
+	var (
+		fetched []string
+		sizes   []int
+		cursor  string
+	)
+	for {
+		page, next, err := repository.FetchPage(ctx, filter, 2, cursor)
+		require.NoError(t, err, "Failed to fetch page")
+		if len(page) == 0 {
+			require.Empty(t, next, "Expected no cursor after the last page")
+			break
+		}
+		for _, url := range page {
+			fetched = append(fetched, url.Id.Hex())
+		}
+		require.Equal(t, page[len(page)-1].Id.Hex(), next, "Expected the last ID as the next cursor")
+		sizes = append(sizes, len(page))
+		cursor = next
+	}
+	require.Equal(t, []int{2, 2, 1}, sizes, "Unexpected page sizes")
+	require.Equal(t, saved, fetched, "Expected every URL once in ID order")
+
+	_, _, err := repository.FetchPage(ctx, filter, 2, "not-an-id")
+	require.Error(t, err, "Expected an invalid cursor to be rejected")
+}
+
+// TestRepository_SoftDelete verifies that soft-deleted URLs are hidden from batches unless included,
+// and that they are removed for good once purged.
+func TestRepository_SoftDelete(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository := container.MongoRepository.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	urlEntity := &entities.Url{Address: "https://deleted.example.com", Status: entities.StatusPending, Source: "test"}
+	require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity")
+	require.NoError(t, repository.SoftDelete(ctx, urlEntity.Id.Hex()), "Failed to soft-delete URL")
+	require.Error(t, repository.SoftDelete(ctx, "invalid-id"), "Expected an invalid ID to be rejected")
+
+	urls, err := repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1)
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Empty(t, urls, "Expected the soft-deleted URL to be excluded")
+
+	urls, err = repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1, entities.IncludeDeleted())
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Len(t, urls, 1, "Expected the soft-deleted URL to be included")
+	require.Equal(t, entities.StatusDeleted, urls[0].Status, "Expected the deleted status")
+	require.False(t, urls[0].DeletedAt.IsZero(), "Expected the deletion time to be set")
+
+	page, _, err := repository.FetchPage(ctx, bson.M{"_id": urlEntity.Id}, 1, "")
+	require.NoError(t, err, "Failed to fetch page")
+	require.Empty(t, page, "Expected the soft-deleted URL to be excluded from pages")
+	page, _, err = repository.FetchPage(ctx, bson.M{"_id": urlEntity.Id}, 1, "", entities.IncludeDeleted())
+	require.NoError(t, err, "Failed to fetch page")
+	require.Len(t, page, 1, "Expected the soft-deleted URL to be included in pages")
+
+	_, err = repository.PurgeDeleted(ctx, urls[0].DeletedAt)
+	require.NoError(t, err, "Failed to purge deleted URLs")
+	urls, err = repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1, entities.IncludeDeleted())
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Len(t, urls, 1, "Expected a URL deleted at the cutoff to be kept")
+
+	purged, err := repository.PurgeDeleted(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err, "Failed to purge deleted URLs")
+	require.GreaterOrEqual(t, purged, 1, "Expected the soft-deleted URL to be purged")
+	urls, err = repository.FetchBatch(ctx, bson.M{"_id": urlEntity.Id}, 1, entities.IncludeDeleted())
+	require.NoError(t, err, "Failed to fetch URL")
+	require.Empty(t, urls, "Expected the purged URL to be gone")
+
+	transitions, err := repository.FetchTransitions(ctx, urlEntity.Id.Hex())
+	require.NoError(t, err, "Failed to fetch transitions")
+	require.NotEmpty(t, transitions, "Expected the audit history to be kept")
+	require.Equal(t, entities.StatusDeleted, transitions[len(transitions)-1].To)
+}
+
+// TestRepository_EnsureIndexes verifies that the indexes are created idempotently, that the unique address index
+// rejects duplicates, and that the pending scan is served by the status index instead of a collection scan.
+func TestRepository_EnsureIndexes(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository, ok := container.MongoRepository.Get().(*url.Repository)
+	require.True(t, ok, "Expected a MongoDB repository")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	require.False(t, repository.UniqueIndex(), "Expected the unique index to be reported missing before")
+	require.NoError(t, repository.EnsureIndexes(ctx), "Failed to create indexes")
+	require.NoError(t, repository.EnsureIndexes(ctx), "Expected existing indexes to be kept")
+	require.True(t, repository.UniqueIndex(), "Expected the unique index to be reported built")
+
+	urlEntity := &entities.Url{Address: "https://unique.example.com", Status: entities.StatusPending, Source: "test"}
+	require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity")
+	duplicate := &entities.Url{Address: urlEntity.Address, Status: entities.StatusPending, Source: "test"}
+	require.ErrorIs(t, repository.Save(ctx, duplicate), entities.ErrDuplicateAddress, "Expected a duplicate address")
+
+	client, err := container.MongoClient.Get().Connect()
+	require.NoError(t, err, "Failed to connect to MongoDB")
+	var (
+		mongoConfig = config.GetConfig().Mongo
+		explain     = bson.D{
+			{Key: "explain", Value: bson.D{
+				{Key: "find", Value: mongoConfig.Collection},
+				{Key: "filter", Value: entities.PendingFilter(time.Now())},
+			}},
+			{Key: "verbosity", Value: "queryPlanner"},
+		}
+		result bson.M
+	)
+	require.NoError(t, client.Database(mongoConfig.DB).RunCommand(ctx, explain).Decode(&result), "Failed to explain")
+
+	plan := fmt.Sprint(result["queryPlanner"])
+	require.Contains(t, plan, "IXSCAN", "Expected the pending scan to use an index")
+	require.Contains(t, plan, "status_updated_at", "Expected the pending scan to use the status index")
+	require.NotContains(t, plan, "COLLSCAN", "Expected no collection scan")
+}
+
+// TestRepository_DeleteDuplicateAddresses verifies that the unique address index is not built over duplicate
+// addresses while the other indexes are, that it is reported missing, and that deleting the duplicates keeps the
+// oldest URL and lets it be built.
+func TestRepository_DeleteDuplicateAddresses(t *testing.T) {
+	container := SetupTestContainer(t)
+	repository, ok := container.MongoRepository.Get().(*url.Repository)
+	require.True(t, ok, "Expected a MongoDB repository")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
+	defer cancel()
+
+	// Without the unique index the same address is stored several times.
+	var list []*entities.Url
+	for i := 0; i < 3; i++ {
+		urlEntity := &entities.Url{Address: "https://duplicate.example.com", Status: entities.StatusPending,
+			Source: fmt.Sprintf("test-%d", i)}
+		require.NoError(t, repository.Save(ctx, urlEntity), "Failed to save URL entity %d", i)
+		list = append(list, urlEntity)
+	}
+	unique := &entities.Url{Address: "https://single.example.com", Status: entities.StatusPending, Source: "test"}
+	require.NoError(t, repository.Save(ctx, unique), "Failed to save URL entity")
+
+	err := repository.EnsureIndexes(ctx)
+	require.ErrorIs(t, err, entities.ErrDuplicateAddress, "Expected the unique index to fail over duplicates")
+	require.False(t, repository.UniqueIndex(), "Expected the unique index to be reported missing")
+
+	deleted, err := repository.DeleteDuplicateAddresses(ctx)
+	require.NoError(t, err, "Failed to delete duplicate addresses")
+	require.Equal(t, 2, deleted, "Expected all but the oldest duplicate to be deleted")
+
+	stored, err := repository.FetchBatch(ctx, bson.M{"address": list[0].Address}, 10)
+	require.NoError(t, err, "Failed to fetch URL entities")
+	require.Len(t, stored, 1, "Expected a single URL per address")
+	require.Equal(t, list[0].Id, stored[0].Id, "Expected the oldest URL to be kept")
+
+	count, err := repository.Count(ctx, bson.M{"status": entities.StatusPending})
+	require.NoError(t, err, "Failed to count URL entities")
+	require.Equal(t, int64(2), count, "Expected the unique URL to be kept")
+
+	deleted, err = repository.DeleteDuplicateAddresses(ctx)
+	require.NoError(t, err, "Failed to delete duplicate addresses")
+	require.Zero(t, deleted, "Expected no duplicates left")
+	require.NoError(t, repository.EnsureIndexes(ctx), "Expected the unique index to be built")
+	require.True(t, repository.UniqueIndex(), "Expected the unique index to be reported built")
+}